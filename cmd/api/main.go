@@ -20,12 +20,12 @@ import (
 	"context"
 	"fmt" // For error formatting
 	"log"
-	"net/http"
 	"os"
 	"os/signal"
 	"quiz-byte/internal/adapter"
 	"quiz-byte/internal/adapter/embedding"
-	"quiz-byte/internal/adapter/evaluator" // Added for NewLLMEvaluator
+	"quiz-byte/internal/adapter/evaluator" // Added for NewFromConfig
+	"quiz-byte/internal/adapter/identity"
 	"quiz-byte/internal/cache"
 	"quiz-byte/internal/config"
 	"quiz-byte/internal/database"
@@ -42,7 +42,6 @@ import (
 	_ "quiz-byte/docs"
 
 	"github.com/gofiber/swagger"
-	"github.com/tmc/langchaingo/llms/ollama"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -122,14 +121,6 @@ func main() {
 		appLogger.Fatal(fmt.Sprintf("Unsupported embedding source: %s. Please check EMBEDDING_SOURCE in config.", cfg.Embedding.Source))
 	}
 
-	// Configure HTTP client for Ollama
-	ollamaHTTPClient := &http.Client{Timeout: 20 * time.Second}
-	// Use LLMProviders.OllamaServerURL from config
-	llm, err := ollama.New(ollama.WithServerURL(cfg.LLMProviders.OllamaServerURL), ollama.WithModel("qwen3:0.6b"), ollama.WithHTTPClient(ollamaHTTPClient))
-	if err != nil {
-		appLogger.Fatal("Failed to create LLM client", zap.Error(err))
-	}
-
 	// Connect to database
 	db, err := database.NewSQLXOracleDB(cfg.GetDSN())
 	if err != nil {
@@ -143,9 +134,19 @@ func main() {
 	quizRepository := repository.NewQuizDatabaseAdapter(db) // Renamed for clarity
 	userRepository := repository.NewSQLXUserRepository(db)
 	userQuizAttemptRepository := repository.NewSQLXUserQuizAttemptRepository(db)
-
-	// Initialize LLM evaluator
-	evaluatorService := evaluator.NewLLMEvaluator(llm)
+	attemptOutboxRepository := repository.NewSQLXAttemptOutboxRepository(db)
+	userSessionRepository := repository.NewSQLXUserSessionRepository(db)
+	userIdentityRepository := repository.NewSQLXUserIdentityRepository(db)
+	roleRepository := repository.NewSQLXRoleRepository(db)
+	categoryRepository := repository.NewCategoryDatabaseAdapter(db)
+	quizStatusRepository := repository.NewSQLXQuizStatusRepository(db)
+
+	// Initialize LLM evaluator from the configured provider
+	evaluatorService, err := evaluator.NewFromConfig(cfg.Evaluator)
+	if err != nil {
+		appLogger.Fatal("Failed to create answer evaluator", zap.Error(err))
+	}
+	appLogger.Info("Answer evaluator initialized", zap.String("provider", cfg.Evaluator.Provider))
 
 	appLogger.Info("RedisCacheAdapter initialized")
 
@@ -167,27 +168,54 @@ func main() {
 		txManager,
 		categoryListTTL,
 		quizListTTL,
+		cfg.CheckAnswer,
 	)
 	appLogger.Info("QuizService initialized")
 
-	authService, err := service.NewAuthService(userRepository, cfg.Auth, txManager) // Pass cfg.Auth
+	identityProviders, err := identity.NewFromConfig(cfg.Auth)
+	if err != nil {
+		appLogger.Fatal("Failed to configure identity providers", zap.Error(err))
+	}
+	appLogger.Info("Identity providers configured", zap.Int("count", len(identityProviders)))
+
+	authService, err := service.NewAuthService(userRepository, userIdentityRepository, userSessionRepository, roleRepository, cacheAdapter, identityProviders, cfg.Auth, txManager) // Pass cfg.Auth
 	if err != nil {
 		appLogger.Fatal("Failed to create AuthService", zap.Error(err))
 	}
 	appLogger.Info("AuthService initialized")
 
+	googleTokenRefresher := service.NewGoogleTokenRefresher(authService, cfg.Auth.GoogleOAuth.TokenRefresh)
+	appLogger.Info("GoogleTokenRefresher initialized", zap.Duration("interval", cfg.Auth.GoogleOAuth.TokenRefresh.Interval))
+
 	userService := service.NewUserService(userRepository, userQuizAttemptRepository, quizRepository, txManager) // Remove cfg
 	appLogger.Info("UserService initialized")
 
+	roleService := service.NewRoleService(userRepository, roleRepository)
+	contentService := service.NewContentService(quizRepository, categoryRepository, quizStatusRepository, quizService, txManager)
+	reviewService := service.NewReviewService(quizRepository, quizStatusRepository, contentService, quizService, txManager)
+
+	attemptOutboxSvc := service.NewAttemptOutboxService(attemptOutboxRepository, userQuizAttemptRepository, txManager, cfg.AttemptOutbox)
+	appLogger.Info("AttemptOutboxService initialized", zap.Duration("poll_interval", cfg.AttemptOutbox.PollInterval))
+
 	// Initialize AnonymousResultCacheService
 	anonymousResultCacheTTL := 5 * time.Minute // As specified in the subtask
 	anonymousResultCacheSvc := service.NewAnonymousResultCacheService(cacheAdapter, anonymousResultCacheTTL, txManager)
 	appLogger.Info("AnonymousResultCacheService initialized", zap.Duration("ttl", anonymousResultCacheTTL))
 
+	checkJobSvc := service.NewCheckJobService(quizService, attemptOutboxSvc, anonymousResultCacheSvc, cacheAdapter, cfg.CheckAnswer.Jobs)
+	appLogger.Info("CheckJobService initialized",
+		zap.Int("workers", cfg.CheckAnswer.Jobs.Workers),
+		zap.Int("queue_size", cfg.CheckAnswer.Jobs.QueueSize))
+
+	attemptClaimSvc := service.NewAttemptClaimService(anonymousResultCacheSvc, attemptOutboxSvc)
+
 	// Initialize handlers
-	quizHandler := handler.NewQuizHandler(quizService, userService, anonymousResultCacheSvc) // Added anonymousResultCacheSvc
-	authHandler := handler.NewAuthHandler(authService)                                       // Remove cfg
-	userHandler := handler.NewUserHandler(userService)
+	quizHandler := handler.NewQuizHandler(quizService, attemptOutboxSvc, anonymousResultCacheSvc, checkJobSvc) // Added anonymousResultCacheSvc
+	authHandler := handler.NewAuthHandler(authService, cfg.Auth)
+	userHandler := handler.NewUserHandler(userService, attemptClaimSvc)
+	roleHandler := handler.NewRoleHandler(roleService)
+	contentHandler := handler.NewContentHandler(contentService)
+	reviewHandler := handler.NewReviewHandler(reviewService)
 
 	// Initialize validation middleware
 	validationMiddleware := middleware.NewValidationMiddleware()
@@ -203,35 +231,81 @@ func main() {
 
 	// Add request logging middleware (remains the same)
 	app.Use(requestLogger())
-	app.Use(cors.New(cors.Config{AllowOrigins: "*", AllowMethods: "GET,POST,PUT,DELETE,OPTIONS", AllowHeaders: "Origin,Content-Type,Accept,Authorization", MaxAge: 300}))
+	app.Use(cors.New(middleware.CORSConfig(cfg.Auth)))
 	app.Use(recover.New())
 
 	// Swagger handler (remains the same)
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
+	// Public keys for verifying access tokens
+	app.Get("/.well-known/jwks.json", authHandler.JWKS)
+
 	// API group
 	apiGroup := app.Group("/api")
 
 	// Auth routes
 	authGroup := apiGroup.Group("/auth")
-	authGroup.Get("/google/login", authHandler.GoogleLogin)
-	authGroup.Get("/google/callback", authHandler.GoogleCallback)
+	authGroup.Get("/providers", authHandler.ListIdentityProviders)
+	authGroup.Get("/:provider/login", authHandler.Login)
+	authGroup.Get("/:provider/callback", authHandler.Callback)
 	authGroup.Post("/refresh", authHandler.RefreshToken)
 	authGroup.Post("/logout", middleware.Protected(authService), authHandler.Logout) // Protected logout
 
 	// User routes (all protected)
 	userGroup := apiGroup.Group("/users", middleware.Protected(authService))
 	userGroup.Get("/me", userHandler.GetMyProfile)
+	userGroup.Delete("/me", authHandler.DeleteMyAccount)
 	userGroup.Get("/me/attempts", userHandler.GetMyAttempts)
+	userGroup.Post("/me/attempts/claim", userHandler.ClaimMyAttempts)
+	userGroup.Get("/me/sessions", authHandler.ListMySessions)
+	userGroup.Delete("/me/sessions", authHandler.RevokeMyOtherSessions)
+	userGroup.Delete("/me/sessions/:id", authHandler.RevokeMySession)
+	userGroup.Get("/me/identities", authHandler.ListMyIdentities)
 	userGroup.Get("/me/incorrect-answers", userHandler.GetMyIncorrectAnswers)
 	userGroup.Get("/me/recommendations", userHandler.GetMyRecommendations)
 
+	// Admin routes (admin role required)
+	adminGroup := apiGroup.Group("/admin", middleware.Protected(authService), middleware.RequireRole(domain.RoleAdmin))
+	adminGroup.Get("/roles/:role/members", roleHandler.ListRoleMembers)
+	adminGroup.Post("/roles/:role/members", roleHandler.GrantRole)
+	adminGroup.Delete("/roles/:role/members", roleHandler.RevokeRole)
+	adminGroup.Get("/categories", contentHandler.ListCategories)
+	adminGroup.Post("/categories", contentHandler.CreateCategory)
+	adminGroup.Put("/categories/:id", contentHandler.UpdateCategory)
+	adminGroup.Delete("/categories/:id", contentHandler.DeleteCategory)
+	adminGroup.Get("/categories/:id/subcategories", contentHandler.ListSubCategories)
+	adminGroup.Post("/categories/:id/subcategories", contentHandler.CreateSubCategory)
+	adminGroup.Put("/subcategories/:id", contentHandler.UpdateSubCategory)
+	adminGroup.Delete("/subcategories/:id", contentHandler.DeleteSubCategory)
+	adminGroup.Get("/subcategories/:id/quizzes", contentHandler.ListQuizzes)
+	adminGroup.Post("/quizzes", contentHandler.CreateQuiz)
+	adminGroup.Get("/quizzes/:id", contentHandler.GetQuiz)
+	adminGroup.Put("/quizzes/:id", contentHandler.UpdateQuiz)
+	adminGroup.Delete("/quizzes/:id", contentHandler.DeleteQuiz)
+	adminGroup.Get("/quizzes/:id/revisions", contentHandler.ListQuizRevisions)
+	adminGroup.Get("/quizzes/:id/revisions/:revision/diff", contentHandler.DiffQuizRevision)
+	adminGroup.Post("/quizzes/:id/revisions/:revision/rollback", contentHandler.RollbackQuiz)
+	adminGroup.Get("/quizzes/:id/rubric", contentHandler.GetQuizRubric)
+	adminGroup.Put("/quizzes/:id/rubric", contentHandler.PutQuizRubric)
+	adminGroup.Delete("/quizzes/:id/rubric", contentHandler.DeleteQuizRubric)
+	adminGroup.Get("/content/export", contentHandler.ExportContent)
+	adminGroup.Post("/content/import", contentHandler.ImportContent)
+	adminGroup.Post("/quizzes/:id/status", reviewHandler.ChangeQuizStatus)
+	adminGroup.Get("/quizzes/:id/status-history", reviewHandler.GetQuizStatusHistory)
+	adminGroup.Get("/review/queue", reviewHandler.ListReviewQueue)
+	adminGroup.Post("/review/quizzes/:id/approve", reviewHandler.ApproveQuiz)
+	adminGroup.Post("/review/quizzes/:id/reject", reviewHandler.RejectQuiz)
+	adminGroup.Put("/review/quizzes/:id", reviewHandler.EditQuiz)
+
 	// Quiz and Category routes
 	apiGroup.Get("/categories", quizHandler.GetAllSubCategories) // Categories can remain public
 	// Apply OptionalAuth to routes that can be accessed by both authenticated and anonymous users
 	apiGroup.Get("/quiz", middleware.OptionalAuth(authService), validationMiddleware.ValidateSubCategory(), quizHandler.GetRandomQuiz)
 	apiGroup.Get("/quizzes", middleware.OptionalAuth(authService), validationMiddleware.ValidateBulkQuizzesParams(), quizHandler.GetBulkQuizzes)
 	apiGroup.Post("/quiz/check", middleware.OptionalAuth(authService), quizHandler.CheckAnswer) // Apply OptionalAuth here
+	apiGroup.Post("/quiz/check/stream", middleware.OptionalAuth(authService), quizHandler.CheckAnswerStream)
+	apiGroup.Get("/quiz/check/:jobId", middleware.OptionalAuth(authService), quizHandler.GetCheckJob)
+	apiGroup.Get("/quiz/:id/next", middleware.OptionalAuth(authService), quizHandler.GetNextQuiz)
 
 	// Start server (remains the same)
 	go func() {
@@ -251,5 +325,16 @@ func main() {
 	if err := app.ShutdownWithContext(ctx); err != nil {
 		appLogger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
+	// Queued grading jobs are finished after the server stops accepting new ones.
+	if err := checkJobSvc.Shutdown(ctx); err != nil {
+		appLogger.Error("Grading jobs interrupted by shutdown", zap.Error(err))
+	}
+	// Drained last, since the requests and grading jobs above queue attempts.
+	if err := attemptOutboxSvc.Shutdown(ctx); err != nil {
+		appLogger.Error("Attempt outbox drain interrupted by shutdown; pending attempts stay queued", zap.Error(err))
+	}
+	if err := googleTokenRefresher.Shutdown(ctx); err != nil {
+		appLogger.Error("Google token refresh interrupted by shutdown", zap.Error(err))
+	}
 	appLogger.Info("Server exited gracefully")
 }
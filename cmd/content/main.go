@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"time"
+
+	"quiz-byte/internal/adapter"
+	"quiz-byte/internal/cache"
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/database"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/logger"
+	"quiz-byte/internal/repository"
+	"quiz-byte/internal/service"
+
+	"go.uber.org/zap"
+)
+
+const usage = `Usage: go run -tags godror cmd/content/main.go <import|export> [flags] [file]
+
+  import [--dry-run] [--format=json|csv|yaml] <file>  Upsert the categories, subcategories, quizzes and rubrics in the file
+  export [--format=json|csv|yaml] [file]              Write all content to the file, or to stdout
+
+The format defaults to the file extension (.json, .csv, .yaml or .yml), then json.`
+
+// Imports and exports the category → subcategory → quiz → rubric tree, the same way as
+// /api/admin/content/import and /api/admin/content/export.
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+	cmd := os.Args[1]
+	flags := flag.NewFlagSet(cmd, flag.ExitOnError)
+	format := flags.String("format", "", "json, csv or yaml")
+	dryRun := flags.Bool("dry-run", false, "validate and report without saving (import only)")
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	_ = flags.Parse(os.Args[2:])
+	file := flags.Arg(0)
+	if *format == "" {
+		*format = service.ContentFormatFromFileName(file)
+	}
+	if *format == "" {
+		*format = service.ContentFormatJSON
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if err := logger.Initialize(cfg.Logger); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer logger.Sync()
+
+	db, err := database.NewSQLXOracleDB(cfg.GetDSN())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Imports invalidate the cached lists the API serves; without Redis they expire with their TTL.
+	var contentCache domain.Cache
+	if redisClient, err := cache.NewRedisClient(cfg.Redis); err != nil {
+		logger.Get().Warn("Failed to connect to Redis; cached quiz lists are not invalidated", zap.Error(err))
+	} else {
+		contentCache = adapter.NewRedisCacheAdapter(redisClient)
+	}
+
+	quizRepository := repository.NewQuizDatabaseAdapter(db)
+	txManager := repository.NewTransactionManagerAdapter(db)
+	quizService := service.NewQuizService(quizRepository, nil, contentCache, nil, nil, txManager,
+		cfg.ParseTTLStringOrDefault(cfg.CacheTTLs.CategoryList, 24*time.Hour),
+		cfg.ParseTTLStringOrDefault(cfg.CacheTTLs.QuizList, 1*time.Hour),
+		config.CheckAnswerConfig{}) // The CLI never checks answers
+	contentService := service.NewContentService(quizRepository, repository.NewCategoryDatabaseAdapter(db), repository.NewSQLXQuizStatusRepository(db), quizService, txManager)
+	ctx := context.Background()
+
+	switch cmd {
+	case "import":
+		if file == "" {
+			log.Fatalf("No file to import\n%s", usage)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatalf("Failed to read %s: %v", file, err)
+		}
+		report, err := contentService.ImportContent(ctx, data, *format, *dryRun, operator())
+		if err != nil {
+			log.Fatalf("Failed to import %s: %v", file, err)
+		}
+		printReport(report)
+		if len(report.Errors) > 0 {
+			os.Exit(1)
+		}
+
+	case "export":
+		data, err := contentService.ExportContent(ctx, *format)
+		if err != nil {
+			log.Fatalf("Failed to export content: %v", err)
+		}
+		if file == "" {
+			_, _ = os.Stdout.Write(data)
+			return
+		}
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			log.Fatalf("Failed to write %s: %v", file, err)
+		}
+		fmt.Printf("Exported content to %s\n", file)
+
+	default:
+		log.Fatalf("Unknown command: %s\n%s", cmd, usage)
+	}
+}
+
+func printReport(report *dto.ContentImportReport) {
+	if len(report.Errors) > 0 {
+		fmt.Printf("Nothing was imported; %d invalid rows:\n", len(report.Errors))
+		for _, rowErr := range report.Errors {
+			fmt.Printf("- %s: %s\n", rowErr.Row, rowErr.Message)
+		}
+		return
+	}
+	if report.DryRun {
+		fmt.Println("Dry run; nothing was saved. The import would make these changes:")
+	}
+	for _, line := range []struct {
+		name   string
+		counts dto.ImportCounts
+	}{
+		{"Categories", report.Categories},
+		{"Subcategories", report.SubCategories},
+		{"Quizzes", report.Quizzes},
+		{"Rubrics", report.Evaluations},
+	} {
+		fmt.Printf("%-14s created %d, updated %d, skipped %d\n", line.name+":", line.counts.Created, line.counts.Updated, line.counts.Skipped)
+	}
+}
+
+// operator describes who ran the command; it is recorded as the author of quiz revisions.
+func operator() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return "cli:" + current.Username
+	}
+	return "cli"
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"strings"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/database"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/logger"
+	"quiz-byte/internal/repository"
+	"quiz-byte/internal/service"
+)
+
+const usage = `Usage: go run -tags godror cmd/roles/main.go <grant|revoke|list> [email|user-id] [role]
+
+  grant <email|user-id> [role]   Grant a role (default "admin") to a user who has signed in once
+  revoke <email|user-id> [role]  Take a role from a user
+  list [role]                    List the users with a role
+
+A user is named by user ID when they signed in without a verified email, and so have none.`
+
+// Manages roles from the command line, e.g. to bootstrap the first admin, who can then manage
+// roles through /api/admin/roles.
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+	arg := func(i int, fallback string) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return fallback
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if err := logger.Initialize(cfg.Logger); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer logger.Sync()
+
+	db, err := database.NewSQLXOracleDB(cfg.GetDSN())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	roleService := service.NewRoleService(repository.NewSQLXUserRepository(db), repository.NewSQLXRoleRepository(db))
+	ctx := context.Background()
+
+	switch cmd {
+	case "grant":
+		name, role := arg(0, ""), arg(1, domain.RoleAdmin)
+		granted, err := roleService.GrantRole(ctx, member(name), role, operator())
+		if err != nil {
+			log.Fatalf("Failed to grant role %s to %s: %v", role, name, err)
+		}
+		if !granted {
+			fmt.Printf("%s already has the %s role\n", name, role)
+			return
+		}
+		fmt.Printf("Granted the %s role to %s; it applies from their next token refresh\n", role, name)
+
+	case "revoke":
+		name, role := arg(0, ""), arg(1, domain.RoleAdmin)
+		revoked, err := roleService.RevokeRole(ctx, member(name), role)
+		if err != nil {
+			log.Fatalf("Failed to revoke role %s of %s: %v", role, name, err)
+		}
+		if !revoked {
+			fmt.Printf("%s does not have the %s role\n", name, role)
+			return
+		}
+		fmt.Printf("Revoked the %s role of %s\n", role, name)
+
+	case "list":
+		role := arg(0, domain.RoleAdmin)
+		members, err := roleService.ListRoleMembers(ctx, role)
+		if err != nil {
+			log.Fatalf("Failed to list members of role %s: %v", role, err)
+		}
+		fmt.Printf("Users with the %s role:\n", role)
+		for _, member := range members.Members {
+			fmt.Printf("- %s (%s), granted by %s at %s\n", member.Email, member.UserID, member.GrantedBy, member.GrantedAt.Format("2006-01-02 15:04"))
+		}
+
+	default:
+		log.Fatalf("Unknown command: %s\n%s", cmd, usage)
+	}
+}
+
+// member names the user by email when name contains an @, and by user ID otherwise.
+func member(name string) dto.RoleMemberRequest {
+	if strings.Contains(name, "@") {
+		return dto.RoleMemberRequest{Email: name}
+	}
+	return dto.RoleMemberRequest{UserID: name}
+}
+
+// operator describes who ran the command; it is recorded as the grantor.
+func operator() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return "cli:" + current.Username
+	}
+	return "cli"
+}
@@ -0,0 +1,38 @@
+package seedmodels
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// MaxQuizKeyLength is the longest key the quiz_seeds table holds.
+const MaxQuizKeyLength = 255
+
+// QuizKey returns the key that identifies a seed quiz across runs of the seeder: its "key" when the
+// seed file gives one, else a hash of its category, subcategory and question. Give a key to quizzes
+// whose question may be reworded, so the reworded quiz updates the seeded one instead of replacing it.
+func QuizKey(categoryName, subCategoryName string, quiz SeedQuiz) string {
+	if key := strings.TrimSpace(quiz.Key); key != "" {
+		return key
+	}
+	return QuestionKey(categoryName, subCategoryName, quiz)
+}
+
+// QuestionKey returns the key a seed quiz has when the seed file gives it no "key". A quiz that was
+// given a key after it was seeded is still stored under this one until the seeder moves it.
+func QuestionKey(categoryName, subCategoryName string, quiz SeedQuiz) string {
+	return "sha256:" + hashFields(categoryName, subCategoryName, quiz.Question)
+}
+
+// ContentHash returns a hash of everything the seeder saves for a quiz, to tell when its entry changed.
+func ContentHash(categoryName, subCategoryName string, quiz SeedQuiz) string {
+	return hashFields(categoryName, subCategoryName, quiz.Question, quiz.ModelAnswers, quiz.Keywords, quiz.Difficulty)
+}
+
+func hashFields(fields ...interface{}) string {
+	data, _ := json.Marshal(fields) // Strings and string slices always marshal
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
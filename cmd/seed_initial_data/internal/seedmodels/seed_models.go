@@ -2,6 +2,7 @@ package seedmodels
 
 // SeedQuiz defines the structure for a quiz item in the JSON seed file.
 type SeedQuiz struct {
+	Key          string   `json:"key,omitempty"` // Optional stable identity; see QuizKey
 	Question     string   `json:"question"`
 	ModelAnswers []string `json:"model_answers"`
 	Keywords     []string `json:"keywords"`
@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
+	"slices"
 
 	"quiz-byte/cmd/seed_initial_data/internal/seedmodels"
 	"quiz-byte/internal/config"
@@ -20,6 +22,9 @@ import (
 const (
 	categorySeedFilePath = "configs/seed_data/category.json"
 	quizSeedFilePath     = "configs/seed_data/initial_english_quizzes.json"
+
+	// seedAuthor is recorded as the author of the quiz revisions the seeder saves
+	seedAuthor = "seeder"
 )
 
 func firstN(s string, n int) string {
@@ -30,6 +35,8 @@ func firstN(s string, n int) string {
 }
 
 func main() {
+	prune := flag.Bool("prune", false, "soft-delete seeded quizzes that are no longer in the seed file, and duplicates of seeded quizzes")
+	flag.Parse()
 	ctx := context.Background()
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -63,7 +70,7 @@ func main() {
 
 	// Step 2: Create subcategories and quizzes from initial_english_quizzes.json
 	log.Info("Step 2: Creating subcategories and quizzes", zap.String("path", quizSeedFilePath))
-	if err := seedQuizData(ctx, db, log); err != nil {
+	if err := seedQuizData(ctx, db, log, *prune); err != nil {
 		log.Fatal("Failed to seed quiz data", zap.Error(err))
 	}
 
@@ -114,7 +121,12 @@ func seedBasicCategories(ctx context.Context, db *sqlx.DB, log *zap.Logger) erro
 	return nil
 }
 
-func seedQuizData(ctx context.Context, db *sqlx.DB, log *zap.Logger) error {
+// seedStats counts what the quiz seeding did.
+type seedStats struct {
+	Created, Updated, Unchanged, Pruned int
+}
+
+func seedQuizData(ctx context.Context, db *sqlx.DB, log *zap.Logger, prune bool) error {
 	log.Info("Loading quiz data from file", zap.String("path", quizSeedFilePath))
 	byteValue, err := os.ReadFile(quizSeedFilePath)
 	if err != nil {
@@ -127,28 +139,97 @@ func seedQuizData(ctx context.Context, db *sqlx.DB, log *zap.Logger) error {
 	}
 	log.Info("Successfully loaded quiz data", zap.Int("categories_count", len(seedCategories)))
 
+	// Every quiz needs a unique key before anything is saved, or two entries would update the same quiz
+	seedKeys := map[string]bool{}
+	for _, sc := range seedCategories {
+		for _, ssc := range sc.SubCategories {
+			for _, sq := range ssc.Quizzes {
+				key := seedmodels.QuizKey(sc.Name, ssc.Name, sq)
+				if len(key) > seedmodels.MaxQuizKeyLength {
+					return fmt.Errorf("key %q of quiz '%s' is longer than %d characters", firstN(key, 50), firstN(sq.Question, 50), seedmodels.MaxQuizKeyLength)
+				}
+				if seedKeys[key] {
+					return fmt.Errorf("more than one quiz has the key %q (quiz '%s'); give them different keys or questions", key, firstN(sq.Question, 50))
+				}
+				seedKeys[key] = true
+			}
+		}
+	}
+
+	seeds, err := repository.NewSQLXQuizSeedRepository(db).ListQuizSeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load seeded quizzes: %w", err)
+	}
+	seedsByKey := make(map[string]*domain.QuizSeed, len(seeds))
+	seededQuizIDs := make(map[string]bool, len(seeds))
+	for _, seed := range seeds {
+		seedsByKey[seed.Key] = seed
+		seededQuizIDs[seed.QuizID] = true
+	}
+
+	var stats seedStats
+	var duplicateQuizIDs []string
+	failed := false
 	for _, sc := range seedCategories {
-		err := seedCategoryData(ctx, db, log, sc)
+		duplicates, err := seedCategoryData(ctx, db, log, sc, seedKeys, seedsByKey, seededQuizIDs, &stats)
 		if err != nil {
 			log.Error("Error seeding category data, transaction rolled back", zap.String("category", sc.Name), zap.Error(err))
+			failed = true
 			// Continue with other categories instead of stopping
+			continue
+		}
+		duplicateQuizIDs = append(duplicateQuizIDs, duplicates...)
+	}
+
+	var staleQuizIDs []string
+	for _, seed := range seeds {
+		if !seedKeys[seed.Key] {
+			staleQuizIDs = append(staleQuizIDs, seed.QuizID)
 		}
 	}
+	switch {
+	case !prune:
+		if len(staleQuizIDs)+len(duplicateQuizIDs) > 0 {
+			log.Info("Run with --prune to delete the seeded quizzes no longer in the seed file and the duplicates of seeded quizzes",
+				zap.Int("stale", len(staleQuizIDs)), zap.Int("duplicates", len(duplicateQuizIDs)))
+		}
+	case failed:
+		// A failed category's quizzes were not seeded, so its duplicates are unknown
+		log.Warn("Skipping pruning because some categories failed to seed")
+	default:
+		if err := pruneQuizzes(ctx, db, append(staleQuizIDs, duplicateQuizIDs...)); err != nil {
+			return err
+		}
+		stats.Pruned = len(staleQuizIDs) + len(duplicateQuizIDs)
+	}
 
+	log.Info("Seeded quizzes", zap.Int("created", stats.Created), zap.Int("updated", stats.Updated),
+		zap.Int("unchanged", stats.Unchanged), zap.Int("pruned", stats.Pruned))
 	return nil
 }
 
+// seedCategoryData upserts the subcategories and quizzes of a seed category in one transaction.
+// A quiz is matched by its seed key, or by the key of its question when it was seeded before the seed
+// file gave it a key; quizzes seeded before quizzes had keys are matched by question within their
+// subcategory and adopted. seedKeys holds the keys of all entries of the seed file. It returns the other unseeded quizzes with a seeded question,
+// which earlier runs of the seeder duplicated.
 func seedCategoryData(
 	ctx context.Context,
 	db *sqlx.DB, // The seeder uses the main DB connection to start a transaction
 	log *zap.Logger,
 	seedCat seedmodels.SeedCategory,
-) (err error) { // Named return for clarity in defer
+	seedKeys map[string]bool,
+	seedsByKey map[string]*domain.QuizSeed,
+	seededQuizIDs map[string]bool,
+	stats *seedStats,
+) (duplicateQuizIDs []string, err error) { // Named return for clarity in defer
 	log.Info("Processing category", zap.String("name", seedCat.Name))
 	tx, err := db.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction for category %s: %w", seedCat.Name, err)
+		return nil, fmt.Errorf("failed to begin transaction for category %s: %w", seedCat.Name, err)
 	}
+	// Counted into stats only once the transaction commits
+	var categoryStats seedStats
 	// Defer a function to handle transaction rollback or commit
 	defer func() {
 		if p := recover(); p != nil {
@@ -167,6 +248,9 @@ func seedCategoryData(
 				err = cErr // Set err so the calling function knows about the commit failure
 			} else {
 				log.Info("Successfully committed transaction for category", zap.String("name", seedCat.Name))
+				stats.Created += categoryStats.Created
+				stats.Updated += categoryStats.Updated
+				stats.Unchanged += categoryStats.Unchanged
 			}
 		}
 	}()
@@ -174,14 +258,15 @@ func seedCategoryData(
 	// Repositories are created with the transaction object (tx which implements DBTX)
 	txCategoryRepo := repository.NewCategoryDatabaseAdapter(tx)
 	txQuizRepo := repository.NewQuizDatabaseAdapter(tx)
+	txSeedRepo := repository.NewSQLXQuizSeedRepository(tx)
 
 	// Check if category exists (it should exist from step 1)
 	dbCategory, err := txCategoryRepo.GetByName(ctx, seedCat.Name)
 	if err != nil {
-		return fmt.Errorf("error checking category %s: %w", seedCat.Name, err) // Propagate error to trigger rollback
+		return nil, fmt.Errorf("error checking category %s: %w", seedCat.Name, err) // Propagate error to trigger rollback
 	}
 	if dbCategory == nil {
-		return fmt.Errorf("category %s not found - should have been created in step 1", seedCat.Name)
+		return nil, fmt.Errorf("category %s not found - should have been created in step 1", seedCat.Name)
 	}
 	log.Info("Found existing category.", zap.String("id", dbCategory.ID), zap.String("name", dbCategory.Name))
 
@@ -189,29 +274,118 @@ func seedCategoryData(
 		log.Info("Processing sub-category", zap.String("name", seedSubCat.Name), zap.String("parent_category", dbCategory.Name))
 		dbSubCategory, errSub := txCategoryRepo.GetByNameAndCategoryID(ctx, seedSubCat.Name, dbCategory.ID)
 		if errSub != nil {
-			return fmt.Errorf("error checking sub-category %s: %w", seedSubCat.Name, errSub) // Propagate error
+			return nil, fmt.Errorf("error checking sub-category %s: %w", seedSubCat.Name, errSub) // Propagate error
 		}
 		if dbSubCategory == nil {
 			log.Info("Sub-category not found, creating.", zap.String("name", seedSubCat.Name))
 			dbSubCategory = domain.NewSubCategory(dbCategory.ID, seedSubCat.Name, seedSubCat.Description)
 			if errSub = txCategoryRepo.SaveSubCategory(ctx, dbSubCategory); errSub != nil {
-				return fmt.Errorf("failed to save sub-category %s: %w", seedSubCat.Name, errSub) // Propagate error
+				return nil, fmt.Errorf("failed to save sub-category %s: %w", seedSubCat.Name, errSub) // Propagate error
 			}
 			log.Info("Created sub-category.", zap.String("id", dbSubCategory.ID), zap.String("name", dbSubCategory.Name))
 		} else {
 			log.Info("Sub-category exists.", zap.String("id", dbSubCategory.ID), zap.String("name", dbSubCategory.Name))
 		}
 
+		// Quizzes without a seed, oldest first, by question
+		dbQuizzes, errQ := txQuizRepo.GetQuizzesBySubCategory(ctx, dbSubCategory.ID)
+		if errQ != nil {
+			return nil, fmt.Errorf("failed to list quizzes of sub-category %s: %w", seedSubCat.Name, errQ)
+		}
+		unseeded := map[string][]*domain.Quiz{}
+		for i := len(dbQuizzes) - 1; i >= 0; i-- {
+			if !seededQuizIDs[dbQuizzes[i].ID] {
+				unseeded[dbQuizzes[i].Question] = append(unseeded[dbQuizzes[i].Question], dbQuizzes[i])
+			}
+		}
+
 		for _, seedQuiz := range seedSubCat.Quizzes {
 			log.Info("Processing quiz", zap.String("question_preview", firstN(seedQuiz.Question, 20)), zap.String("parent_sub_category", dbSubCategory.Name))
+			seed := &domain.QuizSeed{
+				Key:         seedmodels.QuizKey(seedCat.Name, seedSubCat.Name, seedQuiz),
+				ContentHash: seedmodels.ContentHash(seedCat.Name, seedSubCat.Name, seedQuiz),
+			}
+
+			existing := seedsByKey[seed.Key]
+			// Unless another entry still has that question key, an entry given a key after it was seeded takes over its seed
+			if questionKey := seedmodels.QuestionKey(seedCat.Name, seedSubCat.Name, seedQuiz); existing == nil && !seedKeys[questionKey] {
+				if existing = seedsByKey[questionKey]; existing != nil {
+					if errQ = txSeedRepo.RekeyQuizSeed(ctx, questionKey, seed.Key); errQ != nil {
+						return nil, fmt.Errorf("failed to rekey the seed of quiz %s: %w", existing.QuizID, errQ)
+					}
+					delete(seedsByKey, questionKey)
+					existing.Key = seed.Key // So pruning does not take the seed for a stale one
+					seedsByKey[seed.Key] = existing
+					log.Info("Moved the seed of a quiz to its new key.", zap.String("id", existing.QuizID), zap.String("key", seed.Key))
+				}
+			}
+
+			var dbQuiz *domain.Quiz
+			if existing != nil {
+				if existing.ContentHash == seed.ContentHash {
+					// Unchanged since it was seeded; edits made by admins since are kept
+					categoryStats.Unchanged++
+					continue
+				}
+				if dbQuiz, errQ = txQuizRepo.GetQuizByID(ctx, existing.QuizID); errQ != nil {
+					return nil, fmt.Errorf("failed to get seeded quiz %s: %w", existing.QuizID, errQ)
+				}
+			} else if candidates := unseeded[seedQuiz.Question]; len(candidates) > 0 {
+				dbQuiz, unseeded[seedQuiz.Question] = candidates[0], candidates[1:]
+				log.Info("Adopting quiz seeded before quizzes had keys.", zap.String("id", dbQuiz.ID), zap.String("key", seed.Key))
+			}
+
 			difficultyInt := domain.DifficultyToInt(seedQuiz.Difficulty)
-			// Assuming NewQuiz returns *domain.Quiz
-			domainQuiz := domain.NewQuiz(seedQuiz.Question, seedQuiz.ModelAnswers, seedQuiz.Keywords, difficultyInt, dbSubCategory.ID)
-			if errQ := txQuizRepo.SaveQuiz(ctx, domainQuiz); errQ != nil {
-				return fmt.Errorf("failed to save quiz '%s': %w", firstN(seedQuiz.Question, 50), errQ) // Propagate error
+			switch {
+			case dbQuiz == nil:
+				dbQuiz = domain.NewQuiz(seedQuiz.Question, seedQuiz.ModelAnswers, seedQuiz.Keywords, difficultyInt, dbSubCategory.ID)
+				if errQ = txQuizRepo.SaveQuiz(ctx, dbQuiz); errQ != nil {
+					return nil, fmt.Errorf("failed to save quiz '%s': %w", firstN(seedQuiz.Question, 50), errQ) // Propagate error
+				}
+				categoryStats.Created++
+				log.Info("Successfully created quiz.", zap.String("id", dbQuiz.ID)) // ID should be populated by SaveQuiz
+			case dbQuiz.Question == seedQuiz.Question && slices.Equal(dbQuiz.ModelAnswers, seedQuiz.ModelAnswers) &&
+				slices.Equal(dbQuiz.Keywords, seedQuiz.Keywords) && dbQuiz.Difficulty == difficultyInt && dbQuiz.SubCategoryID == dbSubCategory.ID:
+				categoryStats.Unchanged++
+			default:
+				dbQuiz.Question, dbQuiz.ModelAnswers, dbQuiz.Keywords = seedQuiz.Question, seedQuiz.ModelAnswers, seedQuiz.Keywords
+				dbQuiz.Difficulty, dbQuiz.SubCategoryID = difficultyInt, dbSubCategory.ID
+				if errQ = txQuizRepo.UpdateQuiz(ctx, dbQuiz, seedAuthor); errQ != nil {
+					return nil, fmt.Errorf("failed to update quiz %s: %w", dbQuiz.ID, errQ)
+				}
+				categoryStats.Updated++
+				log.Info("Updated quiz from the seed file.", zap.String("id", dbQuiz.ID), zap.Int("revision", dbQuiz.Revision))
+			}
+
+			seed.QuizID = dbQuiz.ID
+			if errQ = txSeedRepo.SaveQuizSeed(ctx, seed); errQ != nil {
+				return nil, fmt.Errorf("failed to save the seed of quiz %s: %w", dbQuiz.ID, errQ)
 			}
-			log.Info("Successfully created quiz.", zap.String("id", domainQuiz.ID)) // ID should be populated by SaveQuiz
+		}
+
+		// What is left of the questions in the seed file are duplicates of seeded quizzes
+		for _, seedQuiz := range seedSubCat.Quizzes {
+			for _, quiz := range unseeded[seedQuiz.Question] {
+				duplicateQuizIDs = append(duplicateQuizIDs, quiz.ID)
+			}
+			delete(unseeded, seedQuiz.Question)
 		}
 	}
-	return nil // Mark success for commit
+	return duplicateQuizIDs, nil // Mark success for commit
+}
+
+// pruneQuizzes soft-deletes the quizzes and their rubrics in one transaction.
+func pruneQuizzes(ctx context.Context, db *sqlx.DB, quizIDs []string) error {
+	quizRepo := repository.NewQuizDatabaseAdapter(db)
+	return repository.NewTransactionManagerAdapter(db).WithTransaction(ctx, func(ctx context.Context) error {
+		for _, quizID := range quizIDs {
+			if _, err := quizRepo.DeleteQuiz(ctx, quizID); err != nil {
+				return fmt.Errorf("failed to prune quiz %s: %w", quizID, err)
+			}
+			if _, err := quizRepo.DeleteQuizEvaluation(ctx, quizID); err != nil {
+				return fmt.Errorf("failed to prune the rubric of quiz %s: %w", quizID, err)
+			}
+		}
+		return nil
+	})
 }
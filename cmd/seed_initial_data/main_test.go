@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"quiz-byte/cmd/seed_initial_data/internal/seedmodels"
+	"quiz-byte/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func setupSeederTestDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	return sqlx.NewDb(mockDB, "sqlmock"), mock
+}
+
+var seedTestQuiz = seedmodels.SeedQuiz{
+	Question:     "What is a hash table?",
+	ModelAnswers: []string{"A map from keys to values"},
+	Keywords:     []string{"hash", "bucket"},
+	Difficulty:   "easy",
+}
+
+// expectSubCategoryQuizzes expects the lookups of the "Data Structures" category and its "Basics"
+// subcategory, whose quizzes are quizIDs, all with seedTestQuiz's content.
+func expectSubCategoryQuizzes(mock sqlmock.Sqlmock, quizIDs ...string) {
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM categories WHERE name = :1`)).
+		WithArgs("Data Structures").
+		WillReturnRows(sqlmock.NewRows([]string{"ID", "NAME", "DESCRIPTION", "CREATED_AT", "UPDATED_AT"}).
+			AddRow("cat1", "Data Structures", nil, now, now))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM sub_categories WHERE name = :1 AND category_id = :2`)).
+		WithArgs("Basics", "cat1").
+		WillReturnRows(sqlmock.NewRows([]string{"ID", "CATEGORY_ID", "NAME", "DESCRIPTION", "CREATED_AT", "UPDATED_AT"}).
+			AddRow("sub1", "cat1", "Basics", nil, now, now))
+	rows := sqlmock.NewRows([]string{"ID", "QUESTION", "MODEL_ANSWERS", "KEYWORDS", "DIFFICULTY", "SUB_CATEGORY_ID", "REVISION", "STATUS", "CREATED_AT", "UPDATED_AT", "DELETED_AT"})
+	for _, id := range quizIDs {
+		rows.AddRow(id, seedTestQuiz.Question, "A map from keys to values", "hash|||bucket", 1, "sub1", 1, domain.QuizStatusPublished, now, now, nil)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM quizzes`) + `\s+` + regexp.QuoteMeta(`WHERE sub_category_id = :1`)).
+		WithArgs("sub1").
+		WillReturnRows(rows)
+}
+
+func seedTestCategory(quizzes ...seedmodels.SeedQuiz) seedmodels.SeedCategory {
+	return seedmodels.SeedCategory{
+		Name:          "Data Structures",
+		SubCategories: []seedmodels.SeedSubCategory{{Name: "Basics", Quizzes: quizzes}},
+	}
+}
+
+func TestSeedCategoryData_KeyGivenAfterSeeding(t *testing.T) {
+	db, mock := setupSeederTestDB(t)
+	defer db.Close()
+
+	keyed := seedTestQuiz
+	keyed.Key = "hash-tables"
+	questionKey := seedmodels.QuestionKey("Data Structures", "Basics", seedTestQuiz)
+	seed := &domain.QuizSeed{Key: questionKey, QuizID: "quiz1", ContentHash: seedmodels.ContentHash("Data Structures", "Basics", keyed)}
+	seedsByKey := map[string]*domain.QuizSeed{questionKey: seed}
+
+	mock.ExpectBegin()
+	expectSubCategoryQuizzes(mock, "quiz1")
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM quiz_seeds WHERE seed_key = :1`)).
+		WithArgs("hash-tables").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE quiz_seeds SET seed_key = :1 WHERE seed_key = :2`)).
+		WithArgs("hash-tables", questionKey).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var stats seedStats
+	duplicates, err := seedCategoryData(context.Background(), db, zap.NewNop(), seedTestCategory(keyed),
+		map[string]bool{"hash-tables": true}, seedsByKey, map[string]bool{"quiz1": true}, &stats)
+	assert.NoError(t, err)
+	assert.Empty(t, duplicates, "the seeded quiz must not be taken for a duplicate")
+	assert.Equal(t, seedStats{Unchanged: 1}, stats)
+	assert.Equal(t, "hash-tables", seed.Key, "the seed must not be pruned as stale")
+	assert.Same(t, seed, seedsByKey["hash-tables"])
+	assert.NotContains(t, seedsByKey, questionKey)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeedCategoryData_QuestionKeyStillInSeedFile(t *testing.T) {
+	db, mock := setupSeederTestDB(t)
+	defer db.Close()
+
+	keyed := seedTestQuiz
+	keyed.Key = "hash-tables"
+	questionKey := seedmodels.QuestionKey("Data Structures", "Basics", seedTestQuiz)
+	seed := &domain.QuizSeed{Key: questionKey, QuizID: "quiz1", ContentHash: seedmodels.ContentHash("Data Structures", "Basics", seedTestQuiz)}
+	seedsByKey := map[string]*domain.QuizSeed{questionKey: seed}
+
+	// The keyless entry keeps its seed, so the keyed one adopts the unseeded copy of the question
+	mock.ExpectBegin()
+	expectSubCategoryQuizzes(mock, "quiz2", "quiz1")
+	mock.ExpectExec(regexp.QuoteMeta(`MERGE INTO quiz_seeds s`)).
+		WithArgs("hash-tables", "quiz2", sqlmock.AnyArg(), sqlmock.AnyArg(), "quiz2", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var stats seedStats
+	duplicates, err := seedCategoryData(context.Background(), db, zap.NewNop(), seedTestCategory(seedTestQuiz, keyed),
+		map[string]bool{questionKey: true, "hash-tables": true}, seedsByKey, map[string]bool{"quiz1": true}, &stats)
+	assert.NoError(t, err)
+	assert.Empty(t, duplicates)
+	assert.Equal(t, seedStats{Unchanged: 2}, stats)
+	assert.Equal(t, questionKey, seed.Key)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeedCategoryData_Duplicates(t *testing.T) {
+	db, mock := setupSeederTestDB(t)
+	defer db.Close()
+
+	questionKey := seedmodels.QuestionKey("Data Structures", "Basics", seedTestQuiz)
+	seedsByKey := map[string]*domain.QuizSeed{questionKey: {
+		Key: questionKey, QuizID: "quiz1", ContentHash: seedmodels.ContentHash("Data Structures", "Basics", seedTestQuiz),
+	}}
+
+	mock.ExpectBegin()
+	expectSubCategoryQuizzes(mock, "quiz3", "quiz2", "quiz1")
+	mock.ExpectCommit()
+
+	var stats seedStats
+	duplicates, err := seedCategoryData(context.Background(), db, zap.NewNop(), seedTestCategory(seedTestQuiz),
+		map[string]bool{questionKey: true}, seedsByKey, map[string]bool{"quiz1": true}, &stats)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"quiz2", "quiz3"}, duplicates)
+	assert.Equal(t, seedStats{Unchanged: 1}, stats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeedCategoryData_RollsBackOnError(t *testing.T) {
+	db, mock := setupSeederTestDB(t)
+	defer db.Close()
+
+	keyed := seedTestQuiz
+	keyed.Key = "hash-tables"
+	questionKey := seedmodels.QuestionKey("Data Structures", "Basics", seedTestQuiz)
+	seedsByKey := map[string]*domain.QuizSeed{questionKey: {Key: questionKey, QuizID: "quiz1"}}
+
+	mock.ExpectBegin()
+	expectSubCategoryQuizzes(mock, "quiz1")
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM quiz_seeds WHERE seed_key = :1`)).
+		WillReturnError(errors.New("db down"))
+	mock.ExpectRollback()
+
+	var stats seedStats
+	_, err := seedCategoryData(context.Background(), db, zap.NewNop(), seedTestCategory(keyed),
+		map[string]bool{"hash-tables": true}, seedsByKey, map[string]bool{"quiz1": true}, &stats)
+	assert.ErrorContains(t, err, "failed to rekey the seed of quiz quiz1")
+	assert.Equal(t, seedStats{}, stats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPruneQuizzes(t *testing.T) {
+	deleteQuiz := regexp.QuoteMeta(`UPDATE quizzes SET deleted_at = :1, updated_at = :2 WHERE id = :3 AND deleted_at IS NULL`)
+	deleteRubric := regexp.QuoteMeta(`UPDATE quiz_evaluations SET deleted_at = :1, updated_at = :2 WHERE quiz_id = :3 AND deleted_at IS NULL`)
+
+	t.Run("Success", func(t *testing.T) {
+		db, mock := setupSeederTestDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		for _, id := range []string{"quiz1", "quiz2"} {
+			mock.ExpectExec(deleteQuiz).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), id).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(deleteRubric).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), id).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		mock.ExpectCommit()
+
+		assert.NoError(t, pruneQuizzes(context.Background(), db, []string{"quiz1", "quiz2"}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolls Back On Error", func(t *testing.T) {
+		db, mock := setupSeederTestDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteQuiz).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "quiz1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(deleteRubric).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "quiz1").WillReturnError(errors.New("db down"))
+		mock.ExpectRollback()
+
+		err := pruneQuizzes(context.Background(), db, []string{"quiz1", "quiz2"})
+		assert.ErrorContains(t, err, "failed to prune the rubric of quiz quiz1")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,32 @@
+-- +migrate Up
+CREATE TABLE attempt_outbox (
+    id VARCHAR2(26) PRIMARY KEY,
+    idempotency_key VARCHAR2(255) NOT NULL,
+    user_id VARCHAR2(26) NOT NULL,
+    quiz_id VARCHAR2(26) NOT NULL,
+    payload CLOB NOT NULL,
+    status VARCHAR2(20) DEFAULT 'pending' NOT NULL,
+    tries NUMBER(5) DEFAULT 0 NOT NULL,
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP NOT NULL,
+    last_error VARCHAR2(1000),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+    processed_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT uq_attempt_outbox_idem_key UNIQUE (idempotency_key)
+);
+CREATE INDEX idx_attempt_outbox_due ON attempt_outbox(status, next_attempt_at);
+
+-- +migrate StatementBegin
+CREATE OR REPLACE TRIGGER attempt_outbox_updated_at_trigger
+BEFORE UPDATE ON attempt_outbox
+FOR EACH ROW
+BEGIN
+    :NEW.updated_at := SYSTIMESTAMP;
+END;
+/
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TRIGGER attempt_outbox_updated_at_trigger;
+DROP INDEX idx_attempt_outbox_due;
+DROP TABLE attempt_outbox;
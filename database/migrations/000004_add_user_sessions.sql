@@ -0,0 +1,29 @@
+-- +migrate Up
+CREATE TABLE user_sessions (
+    id VARCHAR2(26) PRIMARY KEY,
+    user_id VARCHAR2(26) NOT NULL,
+    current_jti VARCHAR2(64) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_reason VARCHAR2(50),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+    CONSTRAINT fk_user_sessions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
+
+-- +migrate StatementBegin
+CREATE OR REPLACE TRIGGER user_sessions_updated_at_trigger
+BEFORE UPDATE ON user_sessions
+FOR EACH ROW
+BEGIN
+    :NEW.updated_at := SYSTIMESTAMP;
+END;
+/
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TRIGGER user_sessions_updated_at_trigger;
+DROP INDEX idx_user_sessions_user_id;
+DROP TABLE user_sessions;
@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE user_sessions ADD (
+    device VARCHAR2(100),
+    user_agent VARCHAR2(512),
+    ip_address VARCHAR2(64)
+);
+CREATE INDEX idx_user_sessions_active ON user_sessions(user_id, revoked_at, expires_at);
+
+-- +migrate Down
+DROP INDEX idx_user_sessions_active;
+ALTER TABLE user_sessions DROP (device, user_agent, ip_address);
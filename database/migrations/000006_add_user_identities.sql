@@ -0,0 +1,43 @@
+-- +migrate Up
+CREATE TABLE user_identities (
+    id VARCHAR2(26) PRIMARY KEY,
+    user_id VARCHAR2(26) NOT NULL,
+    provider VARCHAR2(50) NOT NULL,
+    subject VARCHAR2(255) NOT NULL,
+    email VARCHAR2(255),
+    last_login_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+    CONSTRAINT uq_user_identities_subject UNIQUE (provider, subject),
+    CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
+
+-- Existing users signed up with Google; the user ID doubles as the ID of their Google identity.
+INSERT INTO user_identities (id, user_id, provider, subject, email, last_login_at, created_at, updated_at)
+SELECT id, id, 'google', google_id, email, updated_at, created_at, updated_at
+FROM users
+WHERE deleted_at IS NULL AND google_id IS NOT NULL;
+
+-- google_id is superseded by user_identities and no longer written for new users.
+ALTER TABLE users MODIFY (google_id NULL);
+
+-- +migrate StatementBegin
+CREATE OR REPLACE TRIGGER user_identities_updated_at_trigger
+BEFORE UPDATE ON user_identities
+FOR EACH ROW
+BEGIN
+    :NEW.updated_at := SYSTIMESTAMP;
+END;
+/
+-- +migrate StatementEnd
+
+-- +migrate Down
+UPDATE users u SET google_id = COALESCE(
+    (SELECT i.subject FROM user_identities i WHERE i.user_id = u.id AND i.provider = 'google'),
+    'unlinked:' || u.id)
+WHERE google_id IS NULL;
+ALTER TABLE users MODIFY (google_id NOT NULL);
+DROP TRIGGER user_identities_updated_at_trigger;
+DROP INDEX idx_user_identities_user_id;
+DROP TABLE user_identities;
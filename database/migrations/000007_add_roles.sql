@@ -0,0 +1,24 @@
+-- +migrate Up
+CREATE TABLE roles (
+    name VARCHAR2(50) PRIMARY KEY,
+    description VARCHAR2(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP
+);
+
+CREATE TABLE user_roles (
+    user_id VARCHAR2(26) NOT NULL,
+    role_name VARCHAR2(50) NOT NULL,
+    granted_by VARCHAR2(255),
+    granted_at TIMESTAMP WITH TIME ZONE DEFAULT SYSTIMESTAMP,
+    CONSTRAINT pk_user_roles PRIMARY KEY (user_id, role_name),
+    CONSTRAINT fk_user_roles_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_roles_role FOREIGN KEY (role_name) REFERENCES roles(name) ON DELETE CASCADE
+);
+CREATE INDEX idx_user_roles_role_name ON user_roles(role_name);
+
+INSERT INTO roles (name, description) VALUES ('admin', 'Manages quizzes, categories, users and batch jobs');
+
+-- +migrate Down
+DROP INDEX idx_user_roles_role_name;
+DROP TABLE user_roles;
+DROP TABLE roles;
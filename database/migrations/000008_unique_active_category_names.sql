@@ -0,0 +1,8 @@
+-- +migrate Up
+-- Soft deleted categories keep their row; only active categories need unique names.
+ALTER TABLE categories DROP UNIQUE (name);
+CREATE UNIQUE INDEX uq_categories_active_name ON categories (CASE WHEN deleted_at IS NULL THEN name END);
+
+-- +migrate Down
+DROP INDEX uq_categories_active_name;
+ALTER TABLE categories ADD UNIQUE (name);
@@ -0,0 +1,29 @@
+-- +migrate Up
+-- Every edit of a quiz is kept as a numbered snapshot so past attempts can be traced to the text they were graded against.
+ALTER TABLE quizzes ADD (revision NUMBER(10) DEFAULT 1 NOT NULL);
+
+CREATE TABLE quiz_revisions (
+    quiz_id VARCHAR2(26) NOT NULL,
+    revision NUMBER(10) NOT NULL,
+    question CLOB NOT NULL,
+    model_answers CLOB NOT NULL,
+    keywords CLOB NOT NULL,
+    difficulty NUMBER(1) NOT NULL,
+    sub_category_id VARCHAR2(26) NOT NULL,
+    author VARCHAR2(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT pk_quiz_revisions PRIMARY KEY (quiz_id, revision),
+    CONSTRAINT fk_quiz_revisions_quiz FOREIGN KEY (quiz_id) REFERENCES quizzes(id)
+);
+
+-- Existing quizzes start at revision 1; their author is unknown.
+INSERT INTO quiz_revisions (quiz_id, revision, question, model_answers, keywords, difficulty, sub_category_id, created_at)
+SELECT id, 1, question, model_answers, keywords, difficulty, sub_category_id, updated_at FROM quizzes;
+
+-- Attempts recorded before this migration have no revision.
+ALTER TABLE user_quiz_attempts ADD (quiz_revision NUMBER(10));
+
+-- +migrate Down
+ALTER TABLE user_quiz_attempts DROP COLUMN quiz_revision;
+DROP TABLE quiz_revisions;
+ALTER TABLE quizzes DROP COLUMN revision;
@@ -0,0 +1,24 @@
+-- +migrate Up
+-- Only published quizzes are served to learners; quizzes that existed before the review workflow stay published.
+ALTER TABLE quizzes ADD (status VARCHAR2(20) DEFAULT 'published' NOT NULL);
+ALTER TABLE quizzes ADD CONSTRAINT chk_quizzes_status CHECK (status IN ('draft', 'in_review', 'published', 'retired'));
+CREATE INDEX idx_quizzes_sub_category_status ON quizzes(sub_category_id, status);
+
+CREATE TABLE quiz_status_changes (
+    id VARCHAR2(26) PRIMARY KEY,
+    quiz_id VARCHAR2(26) NOT NULL,
+    from_status VARCHAR2(20) NOT NULL,
+    to_status VARCHAR2(20) NOT NULL,
+    actor VARCHAR2(255),
+    review_comment VARCHAR2(4000),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT fk_quiz_status_changes_quiz FOREIGN KEY (quiz_id) REFERENCES quizzes(id)
+);
+CREATE INDEX idx_quiz_status_changes_quiz_id ON quiz_status_changes(quiz_id);
+
+-- +migrate Down
+DROP INDEX idx_quiz_status_changes_quiz_id;
+DROP TABLE quiz_status_changes;
+DROP INDEX idx_quizzes_sub_category_status;
+ALTER TABLE quizzes DROP CONSTRAINT chk_quizzes_status;
+ALTER TABLE quizzes DROP COLUMN status;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- Links quizzes to the seed file entries they were created from, so re-running the seeder updates them
+-- instead of adding duplicates. content_hash tells when the seed entry changed since it was last seeded.
+CREATE TABLE quiz_seeds (
+    seed_key VARCHAR2(255) PRIMARY KEY,
+    quiz_id VARCHAR2(26) NOT NULL,
+    content_hash VARCHAR2(64) NOT NULL,
+    seeded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CONSTRAINT uq_quiz_seeds_quiz_id UNIQUE (quiz_id),
+    CONSTRAINT fk_quiz_seeds_quiz FOREIGN KEY (quiz_id) REFERENCES quizzes(id)
+);
+
+-- +migrate Down
+DROP TABLE quiz_seeds;
@@ -0,0 +1,6 @@
+-- +migrate Up
+-- A user's email is only stored once a provider verified it; users who signed up with an unverified email have none.
+ALTER TABLE users MODIFY (email NULL);
+
+-- +migrate Down
+ALTER TABLE users MODIFY (email NOT NULL);
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- Tokens whose refresh failed are retried with a growing delay, so they do not hold up the other expiring tokens.
+ALTER TABLE users ADD (
+    token_refresh_failures NUMBER(5) DEFAULT 0 NOT NULL,
+    token_refresh_retry_at TIMESTAMP WITH TIME ZONE
+);
+
+-- +migrate Down
+ALTER TABLE users DROP (token_refresh_failures, token_refresh_retry_at);
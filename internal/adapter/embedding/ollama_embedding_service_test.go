@@ -64,6 +64,10 @@ func (m *MockCache) Delete(ctx context.Context, key string) error {
 	args := m.Called(ctx, key)
 	return args.Error(0)
 }
+func (m *MockCache) GetDel(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
 func (m *MockCache) Ping(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
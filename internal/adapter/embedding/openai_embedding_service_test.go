@@ -39,6 +39,10 @@ func (m *OpenaiMockCache) Delete(ctx context.Context, key string) error {
 	args := m.Called(ctx, key)
 	return args.Error(0)
 }
+func (m *OpenaiMockCache) GetDel(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
 func (m *OpenaiMockCache) Ping(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
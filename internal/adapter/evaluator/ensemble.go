@@ -0,0 +1,196 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/logger"
+	"quiz-byte/internal/port"
+
+	"go.uber.org/zap"
+)
+
+// ensembleEvaluator asks every judge concurrently and combines their scores.
+type ensembleEvaluator struct {
+	judges             []port.AnswerEvaluator
+	strategy           string
+	trimRatio          float64
+	disagreementMargin float64
+}
+
+// NewEnsembleEvaluator creates an evaluator that combines the judges' scores with settings.Strategy.
+// A result is low-confidence when the judges' scores spread further than settings.DisagreementMargin.
+func NewEnsembleEvaluator(judges []port.AnswerEvaluator, settings config.EnsembleConfig) (port.AnswerEvaluator, error) {
+	if len(judges) == 0 {
+		return nil, fmt.Errorf("ensemble needs at least one judge")
+	}
+	switch settings.Strategy {
+	case config.EnsembleStrategyMean, config.EnsembleStrategyMedian, config.EnsembleStrategyTrimmedMean:
+	default:
+		return nil, fmt.Errorf("unsupported ensemble strategy %q", settings.Strategy)
+	}
+	if settings.TrimRatio < 0 || settings.TrimRatio >= 0.5 {
+		return nil, fmt.Errorf("ensemble trim ratio must be in [0, 0.5), got %v", settings.TrimRatio)
+	}
+	return &ensembleEvaluator{
+		judges:             judges,
+		strategy:           settings.Strategy,
+		trimRatio:          settings.TrimRatio,
+		disagreementMargin: settings.DisagreementMargin,
+	}, nil
+}
+
+// EvaluateAnswer implements port.AnswerEvaluator. Judges that fail are left out; the call
+// only fails when every judge does, with the first judge's error.
+func (e *ensembleEvaluator) EvaluateAnswer(ctx context.Context, input port.EvaluationInput) (*domain.Answer, error) {
+	answers := make([]*domain.Answer, len(e.judges))
+	errs := make([]error, len(e.judges))
+
+	var wg sync.WaitGroup
+	for i, judge := range e.judges {
+		wg.Add(1)
+		go func(i int, judge port.AnswerEvaluator) {
+			defer wg.Done()
+			answers[i], errs[i] = judge.EvaluateAnswer(ctx, input)
+		}(i, judge)
+	}
+	wg.Wait()
+
+	results := make([]*domain.Answer, 0, len(answers))
+	for i, answer := range answers {
+		if errs[i] != nil {
+			logger.Get().Warn("Ensemble judge failed", zap.Int("judge", i), zap.Error(errs[i]))
+			continue
+		}
+		results = append(results, answer)
+	}
+	if len(results) == 0 {
+		return nil, errs[0]
+	}
+
+	combined := &domain.Answer{
+		UserAnswer:   input.UserAnswer,
+		Score:        e.combine(results, func(a *domain.Answer) float64 { return a.Score }),
+		Completeness: e.combine(results, func(a *domain.Answer) float64 { return a.Completeness }),
+		Relevance:    e.combine(results, func(a *domain.Answer) float64 { return a.Relevance }),
+		Accuracy:     e.combine(results, func(a *domain.Answer) float64 { return a.Accuracy }),
+		JudgeCount:   len(results),
+	}
+	combined.Explanation = closestJudge(results, combined.Score).Explanation
+	combined.KeywordMatches = majority(results, input.Keywords, func(a *domain.Answer) []string { return a.KeywordMatches })
+	combined.RubricSatisfied, combined.RubricMissed = majorityRubric(results, input.Rubric)
+
+	combined.Confidence = domain.ConfidenceHigh
+	spread := scoreSpread(results)
+	if spread > e.disagreementMargin || (len(e.judges) > 1 && len(results) < 2) {
+		combined.Confidence = domain.ConfidenceLow
+		logger.Get().Info("Ensemble judges disagree",
+			zap.Float64("spread", spread),
+			zap.Int("judges_answered", len(results)),
+			zap.Int("judges_total", len(e.judges)))
+	}
+	return combined, nil
+}
+
+func (e *ensembleEvaluator) combine(results []*domain.Answer, value func(*domain.Answer) float64) float64 {
+	values := make([]float64, len(results))
+	for i, r := range results {
+		values[i] = value(r)
+	}
+	sort.Float64s(values)
+
+	switch e.strategy {
+	case config.EnsembleStrategyMedian:
+		return round2(median(values))
+	case config.EnsembleStrategyTrimmedMean:
+		trim := int(math.Floor(float64(len(values)) * e.trimRatio))
+		return round2(mean(values[trim : len(values)-trim]))
+	default:
+		return round2(mean(values))
+	}
+}
+
+// mean expects a non-empty slice.
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// median expects a sorted, non-empty slice.
+func median(values []float64) float64 {
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+func scoreSpread(results []*domain.Answer) float64 {
+	lo, hi := results[0].Score, results[0].Score
+	for _, r := range results[1:] {
+		lo = math.Min(lo, r.Score)
+		hi = math.Max(hi, r.Score)
+	}
+	return hi - lo
+}
+
+// closestJudge returns the judge whose score is nearest to the combined score, so the
+// explanation matches the reported score.
+func closestJudge(results []*domain.Answer, score float64) *domain.Answer {
+	best := results[0]
+	for _, r := range results[1:] {
+		if math.Abs(r.Score-score) < math.Abs(best.Score-score) {
+			best = r
+		}
+	}
+	return best
+}
+
+// majority returns the candidates listed by more than half of the judges, in candidate order.
+func majority(results []*domain.Answer, candidates []string, values func(*domain.Answer) []string) []string {
+	votes := make(map[string]int, len(candidates))
+	for _, r := range results {
+		seen := make(map[string]bool)
+		for _, v := range values(r) {
+			key := normalizeRubricItem(v)
+			if !seen[key] {
+				seen[key] = true
+				votes[key]++
+			}
+		}
+	}
+	picked := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if votes[normalizeRubricItem(c)]*2 > len(results) {
+			picked = append(picked, c)
+		}
+	}
+	return picked
+}
+
+func majorityRubric(results []*domain.Answer, rubric *domain.QuizEvaluation) (satisfied, missed []string) {
+	items := rubric.RubricItems()
+	if len(items) == 0 {
+		return nil, nil
+	}
+	satisfied = majority(results, items, func(a *domain.Answer) []string { return a.RubricSatisfied })
+	isSatisfied := make(map[string]bool, len(satisfied))
+	for _, item := range satisfied {
+		isSatisfied[item] = true
+	}
+	missed = make([]string, 0, len(items)-len(satisfied))
+	for _, item := range items {
+		if !isSatisfied[item] {
+			missed = append(missed, item)
+		}
+	}
+	return satisfied, missed
+}
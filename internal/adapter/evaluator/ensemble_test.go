@@ -0,0 +1,165 @@
+package evaluator_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"quiz-byte/internal/adapter/evaluator"
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubJudge returns a fixed answer or error.
+type stubJudge struct {
+	answer *domain.Answer
+	err    error
+}
+
+func (s stubJudge) EvaluateAnswer(_ context.Context, _ port.EvaluationInput) (*domain.Answer, error) {
+	return s.answer, s.err
+}
+
+func judgeWithScore(score float64, explanation string, keywords ...string) stubJudge {
+	return stubJudge{answer: &domain.Answer{
+		Score:          score,
+		Explanation:    explanation,
+		KeywordMatches: keywords,
+		Completeness:   score,
+		Relevance:      score,
+		Accuracy:       score,
+	}}
+}
+
+func TestEnsembleEvaluator_Strategies(t *testing.T) {
+	judges := []port.AnswerEvaluator{
+		judgeWithScore(0.1, "outlier low"),
+		judgeWithScore(0.6, "a"),
+		judgeWithScore(0.7, "b"),
+		judgeWithScore(0.8, "c"),
+		judgeWithScore(1.0, "outlier high"),
+	}
+
+	testCases := []struct {
+		strategy string
+		want     float64
+	}{
+		{strategy: config.EnsembleStrategyMean, want: 0.64},
+		{strategy: config.EnsembleStrategyMedian, want: 0.7},
+		{strategy: config.EnsembleStrategyTrimmedMean, want: 0.7},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.strategy, func(t *testing.T) {
+			ev, err := evaluator.NewEnsembleEvaluator(judges, config.EnsembleConfig{Strategy: tc.strategy, TrimRatio: 0.2, DisagreementMargin: 1})
+			require.NoError(t, err)
+
+			answer, err := ev.EvaluateAnswer(context.Background(), port.EvaluationInput{UserAnswer: "x"})
+			require.NoError(t, err)
+			assert.InDelta(t, tc.want, answer.Score, 0.001)
+			assert.InDelta(t, tc.want, answer.Accuracy, 0.001)
+			assert.Equal(t, 5, answer.JudgeCount)
+			assert.Equal(t, domain.ConfidenceHigh, answer.Confidence)
+		})
+	}
+}
+
+func TestEnsembleEvaluator_Disagreement(t *testing.T) {
+	ev, err := evaluator.NewEnsembleEvaluator([]port.AnswerEvaluator{
+		judgeWithScore(0.2, "poor"),
+		judgeWithScore(0.9, "great"),
+		judgeWithScore(0.85, "good"),
+	}, config.EnsembleConfig{Strategy: config.EnsembleStrategyMedian, DisagreementMargin: 0.3})
+	require.NoError(t, err)
+
+	answer, err := ev.EvaluateAnswer(context.Background(), port.EvaluationInput{UserAnswer: "x"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ConfidenceLow, answer.Confidence)
+	assert.Equal(t, 0.85, answer.Score)
+	assert.Equal(t, "good", answer.Explanation)
+}
+
+func TestEnsembleEvaluator_MajorityKeywordsAndRubric(t *testing.T) {
+	rubric := &domain.QuizEvaluation{RequiredTopics: []string{"scheduling", "memory"}}
+	judges := []port.AnswerEvaluator{
+		stubJudge{answer: &domain.Answer{Score: 0.8, KeywordMatches: []string{"goroutine", "runtime"}, RubricSatisfied: []string{"scheduling"}}},
+		stubJudge{answer: &domain.Answer{Score: 0.8, KeywordMatches: []string{"Goroutine"}, RubricSatisfied: []string{"scheduling", "memory"}}},
+		stubJudge{answer: &domain.Answer{Score: 0.8, KeywordMatches: []string{"runtime", "goroutine"}}},
+	}
+	ev, err := evaluator.NewEnsembleEvaluator(judges, config.EnsembleConfig{Strategy: config.EnsembleStrategyMean, DisagreementMargin: 0.3})
+	require.NoError(t, err)
+
+	answer, err := ev.EvaluateAnswer(context.Background(), port.EvaluationInput{
+		UserAnswer: "x",
+		Keywords:   []string{"goroutine", "runtime", "channel"},
+		Rubric:     rubric,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"goroutine", "runtime"}, answer.KeywordMatches)
+	assert.Equal(t, []string{"scheduling"}, answer.RubricSatisfied)
+	assert.Equal(t, []string{"memory"}, answer.RubricMissed)
+}
+
+func TestEnsembleEvaluator_JudgeFailures(t *testing.T) {
+	judgeErr := errors.New("judge down")
+
+	t.Run("partial failure is low confidence", func(t *testing.T) {
+		ev, err := evaluator.NewEnsembleEvaluator([]port.AnswerEvaluator{
+			stubJudge{err: judgeErr},
+			judgeWithScore(0.9, "great"),
+		}, config.EnsembleConfig{Strategy: config.EnsembleStrategyMedian, DisagreementMargin: 0.3})
+		require.NoError(t, err)
+
+		answer, err := ev.EvaluateAnswer(context.Background(), port.EvaluationInput{UserAnswer: "x"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, answer.JudgeCount)
+		assert.Equal(t, domain.ConfidenceLow, answer.Confidence)
+	})
+
+	t.Run("all judges fail", func(t *testing.T) {
+		ev, err := evaluator.NewEnsembleEvaluator([]port.AnswerEvaluator{
+			stubJudge{err: judgeErr},
+			stubJudge{err: errors.New("other")},
+		}, config.EnsembleConfig{Strategy: config.EnsembleStrategyMean})
+		require.NoError(t, err)
+
+		_, err = ev.EvaluateAnswer(context.Background(), port.EvaluationInput{UserAnswer: "x"})
+		assert.ErrorIs(t, err, judgeErr)
+	})
+}
+
+func TestNewFromConfig_Ensemble(t *testing.T) {
+	cfg := config.EvaluatorConfig{
+		Provider: config.EvaluatorProviderEnsemble,
+		Ensemble: config.EnsembleConfig{
+			Judges:   []string{config.EvaluatorProviderFake, config.EvaluatorProviderFake},
+			Strategy: config.EnsembleStrategyMean,
+		},
+	}
+	ev, err := evaluator.NewFromConfig(cfg)
+	require.NoError(t, err)
+
+	answer, err := ev.EvaluateAnswer(context.Background(), port.EvaluationInput{
+		ModelAnswers: []string{"A goroutine is a lightweight thread."},
+		UserAnswer:   "A goroutine is a lightweight thread.",
+		Keywords:     []string{"goroutine"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, answer.JudgeCount)
+	assert.Equal(t, domain.ConfidenceHigh, answer.Confidence)
+
+	for name, bad := range map[string]config.EnsembleConfig{
+		"no judges":        {Strategy: config.EnsembleStrategyMean},
+		"nested ensemble":  {Judges: []string{config.EvaluatorProviderEnsemble}, Strategy: config.EnsembleStrategyMean},
+		"unknown strategy": {Judges: []string{config.EvaluatorProviderFake}, Strategy: "mode"},
+		"trim too large":   {Judges: []string{config.EvaluatorProviderFake}, Strategy: config.EnsembleStrategyTrimmedMean, TrimRatio: 0.5},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := evaluator.NewFromConfig(config.EvaluatorConfig{Provider: config.EvaluatorProviderEnsemble, Ensemble: bad})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// explanationKeyPattern matches the start of the "explanation" string value at the end of the text seen so far.
+var explanationKeyPattern = regexp.MustCompile(`"explanation"\s*:\s*"$`)
+
+const (
+	extractorSearching = iota
+	extractorThinking  // Inside a <think> block, which may mention the key itself
+	extractorInString
+	extractorDone
+)
+
+// explanationExtractor picks the "explanation" string out of a streamed JSON response and
+// emits its decoded text piece by piece. Everything else in the response is ignored.
+type explanationExtractor struct {
+	state  int
+	seen   string // Tail of the text before the explanation value, used to find the key and <think> blocks
+	escape string // Incomplete escape sequence carried over between chunks
+	emit   func(delta string)
+}
+
+func newExplanationExtractor(emit func(delta string)) *explanationExtractor {
+	return &explanationExtractor{emit: emit}
+}
+
+func (x *explanationExtractor) write(chunk string) {
+	var out strings.Builder
+	for _, r := range chunk {
+		switch x.state {
+		case extractorSearching:
+			x.remember(r)
+			if strings.HasSuffix(x.seen, "<think>") {
+				x.state = extractorThinking
+			} else if explanationKeyPattern.MatchString(x.seen) {
+				x.state = extractorInString
+			}
+		case extractorThinking:
+			x.remember(r)
+			if strings.HasSuffix(x.seen, "</think>") {
+				x.seen = ""
+				x.state = extractorSearching
+			}
+		case extractorInString:
+			switch {
+			case x.escape != "":
+				x.escape += string(r)
+				if complete := len(x.escape) == 2 && x.escape[1] != 'u' || len(x.escape) == 6; complete {
+					if decoded, err := strconv.Unquote(`"` + x.escape + `"`); err == nil {
+						out.WriteString(decoded)
+					}
+					x.escape = ""
+				}
+			case r == '\\':
+				x.escape = `\`
+			case r == '"':
+				x.state = extractorDone
+			default:
+				out.WriteRune(r)
+			}
+		}
+	}
+	if out.Len() > 0 {
+		x.emit(out.String())
+	}
+}
+
+// remember keeps the last 64 bytes of text outside the explanation value.
+func (x *explanationExtractor) remember(r rune) {
+	x.seen += string(r)
+	if len(x.seen) > 64 {
+		x.seen = x.seen[len(x.seen)-64:]
+	}
+}
@@ -0,0 +1,124 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/port"
+)
+
+// fakeEvaluator is a deterministic AnswerEvaluator for tests and local runs without a model server.
+// Scores depend only on keyword coverage and word overlap with the model answers, so the same
+// input always produces the same result.
+type fakeEvaluator struct{}
+
+// NewFakeEvaluator creates a deterministic evaluator that never calls an LLM.
+func NewFakeEvaluator() port.AnswerEvaluator {
+	return &fakeEvaluator{}
+}
+
+func newFakeEvaluator(_ config.EvaluatorProviderConfig) (port.AnswerEvaluator, error) {
+	return NewFakeEvaluator(), nil
+}
+
+// EvaluateAnswer implements port.AnswerEvaluator
+func (e *fakeEvaluator) EvaluateAnswer(ctx context.Context, input port.EvaluationInput) (*domain.Answer, error) {
+	if err := ctx.Err(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, domain.NewEvaluationTimeoutError("llm", err)
+		}
+		return nil, err
+	}
+	normalizedAnswer := strings.ToLower(input.UserAnswer)
+
+	keywordMatches := make([]string, 0, len(input.Keywords))
+	for _, kw := range input.Keywords {
+		if kw = strings.TrimSpace(kw); kw != "" && strings.Contains(normalizedAnswer, strings.ToLower(kw)) {
+			keywordMatches = append(keywordMatches, kw)
+		}
+	}
+
+	// The user only has to match one model answer, so the best overlap counts.
+	overlap := 0.0
+	for _, modelAnswer := range input.ModelAnswers {
+		overlap = math.Max(overlap, wordOverlap(modelAnswer, input.UserAnswer))
+	}
+	coverage := overlap
+	if len(input.Keywords) > 0 {
+		coverage = float64(len(keywordMatches)) / float64(len(input.Keywords))
+	}
+	score := round2(0.7*coverage + 0.3*overlap)
+
+	// A required topic counts as covered when all of its words appear in the answer.
+	var coveredTopics []string
+	if input.Rubric != nil {
+		for _, topic := range input.Rubric.RequiredTopics {
+			if strings.TrimSpace(topic) != "" && wordOverlap(topic, input.UserAnswer) == 1 {
+				coveredTopics = append(coveredTopics, topic)
+			}
+		}
+	}
+	satisfied, missed := resolveRubric(input.Rubric, coveredTopics, keywordMatches)
+
+	return &domain.Answer{
+		UserAnswer:      input.UserAnswer,
+		Score:           score,
+		Explanation:     fmt.Sprintf("Matched %d of %d keywords; %.0f%% of the model answer's terms were used.", len(keywordMatches), len(input.Keywords), overlap*100),
+		KeywordMatches:  keywordMatches,
+		Completeness:    round2(coverage),
+		Relevance:       round2(overlap),
+		Accuracy:        score,
+		RubricSatisfied: satisfied,
+		RubricMissed:    missed,
+	}, nil
+}
+
+// wordOverlap returns the share of distinct words in reference that also appear in answer.
+func wordOverlap(reference, answer string) float64 {
+	referenceWords := wordSet(reference)
+	if len(referenceWords) == 0 {
+		return 0
+	}
+	answerWords := wordSet(answer)
+	matched := 0
+	for w := range referenceWords {
+		if _, ok := answerWords[w]; ok {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(referenceWords))
+}
+
+func wordSet(text string) map[string]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return set
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// EvaluateAnswerStream implements port.StreamingAnswerEvaluator by emitting the explanation word by word.
+func (e *fakeEvaluator) EvaluateAnswerStream(ctx context.Context, input port.EvaluationInput, onExplanation func(delta string)) (*domain.Answer, error) {
+	answer, err := e.EvaluateAnswer(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	words := strings.SplitAfter(answer.Explanation, " ")
+	for _, w := range words {
+		onExplanation(w)
+	}
+	return answer, nil
+}
@@ -3,7 +3,9 @@ package evaluator // Changed package name
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"quiz-byte/internal/config"
 	"quiz-byte/internal/domain" // Added for domain types
 	"quiz-byte/internal/logger"
 	"quiz-byte/internal/port"
@@ -11,55 +13,63 @@ import (
 	"time"
 
 	"github.com/tmc/langchaingo/llms"
-	"github.com/tmc/langchaingo/llms/ollama"
 	"go.uber.org/zap"
 )
 
+const defaultLLMTimeout = 20 * time.Second
+
 // llmEvaluator implements domain.AnswerEvaluator
 type llmEvaluator struct {
-	llmClient *ollama.LLM
+	llmClient   llms.Model
+	temperature float64
+	maxTokens   int
+	timeout     time.Duration
 }
 
-// NewLLMEvaluator creates a new instance of llmEvaluator
-func NewLLMEvaluator(llm *ollama.LLM) port.AnswerEvaluator { // Return type is port.AnswerEvaluator
+// NewLLMEvaluator creates a new instance of llmEvaluator for any langchaingo model.
+// Temperature, max tokens and the per-call timeout are taken from settings.
+func NewLLMEvaluator(llm llms.Model, settings config.EvaluatorProviderConfig) port.AnswerEvaluator { // Return type is port.AnswerEvaluator
+	timeout := settings.Timeout
+	if timeout <= 0 {
+		timeout = defaultLLMTimeout
+	}
 	return &llmEvaluator{
-		llmClient: llm,
+		llmClient:   llm,
+		temperature: settings.Temperature,
+		maxTokens:   settings.MaxTokens,
+		timeout:     timeout,
 	}
 }
 
 // EvaluateAnswer implements domain.AnswerEvaluator
-func (e *llmEvaluator) EvaluateAnswer(questionText string, modelAnswer string, userAnswer string, keywords []string) (*domain.Answer, error) { // Return type is *domain.Answer
-	l := logger.Get()
-	l.Info("Evaluating answer with LLM",
-		zap.String("question", questionText),
-		zap.Strings("keywords", keywords))
-
-	// Prompt remains the same
-	prompt := fmt.Sprintf(`You are a quiz answer evaluator. Evaluate the answer and respond with ONLY a JSON object in the following format:
-{
-    "score": 0.0,
-    "explanation": "brief explanation here",
-    "keyword_matches": ["matched_keyword1", "matched_keyword2"],
-    "completeness": 0.0,
-    "relevance": 0.0,
-    "accuracy": 0.0
+func (e *llmEvaluator) EvaluateAnswer(ctx context.Context, input port.EvaluationInput) (*domain.Answer, error) { // Return type is *domain.Answer
+	return e.evaluate(ctx, input, nil)
 }
 
-Question: %s
-Model Answer: %s
-User's Answer: %s
-Keywords to Check: %s
+// EvaluateAnswerStream implements port.StreamingAnswerEvaluator. The model's JSON is streamed
+// and the "explanation" string is passed to onExplanation as it is decoded.
+func (e *llmEvaluator) EvaluateAnswerStream(ctx context.Context, input port.EvaluationInput, onExplanation func(delta string)) (*domain.Answer, error) {
+	return e.evaluate(ctx, input, onExplanation)
+}
+
+func (e *llmEvaluator) evaluate(ctx context.Context, input port.EvaluationInput, onExplanation func(delta string)) (*domain.Answer, error) {
+	l := logger.Get()
+	l.Info("Evaluating answer with LLM",
+		zap.String("question", input.Question),
+		zap.Strings("keywords", input.Keywords),
+		zap.Bool("has_rubric", input.Rubric != nil))
 
-Rules:
-1. All scores must be between 0 and 1 (1 is perfect)
-2. Explanation must be under 100 words, focusing on key strengths and areas for improvement
-3. keyword_matches should list all keywords from the given set that appear in the user's answer
-4. Completeness measures how fully the answer addresses all aspects of the question
-5. Relevance measures how well the answer stays on topic
-6. Accuracy measures the factual correctness based on the model answers provided`, questionText, modelAnswer, userAnswer, strings.Join(keywords, ", "))
+	prompt := buildEvaluationPrompt(input)
 
-	rawLLMResponse, err := e.callLLM(prompt)
+	var onChunk func(chunk string)
+	if onExplanation != nil {
+		onChunk = newExplanationExtractor(onExplanation).write
+	}
+	rawLLMResponse, err := e.callLLM(ctx, prompt, onChunk)
 	if err != nil {
+		if errors.Is(err, domain.ErrEvaluationTimeout) || errors.Is(err, context.Canceled) {
+			return nil, err
+		}
 		l.Error("callLLM failed during LLM evaluation", zap.Error(err), zap.String("prompt_part", prompt[:min(200, len(prompt))]))
 		return nil, domain.NewLLMServiceError(fmt.Errorf("callLLM failed: %w", err)) // Use domain.NewLLMServiceError
 	}
@@ -84,12 +94,13 @@ Rules:
 		l.Info("Attempting to parse extracted JSON string from LLM", zap.String("extracted_json", extractedJSONStr))
 
 		var llmResp struct {
-			Score          float64  `json:"score"`
-			Explanation    string   `json:"explanation"`
-			KeywordMatches []string `json:"keyword_matches"`
-			Completeness   float64  `json:"completeness"`
-			Relevance      float64  `json:"relevance"`
-			Accuracy       float64  `json:"accuracy"`
+			Score           float64  `json:"score"`
+			Explanation     string   `json:"explanation"`
+			KeywordMatches  []string `json:"keyword_matches"`
+			Completeness    float64  `json:"completeness"`
+			Relevance       float64  `json:"relevance"`
+			Accuracy        float64  `json:"accuracy"`
+			RubricSatisfied []string `json:"rubric_satisfied"`
 		}
 
 		if errUnmarshal := json.Unmarshal([]byte(extractedJSONStr), &llmResp); errUnmarshal != nil {
@@ -102,14 +113,17 @@ Rules:
 
 		l.Info("Successfully parsed LLM response", zap.Any("parsed_llm_evaluation", llmResp))
 
+		satisfied, missed := resolveRubric(input.Rubric, llmResp.RubricSatisfied, llmResp.KeywordMatches)
 		answer := &domain.Answer{ // Use domain.Answer
-			UserAnswer:     userAnswer,
-			Score:          llmResp.Score,
-			Explanation:    llmResp.Explanation,
-			KeywordMatches: llmResp.KeywordMatches,
-			Completeness:   llmResp.Completeness,
-			Relevance:      llmResp.Relevance,
-			Accuracy:       llmResp.Accuracy,
+			UserAnswer:      input.UserAnswer,
+			Score:           llmResp.Score,
+			Explanation:     llmResp.Explanation,
+			KeywordMatches:  llmResp.KeywordMatches,
+			Completeness:    llmResp.Completeness,
+			Relevance:       llmResp.Relevance,
+			Accuracy:        llmResp.Accuracy,
+			RubricSatisfied: satisfied,
+			RubricMissed:    missed,
 		}
 		return answer, nil
 
@@ -121,17 +135,34 @@ Rules:
 	}
 }
 
-func (e *llmEvaluator) callLLM(prompt string) (string, error) {
+// callLLM sends the prompt bounded by both the caller's context and the provider timeout.
+// When onChunk is set the response is streamed to it as well.
+func (e *llmEvaluator) callLLM(ctx context.Context, prompt string, onChunk func(chunk string)) (string, error) {
 	l := logger.Get()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second) // Timeout
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
 	defer cancel()
 
-	response, err := e.llmClient.Call(ctx, prompt, llms.WithTemperature(0.1))
+	callOptions := []llms.CallOption{llms.WithTemperature(e.temperature)}
+	if e.maxTokens > 0 {
+		callOptions = append(callOptions, llms.WithMaxTokens(e.maxTokens))
+	}
+	if onChunk != nil {
+		callOptions = append(callOptions, llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+			onChunk(string(chunk))
+			return nil
+		}))
+	}
+
+	response, err := llms.GenerateFromSinglePrompt(ctx, e.llmClient, prompt, callOptions...)
 	if err != nil {
-		if err == context.DeadlineExceeded {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
 			l.Error("LLM request timed out", zap.Error(err))
-			return "", fmt.Errorf("LLM request timed out: %w", err)
+			return "", domain.NewEvaluationTimeoutError("llm", err)
+		}
+		if errors.Is(ctx.Err(), context.Canceled) {
+			l.Warn("LLM request canceled by caller", zap.Error(err))
+			return "", fmt.Errorf("LLM request canceled: %w", context.Canceled)
 		}
 		l.Error("Failed to get response from LLM", zap.Error(err))
 		return "", fmt.Errorf("LLM call failed: %w", err)
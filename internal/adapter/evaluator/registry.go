@@ -0,0 +1,140 @@
+package evaluator
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/port"
+
+	"github.com/tmc/langchaingo/llms/ollama"
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+// ProviderFactory builds an AnswerEvaluator from the settings of a single provider.
+type ProviderFactory func(settings config.EvaluatorProviderConfig) (port.AnswerEvaluator, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]ProviderFactory{
+		config.EvaluatorProviderOllama: newOllamaEvaluator,
+		config.EvaluatorProviderOpenAI: newOpenAIEvaluator,
+		config.EvaluatorProviderGemini: newGeminiEvaluator,
+		config.EvaluatorProviderFake:   newFakeEvaluator,
+	}
+)
+
+// RegisterProvider adds or replaces the factory for the named provider.
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+// Providers returns the names of all registered providers in sorted order.
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewFromConfig builds the AnswerEvaluator selected by cfg.Provider.
+// The ensemble provider builds one judge per entry in cfg.Ensemble.Judges.
+func NewFromConfig(cfg config.EvaluatorConfig) (port.AnswerEvaluator, error) {
+	if cfg.Provider != config.EvaluatorProviderEnsemble {
+		return NewForProvider(cfg.Provider, cfg)
+	}
+
+	judges := make([]port.AnswerEvaluator, 0, len(cfg.Ensemble.Judges))
+	for _, name := range cfg.Ensemble.Judges {
+		if name == config.EvaluatorProviderEnsemble {
+			return nil, fmt.Errorf("ensemble judges cannot include %q", name)
+		}
+		judge, err := NewForProvider(name, cfg)
+		if err != nil {
+			return nil, err
+		}
+		judges = append(judges, judge)
+	}
+	ensemble, err := NewEnsembleEvaluator(judges, cfg.Ensemble)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ensemble evaluator: %w", err)
+	}
+	return ensemble, nil
+}
+
+// NewForProvider builds an AnswerEvaluator for the named provider using its settings in cfg.
+func NewForProvider(name string, cfg config.EvaluatorConfig) (port.AnswerEvaluator, error) {
+	providersMu.RLock()
+	factory, ok := providers[name]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported evaluator provider %q (available: %v)", name, Providers())
+	}
+
+	// Providers registered from outside this package may not have a dedicated config section.
+	settings, _ := cfg.ProviderConfig(name)
+	evaluator, err := factory(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s evaluator: %w", name, err)
+	}
+	return evaluator, nil
+}
+
+func newOllamaEvaluator(settings config.EvaluatorProviderConfig) (port.AnswerEvaluator, error) {
+	if settings.Model == "" {
+		return nil, fmt.Errorf("ollama model name cannot be empty")
+	}
+	opts := []ollama.Option{
+		ollama.WithModel(settings.Model),
+		ollama.WithHTTPClient(&http.Client{Timeout: settings.Timeout}),
+	}
+	if settings.BaseURL != "" {
+		opts = append(opts, ollama.WithServerURL(settings.BaseURL))
+	}
+	llm, err := ollama.New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewLLMEvaluator(llm, settings), nil
+}
+
+func newOpenAIEvaluator(settings config.EvaluatorProviderConfig) (port.AnswerEvaluator, error) {
+	if settings.APIKey == "" {
+		return nil, fmt.Errorf("openai API key cannot be empty")
+	}
+	if settings.Model == "" {
+		return nil, fmt.Errorf("openai model name cannot be empty")
+	}
+	opts := []openai.Option{
+		openai.WithToken(settings.APIKey),
+		openai.WithModel(settings.Model),
+		openai.WithResponseFormat(openai.ResponseFormatJSON),
+	}
+	if settings.BaseURL != "" {
+		opts = append(opts, openai.WithBaseURL(settings.BaseURL))
+	}
+	llm, err := openai.New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewLLMEvaluator(llm, settings), nil
+}
+
+// newGeminiEvaluator talks to Gemini through its OpenAI-compatible endpoint.
+func newGeminiEvaluator(settings config.EvaluatorProviderConfig) (port.AnswerEvaluator, error) {
+	if settings.BaseURL == "" {
+		settings.BaseURL = config.DefaultGeminiBaseURL
+	}
+	if settings.APIKey == "" {
+		return nil, fmt.Errorf("gemini API key cannot be empty")
+	}
+	return newOpenAIEvaluator(settings)
+}
@@ -0,0 +1,322 @@
+package evaluator_test
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/adapter/evaluator"
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/logger"
+	"quiz-byte/internal/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.Initialize(config.LoggerConfig{}); err != nil {
+		log.Fatalf("Failed to initialize logger for evaluator tests: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// recordingLLM is an llms.Model that returns a canned response and remembers the call options.
+type recordingLLM struct {
+	response string
+	prompt   string
+	options  llms.CallOptions
+	deadline time.Time
+}
+
+func (r *recordingLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			if text, ok := part.(llms.TextContent); ok {
+				r.prompt += text.Text
+			}
+		}
+	}
+	for _, opt := range options {
+		opt(&r.options)
+	}
+	r.deadline, _ = ctx.Deadline()
+	if r.options.StreamingFunc != nil {
+		// Stream in small pieces so tokens split JSON keys and escape sequences.
+		for rest := r.response; rest != ""; {
+			n := min(5, len(rest))
+			if err := r.options.StreamingFunc(ctx, []byte(rest[:n])); err != nil {
+				return nil, err
+			}
+			rest = rest[n:]
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: r.response}}}, nil
+}
+
+func (r *recordingLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, r, prompt, options...)
+}
+
+func TestNewFromConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cfg         config.EvaluatorConfig
+		expectedErr string
+	}{
+		{
+			name: "fake provider",
+			cfg:  config.EvaluatorConfig{Provider: config.EvaluatorProviderFake},
+		},
+		{
+			name: "ollama provider",
+			cfg: config.EvaluatorConfig{
+				Provider: config.EvaluatorProviderOllama,
+				Ollama:   config.EvaluatorProviderConfig{BaseURL: "http://localhost:11434", Model: "qwen3:0.6b", Timeout: time.Second},
+			},
+		},
+		{
+			name: "openai provider",
+			cfg: config.EvaluatorConfig{
+				Provider: config.EvaluatorProviderOpenAI,
+				OpenAI:   config.EvaluatorProviderConfig{APIKey: "sk-test", Model: "gpt-4o-mini"},
+			},
+		},
+		{
+			name: "gemini provider",
+			cfg: config.EvaluatorConfig{
+				Provider: config.EvaluatorProviderGemini,
+				Gemini:   config.EvaluatorProviderConfig{APIKey: "gemini-key", Model: "gemini-pro"},
+			},
+		},
+		{
+			name:        "openai without api key",
+			cfg:         config.EvaluatorConfig{Provider: config.EvaluatorProviderOpenAI, OpenAI: config.EvaluatorProviderConfig{Model: "gpt-4o-mini"}},
+			expectedErr: "openai API key cannot be empty",
+		},
+		{
+			name:        "ollama without model",
+			cfg:         config.EvaluatorConfig{Provider: config.EvaluatorProviderOllama},
+			expectedErr: "ollama model name cannot be empty",
+		},
+		{
+			name:        "unknown provider",
+			cfg:         config.EvaluatorConfig{Provider: "mystery"},
+			expectedErr: `unsupported evaluator provider "mystery"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ev, err := evaluator.NewFromConfig(tc.cfg)
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				assert.Nil(t, ev)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, ev)
+		})
+	}
+}
+
+func TestRegisterProvider(t *testing.T) {
+	called := false
+	evaluator.RegisterProvider("custom-test", func(settings config.EvaluatorProviderConfig) (port.AnswerEvaluator, error) {
+		called = true
+		return evaluator.NewFakeEvaluator(), nil
+	})
+
+	assert.Contains(t, evaluator.Providers(), "custom-test")
+	ev, err := evaluator.NewFromConfig(config.EvaluatorConfig{Provider: "custom-test"})
+	require.NoError(t, err)
+	assert.NotNil(t, ev)
+	assert.True(t, called)
+}
+
+func TestFakeEvaluator_IsDeterministic(t *testing.T) {
+	ev := evaluator.NewFakeEvaluator()
+	keywords := []string{"goroutine", "runtime", "channel"}
+	modelAnswer := "A goroutine is a lightweight thread managed by the Go runtime."
+
+	input := port.EvaluationInput{
+		Question:     "What is a goroutine?",
+		ModelAnswers: []string{modelAnswer},
+		UserAnswer:   "A goroutine is a lightweight thread scheduled by the runtime.",
+		Keywords:     keywords,
+	}
+
+	first, err := ev.EvaluateAnswer(context.Background(), input)
+	require.NoError(t, err)
+	second, err := ev.EvaluateAnswer(context.Background(), input)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, []string{"goroutine", "runtime"}, first.KeywordMatches)
+	assert.InDelta(t, 0.67, first.Completeness, 0.001)
+	assert.Greater(t, first.Score, 0.5)
+	assert.LessOrEqual(t, first.Score, 1.0)
+
+	input.UserAnswer = "I don't know"
+	empty, err := ev.EvaluateAnswer(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, empty.Score)
+	assert.Empty(t, empty.KeywordMatches)
+}
+
+func TestLLMEvaluator_AppliesProviderSettings(t *testing.T) {
+	llm := &recordingLLM{response: `<think>grading</think>{"score": 0.9, "explanation": "Good", "keyword_matches": ["cpu"], "completeness": 0.8, "relevance": 1.0, "accuracy": 0.9}`}
+	ev := evaluator.NewLLMEvaluator(llm, config.EvaluatorProviderConfig{Temperature: 0.3, MaxTokens: 256, Timeout: 5 * time.Second})
+
+	start := time.Now()
+	answer, err := ev.EvaluateAnswer(context.Background(), port.EvaluationInput{
+		Question:     "What does a CPU do?",
+		ModelAnswers: []string{"Executes instructions"},
+		UserAnswer:   "It runs instructions",
+		Keywords:     []string{"cpu"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.9, answer.Score)
+	assert.Equal(t, []string{"cpu"}, answer.KeywordMatches)
+	assert.Equal(t, 0.3, llm.options.Temperature)
+	assert.Equal(t, 256, llm.options.MaxTokens)
+	assert.WithinDuration(t, start.Add(5*time.Second), llm.deadline, time.Second)
+}
+
+func TestLLMEvaluator_MalformedResponse(t *testing.T) {
+	ev := evaluator.NewLLMEvaluator(&recordingLLM{response: "no json here"}, config.EvaluatorProviderConfig{})
+
+	_, err := ev.EvaluateAnswer(context.Background(), port.EvaluationInput{Question: "Q", ModelAnswers: []string{"A"}, UserAnswer: "B"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrLLMServiceError)
+}
+
+func TestLLMEvaluator_StreamsExplanation(t *testing.T) {
+	llm := &recordingLLM{response: `<think>the "explanation": "ignored"</think>{"score": 0.5, "explanation": "Say \"run\",\nnot caf\u00e9.", "keyword_matches": [], "completeness": 0.5, "relevance": 0.5, "accuracy": 0.5}`}
+	ev := evaluator.NewLLMEvaluator(llm, config.EvaluatorProviderConfig{})
+	streaming, ok := ev.(port.StreamingAnswerEvaluator)
+	require.True(t, ok)
+
+	var deltas []string
+	answer, err := streaming.EvaluateAnswerStream(context.Background(), port.EvaluationInput{Question: "Q", ModelAnswers: []string{"A"}, UserAnswer: "B"}, func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	require.NoError(t, err)
+
+	assert.Greater(t, len(deltas), 1)
+	assert.Equal(t, answer.Explanation, strings.Join(deltas, ""))
+	assert.Equal(t, "Say \"run\",\nnot café.", answer.Explanation)
+}
+
+// blockingLLM is an llms.Model that never answers and returns once ctx is done.
+type blockingLLM struct{}
+
+func (blockingLLM) GenerateContent(ctx context.Context, _ []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (b blockingLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, b, prompt, options...)
+}
+
+func TestLLMEvaluator_Timeout(t *testing.T) {
+	ev := evaluator.NewLLMEvaluator(blockingLLM{}, config.EvaluatorProviderConfig{Timeout: 20 * time.Millisecond})
+
+	_, err := ev.EvaluateAnswer(context.Background(), port.EvaluationInput{Question: "Q", ModelAnswers: []string{"A"}, UserAnswer: "B"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrEvaluationTimeout)
+	assert.NotErrorIs(t, err, domain.ErrLLMServiceError)
+}
+
+func TestLLMEvaluator_Canceled(t *testing.T) {
+	ev := evaluator.NewLLMEvaluator(blockingLLM{}, config.EvaluatorProviderConfig{Timeout: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ev.EvaluateAnswer(ctx, port.EvaluationInput{Question: "Q", ModelAnswers: []string{"A"}, UserAnswer: "B"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, domain.ErrEvaluationTimeout)
+}
+
+func testRubric() *domain.QuizEvaluation {
+	return &domain.QuizEvaluation{
+		QuizID:          "quiz-1",
+		MinimumKeywords: 2,
+		RequiredTopics:  []string{"Lightweight thread", "Go runtime"},
+		ScoreRanges:     []string{"0.8-1.0", "0.0-0.8"},
+		RubricDetails:   "Full marks need both the scheduling and the cost aspect.",
+		ScoreEvaluations: []domain.ScoreEvaluationDetail{
+			{ScoreRange: "0.8-1.0", SampleAnswers: []string{"A cheap thread multiplexed by the Go scheduler."}, Explanation: "Complete"},
+			{ScoreRange: "0.0-0.8", SampleAnswers: []string{"A kind of function."}, Explanation: "Too vague"},
+		},
+	}
+}
+
+func TestLLMEvaluator_PromptIncludesModelAnswersAndRubric(t *testing.T) {
+	llm := &recordingLLM{response: `{"score": 0.7, "explanation": "Partly", "keyword_matches": ["goroutine"], "completeness": 0.6, "relevance": 1.0, "accuracy": 0.8, "rubric_satisfied": ["lightweight thread", "Invented item"]}`}
+	ev := evaluator.NewLLMEvaluator(llm, config.EvaluatorProviderConfig{})
+
+	answer, err := ev.EvaluateAnswer(context.Background(), port.EvaluationInput{
+		Question:     "What is a goroutine?",
+		ModelAnswers: []string{"A lightweight thread managed by the Go runtime.", "A function running concurrently."},
+		UserAnswer:   "A lightweight thread.",
+		Keywords:     []string{"goroutine", "runtime"},
+		Rubric:       testRubric(),
+	})
+	require.NoError(t, err)
+
+	for _, want := range []string{
+		"1. A lightweight thread managed by the Go runtime.",
+		"2. A function running concurrently.",
+		"- Lightweight thread",
+		"- Go runtime",
+		"- Mentions at least 2 keywords",
+		"Full marks need both the scheduling and the cost aspect.",
+		"Score 0.8-1.0: Complete",
+		"  - A cheap thread multiplexed by the Go scheduler.",
+	} {
+		assert.Contains(t, llm.prompt, want)
+	}
+
+	// Unknown items are dropped and the keyword minimum is checked against keyword_matches.
+	assert.Equal(t, []string{"Lightweight thread"}, answer.RubricSatisfied)
+	assert.Equal(t, []string{"Go runtime", "Mentions at least 2 keywords"}, answer.RubricMissed)
+}
+
+func TestLLMEvaluator_NoRubric(t *testing.T) {
+	llm := &recordingLLM{response: `{"score": 0.5, "explanation": "Ok", "keyword_matches": [], "completeness": 0.5, "relevance": 0.5, "accuracy": 0.5}`}
+	ev := evaluator.NewLLMEvaluator(llm, config.EvaluatorProviderConfig{})
+
+	answer, err := ev.EvaluateAnswer(context.Background(), port.EvaluationInput{Question: "Q", ModelAnswers: []string{"A"}, UserAnswer: "B"})
+	require.NoError(t, err)
+
+	assert.NotContains(t, llm.prompt, "Grading Rubric")
+	assert.Nil(t, answer.RubricSatisfied)
+	assert.Nil(t, answer.RubricMissed)
+}
+
+func TestFakeEvaluator_Rubric(t *testing.T) {
+	ev := evaluator.NewFakeEvaluator()
+
+	answer, err := ev.EvaluateAnswer(context.Background(), port.EvaluationInput{
+		Question:     "What is a goroutine?",
+		ModelAnswers: []string{"Unrelated first answer.", "A goroutine is a lightweight thread managed by the Go runtime."},
+		UserAnswer:   "A goroutine is a lightweight thread.",
+		Keywords:     []string{"goroutine", "runtime"},
+		Rubric:       testRubric(),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Lightweight thread"}, answer.RubricSatisfied)
+	assert.Equal(t, []string{"Go runtime", "Mentions at least 2 keywords"}, answer.RubricMissed)
+	assert.Greater(t, answer.Relevance, 0.3, "overlap should use the best matching model answer")
+}
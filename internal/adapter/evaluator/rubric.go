@@ -0,0 +1,115 @@
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/port"
+)
+
+// buildEvaluationPrompt renders the grading prompt: every model answer, the keywords and,
+// when the quiz has one, the rubric with its per-range reference answers.
+func buildEvaluationPrompt(input port.EvaluationInput) string {
+	var b strings.Builder
+	b.WriteString(`You are a quiz answer evaluator. Evaluate the answer and respond with ONLY a JSON object in the following format:
+{
+    "score": 0.0,
+    "explanation": "brief explanation here",
+    "keyword_matches": ["matched_keyword1", "matched_keyword2"],
+    "completeness": 0.0,
+    "relevance": 0.0,
+    "accuracy": 0.0,
+    "rubric_satisfied": ["rubric item covered by the answer"]
+}
+
+`)
+	fmt.Fprintf(&b, "Question: %s\n", input.Question)
+	b.WriteString("Model Answers (any one of them is a complete answer):\n")
+	for i, answer := range input.ModelAnswers {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, answer)
+	}
+	fmt.Fprintf(&b, "User's Answer: %s\n", input.UserAnswer)
+	fmt.Fprintf(&b, "Keywords to Check: %s\n", strings.Join(input.Keywords, ", "))
+
+	items := input.Rubric.RubricItems()
+	if input.Rubric != nil {
+		b.WriteString("\nGrading Rubric:\n")
+		if len(items) > 0 {
+			b.WriteString("Rubric items (copy the ones the answer covers verbatim into rubric_satisfied):\n")
+			for _, item := range items {
+				fmt.Fprintf(&b, "- %s\n", item)
+			}
+		}
+		if details := strings.TrimSpace(input.Rubric.RubricDetails); details != "" {
+			fmt.Fprintf(&b, "Rubric details: %s\n", details)
+		}
+		if len(input.Rubric.SampleAnswers) > 0 {
+			b.WriteString("Sample answers:\n")
+			for _, sample := range input.Rubric.SampleAnswers {
+				fmt.Fprintf(&b, "- %s\n", sample)
+			}
+		}
+		if len(input.Rubric.ScoreEvaluations) > 0 {
+			b.WriteString("Reference answers by score range:\n")
+			for _, se := range input.Rubric.ScoreEvaluations {
+				fmt.Fprintf(&b, "Score %s: %s\n", se.ScoreRange, se.Explanation)
+				for _, sample := range se.SampleAnswers {
+					fmt.Fprintf(&b, "  - %s\n", sample)
+				}
+			}
+		}
+	}
+
+	b.WriteString(`
+Rules:
+1. All scores must be between 0 and 1 (1 is perfect)
+2. Explanation must be under 100 words, focusing on key strengths and areas for improvement
+3. keyword_matches should list all keywords from the given set that appear in the user's answer
+4. Completeness measures how fully the answer addresses all aspects of the question
+5. Relevance measures how well the answer stays on topic
+6. Accuracy measures the factual correctness based on the model answers provided`)
+	if input.Rubric != nil {
+		b.WriteString(`
+7. The score must follow the rubric: compare the answer with the reference answers and pick the score range it fits best
+8. rubric_satisfied must list exactly the rubric items the answer covers`)
+	}
+	return b.String()
+}
+
+// resolveRubric splits the rubric items into satisfied and missed. Items are matched to
+// claimedSatisfied case-insensitively; the missed items are the rest, so the model only
+// reports what is satisfied and anything it invents is ignored. The minimum keyword item
+// is decided by counting keywordMatches rather than by a rubric claim.
+func resolveRubric(rubric *domain.QuizEvaluation, claimedSatisfied []string, keywordMatches []string) (satisfied, missed []string) {
+	items := rubric.RubricItems()
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	claimed := make(map[string]struct{}, len(claimedSatisfied))
+	for _, item := range claimedSatisfied {
+		claimed[normalizeRubricItem(item)] = struct{}{}
+	}
+
+	satisfied = make([]string, 0, len(items))
+	missed = make([]string, 0, len(items))
+	for _, item := range items {
+		var ok bool
+		if rubric.MinimumKeywords > 0 && item == domain.MinimumKeywordsItem(rubric.MinimumKeywords) {
+			ok = len(keywordMatches) >= rubric.MinimumKeywords
+		} else {
+			_, ok = claimed[normalizeRubricItem(item)]
+		}
+		if ok {
+			satisfied = append(satisfied, item)
+		} else {
+			missed = append(missed, item)
+		}
+	}
+	return satisfied, missed
+}
+
+func normalizeRubricItem(item string) string {
+	return strings.ToLower(strings.TrimSpace(item))
+}
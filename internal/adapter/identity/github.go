@@ -0,0 +1,107 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/logger"
+	"quiz-byte/internal/port"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+const githubAcceptHeader = "application/vnd.github+json"
+
+// githubProvider signs users in with GitHub. GitHub is not an OpenID Connect provider, so the
+// account is read from its REST API and the nonce of the request is not used.
+type githubProvider struct {
+	oauth2Config *oauth2.Config
+	apiURL       string
+}
+
+// NewGitHubProvider creates the GitHub identity provider.
+func NewGitHubProvider(cfg config.GitHubOAuthConfig) port.IdentityProvider {
+	return &githubProvider{
+		oauth2Config: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       []string{"read:user", "user:email"},
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  cfg.AuthURL,
+				TokenURL: cfg.TokenURL,
+			},
+		},
+		apiURL: strings.TrimSuffix(cfg.APIURL, "/"),
+	}
+}
+
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Email     string `json:"email"` // The public profile email, which may be empty
+	AvatarURL string `json:"avatar_url"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func (p *githubProvider) Name() string {
+	return domain.IdentityProviderGitHub
+}
+
+func (p *githubProvider) AuthCodeURL(_ context.Context, req port.AuthRequest) (string, error) {
+	req.Nonce = ""
+	return authCodeURL(p.oauth2Config, req), nil
+}
+
+func (p *githubProvider) Exchange(ctx context.Context, code string, req port.AuthRequest) (*port.ExternalIdentity, *oauth2.Token, error) {
+	token, err := exchangeCode(ctx, p.oauth2Config, code, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var user githubUser
+	if err := getJSON(ctx, p.apiURL+"/user", token.AccessToken, githubAcceptHeader, &user); err != nil {
+		return nil, nil, fmt.Errorf("failed to get github user: %w", err)
+	}
+	if user.ID == 0 {
+		return nil, nil, errors.New("github user has no id")
+	}
+
+	identity := &port.ExternalIdentity{
+		Provider:   domain.IdentityProviderGitHub,
+		Subject:    strconv.FormatInt(user.ID, 10),
+		Email:      user.Email,
+		Name:       user.Name,
+		PictureURL: user.AvatarURL,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+
+	// The profile email is not known to be verified; the primary email from the emails API is.
+	var emails []githubEmail
+	if err := getJSON(ctx, p.apiURL+"/user/emails", token.AccessToken, githubAcceptHeader, &emails); err != nil {
+		logger.Get().Warn("Failed to get github user emails, using the profile email", zap.Int64("githubUserID", user.ID), zap.Error(err))
+		return identity, token, nil
+	}
+	for _, email := range emails {
+		if email.Primary && email.Verified {
+			identity.Email = email.Email
+			identity.EmailVerified = true
+			break
+		}
+	}
+	return identity, token, nil
+}
@@ -0,0 +1,118 @@
+package identity_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"quiz-byte/internal/adapter/identity"
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// githubLogin is the login the GitHub tests redeem codes for.
+var githubLogin = port.AuthRequest{State: "state123", Nonce: "nonce123", CodeVerifier: "verifier-0123456789-0123456789-0123456789"}
+
+// newFakeGitHub stands in for GitHub's token endpoint and REST API. emailsStatus is the status
+// of the emails API, which fails when the user did not grant the user:email scope.
+func newFakeGitHub(t *testing.T, emailsStatus int) config.GitHubOAuthConfig {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "good-code" || r.FormValue("code_verifier") != githubLogin.CodeVerifier {
+			// GitHub reports a bad code with a 200 response.
+			w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+			w.Write([]byte("error=bad_verification_code"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		w.Write([]byte(url.Values{"access_token": {"github-access"}, "token_type": {"bearer"}}.Encode()))
+	})
+	mux.HandleFunc("GET /api/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer github-access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 583231, "login": "octocat", "email": "public@example.com", "avatar_url": "https://avatars.example.com/u/583231"})
+	})
+	mux.HandleFunc("GET /api/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		if emailsStatus != http.StatusOK {
+			w.WriteHeader(emailsStatus)
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"email": "old@example.com", "primary": false, "verified": true},
+			{"email": "octocat@example.com", "primary": true, "verified": true},
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return config.GitHubOAuthConfig{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost/auth/github/callback",
+		AuthURL:      server.URL + "/login/oauth/authorize",
+		TokenURL:     server.URL + "/login/oauth/access_token",
+		APIURL:       server.URL + "/api",
+	}
+}
+
+func TestGitHubProvider_Exchange(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Uses Verified Primary Email", func(t *testing.T) {
+		provider := identity.NewGitHubProvider(newFakeGitHub(t, http.StatusOK))
+		account, token, err := provider.Exchange(ctx, "good-code", githubLogin)
+		require.NoError(t, err)
+		assert.Equal(t, "github-access", token.AccessToken)
+		assert.Equal(t, &port.ExternalIdentity{
+			Provider:      "github",
+			Subject:       "583231",
+			Email:         "octocat@example.com",
+			EmailVerified: true,
+			Name:          "octocat",
+			PictureURL:    "https://avatars.example.com/u/583231",
+		}, account)
+	})
+
+	t.Run("Falls Back To Unverified Profile Email", func(t *testing.T) {
+		provider := identity.NewGitHubProvider(newFakeGitHub(t, http.StatusForbidden))
+		account, _, err := provider.Exchange(ctx, "good-code", githubLogin)
+		require.NoError(t, err)
+		assert.Equal(t, "public@example.com", account.Email)
+		assert.False(t, account.EmailVerified)
+	})
+
+	t.Run("Bad Code", func(t *testing.T) {
+		provider := identity.NewGitHubProvider(newFakeGitHub(t, http.StatusOK))
+		_, _, err := provider.Exchange(ctx, "bad-code", githubLogin)
+		assert.ErrorIs(t, err, port.ErrCodeExchange)
+	})
+
+	t.Run("Wrong Code Verifier", func(t *testing.T) {
+		provider := identity.NewGitHubProvider(newFakeGitHub(t, http.StatusOK))
+		_, _, err := provider.Exchange(ctx, "good-code", port.AuthRequest{State: "state123", CodeVerifier: "another-login"})
+		assert.ErrorIs(t, err, port.ErrCodeExchange)
+	})
+}
+
+func TestGitHubProvider_AuthCodeURL(t *testing.T) {
+	cfg := newFakeGitHub(t, http.StatusOK)
+	loginURL, err := identity.NewGitHubProvider(cfg).AuthCodeURL(context.Background(), githubLogin)
+	require.NoError(t, err)
+	parsed, err := url.Parse(loginURL)
+	require.NoError(t, err)
+	assert.Equal(t, "state123", parsed.Query().Get("state"))
+	assert.Equal(t, "read:user user:email", parsed.Query().Get("scope"))
+	assert.Equal(t, cfg.RedirectURL, parsed.Query().Get("redirect_uri"))
+	assert.Equal(t, oauth2.S256ChallengeFromVerifier(githubLogin.CodeVerifier), parsed.Query().Get("code_challenge"))
+	assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+	assert.False(t, parsed.Query().Has("nonce"), "GitHub does not issue id_tokens")
+}
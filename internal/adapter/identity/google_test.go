@@ -0,0 +1,142 @@
+package identity_test
+
+import (
+	"context"
+	"crypto/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/adapter/identity"
+	"quiz-byte/internal/adapter/identity/identitytest"
+	"quiz-byte/internal/port"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// newLogin returns a login with fresh secrets, as the auth service starts one.
+func newLogin() port.AuthRequest {
+	return port.AuthRequest{State: rand.Text(), Nonce: rand.Text(), CodeVerifier: oauth2.GenerateVerifier()}
+}
+
+func TestGoogleProvider_AuthCodeURL(t *testing.T) {
+	srv := identitytest.NewServer(t)
+	login := newLogin()
+	loginURL, err := identity.NewGoogleProvider(srv.GoogleConfig()).AuthCodeURL(context.Background(), login)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(loginURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, login.State, query.Get("state"))
+	assert.Equal(t, login.Nonce, query.Get("nonce"))
+	assert.Equal(t, oauth2.S256ChallengeFromVerifier(login.CodeVerifier), query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, "offline", query.Get("access_type"))
+	assert.Equal(t, "consent", query.Get("prompt"))
+}
+
+func TestGoogleProvider_Exchange(t *testing.T) {
+	ctx := context.Background()
+	srv := identitytest.NewServer(t)
+	provider := identity.NewGoogleProvider(srv.GoogleConfig())
+
+	login := newLogin()
+	loginURL, err := provider.AuthCodeURL(ctx, login)
+	require.NoError(t, err)
+	account, token, err := provider.Exchange(ctx, srv.Authorize(t, loginURL), login)
+	require.NoError(t, err)
+	assert.Equal(t, &port.ExternalIdentity{
+		Provider:      "google",
+		Subject:       "user-1",
+		Email:         "user@example.com",
+		EmailVerified: true,
+		Name:          "Test User",
+		PictureURL:    "https://example.com/user-1.png",
+	}, account)
+	assert.NotEmpty(t, token.RefreshToken, "offline access returns a refresh token")
+
+	// The keys are cached for the next login.
+	login = newLogin()
+	loginURL, err = provider.AuthCodeURL(ctx, login)
+	require.NoError(t, err)
+	_, _, err = provider.Exchange(ctx, srv.Authorize(t, loginURL), login)
+	require.NoError(t, err)
+	assert.Equal(t, 1, srv.JWKSCalls())
+}
+
+func TestGoogleProvider_RejectsForeignLogins(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Code Redeemed Twice", func(t *testing.T) {
+		srv := identitytest.NewServer(t)
+		provider := identity.NewGoogleProvider(srv.GoogleConfig())
+		login := newLogin()
+		loginURL, err := provider.AuthCodeURL(ctx, login)
+		require.NoError(t, err)
+		code := srv.Authorize(t, loginURL)
+		_, _, err = provider.Exchange(ctx, code, login)
+		require.NoError(t, err)
+
+		_, _, err = provider.Exchange(ctx, code, login)
+		assert.ErrorIs(t, err, port.ErrCodeExchange)
+	})
+
+	t.Run("Code Verifier Of Another Login", func(t *testing.T) {
+		srv := identitytest.NewServer(t)
+		provider := identity.NewGoogleProvider(srv.GoogleConfig())
+		login := newLogin()
+		loginURL, err := provider.AuthCodeURL(ctx, login)
+		require.NoError(t, err)
+
+		injected := login
+		injected.CodeVerifier = oauth2.GenerateVerifier()
+		_, _, err = provider.Exchange(ctx, srv.Authorize(t, loginURL), injected)
+		assert.ErrorIs(t, err, port.ErrCodeExchange)
+	})
+
+	t.Run("Nonce Of Another Login", func(t *testing.T) {
+		srv := identitytest.NewServer(t)
+		provider := identity.NewGoogleProvider(srv.GoogleConfig())
+		login := newLogin()
+		loginURL, err := provider.AuthCodeURL(ctx, login)
+		require.NoError(t, err)
+
+		replayed := login
+		replayed.Nonce = rand.Text()
+		_, _, err = provider.Exchange(ctx, srv.Authorize(t, loginURL), replayed)
+		assert.ErrorIs(t, err, port.ErrInvalidIDToken)
+	})
+}
+
+func TestGoogleProvider_VerifiesIDToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(claims jwt.MapClaims)
+	}{
+		{"Other Audience", func(claims jwt.MapClaims) { claims["aud"] = "another-client" }},
+		{"Other Issuer", func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }},
+		{"Expired", func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"No Expiry", func(claims jwt.MapClaims) { delete(claims, "exp") }},
+		{"No Nonce", func(claims jwt.MapClaims) { delete(claims, "nonce") }},
+		{"No Subject", func(claims jwt.MapClaims) { delete(claims, "sub") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			srv := identitytest.NewServer(t)
+			srv.TamperIDTokens(tt.tamper)
+			provider := identity.NewGoogleProvider(srv.GoogleConfig())
+			login := newLogin()
+			loginURL, err := provider.AuthCodeURL(ctx, login)
+			require.NoError(t, err)
+
+			_, _, err = provider.Exchange(ctx, srv.Authorize(t, loginURL), login)
+			assert.ErrorIs(t, err, port.ErrInvalidIDToken)
+		})
+	}
+}
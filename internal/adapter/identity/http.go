@@ -0,0 +1,70 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"quiz-byte/internal/port"
+
+	"golang.org/x/oauth2"
+)
+
+// providerHTTPTimeout bounds each call to an identity provider.
+const providerHTTPTimeout = 10 * time.Second
+
+var providerHTTPClient = &http.Client{Timeout: providerHTTPTimeout}
+
+// withHTTPClient makes the oauth2 package use providerHTTPClient for token requests.
+func withHTTPClient(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, providerHTTPClient)
+}
+
+// authCodeURL builds the consent page URL with the PKCE challenge and, for OpenID Connect
+// providers, the nonce of the request.
+func authCodeURL(cfg *oauth2.Config, req port.AuthRequest, opts ...oauth2.AuthCodeOption) string {
+	opts = append(opts, oauth2.S256ChallengeOption(req.CodeVerifier))
+	if req.Nonce != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("nonce", req.Nonce))
+	}
+	return cfg.AuthCodeURL(req.State, opts...)
+}
+
+// exchangeCode trades the authorization code for a token, proving the login with the PKCE
+// verifier. Failures wrap port.ErrCodeExchange.
+func exchangeCode(ctx context.Context, cfg *oauth2.Config, code string, req port.AuthRequest) (*oauth2.Token, error) {
+	token, err := cfg.Exchange(withHTTPClient(ctx), code, oauth2.VerifierOption(req.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", port.ErrCodeExchange, err)
+	}
+	return token, nil
+}
+
+// getJSON calls a provider API with the access token and decodes the JSON response into out.
+func getJSON(ctx context.Context, url string, accessToken string, accept string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := providerHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned status %d: %s", url, resp.StatusCode, body)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response of %s: %w", url, err)
+	}
+	return nil
+}
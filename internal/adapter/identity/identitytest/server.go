@@ -0,0 +1,403 @@
+// Package identitytest provides a fake OAuth2 authorization server for testing identity providers.
+package identitytest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Client credentials the server accepts.
+const (
+	ClientID     = "test-client-id"
+	ClientSecret = "test-client-secret"
+	RedirectURL  = "http://localhost/auth/callback"
+)
+
+// Account is the user the server signs in.
+type Account struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Server is a fake authorization server and OpenID Connect provider. Its consent page approves
+// every request right away, the token endpoint requires PKCE with S256, and id_tokens are signed
+// with RS256 and carry the nonce of the consent request.
+type Server struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	account         Account
+	issuer          string // Issuer in discovery and id_tokens; the server URL when empty
+	discoveryDown   bool
+	tamperIDToken   func(claims jwt.MapClaims)
+	key             *rsa.PrivateKey
+	keyID           string
+	codes           map[string]authorization
+	accessTokens    map[string]Account
+	refreshTokens   map[string]Account
+	revoked         []string
+	consentRequests []url.Values
+	discoveryCalls  int
+	jwksCalls       int
+}
+
+// authorization is a consent request an authorization code was issued for.
+type authorization struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	account       Account
+}
+
+// NewServer starts a server that signs in a verified user@example.com. It is closed when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		account:       Account{Subject: "user-1", Email: "user@example.com", EmailVerified: true, Name: "Test User", Picture: "https://example.com/user-1.png"},
+		codes:         make(map[string]authorization),
+		accessTokens:  make(map[string]Account),
+		refreshTokens: make(map[string]Account),
+	}
+	s.RotateKey(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.handleDiscovery)
+	mux.HandleFunc("GET /authorize", s.handleAuthorize)
+	mux.HandleFunc("POST /token", s.handleToken)
+	mux.HandleFunc("GET /userinfo", s.handleUserInfo)
+	mux.HandleFunc("GET /jwks", s.handleJWKS)
+	mux.HandleFunc("POST /revoke", s.handleRevoke)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// GoogleConfig points the Google provider at the server.
+func (s *Server) GoogleConfig() config.GoogleOAuthConfig {
+	return config.GoogleOAuthConfig{
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  RedirectURL,
+		AuthURL:      s.URL + "/authorize",
+		TokenURL:     s.URL + "/token",
+		JWKSURL:      s.URL + "/jwks",
+		Issuer:       s.URL,
+		RevokeURL:    s.URL + "/revoke",
+	}
+}
+
+// OIDCConfig configures an OpenID Connect provider that discovers the server.
+func (s *Server) OIDCConfig(name string) config.OIDCProviderConfig {
+	return config.OIDCProviderConfig{
+		Name:         name,
+		IssuerURL:    s.URL + "/",
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  RedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+}
+
+// SetAccount changes the user signed in by later consent requests.
+func (s *Server) SetAccount(account Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.account = account
+}
+
+// SetIssuer makes the server announce another issuer.
+func (s *Server) SetIssuer(issuer string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.issuer = issuer
+}
+
+// SetDiscoveryDown makes the discovery document fail with 503.
+func (s *Server) SetDiscoveryDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discoveryDown = down
+}
+
+// TamperIDTokens edits the claims of the id_tokens issued from now on.
+func (s *Server) TamperIDTokens(tamper func(claims jwt.MapClaims)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tamperIDToken = tamper
+}
+
+// RotateKey replaces the signing key. Only the new key is published.
+func (s *Server) RotateKey(t testing.TB) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate signing key: %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key, s.keyID = key, rand.Text()
+}
+
+// Authorize follows the consent page URL as a browser would and returns the authorization
+// code of the redirect back to the app.
+func (s *Server) Authorize(t testing.TB, loginURL string) string {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(loginURL)
+	if err != nil {
+		t.Fatalf("consent request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("consent request got status %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid redirect: %v", err)
+	}
+	return location.Query().Get("code")
+}
+
+// IssueRefreshToken returns a valid refresh token of the current account.
+func (s *Server) IssueRefreshToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token := "refresh-" + rand.Text()
+	s.refreshTokens[token] = s.account
+	return token
+}
+
+// ConsentRequests returns the query parameters of the consent requests received so far.
+func (s *Server) ConsentRequests() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.consentRequests)
+}
+
+// Revoked returns the tokens revoked so far.
+func (s *Server) Revoked() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.revoked)
+}
+
+// DiscoveryCalls returns how often the discovery document was requested.
+func (s *Server) DiscoveryCalls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.discoveryCalls
+}
+
+// JWKSCalls returns how often the signing keys were requested.
+func (s *Server) JWKSCalls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jwksCalls
+}
+
+func (s *Server) issuerLocked() string {
+	if s.issuer != "" {
+		return s.issuer
+	}
+	return s.URL
+}
+
+func (s *Server) handleDiscovery(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discoveryCalls++
+	if s.discoveryDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                           s.issuerLocked(),
+		"authorization_endpoint":           s.URL + "/authorize",
+		"token_endpoint":                   s.URL + "/token",
+		"userinfo_endpoint":                s.URL + "/userinfo",
+		"jwks_uri":                         s.URL + "/jwks",
+		"code_challenge_methods_supported": []string{"S256"},
+	})
+}
+
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.consentRequests = append(s.consentRequests, query)
+	if query.Get("client_id") != ClientID || query.Get("response_type") != "code" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	code := "code-" + rand.Text()
+	s.codes[code] = authorization{
+		redirectURI:   query.Get("redirect_uri"),
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		account:       s.account,
+	}
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if clientID != ClientID || clientSecret != ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		code := r.PostFormValue("code")
+		auth, ok := s.codes[code]
+		delete(s.codes, code) // Codes are single use
+		if !ok || auth.redirectURI != r.PostFormValue("redirect_uri") {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		challenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(challenge[:]) != auth.codeChallenge {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "code_verifier does not match"})
+			return
+		}
+		idToken, err := s.signIDToken(auth.account, auth.nonce)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+			return
+		}
+		accessToken, refreshToken := "access-"+rand.Text(), "refresh-"+rand.Text()
+		s.accessTokens[accessToken] = auth.account
+		s.refreshTokens[refreshToken] = auth.account
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token":  accessToken,
+			"refresh_token": refreshToken,
+			"id_token":      idToken,
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	case "refresh_token":
+		account, ok := s.refreshTokens[r.PostFormValue("refresh_token")]
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		accessToken := "access-" + rand.Text()
+		s.accessTokens[accessToken] = account
+		writeJSON(w, http.StatusOK, map[string]any{"access_token": accessToken, "token_type": "Bearer", "expires_in": 3600})
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+	}
+}
+
+func (s *Server) signIDToken(account Account, nonce string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.issuerLocked(),
+		"aud":            ClientID,
+		"sub":            account.Subject,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"email":          account.Email,
+		"email_verified": account.EmailVerified,
+		"name":           account.Name,
+		"picture":        account.Picture,
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	if s.tamperIDToken != nil {
+		s.tamperIDToken(claims)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.keyID
+	return token.SignedString(s.key)
+}
+
+func (s *Server) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, ok := s.accessTokens[bearerToken(r)]
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"sub":            account.Subject,
+		"email":          account.Email,
+		"email_verified": account.EmailVerified,
+		"name":           account.Name,
+		"picture":        account.Picture,
+	})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jwksCalls++
+	writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"use": "sig",
+		"alg": "RS256",
+		"kid": s.keyID,
+		"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+	}}})
+}
+
+func (s *Server) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked = append(s.revoked, r.PostFormValue("token"))
+}
+
+func bearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if len(header) <= len(prefix) || header[:len(prefix)] != prefix {
+		return ""
+	}
+	return header[len(prefix):]
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,207 @@
+package identity
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"quiz-byte/internal/port"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// idTokenLeeway allows for clock skew between us and the provider.
+	idTokenLeeway = time.Minute
+	// jwksRefetchInterval limits how often an unknown kid makes the keys be fetched again.
+	jwksRefetchInterval = time.Minute
+)
+
+// idTokenClaims are the claims read from an OpenID Connect id_token.
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"` // Some providers send the string "true"
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+}
+
+// emailVerified reports whether the email_verified claim is true.
+func emailVerified(claim any) bool {
+	return claim == true || claim == "true"
+}
+
+// idTokenVerifier checks id_tokens against the keys the provider publishes at its JWKS URL. The
+// keys are cached and fetched again when a token is signed with a key that is not known yet.
+type idTokenVerifier struct {
+	issuers  []string // Accepted iss values
+	clientID string   // Expected audience
+	jwksURL  string
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey // By kid
+	fetchedAt time.Time
+}
+
+func newIDTokenVerifier(jwksURL string, clientID string, issuers ...string) *idTokenVerifier {
+	return &idTokenVerifier{issuers: issuers, clientID: clientID, jwksURL: jwksURL}
+}
+
+// verifyToken verifies the id_token of the token response. Failures wrap port.ErrInvalidIDToken.
+func (v *idTokenVerifier) verifyToken(ctx context.Context, token *oauth2.Token, nonce string) (*idTokenClaims, error) {
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", port.ErrInvalidIDToken)
+	}
+	return v.verify(ctx, rawIDToken, nonce)
+}
+
+// verify checks the signature, issuer, audience, expiry and nonce of the id_token.
+func (v *idTokenVerifier) verify(ctx context.Context, rawIDToken string, nonce string) (*idTokenClaims, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims,
+		func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			return v.key(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithAudience(v.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(idTokenLeeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", port.ErrInvalidIDToken, err)
+	}
+	if !slices.Contains(v.issuers, claims.Issuer) {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", port.ErrInvalidIDToken, claims.Issuer)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: no subject", port.ErrInvalidIDToken)
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce does not match the login", port.ErrInvalidIDToken)
+	}
+	return claims, nil
+}
+
+// key returns the public key with the kid, fetching the provider's keys if it is not cached.
+// An empty kid is accepted when the provider publishes a single key.
+func (v *idTokenVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if key, ok := v.lookup(kid); ok {
+		return key, nil
+	}
+	if time.Since(v.fetchedAt) >= jwksRefetchInterval {
+		keys, err := fetchJWKS(ctx, v.jwksURL)
+		if err != nil {
+			return nil, err
+		}
+		v.keys, v.fetchedAt = keys, time.Now()
+		if key, ok := v.lookup(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("signing key %q is not published by the provider", kid)
+}
+
+func (v *idTokenVerifier) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// jsonWebKey is a public key of the provider's JSON Web Key Set (RFC 7517).
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"` // RSA keys
+	E       string `json:"e"` // RSA keys
+	X       string `json:"x"` // EC keys
+	Y       string `json:"y"` // EC keys
+}
+
+// fetchJWKS returns the signing keys of a JSON Web Key Set. Keys of unsupported types are skipped.
+func fetchJWKS(ctx context.Context, jwksURL string) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, jwksURL, "", "application/json", &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("provider publishes no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch {
+	case k.KeyType == "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("rsa exponent out of range")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case k.KeyType == "EC" && k.Curve == "P-256":
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !key.Curve.IsOnCurve(x, y) {
+			return nil, errors.New("ec point is not on the curve")
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.KeyType)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,38 @@
+package identity
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/adapter/identity/identitytest"
+	"quiz-byte/internal/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestIDTokenVerifier_FollowsKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	srv := identitytest.NewServer(t)
+	provider := NewGoogleProvider(srv.GoogleConfig()).(*googleProvider)
+	signIn := func() error {
+		login := port.AuthRequest{State: rand.Text(), Nonce: rand.Text(), CodeVerifier: oauth2.GenerateVerifier()}
+		loginURL, err := provider.AuthCodeURL(ctx, login)
+		require.NoError(t, err)
+		_, _, err = provider.Exchange(ctx, srv.Authorize(t, loginURL), login)
+		return err
+	}
+	require.NoError(t, signIn())
+
+	// Unknown keys do not make the keys be fetched again right away.
+	srv.RotateKey(t)
+	assert.ErrorIs(t, signIn(), port.ErrInvalidIDToken)
+	assert.Equal(t, 1, srv.JWKSCalls())
+
+	provider.verifier.fetchedAt = time.Now().Add(-jwksRefetchInterval)
+	assert.NoError(t, signIn())
+	assert.Equal(t, 2, srv.JWKSCalls())
+}
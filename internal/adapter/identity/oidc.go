@@ -0,0 +1,158 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/port"
+
+	"golang.org/x/oauth2"
+)
+
+// oidcDiscoveryPath is appended to the issuer URL to find the provider's metadata.
+const oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+// oidcProvider signs users in with a generic OpenID Connect provider. Its endpoints are discovered
+// on first use rather than at startup, so the API still starts while the provider is down. The
+// account is read from the verified id_token; the userinfo endpoint is only called when the
+// id_token carries no email.
+type oidcProvider struct {
+	cfg config.OIDCProviderConfig
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery // nil until discovery succeeded
+}
+
+// oidcDiscovery is what the provider's discovery document resolves to.
+type oidcDiscovery struct {
+	oauth2Config *oauth2.Config
+	verifier     *idTokenVerifier
+	userInfoURL  string // Optional
+}
+
+// NewOIDCProvider creates an OpenID Connect identity provider.
+func NewOIDCProvider(cfg config.OIDCProviderConfig) port.IdentityProvider {
+	return &oidcProvider{cfg: cfg}
+}
+
+type oidcDiscoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcUserInfo struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"` // Some providers send the string "true"
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+}
+
+func (p *oidcProvider) Name() string {
+	return p.cfg.Name
+}
+
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, req port.AuthRequest) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return authCodeURL(discovery.oauth2Config, req), nil
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code string, req port.AuthRequest) (*port.ExternalIdentity, *oauth2.Token, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	token, err := exchangeCode(ctx, discovery.oauth2Config, code, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	claims, err := discovery.verifier.verifyToken(ctx, token, req.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	identity := &port.ExternalIdentity{
+		Provider:      p.cfg.Name,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: emailVerified(claims.EmailVerified),
+		Name:          claims.Name,
+		PictureURL:    claims.Picture,
+	}
+	preferredUsername := claims.PreferredUsername
+	if identity.Email == "" && discovery.userInfoURL != "" {
+		var userInfo oidcUserInfo
+		if err := getJSON(ctx, discovery.userInfoURL, token.AccessToken, "application/json", &userInfo); err != nil {
+			return nil, nil, fmt.Errorf("failed to get %s user info: %w", p.cfg.Name, err)
+		}
+		// The userinfo response must be about the user the id_token was issued for.
+		if userInfo.Subject != claims.Subject {
+			return nil, nil, fmt.Errorf("%s user info is for subject %q, the id_token for %q", p.cfg.Name, userInfo.Subject, claims.Subject)
+		}
+		identity.Email = userInfo.Email
+		identity.EmailVerified = emailVerified(userInfo.EmailVerified)
+		if identity.Name == "" {
+			identity.Name = userInfo.Name
+		}
+		if identity.PictureURL == "" {
+			identity.PictureURL = userInfo.Picture
+		}
+		if preferredUsername == "" {
+			preferredUsername = userInfo.PreferredUsername
+		}
+	}
+	if identity.Name == "" {
+		identity.Name = preferredUsername
+	}
+	return identity, token, nil
+}
+
+// discover resolves the provider's endpoints and keys from its discovery document. A failed
+// discovery is not cached and is retried on the next call.
+func (p *oidcProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	issuer := strings.TrimSuffix(p.cfg.IssuerURL, "/")
+	var doc oidcDiscoveryDocument
+	if err := getJSON(ctx, issuer+oidcDiscoveryPath, "", "application/json", &doc); err != nil {
+		return nil, fmt.Errorf("failed to discover %s endpoints: %w", p.cfg.Name, err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("%s discovery document is for issuer %q, expected %q", p.cfg.Name, doc.Issuer, p.cfg.IssuerURL)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New(p.cfg.Name + " discovery document lacks the authorization or token endpoint or the jwks_uri")
+	}
+
+	p.discovery = &oidcDiscovery{
+		oauth2Config: &oauth2.Config{
+			ClientID:     p.cfg.ClientID,
+			ClientSecret: p.cfg.ClientSecret,
+			RedirectURL:  p.cfg.RedirectURL,
+			Scopes:       p.cfg.Scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  doc.AuthorizationEndpoint,
+				TokenURL: doc.TokenEndpoint,
+			},
+		},
+		// The id_token carries the issuer exactly as the discovery document states it.
+		verifier:    newIDTokenVerifier(doc.JWKSURI, p.cfg.ClientID, doc.Issuer),
+		userInfoURL: doc.UserInfoEndpoint,
+	}
+	return p.discovery, nil
+}
@@ -0,0 +1,110 @@
+package identity_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"quiz-byte/internal/adapter/identity"
+	"quiz-byte/internal/adapter/identity/identitytest"
+	"quiz-byte/internal/port"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestOIDCProvider_DiscoveryAndExchange(t *testing.T) {
+	ctx := context.Background()
+	srv := identitytest.NewServer(t)
+	provider := identity.NewOIDCProvider(srv.OIDCConfig("corp"))
+	assert.Equal(t, "corp", provider.Name())
+
+	login := newLogin()
+	loginURL, err := provider.AuthCodeURL(ctx, login)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(loginURL, srv.URL+"/authorize?"))
+	parsed, err := url.Parse(loginURL)
+	require.NoError(t, err)
+	assert.Equal(t, "openid email profile", parsed.Query().Get("scope"))
+	assert.Equal(t, login.State, parsed.Query().Get("state"))
+	assert.Equal(t, login.Nonce, parsed.Query().Get("nonce"))
+	assert.Equal(t, oauth2.S256ChallengeFromVerifier(login.CodeVerifier), parsed.Query().Get("code_challenge"))
+
+	account, _, err := provider.Exchange(ctx, srv.Authorize(t, loginURL), login)
+	require.NoError(t, err)
+	assert.Equal(t, &port.ExternalIdentity{
+		Provider:      "corp",
+		Subject:       "user-1",
+		Email:         "user@example.com",
+		EmailVerified: true,
+		Name:          "Test User",
+		PictureURL:    "https://example.com/user-1.png",
+	}, account)
+	assert.Equal(t, 1, srv.DiscoveryCalls(), "the discovery document is fetched once")
+
+	_, _, err = provider.Exchange(ctx, "bad-code", login)
+	assert.ErrorIs(t, err, port.ErrCodeExchange)
+}
+
+func TestOIDCProvider_UserInfoWhenIDTokenHasNoEmail(t *testing.T) {
+	ctx := context.Background()
+	srv := identitytest.NewServer(t)
+	srv.SetAccount(identitytest.Account{Subject: "corp-user-1", Email: "user@corp.example", EmailVerified: true})
+	srv.TamperIDTokens(func(claims jwt.MapClaims) {
+		delete(claims, "email")
+		delete(claims, "email_verified")
+		claims["preferred_username"] = "jdoe"
+	})
+	provider := identity.NewOIDCProvider(srv.OIDCConfig("corp"))
+
+	login := newLogin()
+	loginURL, err := provider.AuthCodeURL(ctx, login)
+	require.NoError(t, err)
+	account, _, err := provider.Exchange(ctx, srv.Authorize(t, loginURL), login)
+	require.NoError(t, err)
+	assert.Equal(t, "corp-user-1", account.Subject)
+	assert.Equal(t, "user@corp.example", account.Email)
+	assert.True(t, account.EmailVerified)
+	assert.Equal(t, "jdoe", account.Name)
+}
+
+func TestOIDCProvider_RejectsNonceOfAnotherLogin(t *testing.T) {
+	ctx := context.Background()
+	srv := identitytest.NewServer(t)
+	provider := identity.NewOIDCProvider(srv.OIDCConfig("corp"))
+
+	login := newLogin()
+	loginURL, err := provider.AuthCodeURL(ctx, login)
+	require.NoError(t, err)
+	replayed := login
+	replayed.Nonce = newLogin().Nonce
+	_, _, err = provider.Exchange(ctx, srv.Authorize(t, loginURL), replayed)
+	assert.ErrorIs(t, err, port.ErrInvalidIDToken)
+}
+
+func TestOIDCProvider_RetriesFailedDiscovery(t *testing.T) {
+	ctx := context.Background()
+	srv := identitytest.NewServer(t)
+	provider := identity.NewOIDCProvider(srv.OIDCConfig("corp"))
+
+	srv.SetDiscoveryDown(true)
+	_, err := provider.AuthCodeURL(ctx, newLogin())
+	assert.Error(t, err)
+
+	srv.SetDiscoveryDown(false)
+	_, err = provider.AuthCodeURL(ctx, newLogin())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, srv.DiscoveryCalls())
+}
+
+func TestOIDCProvider_RejectsIssuerMismatch(t *testing.T) {
+	srv := identitytest.NewServer(t)
+	srv.SetIssuer("https://evil.example.com")
+	provider := identity.NewOIDCProvider(srv.OIDCConfig("corp"))
+
+	_, err := provider.AuthCodeURL(context.Background(), newLogin())
+	assert.ErrorContains(t, err, "issuer")
+}
@@ -0,0 +1,43 @@
+package identity
+
+import (
+	"fmt"
+	"regexp"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/port"
+)
+
+// providerNamePattern keeps OIDC provider names usable as a path segment of the login routes.
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// NewFromConfig builds the identity providers enabled in cfg: Google and GitHub when their client
+// ID is set, and every configured OpenID Connect provider.
+func NewFromConfig(cfg config.AuthConfig) ([]port.IdentityProvider, error) {
+	var providers []port.IdentityProvider
+	names := make(map[string]bool)
+	if cfg.GoogleOAuth.ClientID != "" {
+		providers = append(providers, NewGoogleProvider(cfg.GoogleOAuth))
+		names[domain.IdentityProviderGoogle] = true
+	}
+	if cfg.GitHubOAuth.ClientID != "" {
+		providers = append(providers, NewGitHubProvider(cfg.GitHubOAuth))
+		names[domain.IdentityProviderGitHub] = true
+	}
+
+	for _, oidcCfg := range cfg.OIDCProviders {
+		if !providerNamePattern.MatchString(oidcCfg.Name) {
+			return nil, fmt.Errorf("invalid OIDC provider name %q (use lowercase letters, digits, '-' and '_')", oidcCfg.Name)
+		}
+		if names[oidcCfg.Name] || oidcCfg.Name == domain.IdentityProviderGoogle || oidcCfg.Name == domain.IdentityProviderGitHub {
+			return nil, fmt.Errorf("identity provider %q is configured more than once", oidcCfg.Name)
+		}
+		if oidcCfg.IssuerURL == "" || oidcCfg.ClientID == "" {
+			return nil, fmt.Errorf("OIDC provider %q needs an issuer_url and a client_id", oidcCfg.Name)
+		}
+		providers = append(providers, NewOIDCProvider(oidcCfg))
+		names[oidcCfg.Name] = true
+	}
+	return providers, nil
+}
@@ -0,0 +1,63 @@
+package identity_test
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"quiz-byte/internal/adapter/identity"
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.Initialize(config.LoggerConfig{}); err != nil {
+		log.Fatalf("Failed to initialize logger for identity tests: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestNewFromConfig(t *testing.T) {
+	providers, err := identity.NewFromConfig(config.AuthConfig{
+		GoogleOAuth: config.GoogleOAuthConfig{ClientID: "google-client"},
+		OIDCProviders: []config.OIDCProviderConfig{
+			{Name: "corp", IssuerURL: "https://sso.example.com", ClientID: "corp-client"},
+		},
+	})
+	require.NoError(t, err)
+	var names []string
+	for _, provider := range providers {
+		names = append(names, provider.Name())
+	}
+	assert.Equal(t, []string{"google", "corp"}, names, "GitHub is disabled without a client ID")
+
+	providers, err = identity.NewFromConfig(config.AuthConfig{})
+	require.NoError(t, err)
+	assert.Empty(t, providers)
+}
+
+func TestNewFromConfig_InvalidOIDCProviders(t *testing.T) {
+	testCases := []struct {
+		name     string
+		provider config.OIDCProviderConfig
+	}{
+		{"Missing Name", config.OIDCProviderConfig{IssuerURL: "https://sso.example.com", ClientID: "client"}},
+		{"Name Not URL Safe", config.OIDCProviderConfig{Name: "Corp SSO", IssuerURL: "https://sso.example.com", ClientID: "client"}},
+		{"Built-in Name", config.OIDCProviderConfig{Name: "github", IssuerURL: "https://sso.example.com", ClientID: "client"}},
+		{"Missing Issuer", config.OIDCProviderConfig{Name: "corp", ClientID: "client"}},
+		{"Missing Client ID", config.OIDCProviderConfig{Name: "corp", IssuerURL: "https://sso.example.com"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := identity.NewFromConfig(config.AuthConfig{OIDCProviders: []config.OIDCProviderConfig{tc.provider}})
+			assert.Error(t, err)
+		})
+	}
+
+	duplicate := config.OIDCProviderConfig{Name: "corp", IssuerURL: "https://sso.example.com", ClientID: "client"}
+	_, err := identity.NewFromConfig(config.AuthConfig{OIDCProviders: []config.OIDCProviderConfig{duplicate, duplicate}})
+	assert.Error(t, err)
+}
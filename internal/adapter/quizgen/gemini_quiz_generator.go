@@ -1,25 +1,96 @@
 package quizgen
 
 import (
-	"bytes" // Added for gob
+	"bytes"
 	"context"
 	"crypto/sha256"
-	"encoding/gob" // Added for gob
+	"encoding/gob"
 	"encoding/hex"
-	// "encoding/json" // No longer used for cache data, only for parsing simulated LLM response string
-	"encoding/json" // Keep for unmarshalling simulatedJsonResponse
+	"encoding/json"
+	"errors"
 	"fmt"
-	"io"      // For io.EOF with gob
-	"strings" // For building the prompt
-	"time"    // For cache TTL
+	"io"
+	"strings"
+	"time"
 
-	"quiz-byte/internal/cache" // Added for caching
-	"quiz-byte/internal/config" // Added for config access (e.g., TTL)
+	"quiz-byte/internal/cache"
+	"quiz-byte/internal/config"
 	"quiz-byte/internal/domain"
-	"go.uber.org/zap"                // For logging the prompt
-	"golang.org/x/sync/singleflight" // Added for singleflight
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/openai"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrMalformedResponse is returned when the model keeps answering with output that
+// does not match the expected JSON schema after all retries are used up.
+var ErrMalformedResponse = errors.New("malformed LLM response")
+
+const (
+	defaultGeminiTimeout    = 60 * time.Second
+	defaultLLMResponseTTL   = 24 * time.Hour
+	quizGenerationTemp      = 0.7
+	scoreEvaluationTemp     = 0.4
+	maxKeywordsPerCandidate = 10
 )
 
+// quizCandidatesSchema is the structured-output schema for GenerateQuizCandidates.
+// Structured output requires an object at the root, so the quizzes are wrapped.
+var quizCandidatesSchema = &openai.ResponseFormat{
+	Type: "json_schema",
+	JSONSchema: &openai.ResponseFormatJSONSchema{
+		Name:   "quiz_candidates",
+		Strict: true,
+		Schema: &openai.ResponseFormatJSONSchemaProperty{
+			Type:     "object",
+			Required: []string{"quizzes"},
+			Properties: map[string]*openai.ResponseFormatJSONSchemaProperty{
+				"quizzes": {
+					Type: "array",
+					Items: &openai.ResponseFormatJSONSchemaProperty{
+						Type:     "object",
+						Required: []string{"question", "model_answer", "keywords", "difficulty"},
+						Properties: map[string]*openai.ResponseFormatJSONSchemaProperty{
+							"question":     {Type: "string"},
+							"model_answer": {Type: "string"},
+							"keywords":     {Type: "array", Items: &openai.ResponseFormatJSONSchemaProperty{Type: "string"}},
+							"difficulty":   {Type: "string", Enum: []interface{}{"easy", "medium", "hard"}},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// scoreEvaluationsSchema is the structured-output schema for GenerateScoreEvaluationsForQuiz.
+var scoreEvaluationsSchema = &openai.ResponseFormat{
+	Type: "json_schema",
+	JSONSchema: &openai.ResponseFormatJSONSchema{
+		Name:   "score_evaluations",
+		Strict: true,
+		Schema: &openai.ResponseFormatJSONSchemaProperty{
+			Type:     "object",
+			Required: []string{"score_evaluations"},
+			Properties: map[string]*openai.ResponseFormatJSONSchemaProperty{
+				"score_evaluations": {
+					Type: "array",
+					Items: &openai.ResponseFormatJSONSchemaProperty{
+						Type:     "object",
+						Required: []string{"score_range", "sample_answers", "explanation"},
+						Properties: map[string]*openai.ResponseFormatJSONSchemaProperty{
+							"score_range":    {Type: "string"},
+							"sample_answers": {Type: "array", Items: &openai.ResponseFormatJSONSchemaProperty{Type: "string"}},
+							"explanation":    {Type: "string"},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
 // hashString computes SHA256 hash of a string and returns it as a hex string.
 func hashString(text string) string {
 	hasher := sha256.New()
@@ -32,21 +103,22 @@ func HashStringForTest(text string) string {
 	return hashString(text)
 }
 
-// GeminiQuizGenerator implements the domain.QuizGenerationService interface using a conceptual LangchainGo client.
+// GeminiQuizGenerator implements the domain.QuizGenerationService interface on top of
+// Gemini's OpenAI-compatible endpoint through langchaingo.
 type GeminiQuizGenerator struct {
-	// In a real scenario, this would be the LangchainGo Gemini client instance.
-	// For example: client *gemini.GenerativeModel (or whatever the specific type is)
-	langchainClient interface{}
-	apiKey          string
-	modelName       string
-	logger          *zap.Logger
-	cache           domain.Cache   // Added for caching
-	config          *config.Config // Added for config access
-	sfGroup         singleflight.Group // Added for singleflight
+	quizLLM    llms.Model // Client constrained to quizCandidatesSchema
+	evalLLM    llms.Model // Client constrained to scoreEvaluationsSchema
+	modelName  string
+	maxRetries int
+	timeout    time.Duration
+	logger     *zap.Logger
+	cache      domain.Cache
+	config     *config.Config
+	sfGroup    singleflight.Group
 }
 
 // NewGeminiQuizGenerator creates a new instance of GeminiQuizGenerator.
-// The actual LangchainGo client initialization would happen here.
+// The endpoint, retry count and per-call timeout are read from config.LLMProviders.Gemini.
 func NewGeminiQuizGenerator(apiKey string, modelName string, logger *zap.Logger, cache domain.Cache, config *config.Config) (domain.QuizGenerationService, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("Gemini API key cannot be empty")
@@ -55,44 +127,98 @@ func NewGeminiQuizGenerator(apiKey string, modelName string, logger *zap.Logger,
 		return nil, fmt.Errorf("Gemini model name cannot be empty")
 	}
 	if cache == nil {
-		// Depending on policy, this could be a fatal error or a warning allowing operation without cache.
-		// For now, let's assume it's required.
 		return nil, fmt.Errorf("cache instance cannot be nil for GeminiQuizGenerator")
 	}
 	if config == nil {
 		return nil, fmt.Errorf("config instance cannot be nil for GeminiQuizGenerator")
 	}
-	logger.Info("Initializing GeminiQuizGenerator", zap.String("model", modelName))
-	// Placeholder for actual LangchainGo client init
-	// client := langchain.NewGeminiClient(apiKey, modelName) etc.
+
+	geminiCfg := config.LLMProviders.Gemini
+	baseURL := geminiCfg.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL()
+	}
+	timeout := geminiCfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultGeminiTimeout
+	}
+	maxRetries := geminiCfg.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	quizLLM, err := openai.New(
+		openai.WithToken(apiKey),
+		openai.WithModel(modelName),
+		openai.WithBaseURL(baseURL),
+		openai.WithResponseFormat(quizCandidatesSchema),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client for quiz generation: %w", err)
+	}
+	evalLLM, err := openai.New(
+		openai.WithToken(apiKey),
+		openai.WithModel(modelName),
+		openai.WithBaseURL(baseURL),
+		openai.WithResponseFormat(scoreEvaluationsSchema),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client for score evaluations: %w", err)
+	}
+
+	logger.Info("Initializing GeminiQuizGenerator",
+		zap.String("model", modelName),
+		zap.String("base_url", baseURL),
+		zap.Int("max_retries", maxRetries),
+		zap.Duration("timeout", timeout))
 	return &GeminiQuizGenerator{
-		langchainClient: nil, // Placeholder
-		apiKey:          apiKey,
-		modelName:       modelName,
-		logger:          logger,
-		cache:           cache,  // Initialize cache
-		config:          config, // Initialize config
+		quizLLM:    quizLLM,
+		evalLLM:    evalLLM,
+		modelName:  modelName,
+		maxRetries: maxRetries,
+		timeout:    timeout,
+		logger:     logger,
+		cache:      cache,
+		config:     config,
 	}, nil
 }
 
-// GenerateQuizCandidates constructs a prompt and simulates an LLM call.
-// This method matches the domain.QuizGenerationService interface.
-func (a *GeminiQuizGenerator) GenerateQuizCandidates(ctx context.Context, subCategoryName string, existingKeywords []string, numQuestions int) ([]*domain.NewQuizData, error) {
-	generatedQuizzes := make([]*domain.NewQuizData, 0) // Initialize as empty slice
+func defaultBaseURL() string {
+	return config.DefaultGeminiBaseURL
+}
+
+// llmQuizCandidate is the wire format of a single generated quiz.
+type llmQuizCandidate struct {
+	Question    string   `json:"question"`
+	ModelAnswer string   `json:"model_answer"`
+	Keywords    []string `json:"keywords"`
+	Difficulty  string   `json:"difficulty"`
+}
+
+type llmQuizCandidatesResponse struct {
+	Quizzes []llmQuizCandidate `json:"quizzes"`
+}
+
+type llmScoreEvaluationResponse struct {
+	ScoreEvaluations []domain.ScoreEvaluationDetail `json:"score_evaluations"`
+}
 
+// buildQuizCandidatesPrompt renders the generation prompt. Its hash is the cache key.
+func buildQuizCandidatesPrompt(subCategoryName string, existingKeywords []string, numQuestions int) string {
 	promptTemplate := `
 You are an expert quiz generator. Your task is to create %d unique and high-quality quiz questions
 for the sub-category: "%s".
 
 Avoid generating questions that are too similar to existing themes covered by these keywords: [%s].
 
-For each question, provide the following information in JSON format:
+For each question, provide the following information:
 1.  "question": The quiz question text.
 2.  "model_answer": A concise and accurate model answer.
 3.  "keywords": An array of 2-5 relevant keywords for this question.
 4.  "difficulty": A string indicating the difficulty ("easy", "medium", or "hard").
 
-Ensure your entire response is a single JSON array containing %d JSON objects, each representing a quiz.
+Respond with ONLY a single JSON object of the form {"quizzes": [...]} containing exactly %d quiz objects.
+Do not wrap the JSON in markdown and do not add any other fields.
 Example for one quiz object:
 {
   "question": "What is the capital of France?",
@@ -101,7 +227,23 @@ Example for one quiz object:
   "difficulty": "easy"
 }
 `
-	formattedPrompt := fmt.Sprintf(promptTemplate, numQuestions, subCategoryName, strings.Join(existingKeywords, ", "), numQuestions)
+	return fmt.Sprintf(promptTemplate, numQuestions, subCategoryName, strings.Join(existingKeywords, ", "), numQuestions)
+}
+
+// BuildQuizCandidatesPromptForTest is a test helper to access the unexported prompt builder.
+func BuildQuizCandidatesPromptForTest(subCategoryName string, existingKeywords []string, numQuestions int) string {
+	return buildQuizCandidatesPrompt(subCategoryName, existingKeywords, numQuestions)
+}
+
+// GenerateQuizCandidates asks the model for numQuestions new quizzes in the given sub-category.
+// This method matches the domain.QuizGenerationService interface.
+func (a *GeminiQuizGenerator) GenerateQuizCandidates(ctx context.Context, subCategoryName string, existingKeywords []string, numQuestions int) ([]*domain.NewQuizData, error) {
+	generatedQuizzes := make([]*domain.NewQuizData, 0)
+	if numQuestions <= 0 {
+		return generatedQuizzes, nil
+	}
+
+	formattedPrompt := buildQuizCandidatesPrompt(subCategoryName, existingKeywords, numQuestions)
 	promptHash := hashString(formattedPrompt)
 	cacheKey := cache.GenerateCacheKey("llm_response", "gemini", promptHash)
 
@@ -111,100 +253,63 @@ Example for one quiz object:
 		if err == nil { // Cache hit
 			a.logger.Info("LLM response cache hit", zap.String("cacheKey", cacheKey), zap.String("promptHash", promptHash))
 			var cachedQuizzesData []*domain.NewQuizData
-			byteReader := bytes.NewReader([]byte(cachedDataString))
-			decoder := gob.NewDecoder(byteReader)
+			decoder := gob.NewDecoder(bytes.NewReader([]byte(cachedDataString)))
 			if errDecode := decoder.Decode(&cachedQuizzesData); errDecode == nil {
-				// Basic validation for cached data
 				for _, qd := range cachedQuizzesData {
 					if qd.Question == "" || qd.ModelAnswer == "" || len(qd.Keywords) == 0 || qd.Difficulty == "" {
 						a.logger.Warn("Cached LLM response contained incomplete quiz data (gob)", zap.Any("quiz_data", qd), zap.String("cacheKey", cacheKey))
-						continue // Skip this incomplete data
+						continue
 					}
 					generatedQuizzes = append(generatedQuizzes, qd)
 				}
-				// If generatedQuizzes is still empty after filtering, it means all cached items were invalid.
-				// In such a case, we might want to proceed as a cache miss.
-				// For now, if any valid data was found, return it.
 				if len(generatedQuizzes) > 0 {
 					a.logger.Info("Successfully decoded cached LLM response (gob)", zap.Int("num_quizzes_from_cache", len(generatedQuizzes)))
 					return generatedQuizzes, nil
 				}
 				a.logger.Warn("All cached LLM quiz data items were invalid (gob)", zap.String("cacheKey", cacheKey))
-				// Proceed as cache miss if all items were invalid
 			} else if errDecode == io.EOF {
 				a.logger.Warn("Cached LLM response data is empty (EOF) (gob)", zap.String("cacheKey", cacheKey))
 			} else {
 				a.logger.Error("Failed to decode cached LLM response (gob)", zap.Error(errDecode), zap.String("cacheKey", cacheKey))
 			}
-			// Proceed to LLM call if decoding failed
 		} else if err != domain.ErrCacheMiss {
 			a.logger.Error("Failed to get from cache (not a cache miss)", zap.Error(err), zap.String("cacheKey", cacheKey))
-			// Proceed to LLM call, but log that cache check failed for a reason other than miss
 		} else {
 			a.logger.Info("LLM response cache miss", zap.String("cacheKey", cacheKey), zap.String("promptHash", promptHash))
 		}
-	} else {
-		a.logger.Warn("Cache client is nil, skipping cache check for LLM response.")
 	}
 
 	// Cache Miss or error during cache read: Use singleflight
 	res, sfErr, _ := a.sfGroup.Do(cacheKey, func() (interface{}, error) {
-		a.logger.Info("Simulating LLM call (within singleflight)", zap.String("cacheKey", cacheKey), zap.String("promptHash", promptHash))
-
-		// Simulate LLM response (hardcoded JSON string)
-		simulatedResponses := []string{}
-		for i := 0; i < numQuestions; i++ {
-			simulatedResponses = append(simulatedResponses, fmt.Sprintf(`
-			{
-				"Question": "Simulated Question %d for %s (from LLM): What is the primary function of a CPU?",
-				"ModelAnswer": "The primary function of a CPU is to execute instructions from computer programs.",
-				"Keywords": ["cpu", "processor", "computer architecture", "question%d"],
-				"Difficulty": "%s"
-			}`, i+1, subCategoryName, i+1, []string{"easy", "medium", "hard"}[i%3]))
-		}
-		simulatedJsonResponse := fmt.Sprintf("[%s]", strings.Join(simulatedResponses, ","))
-		a.logger.Info("Simulated LLM JSON response generated (singleflight)", zap.String("promptHash", promptHash))
-
-		var llmQuizzesData []*domain.NewQuizData
-		if err := json.Unmarshal([]byte(simulatedJsonResponse), &llmQuizzesData); err != nil {
-			a.logger.Error("Failed to unmarshal simulated LLM JSON response (singleflight)", zap.Error(err), zap.String("json_response", simulatedJsonResponse))
-			return nil, fmt.Errorf("failed to parse LLM response: %w. Response: %s", err, simulatedJsonResponse)
-		}
+		a.logger.Info("Calling Gemini for quiz candidates (within singleflight)", zap.String("cacheKey", cacheKey), zap.String("promptHash", promptHash))
 
-		// Filter incomplete data from LLM before caching and returning
-		validLlmQuizzesData := make([]*domain.NewQuizData, 0, len(llmQuizzesData))
-		for _, qd := range llmQuizzesData {
-			if qd.Question == "" || qd.ModelAnswer == "" || len(qd.Keywords) == 0 || qd.Difficulty == "" {
-				a.logger.Warn("Simulated LLM generated incomplete quiz data (singleflight)", zap.Any("quiz_data", qd))
-				continue
+		var quizzes []*domain.NewQuizData
+		err := a.generateWithRetry(ctx, a.quizLLM, formattedPrompt, quizGenerationTemp, func(raw string) error {
+			parsed, parseErr := parseQuizCandidates(raw, numQuestions)
+			if parseErr != nil {
+				return parseErr
 			}
-			validLlmQuizzesData = append(validLlmQuizzesData, qd)
+			quizzes = parsed
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 
-
-		// Store in cache if successful and cache client is available
-		if a.cache != nil { // Check a.cache, not s.cache
+		if a.cache != nil {
 			var buffer bytes.Buffer
-			encoder := gob.NewEncoder(&buffer)
-			if errEncode := encoder.Encode(validLlmQuizzesData); errEncode != nil { // Cache the filtered data
+			if errEncode := gob.NewEncoder(&buffer).Encode(quizzes); errEncode != nil {
 				a.logger.Error("Failed to gob encode LLM response for caching (singleflight)", zap.Error(errEncode), zap.String("cacheKey", cacheKey))
-				// Return data even if caching fails
-				return validLlmQuizzesData, nil
+				return quizzes, nil
+			}
+			cacheTTL := a.llmResponseTTL()
+			if errCacheSet := a.cache.Set(ctx, cacheKey, buffer.String(), cacheTTL); errCacheSet != nil {
+				a.logger.Error("Failed to set LLM response to cache (gob, singleflight)", zap.Error(errCacheSet), zap.String("cacheKey", cacheKey))
 			} else {
-				defaultLLMResponseTTL := 24 * time.Hour
-				cacheTTL := defaultLLMResponseTTL
-				if a.config != nil && a.config.CacheTTLs.LLMResponse != "" {
-					cacheTTL = a.config.ParseTTLStringOrDefault(a.config.CacheTTLs.LLMResponse, defaultLLMResponseTTL)
-				}
-
-				if errCacheSet := a.cache.Set(ctx, cacheKey, buffer.String(), cacheTTL); errCacheSet != nil {
-					a.logger.Error("Failed to set LLM response to cache (gob, singleflight)", zap.Error(errCacheSet), zap.String("cacheKey", cacheKey))
-				} else {
-					a.logger.Info("LLM response cached successfully (gob, singleflight)", zap.String("cacheKey", cacheKey), zap.Duration("ttl", cacheTTL))
-				}
+				a.logger.Info("LLM response cached successfully (gob, singleflight)", zap.String("cacheKey", cacheKey), zap.Duration("ttl", cacheTTL))
 			}
 		}
-		return validLlmQuizzesData, nil
+		return quizzes, nil
 	})
 
 	if sfErr != nil {
@@ -212,9 +317,6 @@ Example for one quiz object:
 	}
 
 	if finalQuizzes, ok := res.([]*domain.NewQuizData); ok {
-		if len(finalQuizzes) == 0 && numQuestions > 0 {
-			a.logger.Warn("LLM simulation returned empty list (after filtering, post-singleflight) but questions were requested", zap.Int("num_requested", numQuestions))
-		}
 		a.logger.Info("Successfully processed LLM response (singleflight)", zap.Int("num_quizzes_generated", len(finalQuizzes)))
 		return finalQuizzes, nil
 	}
@@ -225,15 +327,10 @@ Example for one quiz object:
 // Static assertion to ensure GeminiQuizGenerator implements QuizGenerationService
 var _ domain.QuizGenerationService = (*GeminiQuizGenerator)(nil)
 
-type llmScoreEvaluationResponse struct {
-	ScoreEvaluations []domain.ScoreEvaluationDetail `json:"score_evaluations"`
-}
-
-func (a *GeminiQuizGenerator) GenerateScoreEvaluationsForQuiz(ctx context.Context, quiz *domain.Quiz, scoreRanges []string) ([]domain.ScoreEvaluationDetail, error) {
-	a.logger.Info("Generating score evaluations for quiz", zap.String("quizID", quiz.ID), zap.String("question", quiz.Question))
-
+// buildScoreEvaluationsPrompt renders the score-evaluation prompt. Its hash is the cache key.
+func buildScoreEvaluationsPrompt(quiz *domain.Quiz, scoreRanges []string) string {
 	var promptBuilder strings.Builder
-	promptBuilder.WriteString(fmt.Sprintf("For the following quiz question:\n"))
+	promptBuilder.WriteString("For the following quiz question:\n")
 	promptBuilder.WriteString(fmt.Sprintf("Question: \"%s\"\n", quiz.Question))
 	promptBuilder.WriteString(fmt.Sprintf("Ideal Model Answer(s) (for a perfect score): \"%s\"\n", strings.Join(quiz.ModelAnswers, "; ")))
 	promptBuilder.WriteString(fmt.Sprintf("Keywords: \"%s\"\n\n", strings.Join(quiz.Keywords, ", ")))
@@ -241,13 +338,23 @@ func (a *GeminiQuizGenerator) GenerateScoreEvaluationsForQuiz(ctx context.Contex
 
 	for i, sr := range scoreRanges {
 		promptBuilder.WriteString(fmt.Sprintf("%d. Score Range: \"%s\"\n", i+1, sr))
-		promptBuilder.WriteString(fmt.Sprintf("   - Provide 1-2 distinct example answers that would achieve this score.\n"))
-		promptBuilder.WriteString(fmt.Sprintf("   - Provide a general explanation/feedback for answers in this range.\n"))
+		promptBuilder.WriteString("   - Provide 1-2 distinct example answers that would achieve this score.\n")
+		promptBuilder.WriteString("   - Provide a general explanation/feedback for answers in this range.\n")
 	}
-	promptBuilder.WriteString("\nFormat your response as a JSON object with a list called \"score_evaluations\", where each item contains \"score_range\", \"sample_answers\" (list of strings), and \"explanation\" (string).\n")
+	promptBuilder.WriteString("\nRespond with ONLY a JSON object with a list called \"score_evaluations\", where each item contains \"score_range\" (copied exactly from above), \"sample_answers\" (list of strings), and \"explanation\" (string).\n")
 	promptBuilder.WriteString("Example item: {\"score_range\": \"0.8-1.0\", \"sample_answers\": [\"Example 1...\", \"Example 2...\"], \"explanation\": \"Feedback for this range...\"}\n")
+	return promptBuilder.String()
+}
+
+// GenerateScoreEvaluationsForQuiz asks the model for sample answers and feedback per score range.
+// The result is ordered like scoreRanges.
+func (a *GeminiQuizGenerator) GenerateScoreEvaluationsForQuiz(ctx context.Context, quiz *domain.Quiz, scoreRanges []string) ([]domain.ScoreEvaluationDetail, error) {
+	a.logger.Info("Generating score evaluations for quiz", zap.String("quizID", quiz.ID), zap.String("question", quiz.Question))
+	if len(scoreRanges) == 0 {
+		return []domain.ScoreEvaluationDetail{}, nil
+	}
 
-	prompt := promptBuilder.String()
+	prompt := buildScoreEvaluationsPrompt(quiz, scoreRanges)
 	promptHash := hashString(prompt)
 	cacheKey := cache.GenerateCacheKey("llm_score_evals", "gemini", promptHash)
 
@@ -255,15 +362,11 @@ func (a *GeminiQuizGenerator) GenerateScoreEvaluationsForQuiz(ctx context.Contex
 		cachedDataString, err := a.cache.Get(ctx, cacheKey)
 		if err == nil {
 			a.logger.Info("LLM score evaluations cache hit", zap.String("cacheKey", cacheKey))
-			var parsedResp llmScoreEvaluationResponse
-			if errUnmarshal := json.Unmarshal([]byte(cachedDataString), &parsedResp); errUnmarshal == nil {
-				if len(parsedResp.ScoreEvaluations) > 0 && len(parsedResp.ScoreEvaluations) == len(scoreRanges) {
-					a.logger.Info("Successfully decoded cached LLM score evaluations (JSON string)", zap.Int("count", len(parsedResp.ScoreEvaluations)))
-					return parsedResp.ScoreEvaluations, nil
-				}
-				a.logger.Warn("Cached LLM score evaluations (JSON string) invalid or mismatch.", zap.String("cacheKey", cacheKey))
+			if details, errParse := parseScoreEvaluations(cachedDataString, scoreRanges); errParse == nil {
+				a.logger.Info("Successfully decoded cached LLM score evaluations (JSON string)", zap.Int("count", len(details)))
+				return details, nil
 			} else {
-				a.logger.Error("Failed to unmarshal cached LLM score evaluations (JSON string)", zap.Error(errUnmarshal), zap.String("cacheKey", cacheKey))
+				a.logger.Warn("Cached LLM score evaluations (JSON string) invalid or mismatch.", zap.Error(errParse), zap.String("cacheKey", cacheKey))
 			}
 		} else if err != domain.ErrCacheMiss {
 			a.logger.Error("Cache get failed for score evaluations (not a miss)", zap.Error(err), zap.String("cacheKey", cacheKey))
@@ -272,41 +375,185 @@ func (a *GeminiQuizGenerator) GenerateScoreEvaluationsForQuiz(ctx context.Contex
 		}
 	}
 
-	a.logger.Info("Simulating LLM call for score evaluations", zap.String("quizID", quiz.ID), zap.String("prompt_hash", promptHash))
-	var simulatedDetails []string
-	for _, sr := range scoreRanges {
-		detail := fmt.Sprintf(`{"score_range": "%s", "sample_answers": ["Simulated answer for %s range.", "Another simulated answer."], "explanation": "Simulated explanation for %s range."}`, sr, sr, sr)
-		simulatedDetails = append(simulatedDetails, detail)
+	res, sfErr, _ := a.sfGroup.Do(cacheKey, func() (interface{}, error) {
+		a.logger.Info("Calling Gemini for score evaluations (within singleflight)", zap.String("quizID", quiz.ID), zap.String("prompt_hash", promptHash))
+
+		var details []domain.ScoreEvaluationDetail
+		err := a.generateWithRetry(ctx, a.evalLLM, prompt, scoreEvaluationTemp, func(raw string) error {
+			parsed, parseErr := parseScoreEvaluations(raw, scoreRanges)
+			if parseErr != nil {
+				return parseErr
+			}
+			details = parsed
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if a.cache != nil {
+			// Cache the normalized payload so a hit parses exactly like a fresh response.
+			payload, errMarshal := json.Marshal(llmScoreEvaluationResponse{ScoreEvaluations: details})
+			if errMarshal != nil {
+				a.logger.Error("Failed to marshal LLM score evaluations for caching", zap.Error(errMarshal), zap.String("cacheKey", cacheKey))
+				return details, nil
+			}
+			if errCacheSet := a.cache.Set(ctx, cacheKey, string(payload), a.llmResponseTTL()); errCacheSet != nil {
+				a.logger.Error("Failed to set LLM score evaluations to cache", zap.Error(errCacheSet), zap.String("cacheKey", cacheKey))
+			} else {
+				a.logger.Info("LLM score evaluations cached successfully", zap.String("cacheKey", cacheKey))
+			}
+		}
+		return details, nil
+	})
+	if sfErr != nil {
+		return nil, sfErr
 	}
-	llmResponseString := fmt.Sprintf(`{"score_evaluations": [%s]}`, strings.Join(simulatedDetails, ","))
-	a.logger.Debug("Simulated LLM response for score evaluations", zap.String("response", llmResponseString))
 
-	var parsedResp llmScoreEvaluationResponse
-	if err := json.Unmarshal([]byte(llmResponseString), &parsedResp); err != nil {
-		a.logger.Error("Failed to unmarshal LLM response for score evaluations", zap.Error(err), zap.String("quizID", quiz.ID), zap.String("response", llmResponseString))
-		return nil, fmt.Errorf("failed to parse LLM response: %w. Response: %s", err, llmResponseString)
+	details, ok := res.([]domain.ScoreEvaluationDetail)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type from singleflight.Do for score evaluations: %T", res)
 	}
+	a.logger.Info("Successfully generated score evaluations for quiz", zap.String("quizID", quiz.ID), zap.Int("count", len(details)))
+	return details, nil
+}
+
+// generateWithRetry calls the model and hands the raw text to parse. When parse rejects the
+// output, the call is repeated with the validation error appended to the prompt, up to
+// maxRetries extra times. Transport errors are returned immediately.
+func (a *GeminiQuizGenerator) generateWithRetry(ctx context.Context, model llms.Model, prompt string, temperature float64, parse func(raw string) error) error {
+	currentPrompt := prompt
+	var lastErr error
+	for attempt := 0; attempt <= a.maxRetries; attempt++ {
+		callCtx, cancel := context.WithTimeout(ctx, a.timeout)
+		raw, err := llms.GenerateFromSinglePrompt(callCtx, model, currentPrompt, llms.WithTemperature(temperature))
+		cancel()
+		if err != nil {
+			a.logger.Error("Gemini call failed", zap.Error(err), zap.Int("attempt", attempt+1))
+			return fmt.Errorf("gemini call failed: %w", err)
+		}
+
+		if lastErr = parse(raw); lastErr == nil {
+			return nil
+		}
+		a.logger.Warn("Gemini returned malformed output",
+			zap.Error(lastErr),
+			zap.Int("attempt", attempt+1),
+			zap.Int("max_attempts", a.maxRetries+1),
+			zap.String("raw_response", raw[:min(500, len(raw))]))
+		currentPrompt = prompt + fmt.Sprintf("\n\nYour previous response was rejected: %v\nRespond again with ONLY valid JSON that follows the format above.\n", lastErr)
+	}
+	return fmt.Errorf("%w after %d attempts: %v", ErrMalformedResponse, a.maxRetries+1, lastErr)
+}
+
+func (a *GeminiQuizGenerator) llmResponseTTL() time.Duration {
+	if a.config != nil && a.config.CacheTTLs.LLMResponse != "" {
+		return a.config.ParseTTLStringOrDefault(a.config.CacheTTLs.LLMResponse, defaultLLMResponseTTL)
+	}
+	return defaultLLMResponseTTL
+}
 
-	if len(parsedResp.ScoreEvaluations) != len(scoreRanges) {
-		a.logger.Warn("LLM did not return evaluations for all requested score ranges",
-			zap.Int("returned_count", len(parsedResp.ScoreEvaluations)),
-			zap.Int("expected_count", len(scoreRanges)),
-			zap.String("quizID", quiz.ID))
+// decodeStrict unmarshals the single JSON document in raw into v, rejecting unknown
+// fields and trailing data. A surrounding markdown code fence is tolerated.
+func decodeStrict(raw string, v interface{}) error {
+	cleaned := strings.TrimSpace(raw)
+	if strings.HasPrefix(cleaned, "```") {
+		cleaned = strings.TrimPrefix(cleaned, "```json")
+		cleaned = strings.TrimPrefix(cleaned, "```")
+		cleaned = strings.TrimSuffix(cleaned, "```")
+		cleaned = strings.TrimSpace(cleaned)
 	}
 
-	if a.cache != nil && len(parsedResp.ScoreEvaluations) > 0 {
-		defaultTTL := 24 * time.Hour
-		cacheTTL := defaultTTL
-		if a.config != nil && a.config.CacheTTLs.LLMResponse != "" { // Check if CacheTTLs itself is not nil
-			cacheTTL = a.config.ParseTTLStringOrDefault(a.config.CacheTTLs.LLMResponse, defaultTTL)
+	decoder := json.NewDecoder(strings.NewReader(cleaned))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("invalid JSON: unexpected data after the top-level object")
+	}
+	return nil
+}
+
+// parseQuizCandidates validates a quiz generation response against quizCandidatesSchema.
+func parseQuizCandidates(raw string, numQuestions int) ([]*domain.NewQuizData, error) {
+	var resp llmQuizCandidatesResponse
+	if err := decodeStrict(raw, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Quizzes) != numQuestions {
+		return nil, fmt.Errorf("expected %d quizzes, got %d", numQuestions, len(resp.Quizzes))
+	}
+
+	quizzes := make([]*domain.NewQuizData, 0, len(resp.Quizzes))
+	for i, c := range resp.Quizzes {
+		question := strings.TrimSpace(c.Question)
+		modelAnswer := strings.TrimSpace(c.ModelAnswer)
+		difficulty := strings.ToLower(strings.TrimSpace(c.Difficulty))
+		if question == "" {
+			return nil, fmt.Errorf("quizzes[%d].question is required", i)
 		}
-		if errCacheSet := a.cache.Set(ctx, cacheKey, llmResponseString, cacheTTL); errCacheSet != nil {
-			a.logger.Error("Failed to set LLM score evaluations to cache", zap.Error(errCacheSet), zap.String("cacheKey", cacheKey))
-		} else {
-			a.logger.Info("LLM score evaluations cached successfully", zap.String("cacheKey", cacheKey))
+		if modelAnswer == "" {
+			return nil, fmt.Errorf("quizzes[%d].model_answer is required", i)
+		}
+		if difficulty != "easy" && difficulty != "medium" && difficulty != "hard" {
+			return nil, fmt.Errorf("quizzes[%d].difficulty must be one of easy, medium, hard (got %q)", i, c.Difficulty)
+		}
+
+		keywords := make([]string, 0, len(c.Keywords))
+		for _, kw := range c.Keywords {
+			if kw = strings.TrimSpace(kw); kw != "" {
+				keywords = append(keywords, kw)
+			}
+		}
+		if len(keywords) == 0 || len(keywords) > maxKeywordsPerCandidate {
+			return nil, fmt.Errorf("quizzes[%d].keywords must contain 1-%d entries (got %d)", i, maxKeywordsPerCandidate, len(keywords))
 		}
+
+		quizzes = append(quizzes, &domain.NewQuizData{
+			Question:    question,
+			ModelAnswer: modelAnswer,
+			Keywords:    keywords,
+			Difficulty:  difficulty,
+		})
+	}
+	return quizzes, nil
+}
+
+// parseScoreEvaluations validates a score evaluation response against scoreEvaluationsSchema
+// and returns exactly one detail per requested range, in the requested order.
+func parseScoreEvaluations(raw string, scoreRanges []string) ([]domain.ScoreEvaluationDetail, error) {
+	var resp llmScoreEvaluationResponse
+	if err := decodeStrict(raw, &resp); err != nil {
+		return nil, err
 	}
 
-	a.logger.Info("Successfully generated score evaluations for quiz", zap.String("quizID", quiz.ID), zap.Int("count", len(parsedResp.ScoreEvaluations)))
-	return parsedResp.ScoreEvaluations, nil
+	byRange := make(map[string]domain.ScoreEvaluationDetail, len(resp.ScoreEvaluations))
+	for i, se := range resp.ScoreEvaluations {
+		scoreRange := strings.TrimSpace(se.ScoreRange)
+		if _, dup := byRange[scoreRange]; dup {
+			return nil, fmt.Errorf("score_evaluations[%d]: duplicate score_range %q", i, se.ScoreRange)
+		}
+		if len(se.SampleAnswers) == 0 {
+			return nil, fmt.Errorf("score_evaluations[%d].sample_answers is required", i)
+		}
+		if strings.TrimSpace(se.Explanation) == "" {
+			return nil, fmt.Errorf("score_evaluations[%d].explanation is required", i)
+		}
+		se.ScoreRange = scoreRange
+		byRange[scoreRange] = se
+	}
+
+	details := make([]domain.ScoreEvaluationDetail, 0, len(scoreRanges))
+	for _, sr := range scoreRanges {
+		se, ok := byRange[sr]
+		if !ok {
+			return nil, fmt.Errorf("missing evaluation for score range %q", sr)
+		}
+		details = append(details, se)
+	}
+	if len(byRange) != len(scoreRanges) {
+		return nil, fmt.Errorf("expected %d score evaluations, got %d", len(scoreRanges), len(byRange))
+	}
+	return details, nil
 }
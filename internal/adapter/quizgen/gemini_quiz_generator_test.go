@@ -1,22 +1,25 @@
 package quizgen_test
 
 import (
+	"bytes"
 	"context"
-	"fmt"
+	"encoding/gob"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
-	"time" // For MockCache
+	"time"
+
+	"quiz-byte/internal/adapter/quizgen"
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
 
-	"quiz-byte/internal/adapter/quizgen" // Use the actual package name
-	"quiz-byte/internal/config"          // Added import
-	"quiz-byte/internal/domain"          // For domain.Cache interface
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
-	"bytes"         // Added for gob
-	"encoding/gob"  // Added for gob
-	"encoding/json" // For unmarshalling expectedQuizzesData from string literal
-	"strings"       // Added for strings.Join
-	"github.com/stretchr/testify/mock" // For testify mock
 )
 
 // MockCache now uses testify's mock.
@@ -36,6 +39,10 @@ func (m *MockCache) Delete(ctx context.Context, key string) error {
 	args := m.Called(ctx, key)
 	return args.Error(0)
 }
+func (m *MockCache) GetDel(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
 func (m *MockCache) Ping(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
@@ -97,6 +104,115 @@ func TestNewGeminiQuizGenerator(t *testing.T) {
 	assert.Contains(t, err.Error(), "config instance cannot be nil")
 }
 
+// stubGemini is a local stand-in for Gemini's OpenAI-compatible chat completions endpoint.
+// Each request is answered with the next queued content; the last one is repeated.
+type stubGemini struct {
+	t         *testing.T
+	mu        sync.Mutex
+	contents  []string
+	requests  []stubChatRequest
+	authToken string
+}
+
+type stubChatRequest struct {
+	Model    string `json:"model"`
+	Messages []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"messages"`
+	ResponseFormat *struct {
+		Type       string `json:"type"`
+		JSONSchema *struct {
+			Name   string `json:"name"`
+			Strict bool   `json:"strict"`
+		} `json:"json_schema"`
+	} `json:"response_format"`
+}
+
+// prompt returns the text of the last user message.
+func (r stubChatRequest) prompt() string {
+	if len(r.Messages) == 0 {
+		return ""
+	}
+	raw := r.Messages[len(r.Messages)-1].Content
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	_ = json.Unmarshal(raw, &parts)
+	var sb strings.Builder
+	for _, p := range parts {
+		sb.WriteString(p.Text)
+	}
+	return sb.String()
+}
+
+func newStubGemini(t *testing.T, contents ...string) (*stubGemini, *httptest.Server) {
+	stub := &stubGemini{t: t, contents: contents}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		var req stubChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		stub.mu.Lock()
+		stub.requests = append(stub.requests, req)
+		stub.authToken = r.Header.Get("Authorization")
+		idx := len(stub.requests) - 1
+		if idx >= len(stub.contents) {
+			idx = len(stub.contents) - 1
+		}
+		content := stub.contents[idx]
+		stub.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "chatcmpl-stub",
+			"object":  "chat.completion",
+			"created": time.Now().Unix(),
+			"model":   req.Model,
+			"choices": []map[string]interface{}{{
+				"index":         0,
+				"message":       map[string]string{"role": "assistant", "content": content},
+				"finish_reason": "stop",
+			}},
+			"usage": map[string]int{"prompt_tokens": 1, "completion_tokens": 1, "total_tokens": 2},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return stub, server
+}
+
+func (s *stubGemini) calls() []stubChatRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]stubChatRequest(nil), s.requests...)
+}
+
+func newStubConfig(baseURL string, maxRetries int) *config.Config {
+	return &config.Config{
+		CacheTTLs: config.CacheTTLConfig{LLMResponse: "15m"},
+		LLMProviders: config.LLMProvidersConfig{
+			Gemini: config.GeminiConfig{BaseURL: baseURL, MaxRetries: maxRetries, Timeout: 5 * time.Second},
+		},
+	}
+}
+
+func gobEncode(t *testing.T, v interface{}) string {
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(v))
+	return buf.String()
+}
+
+const validQuizCandidatesJSON = `{"quizzes": [{"question": "What does a CPU do?", "model_answer": "It executes program instructions.", "keywords": ["cpu", "instructions"], "difficulty": "Easy"}]}`
 
 func TestGeminiQuizGenerator_GenerateQuizCandidates_Caching(t *testing.T) {
 	logger := zap.NewNop()
@@ -105,238 +221,213 @@ func TestGeminiQuizGenerator_GenerateQuizCandidates_Caching(t *testing.T) {
 	ctx := context.Background()
 	subCategoryName := "Caching Test SubCategory"
 	existingKeywords := []string{"cache_kw1", "cache_kw2"}
-	numQuestions := 1 // Simplified for cache test
-
-	// Expected data (simulated LLM response)
-	simulatedResponses := []string{
-		fmt.Sprintf(`
-		{
-			"Question": "Simulated Question 1 for %s (from LLM): What is the primary function of a CPU?",
-			"ModelAnswer": "The primary function of a CPU is to execute instructions from computer programs.",
-			"Keywords": ["cpu", "processor", "computer architecture", "question1"],
-			"Difficulty": "easy"
-		}`, subCategoryName),
-	}
-	expectedJsonResponse := fmt.Sprintf("[%s]", strings.Join(simulatedResponses, ","))
-	var expectedQuizzesData []*domain.NewQuizData
-	_ = json.Unmarshal([]byte(expectedJsonResponse), &expectedQuizzesData) // Pre-unmarshal for comparison
-
-
-	// Prompt construction logic copied from SUT to generate expected hash/key
-	promptTemplate := `
-You are an expert quiz generator. Your task is to create %d unique and high-quality quiz questions
-for the sub-category: "%s".
-
-Avoid generating questions that are too similar to existing themes covered by these keywords: [%s].
-
-For each question, provide the following information in JSON format:
-1.  "question": The quiz question text.
-2.  "model_answer": A concise and accurate model answer.
-3.  "keywords": An array of 2-5 relevant keywords for this question.
-4.  "difficulty": A string indicating the difficulty ("easy", "medium", or "hard").
-
-Ensure your entire response is a single JSON array containing %d JSON objects, each representing a quiz.
-Example for one quiz object:
-{
-  "question": "What is the capital of France?",
-  "model_answer": "Paris",
-  "keywords": ["france", "capital", "paris"],
-  "difficulty": "easy"
-}
-`
-	formattedPrompt := fmt.Sprintf(promptTemplate, numQuestions, subCategoryName, strings.Join(existingKeywords, ", "), numQuestions)
-	// hashString is unexported, so we can't call it directly.
-	// Instead, we rely on the service to generate the key and ensure cache interactions happen.
-	// For asserting the key, we'd need to make hashString exportable or replicate logic.
-	// For now, we'll use mock.Anything for the key if exact match is too complex without direct hashString access.
-	// Or, we can grab the key from the first call if we are careful.
-	// Let's assume we can predict the key for now if hashString becomes available or by other means.
-	// For now, let's use mock.Anything or a placeholder if hash is tricky.
-	// Re-evaluating: tests are in the same package, so unexported hashString is callable.
-	promptHash := quizgen.HashStringForTest(formattedPrompt) // Accessing via test helper if unexported
-	cacheKey := "quizbyte:llm_response:gemini:" + promptHash
+	numQuestions := 1
+
+	expectedQuizzesData := []*domain.NewQuizData{{
+		Question:    "What does a CPU do?",
+		ModelAnswer: "It executes program instructions.",
+		Keywords:    []string{"cpu", "instructions"},
+		Difficulty:  "easy",
+	}}
 
+	formattedPrompt := quizgen.BuildQuizCandidatesPromptForTest(subCategoryName, existingKeywords, numQuestions)
+	cacheKey := "quizbyte:llm_response:gemini:" + quizgen.HashStringForTest(formattedPrompt)
 
 	t.Run("Cache Miss", func(t *testing.T) {
+		stub, server := newStubGemini(t, validQuizCandidatesJSON)
 		mockCache := new(MockCache)
-		testConfig := &config.Config{
-			CacheTTLs: config.CacheTTLConfig{LLMResponse: "15m"},
-		}
-		svc, err := quizgen.NewGeminiQuizGenerator(apiKey, modelName, logger, mockCache, testConfig)
+		svc, err := quizgen.NewGeminiQuizGenerator(apiKey, modelName, logger, mockCache, newStubConfig(server.URL, 2))
 		require.NoError(t, err)
 
 		mockCache.On("Get", ctx, cacheKey).Return("", domain.ErrCacheMiss).Once()
-		// The Set call will use the actual JSON response from the (simulated) LLM
-		// We need to ensure the mock LLM call (already in the SUT) produces `expectedJsonResponse`
-	// which is then unmarshalled to expectedQuizzesData, which is then gob-encoded for caching.
-		expectedTTL, _ := time.ParseDuration("15m")
-
-	var expectedBuffer bytes.Buffer
-	enc := gob.NewEncoder(&expectedBuffer)
-	_ = enc.Encode(expectedQuizzesData) // expectedQuizzesData is []*domain.NewQuizData
-	expectedGobData := expectedBuffer.String()
-
-	mockCache.On("Set", ctx, cacheKey, expectedGobData, expectedTTL).Return(nil).Once()
+		mockCache.On("Set", ctx, cacheKey, gobEncode(t, expectedQuizzesData), 15*time.Minute).Return(nil).Once()
 
 		quizDataSlice, err := svc.GenerateQuizCandidates(ctx, subCategoryName, existingKeywords, numQuestions)
-		assert.NoError(t, err)
+		require.NoError(t, err)
 		assert.Equal(t, expectedQuizzesData, quizDataSlice)
 		mockCache.AssertExpectations(t)
+
+		calls := stub.calls()
+		require.Len(t, calls, 1)
+		assert.Equal(t, modelName, calls[0].Model)
+		assert.Equal(t, "Bearer "+apiKey, stub.authToken)
+		assert.Equal(t, formattedPrompt, calls[0].prompt())
+		require.NotNil(t, calls[0].ResponseFormat)
+		assert.Equal(t, "json_schema", calls[0].ResponseFormat.Type)
+		require.NotNil(t, calls[0].ResponseFormat.JSONSchema)
+		assert.Equal(t, "quiz_candidates", calls[0].ResponseFormat.JSONSchema.Name)
+		assert.True(t, calls[0].ResponseFormat.JSONSchema.Strict)
 	})
 
 	t.Run("Cache Hit", func(t *testing.T) {
+		stub, server := newStubGemini(t, validQuizCandidatesJSON)
 		mockCache := new(MockCache)
-		testConfig := &config.Config{ // TTL doesn't matter for cache hit Get
-			CacheTTLs: config.CacheTTLConfig{LLMResponse: "15m"},
-		}
-		svc, err := quizgen.NewGeminiQuizGenerator(apiKey, modelName, logger, mockCache, testConfig)
+		svc, err := quizgen.NewGeminiQuizGenerator(apiKey, modelName, logger, mockCache, newStubConfig(server.URL, 2))
 		require.NoError(t, err)
 
-	var expectedBuffer bytes.Buffer
-	enc := gob.NewEncoder(&expectedBuffer)
-	_ = enc.Encode(expectedQuizzesData)
-	expectedGobData := expectedBuffer.String()
-
-	mockCache.On("Get", ctx, cacheKey).Return(expectedGobData, nil).Once()
+		mockCache.On("Get", ctx, cacheKey).Return(gobEncode(t, expectedQuizzesData), nil).Once()
 
 		quizDataSlice, err := svc.GenerateQuizCandidates(ctx, subCategoryName, existingKeywords, numQuestions)
 		assert.NoError(t, err)
 		assert.Equal(t, expectedQuizzesData, quizDataSlice)
 		mockCache.AssertExpectations(t)
 		mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		assert.Empty(t, stub.calls(), "LLM must not be called on a cache hit")
 	})
+}
 
+func TestGeminiQuizGenerator_GenerateQuizCandidates_RetryOnMalformedJSON(t *testing.T) {
+	ctx := context.Background()
+	stub, server := newStubGemini(t,
+		"Sure! Here are your quizzes: not json",
+		`{"quizzes": [{"question": "Q", "model_answer": "A", "keywords": ["k"], "difficulty": "impossible"}]}`,
+		"```json\n"+validQuizCandidatesJSON+"\n```",
+	)
+	mockCache := new(MockCache)
+	mockCache.On("Get", ctx, mock.Anything).Return("", domain.ErrCacheMiss).Once()
+	mockCache.On("Set", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	svc, err := quizgen.NewGeminiQuizGenerator("test-api-key", "test-model", zap.NewNop(), mockCache, newStubConfig(server.URL, 2))
+	require.NoError(t, err)
+
+	quizzes, err := svc.GenerateQuizCandidates(ctx, "Retry", nil, 1)
+	require.NoError(t, err)
+	require.Len(t, quizzes, 1)
+	assert.Equal(t, "easy", quizzes[0].Difficulty)
+
+	calls := stub.calls()
+	require.Len(t, calls, 3)
+	assert.NotContains(t, calls[0].prompt(), "previous response was rejected")
+	assert.Contains(t, calls[1].prompt(), "previous response was rejected")
+	assert.Contains(t, calls[2].prompt(), "difficulty must be one of easy, medium, hard")
+	mockCache.AssertExpectations(t)
 }
 
+func TestGeminiQuizGenerator_GenerateQuizCandidates_MalformedJSONExhaustsRetries(t *testing.T) {
+	ctx := context.Background()
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "not json", content: "I cannot help with that."},
+		{name: "unknown field", content: `{"quizzes": [{"question": "Q", "model_answer": "A", "keywords": ["k"], "difficulty": "easy", "extra": 1}]}`},
+		{name: "wrong count", content: `{"quizzes": []}`},
+		{name: "missing answer", content: `{"quizzes": [{"question": "Q", "model_answer": "", "keywords": ["k"], "difficulty": "easy"}]}`},
+		{name: "trailing data", content: validQuizCandidatesJSON + ` {"quizzes": []}`},
+	}
 
-// TestGeminiQuizGenerator_GenerateQuizCandidates_Success is replaced by _Caching tests
-// func TestGeminiQuizGenerator_GenerateQuizCandidates_Success(t *testing.T) { ... }
-
-
-// TestGenerateQuizCandidates_MalformedJSON (Conceptual as per subtask description)
-// To truly test this, the JSON generation/fetching part in the SUT would need to be mockable
-// or the internal hardcoded string would need to be made malformed for this specific test.
-// The current implementation of GeminiQuizGenerator always produces valid JSON string.
-// If the hardcoded string in GeminiQuizGenerator were to become malformed,
-// the json.Unmarshal call would return an error, which is covered by standard error handling.
-// This test case serves as a placeholder for how one might approach it if the SUT were different.
-func TestGeminiQuizGenerator_GenerateQuizCandidates_MalformedJSON_Conceptual(t *testing.T) {
-	t.Skip("Skipping MalformedJSON test as it requires SUT modification or specific mocking for current structure")
-	// If we could inject the JSON response or if the SUT fetched it from an external source:
-	// 1. Setup the service.
-	// 2. Mock the LLM call to return a malformed JSON string.
-	//    e.g., `mockLLM.On("Call", mock.Anything).Return("this is not json", nil)`
-	// 3. Call GenerateQuizCandidates.
-	// 4. Assert that an error is returned and it's related to JSON parsing.
-	//    `assert.Error(t, err)`
-	//    `assert.Contains(t, err.Error(), "failed to parse LLM response")`
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub, server := newStubGemini(t, tc.content)
+			mockCache := new(MockCache)
+			mockCache.On("Get", ctx, mock.Anything).Return("", domain.ErrCacheMiss).Once()
+
+			svc, err := quizgen.NewGeminiQuizGenerator("test-api-key", "test-model", zap.NewNop(), mockCache, newStubConfig(server.URL, 1))
+			require.NoError(t, err)
+
+			quizzes, err := svc.GenerateQuizCandidates(ctx, "Malformed", nil, 1)
+			assert.Nil(t, quizzes)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, quizgen.ErrMalformedResponse)
+			assert.Len(t, stub.calls(), 2, "one initial call plus one retry")
+			mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
 }
 
-
-// TestGenerateQuizCandidates_PromptConstruction (Conceptual - requires logger capture)
-// The current GeminiQuizGenerator logs the prompt. To test this, we'd need a logger
-// that writes to a buffer.
-func TestGeminiQuizGenerator_GenerateQuizCandidates_PromptConstruction_Conceptual(t *testing.T) {
-	t.Skip("Skipping PromptConstruction test as it requires logger capture setup")
-
-	// Example of how it could be done with a logger that supports capturing output:
-	// var logBuffer bytes.Buffer
-	// logger := zap.New(zapcore.NewCore(
-	//     zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()),
-	//     zapcore.AddSync(&logBuffer),
-	//     zap.InfoLevel,
-	// ))
-	//
-	// apiKey := "test-api-key"
-	// modelName := "test-model"
-	// For this conceptual test, cache and config are not directly involved in prompt construction part.
-	// svc, _ := quizgen.NewGeminiQuizGenerator(apiKey, modelName, logger, nil, nil)
-	//
-	// ctx := context.Background()
-	// subCategoryName := "Test Prompt Construction"
-	// existingKeywords := []string{"prompt_keyword1", "pk2"}
-	// numQuestions := 1
-	//
-	// _, _ = svc.GenerateQuizCandidates(ctx, subCategoryName, existingKeywords, numQuestions)
-	//
-	// loggedOutput := logBuffer.String()
-	// assert.Contains(t, loggedOutput, subCategoryName)
-	// assert.Contains(t, loggedOutput, "prompt_keyword1")
-	// assert.Contains(t, loggedOutput, "pk2")
-	// assert.Contains(t, loggedOutput, fmt.Sprintf("create %d unique", numQuestions))
-	// assert.Contains(t, loggedOutput, "JSON format")
-	// assert.Contains(t, loggedOutput, "\"question\"")
-	// assert.Contains(t, loggedOutput, "\"model_answer\"")
-	// assert.Contains(t, loggedOutput, "\"keywords\"")
-	// assert.Contains(t, loggedOutput, "\"difficulty\"")
+func TestGeminiQuizGenerator_GenerateQuizCandidates_UpstreamError(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error": {"message": "boom"}}`))
+	}))
+	defer server.Close()
+
+	mockCache := new(MockCache)
+	mockCache.On("Get", ctx, mock.Anything).Return("", domain.ErrCacheMiss).Once()
+	svc, err := quizgen.NewGeminiQuizGenerator("test-api-key", "test-model", zap.NewNop(), mockCache, newStubConfig(server.URL, 3))
+	require.NoError(t, err)
+
+	_, err = svc.GenerateQuizCandidates(ctx, "Upstream", nil, 1)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, quizgen.ErrMalformedResponse)
+	assert.Contains(t, err.Error(), "boom")
+	assert.Equal(t, 1, calls, "transport errors are not retried")
 }
 
-// It's also useful to test how the service handles an empty or nil response from the LLM
-// (if the LLM could theoretically return that for a valid request).
-// The current simulation always returns data if numQuestions > 0.
-func TestGeminiQuizGenerator_GenerateQuizCandidates_EmptyResponseFromLLM(t *testing.T) {
-    logger := zap.NewNop()
-    apiKey := "test-api-key"
-    modelName := "test-model"
-    mockCache := new(MockCache) // Use testify mock
-    validConfig := &config.Config{
-		CacheTTLs: config.CacheTTLConfig{LLMResponse: "1h"}, // Provide a test TTL
-	}
+func TestGeminiQuizGenerator_GenerateQuizCandidates_ZeroQuestions(t *testing.T) {
+	stub, server := newStubGemini(t, validQuizCandidatesJSON)
+	mockCache := new(MockCache)
+	svc, err := quizgen.NewGeminiQuizGenerator("test-api-key", "test-model", zap.NewNop(), mockCache, newStubConfig(server.URL, 2))
+	require.NoError(t, err)
 
-    svc, err := quizgen.NewGeminiQuizGenerator(apiKey, modelName, logger, mockCache, validConfig)
-    require.NoError(t, err)
-    require.NotNil(t, svc)
-
-    ctx := context.Background()
-    subCategoryName := "Test Empty Response"
-    existingKeywords := []string{}
-    numQuestions := 0 // Requesting zero questions
-
-	// Calculate expected cache key
-	promptTemplate := `
-You are an expert quiz generator. Your task is to create %d unique and high-quality quiz questions
-for the sub-category: "%s".
-
-Avoid generating questions that are too similar to existing themes covered by these keywords: [%s].
-
-For each question, provide the following information in JSON format:
-1.  "question": The quiz question text.
-2.  "model_answer": A concise and accurate model answer.
-3.  "keywords": An array of 2-5 relevant keywords for this question.
-4.  "difficulty": A string indicating the difficulty ("easy", "medium", or "hard").
-
-Ensure your entire response is a single JSON array containing %d JSON objects, each representing a quiz.
-Example for one quiz object:
-{
-  "question": "What is the capital of France?",
-  "model_answer": "Paris",
-  "keywords": ["france", "capital", "paris"],
-  "difficulty": "easy"
+	quizDataSlice, err := svc.GenerateQuizCandidates(context.Background(), "Test Empty Response", []string{}, 0)
+	assert.NoError(t, err)
+	require.NotNil(t, quizDataSlice) // Should return an empty slice, not nil
+	assert.Len(t, quizDataSlice, 0)
+	assert.Empty(t, stub.calls())
+	mockCache.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
 }
-`
-	formattedPrompt := fmt.Sprintf(promptTemplate, numQuestions, subCategoryName, strings.Join(existingKeywords, ", "), numQuestions)
-	promptHash := quizgen.HashStringForTest(formattedPrompt)
-	cacheKey := "quizbyte:llm_response:gemini:" + promptHash
-	expectedTTL, _ := time.ParseDuration(validConfig.CacheTTLs.LLMResponse)
 
-	mockCache.On("Get", ctx, cacheKey).Return("", domain.ErrCacheMiss).Once()
-
-	// Expect gob-encoded empty slice of []*domain.NewQuizData to be cached
-	var emptyQuizzesData []*domain.NewQuizData
-	var expectedEmptyBuffer bytes.Buffer
-	emptyEnc := gob.NewEncoder(&expectedEmptyBuffer)
-	_ = emptyEnc.Encode(emptyQuizzesData) // This will be a very small gob byte slice, not "[]"
-	expectedEmptyGobData := expectedEmptyBuffer.String()
+func TestGeminiQuizGenerator_GenerateScoreEvaluationsForQuiz(t *testing.T) {
+	ctx := context.Background()
+	quiz := &domain.Quiz{
+		ID:           "quiz-1",
+		Question:     "What is a goroutine?",
+		ModelAnswers: []string{"A lightweight thread managed by the Go runtime."},
+		Keywords:     []string{"goroutine", "runtime"},
+	}
+	scoreRanges := []string{"0.8-1.0", "0-0.8"}
+	// Ranges come back out of order; the result must follow scoreRanges.
+	response := `{"score_evaluations": [
+		{"score_range": "0-0.8", "sample_answers": ["A function."], "explanation": "Too vague."},
+		{"score_range": "0.8-1.0", "sample_answers": ["A runtime-managed lightweight thread."], "explanation": "Accurate."}
+	]}`
+
+	t.Run("retries until every range is covered", func(t *testing.T) {
+		stub, server := newStubGemini(t,
+			`{"score_evaluations": [{"score_range": "0.8-1.0", "sample_answers": ["x"], "explanation": "y"}]}`,
+			response,
+		)
+		mockCache := new(MockCache)
+		mockCache.On("Get", ctx, mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "quizbyte:llm_score_evals:gemini:")
+		})).Return("", domain.ErrCacheMiss).Once()
+		mockCache.On("Set", ctx, mock.Anything, mock.Anything, 15*time.Minute).Return(nil).Once()
 
-	mockCache.On("Set", ctx, cacheKey, expectedEmptyGobData, expectedTTL).Return(nil).Once()
+		svc, err := quizgen.NewGeminiQuizGenerator("test-api-key", "test-model", zap.NewNop(), mockCache, newStubConfig(server.URL, 2))
+		require.NoError(t, err)
 
+		details, err := svc.GenerateScoreEvaluationsForQuiz(ctx, quiz, scoreRanges)
+		require.NoError(t, err)
+		require.Len(t, details, 2)
+		assert.Equal(t, "0.8-1.0", details[0].ScoreRange)
+		assert.Equal(t, "0-0.8", details[1].ScoreRange)
+		assert.Equal(t, []string{"A function."}, details[1].SampleAnswers)
+
+		calls := stub.calls()
+		require.Len(t, calls, 2)
+		require.NotNil(t, calls[0].ResponseFormat)
+		require.NotNil(t, calls[0].ResponseFormat.JSONSchema)
+		assert.Equal(t, "score_evaluations", calls[0].ResponseFormat.JSONSchema.Name)
+		assert.Contains(t, calls[1].prompt(), `missing evaluation for score range "0-0.8"`)
+		mockCache.AssertExpectations(t)
+	})
 
-    quizDataSlice, err := svc.GenerateQuizCandidates(ctx, subCategoryName, existingKeywords, numQuestions)
+	t.Run("cache hit skips the model", func(t *testing.T) {
+		stub, server := newStubGemini(t, response)
+		mockCache := new(MockCache)
+		mockCache.On("Get", ctx, mock.Anything).Return(response, nil).Once()
 
-    assert.NoError(t, err)
-    require.NotNil(t, quizDataSlice) // Should return an empty slice, not nil
-    assert.Len(t, quizDataSlice, 0, "Should return an empty slice when 0 questions are requested")
+		svc, err := quizgen.NewGeminiQuizGenerator("test-api-key", "test-model", zap.NewNop(), mockCache, newStubConfig(server.URL, 2))
+		require.NoError(t, err)
 
-	// The adapter's current simulation will produce an empty JSON array "[]" if numQuestions is 0.
-	// This will be parsed correctly into an empty slice.
+		details, err := svc.GenerateScoreEvaluationsForQuiz(ctx, quiz, scoreRanges)
+		require.NoError(t, err)
+		assert.Len(t, details, 2)
+		assert.Empty(t, stub.calls())
+		mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
 }
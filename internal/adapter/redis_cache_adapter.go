@@ -49,6 +49,18 @@ func (r *RedisCacheAdapter) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// GetDel implements Cache.GetDel with the Redis GETDEL command (Redis 6.2+).
+func (r *RedisCacheAdapter) GetDel(ctx context.Context, key string) (string, error) {
+	val, err := r.client.GetDel(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", domain.ErrCacheMiss
+		}
+		return "", fmt.Errorf("redis GetDel failed for key %s: %w", key, err)
+	}
+	return val, nil
+}
+
 // Ping checks the health of the Redis server.
 func (r *RedisCacheAdapter) Ping(ctx context.Context) error {
 	if err := r.client.Ping(ctx).Err(); err != nil {
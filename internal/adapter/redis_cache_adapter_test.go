@@ -101,6 +101,30 @@ func TestRedisCacheAdapter_Delete(t *testing.T) {
 	})
 }
 
+func TestRedisCacheAdapter_GetDel(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	adapter := NewRedisCacheAdapter(db)
+	ctx := context.Background()
+
+	key := "testkey"
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectGetDel(key).SetVal("testvalue")
+		val, err := adapter.GetDel(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, "testvalue", val)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("CacheMiss", func(t *testing.T) {
+		mock.ExpectGetDel(key).SetErr(redis.Nil)
+		val, err := adapter.GetDel(ctx, key)
+		assert.ErrorIs(t, err, domain.ErrCacheMiss)
+		assert.Empty(t, val)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestRedisCacheAdapter_Ping(t *testing.T) {
 	db, mock := redismock.NewClientMock()
 	adapter := NewRedisCacheAdapter(db)
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -11,22 +13,104 @@ import (
 
 // AuthConfig holds all authentication related configurations.
 type AuthConfig struct {
-	JWT         JWTConfig         `yaml:"jwt"`
-	GoogleOAuth GoogleOAuthConfig `yaml:"google_oauth"`
+	JWT                JWTConfig            `yaml:"jwt"`
+	GoogleOAuth        GoogleOAuthConfig    `yaml:"google_oauth"`
+	GitHubOAuth        GitHubOAuthConfig    `yaml:"github_oauth"`
+	OIDCProviders      []OIDCProviderConfig `yaml:"oidc_providers"`       // Generic OpenID Connect identity providers, e.g. a corporate IdP
+	TokenEncryptionKey string               `yaml:"token_encryption_key"` // Base64 encoded 32 byte AES key for stored OAuth tokens
+	Mode               string               `yaml:"mode"`                 // AuthModeBearer (default) or AuthModeCookie
+	Cookie             AuthCookieConfig     `yaml:"cookie"`               // Used in AuthModeCookie
+}
+
+// Auth modes: how the OAuth callback hands the tokens to the client. Bearer tokens in the
+// Authorization header are accepted in both modes.
+const (
+	AuthModeBearer = "bearer" // The callback returns the tokens as JSON
+	AuthModeCookie = "cookie" // The callback sets the tokens in HttpOnly cookies and redirects to the frontend
+)
+
+// AuthCookieConfig configures the token cookies of the cookie auth mode.
+type AuthCookieConfig struct {
+	FrontendURL string `yaml:"frontend_url"` // Where the OAuth callback redirects the browser to; required in cookie mode
+	Domain      string `yaml:"domain"`       // Cookie domain; empty for the API host only
+	SameSite    string `yaml:"same_site"`    // "Lax" (default), "Strict" or "None"
+	Insecure    bool   `yaml:"insecure"`     // Omits the Secure attribute, for local development over plain HTTP
 }
 
 // GoogleOAuthConfig holds configuration for Google OAuth.
 type GoogleOAuthConfig struct {
+	ClientID     string                   `yaml:"client_id"`
+	ClientSecret string                   `yaml:"client_secret"`
+	RedirectURL  string                   `yaml:"redirect_url"`
+	AuthURL      string                   `yaml:"auth_url"`   // Defaults to Google's endpoints; override to use a stub
+	TokenURL     string                   `yaml:"token_url"`  // Token exchange and refresh
+	JWKSURL      string                   `yaml:"jwks_url"`   // Keys the id_token is signed with
+	Issuer       string                   `yaml:"issuer"`     // Expected iss of the id_token
+	RevokeURL    string                   `yaml:"revoke_url"` // Called when an account is deleted
+	TokenRefresh GoogleTokenRefreshConfig `yaml:"token_refresh"`
+}
+
+// Google's OAuth endpoints, used when GoogleOAuthConfig leaves them empty.
+const (
+	DefaultGoogleAuthURL   = "https://accounts.google.com/o/oauth2/auth"
+	DefaultGoogleTokenURL  = "https://oauth2.googleapis.com/token"
+	DefaultGoogleJWKSURL   = "https://www.googleapis.com/oauth2/v3/certs"
+	DefaultGoogleIssuer    = "https://accounts.google.com"
+	DefaultGoogleRevokeURL = "https://oauth2.googleapis.com/revoke"
+)
+
+// GitHubOAuthConfig holds configuration for signing in with GitHub. GitHub sign-in is enabled when ClientID is set.
+type GitHubOAuthConfig struct {
 	ClientID     string `yaml:"client_id"`
 	ClientSecret string `yaml:"client_secret"`
 	RedirectURL  string `yaml:"redirect_url"`
+	AuthURL      string `yaml:"auth_url"`  // Defaults to GitHub's endpoints; override for GitHub Enterprise or a stub
+	TokenURL     string `yaml:"token_url"` // Token exchange
+	APIURL       string `yaml:"api_url"`   // REST API base URL, for the user profile and emails
+}
+
+// GitHub's OAuth endpoints, used when GitHubOAuthConfig leaves them empty.
+const (
+	DefaultGitHubAuthURL  = "https://github.com/login/oauth/authorize"
+	DefaultGitHubTokenURL = "https://github.com/login/oauth/access_token"
+	DefaultGitHubAPIURL   = "https://api.github.com"
+)
+
+// OIDCProviderConfig describes an OpenID Connect identity provider. Its endpoints are read from
+// the issuer's discovery document (<issuer_url>/.well-known/openid-configuration).
+type OIDCProviderConfig struct {
+	Name         string   `yaml:"name" mapstructure:"name"`             // Provider name in the login routes, e.g. /auth/<name>/login
+	IssuerURL    string   `yaml:"issuer_url" mapstructure:"issuer_url"` // Must match the issuer in the discovery document
+	ClientID     string   `yaml:"client_id" mapstructure:"client_id"`
+	ClientSecret string   `yaml:"client_secret" mapstructure:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url" mapstructure:"redirect_url"`
+	Scopes       []string `yaml:"scopes" mapstructure:"scopes"` // Defaults to openid, email and profile
+}
+
+// GoogleTokenRefreshConfig tunes the background job that renews stored Google access tokens before they expire.
+type GoogleTokenRefreshConfig struct {
+	Interval      time.Duration `yaml:"interval"`       // How often expiring tokens are looked for
+	RefreshBefore time.Duration `yaml:"refresh_before"` // Tokens expiring within this window are renewed
+	BatchSize     int           `yaml:"batch_size"`     // Tokens renewed per run
 }
 
 // JWTConfig holds configuration for JWT.
 type JWTConfig struct {
-	SecretKey       string        `yaml:"secret_key"`
-	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
-	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+	SigningKeys     []JWTKeyConfig `yaml:"signing_keys"`
+	ActiveKeyID     string         `yaml:"active_key_id"` // kid of the key new tokens are signed with
+	AccessTokenTTL  time.Duration  `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration  `yaml:"refresh_token_ttl"`
+}
+
+// JWTKeyConfig describes one JWT key. Keys with a private key can sign; keys with only a
+// public key are kept to verify tokens that were signed before a rotation.
+type JWTKeyConfig struct {
+	ID             string `yaml:"id" mapstructure:"id"`                             // Published as the kid header
+	Algorithm      string `yaml:"algorithm" mapstructure:"algorithm"`               // "EdDSA" or "RS256"
+	PrivateKey     string `yaml:"private_key" mapstructure:"private_key"`           // PKCS#8 PEM (PKCS#1 is accepted for RS256)
+	PrivateKeyFile string `yaml:"private_key_file" mapstructure:"private_key_file"` // Path to the PEM file, instead of PrivateKey
+	PublicKey      string `yaml:"public_key" mapstructure:"public_key"`             // PKIX PEM, for verify-only keys
+	PublicKeyFile  string `yaml:"public_key_file" mapstructure:"public_key_file"`   // Path to the PEM file, instead of PublicKey
 }
 
 // LLMProvidersConfig holds configurations for LLM providers.
@@ -42,15 +126,115 @@ type LoggerConfig struct {
 }
 
 type Config struct {
-	DB           DBConfig
-	Server       ServerConfig
-	Redis        RedisConfig
-	Embedding    EmbeddingConfig
-	Batch        BatchConfig        // New field for Batch operations
-	Auth         AuthConfig         `yaml:"auth"`
-	LLMProviders LLMProvidersConfig `yaml:"llm_providers"`
-	Logger       LoggerConfig       `yaml:"logger"`
-	CacheTTLs    CacheTTLConfig     // Added CacheTTLs
+	DB            DBConfig
+	Server        ServerConfig
+	Redis         RedisConfig
+	Embedding     EmbeddingConfig
+	Batch         BatchConfig         // New field for Batch operations
+	Auth          AuthConfig          `yaml:"auth"`
+	LLMProviders  LLMProvidersConfig  `yaml:"llm_providers"`
+	Logger        LoggerConfig        `yaml:"logger"`
+	CacheTTLs     CacheTTLConfig      // Added CacheTTLs
+	Evaluator     EvaluatorConfig     `yaml:"evaluator"`
+	CheckAnswer   CheckAnswerConfig   `yaml:"check_answer"`
+	AttemptOutbox AttemptOutboxConfig `yaml:"attempt_outbox"`
+}
+
+// AttemptOutboxConfig tunes the dispatcher that writes queued quiz attempts to the attempt history.
+type AttemptOutboxConfig struct {
+	PollInterval   time.Duration `yaml:"poll_interval"`   // How often the outbox is checked for due entries
+	BatchSize      int           `yaml:"batch_size"`      // Entries read per query
+	MaxTries       int           `yaml:"max_tries"`       // Failed tries before an entry is given up
+	InitialBackoff time.Duration `yaml:"initial_backoff"` // Delay after the first failure; doubled on each further failure
+	MaxBackoff     time.Duration `yaml:"max_backoff"`     // Upper bound of the retry delay
+}
+
+// CheckAnswerConfig holds the time budgets for each stage of answer checking.
+// A zero budget leaves the stage bounded only by the request context.
+type CheckAnswerConfig struct {
+	Total       time.Duration   `yaml:"total"`        // Whole CheckAnswer call
+	Embedding   time.Duration   `yaml:"embedding"`    // Embedding the user's answer
+	CacheLookup time.Duration   `yaml:"cache_lookup"` // Similar-answer cache lookup
+	Evaluation  time.Duration   `yaml:"evaluation"`   // LLM evaluation
+	PreScore    PreScoreConfig  `yaml:"pre_score"`
+	Jobs        CheckJobsConfig `yaml:"jobs"`
+}
+
+// CheckJobsConfig sizes the worker pool that grades answers submitted with ?async=true.
+type CheckJobsConfig struct {
+	Workers   int           `yaml:"workers"`    // Answers graded concurrently
+	QueueSize int           `yaml:"queue_size"` // Jobs waiting for a worker; further submissions are rejected with 503
+	ResultTTL time.Duration `yaml:"result_ttl"` // How long a job and its result can be polled
+}
+
+// PreScoreConfig tunes the deterministic scoring stage that runs before the LLM.
+// It compares the answer with the quiz keywords and the model answer embeddings.
+type PreScoreConfig struct {
+	Enabled              bool    `yaml:"enabled"`
+	OffTopicSimilarity   float64 `yaml:"off_topic_similarity"`   // Below this similarity an answer without any keyword scores 0
+	ExactMatchSimilarity float64 `yaml:"exact_match_similarity"` // At or above this similarity the answer scores 1
+	FallbackOnLLMError   bool    `yaml:"fallback_on_llm_error"`  // Return the local estimate instead of LLM_SERVICE_ERROR
+}
+
+// Evaluator providers supported by EvaluatorConfig.Provider.
+const (
+	EvaluatorProviderOllama = "ollama"
+	EvaluatorProviderOpenAI = "openai"
+	EvaluatorProviderGemini = "gemini"
+	EvaluatorProviderFake   = "fake"
+	// EvaluatorProviderEnsemble combines the providers listed in EvaluatorConfig.Ensemble.Judges.
+	EvaluatorProviderEnsemble = "ensemble"
+)
+
+// Strategies for combining ensemble judge scores.
+const (
+	EnsembleStrategyMean        = "mean"
+	EnsembleStrategyMedian      = "median"
+	EnsembleStrategyTrimmedMean = "trimmed_mean"
+)
+
+// EvaluatorConfig selects the LLM provider used to grade answers and holds per-provider settings.
+type EvaluatorConfig struct {
+	Provider string                  `yaml:"provider"` // One of ollama, openai, gemini, fake, ensemble
+	Ollama   EvaluatorProviderConfig `yaml:"ollama"`
+	OpenAI   EvaluatorProviderConfig `yaml:"openai"`
+	Gemini   EvaluatorProviderConfig `yaml:"gemini"`
+	Fake     EvaluatorProviderConfig `yaml:"fake"`
+	Ensemble EnsembleConfig          `yaml:"ensemble"`
+}
+
+// EnsembleConfig configures the ensemble provider, which asks several judges and combines their scores.
+type EnsembleConfig struct {
+	Judges             []string `yaml:"judges"`              // Provider names, one judge each; a name may repeat
+	Strategy           string   `yaml:"strategy"`            // mean, median or trimmed_mean
+	TrimRatio          float64  `yaml:"trim_ratio"`          // Share of scores dropped from each end for trimmed_mean
+	DisagreementMargin float64  `yaml:"disagreement_margin"` // Score spread above which the result is low-confidence
+}
+
+// EvaluatorProviderConfig holds model settings for a single evaluator provider.
+type EvaluatorProviderConfig struct {
+	APIKey      string        `yaml:"api_key"`
+	BaseURL     string        `yaml:"base_url"` // Server URL for ollama, API endpoint for openai/gemini
+	Model       string        `yaml:"model"`
+	Temperature float64       `yaml:"temperature"`
+	Timeout     time.Duration `yaml:"timeout"`
+	MaxTokens   int           `yaml:"max_tokens"` // 0 leaves the provider default
+}
+
+// ProviderConfig returns the settings for the named provider.
+func (c EvaluatorConfig) ProviderConfig(provider string) (EvaluatorProviderConfig, bool) {
+	switch provider {
+	case EvaluatorProviderOllama:
+		return c.Ollama, true
+	case EvaluatorProviderOpenAI:
+		return c.OpenAI, true
+	case EvaluatorProviderGemini:
+		return c.Gemini, true
+	case EvaluatorProviderFake:
+		return c.Fake, true
+	default:
+		return EvaluatorProviderConfig{}, false
+	}
 }
 
 // CacheTTLConfig holds configuration for cache TTLs.
@@ -70,10 +254,16 @@ type BatchConfig struct {
 	DefaultScoreRanges         []string `yaml:"default_score_ranges"` // Added
 }
 
+// DefaultGeminiBaseURL is Gemini's OpenAI-compatible API endpoint.
+const DefaultGeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta/openai"
+
 // GeminiConfig holds configuration for the Gemini LLM.
 type GeminiConfig struct {
-	APIKey string `yaml:"api_key"`
-	Model  string `yaml:"model"`
+	APIKey     string        `yaml:"api_key"`
+	Model      string        `yaml:"model"`
+	BaseURL    string        `yaml:"base_url"`    // OpenAI-compatible endpoint; point at a local stub for offline runs
+	MaxRetries int           `yaml:"max_retries"` // Extra attempts when the model returns malformed JSON
+	Timeout    time.Duration `yaml:"timeout"`     // Per-request timeout for a single LLM call
 }
 
 type EmbeddingConfig struct {
@@ -151,6 +341,9 @@ func LoadConfig() (*Config, error) {
 	viper.BindEnv("llm_providers.ollama_server_url", "APP_LLM_PROVIDERS_OLLAMA_SERVER_URL")
 	viper.BindEnv("llm_providers.gemini.api_key", "APP_LLM_PROVIDERS_GEMINI_API_KEY")
 	viper.BindEnv("llm_providers.gemini.model", "APP_LLM_PROVIDERS_GEMINI_MODEL")
+	viper.BindEnv("llm_providers.gemini.base_url", "APP_LLM_PROVIDERS_GEMINI_BASE_URL")
+	viper.BindEnv("llm_providers.gemini.max_retries", "APP_LLM_PROVIDERS_GEMINI_MAX_RETRIES")
+	viper.BindEnv("llm_providers.gemini.timeout", "APP_LLM_PROVIDERS_GEMINI_TIMEOUT")
 
 	// Redis environment variables
 	viper.BindEnv("redis.address", "APP_REDIS_ADDRESS")
@@ -173,9 +366,59 @@ func LoadConfig() (*Config, error) {
 	viper.BindEnv("auth.google_oauth.client_id", "APP_AUTH_GOOGLE_OAUTH_CLIENT_ID")
 	viper.BindEnv("auth.google_oauth.client_secret", "APP_AUTH_GOOGLE_OAUTH_CLIENT_SECRET")
 	viper.BindEnv("auth.google_oauth.redirect_url", "APP_AUTH_GOOGLE_OAUTH_REDIRECT_URL")
-	viper.BindEnv("auth.jwt.secret_key", "APP_AUTH_JWT_SECRET_KEY")
+	viper.BindEnv("auth.google_oauth.auth_url", "APP_AUTH_GOOGLE_OAUTH_AUTH_URL")
+	viper.BindEnv("auth.google_oauth.token_url", "APP_AUTH_GOOGLE_OAUTH_TOKEN_URL")
+	viper.BindEnv("auth.google_oauth.jwks_url", "APP_AUTH_GOOGLE_OAUTH_JWKS_URL")
+	viper.BindEnv("auth.google_oauth.issuer", "APP_AUTH_GOOGLE_OAUTH_ISSUER")
+	viper.BindEnv("auth.google_oauth.revoke_url", "APP_AUTH_GOOGLE_OAUTH_REVOKE_URL")
+	viper.BindEnv("auth.github_oauth.client_id", "APP_AUTH_GITHUB_OAUTH_CLIENT_ID")
+	viper.BindEnv("auth.github_oauth.client_secret", "APP_AUTH_GITHUB_OAUTH_CLIENT_SECRET")
+	viper.BindEnv("auth.github_oauth.redirect_url", "APP_AUTH_GITHUB_OAUTH_REDIRECT_URL")
+	viper.BindEnv("auth.github_oauth.auth_url", "APP_AUTH_GITHUB_OAUTH_AUTH_URL")
+	viper.BindEnv("auth.github_oauth.token_url", "APP_AUTH_GITHUB_OAUTH_TOKEN_URL")
+	viper.BindEnv("auth.github_oauth.api_url", "APP_AUTH_GITHUB_OAUTH_API_URL")
+	viper.BindEnv("auth.jwt.active_key_id", "APP_AUTH_JWT_ACTIVE_KEY_ID")
 	viper.BindEnv("auth.jwt.access_token_ttl", "APP_AUTH_JWT_ACCESS_TOKEN_TTL")   // Expecting value in seconds
 	viper.BindEnv("auth.jwt.refresh_token_ttl", "APP_AUTH_JWT_REFRESH_TOKEN_TTL") // Expecting value in seconds
+	viper.BindEnv("auth.token_encryption_key", "APP_AUTH_TOKEN_ENCRYPTION_KEY")
+	viper.BindEnv("auth.mode", "APP_AUTH_MODE")
+	viper.BindEnv("auth.cookie.frontend_url", "APP_AUTH_COOKIE_FRONTEND_URL")
+	viper.BindEnv("auth.cookie.domain", "APP_AUTH_COOKIE_DOMAIN")
+	viper.BindEnv("auth.cookie.same_site", "APP_AUTH_COOKIE_SAME_SITE")
+	viper.BindEnv("auth.cookie.insecure", "APP_AUTH_COOKIE_INSECURE")
+
+	// Evaluator environment variables, e.g. APP_EVALUATOR_OPENAI_MODEL
+	viper.BindEnv("evaluator.provider", "APP_EVALUATOR_PROVIDER")
+	for _, provider := range evaluatorProviders {
+		for _, field := range evaluatorProviderFields {
+			key := fmt.Sprintf("evaluator.%s.%s", provider, field)
+			viper.BindEnv(key, "APP_"+strings.ToUpper(strings.ReplaceAll(key, ".", "_")))
+		}
+	}
+	viper.BindEnv("evaluator.ensemble.judges", "APP_EVALUATOR_ENSEMBLE_JUDGES") // Space-separated provider names
+	viper.BindEnv("evaluator.ensemble.strategy", "APP_EVALUATOR_ENSEMBLE_STRATEGY")
+	viper.BindEnv("evaluator.ensemble.trim_ratio", "APP_EVALUATOR_ENSEMBLE_TRIM_RATIO")
+	viper.BindEnv("evaluator.ensemble.disagreement_margin", "APP_EVALUATOR_ENSEMBLE_DISAGREEMENT_MARGIN")
+
+	// Check answer budget environment variables
+	viper.BindEnv("check_answer.total", "APP_CHECK_ANSWER_TOTAL")
+	viper.BindEnv("check_answer.embedding", "APP_CHECK_ANSWER_EMBEDDING")
+	viper.BindEnv("check_answer.cache_lookup", "APP_CHECK_ANSWER_CACHE_LOOKUP")
+	viper.BindEnv("check_answer.evaluation", "APP_CHECK_ANSWER_EVALUATION")
+	viper.BindEnv("check_answer.pre_score.enabled", "APP_CHECK_ANSWER_PRE_SCORE_ENABLED")
+	viper.BindEnv("check_answer.pre_score.off_topic_similarity", "APP_CHECK_ANSWER_PRE_SCORE_OFF_TOPIC_SIMILARITY")
+	viper.BindEnv("check_answer.pre_score.exact_match_similarity", "APP_CHECK_ANSWER_PRE_SCORE_EXACT_MATCH_SIMILARITY")
+	viper.BindEnv("check_answer.pre_score.fallback_on_llm_error", "APP_CHECK_ANSWER_PRE_SCORE_FALLBACK_ON_LLM_ERROR")
+	viper.BindEnv("check_answer.jobs.workers", "APP_CHECK_ANSWER_JOBS_WORKERS")
+	viper.BindEnv("check_answer.jobs.queue_size", "APP_CHECK_ANSWER_JOBS_QUEUE_SIZE")
+	viper.BindEnv("check_answer.jobs.result_ttl", "APP_CHECK_ANSWER_JOBS_RESULT_TTL")
+
+	// Attempt outbox environment variables
+	viper.BindEnv("attempt_outbox.poll_interval", "APP_ATTEMPT_OUTBOX_POLL_INTERVAL")
+	viper.BindEnv("attempt_outbox.batch_size", "APP_ATTEMPT_OUTBOX_BATCH_SIZE")
+	viper.BindEnv("attempt_outbox.max_tries", "APP_ATTEMPT_OUTBOX_MAX_TRIES")
+	viper.BindEnv("attempt_outbox.initial_backoff", "APP_ATTEMPT_OUTBOX_INITIAL_BACKOFF")
+	viper.BindEnv("attempt_outbox.max_backoff", "APP_ATTEMPT_OUTBOX_MAX_BACKOFF")
 
 	// Cache TTLs environment variables
 	viper.BindEnv("cachettls.llm_response", "APP_CACHE_TTL_LLM_RESPONSE")
@@ -189,6 +432,15 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	var jwtSigningKeys []JWTKeyConfig
+	if err := viper.UnmarshalKey("auth.jwt.signing_keys", &jwtSigningKeys); err != nil {
+		return nil, fmt.Errorf("failed to read auth.jwt.signing_keys: %w", err)
+	}
+	var oidcProviders []OIDCProviderConfig
+	if err := viper.UnmarshalKey("auth.oidc_providers", &oidcProviders); err != nil {
+		return nil, fmt.Errorf("failed to read auth.oidc_providers: %w", err)
+	}
+
 	// Log the config file being used
 	configFile := viper.ConfigFileUsed()
 	if configFile != "" {
@@ -235,18 +487,49 @@ func LoadConfig() (*Config, error) {
 				ClientID:     viper.GetString("auth.google_oauth.client_id"),
 				ClientSecret: viper.GetString("auth.google_oauth.client_secret"),
 				RedirectURL:  viper.GetString("auth.google_oauth.redirect_url"),
+				AuthURL:      viper.GetString("auth.google_oauth.auth_url"),
+				TokenURL:     viper.GetString("auth.google_oauth.token_url"),
+				JWKSURL:      viper.GetString("auth.google_oauth.jwks_url"),
+				Issuer:       viper.GetString("auth.google_oauth.issuer"),
+				RevokeURL:    viper.GetString("auth.google_oauth.revoke_url"),
+				TokenRefresh: GoogleTokenRefreshConfig{
+					Interval:      viper.GetDuration("auth.google_oauth.token_refresh.interval"),
+					RefreshBefore: viper.GetDuration("auth.google_oauth.token_refresh.refresh_before"),
+					BatchSize:     viper.GetInt("auth.google_oauth.token_refresh.batch_size"),
+				},
+			},
+			GitHubOAuth: GitHubOAuthConfig{
+				ClientID:     viper.GetString("auth.github_oauth.client_id"),
+				ClientSecret: viper.GetString("auth.github_oauth.client_secret"),
+				RedirectURL:  viper.GetString("auth.github_oauth.redirect_url"),
+				AuthURL:      viper.GetString("auth.github_oauth.auth_url"),
+				TokenURL:     viper.GetString("auth.github_oauth.token_url"),
+				APIURL:       viper.GetString("auth.github_oauth.api_url"),
 			},
+			OIDCProviders: oidcProviders,
 			JWT: JWTConfig{
-				SecretKey:       viper.GetString("auth.jwt.secret_key"),
+				SigningKeys:     jwtSigningKeys,
+				ActiveKeyID:     viper.GetString("auth.jwt.active_key_id"),
 				AccessTokenTTL:  viper.GetDuration("auth.jwt.access_token_ttl"),
 				RefreshTokenTTL: viper.GetDuration("auth.jwt.refresh_token_ttl"),
 			},
+			TokenEncryptionKey: viper.GetString("auth.token_encryption_key"),
+			Mode:               viper.GetString("auth.mode"),
+			Cookie: AuthCookieConfig{
+				FrontendURL: viper.GetString("auth.cookie.frontend_url"),
+				Domain:      viper.GetString("auth.cookie.domain"),
+				SameSite:    viper.GetString("auth.cookie.same_site"),
+				Insecure:    viper.GetBool("auth.cookie.insecure"),
+			},
 		},
 		LLMProviders: LLMProvidersConfig{
 			OllamaServerURL: viper.GetString("llm_providers.ollama_server_url"),
 			Gemini: GeminiConfig{
-				APIKey: viper.GetString("llm_providers.gemini.api_key"),
-				Model:  viper.GetString("llm_providers.gemini.model"),
+				APIKey:     viper.GetString("llm_providers.gemini.api_key"),
+				Model:      viper.GetString("llm_providers.gemini.model"),
+				BaseURL:    viper.GetString("llm_providers.gemini.base_url"),
+				MaxRetries: viper.GetInt("llm_providers.gemini.max_retries"),
+				Timeout:    viper.GetDuration("llm_providers.gemini.timeout"),
 			},
 		},
 		Logger: LoggerConfig{
@@ -261,6 +544,43 @@ func LoadConfig() (*Config, error) {
 			AnswerEvaluation: viper.GetString("cachettls.answer_evaluation"),
 			QuizDetail:       viper.GetString("cachettls.quiz_detail"),
 		},
+		Evaluator: EvaluatorConfig{
+			Provider: viper.GetString("evaluator.provider"),
+			Ollama:   loadEvaluatorProviderConfig(EvaluatorProviderOllama),
+			OpenAI:   loadEvaluatorProviderConfig(EvaluatorProviderOpenAI),
+			Gemini:   loadEvaluatorProviderConfig(EvaluatorProviderGemini),
+			Fake:     loadEvaluatorProviderConfig(EvaluatorProviderFake),
+			Ensemble: EnsembleConfig{
+				Judges:             viper.GetStringSlice("evaluator.ensemble.judges"),
+				Strategy:           viper.GetString("evaluator.ensemble.strategy"),
+				TrimRatio:          viper.GetFloat64("evaluator.ensemble.trim_ratio"),
+				DisagreementMargin: viper.GetFloat64("evaluator.ensemble.disagreement_margin"),
+			},
+		},
+		CheckAnswer: CheckAnswerConfig{
+			Total:       viper.GetDuration("check_answer.total"),
+			Embedding:   viper.GetDuration("check_answer.embedding"),
+			CacheLookup: viper.GetDuration("check_answer.cache_lookup"),
+			Evaluation:  viper.GetDuration("check_answer.evaluation"),
+			PreScore: PreScoreConfig{
+				Enabled:              viper.GetBool("check_answer.pre_score.enabled"),
+				OffTopicSimilarity:   viper.GetFloat64("check_answer.pre_score.off_topic_similarity"),
+				ExactMatchSimilarity: viper.GetFloat64("check_answer.pre_score.exact_match_similarity"),
+				FallbackOnLLMError:   viper.GetBool("check_answer.pre_score.fallback_on_llm_error"),
+			},
+			Jobs: CheckJobsConfig{
+				Workers:   viper.GetInt("check_answer.jobs.workers"),
+				QueueSize: viper.GetInt("check_answer.jobs.queue_size"),
+				ResultTTL: viper.GetDuration("check_answer.jobs.result_ttl"),
+			},
+		},
+		AttemptOutbox: AttemptOutboxConfig{
+			PollInterval:   viper.GetDuration("attempt_outbox.poll_interval"),
+			BatchSize:      viper.GetInt("attempt_outbox.batch_size"),
+			MaxTries:       viper.GetInt("attempt_outbox.max_tries"),
+			InitialBackoff: viper.GetDuration("attempt_outbox.initial_backoff"),
+			MaxBackoff:     viper.GetDuration("attempt_outbox.max_backoff"),
+		},
 	}
 
 	// Set default for SimilarityThreshold if not provided or zero
@@ -272,6 +592,15 @@ func LoadConfig() (*Config, error) {
 	if config.LLMProviders.Gemini.Model == "" {
 		config.LLMProviders.Gemini.Model = "gemini-pro" // Default model
 	}
+	if config.LLMProviders.Gemini.BaseURL == "" {
+		config.LLMProviders.Gemini.BaseURL = DefaultGeminiBaseURL
+	}
+	if !viper.IsSet("llm_providers.gemini.max_retries") {
+		config.LLMProviders.Gemini.MaxRetries = 2 // Default value
+	}
+	if config.LLMProviders.Gemini.Timeout == 0 {
+		config.LLMProviders.Gemini.Timeout = 60 * time.Second
+	}
 
 	// Set default for LLMProviders.OllamaServerURL if not provided
 	if config.LLMProviders.OllamaServerURL == "" {
@@ -296,6 +625,18 @@ func LoadConfig() (*Config, error) {
 		config.Auth.JWT.RefreshTokenTTL = 7 * 24 * time.Hour // Default to 7 days
 	}
 
+	// Set defaults for the Google OAuth endpoints and token refresh if not provided
+	applyGoogleOAuthDefaults(&config.Auth.GoogleOAuth)
+	applyGitHubOAuthDefaults(&config.Auth.GitHubOAuth)
+	if err := applyAuthModeDefaults(&config.Auth); err != nil {
+		return nil, err
+	}
+	for i := range config.Auth.OIDCProviders {
+		if len(config.Auth.OIDCProviders[i].Scopes) == 0 {
+			config.Auth.OIDCProviders[i].Scopes = []string{"openid", "email", "profile"}
+		}
+	}
+
 	// Set defaults for Server timeouts if not provided or zero
 	if config.Server.ReadTimeout == 0 {
 		config.Server.ReadTimeout = 30 * time.Second
@@ -311,6 +652,9 @@ func LoadConfig() (*Config, error) {
 	if config.Logger.Env == "" {
 		config.Logger.Env = "development"
 	}
+	if err := checkJWTSigningKeys(&config.Auth.JWT, config.Logger.Env); err != nil {
+		return nil, err
+	}
 
 	// Set defaults for CacheTTLs if not provided or empty strings
 	if config.CacheTTLs.LLMResponse == "" {
@@ -332,9 +676,128 @@ func LoadConfig() (*Config, error) {
 		config.CacheTTLs.QuizDetail = "6h"
 	}
 
+	applyEvaluatorDefaults(config)
+
+	// Set defaults for CheckAnswer budgets if not provided or zero
+	if config.CheckAnswer.Total == 0 {
+		config.CheckAnswer.Total = 30 * time.Second
+	}
+	if config.CheckAnswer.Embedding == 0 {
+		config.CheckAnswer.Embedding = 5 * time.Second
+	}
+	if config.CheckAnswer.CacheLookup == 0 {
+		config.CheckAnswer.CacheLookup = 2 * time.Second
+	}
+	if config.CheckAnswer.Evaluation == 0 {
+		config.CheckAnswer.Evaluation = 25 * time.Second
+	}
+	if !viper.IsSet("check_answer.pre_score.enabled") {
+		config.CheckAnswer.PreScore.Enabled = true
+	}
+	if !viper.IsSet("check_answer.pre_score.fallback_on_llm_error") {
+		config.CheckAnswer.PreScore.FallbackOnLLMError = true
+	}
+	if config.CheckAnswer.PreScore.OffTopicSimilarity == 0 {
+		config.CheckAnswer.PreScore.OffTopicSimilarity = 0.3
+	}
+	if config.CheckAnswer.PreScore.ExactMatchSimilarity == 0 {
+		config.CheckAnswer.PreScore.ExactMatchSimilarity = 0.97
+	}
+	if config.CheckAnswer.Jobs.Workers <= 0 {
+		config.CheckAnswer.Jobs.Workers = 2
+	}
+	if config.CheckAnswer.Jobs.QueueSize <= 0 {
+		config.CheckAnswer.Jobs.QueueSize = 100
+	}
+	if config.CheckAnswer.Jobs.ResultTTL == 0 {
+		config.CheckAnswer.Jobs.ResultTTL = time.Hour
+	}
+	if config.AttemptOutbox.PollInterval <= 0 {
+		config.AttemptOutbox.PollInterval = 5 * time.Second
+	}
+	if config.AttemptOutbox.BatchSize <= 0 {
+		config.AttemptOutbox.BatchSize = 50
+	}
+	if config.AttemptOutbox.MaxTries <= 0 {
+		config.AttemptOutbox.MaxTries = 10
+	}
+	if config.AttemptOutbox.InitialBackoff <= 0 {
+		config.AttemptOutbox.InitialBackoff = time.Second
+	}
+	if config.AttemptOutbox.MaxBackoff <= 0 {
+		config.AttemptOutbox.MaxBackoff = 10 * time.Minute
+	}
+
 	return config, nil
 }
 
+var (
+	evaluatorProviders      = []string{EvaluatorProviderOllama, EvaluatorProviderOpenAI, EvaluatorProviderGemini, EvaluatorProviderFake}
+	evaluatorProviderFields = []string{"api_key", "base_url", "model", "temperature", "timeout", "max_tokens"}
+)
+
+func loadEvaluatorProviderConfig(provider string) EvaluatorProviderConfig {
+	prefix := "evaluator." + provider + "."
+	return EvaluatorProviderConfig{
+		APIKey:      viper.GetString(prefix + "api_key"),
+		BaseURL:     viper.GetString(prefix + "base_url"),
+		Model:       viper.GetString(prefix + "model"),
+		Temperature: viper.GetFloat64(prefix + "temperature"),
+		Timeout:     viper.GetDuration(prefix + "timeout"),
+		MaxTokens:   viper.GetInt(prefix + "max_tokens"),
+	}
+}
+
+// applyEvaluatorDefaults fills unset evaluator settings. Credentials and endpoints fall back
+// to the ones already configured for embeddings and quiz generation.
+func applyEvaluatorDefaults(config *Config) {
+	if config.Evaluator.Provider == "" {
+		config.Evaluator.Provider = EvaluatorProviderOllama
+	}
+
+	defaults := map[string]EvaluatorProviderConfig{
+		EvaluatorProviderOllama: {BaseURL: config.LLMProviders.OllamaServerURL, Model: "qwen3:0.6b"},
+		EvaluatorProviderOpenAI: {APIKey: config.Embedding.OpenAI.APIKey, Model: "gpt-4o-mini"},
+		EvaluatorProviderGemini: {APIKey: config.LLMProviders.Gemini.APIKey, BaseURL: config.LLMProviders.Gemini.BaseURL, Model: config.LLMProviders.Gemini.Model},
+		EvaluatorProviderFake:   {Model: "fake"},
+	}
+	targets := map[string]*EvaluatorProviderConfig{
+		EvaluatorProviderOllama: &config.Evaluator.Ollama,
+		EvaluatorProviderOpenAI: &config.Evaluator.OpenAI,
+		EvaluatorProviderGemini: &config.Evaluator.Gemini,
+		EvaluatorProviderFake:   &config.Evaluator.Fake,
+	}
+	for provider, target := range targets {
+		def := defaults[provider]
+		if target.APIKey == "" {
+			target.APIKey = def.APIKey
+		}
+		if target.BaseURL == "" {
+			target.BaseURL = def.BaseURL
+		}
+		if target.Model == "" {
+			target.Model = def.Model
+		}
+		if !viper.IsSet("evaluator." + provider + ".temperature") {
+			target.Temperature = 0.1 // Default value
+		}
+		if target.Timeout == 0 {
+			target.Timeout = 20 * time.Second
+		}
+	}
+
+	if config.Evaluator.Ensemble.Strategy == "" {
+		config.Evaluator.Ensemble.Strategy = EnsembleStrategyMedian
+	}
+	// 0 is a valid setting for both: no trimming, and any disagreement is low-confidence
+	if !viper.IsSet("evaluator.ensemble.trim_ratio") {
+		config.Evaluator.Ensemble.TrimRatio = 0.2
+	}
+	if !viper.IsSet("evaluator.ensemble.disagreement_margin") {
+		config.Evaluator.Ensemble.DisagreementMargin = 0.3
+	}
+}
+
 // ParseTTLStringOrDefault parses a TTL string (e.g., "1h", "30m") into a time.Duration.
 // If parsing fails or the string is empty, it returns the defaultDuration.
 func (c *Config) ParseTTLStringOrDefault(ttlString string, defaultDuration time.Duration) time.Duration {
@@ -362,3 +825,80 @@ func (c *Config) GetDSN() string {
 		c.DB.DBName,
 	)
 }
+
+func applyGoogleOAuthDefaults(cfg *GoogleOAuthConfig) {
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = DefaultGoogleAuthURL
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = DefaultGoogleTokenURL
+	}
+	if cfg.JWKSURL == "" {
+		cfg.JWKSURL = DefaultGoogleJWKSURL
+	}
+	if cfg.Issuer == "" {
+		cfg.Issuer = DefaultGoogleIssuer
+	}
+	if cfg.RevokeURL == "" {
+		cfg.RevokeURL = DefaultGoogleRevokeURL
+	}
+	if cfg.TokenRefresh.Interval == 0 {
+		cfg.TokenRefresh.Interval = 5 * time.Minute
+	}
+	if cfg.TokenRefresh.RefreshBefore == 0 {
+		cfg.TokenRefresh.RefreshBefore = 10 * time.Minute
+	}
+	if cfg.TokenRefresh.BatchSize <= 0 {
+		cfg.TokenRefresh.BatchSize = 50
+	}
+}
+
+func applyGitHubOAuthDefaults(cfg *GitHubOAuthConfig) {
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = DefaultGitHubAuthURL
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = DefaultGitHubTokenURL
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultGitHubAPIURL
+	}
+}
+
+// checkJWTSigningKeys requires JWT signing keys outside development. Without them every instance signs
+// with a temporary key of its own, so its tokens are rejected by the other instances and after a restart.
+func checkJWTSigningKeys(cfg *JWTConfig, env string) error {
+	if len(cfg.SigningKeys) == 0 && env != "development" {
+		return fmt.Errorf("auth.jwt.signing_keys is required when logger.env is %q; only development may sign with a temporary key", env)
+	}
+	return nil
+}
+
+// applyAuthModeDefaults defaults to the bearer mode and checks that the cookie mode can redirect to the frontend.
+func applyAuthModeDefaults(cfg *AuthConfig) error {
+	if cfg.Mode == "" {
+		cfg.Mode = AuthModeBearer
+	}
+	if cfg.Cookie.SameSite == "" {
+		cfg.Cookie.SameSite = "Lax"
+	}
+	switch cfg.Mode {
+	case AuthModeBearer:
+	case AuthModeCookie:
+		if cfg.Cookie.FrontendURL == "" {
+			return fmt.Errorf("auth.cookie.frontend_url is required when auth.mode is %q", AuthModeCookie)
+		}
+	default:
+		return fmt.Errorf("unknown auth.mode %q, expected %q or %q", cfg.Mode, AuthModeBearer, AuthModeCookie)
+	}
+	switch strings.ToLower(cfg.Cookie.SameSite) {
+	case "lax", "strict":
+	case "none":
+		if cfg.Cookie.Insecure {
+			return errors.New("auth.cookie.same_site None requires secure cookies; unset auth.cookie.insecure")
+		}
+	default:
+		return fmt.Errorf("unknown auth.cookie.same_site %q, expected Lax, Strict or None", cfg.Cookie.SameSite)
+	}
+	return nil
+}
@@ -65,6 +65,12 @@ func CleanDatabase(db *sqlx.DB) error {
 		// 000002에서 추가된 트리거들
 		"BEGIN EXECUTE IMMEDIATE 'DROP TRIGGER users_updated_at_trigger'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -4080 THEN RAISE; END IF; END;",
 		"BEGIN EXECUTE IMMEDIATE 'DROP TRIGGER user_quiz_attempts_updated_at_trigger'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -4080 THEN RAISE; END IF; END;",
+		// 000003에서 추가된 트리거들
+		"BEGIN EXECUTE IMMEDIATE 'DROP TRIGGER attempt_outbox_updated_at_trigger'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -4080 THEN RAISE; END IF; END;",
+		// 000004에서 추가된 트리거들
+		"BEGIN EXECUTE IMMEDIATE 'DROP TRIGGER user_sessions_updated_at_trigger'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -4080 THEN RAISE; END IF; END;",
+		// 000006에서 추가된 트리거들
+		"BEGIN EXECUTE IMMEDIATE 'DROP TRIGGER user_identities_updated_at_trigger'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -4080 THEN RAISE; END IF; END;",
 
 		// Indexes 삭제 (000001)
 		"BEGIN EXECUTE IMMEDIATE 'DROP INDEX idx_quiz_evaluations_quiz_id'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -1418 THEN RAISE; END IF; END;",
@@ -76,8 +82,29 @@ func CleanDatabase(db *sqlx.DB) error {
 		"BEGIN EXECUTE IMMEDIATE 'DROP INDEX idx_user_quiz_attempts_user_id'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -1418 THEN RAISE; END IF; END;",
 		"BEGIN EXECUTE IMMEDIATE 'DROP INDEX idx_user_quiz_attempts_quiz_id'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -1418 THEN RAISE; END IF; END;",
 		"BEGIN EXECUTE IMMEDIATE 'DROP INDEX idx_user_quiz_attempts_attempted_at'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -1418 THEN RAISE; END IF; END;",
+		// 000003에서 추가된 인덱스들
+		"BEGIN EXECUTE IMMEDIATE 'DROP INDEX idx_attempt_outbox_due'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -1418 THEN RAISE; END IF; END;",
+		// 000004에서 추가된 인덱스들
+		"BEGIN EXECUTE IMMEDIATE 'DROP INDEX idx_user_sessions_user_id'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -1418 THEN RAISE; END IF; END;",
+		// 000005에서 추가된 인덱스들
+		"BEGIN EXECUTE IMMEDIATE 'DROP INDEX idx_user_sessions_active'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -1418 THEN RAISE; END IF; END;",
+		// 000006에서 추가된 인덱스들
+		"BEGIN EXECUTE IMMEDIATE 'DROP INDEX idx_user_identities_user_id'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -1418 THEN RAISE; END IF; END;",
+		// 000007에서 추가된 인덱스들
+		"BEGIN EXECUTE IMMEDIATE 'DROP INDEX idx_user_roles_role_name'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -1418 THEN RAISE; END IF; END;",
+		// 000008에서 추가된 인덱스들
+		"BEGIN EXECUTE IMMEDIATE 'DROP INDEX uq_categories_active_name'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -1418 THEN RAISE; END IF; END;",
+		// 000010에서 추가된 인덱스들
+		"BEGIN EXECUTE IMMEDIATE 'DROP INDEX idx_quizzes_sub_category_status'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -1418 THEN RAISE; END IF; END;",
+		"BEGIN EXECUTE IMMEDIATE 'DROP INDEX idx_quiz_status_changes_quiz_id'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 AND SQLCODE != -1418 THEN RAISE; END IF; END;",
 
 		// Tables 삭제 (dependency 순서대로)
+		// 000009에서 추가된 테이블들 (quizzes보다 먼저 삭제)
+		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE quiz_revisions CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
+		// 000010에서 추가된 테이블들 (quizzes보다 먼저 삭제)
+		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE quiz_status_changes CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
+		// 000011에서 추가된 테이블들 (quizzes보다 먼저 삭제)
+		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE quiz_seeds CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
 		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE quiz_evaluations CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
 		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE answers CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
 		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE quizzes CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
@@ -86,6 +113,15 @@ func CleanDatabase(db *sqlx.DB) error {
 		// 000002에서 추가된 테이블들
 		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE user_quiz_attempts CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
 		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE users CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
+		// 000003에서 추가된 테이블들
+		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE attempt_outbox CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
+		// 000004에서 추가된 테이블들 (users보다 먼저 삭제)
+		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE user_sessions CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
+		// 000006에서 추가된 테이블들 (users보다 먼저 삭제)
+		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE user_identities CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
+		// 000007에서 추가된 테이블들 (users보다 먼저 삭제)
+		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE user_roles CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
+		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE roles CASCADE CONSTRAINTS'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
 
 		// Migration table 삭제
 		"BEGIN EXECUTE IMMEDIATE 'DROP TABLE gorp_migrations'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -942 THEN RAISE; END IF; END;",
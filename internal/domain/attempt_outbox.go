@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Attempt outbox entry states.
+const (
+	OutboxStatusPending = "pending" // Waiting for the dispatcher
+	OutboxStatusDone    = "done"    // Written to user_quiz_attempts
+	OutboxStatusFailed  = "failed"  // Given up after the maximum number of tries
+)
+
+// AttemptOutboxEntry is a quiz attempt waiting to be written to the user's attempt history.
+type AttemptOutboxEntry struct {
+	ID             string          // Also used as the ID of the recorded attempt
+	IdempotencyKey string          // An entry repeating the key of an earlier one is dropped
+	Attempt        UserQuizAttempt // The attempt to record
+	Status         string          // One of the OutboxStatus constants
+	Tries          int             // Failed dispatch tries so far
+	NextAttemptAt  time.Time       // The dispatcher skips the entry until then
+	LastError      string
+	CreatedAt      time.Time
+	ProcessedAt    *time.Time
+}
+
+// AttemptOutboxRepository stores attempts until the outbox dispatcher has recorded them.
+type AttemptOutboxRepository interface {
+	// Enqueue stores a pending entry. It returns false, without error, when an entry with the
+	// same idempotency key already exists.
+	Enqueue(ctx context.Context, entry *AttemptOutboxEntry) (bool, error)
+	// FetchDue returns up to limit pending entries whose next attempt time is not after now, oldest first.
+	FetchDue(ctx context.Context, now time.Time, limit int) ([]*AttemptOutboxEntry, error)
+	// MarkDone marks a pending entry as done. It returns false when the entry is no longer pending,
+	// which happens when another dispatcher recorded it first.
+	MarkDone(ctx context.Context, id string) (bool, error)
+	// MarkFailed records a failed try. The entry is retried at nextAttemptAt, or marked failed when giveUp is set.
+	MarkFailed(ctx context.Context, id string, tries int, nextAttemptAt time.Time, lastError string, giveUp bool) error
+}
@@ -31,6 +31,10 @@ type Cache interface {
 	// It should not return an error if the key is not found.
 	Delete(ctx context.Context, key string) error
 
+	// GetDel retrieves an item and removes it in one atomic step, so only one caller can get it.
+	// It returns ErrCacheMiss if the key is not found.
+	GetDel(ctx context.Context, key string) (string, error)
+
 	// Ping checks the health of the cache service.
 	Ping(ctx context.Context) error
 
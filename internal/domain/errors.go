@@ -12,12 +12,15 @@ var (
 	ErrInvalidInput = errors.New("invalid input")
 	ErrNotFound     = errors.New("not found")
 	ErrUnauthorized = errors.New("unauthorized")
+	ErrConflict     = errors.New("conflict")
 
 	// Quiz specific errors
-	ErrQuizNotFound    = errors.New("quiz not found")
-	ErrInvalidAnswer   = errors.New("invalid answer")
-	ErrLLMServiceError = errors.New("llm service error")
-	ErrInvalidCategory = errors.New("invalid category")
+	ErrQuizNotFound      = errors.New("quiz not found")
+	ErrInvalidAnswer     = errors.New("invalid answer")
+	ErrLLMServiceError   = errors.New("llm service error")
+	ErrInvalidCategory   = errors.New("invalid category")
+	ErrEvaluationTimeout = errors.New("evaluation timeout")
+	ErrGradingQueueFull  = errors.New("grading queue full")
 
 	// Validation errors
 	ErrValidation    = errors.New("validation error")
@@ -34,11 +37,14 @@ const (
 	CodeInvalidInput ErrorCode = "INVALID_INPUT"
 	CodeNotFound     ErrorCode = "NOT_FOUND"
 	CodeUnauthorized ErrorCode = "UNAUTHORIZED"
+	CodeConflict     ErrorCode = "CONFLICT"
 
-	CodeQuizNotFound    ErrorCode = "QUIZ_NOT_FOUND"
-	CodeInvalidAnswer   ErrorCode = "INVALID_ANSWER"
-	CodeLLMServiceError ErrorCode = "LLM_SERVICE_ERROR"
-	CodeInvalidCategory ErrorCode = "INVALID_CATEGORY"
+	CodeQuizNotFound      ErrorCode = "QUIZ_NOT_FOUND"
+	CodeInvalidAnswer     ErrorCode = "INVALID_ANSWER"
+	CodeLLMServiceError   ErrorCode = "LLM_SERVICE_ERROR"
+	CodeInvalidCategory   ErrorCode = "INVALID_CATEGORY"
+	CodeEvaluationTimeout ErrorCode = "EVALUATION_TIMEOUT"
+	CodeGradingQueueFull  ErrorCode = "GRADING_QUEUE_FULL"
 
 	CodeValidation    ErrorCode = "VALIDATION_ERROR"
 	CodeMissingField  ErrorCode = "MISSING_FIELD"
@@ -115,6 +121,11 @@ func NewValidationError(message string) error {
 	return NewError(CodeValidation, message, ErrValidation)
 }
 
+// NewConflictError reports that a change clashes with the current state, e.g. a name that is taken
+func NewConflictError(message string) error {
+	return NewError(CodeConflict, message, ErrConflict)
+}
+
 func NewInternalError(message string, err error) error {
 	return NewError(CodeInternal, message, err)
 
@@ -133,6 +144,17 @@ func NewLLMServiceError(err error) error {
 	return NewError(CodeLLMServiceError, "Failed to process with LLM service", fmt.Errorf("%w: %v", ErrLLMServiceError, err))
 }
 
+// NewEvaluationTimeoutError reports that answer evaluation ran past its time budget in the given stage
+func NewEvaluationTimeoutError(stage string, err error) error {
+	return NewError(CodeEvaluationTimeout, "Answer evaluation timed out", fmt.Errorf("%w: %v", ErrEvaluationTimeout, err)).
+		WithContext("stage", stage)
+}
+
+// NewGradingQueueFullError reports that an asynchronous grading job was rejected because no worker can take it
+func NewGradingQueueFullError() error {
+	return NewError(CodeGradingQueueFull, "Too many answers are waiting to be graded, please retry later", ErrGradingQueueFull)
+}
+
 func NewInvalidCategoryError(category string) error {
 	return NewError(CodeInvalidCategory, fmt.Sprintf("Invalid category: %s", category), ErrInvalidCategory).
 		WithContext("category", category)
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Names of the built-in identity providers. Generic OpenID Connect providers are named in the configuration.
+const (
+	IdentityProviderGoogle = "google"
+	IdentityProviderGitHub = "github"
+)
+
+// UserIdentity links an account at an identity provider to a user. A user can sign in with
+// every identity linked to them.
+type UserIdentity struct {
+	ID          string
+	UserID      string
+	Provider    string // Name of the identity provider, e.g. IdentityProviderGoogle
+	Subject     string // The provider's ID of the account, unique per provider
+	Email       string // Email the provider reported at the last sign-in
+	LastLoginAt time.Time
+	CreatedAt   time.Time
+}
+
+// UserIdentityRepository defines the interface for user identity persistence.
+type UserIdentityRepository interface {
+	// GetIdentity returns nil, without error, when the provider account is not linked to a user.
+	GetIdentity(ctx context.Context, provider string, subject string) (*UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *UserIdentity) error
+	// UpdateIdentityLogin records a sign-in with the identity and the email reported with it.
+	UpdateIdentityLogin(ctx context.Context, identityID string, email string, at time.Time) error
+	// ListIdentitiesByUserID returns the identities of the user, oldest first.
+	ListIdentitiesByUserID(ctx context.Context, userID string) ([]UserIdentity, error)
+	// DeleteIdentitiesByUserID unlinks all identities of the user, so the accounts can sign up again.
+	DeleteIdentitiesByUserID(ctx context.Context, userID string) error
+}
@@ -82,6 +82,8 @@ type Quiz struct {
 	Keywords      []string // Keywords for similarity matching
 	Difficulty    int      // Difficulty (1: Easy, 2: Medium, 3: Hard)
 	SubCategoryID string   // FK to SubCategory
+	Revision      int      // Number of the current QuizRevision; starts at 1 and grows with every update
+	Status        string   // Editorial status, e.g. QuizStatusPublished
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
@@ -95,6 +97,8 @@ func NewQuiz(question string, modelAnswers []string, keywords []string, difficul
 		Keywords:      keywords,
 		Difficulty:    difficulty,
 		SubCategoryID: subCategoryID,
+		Revision:      1,
+		Status:        QuizStatusPublished,
 		CreatedAt:     now,
 		UpdatedAt:     now,
 	}
@@ -114,6 +118,20 @@ func DifficultyToInt(diff string) int {
 	}
 }
 
+// ParseDifficulty is DifficultyToInt without the fallback: it returns 0 for an unknown level, which Quiz.Validate rejects
+func ParseDifficulty(diff string) int {
+	switch strings.ToLower(diff) {
+	case "easy":
+		return DifficultyEasy
+	case "medium":
+		return DifficultyMedium
+	case "hard":
+		return DifficultyHard
+	default:
+		return 0
+	}
+}
+
 func (q *Quiz) DifficultyToString() string {
 	switch q.Difficulty {
 	case 1:
@@ -127,6 +145,19 @@ func (q *Quiz) DifficultyToString() string {
 	}
 }
 
+// QuizFilter narrows down random quiz selection within a subcategory
+type QuizFilter struct {
+	Difficulty               int      // 0 means any difficulty
+	Keywords                 []string // Quiz must contain at least one of these keywords
+	ExcludeIDs               []string // Quizzes to skip (e.g. already seen by the client)
+	ExcludeAttemptedByUserID string   // Skip quizzes this user already answered
+}
+
+// IsEmpty reports whether the filter has no conditions set
+func (f *QuizFilter) IsEmpty() bool {
+	return f == nil || (f.Difficulty == 0 && len(f.Keywords) == 0 && len(f.ExcludeIDs) == 0 && f.ExcludeAttemptedByUserID == "")
+}
+
 // Validate validates the quiz
 func (q *Quiz) Validate() error {
 	if q.Question == "" {
@@ -135,24 +166,49 @@ func (q *Quiz) Validate() error {
 	if len(q.ModelAnswers) == 0 {
 		return NewValidationError("at least one model answer is required")
 	}
+	if q.Difficulty < DifficultyEasy || q.Difficulty > DifficultyHard {
+		return NewValidationError("difficulty must be easy, medium or hard")
+	}
+	if q.SubCategoryID == "" {
+		return NewValidationError("subcategory ID is required")
+	}
 	return nil
 }
 
 // Answer represents a user's answer to a quiz
 // @Description Detailed result of a user's answer evaluation
 type Answer struct {
-	ID             string
-	QuizID         string
-	UserAnswer     string   // Descriptive answer
-	Score          float64  // Score between 0.0 and 1.0
-	Explanation    string   // Feedback generated by LLM
-	KeywordMatches []string // Matched keywords
-	Completeness   float64  // Answer completeness (0.0 ~ 1.0)
-	Relevance      float64  // Answer relevance (0.0 ~ 1.0)
-	Accuracy       float64  // Answer accuracy (0.0 ~ 1.0)
-	AnsweredAt     time.Time
+	ID              string
+	QuizID          string
+	UserAnswer      string   // Descriptive answer
+	Score           float64  // Score between 0.0 and 1.0
+	Explanation     string   // Feedback generated by LLM
+	KeywordMatches  []string // Matched keywords
+	Completeness    float64  // Answer completeness (0.0 ~ 1.0)
+	Relevance       float64  // Answer relevance (0.0 ~ 1.0)
+	Accuracy        float64  // Answer accuracy (0.0 ~ 1.0)
+	RubricSatisfied []string // Rubric items the answer covers
+	RubricMissed    []string // Rubric items the answer does not cover
+	Confidence      string   // ConfidenceHigh or ConfidenceLow; empty when a single judge graded the answer
+	JudgeCount      int      // Number of judges whose scores were combined
+	ScoredBy        string   // ScoredByLLM, ScoredByPreScore or ScoredByFallback
+	QuizRevision    int      // Revision of the quiz the answer was graded against; 0 when unknown
+	AnsweredAt      time.Time
 }
 
+// Stages that can produce an answer's score.
+const (
+	ScoredByLLM      = "llm"       // Graded by the configured evaluator
+	ScoredByPreScore = "pre_score" // Decided locally without calling the evaluator
+	ScoredByFallback = "fallback"  // Local estimate used because the evaluator failed
+)
+
+// Confidence levels of an ensemble evaluation.
+const (
+	ConfidenceHigh = "high" // Judges agreed within the configured margin
+	ConfidenceLow  = "low"  // Judges disagreed by more than the configured margin
+)
+
 // ScoreEvaluationDetail represents detailed evaluation criteria for a specific score range.
 type ScoreEvaluationDetail struct {
 	ScoreRange    string   `json:"score_range"`    // 예: "0.8-1.0", "0.6-0.8"
@@ -286,6 +342,29 @@ func (e *QuizEvaluation) Validate() error {
 	return nil
 }
 
+// MinimumKeywordsItem is the rubric item for the minimum keyword requirement.
+func MinimumKeywordsItem(n int) string {
+	return fmt.Sprintf("Mentions at least %d keywords", n)
+}
+
+// RubricItems lists the checkable rubric items: every required topic, then the
+// minimum keyword requirement when one is set.
+func (e *QuizEvaluation) RubricItems() []string {
+	if e == nil {
+		return nil
+	}
+	items := make([]string, 0, len(e.RequiredTopics)+1)
+	for _, topic := range e.RequiredTopics {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			items = append(items, topic)
+		}
+	}
+	if e.MinimumKeywords > 0 {
+		items = append(items, MinimumKeywordsItem(e.MinimumKeywords))
+	}
+	return items
+}
+
 // InternalError represents an internal error
 type InternalError struct {
 	message string
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// QuizRevision is a snapshot of the gradable content of a quiz. A quiz's revisions are numbered
+// from 1; the quiz itself always holds the content of its latest revision.
+type QuizRevision struct {
+	QuizID        string
+	Revision      int
+	Question      string
+	ModelAnswers  []string
+	Keywords      []string
+	Difficulty    int
+	SubCategoryID string
+	Author        string // ID of the admin who made the change; empty for revisions predating the history
+	CreatedAt     time.Time
+}
+
+// NewQuizRevision takes a snapshot of the quiz at its current revision.
+func NewQuizRevision(quiz *Quiz, author string, createdAt time.Time) *QuizRevision {
+	return &QuizRevision{
+		QuizID:        quiz.ID,
+		Revision:      quiz.Revision,
+		Question:      quiz.Question,
+		ModelAnswers:  slices.Clone(quiz.ModelAnswers),
+		Keywords:      slices.Clone(quiz.Keywords),
+		Difficulty:    quiz.Difficulty,
+		SubCategoryID: quiz.SubCategoryID,
+		Author:        author,
+		CreatedAt:     createdAt,
+	}
+}
+
+// QuizFieldChange is a field that differs between two revisions of a quiz.
+type QuizFieldChange struct {
+	Field string      // JSON name of the field, as in the quiz admin API
+	Old   interface{} // Value in the older revision
+	New   interface{} // Value in the newer revision
+}
+
+// DiffQuizRevisions lists the fields that changed from one revision to another, in a fixed order.
+func DiffQuizRevisions(from, to *QuizRevision) []QuizFieldChange {
+	var changes []QuizFieldChange
+	if from.Question != to.Question {
+		changes = append(changes, QuizFieldChange{Field: "question", Old: from.Question, New: to.Question})
+	}
+	if !slices.Equal(from.ModelAnswers, to.ModelAnswers) {
+		changes = append(changes, QuizFieldChange{Field: "model_answers", Old: from.ModelAnswers, New: to.ModelAnswers})
+	}
+	if !slices.Equal(from.Keywords, to.Keywords) {
+		changes = append(changes, QuizFieldChange{Field: "keywords", Old: from.Keywords, New: to.Keywords})
+	}
+	if from.Difficulty != to.Difficulty {
+		fromQuiz, toQuiz := Quiz{Difficulty: from.Difficulty}, Quiz{Difficulty: to.Difficulty}
+		changes = append(changes, QuizFieldChange{Field: "difficulty", Old: fromQuiz.DifficultyToString(), New: toQuiz.DifficultyToString()})
+	}
+	if from.SubCategoryID != to.SubCategoryID {
+		changes = append(changes, QuizFieldChange{Field: "sub_category_id", Old: from.SubCategoryID, New: to.SubCategoryID})
+	}
+	return changes
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffQuizRevisions(t *testing.T) {
+	from := &QuizRevision{Revision: 1, Question: "What is Go?", ModelAnswers: []string{"A language"}, Keywords: []string{"go"}, Difficulty: DifficultyEasy, SubCategoryID: "sub-1"}
+
+	same := *from
+	same.Revision = 2
+	assert.Empty(t, DiffQuizRevisions(from, &same))
+
+	to := &QuizRevision{Revision: 3, Question: "What is Go used for?", ModelAnswers: []string{"A language"}, Keywords: []string{"go", "servers"}, Difficulty: DifficultyMedium, SubCategoryID: "sub-2"}
+	assert.Equal(t, []QuizFieldChange{
+		{Field: "question", Old: "What is Go?", New: "What is Go used for?"},
+		{Field: "keywords", Old: []string{"go"}, New: []string{"go", "servers"}},
+		{Field: "difficulty", Old: "easy", New: "medium"},
+		{Field: "sub_category_id", Old: "sub-1", New: "sub-2"},
+	}, DiffQuizRevisions(from, to))
+}
+
+func TestNewQuizRevision_CopiesLists(t *testing.T) {
+	quiz := &Quiz{ID: "quiz-1", Revision: 2, ModelAnswers: []string{"A"}, Keywords: []string{"k"}}
+	revision := NewQuizRevision(quiz, "admin-1", quiz.UpdatedAt)
+	quiz.ModelAnswers[0] = "changed"
+
+	assert.Equal(t, 2, revision.Revision)
+	assert.Equal(t, "admin-1", revision.Author)
+	assert.Equal(t, []string{"A"}, revision.ModelAnswers)
+}
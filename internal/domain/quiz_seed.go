@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// QuizSeed links a quiz to the seed file entry it was created from.
+type QuizSeed struct {
+	Key         string // Key of the seed entry, stable across runs of the seeder
+	QuizID      string
+	ContentHash string // Hash of the entry's content when it was last seeded
+	SeededAt    time.Time
+}
+
+// QuizSeedRepository defines the interface for quiz seed persistence.
+type QuizSeedRepository interface {
+	// ListQuizSeeds returns the seeds of the quizzes that are not deleted.
+	ListQuizSeeds(ctx context.Context) ([]*QuizSeed, error)
+	// SaveQuizSeed links seed.QuizID to seed.Key, replacing the quiz or content hash the key had.
+	SaveQuizSeed(ctx context.Context, seed *QuizSeed) error
+	// RekeyQuizSeed moves the seed under oldKey to newKey, dropping a seed newKey had.
+	RekeyQuizSeed(ctx context.Context, oldKey, newKey string) error
+}
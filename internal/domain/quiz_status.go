@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Editorial statuses of a quiz. Only published quizzes are served to learners.
+const (
+	QuizStatusDraft     = "draft"     // Not reviewed yet, e.g. generated by a batch job
+	QuizStatusInReview  = "in_review" // Edited by a reviewer and waiting for approval
+	QuizStatusPublished = "published" // Served to learners
+	QuizStatusRetired   = "retired"   // Rejected or withdrawn; kept for the attempts that reference it
+)
+
+// quizStatusTransitions lists the statuses a quiz can move to from each status.
+var quizStatusTransitions = map[string][]string{
+	QuizStatusDraft:     {QuizStatusInReview, QuizStatusPublished, QuizStatusRetired},
+	QuizStatusInReview:  {QuizStatusDraft, QuizStatusPublished, QuizStatusRetired},
+	QuizStatusPublished: {QuizStatusRetired},
+	QuizStatusRetired:   {QuizStatusDraft},
+}
+
+// IsValidQuizStatus reports whether status is one of the editorial statuses.
+func IsValidQuizStatus(status string) bool {
+	_, ok := quizStatusTransitions[status]
+	return ok
+}
+
+// CanTransitionQuizStatus reports whether a quiz can move from one status to another.
+func CanTransitionQuizStatus(from, to string) bool {
+	return slices.Contains(quizStatusTransitions[from], to)
+}
+
+// QuizStatusChange is the audit record of a quiz moving from one status to another.
+type QuizStatusChange struct {
+	ID         string
+	QuizID     string
+	FromStatus string
+	ToStatus   string
+	Actor      string // ID of the admin who made the change
+	Comment    string // Reviewer's comment, e.g. why the quiz was rejected
+	CreatedAt  time.Time
+}
+
+// QuizStatusRepository defines the interface for quiz status persistence.
+type QuizStatusRepository interface {
+	// ChangeQuizStatus moves the quiz from change.FromStatus to change.ToStatus and records the change.
+	// It fails with ErrConflict when the quiz is no longer in change.FromStatus.
+	// Run it in a transaction so the status and its audit record are saved together.
+	ChangeQuizStatus(ctx context.Context, change *QuizStatusChange) error
+	// ListQuizStatusChanges returns the status changes of a quiz, oldest first.
+	ListQuizStatusChanges(ctx context.Context, quizID string) ([]*QuizStatusChange, error)
+	// ListQuizzesByStatus returns up to limit active quizzes in one of the statuses, least recently updated first.
+	ListQuizzesByStatus(ctx context.Context, statuses []string, limit int) ([]*Quiz, error)
+}
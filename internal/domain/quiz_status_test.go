@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransitionQuizStatus(t *testing.T) {
+	assert.True(t, CanTransitionQuizStatus(QuizStatusDraft, QuizStatusPublished))
+	assert.True(t, CanTransitionQuizStatus(QuizStatusInReview, QuizStatusRetired))
+	assert.True(t, CanTransitionQuizStatus(QuizStatusPublished, QuizStatusRetired))
+	assert.True(t, CanTransitionQuizStatus(QuizStatusRetired, QuizStatusDraft))
+
+	// Published quizzes are retired rather than edited back into review, and retired ones are redrafted first.
+	assert.False(t, CanTransitionQuizStatus(QuizStatusPublished, QuizStatusDraft))
+	assert.False(t, CanTransitionQuizStatus(QuizStatusRetired, QuizStatusPublished))
+	assert.False(t, CanTransitionQuizStatus(QuizStatusDraft, QuizStatusDraft))
+	assert.False(t, CanTransitionQuizStatus("archived", QuizStatusDraft))
+}
+
+func TestIsValidQuizStatus(t *testing.T) {
+	assert.True(t, IsValidQuizStatus(QuizStatusInReview))
+	assert.False(t, IsValidQuizStatus(""))
+	assert.False(t, IsValidQuizStatus("Published"))
+}
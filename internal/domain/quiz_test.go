@@ -23,6 +23,33 @@ func TestScoreEvaluationDetail_Validate_Dummy(t *testing.T) {
 	}
 }
 
+func TestQuiz_Validate(t *testing.T) {
+	valid := func() *Quiz {
+		return &Quiz{Question: "Q?", ModelAnswers: []string{"A"}, Difficulty: DifficultyMedium, SubCategoryID: "sub-1"}
+	}
+	tests := []struct {
+		name    string
+		modify  func(q *Quiz)
+		wantErr bool
+	}{
+		{"valid quiz", func(q *Quiz) {}, false},
+		{"missing question", func(q *Quiz) { q.Question = "" }, true},
+		{"no model answers", func(q *Quiz) { q.ModelAnswers = nil }, true},
+		{"unknown difficulty", func(q *Quiz) { q.Difficulty = ParseDifficulty("extreme") }, true},
+		{"difficulty above hard", func(q *Quiz) { q.Difficulty = DifficultyHard + 1 }, true},
+		{"missing subcategory", func(q *Quiz) { q.SubCategoryID = "" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quiz := valid()
+			tt.modify(quiz)
+			if err := quiz.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Quiz.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestQuizEvaluation_Validate(t *testing.T) {
 	validScoreRanges := []string{"0.0-0.5", "0.5-1.0"}
 	validScoreEvals := []ScoreEvaluationDetail{
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Names of the roles, seeded by migrations. Users without a role are learners.
+const (
+	RoleAdmin = "admin" // Manages content, users and batch jobs
+)
+
+// Role is a set of permissions granted to users.
+type Role struct {
+	Name        string
+	Description string
+	CreatedAt   time.Time
+}
+
+// UserRole records that a role was granted to a user.
+type UserRole struct {
+	UserID    string
+	Email     string // Email of the user, for listings
+	Role      string
+	GrantedBy string // ID of the admin who granted the role, or a description of the CLI operator
+	GrantedAt time.Time
+}
+
+// RoleRepository defines the interface for role persistence.
+type RoleRepository interface {
+	// GetRole returns nil, without error, when there is no role with the name.
+	GetRole(ctx context.Context, name string) (*Role, error)
+	// ListUserRoles returns the names of the roles of an active user, sorted by name.
+	ListUserRoles(ctx context.Context, userID string) ([]string, error)
+	// ListRoleMembers returns the active users with the role, in the order it was granted.
+	ListRoleMembers(ctx context.Context, role string) ([]UserRole, error)
+	// GrantRole grants the role to the user. It returns false when the user already had it.
+	GrantRole(ctx context.Context, grant *UserRole) (bool, error)
+	// RevokeRole takes the role from the user. It returns false when the user did not have it.
+	RevokeRole(ctx context.Context, userID string, role string) (bool, error)
+}
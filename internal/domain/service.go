@@ -34,8 +34,8 @@ type QuizService interface {
 type QuizRepository interface {
 	GetQuizByID(ctx context.Context, id string) (*Quiz, error)
 	GetRandomQuiz(ctx context.Context) (*Quiz, error)
-	GetRandomQuizBySubCategory(ctx context.Context, subCategory string) (*Quiz, error)
-	GetSimilarQuiz(ctx context.Context, quizID string) (*Quiz, error)
+	GetRandomQuizBySubCategory(ctx context.Context, subCategoryID string, filter *QuizFilter) (*Quiz, error)
+	GetSimilarQuiz(ctx context.Context, quizID string, filter *QuizFilter) (*Quiz, error)
 	GetAllSubCategories(ctx context.Context) ([]string, error)
 	SaveAnswer(ctx context.Context, answer *Answer) error
 	SaveQuiz(ctx context.Context, quiz *Quiz) error
@@ -43,9 +43,21 @@ type QuizRepository interface {
 	GetSubCategoryIDByName(ctx context.Context, name string) (string, error)
 	GetQuizzesBySubCategory(ctx context.Context, subCategoryID string) ([]*Quiz, error)
 	// Methods from internal/domain/quiz.go
-	UpdateQuiz(ctx context.Context, quiz *Quiz) error
+	// UpdateQuiz saves the quiz as its next revision and records a QuizRevision by author.
+	// It fails with ErrConflict when quiz.Revision is no longer the quiz's current revision.
+	UpdateQuiz(ctx context.Context, quiz *Quiz, author string) error
+	// ListQuizRevisions returns the revisions of a quiz, newest first.
+	ListQuizRevisions(ctx context.Context, quizID string) ([]*QuizRevision, error)
+	// GetQuizRevision returns (nil, nil) when the quiz has no such revision.
+	GetQuizRevision(ctx context.Context, quizID string, revision int) (*QuizRevision, error)
 	SaveQuizEvaluation(ctx context.Context, evaluation *QuizEvaluation) error
 	GetQuizEvaluation(ctx context.Context, quizID string) (*QuizEvaluation, error)
+	// UpdateQuizEvaluation replaces the rubric of the evaluation's quiz.
+	UpdateQuizEvaluation(ctx context.Context, evaluation *QuizEvaluation) error
+	// DeleteQuiz soft deletes a quiz. It returns false when there was no active quiz with the ID.
+	DeleteQuiz(ctx context.Context, id string) (bool, error)
+	// DeleteQuizEvaluation soft deletes the rubric of a quiz. It returns false when the quiz had none.
+	DeleteQuizEvaluation(ctx context.Context, quizID string) (bool, error)
 	GetUnattemptedQuizzesWithDetails(ctx context.Context, userID string, limit int, optionalSubCategoryID string) ([]dto.QuizRecommendationItem, error)
 }
 
@@ -65,4 +77,17 @@ type CategoryRepository interface {
 
 	GetByName(ctx context.Context, name string) (*Category, error)
 	GetByNameAndCategoryID(ctx context.Context, name string, categoryID string) (*SubCategory, error)
+
+	// GetCategoryByID and GetSubCategoryByID return (nil, nil) when there is no active row with the ID
+	GetCategoryByID(ctx context.Context, id string) (*Category, error)
+	GetSubCategoryByID(ctx context.Context, id string) (*SubCategory, error)
+
+	// UpdateCategory and UpdateSubCategory save the name and description of an active row
+	UpdateCategory(ctx context.Context, category *Category) error
+	UpdateSubCategory(ctx context.Context, subCategory *SubCategory) error
+
+	// DeleteCategory and DeleteSubCategory soft delete a row that has no active children (subcategories or quizzes).
+	// They return false when there was no active row with the ID or it still has children.
+	DeleteCategory(ctx context.Context, id string) (bool, error)
+	DeleteSubCategory(ctx context.Context, id string) (bool, error)
 }
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Reasons a session was revoked.
+const (
+	SessionRevokedLogout         = "logout"              // The user logged out
+	SessionRevokedTokenReuse     = "refresh_token_reuse" // A rotated refresh token was presented again
+	SessionRevokedByUser         = "revoked_by_user"     // Signed out from another session
+	SessionRevokedAccountDeleted = "account_deleted"     // The user deleted their account
+)
+
+// UserSession is one sign-in of a user: the family of refresh tokens rotated from the first one.
+// Only the latest refresh token of the family can be used.
+type UserSession struct {
+	ID            string // Carried in the "sid" claim of every token issued for the session
+	UserID        string
+	CurrentJTI    string    // jti of the refresh token that can be used next
+	ExpiresAt     time.Time // Expiry of the current refresh token
+	LastUsedAt    time.Time
+	RevokedAt     *time.Time
+	RevokedReason string // One of the SessionRevoked constants
+	Device        string // Browser and OS, derived from UserAgent
+	UserAgent     string
+	IPAddress     string
+	CreatedAt     time.Time
+}
+
+// UserSessionRepository defines the interface for user session persistence.
+type UserSessionRepository interface {
+	CreateSession(ctx context.Context, session *UserSession) error
+	// GetSessionByID returns nil, without error, when the session does not exist.
+	GetSessionByID(ctx context.Context, id string) (*UserSession, error)
+	// ListActiveSessions returns the sessions of the user that are neither revoked nor expired at now, most recently used first.
+	ListActiveSessions(ctx context.Context, userID string, now time.Time) ([]UserSession, error)
+	// RotateSession replaces the current jti of an active session, but only if it is still currentJTI.
+	// It returns false when the session was revoked or the jti has already been rotated.
+	RotateSession(ctx context.Context, id string, currentJTI string, newJTI string, expiresAt time.Time, usedAt time.Time) (bool, error)
+	// RevokeSession revokes an active session. It returns false when the session is unknown or already revoked.
+	RevokeSession(ctx context.Context, id string, reason string, revokedAt time.Time) (bool, error)
+}
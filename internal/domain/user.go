@@ -10,7 +10,6 @@ import (
 // User represents a domain user object
 type User struct {
 	ID                string
-	GoogleID          string
 	Email             string
 	Name              string
 	ProfilePictureURL string
@@ -20,10 +19,9 @@ type User struct {
 }
 
 // NewUser creates a new User instance
-func NewUser(googleID, email string) *User {
+func NewUser(email string) *User {
 	now := time.Now()
 	return &User{
-		GoogleID:  googleID,
 		Email:     email,
 		CreatedAt: now,
 		UpdatedAt: now,
@@ -32,15 +30,21 @@ func NewUser(googleID, email string) *User {
 
 // Validate validates the user
 func (u *User) Validate() error {
-	if u.GoogleID == "" {
-		return NewValidationError("google_id is required")
-	}
 	if u.Email == "" {
 		return NewValidationError("email is required")
 	}
 	return nil
 }
 
+// GoogleTokens holds a user's Google OAuth tokens, encrypted with the token encryption key.
+type GoogleTokens struct {
+	UserID                string
+	EncryptedAccessToken  string
+	EncryptedRefreshToken string // Google only returns a refresh token on consent, so it may be empty
+	ExpiresAt             time.Time
+	RefreshFailures       int // Failed background refreshes since the tokens were last stored
+}
+
 // UserQuizAttempt represents a user's attempt at a quiz question.
 type UserQuizAttempt struct {
 	ID                string
@@ -54,6 +58,7 @@ type UserQuizAttempt struct {
 	LLMRelevance      float64
 	LLMAccuracy       float64
 	IsCorrect         bool
+	QuizRevision      int // Revision of the quiz the answer was graded against; 0 when unknown
 	AttemptedAt       time.Time
 	CreatedAt         time.Time
 	UpdatedAt         time.Time
@@ -63,9 +68,26 @@ type UserQuizAttempt struct {
 // UserRepository defines the interface for user data persistence.
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *User) error
-	GetUserByGoogleID(ctx context.Context, googleID string) (*User, error)
+	// GetUserByEmail returns the active user with the email, or nil, nil when there is none.
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	GetUserByID(ctx context.Context, userID string) (*User, error)
 	UpdateUser(ctx context.Context, user *User) error
+	// UpdateGoogleTokens stores the user's Google tokens. An empty EncryptedRefreshToken keeps the stored one.
+	UpdateGoogleTokens(ctx context.Context, tokens *GoogleTokens) error
+	// GetGoogleTokens returns the stored tokens of an active user, or nil, nil when there are none.
+	GetGoogleTokens(ctx context.Context, userID string) (*GoogleTokens, error)
+	// ListExpiringGoogleTokens lists the tokens of active users that have a refresh token and
+	// whose access token expires before the given time, soonest first. Tokens whose refresh failed
+	// are left out until their retry time.
+	ListExpiringGoogleTokens(ctx context.Context, before time.Time, limit int) ([]GoogleTokens, error)
+	// RecordGoogleTokenRefreshFailure counts a failed refresh and puts the next try off until retryAt.
+	// Storing new tokens with UpdateGoogleTokens resets both.
+	RecordGoogleTokenRefreshFailure(ctx context.Context, userID string, retryAt time.Time) error
+	// ClearGoogleTokens removes the stored tokens, e.g. after the user revoked access at Google.
+	ClearGoogleTokens(ctx context.Context, userID string) error
+	// DeleteUser soft-deletes an active user. Personal data and tokens are cleared, and the
+	// email is released for a new sign-up. It returns false when there was no active user.
+	DeleteUser(ctx context.Context, userID string, deletedAt time.Time) (bool, error)
 }
 
 // UserQuizAttemptRepository defines the interface for user quiz attempt data persistence.
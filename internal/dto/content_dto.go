@@ -0,0 +1,215 @@
+package dto
+
+import "time"
+
+// CategoryRequest is the body for creating or updating a category
+// @Description Category fields editable by admins
+type CategoryRequest struct {
+	Name        string `json:"name" example:"Backend"`
+	Description string `json:"description" example:"Server-side development"`
+}
+
+// SubCategoryRequest is the body for creating or updating a subcategory
+// @Description Subcategory fields editable by admins
+type SubCategoryRequest struct {
+	Name        string `json:"name" example:"Databases"`
+	Description string `json:"description" example:"Relational and NoSQL databases"`
+}
+
+// CategoryListResponse lists the active categories
+type CategoryListResponse struct {
+	Categories []CategoryResponse `json:"categories"`
+}
+
+// SubCategoryListResponse lists the active subcategories of a category
+type SubCategoryListResponse struct {
+	SubCategories []SubCategoryResponse `json:"sub_categories"`
+}
+
+// QuizRequest is the body for creating or updating a quiz
+// @Description Quiz fields editable by admins
+type QuizRequest struct {
+	Question      string   `json:"question" example:"What is a database index?"`
+	ModelAnswers  []string `json:"model_answers"`
+	Keywords      []string `json:"keywords"`
+	Difficulty    string   `json:"difficulty" example:"medium"` // easy, medium or hard
+	SubCategoryID string   `json:"sub_category_id"`
+	Revision      int      `json:"revision,omitempty" example:"3"` // Updates only: the revision the edit is based on; a newer revision makes the update fail with 409
+}
+
+// QuizDetailResponse is a quiz with the fields admins manage
+type QuizDetailResponse struct {
+	ID            string    `json:"id"`
+	Question      string    `json:"question"`
+	ModelAnswers  []string  `json:"model_answers"`
+	Keywords      []string  `json:"keywords"`
+	Difficulty    string    `json:"difficulty"`
+	SubCategoryID string    `json:"sub_category_id"`
+	Revision      int       `json:"revision"`
+	Status        string    `json:"status" example:"published"` // draft, in_review, published or retired
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// QuizDetailListResponse lists the active quizzes of a subcategory, newest first
+type QuizDetailListResponse struct {
+	Quizzes []QuizDetailResponse `json:"quizzes"`
+}
+
+// QuizRevisionResponse is a snapshot of a quiz's gradable content
+type QuizRevisionResponse struct {
+	QuizID        string    `json:"quiz_id"`
+	Revision      int       `json:"revision"`
+	Question      string    `json:"question"`
+	ModelAnswers  []string  `json:"model_answers"`
+	Keywords      []string  `json:"keywords"`
+	Difficulty    string    `json:"difficulty"`
+	SubCategoryID string    `json:"sub_category_id"`
+	Author        string    `json:"author,omitempty"` // ID of the admin who made the change; absent for revisions predating the history
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// QuizRevisionListResponse lists the revisions of a quiz, newest first
+type QuizRevisionListResponse struct {
+	Revisions []QuizRevisionResponse `json:"revisions"`
+}
+
+// QuizFieldChangeResponse is a field that differs between two revisions
+type QuizFieldChangeResponse struct {
+	Field string      `json:"field" example:"question"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// QuizRevisionDiffResponse lists the fields changed from one revision of a quiz to another
+type QuizRevisionDiffResponse struct {
+	QuizID       string                    `json:"quiz_id"`
+	FromRevision int                       `json:"from_revision"`
+	ToRevision   int                       `json:"to_revision"`
+	Changes      []QuizFieldChangeResponse `json:"changes"`
+}
+
+// ReviewQueueResponse lists the quizzes waiting for review, those waiting longest first
+type ReviewQueueResponse struct {
+	Quizzes []QuizDetailResponse `json:"quizzes"`
+}
+
+// ReviewDecisionRequest is the body for approving or rejecting a quiz
+type ReviewDecisionRequest struct {
+	Comment string `json:"comment" example:"Model answer checked against the docs"` // Required when rejecting
+}
+
+// ReviewEditRequest is the body for a reviewer's edit of a quiz
+type ReviewEditRequest struct {
+	QuizRequest
+	Comment string `json:"comment" example:"Fixed the model answer"`
+}
+
+// QuizStatusRequest is the body for moving a quiz to another status
+type QuizStatusRequest struct {
+	Status  string `json:"status" example:"retired"` // draft, in_review, published or retired
+	Comment string `json:"comment"`
+}
+
+// QuizStatusChangeResponse is an audited status change of a quiz
+type QuizStatusChangeResponse struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Actor      string    `json:"actor,omitempty"` // ID of the admin who made the change
+	Comment    string    `json:"comment,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// QuizStatusHistoryResponse is the current status of a quiz and how it got there, oldest change first
+type QuizStatusHistoryResponse struct {
+	QuizID  string                     `json:"quiz_id"`
+	Status  string                     `json:"status"`
+	Changes []QuizStatusChangeResponse `json:"changes"`
+}
+
+// ScoreEvaluationItem explains what an answer scoring in a range looks like
+type ScoreEvaluationItem struct {
+	ScoreRange    string   `json:"score_range" yaml:"score_range" example:"0.8-1.0"`
+	SampleAnswers []string `json:"sample_answers" yaml:"sample_answers"`
+	Explanation   string   `json:"explanation" yaml:"explanation"`
+}
+
+// QuizRubricRequest is the body for setting the grading rubric of a quiz
+// @Description Grading rubric of a quiz; every score range needs a matching score evaluation
+type QuizRubricRequest struct {
+	MinimumKeywords  int                   `json:"minimum_keywords" yaml:"minimum_keywords"`
+	RequiredTopics   []string              `json:"required_topics" yaml:"required_topics"`
+	ScoreRanges      []string              `json:"score_ranges" yaml:"score_ranges"`
+	SampleAnswers    []string              `json:"sample_answers" yaml:"sample_answers"`
+	RubricDetails    string                `json:"rubric_details" yaml:"rubric_details"`
+	ScoreEvaluations []ScoreEvaluationItem `json:"score_evaluations" yaml:"score_evaluations"`
+}
+
+// QuizRubricResponse is the grading rubric of a quiz
+type QuizRubricResponse struct {
+	QuizID           string                `json:"quiz_id"`
+	MinimumKeywords  int                   `json:"minimum_keywords"`
+	RequiredTopics   []string              `json:"required_topics"`
+	ScoreRanges      []string              `json:"score_ranges"`
+	SampleAnswers    []string              `json:"sample_answers"`
+	RubricDetails    string                `json:"rubric_details"`
+	ScoreEvaluations []ScoreEvaluationItem `json:"score_evaluations"`
+	UpdatedAt        time.Time             `json:"updated_at"`
+}
+
+// ContentBundle is the category → subcategory → quiz → rubric tree that content is imported and exported as.
+// Categories are matched by name, subcategories by name within their category and quizzes by question within
+// their subcategory.
+type ContentBundle struct {
+	Categories []BundleCategory `json:"categories" yaml:"categories"`
+}
+
+// BundleCategory is a category of a ContentBundle
+type BundleCategory struct {
+	Name          string              `json:"name" yaml:"name"`
+	Description   string              `json:"description,omitempty" yaml:"description,omitempty"`
+	SubCategories []BundleSubCategory `json:"sub_categories" yaml:"sub_categories"`
+	Line          int                 `json:"-" yaml:"-"` // CSV line it was read from, for error reports
+}
+
+// BundleSubCategory is a subcategory of a ContentBundle
+type BundleSubCategory struct {
+	Name        string       `json:"name" yaml:"name"`
+	Description string       `json:"description,omitempty" yaml:"description,omitempty"`
+	Quizzes     []BundleQuiz `json:"quizzes" yaml:"quizzes"`
+	Line        int          `json:"-" yaml:"-"`
+}
+
+// BundleQuiz is a quiz of a ContentBundle with its optional grading rubric
+type BundleQuiz struct {
+	Question     string             `json:"question" yaml:"question"`
+	ModelAnswers []string           `json:"model_answers" yaml:"model_answers"`
+	Keywords     []string           `json:"keywords" yaml:"keywords"`
+	Difficulty   string             `json:"difficulty" yaml:"difficulty"`             // easy, medium or hard
+	Status       string             `json:"status,omitempty" yaml:"status,omitempty"` // Used for new quizzes only; defaults to published
+	Evaluation   *QuizRubricRequest `json:"evaluation,omitempty" yaml:"evaluation,omitempty"`
+	Line         int                `json:"-" yaml:"-"`
+}
+
+// ImportCounts counts what an import created, updated and left unchanged
+type ImportCounts struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+}
+
+// ImportRowError is a problem with one row of an import; row is the CSV line or the path in the tree
+type ImportRowError struct {
+	Row     string `json:"row" example:"categories[0].sub_categories[1].quizzes[2]"`
+	Message string `json:"message"`
+}
+
+// ContentImportReport is the outcome of a content import. When it has errors nothing was imported.
+type ContentImportReport struct {
+	DryRun        bool             `json:"dry_run"`
+	Categories    ImportCounts     `json:"categories"`
+	SubCategories ImportCounts     `json:"sub_categories"`
+	Quizzes       ImportCounts     `json:"quizzes"`
+	Evaluations   ImportCounts     `json:"evaluations"`
+	Errors        []ImportRowError `json:"errors,omitempty"`
+}
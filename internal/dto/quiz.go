@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // CategoryResponse represents a category in the API response
 // @Description Category information
 type CategoryResponse struct {
@@ -26,6 +28,23 @@ type QuizResponse struct {
 	DiffLevel    string   `json:"diff_level"`
 }
 
+// RandomQuizRequest represents a request to get a random quiz
+// @Description Request query parameters for getting a random quiz
+type RandomQuizRequest struct {
+	SubCategory string   `query:"sub_category" validate:"required"` // Sub-category ID or name
+	Difficulty  string   `query:"difficulty"`                       // Optional difficulty (easy, medium, hard)
+	Keywords    []string `query:"keywords"`                         // Optional comma-separated keywords, any of which must match
+	ExcludeIDs  []string `query:"exclude_ids"`                      // Optional comma-separated quiz IDs to skip
+}
+
+// NextQuizRequest represents a request to get a follow-up quiz
+// @Description Request parameters for getting the next quiz after the current one
+type NextQuizRequest struct {
+	CurrentQuizID string   `params:"id"`   // Quiz the learner just answered
+	LastScore     *float64 `query:"score"` // Optional score (0.0 ~ 1.0) of the current quiz, used to shift difficulty
+	UserID        string   `json:"-"`      // Set for authenticated users to skip already attempted quizzes
+}
+
 // CheckAnswerRequest represents a request to check a quiz answer
 // @Description Request body for checking a quiz answer
 type CheckAnswerRequest struct {
@@ -35,13 +54,78 @@ type CheckAnswerRequest struct {
 
 // CheckAnswerResponse represents the evaluation result in the API response
 type CheckAnswerResponse struct {
-	Score          float64  `json:"score"`                  // Overall score (0.0 ~ 1.0)
-	Explanation    string   `json:"explanation"`            // Feedback generated by LLM
-	KeywordMatches []string `json:"keyword_matches"`        // Matched keywords
-	Completeness   float64  `json:"completeness"`           // Answer completeness (0.0 ~ 1.0)
-	Relevance      float64  `json:"relevance"`              // Answer relevance (0.0 ~ 1.0)
-	Accuracy       float64  `json:"accuracy"`               // Answer accuracy (0.0 ~ 1.0)
-	ModelAnswer    string   `json:"model_answer,omitempty"` // Model answer (optional)
+	Score           float64  `json:"score"`                      // Overall score (0.0 ~ 1.0)
+	Explanation     string   `json:"explanation"`                // Feedback generated by LLM
+	KeywordMatches  []string `json:"keyword_matches"`            // Matched keywords
+	Completeness    float64  `json:"completeness"`               // Answer completeness (0.0 ~ 1.0)
+	Relevance       float64  `json:"relevance"`                  // Answer relevance (0.0 ~ 1.0)
+	Accuracy        float64  `json:"accuracy"`                   // Answer accuracy (0.0 ~ 1.0)
+	ModelAnswer     string   `json:"model_answer,omitempty"`     // Model answer (optional)
+	RubricSatisfied []string `json:"rubric_satisfied,omitempty"` // Rubric items the answer covers (quizzes with a rubric only)
+	RubricMissed    []string `json:"rubric_missed,omitempty"`    // Rubric items the answer misses (quizzes with a rubric only)
+	Confidence      string   `json:"confidence,omitempty"`       // "high" or "low" when several judges graded the answer
+	JudgeCount      int      `json:"judge_count"`                // Number of judges whose scores were combined
+	ScoredBy        string   `json:"scored_by"`                  // "llm", "pre_score" (obvious case decided locally) or "fallback" (LLM unavailable)
+	QuizRevision    int      `json:"quiz_revision,omitempty"`    // Revision of the quiz the answer was graded against
+	ResultToken     string   `json:"result_token,omitempty"`     // Anonymous callers only: claim the result after login with POST /api/users/me/attempts/claim
+}
+
+// Server-Sent Event names of POST /api/quiz/check/stream, in the order they are sent.
+const (
+	CheckStreamEventCache           = "cache"            // data: CacheLookupEvent
+	CheckStreamEventExplanation     = "explanation"      // data: ExplanationDeltaEvent, repeated while the LLM writes; not sent when the rubric's explanation replaces the LLM's
+	CheckStreamEventResult          = "result"           // data: CheckAnswerResponse
+	CheckStreamEventAttemptRecorded = "attempt_recorded" // data: AttemptRecordedEvent
+	CheckStreamEventError           = "error"            // data: middleware.ErrorResponse; ends the stream
+)
+
+// CheckAnswerStreamEvent is one event of the answer evaluation stream
+type CheckAnswerStreamEvent struct {
+	Event string      // One of the CheckStreamEvent* names
+	Data  interface{} // JSON-encoded as the event data
+}
+
+// CacheLookupEvent reports whether a similar answer was already graded
+type CacheLookupEvent struct {
+	Hit bool `json:"hit"`
+}
+
+// ExplanationDeltaEvent carries the next piece of the explanation text.
+// The final explanation in the result event may differ when the quiz has a canned explanation for the score.
+type ExplanationDeltaEvent struct {
+	Delta string `json:"delta"`
+}
+
+// AttemptRecordedEvent acknowledges that the graded attempt was stored for the user
+type AttemptRecordedEvent struct {
+	Recorded    bool   `json:"recorded"`               // false for anonymous users or when storing failed
+	ResultToken string `json:"result_token,omitempty"` // Anonymous users only, see CheckAnswerResponse.ResultToken
+}
+
+// Statuses of an asynchronous grading job.
+const (
+	CheckJobStatusPending = "pending" // Waiting for or being graded by a worker
+	CheckJobStatusDone    = "done"    // Result is set
+	CheckJobStatusFailed  = "failed"  // Error is set
+)
+
+// CheckJobResponse is the state of an answer submitted with POST /api/quiz/check?async=true
+// @Description Asynchronous grading job; poll GET /api/quiz/check/{jobId} until status is "done" or "failed"
+type CheckJobResponse struct {
+	JobID       string               `json:"job_id"`
+	Status      string               `json:"status"` // "pending", "done" or "failed"
+	QuizID      string               `json:"quiz_id"`
+	Result      *CheckAnswerResponse `json:"result,omitempty"` // Set when status is "done"
+	Error       *CheckJobError       `json:"error,omitempty"`  // Set when status is "failed"
+	SubmittedAt time.Time            `json:"submitted_at"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+	ResultToken string               `json:"result_token,omitempty"` // Anonymous jobs only, in the submit response: claims the result once the job is done
+}
+
+// CheckJobError describes why a grading job failed, with the code the synchronous endpoint would have returned
+type CheckJobError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 // QuizEvaluationResponse represents the evaluation criteria in the API response
@@ -6,25 +6,26 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// GoogleUserInfo holds user information obtained from Google.
-type GoogleUserInfo struct {
-	ID            string `json:"id"`
-	Email         string `json:"email"`
-	VerifiedEmail bool   `json:"verified_email"`
-	Name          string `json:"name"`
-	GivenName     string `json:"given_name"`
-	FamilyName    string `json:"family_name"`
-	Picture       string `json:"picture"`
-	Locale        string `json:"locale"`
-}
-
 // AuthClaims defines the custom claims for JWT.
+// RegisteredClaims.ID is the "jti" claim; it is unique per token.
 type AuthClaims struct {
-	UserID    string `json:"user_id"`
-	TokenType string `json:"token_type"` // "access" or "refresh"
+	UserID    string   `json:"user_id"`
+	TokenType string   `json:"token_type"`      // "access" or "refresh"
+	SessionID string   `json:"sid,omitempty"`   // Login session the token was issued for
+	Roles     []string `json:"roles,omitempty"` // Roles of the user when the token was issued, e.g. "admin"
 	jwt.RegisteredClaims
 }
 
+// HasRole reports whether the token carries the role.
+func (c *AuthClaims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 // UserProfileResponse defines the structure for a user's profile information.
 type UserProfileResponse struct {
 	ID                string `json:"id"`
@@ -103,6 +104,32 @@ type UserQuizAttemptsResponse struct {
 	PaginationInfo PaginationInfo        `json:"pagination_info"`
 }
 
+// ClaimAttemptsRequest is the request body for claiming anonymous results into the user's history.
+// @Description Result tokens returned by POST /api/quiz/check to anonymous callers
+type ClaimAttemptsRequest struct {
+	ResultTokens []string `json:"result_tokens"`
+}
+
+// Statuses of a claimed result token.
+const (
+	ClaimStatusClaimed  = "claimed"   // Recorded as a quiz attempt
+	ClaimStatusNotFound = "not_found" // Unknown, expired or already claimed
+	ClaimStatusFailed   = "failed"    // Could not be recorded; the token can be claimed again
+)
+
+// ClaimedAttemptItem is the outcome for one result token.
+type ClaimedAttemptItem struct {
+	ResultToken string `json:"result_token"`
+	Status      string `json:"status"`
+	QuizID      string `json:"quiz_id,omitempty"`
+}
+
+// ClaimAttemptsResponse is the response for claiming anonymous results.
+type ClaimAttemptsResponse struct {
+	Claimed int                  `json:"claimed"` // Number of tokens recorded as attempts
+	Results []ClaimedAttemptItem `json:"results"`
+}
+
 // --- User Incorrect Answers DTOs ---
 
 // UserIncorrectAnswerItem represents a single incorrect answer for the user.
@@ -148,3 +175,101 @@ type AuthenticatedUser struct {
 	Name              string `json:"name,omitempty"`
 	ProfilePictureURL string `json:"profile_picture_url,omitempty"`
 }
+
+// --- Session DTOs ---
+
+// SessionClientInfo describes the client a login session is started from.
+type SessionClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionItem represents one signed-in device of the user.
+type SessionItem struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"` // e.g. "Chrome on macOS"
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"` // The session of the access token that made the request
+}
+
+// SessionListResponse is the response for listing the user's active sessions.
+type SessionListResponse struct {
+	Sessions []SessionItem `json:"sessions"`
+}
+
+// RevokeSessionsResponse is the response for signing out of all other sessions.
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"` // Number of sessions signed out
+}
+
+// --- Identity DTOs ---
+
+// OAuthFlow holds the secrets of a login in progress. They are kept in short-lived cookies
+// between the redirect to the identity provider and its callback.
+type OAuthFlow struct {
+	State        string // Echoed by the provider in the callback; guards against CSRF
+	Nonce        string // Must come back in the id_token
+	CodeVerifier string // PKCE verifier; the provider only saw its S256 challenge
+}
+
+// IdentityProvidersResponse lists the identity providers users can sign in with.
+type IdentityProvidersResponse struct {
+	Providers []string `json:"providers"` // Names usable in /auth/{provider}/login
+}
+
+// IdentityItem is an identity provider account linked to the user.
+type IdentityItem struct {
+	Provider    string    `json:"provider"`
+	Email       string    `json:"email,omitempty"` // Email the provider reported at the last sign-in
+	LinkedAt    time.Time `json:"linked_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+// IdentityListResponse is the response for listing the user's linked identities.
+type IdentityListResponse struct {
+	Identities []IdentityItem `json:"identities"`
+}
+
+// --- JWKS DTOs ---
+
+// JWK is a public JSON Web Key (RFC 7517) that access tokens can be verified with.
+type JWK struct {
+	KeyType   string `json:"kty"` // "OKP" for Ed25519, "RSA"
+	Use       string `json:"use"` // Always "sig"
+	Algorithm string `json:"alg"` // "EdDSA" or "RS256"
+	KeyID     string `json:"kid"`
+	Curve     string `json:"crv,omitempty"` // OKP keys
+	X         string `json:"x,omitempty"`   // OKP keys
+	N         string `json:"n,omitempty"`   // RSA keys
+	E         string `json:"e,omitempty"`   // RSA keys
+}
+
+// JWKSet is the response of GET /.well-known/jwks.json.
+// @Description Public keys for verifying tokens issued by this service
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// RoleMemberItem is a user a role was granted to.
+type RoleMemberItem struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	GrantedBy string    `json:"granted_by,omitempty"` // ID of the granting admin, or "cli:<operator>"
+	GrantedAt time.Time `json:"granted_at"`
+}
+
+// RoleMemberListResponse is the response for listing the users with a role.
+type RoleMemberListResponse struct {
+	Role    string           `json:"role"`
+	Members []RoleMemberItem `json:"members"`
+}
+
+// RoleMemberRequest names the user to grant a role to or take it from, by either email or user ID.
+// Users who signed in without a verified email have no email and can only be named by ID.
+type RoleMemberRequest struct {
+	Email  string `json:"email,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+}
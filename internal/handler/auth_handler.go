@@ -1,9 +1,9 @@
 package handler
 
 import (
-	"crypto/rand"
-	"encoding/base64"
 	"errors"
+	"net/url"
+	"quiz-byte/internal/config"
 	"quiz-byte/internal/dto"        // Ensure dto is imported if AuthenticatedUser is used explicitly in handler (it is for logging)
 	"quiz-byte/internal/logger"     // Added
 	"quiz-byte/internal/middleware" // For middleware.ErrorResponse
@@ -15,157 +15,237 @@ import (
 )
 
 const (
-	oauthStateCookieName = "oauthstate"
-	// Note: Frontend URL should come from config for redirects
+	oauthStateCookieName    = "oauthstate"
+	oauthNonceCookieName    = "oauthnonce"
+	oauthVerifierCookieName = "oauthpkce"
+	oauthModeCookieName     = "oauthmode" // Set when a login asks for bearer tokens in cookie mode
+	oauthFlowCookieTTL      = 10 * time.Minute
+	// authErrorParam carries the error code when a failed login redirects to the frontend.
+	authErrorParam = "auth_error"
 )
 
 type AuthHandler struct {
 	authService service.AuthService
-	// appConfig   *config.Config // Removed
+	authMode    string // config.AuthModeBearer or config.AuthModeCookie
+	cookies     *middleware.AuthCookies
+	frontendURL string // Where the callback redirects to in cookie mode
 }
 
-func NewAuthHandler(authService service.AuthService /*, appConfig *config.Config */) *AuthHandler {
+func NewAuthHandler(authService service.AuthService, authCfg config.AuthConfig) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
-		// appConfig:   appConfig, // Removed
+		authMode:    authCfg.Mode,
+		cookies:     middleware.NewAuthCookies(authCfg),
+		frontendURL: authCfg.Cookie.FrontendURL,
 	}
 }
 
-// GoogleLogin initiates the Google OAuth2 login flow.
-// @Summary Initiate Google Login
-// @Description Redirects the user to Google's OAuth2 consent page.
+// cookieMode reports whether tokens are handed to browsers in cookies.
+func (h *AuthHandler) cookieMode() bool {
+	return h.authMode == config.AuthModeCookie
+}
+
+// ListIdentityProviders lists the identity providers users can sign in with.
+// @Summary List Identity Providers
+// @Description Names of the configured identity providers, usable as {provider} in the login routes.
 // @Tags auth
-// @Success 302 {string} string "Redirects to Google"
-// @Router /auth/google/login [get]
-func (h *AuthHandler) GoogleLogin(c *fiber.Ctx) error {
+// @Produce json
+// @Success 200 {object} dto.IdentityProvidersResponse
+// @Router /auth/providers [get]
+func (h *AuthHandler) ListIdentityProviders(c *fiber.Ctx) error {
+	return c.JSON(dto.IdentityProvidersResponse{Providers: h.authService.IdentityProviders()})
+}
+
+// unknownProviderResponse writes the 404 response for a provider that is not configured.
+func unknownProviderResponse(c *fiber.Ctx, provider string) error {
+	return c.Status(fiber.StatusNotFound).JSON(middleware.ErrorResponse{
+		Code: "UNKNOWN_IDENTITY_PROVIDER", Message: "Unknown identity provider: " + provider, Status: fiber.StatusNotFound,
+	})
+}
+
+// Login initiates the OAuth2 login flow of an identity provider.
+// @Summary Initiate Login
+// @Description Redirects the user to the consent page of the identity provider (google, github or a configured OIDC provider).
+// @Description The state, OIDC nonce and PKCE code verifier of the login are kept in HttpOnly cookies until the callback.
+// @Description In cookie auth mode, mode=bearer makes the callback return the tokens as JSON, e.g. for the Android app.
+// @Tags auth
+// @Param provider path string true "Identity provider name"
+// @Param mode query string false "Token delivery of the callback in cookie auth mode" Enums(bearer)
+// @Success 302 {string} string "Redirects to the identity provider"
+// @Failure 404 {object} middleware.ErrorResponse "Unknown identity provider"
+// @Failure 502 {object} middleware.ErrorResponse "Identity provider unavailable"
+// @Router /auth/{provider}/login [get]
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	appLogger := logger.Get()
-	b := make([]byte, 32)
-	_, err := rand.Read(b)
+	provider := c.Params("provider")
+
+	loginURL, flow, err := h.authService.LoginURL(c.Context(), provider)
 	if err != nil {
-		appLogger.Error("Failed to generate random state for OAuth", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(middleware.ErrorResponse{
-			Code: "OAUTH_STATE_GENERATION_ERROR", Message: "Could not generate state for OAuth flow", Status: fiber.StatusInternalServerError,
+		if errors.Is(err, service.ErrUnknownIdentityProvider) {
+			return unknownProviderResponse(c, provider)
+		}
+		appLogger.Error("Failed to build identity provider login URL", zap.String("provider", provider), zap.Error(err))
+		return c.Status(fiber.StatusBadGateway).JSON(middleware.ErrorResponse{
+			Code: "IDENTITY_PROVIDER_UNAVAILABLE", Message: "Identity provider is unavailable", Status: fiber.StatusBadGateway,
 		})
 	}
-	state := base64.URLEncoding.EncodeToString(b)
-	appLogger.Info("Google login process initiated", zap.String("state", state))
-
-	c.Cookie(&fiber.Cookie{
-		Name:     oauthStateCookieName,
-		Value:    state,
-		Expires:  time.Now().Add(10 * time.Minute),
-		HTTPOnly: true,
-		Secure:   c.Secure(),
-		SameSite: "Lax",
-		Path:     "/",
-	})
+	appLogger.Info("OAuth login process initiated", zap.String("provider", provider), zap.String("state", flow.State))
 
-	loginURL := h.authService.GetGoogleLoginURL(state)
+	expires := time.Now().Add(oauthFlowCookieTTL)
+	setOAuthFlowCookies(c, *flow, expires)
+	if h.cookieMode() && c.Query("mode") == config.AuthModeBearer {
+		c.Cookie(&fiber.Cookie{Name: oauthModeCookieName, Value: config.AuthModeBearer, Expires: expires, HTTPOnly: true, Secure: c.Secure(), SameSite: "Lax", Path: "/"})
+	}
 	return c.Redirect(loginURL, fiber.StatusTemporaryRedirect)
 }
 
-// GoogleCallback handles the callback from Google OAuth2.
-// @Summary Google OAuth2 Callback
-// @Description Handles user authentication after Google login, issues JWTs.
+// setOAuthFlowCookies keeps the secrets of a login in progress until the callback. Pass an
+// expiry in the past to clear them.
+func setOAuthFlowCookies(c *fiber.Ctx, flow dto.OAuthFlow, expires time.Time) {
+	for name, value := range map[string]string{
+		oauthStateCookieName:    flow.State,
+		oauthNonceCookieName:    flow.Nonce,
+		oauthVerifierCookieName: flow.CodeVerifier,
+	} {
+		c.Cookie(&fiber.Cookie{
+			Name:     name,
+			Value:    value,
+			Expires:  expires,
+			HTTPOnly: true,
+			Secure:   c.Secure(),
+			SameSite: "Lax",
+			Path:     "/",
+		})
+	}
+}
+
+// Callback handles the OAuth2 callback of an identity provider.
+// @Summary OAuth2 Callback
+// @Description Handles user authentication after the identity provider login, issues JWTs.
+// @Description A new account is linked to an existing user only if the provider verified the user's email.
+// @Description The code is redeemed with the PKCE verifier of the login, and OpenID Connect id_tokens must carry its nonce.
+// @Description In cookie auth mode the tokens are set in HttpOnly cookies, with a csrf_token cookie, and the browser is redirected to the frontend; failures redirect there with the error code in the auth_error query parameter.
 // @Tags auth
-// @Param code query string true "Authorization code from Google"
+// @Param provider path string true "Identity provider name"
+// @Param code query string true "Authorization code from the identity provider"
 // @Param state query string true "State string for CSRF protection"
 // @Success 200 {object} map[string]string "Contains access_token and refresh_token"
+// @Success 303 {string} string "Cookie mode: redirects to the frontend"
 // @Failure 400 {object} middleware.ErrorResponse "Invalid state or code"
+// @Failure 404 {object} middleware.ErrorResponse "Unknown identity provider"
+// @Failure 409 {object} middleware.ErrorResponse "Email belongs to another user"
 // @Failure 500 {object} middleware.ErrorResponse "Internal server error"
-// @Router /auth/google/callback [get]
-func (h *AuthHandler) GoogleCallback(c *fiber.Ctx) error {
+// @Router /auth/{provider}/callback [get]
+func (h *AuthHandler) Callback(c *fiber.Ctx) error {
 	appLogger := logger.Get()
+	provider := c.Params("provider")
 	code := c.Query("code")
 	receivedState := c.Query("state")
-	expectedState := c.Cookies(oauthStateCookieName)
-
-	c.Cookie(&fiber.Cookie{
-		Name:     oauthStateCookieName,
-		Value:    "",
-		Expires:  time.Now().Add(-time.Hour),
-		HTTPOnly: true,
-		Secure:   c.Secure(),
-		SameSite: "Lax",
-		Path:     "/",
-	})
+	flow := dto.OAuthFlow{
+		State:        c.Cookies(oauthStateCookieName),
+		Nonce:        c.Cookies(oauthNonceCookieName),
+		CodeVerifier: c.Cookies(oauthVerifierCookieName),
+	}
+	expectedState := flow.State
+	useCookies := h.cookieMode() && c.Cookies(oauthModeCookieName) != config.AuthModeBearer
+
+	setOAuthFlowCookies(c, dto.OAuthFlow{}, time.Now().Add(-time.Hour))
+	if c.Cookies(oauthModeCookieName) != "" {
+		c.Cookie(&fiber.Cookie{Name: oauthModeCookieName, Value: "", Expires: time.Now().Add(-time.Hour), HTTPOnly: true, Secure: c.Secure(), SameSite: "Lax", Path: "/"})
+	}
 
 	if code == "" {
-		appLogger.Warn("Authorization code missing in Google OAuth callback")
-		return c.Status(fiber.StatusBadRequest).JSON(middleware.ErrorResponse{
-			Code: "MISSING_CODE", Message: "Authorization code is missing", Status: fiber.StatusBadRequest,
-		})
+		appLogger.Warn("Authorization code missing in OAuth callback", zap.String("provider", provider))
+		return h.callbackError(c, useCookies, fiber.StatusBadRequest, "MISSING_CODE", "Authorization code is missing")
 	}
 	if receivedState == "" || expectedState == "" || receivedState != expectedState {
 		appLogger.Warn("OAuth state mismatch", zap.String("received", receivedState), zap.String("expected", expectedState))
-		return c.Status(fiber.StatusBadRequest).JSON(middleware.ErrorResponse{
-			Code: "INVALID_STATE", Message: "OAuth state mismatch or missing", Status: fiber.StatusBadRequest,
-		})
+		return h.callbackError(c, useCookies, fiber.StatusBadRequest, "INVALID_STATE", "OAuth state mismatch or missing")
 	}
 
-	accessToken, refreshToken, authUser, err := h.authService.HandleGoogleCallback(c.Context(), code, receivedState, expectedState) // authUser is now *dto.AuthenticatedUser
+	client := dto.SessionClientInfo{UserAgent: c.Get(fiber.HeaderUserAgent), IPAddress: c.IP()}
+	accessToken, refreshToken, authUser, err := h.authService.HandleOAuthCallback(c.Context(), provider, code, receivedState, flow, client)
 	if err != nil {
-		appLogger.Error("Failed to handle Google callback in authService",
+		appLogger.Error("Failed to handle OAuth callback in authService",
 			zap.Error(err),
-			zap.String("code", code),
+			zap.String("provider", provider),
 			zap.String("received_state", receivedState))
-		if errors.Is(err, service.ErrInvalidAuthState) || errors.Is(err, service.ErrFailedToExchangeToken) {
-			return c.Status(fiber.StatusBadRequest).JSON(middleware.ErrorResponse{
-				Code: "OAUTH_CALLBACK_ERROR", Message: err.Error(), Status: fiber.StatusBadRequest,
-			})
+		switch {
+		case errors.Is(err, service.ErrUnknownIdentityProvider):
+			return h.callbackError(c, useCookies, fiber.StatusNotFound, "UNKNOWN_IDENTITY_PROVIDER", "Unknown identity provider: "+provider)
+		case errors.Is(err, service.ErrIdentityEmailConflict):
+			return h.callbackError(c, useCookies, fiber.StatusConflict, "IDENTITY_EMAIL_CONFLICT", "The account's email belongs to another user; sign in with the linked provider")
+		case errors.Is(err, service.ErrInvalidAuthState) || errors.Is(err, service.ErrFailedToExchangeToken) || errors.Is(err, service.ErrInvalidIDToken):
+			return h.callbackError(c, useCookies, fiber.StatusBadRequest, "OAUTH_CALLBACK_ERROR", err.Error())
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(middleware.ErrorResponse{
-			Code: "OAUTH_PROCESSING_ERROR", Message: "Error processing Google login", Status: fiber.StatusInternalServerError,
-		})
+		return h.callbackError(c, useCookies, fiber.StatusInternalServerError, "OAUTH_PROCESSING_ERROR", "Error processing login")
 	}
 
 	if authUser != nil { // Check authUser
-		appLogger.Info("Google OAuth callback successful, tokens issued", zap.String("userID", authUser.ID)) // Use authUser.ID
+		appLogger.Info("OAuth callback successful, tokens issued", zap.String("provider", provider), zap.String("userID", authUser.ID), zap.Bool("cookies", useCookies))
 	} else {
-		appLogger.Error("AuthenticatedUser object is nil after successful Google OAuth callback")
-	}
-
-	// Here, instead of returning tokens in JSON, you might set them in HttpOnly cookies
-	// and redirect to the frontend application.
-	// Example:
-	// frontendURL := h.appConfig.Server.FrontendURL // Assuming you have this in your config
-	// if frontendURL == "" { frontendURL = "/" } // Default redirect
-	//
-	// c.Cookie(&fiber.Cookie{Name: "access_token", Value: accessToken, Path: "/", HttpOnly: true, Secure: c.Secure(), SameSite: "Lax", Expires: time.Now().Add(h.authService.GetAccessTokenTTL())})
-	// c.Cookie(&fiber.Cookie{Name: "refresh_token", Value: refreshToken, Path: "/", HttpOnly: true, Secure: c.Secure(), SameSite: "Lax", Expires: time.Now().Add(h.authService.GetRefreshTokenTTL())})
-	// return c.Redirect(frontendURL, fiber.StatusTemporaryRedirect)
-	//
-	// For now, returning as JSON as per original plan for API-style interaction.
+		appLogger.Error("AuthenticatedUser object is nil after successful OAuth callback", zap.String("provider", provider))
+	}
+
+	if useCookies {
+		h.cookies.Set(c, accessToken, refreshToken)
+		return c.Redirect(h.frontendURL, fiber.StatusSeeOther)
+	}
 	return c.Status(fiber.StatusOK).JSON(dto.TokenResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	})
 }
 
+// callbackError reports a failed login: as a JSON error, or by redirecting the browser to the
+// frontend with the error code when the tokens would have been set in cookies.
+func (h *AuthHandler) callbackError(c *fiber.Ctx, useCookies bool, status int, code string, message string) error {
+	if !useCookies {
+		return c.Status(status).JSON(middleware.ErrorResponse{Code: code, Message: message, Status: status})
+	}
+	redirectURL, err := url.Parse(h.frontendURL)
+	if err != nil {
+		return c.Status(status).JSON(middleware.ErrorResponse{Code: code, Message: message, Status: status})
+	}
+	query := redirectURL.Query()
+	query.Set(authErrorParam, code)
+	redirectURL.RawQuery = query.Encode()
+	return c.Redirect(redirectURL.String(), fiber.StatusSeeOther)
+}
+
 // RefreshToken generates new access and refresh tokens using a valid refresh token.
 // @Summary Refresh JWT tokens
-// @Description Provides a new access token and potentially a new refresh token if the provided refresh token is valid.
+// @Description Rotates the refresh token: returns a new access token and a new refresh token, and the provided one can no longer be used.
+// @Description Presenting an already rotated refresh token revokes the whole session.
+// @Description In cookie auth mode a request without a body uses the refresh_token cookie instead; it needs the X-CSRF-Token header, and the new tokens are set in cookies.
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param body body map[string]string true "JSON object with 'refresh_token'"
-// @Success 200 {object} map[string]string "Contains 'access_token' and 'refresh_token'"
+// @Param body body map[string]string false "JSON object with 'refresh_token'"
+// @Success 200 {object} map[string]string "Contains 'access_token' and 'refresh_token', or a message in cookie mode"
 // @Failure 400 {object} middleware.ErrorResponse "Refresh token missing or invalid format"
 // @Failure 401 {object} middleware.ErrorResponse "Refresh token invalid or expired"
+// @Failure 403 {object} middleware.ErrorResponse "Missing or invalid CSRF token"
 // @Router /auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	appLogger := logger.Get()
 	var req dto.RefreshTokenRequest // Changed to dto.RefreshTokenRequest
-	if err := c.BodyParser(&req); err != nil {
+	fromCookie := h.cookieMode() && len(c.Body()) == 0 && c.Cookies(middleware.RefreshTokenCookie) != ""
+	if fromCookie {
+		if !middleware.ValidCSRF(c) {
+			appLogger.Warn("CSRF token missing or invalid for cookie token refresh")
+			return c.Status(fiber.StatusForbidden).JSON(middleware.ErrorResponse{
+				Code: "INVALID_CSRF_TOKEN", Message: "CSRF token is missing or does not match; send the csrf_token cookie value in the " + middleware.CSRFHeader + " header", Status: fiber.StatusForbidden,
+			})
+		}
+		req.RefreshToken = c.Cookies(middleware.RefreshTokenCookie)
+	} else if err := c.BodyParser(&req); err != nil {
 		appLogger.Warn("Failed to parse request body for token refresh", zap.Error(err))
 		return c.Status(fiber.StatusBadRequest).JSON(middleware.ErrorResponse{
 			Code: "INVALID_REQUEST_BODY", Message: "Invalid request body", Status: fiber.StatusBadRequest,
 		})
 	}
 
-	// TODO: Add validation for req.RefreshToken if not handled by a global validator
-	// Example: if req.RefreshToken == "" { ... }
-
 	if req.RefreshToken == "" { // Basic validation
 		appLogger.Warn("Refresh token missing in request body")
 		return c.Status(fiber.StatusBadRequest).JSON(middleware.ErrorResponse{
@@ -175,16 +255,32 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 
 	newAccessToken, newRefreshToken, err := h.authService.RefreshToken(c.Context(), req.RefreshToken) // Use req.RefreshToken
 	if err != nil {
-		appLogger.Warn("AuthService failed to refresh token", zap.Error(err))
+		appLogger.Warn("AuthService failed to refresh token", zap.Error(err), zap.Bool("fromCookie", fromCookie))
+		if fromCookie {
+			h.cookies.Clear(c) // The browser has to sign in again
+		}
+		if errors.Is(err, service.ErrRefreshTokenReused) {
+			return c.Status(fiber.StatusUnauthorized).JSON(middleware.ErrorResponse{
+				Code: "REFRESH_TOKEN_REUSED", Message: "Refresh token was already used; please log in again", Status: fiber.StatusUnauthorized,
+			})
+		}
+		if errors.Is(err, service.ErrSessionRevoked) {
+			return c.Status(fiber.StatusUnauthorized).JSON(middleware.ErrorResponse{
+				Code: "SESSION_REVOKED", Message: "Session has been revoked; please log in again", Status: fiber.StatusUnauthorized,
+			})
+		}
 		return c.Status(fiber.StatusUnauthorized).JSON(middleware.ErrorResponse{
 			Code: "INVALID_REFRESH_TOKEN", Message: "Failed to refresh token: " + err.Error(), Status: fiber.StatusUnauthorized,
 		})
 	}
 
-	// Log successful refresh. If UserID is needed, it has to be extracted from newAccessToken if not returned by RefreshToken.
-	// For simplicity, the service layer already logs this with UserID. Handler log can be simpler.
-	appLogger.Info("Tokens refreshed successfully via /auth/refresh endpoint")
+	// The service layer already logs this with UserID.
+	appLogger.Info("Tokens refreshed successfully via /auth/refresh endpoint", zap.Bool("fromCookie", fromCookie))
 
+	if fromCookie {
+		h.cookies.Set(c, newAccessToken, newRefreshToken)
+		return c.Status(fiber.StatusOK).JSON(dto.MessageResponse{Message: "Tokens refreshed."})
+	}
 	return c.Status(fiber.StatusOK).JSON(dto.TokenResponse{ // Changed to dto.TokenResponse
 		AccessToken:  newAccessToken,
 		RefreshToken: newRefreshToken,
@@ -193,31 +289,172 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 
 // Logout handles user logout.
 // @Summary Logout user
-// @Description Invalidates user's session/tokens (primarily client-side for JWTs unless server-side blacklisting is used).
+// @Description Revokes the session of the access token: its refresh token and access tokens are no longer accepted.
+// @Description In cookie auth mode the token cookies are cleared.
 // @Tags auth
 // @Security ApiKeyAuth
 // @Success 200 {object} map[string]string "Logout success message"
+// @Failure 401 {object} middleware.ErrorResponse "Missing or invalid access token"
 // @Router /auth/logout [post]
-// Note: ApiKeyAuth for logout implies the access token is sent for logging/blacklisting, which is fine.
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	appLogger := logger.Get()
-	userIDFromContext, ok := c.Locals(middleware.UserIDKey).(string)
-	if ok && userIDFromContext != "" {
-		appLogger.Info("User logout request", zap.String("userID", userIDFromContext))
-	} else {
-		appLogger.Info("Logout request received (user not identified from context)")
-	}
-
-	// For JWT, logout is primarily client-side (client discards tokens).
-	// If refresh tokens are stored in HttpOnly cookies, clear them:
-	// c.Cookie(&fiber.Cookie{
-	// Name: "refresh_token", // Or whatever name you use
-	// Value: "",
-	// Expires: time.Now().Add(-time.Hour), // Expire the cookie
-	// HTTPOnly: true,
-	// Secure: c.Secure(), // Match Secure attribute from when it was set
-	// SameSite: "Lax", // Match SameSite attribute
-	// Path: "/", // Match Path attribute
-	// })
-	return c.Status(fiber.StatusOK).JSON(dto.MessageResponse{Message: "Logout successful. Please discard your tokens."}) // Changed to dto.MessageResponse
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+	appLogger.Info("User logout request", zap.String("userID", claims.UserID), zap.String("sessionID", claims.SessionID))
+
+	if err := h.authService.Logout(c.Context(), claims); err != nil {
+		return err // Handled by the global error handler
+	}
+
+	if h.cookieMode() {
+		h.cookies.Clear(c)
+	}
+	return c.Status(fiber.StatusOK).JSON(dto.MessageResponse{Message: "Logout successful."}) // Changed to dto.MessageResponse
+}
+
+// authClaims returns the claims stored by middleware.Protected, or writes a 401 response.
+func authClaims(c *fiber.Ctx) (*dto.AuthClaims, bool) {
+	claims, ok := c.Locals(middleware.AuthClaimsKey).(*dto.AuthClaims)
+	if !ok || claims == nil || claims.UserID == "" {
+		logger.Get().Warn("Auth claims not found in context", zap.String("path", c.Path()))
+		_ = c.Status(fiber.StatusUnauthorized).JSON(middleware.ErrorResponse{
+			Code: "INVALID_USER_CONTEXT", Message: "User not authenticated", Status: fiber.StatusUnauthorized,
+		})
+		return nil, false
+	}
+	return claims, true
+}
+
+// ListMySessions lists the devices the current user is signed in on.
+// @Summary List My Sessions
+// @Description Lists the active login sessions of the logged-in user, most recently used first. The session of the calling token is marked as current.
+// @Tags users
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.SessionListResponse
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 500 {object} middleware.ErrorResponse "Internal server error"
+// @Router /users/me/sessions [get]
+func (h *AuthHandler) ListMySessions(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+
+	resp, err := h.authService.ListSessions(c.Context(), claims.UserID, claims.SessionID)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// ListMyIdentities lists the identity provider accounts the current user signs in with.
+// @Summary List My Identities
+// @Description Lists the identity provider accounts (Google, GitHub, OIDC) linked to the logged-in user.
+// @Tags users
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.IdentityListResponse
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 500 {object} middleware.ErrorResponse "Internal server error"
+// @Router /users/me/identities [get]
+func (h *AuthHandler) ListMyIdentities(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+
+	resp, err := h.authService.ListIdentities(c.Context(), claims.UserID)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// RevokeMySession signs one of the current user's sessions out.
+// @Summary Revoke A Session
+// @Description Signs out the given session of the logged-in user; its tokens are rejected from then on. The current session can be revoked too.
+// @Tags users
+// @Security ApiKeyAuth
+// @Param id path string true "Session ID"
+// @Success 204 "Session revoked"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 404 {object} middleware.ErrorResponse "Session not found or already revoked"
+// @Failure 500 {object} middleware.ErrorResponse "Internal server error"
+// @Router /users/me/sessions/{id} [delete]
+func (h *AuthHandler) RevokeMySession(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+
+	sessionID := c.Params("id")
+	if err := h.authService.RevokeSession(c.Context(), claims.UserID, sessionID); err != nil {
+		return err // Handled by the global error handler
+	}
+	logger.Get().Info("Session revoked by user", zap.String("userID", claims.UserID), zap.String("sessionID", sessionID))
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DeleteMyAccount deletes the current user's account.
+// @Summary Delete My Account
+// @Description Deletes the logged-in user's account, signs out all of their sessions and removes their linked identities and revokes the app's access to their Google account.
+// @Tags users
+// @Security ApiKeyAuth
+// @Success 204 "Account deleted"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 404 {object} middleware.ErrorResponse "User not found"
+// @Failure 500 {object} middleware.ErrorResponse "Internal server error"
+// @Router /users/me [delete]
+func (h *AuthHandler) DeleteMyAccount(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.authService.DeleteAccount(c.Context(), claims.UserID); err != nil {
+		return err // Handled by the global error handler
+	}
+	if h.cookieMode() {
+		h.cookies.Clear(c)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RevokeMyOtherSessions signs the current user out everywhere else.
+// @Summary Revoke Other Sessions
+// @Description Signs out every session of the logged-in user except the current one.
+// @Tags users
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.RevokeSessionsResponse
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 500 {object} middleware.ErrorResponse "Internal server error"
+// @Router /users/me/sessions [delete]
+func (h *AuthHandler) RevokeMyOtherSessions(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+
+	revoked, err := h.authService.RevokeOtherSessions(c.Context(), claims.UserID, claims.SessionID)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	logger.Get().Info("Other sessions revoked by user", zap.String("userID", claims.UserID), zap.Int("revoked", revoked))
+	return c.JSON(dto.RevokeSessionsResponse{Revoked: revoked})
+}
+
+// JWKS publishes the public keys access tokens are signed with.
+// @Summary JSON Web Key Set
+// @Description Public keys other services can verify access tokens with, selected by the token's kid header. Keys retired by a rotation stay listed until their tokens have expired.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} dto.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.authService.JWKS())
 }
@@ -0,0 +1,569 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/middleware"
+	"quiz-byte/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ContentHandler lets admins manage categories, subcategories, quizzes and their grading rubrics.
+type ContentHandler struct {
+	contentService service.ContentService
+}
+
+func NewContentHandler(contentService service.ContentService) *ContentHandler {
+	return &ContentHandler{contentService: contentService}
+}
+
+// ListCategories lists the categories.
+// @Summary List Categories
+// @Description Lists the active categories. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.CategoryListResponse
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Router /admin/categories [get]
+func (h *ContentHandler) ListCategories(c *fiber.Ctx) error {
+	resp, err := h.contentService.ListCategories(c.Context())
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// CreateCategory creates a category.
+// @Summary Create A Category
+// @Description Creates a category. Names of active categories are unique. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param body body dto.CategoryRequest true "Category"
+// @Success 201 {object} dto.CategoryResponse
+// @Failure 400 {object} middleware.ErrorResponse "Invalid category"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 409 {object} middleware.ErrorResponse "Name taken"
+// @Router /admin/categories [post]
+func (h *ContentHandler) CreateCategory(c *fiber.Ctx) error {
+	var req dto.CategoryRequest
+	if !parseBody(c, &req) {
+		return nil
+	}
+	resp, err := h.contentService.CreateCategory(c.Context(), &req)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// UpdateCategory updates a category.
+// @Summary Update A Category
+// @Description Renames a category or changes its description. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param body body dto.CategoryRequest true "Category"
+// @Success 200 {object} dto.CategoryResponse
+// @Failure 400 {object} middleware.ErrorResponse "Invalid category"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Category not found"
+// @Failure 409 {object} middleware.ErrorResponse "Name taken"
+// @Router /admin/categories/{id} [put]
+func (h *ContentHandler) UpdateCategory(c *fiber.Ctx) error {
+	var req dto.CategoryRequest
+	if !parseBody(c, &req) {
+		return nil
+	}
+	resp, err := h.contentService.UpdateCategory(c.Context(), c.Params("id"), &req)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// DeleteCategory soft deletes a category.
+// @Summary Delete A Category
+// @Description Soft deletes a category without subcategories. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Param id path string true "Category ID"
+// @Success 204 "Category deleted"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Category not found"
+// @Failure 409 {object} middleware.ErrorResponse "Category still has subcategories"
+// @Router /admin/categories/{id} [delete]
+func (h *ContentHandler) DeleteCategory(c *fiber.Ctx) error {
+	if err := h.contentService.DeleteCategory(c.Context(), c.Params("id")); err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListSubCategories lists the subcategories of a category.
+// @Summary List Subcategories
+// @Description Lists the active subcategories of a category. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {object} dto.SubCategoryListResponse
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Category not found"
+// @Router /admin/categories/{id}/subcategories [get]
+func (h *ContentHandler) ListSubCategories(c *fiber.Ctx) error {
+	resp, err := h.contentService.ListSubCategories(c.Context(), c.Params("id"))
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// CreateSubCategory creates a subcategory in a category.
+// @Summary Create A Subcategory
+// @Description Creates a subcategory in the category. Names are unique within a category. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param body body dto.SubCategoryRequest true "Subcategory"
+// @Success 201 {object} dto.SubCategoryResponse
+// @Failure 400 {object} middleware.ErrorResponse "Invalid subcategory"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Category not found"
+// @Failure 409 {object} middleware.ErrorResponse "Name taken"
+// @Router /admin/categories/{id}/subcategories [post]
+func (h *ContentHandler) CreateSubCategory(c *fiber.Ctx) error {
+	var req dto.SubCategoryRequest
+	if !parseBody(c, &req) {
+		return nil
+	}
+	resp, err := h.contentService.CreateSubCategory(c.Context(), c.Params("id"), &req)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// UpdateSubCategory updates a subcategory.
+// @Summary Update A Subcategory
+// @Description Renames a subcategory or changes its description. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Subcategory ID"
+// @Param body body dto.SubCategoryRequest true "Subcategory"
+// @Success 200 {object} dto.SubCategoryResponse
+// @Failure 400 {object} middleware.ErrorResponse "Invalid subcategory"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Subcategory not found"
+// @Failure 409 {object} middleware.ErrorResponse "Name taken"
+// @Router /admin/subcategories/{id} [put]
+func (h *ContentHandler) UpdateSubCategory(c *fiber.Ctx) error {
+	var req dto.SubCategoryRequest
+	if !parseBody(c, &req) {
+		return nil
+	}
+	resp, err := h.contentService.UpdateSubCategory(c.Context(), c.Params("id"), &req)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// DeleteSubCategory soft deletes a subcategory.
+// @Summary Delete A Subcategory
+// @Description Soft deletes a subcategory without quizzes. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Param id path string true "Subcategory ID"
+// @Success 204 "Subcategory deleted"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Subcategory not found"
+// @Failure 409 {object} middleware.ErrorResponse "Subcategory still has quizzes"
+// @Router /admin/subcategories/{id} [delete]
+func (h *ContentHandler) DeleteSubCategory(c *fiber.Ctx) error {
+	if err := h.contentService.DeleteSubCategory(c.Context(), c.Params("id")); err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListQuizzes lists the quizzes of a subcategory.
+// @Summary List Quizzes
+// @Description Lists the active quizzes of a subcategory with their model answers, newest first. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Subcategory ID"
+// @Success 200 {object} dto.QuizDetailListResponse
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Subcategory not found"
+// @Router /admin/subcategories/{id}/quizzes [get]
+func (h *ContentHandler) ListQuizzes(c *fiber.Ctx) error {
+	resp, err := h.contentService.ListQuizzes(c.Context(), c.Params("id"))
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// GetQuiz returns a quiz.
+// @Summary Get A Quiz
+// @Description Returns a quiz with its model answers. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} dto.QuizDetailResponse
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz not found"
+// @Router /admin/quizzes/{id} [get]
+func (h *ContentHandler) GetQuiz(c *fiber.Ctx) error {
+	resp, err := h.contentService.GetQuiz(c.Context(), c.Params("id"))
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// CreateQuiz creates a quiz.
+// @Summary Create A Quiz
+// @Description Creates a quiz in an existing subcategory. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param body body dto.QuizRequest true "Quiz"
+// @Success 201 {object} dto.QuizDetailResponse
+// @Failure 400 {object} middleware.ErrorResponse "Invalid quiz"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Router /admin/quizzes [post]
+func (h *ContentHandler) CreateQuiz(c *fiber.Ctx) error {
+	var req dto.QuizRequest
+	if !parseBody(c, &req) {
+		return nil
+	}
+	resp, err := h.contentService.CreateQuiz(c.Context(), &req)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// UpdateQuiz updates a quiz.
+// @Summary Update A Quiz
+// @Description Replaces the fields of a quiz, saved as a new revision; cached answer evaluations of the quiz are dropped. Pass the revision the edit is based on to reject it if someone changed the quiz meanwhile. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Param body body dto.QuizRequest true "Quiz"
+// @Success 200 {object} dto.QuizDetailResponse
+// @Failure 400 {object} middleware.ErrorResponse "Invalid quiz"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz not found"
+// @Failure 409 {object} middleware.ErrorResponse "Quiz changed since the given revision"
+// @Router /admin/quizzes/{id} [put]
+func (h *ContentHandler) UpdateQuiz(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+	var req dto.QuizRequest
+	if !parseBody(c, &req) {
+		return nil
+	}
+	resp, err := h.contentService.UpdateQuiz(c.Context(), c.Params("id"), &req, claims.UserID)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// DeleteQuiz soft deletes a quiz.
+// @Summary Delete A Quiz
+// @Description Soft deletes a quiz along with its rubric. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Param id path string true "Quiz ID"
+// @Success 204 "Quiz deleted"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz not found"
+// @Router /admin/quizzes/{id} [delete]
+func (h *ContentHandler) DeleteQuiz(c *fiber.Ctx) error {
+	if err := h.contentService.DeleteQuiz(c.Context(), c.Params("id")); err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListQuizRevisions lists the revisions of a quiz.
+// @Summary List Quiz Revisions
+// @Description Lists the snapshots of a quiz saved by each edit, newest first. Attempts record the revision they were graded against. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} dto.QuizRevisionListResponse
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz not found"
+// @Router /admin/quizzes/{id}/revisions [get]
+func (h *ContentHandler) ListQuizRevisions(c *fiber.Ctx) error {
+	resp, err := h.contentService.ListQuizRevisions(c.Context(), c.Params("id"))
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// DiffQuizRevision shows what a revision of a quiz changed.
+// @Summary Diff A Quiz Revision
+// @Description Lists the fields that differ between a revision and the one before it, or the revision given by against. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Param revision path int true "Revision"
+// @Param against query int false "Revision to compare against; defaults to the previous revision"
+// @Success 200 {object} dto.QuizRevisionDiffResponse
+// @Failure 400 {object} middleware.ErrorResponse "Invalid revision"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz or revision not found"
+// @Router /admin/quizzes/{id}/revisions/{revision}/diff [get]
+func (h *ContentHandler) DiffQuizRevision(c *fiber.Ctx) error {
+	revision, err := parseRevision(c.Params("revision"))
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	against := 0
+	if c.Query("against") != "" {
+		if against, err = parseRevision(c.Query("against")); err != nil {
+			return err // Handled by the global error handler
+		}
+	}
+	resp, err := h.contentService.DiffQuizRevision(c.Context(), c.Params("id"), revision, against)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// RollbackQuiz restores a revision of a quiz.
+// @Summary Roll Back A Quiz
+// @Description Restores the content of a revision, saved as a new revision; cached answer evaluations of the quiz are dropped. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Param revision path int true "Revision to restore"
+// @Success 200 {object} dto.QuizDetailResponse
+// @Failure 400 {object} middleware.ErrorResponse "Invalid revision, or the quiz is already at it"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz or revision not found"
+// @Failure 409 {object} middleware.ErrorResponse "Quiz changed during the rollback"
+// @Router /admin/quizzes/{id}/revisions/{revision}/rollback [post]
+func (h *ContentHandler) RollbackQuiz(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+	revision, err := parseRevision(c.Params("revision"))
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	resp, err := h.contentService.RollbackQuiz(c.Context(), c.Params("id"), revision, claims.UserID)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// GetQuizRubric returns the grading rubric of a quiz.
+// @Summary Get A Quiz Rubric
+// @Description Returns the rubric answers to the quiz are graded with. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} dto.QuizRubricResponse
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz or rubric not found"
+// @Router /admin/quizzes/{id}/rubric [get]
+func (h *ContentHandler) GetQuizRubric(c *fiber.Ctx) error {
+	resp, err := h.contentService.GetQuizRubric(c.Context(), c.Params("id"))
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// PutQuizRubric sets the grading rubric of a quiz.
+// @Summary Set A Quiz Rubric
+// @Description Creates or replaces the rubric of a quiz; cached answer evaluations of the quiz are dropped. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Param body body dto.QuizRubricRequest true "Rubric"
+// @Success 200 {object} dto.QuizRubricResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse "Invalid rubric"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz not found"
+// @Router /admin/quizzes/{id}/rubric [put]
+func (h *ContentHandler) PutQuizRubric(c *fiber.Ctx) error {
+	var req dto.QuizRubricRequest
+	if !parseBody(c, &req) {
+		return nil
+	}
+	resp, err := h.contentService.PutQuizRubric(c.Context(), c.Params("id"), &req)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// DeleteQuizRubric soft deletes the grading rubric of a quiz.
+// @Summary Delete A Quiz Rubric
+// @Description Soft deletes the rubric of a quiz. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Param id path string true "Quiz ID"
+// @Success 204 "Rubric deleted"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Rubric not found"
+// @Router /admin/quizzes/{id}/rubric [delete]
+func (h *ContentHandler) DeleteQuizRubric(c *fiber.Ctx) error {
+	if err := h.contentService.DeleteQuizRubric(c.Context(), c.Params("id")); err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ExportContent exports all content.
+// @Summary Export Content
+// @Description Exports every category, subcategory, quiz and rubric as a tree in JSON, or as CSV or YAML. The export can be imported again. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Produce text/csv
+// @Produce application/yaml
+// @Param format query string false "json (default), csv or yaml"
+// @Success 200 {object} dto.ContentBundle
+// @Failure 400 {object} middleware.ErrorResponse "Unknown format"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Router /admin/content/export [get]
+func (h *ContentHandler) ExportContent(c *fiber.Ctx) error {
+	format := c.Query("format", service.ContentFormatJSON)
+	data, err := h.contentService.ExportContent(c.Context(), format)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	c.Set(fiber.HeaderContentType, contentFormatMIMETypes[format])
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="quiz-content.%s"`, format))
+	return c.Send(data)
+}
+
+// ImportContent imports content.
+// @Summary Import Content
+// @Description Upserts categories, subcategories, quizzes and rubrics by name, subcategory name and question. Changed quizzes are saved as revisions. With dry_run, nothing is saved and the report tells what would change. When a row is invalid, nothing is saved and the report lists every invalid row. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Accept text/csv
+// @Accept application/yaml
+// @Produce json
+// @Param format query string false "json, csv or yaml; defaults to the format of the Content-Type, then json"
+// @Param dry_run query bool false "Validate and report without saving"
+// @Param body body dto.ContentBundle true "Content"
+// @Success 200 {object} dto.ContentImportReport
+// @Failure 400 {object} dto.ContentImportReport "Invalid rows; nothing was imported"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 409 {object} middleware.ErrorResponse "A quiz was changed during the import"
+// @Router /admin/content/import [post]
+func (h *ContentHandler) ImportContent(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+	format := c.Query("format", contentFormatOf(c.Get(fiber.HeaderContentType)))
+	report, err := h.contentService.ImportContent(c.Context(), c.Body(), format, c.QueryBool("dry_run"), claims.UserID)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	if len(report.Errors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(report)
+	}
+	return c.JSON(report)
+}
+
+var contentFormatMIMETypes = map[string]string{
+	service.ContentFormatJSON: fiber.MIMEApplicationJSONCharsetUTF8,
+	service.ContentFormatCSV:  "text/csv; charset=utf-8",
+	service.ContentFormatYAML: "application/yaml; charset=utf-8",
+}
+
+// contentFormatOf returns the content format of a Content-Type.
+func contentFormatOf(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "csv"):
+		return service.ContentFormatCSV
+	case strings.Contains(contentType, "yaml"), strings.Contains(contentType, "yml"):
+		return service.ContentFormatYAML
+	}
+	return service.ContentFormatJSON
+}
+
+// parseRevision parses a quiz revision number from the path or query.
+func parseRevision(value string) (int, error) {
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision < 1 {
+		return 0, domain.NewValidationError(fmt.Sprintf("invalid revision %q", value))
+	}
+	return revision, nil
+}
+
+// parseBody parses the JSON body into req. When it cannot, it writes a 400 response and returns false.
+func parseBody(c *fiber.Ctx, req interface{}) bool {
+	if err := c.BodyParser(req); err != nil {
+		_ = c.Status(fiber.StatusBadRequest).JSON(middleware.ErrorResponse{
+			Code: "INVALID_REQUEST_BODY", Message: "Invalid request body", Status: fiber.StatusBadRequest,
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// disconnectPollInterval is how often a request's connection is checked for a client that went away.
+const disconnectPollInterval = 200 * time.Millisecond
+
+// requestContext makes the user context of c cancel when the client closes its connection, which
+// fasthttp does not report while a handler runs. Call stop when the handler is done with the client.
+func requestContext(c *fiber.Ctx) (ctx context.Context, stop func()) {
+	parent := c.UserContext()
+	ctx, cancel := context.WithCancel(parent)
+	c.SetUserContext(ctx)
+
+	done := make(chan struct{})
+	go watchDisconnect(c.Context().Conn(), done, cancel)
+	return ctx, func() {
+		close(done)
+		cancel()
+		c.SetUserContext(parent)
+	}
+}
+
+// watchDisconnect calls cancel once conn is closed by the client, polling until done is closed.
+func watchDisconnect(conn net.Conn, done <-chan struct{}, cancel context.CancelFunc) {
+	ticker := time.NewTicker(disconnectPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if connClosed(conn) {
+				cancel()
+				return
+			}
+		}
+	}
+}
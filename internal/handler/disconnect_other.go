@@ -0,0 +1,10 @@
+//go:build !unix
+
+package handler
+
+import "net"
+
+// connClosed cannot peek at connections on this platform, so disconnects are not detected.
+func connClosed(conn net.Conn) bool {
+	return false
+}
@@ -0,0 +1,35 @@
+//go:build unix
+
+package handler
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// connClosed peeks at conn without consuming anything: a read of zero bytes means the client sent
+// FIN, and a reset means it is gone. Pipelined requests and an idle connection both count as open.
+func connClosed(conn net.Conn) bool {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return false // E.g. TLS or in-memory connections, which cannot be peeked at
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return false
+	}
+	closed := false
+	buf := make([]byte, 1)
+	err = raw.Read(func(fd uintptr) bool {
+		n, _, err := syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		switch {
+		case err == nil:
+			closed = n == 0
+		case errors.Is(err, syscall.ECONNRESET):
+			closed = true
+		}
+		return true // Never wait for the connection to become readable
+	})
+	return closed || err != nil
+}
@@ -0,0 +1,93 @@
+//go:build unix
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/middleware"
+	"quiz-byte/internal/util"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingQuizService grades until its context is canceled, like an LLM call honouring ctx.
+type blockingQuizService struct {
+	*MockQuizService
+	started  chan struct{}
+	canceled chan error
+}
+
+func (s *blockingQuizService) CheckAnswer(ctx context.Context, req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
+	close(s.started)
+	<-ctx.Done()
+	s.canceled <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func TestCheckAnswer_ClientDisconnectCancelsEvaluation(t *testing.T) {
+	svc := &blockingQuizService{MockQuizService: new(MockQuizService), started: make(chan struct{}), canceled: make(chan error, 1)}
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler(), DisableStartupMessage: true})
+	app.Post("/quiz/check", NewQuizHandler(svc, new(MockAttemptRecorder), new(MockAnonymousResultCacheService), nil).CheckAnswer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	defer func() { _ = app.Shutdown() }()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	body := fmt.Sprintf(`{"quiz_id":%q,"user_answer":"4"}`, util.NewULID())
+	_, err = fmt.Fprintf(conn, "POST /quiz/check HTTP/1.1\r\nHost: test\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+	require.NoError(t, err)
+
+	select {
+	case <-svc.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("evaluation did not start")
+	}
+	require.NoError(t, conn.Close()) // The client goes away in the middle of the evaluation
+
+	select {
+	case err := <-svc.canceled:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("evaluation kept running after the client disconnected")
+	}
+}
+
+func TestConnClosed(t *testing.T) {
+	client, server := net.Pipe() // Cannot be peeked at, so it always counts as open
+	defer client.Close()
+	defer server.Close()
+	assert.False(t, connClosed(server))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	dialed, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer dialed.Close()
+	accepted, err := ln.Accept()
+	require.NoError(t, err)
+	defer accepted.Close()
+
+	assert.False(t, connClosed(accepted), "an idle connection is open")
+	_, err = dialed.Write([]byte("GET / HTTP/1.1\r\n"))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return !connClosed(accepted) }, time.Second, 10*time.Millisecond, "pending data is not consumed")
+	buf := make([]byte, 16)
+	n, err := accepted.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "GET / HTTP/1.1\r\n", string(buf[:n]))
+
+	require.NoError(t, dialed.Close())
+	assert.Eventually(t, func() bool { return connClosed(accepted) }, time.Second, 10*time.Millisecond)
+}
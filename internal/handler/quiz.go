@@ -1,15 +1,18 @@
 package handler
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"fmt"
 	"quiz-byte/internal/domain"
 	"quiz-byte/internal/dto"
 	"quiz-byte/internal/logger"
 	"quiz-byte/internal/middleware"
 	"quiz-byte/internal/service"
-	"quiz-byte/internal/util" // Added for NewULID
 	"quiz-byte/internal/validation"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -18,24 +21,30 @@ import (
 
 const DefaultBulkQuizCount = 10
 
+// maxIdempotencyKeyLength bounds the Idempotency-Key header accepted when checking answers.
+const maxIdempotencyKeyLength = 100
+
 // QuizHandler handles quiz-related HTTP requests following Clean Architecture principles
 type QuizHandler struct {
 	quizService                 service.QuizService
-	userService                 service.UserService
+	attempts                    service.AttemptRecorder             // nil disables attempt recording
 	anonymousResultCacheService service.AnonymousResultCacheService // Added
+	checkJobs                   service.CheckJobService             // nil disables ?async=true
 	validator                   *validation.Validator
 }
 
 // NewQuizHandler creates a new QuizHandler instance
 func NewQuizHandler(
 	quizService service.QuizService,
-	userService service.UserService,
+	attempts service.AttemptRecorder,
 	anonymousResultCacheService service.AnonymousResultCacheService, // Added
+	checkJobs service.CheckJobService,
 ) *QuizHandler {
 	return &QuizHandler{
 		quizService:                 quizService,
-		userService:                 userService,
+		attempts:                    attempts,
 		anonymousResultCacheService: anonymousResultCacheService, // Added
+		checkJobs:                   checkJobs,
 		validator:                   validation.NewValidator(),
 	}
 }
@@ -72,12 +81,15 @@ func (h *QuizHandler) GetAllSubCategories(c *fiber.Ctx) error {
 
 // GetRandomQuiz godoc
 // @Summary Get a random quiz
-// @Description Get a random quiz by sub category. Requires authentication.
+// @Description Get a random quiz by sub category (ID or name), optionally filtered by difficulty and keywords.
 // @Tags quiz
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
-// @Param sub_category query string true "Sub Category"
+// @Param sub_category query string true "Sub Category ID or name"
+// @Param difficulty query string false "Difficulty (easy, medium, hard)"
+// @Param keywords query string false "Comma-separated keywords; quiz must match at least one"
+// @Param exclude_ids query string false "Comma-separated quiz IDs to exclude"
 // @Success 200 {object} dto.QuizResponse
 // @Failure 400 {object} middleware.ErrorResponse "Invalid request (e.g., missing sub_category)"
 // @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
@@ -88,31 +100,44 @@ func (h *QuizHandler) GetRandomQuiz(c *fiber.Ctx) error {
 	appLogger := logger.Get()
 	userID, _ := c.Locals(middleware.UserIDKey).(string)
 
-	// Get validated sub_category from middleware or validate here
+	req := &dto.RandomQuizRequest{}
+
+	// Get validated parameters from middleware or validate here
 	subCategory, ok := c.Locals("validated_sub_category").(string)
-	if !ok {
+	if ok {
+		req.SubCategory = subCategory
+		req.Difficulty, _ = c.Locals("validated_difficulty").(string)
+		req.Keywords, _ = c.Locals("validated_keywords").([]string)
+		req.ExcludeIDs, _ = c.Locals("validated_exclude_ids").([]string)
+	} else {
 		// Fallback validation if middleware wasn't used
-		subCategory = c.Params("subCategory")
-		if subCategory == "" {
-			subCategory = c.Query("sub_category")
+		req.SubCategory = c.Params("subCategory")
+		if req.SubCategory == "" {
+			req.SubCategory = c.Query("sub_category")
 		}
+		req.Difficulty = strings.TrimSpace(c.Query("difficulty"))
+		req.Keywords = validation.SplitCommaList(c.Query("keywords"))
+		req.ExcludeIDs = validation.SplitCommaList(c.Query("exclude_ids"))
 
-		if validationErrors := h.validator.ValidateSubCategory(subCategory); len(validationErrors) > 0 {
+		if validationErrors := h.validator.ValidateSubCategory(req.SubCategory); len(validationErrors) > 0 {
+			return validationErrors
+		}
+		if validationErrors := h.validator.ValidateRandomQuizFilters(req.Difficulty, req.Keywords, req.ExcludeIDs); len(validationErrors) > 0 {
 			return validationErrors
 		}
 	}
 
 	if userID != "" {
-		appLogger.Info("Random quiz requested by user", zap.String("userID", userID), zap.String("sub_category", subCategory))
+		appLogger.Info("Random quiz requested by user", zap.String("userID", userID), zap.String("sub_category", req.SubCategory))
 	} else {
-		appLogger.Info("Random quiz requested (unauthenticated)", zap.String("sub_category", subCategory))
+		appLogger.Info("Random quiz requested (unauthenticated)", zap.String("sub_category", req.SubCategory))
 	}
 
-	quiz, err := h.quizService.GetRandomQuiz(subCategory)
+	quiz, err := h.quizService.GetRandomQuiz(req)
 	if err != nil {
 		appLogger.Error("Failed to get random quiz from service",
 			zap.Error(err),
-			zap.String("sub_category", subCategory),
+			zap.String("sub_category", req.SubCategory),
 		)
 		return err // Return error directly, middleware will handle it
 	}
@@ -126,14 +151,66 @@ func (h *QuizHandler) GetRandomQuiz(c *fiber.Ctx) error {
 	})
 }
 
+// GetNextQuiz godoc
+// @Summary Get the next quiz
+// @Description Get a follow-up quiz from the same sub category. A high score on the current quiz moves to a harder quiz, a low score to an easier one. Authenticated users never get quizzes they already attempted.
+// @Tags quiz
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Current Quiz ID"
+// @Param score query number false "Score of the current quiz (0.0 ~ 1.0)"
+// @Success 200 {object} dto.QuizResponse
+// @Failure 400 {object} middleware.ErrorResponse "Invalid request"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz not found or no next quiz available"
+// @Failure 500 {object} middleware.ErrorResponse "Internal server error"
+// @Router /quiz/{id}/next [get]
+func (h *QuizHandler) GetNextQuiz(c *fiber.Ctx) error {
+	appLogger := logger.Get()
+	userID, _ := c.Locals(middleware.UserIDKey).(string)
+
+	req := &dto.NextQuizRequest{
+		CurrentQuizID: c.Params("id"),
+		UserID:        userID,
+	}
+
+	if scoreStr := c.Query("score"); scoreStr != "" {
+		score, err := strconv.ParseFloat(scoreStr, 64)
+		if err != nil {
+			return domain.ValidationErrors{domain.NewInvalidFormatError("score", scoreStr)}
+		}
+		req.LastScore = &score
+	}
+
+	if validationErrors := h.validator.ValidateNextQuizRequest(req.CurrentQuizID, req.LastScore); len(validationErrors) > 0 {
+		return validationErrors
+	}
+
+	quiz, err := h.quizService.GetNextQuiz(req)
+	if err != nil {
+		appLogger.Error("Failed to get next quiz from service",
+			zap.Error(err),
+			zap.String("quiz_id", req.CurrentQuizID),
+			zap.String("userID", userID),
+		)
+		return err
+	}
+
+	return c.JSON(quiz)
+}
+
 // CheckAnswer godoc
 // @Summary Check an answer for a quiz
-// @Description Check an answer for a quiz
+// @Description Check an answer for a quiz. With async=true the answer is graded in the background:
+// @Description the response is 202 with a job to poll at GET /quiz/check/{jobId}.
 // @Tags quiz
 // @Accept json
 // @Produce json
 // @Param answer body dto.CheckAnswerRequest true "Answer Request"
+// @Param async query bool false "Grade in the background and return a job"
+// @Param Idempotency-Key header string false "Retries with the same key record the attempt only once (signed-in users)"
 // @Success 200 {object} domain.Answer
+// @Success 202 {object} dto.CheckJobResponse
 // @Failure 400 {object} dto.ErrorResponse
 // @Failure 404 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
@@ -154,8 +231,28 @@ func (h *QuizHandler) CheckAnswer(c *fiber.Ctx) error {
 		return validationErrors
 	}
 
+	userID, userIsAuthenticated := c.Locals(middleware.UserIDKey).(string)
+	idempotencyKey, err := attemptIdempotencyKey(c, userID)
+	if err != nil {
+		return err
+	}
+
+	if c.QueryBool("async") && h.checkJobs != nil {
+		job, err := h.checkJobs.Submit(c.UserContext(), &req, userID)
+		if err != nil {
+			appLogger.Error("Failed to submit grading job", zap.Error(err), zap.String("quiz_id", req.QuizID))
+			return err
+		}
+		c.Location("/api/quiz/check/" + job.JobID)
+		return c.Status(fiber.StatusAccepted).JSON(job)
+	}
+
+	// Grading stops when the client goes away; the job above outlives the request on purpose.
+	ctx, stop := requestContext(c)
+	defer stop()
+
 	// Call the service to check answer
-	domainResult, err := h.quizService.CheckAnswer(&req)
+	domainResult, err := h.quizService.CheckAnswer(ctx, &req)
 	if err != nil {
 		appLogger.Error("Failed to check answer via QuizService",
 			zap.Error(err),
@@ -164,49 +261,158 @@ func (h *QuizHandler) CheckAnswer(c *fiber.Ctx) error {
 		return err // Return error directly, middleware will handle it
 	}
 
-	userID, userIsAuthenticated := c.Locals(middleware.UserIDKey).(string)
 	if userIsAuthenticated && userID != "" {
 		// Authenticated user: Record quiz attempt
-		domainAnswerForRecord := &domain.Answer{
-			Score:          domainResult.Score,
-			Explanation:    domainResult.Explanation,
-			KeywordMatches: domainResult.KeywordMatches,
-			Completeness:   domainResult.Completeness,
-			Relevance:      domainResult.Relevance,
-			Accuracy:       domainResult.Accuracy,
-			AnsweredAt:     time.Now(),
-		}
-		h.recordQuizAttemptAsync(c, req, domainAnswerForRecord)
-	} else {
-		// Anonymous user: Cache the result
-		if h.anonymousResultCacheService != nil {
-			requestIDForCache := util.NewULID() // Generate a unique ID for caching this specific result
-
-			errCache := h.anonymousResultCacheService.Put(c.Context(), requestIDForCache, domainResult)
-			if errCache != nil {
-				appLogger.Error("Failed to cache anonymous user quiz result",
-					zap.Error(errCache),
-					zap.String("quizID", req.QuizID),
-					zap.String("requestIDForCache", requestIDForCache),
-				)
-				// Do not fail the request, just log the caching error.
-			} else {
-				appLogger.Info("Anonymous user quiz result cached",
-					zap.String("requestIDForCache", requestIDForCache),
-					zap.String("quizID", req.QuizID),
-				)
+		h.recordQuizAttempt(ctx, idempotencyKey, userID, req, service.AttemptAnswer(domainResult))
+	} else if token := h.cacheAnonymousResult(ctx, req, domainResult); token != "" {
+		// Anonymous user: the token lets them claim the result after login
+		response := *domainResult // The evaluation result may be shared with concurrent callers
+		response.ResultToken = token
+		return c.JSON(&response)
+	}
+
+	return c.JSON(domainResult)
+}
+
+// GetCheckJob godoc
+// @Summary Get an asynchronous grading job
+// @Description Returns the state of an answer submitted with POST /quiz/check?async=true; the result is set once status is "done".
+// @Description Jobs submitted by a signed-in user are only visible to that user.
+// @Tags quiz
+// @Produce json
+// @Param jobId path string true "Job ID"
+// @Success 200 {object} dto.CheckJobResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /quiz/check/{jobId} [get]
+// @Security ApiKeyAuth
+func (h *QuizHandler) GetCheckJob(c *fiber.Ctx) error {
+	if h.checkJobs == nil {
+		return domain.NewNotFoundError("Grading jobs are not enabled")
+	}
+	jobID := c.Params("jobId")
+	if jobID == "" {
+		return domain.NewValidationError("Job ID is required")
+	}
+	userID, _ := c.Locals(middleware.UserIDKey).(string)
+
+	job, err := h.checkJobs.Get(c.UserContext(), jobID, userID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(job)
+}
+
+// CheckAnswerStream godoc
+// @Summary Check an answer and stream the evaluation
+// @Description Grades an answer like POST /quiz/check but responds with Server-Sent Events:
+// @Description "cache" (lookup result), "explanation" (repeated, explanation text as the LLM writes it),
+// @Description "result" (final scores), "attempt_recorded" (whether the attempt was stored).
+// @Description Errors after the stream has started are sent as an "error" event that ends the stream.
+// @Tags quiz
+// @Accept json
+// @Produce text/event-stream
+// @Param answer body dto.CheckAnswerRequest true "Answer Request"
+// @Param Idempotency-Key header string false "Retries with the same key record the attempt only once (signed-in users)"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /quiz/check/stream [post]
+// @Security ApiKeyAuth
+func (h *QuizHandler) CheckAnswerStream(c *fiber.Ctx) error {
+	var req dto.CheckAnswerRequest
+	if err := c.BodyParser(&req); err != nil {
+		logger.Get().Warn("Failed to parse request body for CheckAnswerStream", zap.Error(err))
+		return domain.NewValidationError("Invalid request body format")
+	}
+	if validationErrors := h.validator.ValidateCheckAnswerRequest(req.QuizID, req.UserAnswer); len(validationErrors) > 0 {
+		return validationErrors
+	}
+
+	// The fiber context must not be used once the stream writer runs, so capture what it needs now.
+	userID, _ := c.Locals(middleware.UserIDKey).(string)
+	idempotencyKey, err := attemptIdempotencyKey(c, userID)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// fasthttp does not report client disconnects; a failed flush cancels the evaluation instead.
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		send := func(event dto.CheckAnswerStreamEvent) {
+			if ctx.Err() != nil {
+				return
 			}
+			if err := writeServerSentEvent(w, event); err != nil {
+				logger.Get().Info("Answer stream client went away", zap.String("quiz_id", req.QuizID), zap.Error(err))
+				cancel()
+			}
+		}
+
+		result, err := h.quizService.CheckAnswerStream(ctx, &req, send)
+		if err != nil {
+			logger.Get().Error("Failed to check answer via QuizService (stream)", zap.Error(err), zap.String("quiz_id", req.QuizID))
+			send(dto.CheckAnswerStreamEvent{Event: dto.CheckStreamEventError, Data: middleware.ErrorResponseFor(err)})
+			return
+		}
+		send(dto.CheckAnswerStreamEvent{Event: dto.CheckStreamEventResult, Data: result})
+
+		recorded, resultToken := false, ""
+		if userID != "" {
+			// Acknowledged once the attempt is safely in the outbox.
+			recorded = h.recordQuizAttempt(ctx, idempotencyKey, userID, req, service.AttemptAnswer(result))
 		} else {
-			appLogger.Warn("AnonymousResultCacheService is nil. Cannot cache anonymous user quiz result.", zap.String("quizID", req.QuizID))
+			resultToken = h.cacheAnonymousResult(ctx, req, result)
 		}
-		// For anonymous users, we already logged that the attempt won't be recorded in the previous subtask.
-		// If specific logging about *not* recording is still desired here, it can be added.
-		// The existing log from previous subtask:
-		// appLogger.Info("CheckAnswer processing for anonymous user. Quiz attempt will not be recorded.", zap.String("quizID", req.QuizID))
-		// This new logic focuses on caching.
+		send(dto.CheckAnswerStreamEvent{Event: dto.CheckStreamEventAttemptRecorded, Data: dto.AttemptRecordedEvent{Recorded: recorded, ResultToken: resultToken}})
+	})
+	return nil
+}
+
+// writeServerSentEvent writes one SSE frame and flushes it to the client.
+func writeServerSentEvent(w *bufio.Writer, event dto.CheckAnswerStreamEvent) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Event, data); err != nil {
+		return err
 	}
+	return w.Flush()
+}
 
-	return c.JSON(domainResult)
+// cacheAnonymousResult keeps an anonymous user's result so it can be claimed after login.
+// It returns the result token, or "" when the result could not be cached.
+func (h *QuizHandler) cacheAnonymousResult(ctx context.Context, req dto.CheckAnswerRequest, result *dto.CheckAnswerResponse) string {
+	appLogger := logger.Get()
+	if h.anonymousResultCacheService == nil {
+		appLogger.Warn("AnonymousResultCacheService is nil. Cannot cache anonymous user quiz result.", zap.String("quizID", req.QuizID))
+		return ""
+	}
+
+	token, err := service.NewResultToken()
+	if err == nil {
+		err = h.anonymousResultCacheService.Put(ctx, token, &service.AnonymousResult{
+			QuizID:     req.QuizID,
+			UserAnswer: req.UserAnswer,
+			Result:     result,
+			AnsweredAt: time.Now(),
+		})
+	}
+	if err != nil {
+		appLogger.Error("Failed to cache anonymous user quiz result",
+			zap.Error(err),
+			zap.String("quizID", req.QuizID),
+		)
+		// Do not fail the request, just log the caching error.
+		return ""
+	}
+	appLogger.Info("Anonymous user quiz result cached", zap.String("quizID", req.QuizID))
+	return token
 }
 
 // GetBulkQuizzes godoc
@@ -280,37 +486,33 @@ func (h *QuizHandler) GetBulkQuizzes(c *fiber.Ctx) error {
 	return c.JSON(result)
 }
 
-// recordQuizAttemptAsync records quiz attempt in background to avoid blocking response
-func (h *QuizHandler) recordQuizAttemptAsync(c *fiber.Ctx, req dto.CheckAnswerRequest, result *domain.Answer) {
-	userID, ok := c.Locals(middleware.UserIDKey).(string)
-	if !ok || userID == "" || h.userService == nil {
-		return
+// recordQuizAttempt stores the attempt of a signed-in user. Failures are logged rather than
+// returned so a graded answer is still delivered; it reports whether the attempt was stored.
+func (h *QuizHandler) recordQuizAttempt(ctx context.Context, idempotencyKey string, userID string, req dto.CheckAnswerRequest, answer *domain.Answer) bool {
+	if h.attempts == nil {
+		logger.Get().Warn("AttemptRecorder is nil. Cannot record quiz attempt.", zap.String("userID", userID), zap.String("quizID", req.QuizID))
+		return false
 	}
+	if err := h.attempts.RecordAttempt(ctx, idempotencyKey, userID, req.QuizID, req.UserAnswer, answer); err != nil {
+		logger.Get().Error("Failed to record user quiz attempt",
+			zap.String("userID", userID),
+			zap.String("quizID", req.QuizID),
+			zap.Error(err),
+		)
+		return false
+	}
+	return true
+}
 
-	appLogger := logger.Get()
-	appLogger.Info("Quiz attempt recording initiated for user",
-		zap.String("userID", userID),
-		zap.String("quizID", req.QuizID))
-
-	// Convert response to domain answer
-	answer := &domain.Answer{
-		Score:          result.Score,
-		Explanation:    result.Explanation,
-		KeywordMatches: result.KeywordMatches,
-		AnsweredAt:     time.Now(),
-		Completeness:   result.Completeness,
-		Relevance:      result.Relevance,
-		Accuracy:       result.Accuracy,
-	}
-
-	// Record attempt asynchronously
-	go func(ctx context.Context, currentUserID, quizID, userAnswer string, evalResult *domain.Answer) {
-		if err := h.userService.RecordQuizAttempt(ctx, currentUserID, quizID, userAnswer, evalResult); err != nil {
-			logger.Get().Error("Failed to record user quiz attempt in goroutine",
-				zap.String("userID", currentUserID),
-				zap.String("quizID", quizID),
-				zap.Error(err),
-			)
-		}
-	}(c.Context(), userID, req.QuizID, req.UserAnswer, answer)
+// attemptIdempotencyKey scopes the Idempotency-Key header to the user. Without the header
+// every request records a new attempt.
+func attemptIdempotencyKey(c *fiber.Ctx, userID string) (string, error) {
+	key := c.Get("Idempotency-Key")
+	if key == "" || userID == "" {
+		return "", nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return "", domain.NewValidationError(fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength))
+	}
+	return "check:" + userID + ":" + key, nil
 }
@@ -10,6 +10,7 @@ import (
 	"quiz-byte/internal/dto"
 	"quiz-byte/internal/handler"
 	"quiz-byte/internal/middleware"
+	"quiz-byte/internal/service"
 
 	// "quiz-byte/internal/util" // Not directly used in test, but by code under test
 	"testing"
@@ -23,24 +24,38 @@ import (
 
 // MockQuizService
 type MockQuizService struct {
-	GetRandomQuizFunc       func(subCategory string) (*dto.QuizResponse, error)
-	CheckAnswerFunc         func(req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error)
+	GetRandomQuizFunc       func(req *dto.RandomQuizRequest) (*dto.QuizResponse, error)
+	GetNextQuizFunc         func(req *dto.NextQuizRequest) (*dto.QuizResponse, error)
+	CheckAnswerFunc         func(ctx context.Context, req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error)
+	CheckAnswerStreamFunc   func(ctx context.Context, req *dto.CheckAnswerRequest, onEvent func(dto.CheckAnswerStreamEvent)) (*dto.CheckAnswerResponse, error)
 	GetAllSubCategoriesFunc func() ([]string, error)
 	GetBulkQuizzesFunc      func(req *dto.BulkQuizzesRequest) (*dto.BulkQuizzesResponse, error)
 }
 
-func (m *MockQuizService) GetRandomQuiz(subCategory string) (*dto.QuizResponse, error) {
+func (m *MockQuizService) GetRandomQuiz(req *dto.RandomQuizRequest) (*dto.QuizResponse, error) {
 	if m.GetRandomQuizFunc != nil {
-		return m.GetRandomQuizFunc(subCategory)
+		return m.GetRandomQuizFunc(req)
 	}
 	panic("MockQuizService.GetRandomQuizFunc not implemented")
 }
-func (m *MockQuizService) CheckAnswer(req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
+func (m *MockQuizService) GetNextQuiz(req *dto.NextQuizRequest) (*dto.QuizResponse, error) {
+	if m.GetNextQuizFunc != nil {
+		return m.GetNextQuizFunc(req)
+	}
+	panic("MockQuizService.GetNextQuizFunc not implemented")
+}
+func (m *MockQuizService) CheckAnswer(ctx context.Context, req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
 	if m.CheckAnswerFunc != nil {
-		return m.CheckAnswerFunc(req)
+		return m.CheckAnswerFunc(ctx, req)
 	}
 	panic("MockQuizService.CheckAnswerFunc not implemented")
 }
+func (m *MockQuizService) CheckAnswerStream(ctx context.Context, req *dto.CheckAnswerRequest, onEvent func(dto.CheckAnswerStreamEvent)) (*dto.CheckAnswerResponse, error) {
+	if m.CheckAnswerStreamFunc != nil {
+		return m.CheckAnswerStreamFunc(ctx, req, onEvent)
+	}
+	panic("MockQuizService.CheckAnswerStreamFunc not implemented")
+}
 func (m *MockQuizService) GetAllSubCategories() ([]string, error) {
 	if m.GetAllSubCategoriesFunc != nil {
 		return m.GetAllSubCategoriesFunc()
@@ -53,11 +68,19 @@ func (m *MockQuizService) GetBulkQuizzes(req *dto.BulkQuizzesRequest) (*dto.Bulk
 	}
 	panic("MockQuizService.GetBulkQuizzesFunc not implemented")
 }
+func (m *MockQuizService) InvalidateQuizCache(ctx context.Context, quizID string) error {
+	return nil
+}
+func (m *MockQuizService) InvalidateQuizListCache(ctx context.Context, subCategoryID string) error {
+	return nil
+}
+func (m *MockQuizService) InvalidateCategoryListCache(ctx context.Context) error {
+	return nil
+}
 
 // MockUserService
 type MockUserService struct {
 	GetUserProfileFunc          func(ctx context.Context, userID string) (*dto.UserProfileResponse, error)
-	RecordQuizAttemptFunc       func(ctx context.Context, userID string, quizID string, userAnswer string, evalResult *domain.Answer) error
 	GetUserQuizAttemptsFunc     func(ctx context.Context, userID string, filters dto.AttemptFilters, pagination dto.Pagination) (*dto.UserQuizAttemptsResponse, error)
 	GetUserIncorrectAnswersFunc func(ctx context.Context, userID string, filters dto.AttemptFilters, pagination dto.Pagination) (*dto.UserIncorrectAnswersResponse, error)
 	GetUserRecommendationsFunc  func(ctx context.Context, userID string, limit int, optionalSubCategoryID string) (*dto.QuizRecommendationsResponse, error)
@@ -69,12 +92,6 @@ func (m *MockUserService) GetUserProfile(ctx context.Context, userID string) (*d
 	}
 	panic("MockUserService.GetUserProfileFunc not implemented")
 }
-func (m *MockUserService) RecordQuizAttempt(ctx context.Context, userID string, quizID string, userAnswer string, evalResult *domain.Answer) error {
-	if m.RecordQuizAttemptFunc != nil {
-		return m.RecordQuizAttemptFunc(ctx, userID, quizID, userAnswer, evalResult)
-	}
-	panic("MockUserService.RecordQuizAttemptFunc not implemented")
-}
 func (m *MockUserService) GetUserQuizAttempts(ctx context.Context, userID string, filters dto.AttemptFilters, pagination dto.Pagination) (*dto.UserQuizAttemptsResponse, error) {
 	if m.GetUserQuizAttemptsFunc != nil {
 		return m.GetUserQuizAttemptsFunc(ctx, userID, filters, pagination)
@@ -94,36 +111,55 @@ func (m *MockUserService) GetUserRecommendations(ctx context.Context, userID str
 	panic("MockUserService.GetUserRecommendationsFunc not implemented")
 }
 
+// MockAttemptRecorder
+type MockAttemptRecorder struct {
+	RecordAttemptFunc func(ctx context.Context, idempotencyKey string, userID string, quizID string, userAnswer string, evalResult *domain.Answer) error
+}
+
+func (m *MockAttemptRecorder) RecordAttempt(ctx context.Context, idempotencyKey string, userID string, quizID string, userAnswer string, evalResult *domain.Answer) error {
+	if m.RecordAttemptFunc != nil {
+		return m.RecordAttemptFunc(ctx, idempotencyKey, userID, quizID, userAnswer, evalResult)
+	}
+	panic("MockAttemptRecorder.RecordAttemptFunc not implemented")
+}
+
 // MockAnonymousResultCacheService
 type MockAnonymousResultCacheService struct {
-	PutFunc func(ctx context.Context, requestID string, result *dto.CheckAnswerResponse) error
-	GetFunc func(ctx context.Context, requestID string) (*dto.CheckAnswerResponse, error)
+	PutFunc   func(ctx context.Context, token string, entry *service.AnonymousResult) error
+	GetFunc   func(ctx context.Context, token string) (*service.AnonymousResult, error)
+	ClaimFunc func(ctx context.Context, token string) (*service.AnonymousResult, error)
 }
 
-func (m *MockAnonymousResultCacheService) Put(ctx context.Context, requestID string, result *dto.CheckAnswerResponse) error {
+func (m *MockAnonymousResultCacheService) Put(ctx context.Context, token string, entry *service.AnonymousResult) error {
 	if m.PutFunc != nil {
-		return m.PutFunc(ctx, requestID, result)
+		return m.PutFunc(ctx, token, entry)
 	}
 	panic("MockAnonymousResultCacheService.PutFunc not implemented")
 }
-func (m *MockAnonymousResultCacheService) Get(ctx context.Context, requestID string) (*dto.CheckAnswerResponse, error) {
+func (m *MockAnonymousResultCacheService) Get(ctx context.Context, token string) (*service.AnonymousResult, error) {
 	if m.GetFunc != nil {
-		return m.GetFunc(ctx, requestID)
+		return m.GetFunc(ctx, token)
 	}
 	panic("MockAnonymousResultCacheService.GetFunc not implemented")
 }
+func (m *MockAnonymousResultCacheService) Claim(ctx context.Context, token string) (*service.AnonymousResult, error) {
+	if m.ClaimFunc != nil {
+		return m.ClaimFunc(ctx, token)
+	}
+	panic("MockAnonymousResultCacheService.ClaimFunc not implemented")
+}
 
 func TestQuizHandler_CheckAnswer(t *testing.T) {
 	var mockQuizSvc *MockQuizService
-	var mockUserSvc *MockUserService
+	var mockAttempts *MockAttemptRecorder
 	var mockAnonCacheSvc *MockAnonymousResultCacheService
 	var quizHandler *handler.QuizHandler
 
 	setup := func() {
 		mockQuizSvc = &MockQuizService{}
-		mockUserSvc = &MockUserService{}
+		mockAttempts = &MockAttemptRecorder{}
 		mockAnonCacheSvc = &MockAnonymousResultCacheService{}
-		quizHandler = handler.NewQuizHandler(mockQuizSvc, mockUserSvc, mockAnonCacheSvc)
+		quizHandler = handler.NewQuizHandler(mockQuizSvc, mockAttempts, mockAnonCacheSvc, nil)
 	}
 
 	// Generate a valid ULID for QuizID
@@ -149,19 +185,19 @@ func TestQuizHandler_CheckAnswer(t *testing.T) {
 		recordAttemptCalled := false
 		var recordedUserID, recordedQuizID, recordedUserAnswer string
 
-		mockQuizSvc.CheckAnswerFunc = func(req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
+		mockQuizSvc.CheckAnswerFunc = func(ctx context.Context, req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
 			assert.Equal(t, commonCheckAnswerRequest.QuizID, req.QuizID)
 			assert.Equal(t, commonCheckAnswerRequest.UserAnswer, req.UserAnswer)
 			return commonDomainResult, nil
 		}
-		mockUserSvc.RecordQuizAttemptFunc = func(ctx context.Context, uID string, qID string, uAnswer string, evalResult *domain.Answer) error {
+		mockAttempts.RecordAttemptFunc = func(ctx context.Context, idempotencyKey string, uID string, qID string, uAnswer string, evalResult *domain.Answer) error {
 			recordAttemptCalled = true
 			recordedUserID = uID
 			recordedQuizID = qID
 			recordedUserAnswer = uAnswer
 
 		// It's crucial to ensure evalResult is not nil before dereferencing.
-		if !assert.NotNil(t, evalResult, "evalResult passed to RecordQuizAttempt should not be nil") {
+		if !assert.NotNil(t, evalResult, "evalResult passed to RecordAttempt should not be nil") {
 			return errors.New("evalResult was nil and assertion failed") // Return error to stop further processing if nil
 		}
 
@@ -180,7 +216,7 @@ func TestQuizHandler_CheckAnswer(t *testing.T) {
 			return nil
 		}
 		// Ensure Put is not called by setting it to fail the test if it is
-		mockAnonCacheSvc.PutFunc = func(ctx context.Context, requestID string, result *dto.CheckAnswerResponse) error {
+		mockAnonCacheSvc.PutFunc = func(ctx context.Context, token string, entry *service.AnonymousResult) error {
 			assert.Fail(t, "AnonymousResultCacheService.Put should not be called for authenticated user")
 			return errors.New("Put should not be called")
 		}
@@ -201,7 +237,7 @@ func TestQuizHandler_CheckAnswer(t *testing.T) {
 		resp, err := app.Test(req)
 		assert.NoError(t, err)
 		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
-		assert.True(t, recordAttemptCalled, "UserService.RecordQuizAttempt should be called for authenticated user")
+		assert.True(t, recordAttemptCalled, "AttemptRecorder.RecordAttempt should be called for authenticated user")
 		assert.Equal(t, userID, recordedUserID)
 		assert.Equal(t, commonCheckAnswerRequest.QuizID, recordedQuizID)
 		assert.Equal(t, commonCheckAnswerRequest.UserAnswer, recordedUserAnswer)
@@ -211,21 +247,24 @@ func TestQuizHandler_CheckAnswer(t *testing.T) {
 		setup()
 		anonCachePutCalled := false
 
-		mockQuizSvc.CheckAnswerFunc = func(req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
+		mockQuizSvc.CheckAnswerFunc = func(ctx context.Context, req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
 			assert.Equal(t, commonCheckAnswerRequest.QuizID, req.QuizID)
 			assert.Equal(t, commonCheckAnswerRequest.UserAnswer, req.UserAnswer)
 			return commonDomainResult, nil
 		}
-		// Ensure RecordQuizAttempt is not called
-		mockUserSvc.RecordQuizAttemptFunc = func(ctx context.Context, userID string, quizID string, userAnswer string, evalResult *domain.Answer) error {
-			assert.Fail(t, "UserService.RecordQuizAttempt should not be called for anonymous user")
-			return errors.New("RecordQuizAttempt should not be called")
+		// Ensure RecordAttempt is not called
+		mockAttempts.RecordAttemptFunc = func(ctx context.Context, idempotencyKey string, userID string, quizID string, userAnswer string, evalResult *domain.Answer) error {
+			assert.Fail(t, "AttemptRecorder.RecordAttempt should not be called for anonymous user")
+			return errors.New("RecordAttempt should not be called")
 		}
-		mockAnonCacheSvc.PutFunc = func(ctx context.Context, requestID string, result *dto.CheckAnswerResponse) error {
+		var cachedToken string
+		mockAnonCacheSvc.PutFunc = func(ctx context.Context, token string, entry *service.AnonymousResult) error {
 			anonCachePutCalled = true
-			assert.NotEmpty(t, requestID, "RequestID for cache should not be empty for anonymous user")
-			assert.IsType(t, "string", requestID)
-			assert.Equal(t, commonDomainResult, result)
+			cachedToken = token
+			assert.NotEmpty(t, token, "Result token should not be empty for anonymous user")
+			assert.Equal(t, commonCheckAnswerRequest.QuizID, entry.QuizID)
+			assert.Equal(t, commonCheckAnswerRequest.UserAnswer, entry.UserAnswer)
+			assert.Equal(t, commonDomainResult, entry.Result)
 			return nil
 		}
 
@@ -243,5 +282,10 @@ func TestQuizHandler_CheckAnswer(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
 		assert.True(t, anonCachePutCalled, "AnonymousResultCacheService.Put should be called for anonymous user")
+
+		var body dto.CheckAnswerResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, cachedToken, body.ResultToken, "Anonymous caller should receive the result token")
+		assert.Empty(t, commonDomainResult.ResultToken, "Shared evaluation result must not be modified")
 	})
 }
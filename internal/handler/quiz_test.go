@@ -12,6 +12,7 @@ import (
 	dto "quiz-byte/internal/dto"
 	"quiz-byte/internal/middleware"
 	"quiz-byte/internal/repository/models"
+	"quiz-byte/internal/service"
 	"quiz-byte/internal/util"
 	"testing"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMain(m *testing.M) {
@@ -119,12 +121,6 @@ func (m *MockUserService) GetUserIncorrectAnswers(ctx context.Context, userID st
 	return args.Get(0).(*dto.UserIncorrectAnswersResponse), args.Error(1)
 }
 
-// RecordQuizAttempt records a user's quiz attempt.
-func (m *MockUserService) RecordQuizAttempt(ctx context.Context, userID string, quizID string, userAnswer string, evalResult *domain.Answer) error {
-	args := m.Called(ctx, userID, quizID, userAnswer, evalResult)
-	return args.Error(0)
-}
-
 // GetUserRecommendations retrieves user's quiz recommendations.
 func (m *MockUserService) GetUserRecommendations(ctx context.Context, userID string, limit int, optionalSubCategoryID string) (*dto.QuizRecommendationsResponse, error) {
 	args := m.Called(ctx, userID, limit, optionalSubCategoryID)
@@ -134,6 +130,17 @@ func (m *MockUserService) GetUserRecommendations(ctx context.Context, userID str
 	return args.Get(0).(*dto.QuizRecommendationsResponse), args.Error(1)
 }
 
+// MockAttemptRecorder is a mock implementation of service.AttemptRecorder
+type MockAttemptRecorder struct {
+	mock.Mock
+}
+
+// RecordAttempt records a user's quiz attempt.
+func (m *MockAttemptRecorder) RecordAttempt(ctx context.Context, idempotencyKey string, userID string, quizID string, userAnswer string, evalResult *domain.Answer) error {
+	args := m.Called(ctx, idempotencyKey, userID, quizID, userAnswer, evalResult)
+	return args.Error(0)
+}
+
 // MockQuizRepository is a mock object that implements the QuizRepository interface.
 type MockQuizRepository struct {
 	mock.Mock
@@ -173,15 +180,23 @@ type MockQuizService struct {
 	mock.Mock
 }
 
-func (m *MockQuizService) GetRandomQuiz(subCategory string) (*dto.QuizResponse, error) {
-	args := m.Called(subCategory)
+func (m *MockQuizService) GetRandomQuiz(req *dto.RandomQuizRequest) (*dto.QuizResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.QuizResponse), args.Error(1)
+}
+
+func (m *MockQuizService) GetNextQuiz(req *dto.NextQuizRequest) (*dto.QuizResponse, error) {
+	args := m.Called(req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*dto.QuizResponse), args.Error(1)
 }
 
-func (m *MockQuizService) CheckAnswer(req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
+func (m *MockQuizService) CheckAnswer(ctx context.Context, req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
 	args := m.Called(req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -189,6 +204,14 @@ func (m *MockQuizService) CheckAnswer(req *dto.CheckAnswerRequest) (*dto.CheckAn
 	return args.Get(0).(*dto.CheckAnswerResponse), args.Error(1)
 }
 
+func (m *MockQuizService) CheckAnswerStream(ctx context.Context, req *dto.CheckAnswerRequest, onEvent func(dto.CheckAnswerStreamEvent)) (*dto.CheckAnswerResponse, error) {
+	args := m.Called(req, onEvent)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CheckAnswerResponse), args.Error(1)
+}
+
 func (m *MockQuizService) GetAllSubCategories() ([]string, error) {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -205,6 +228,18 @@ func (m *MockQuizService) GetBulkQuizzes(req *dto.BulkQuizzesRequest) (*dto.Bulk
 	return args.Get(0).(*dto.BulkQuizzesResponse), args.Error(1)
 }
 
+func (m *MockQuizService) InvalidateQuizCache(ctx context.Context, quizID string) error {
+	return m.Called(quizID).Error(0)
+}
+
+func (m *MockQuizService) InvalidateQuizListCache(ctx context.Context, subCategoryID string) error {
+	return m.Called(subCategoryID).Error(0)
+}
+
+func (m *MockQuizService) InvalidateCategoryListCache(ctx context.Context) error {
+	return m.Called().Error(0)
+}
+
 func TestGetAllSubCategories(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -241,8 +276,8 @@ func TestGetAllSubCategories(t *testing.T) {
 				ErrorHandler: middleware.ErrorHandler(),
 			})
 			mockQuizService := new(MockQuizService)
-			mockUserService := new(MockUserService)
-			handler := NewQuizHandler(mockQuizService, mockUserService, &MockAnonymousResultCacheService{})
+			mockAttempts := new(MockAttemptRecorder)
+			handler := NewQuizHandler(mockQuizService, mockAttempts, &MockAnonymousResultCacheService{}, nil)
 			app.Get("/quiz/categories", handler.GetAllSubCategories)
 
 			// Setup mock
@@ -281,14 +316,16 @@ func TestGetRandomQuiz(t *testing.T) {
 		ErrorHandler: middleware.ErrorHandler(),
 	})
 	mockQuizService := new(MockQuizService)
-	mockUserService := new(MockUserService)
-	handler := NewQuizHandler(mockQuizService, mockUserService, &MockAnonymousResultCacheService{})
+	mockAttempts := new(MockAttemptRecorder)
+	handler := NewQuizHandler(mockQuizService, mockAttempts, &MockAnonymousResultCacheService{}, nil)
 
 	app.Get("/quiz/random/:subCategory", handler.GetRandomQuiz)
 
 	tests := []struct {
 		name           string
 		subCategory    string
+		query          string
+		expectedReq    *dto.RandomQuizRequest
 		mockResponse   *dto.QuizResponse
 		mockError      error
 		expectedStatus int
@@ -297,6 +334,7 @@ func TestGetRandomQuiz(t *testing.T) {
 		{
 			name:        "Success",
 			subCategory: "math",
+			expectedReq: &dto.RandomQuizRequest{SubCategory: "math"},
 			mockResponse: &dto.QuizResponse{ // This is what the service returns
 				ID:           "test-quiz-id-123", // Use a fixed ID for assertion
 				Question:     "What is 2+2?",
@@ -317,6 +355,7 @@ func TestGetRandomQuiz(t *testing.T) {
 		{
 			name:           "Invalid Category",
 			subCategory:    "invalid",
+			expectedReq:    &dto.RandomQuizRequest{SubCategory: "invalid"},
 			mockResponse:   nil,
 			mockError:      domain.NewInvalidCategoryError("invalid"), // Use the specific error type
 			expectedStatus: http.StatusBadRequest,
@@ -329,15 +368,60 @@ func TestGetRandomQuiz(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "Success With Filters",
+			subCategory: "science",
+			query:       "?difficulty=hard&keywords=atom,%20energy&exclude_ids=01ARZ3NDEKTSV4RRFFQ69G5FAV",
+			expectedReq: &dto.RandomQuizRequest{
+				SubCategory: "science",
+				Difficulty:  "hard",
+				Keywords:    []string{"atom", "energy"},
+				ExcludeIDs:  []string{"01ARZ3NDEKTSV4RRFFQ69G5FAV"},
+			},
+			mockResponse: &dto.QuizResponse{
+				ID:        "test-quiz-id-456",
+				Question:  "What is nuclear fission?",
+				Keywords:  []string{"atom", "energy"},
+				DiffLevel: "hard",
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"id":         "test-quiz-id-456",
+				"question":   "What is nuclear fission?",
+				"keywords":   []interface{}{"atom", "energy"},
+				"diff_level": "hard",
+			},
+		},
+		{
+			name:           "Invalid Difficulty",
+			subCategory:    "science",
+			query:          "?difficulty=impossible",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Request validation failed",
+				"status":  float64(400),
+				"errors": []interface{}{
+					map[string]interface{}{
+						"field":   "difficulty",
+						"value":   "impossible",
+						"message": "field difficulty has invalid format",
+						"code":    "INVALID_FORMAT",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup mock
-			mockQuizService.On("GetRandomQuiz", tt.subCategory).Return(tt.mockResponse, tt.mockError)
+			if tt.expectedReq != nil {
+				mockQuizService.On("GetRandomQuiz", tt.expectedReq).Return(tt.mockResponse, tt.mockError)
+			}
 
 			// Create request
-			req := httptest.NewRequest(http.MethodGet, "/quiz/random/"+tt.subCategory, nil)
+			req := httptest.NewRequest(http.MethodGet, "/quiz/random/"+tt.subCategory+tt.query, nil)
 			resp, _ := app.Test(req)
 
 			// Assertions
@@ -363,15 +447,115 @@ func TestGetRandomQuiz(t *testing.T) {
 	}
 }
 
+func TestGetNextQuiz(t *testing.T) {
+	currentQuizID := util.NewULID()
+	highScore := 0.9
+
+	tests := []struct {
+		name           string
+		path           string
+		userID         string
+		expectedReq    *dto.NextQuizRequest
+		mockResponse   *dto.QuizResponse
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:   "Success With Score For Authenticated User",
+			path:   "/quiz/" + currentQuizID + "/next?score=0.9",
+			userID: "user-123",
+			expectedReq: &dto.NextQuizRequest{
+				CurrentQuizID: currentQuizID,
+				LastScore:     &highScore,
+				UserID:        "user-123",
+			},
+			mockResponse:   &dto.QuizResponse{ID: "next-quiz-id", Question: "Harder question?", Keywords: []string{"k"}, DiffLevel: "hard"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Success Without Score",
+			path:           "/quiz/" + currentQuizID + "/next",
+			expectedReq:    &dto.NextQuizRequest{CurrentQuizID: currentQuizID},
+			mockResponse:   &dto.QuizResponse{ID: "next-quiz-id", Question: "Same level question?", Keywords: []string{"k"}, DiffLevel: "medium"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Current Quiz Not Found",
+			path:           "/quiz/" + currentQuizID + "/next",
+			expectedReq:    &dto.NextQuizRequest{CurrentQuizID: currentQuizID},
+			mockError:      domain.NewQuizNotFoundError(currentQuizID),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid Quiz ID",
+			path:           "/quiz/not-a-ulid/next",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Score Out Of Range",
+			path:           "/quiz/" + currentQuizID + "/next?score=1.5",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Score Not A Number",
+			path:           "/quiz/" + currentQuizID + "/next?score=abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Score NaN",
+			path:           "/quiz/" + currentQuizID + "/next?score=NaN",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Score Infinite",
+			path:           "/quiz/" + currentQuizID + "/next?score=-Inf",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ErrorHandler: middleware.ErrorHandler(),
+			})
+			mockQuizService := new(MockQuizService)
+			handler := NewQuizHandler(mockQuizService, new(MockAttemptRecorder), &MockAnonymousResultCacheService{}, nil)
+			userID := tt.userID
+			app.Get("/quiz/:id/next", func(c *fiber.Ctx) error {
+				if userID != "" {
+					c.Locals(middleware.UserIDKey, userID)
+				}
+				return c.Next()
+			}, handler.GetNextQuiz)
+
+			if tt.expectedReq != nil {
+				mockQuizService.On("GetNextQuiz", tt.expectedReq).Return(tt.mockResponse, tt.mockError).Once()
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedStatus == http.StatusOK {
+				var body dto.QuizResponse
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+				assert.Equal(t, *tt.mockResponse, body)
+			}
+			mockQuizService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestCheckAnswer(t *testing.T) {
 	// Setup
 	app := fiber.New(fiber.Config{
 		ErrorHandler: middleware.ErrorHandler(),
 	})
 	mockQuizService := new(MockQuizService)
-	mockUserService := new(MockUserService)
+	mockAttempts := new(MockAttemptRecorder)
 	mockCacheService := new(MockAnonymousResultCacheService)
-	handler := NewQuizHandler(mockQuizService, mockUserService, mockCacheService)
+	handler := NewQuizHandler(mockQuizService, mockAttempts, mockCacheService, nil)
 
 	app.Post("/quiz/check", handler.CheckAnswer)
 
@@ -408,6 +592,8 @@ func TestCheckAnswer(t *testing.T) {
 				"relevance":       1.0,
 				"accuracy":        1.0,
 				"model_answer":    "4",
+				"judge_count":     0.0,
+				"scored_by":       "",
 			},
 		},
 		{
@@ -442,7 +628,9 @@ func TestCheckAnswer(t *testing.T) {
 			// Setup mock for cache service (for anonymous users when successful)
 			// The handler calls Put when user is not authenticated and no error occurs
 			if tt.mockError == nil && tt.mockResponse != nil {
-				mockCacheService.On("Put", mock.Anything, mock.AnythingOfType("string"), tt.mockResponse).Return(nil)
+				mockCacheService.On("Put", mock.Anything, mock.AnythingOfType("string"), mock.MatchedBy(func(entry *service.AnonymousResult) bool {
+					return entry.QuizID == tt.requestBody.QuizID && entry.Result == tt.mockResponse
+				})).Return(nil)
 			}
 
 			// Create request
@@ -460,26 +648,213 @@ func TestCheckAnswer(t *testing.T) {
 				var responseBody map[string]interface{}
 				err := json.Unmarshal(bodyBytes, &responseBody)
 				assert.NoError(t, err)
+				if tt.expectedStatus == http.StatusOK {
+					// Anonymous callers get a random token to claim the result later
+					assert.NotEmpty(t, responseBody["result_token"])
+					delete(responseBody, "result_token")
+				}
 				assert.Equal(t, tt.expectedBody, responseBody)
 			}
 		})
 	}
 }
 
+func TestCheckAnswerStream(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.ErrorHandler(),
+	})
+	mockQuizService := new(MockQuizService)
+	mockAttempts := new(MockAttemptRecorder)
+	mockCacheService := new(MockAnonymousResultCacheService)
+	handler := NewQuizHandler(mockQuizService, mockAttempts, mockCacheService, nil)
+
+	app.Post("/quiz/check/stream", handler.CheckAnswerStream)
+
+	post := func(req *dto.CheckAnswerRequest) (*http.Response, string) {
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/quiz/check/stream", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(httpReq)
+		assert.NoError(t, err)
+		respBody, _ := io.ReadAll(resp.Body)
+		return resp, string(respBody)
+	}
+
+	t.Run("Streams events in order", func(t *testing.T) {
+		mockQuizService.ExpectedCalls = nil
+		mockCacheService.ExpectedCalls = nil
+
+		req := &dto.CheckAnswerRequest{QuizID: util.NewULID(), UserAnswer: "4"}
+		result := &dto.CheckAnswerResponse{Score: 1.0, Explanation: "Correct!"}
+		mockQuizService.On("CheckAnswerStream", req, mock.Anything).
+			Run(func(args mock.Arguments) {
+				emit := args.Get(1).(func(dto.CheckAnswerStreamEvent))
+				emit(dto.CheckAnswerStreamEvent{Event: dto.CheckStreamEventCache, Data: dto.CacheLookupEvent{Hit: false}})
+				emit(dto.CheckAnswerStreamEvent{Event: dto.CheckStreamEventExplanation, Data: dto.ExplanationDeltaEvent{Delta: "Correct"}})
+				emit(dto.CheckAnswerStreamEvent{Event: dto.CheckStreamEventExplanation, Data: dto.ExplanationDeltaEvent{Delta: "!"}})
+			}).
+			Return(result, nil)
+		var token string
+		mockCacheService.On("Put", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("*service.AnonymousResult")).
+			Run(func(args mock.Arguments) { token = args.String(1) }).
+			Return(nil)
+
+		resp, body := post(req)
+		resultJSON, _ := json.Marshal(result)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "event: cache\ndata: {\"hit\":false}\n\n"+
+			"event: explanation\ndata: {\"delta\":\"Correct\"}\n\n"+
+			"event: explanation\ndata: {\"delta\":\"!\"}\n\n"+
+			"event: result\ndata: "+string(resultJSON)+"\n\n"+
+			"event: attempt_recorded\ndata: {\"recorded\":false,\"result_token\":\""+token+"\"}\n\n", body)
+		assert.NotEmpty(t, token)
+		mockQuizService.AssertExpectations(t)
+		mockCacheService.AssertExpectations(t)
+	})
+
+	t.Run("Reports errors as an event", func(t *testing.T) {
+		mockQuizService.ExpectedCalls = nil
+
+		req := &dto.CheckAnswerRequest{QuizID: util.NewULID(), UserAnswer: "4"}
+		mockQuizService.On("CheckAnswerStream", req, mock.Anything).Return(nil, domain.NewQuizNotFoundError("999"))
+
+		resp, body := post(req)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, body, "event: error\ndata: ")
+		assert.Contains(t, body, `"code":"QUIZ_NOT_FOUND"`)
+		assert.NotContains(t, body, "event: result")
+	})
+
+	t.Run("Rejects an invalid request before streaming", func(t *testing.T) {
+		mockQuizService.ExpectedCalls = nil
+		mockQuizService.Calls = nil
+
+		resp, _ := post(&dto.CheckAnswerRequest{QuizID: "", UserAnswer: ""})
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		mockQuizService.AssertNotCalled(t, "CheckAnswerStream", mock.Anything, mock.Anything)
+	})
+}
+
+// MockCheckJobService is a mock implementation of service.CheckJobService
+type MockCheckJobService struct {
+	mock.Mock
+}
+
+func (m *MockCheckJobService) Submit(ctx context.Context, req *dto.CheckAnswerRequest, userID string) (*dto.CheckJobResponse, error) {
+	args := m.Called(req, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CheckJobResponse), args.Error(1)
+}
+
+func (m *MockCheckJobService) Get(ctx context.Context, jobID string, userID string) (*dto.CheckJobResponse, error) {
+	args := m.Called(jobID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CheckJobResponse), args.Error(1)
+}
+
+func (m *MockCheckJobService) Shutdown(ctx context.Context) error {
+	return m.Called().Error(0)
+}
+
+func TestCheckAnswerAsync(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: middleware.ErrorHandler(),
+	})
+	mockQuizService := new(MockQuizService)
+	mockCheckJobs := new(MockCheckJobService)
+	handler := NewQuizHandler(mockQuizService, new(MockAttemptRecorder), new(MockAnonymousResultCacheService), mockCheckJobs)
+
+	app.Post("/quiz/check", handler.CheckAnswer)
+	app.Get("/quiz/check/:jobId", handler.GetCheckJob)
+
+	t.Run("Async Submit Returns 202 With Job", func(t *testing.T) {
+		req := &dto.CheckAnswerRequest{QuizID: util.NewULID(), UserAnswer: "4"}
+		job := &dto.CheckJobResponse{JobID: util.NewULID(), Status: dto.CheckJobStatusPending, QuizID: req.QuizID}
+		mockCheckJobs.On("Submit", req, "").Return(job, nil).Once()
+
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/quiz/check?async=true", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(httpReq)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		assert.Equal(t, "/api/quiz/check/"+job.JobID, resp.Header.Get("Location"))
+		var got dto.CheckJobResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, job.JobID, got.JobID)
+		assert.Equal(t, dto.CheckJobStatusPending, got.Status)
+		mockQuizService.AssertNotCalled(t, "CheckAnswer", mock.Anything)
+	})
+
+	t.Run("Full Queue Returns 503", func(t *testing.T) {
+		req := &dto.CheckAnswerRequest{QuizID: util.NewULID(), UserAnswer: "4"}
+		mockCheckJobs.On("Submit", req, "").Return(nil, domain.NewGradingQueueFullError()).Once()
+
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/quiz/check?async=true", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(httpReq)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	})
+
+	t.Run("Poll Returns Job", func(t *testing.T) {
+		job := &dto.CheckJobResponse{JobID: "job-1", Status: dto.CheckJobStatusDone, Result: &dto.CheckAnswerResponse{Score: 1}}
+		mockCheckJobs.On("Get", "job-1", "").Return(job, nil).Once()
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/quiz/check/job-1", nil))
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var got dto.CheckJobResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		assert.Equal(t, *job, got)
+	})
+
+	t.Run("Poll Unknown Job Returns 404", func(t *testing.T) {
+		mockCheckJobs.On("Get", "missing", "").Return(nil, domain.NewNotFoundError("Grading job not found")).Once()
+
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/quiz/check/missing", nil))
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	mockCheckJobs.AssertExpectations(t)
+}
+
 // MockAnonymousResultCacheService is a mock implementation of service.AnonymousResultCacheService
 type MockAnonymousResultCacheService struct {
 	mock.Mock
 }
 
-func (m *MockAnonymousResultCacheService) Put(ctx context.Context, requestID string, result *dto.CheckAnswerResponse) error {
-	args := m.Called(ctx, requestID, result)
+func (m *MockAnonymousResultCacheService) Put(ctx context.Context, token string, entry *service.AnonymousResult) error {
+	args := m.Called(ctx, token, entry)
 	return args.Error(0)
 }
 
-func (m *MockAnonymousResultCacheService) Get(ctx context.Context, requestID string) (*dto.CheckAnswerResponse, error) {
-	args := m.Called(ctx, requestID)
+func (m *MockAnonymousResultCacheService) Get(ctx context.Context, token string) (*service.AnonymousResult, error) {
+	args := m.Called(ctx, token)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*dto.CheckAnswerResponse), args.Error(1)
+	return args.Get(0).(*service.AnonymousResult), args.Error(1)
+}
+
+func (m *MockAnonymousResultCacheService) Claim(ctx context.Context, token string) (*service.AnonymousResult, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*service.AnonymousResult), args.Error(1)
 }
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReviewHandler lets admins review drafted quizzes and move quizzes between statuses.
+type ReviewHandler struct {
+	reviewService service.ReviewService
+}
+
+func NewReviewHandler(reviewService service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService}
+}
+
+// ListReviewQueue lists the quizzes waiting for review.
+// @Summary List The Review Queue
+// @Description Lists draft and in-review quizzes, oldest change first. Pass status to list the quizzes in one status instead. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param status query string false "Quiz status (draft, in_review, published, retired)"
+// @Param limit query int false "Maximum number of quizzes (default 50, max 200)"
+// @Success 200 {object} dto.ReviewQueueResponse
+// @Failure 400 {object} middleware.ErrorResponse "Invalid status or limit"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Router /admin/review/queue [get]
+func (h *ReviewHandler) ListReviewQueue(c *fiber.Ctx) error {
+	limit, err := strconv.Atoi(c.Query("limit", "0"))
+	if err != nil || limit < 0 {
+		return domain.NewValidationError(fmt.Sprintf("invalid limit %q", c.Query("limit")))
+	}
+	resp, err := h.reviewService.ListReviewQueue(c.Context(), c.Query("status"), limit)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// ApproveQuiz publishes a quiz waiting for review.
+// @Summary Approve A Quiz
+// @Description Publishes a draft or in-review quiz so it is served to users. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Param body body dto.ReviewDecisionRequest false "Review comment"
+// @Success 200 {object} dto.QuizDetailResponse
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz not found"
+// @Failure 409 {object} middleware.ErrorResponse "Quiz not waiting for review"
+// @Router /admin/review/quizzes/{id}/approve [post]
+func (h *ReviewHandler) ApproveQuiz(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+	var req dto.ReviewDecisionRequest
+	if len(c.Body()) > 0 && !parseBody(c, &req) {
+		return nil
+	}
+	resp, err := h.reviewService.ApproveQuiz(c.Context(), c.Params("id"), req.Comment, claims.UserID)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// RejectQuiz retires a quiz waiting for review.
+// @Summary Reject A Quiz
+// @Description Retires a draft or in-review quiz. The comment explaining the rejection is required. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Param body body dto.ReviewDecisionRequest true "Review comment"
+// @Success 200 {object} dto.QuizDetailResponse
+// @Failure 400 {object} middleware.ErrorResponse "Comment missing"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz not found"
+// @Failure 409 {object} middleware.ErrorResponse "Quiz not waiting for review"
+// @Router /admin/review/quizzes/{id}/reject [post]
+func (h *ReviewHandler) RejectQuiz(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+	var req dto.ReviewDecisionRequest
+	if !parseBody(c, &req) {
+		return nil
+	}
+	resp, err := h.reviewService.RejectQuiz(c.Context(), c.Params("id"), req.Comment, claims.UserID)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// EditQuiz saves a reviewer's edit of a quiz waiting for review.
+// @Summary Edit A Quiz In Review
+// @Description Saves the edit as a new revision and moves the quiz to in_review. The revision must match the current one. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Param body body dto.ReviewEditRequest true "Quiz and review comment"
+// @Success 200 {object} dto.QuizDetailResponse
+// @Failure 400 {object} middleware.ValidationErrorResponse "Invalid quiz"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz not found"
+// @Failure 409 {object} middleware.ErrorResponse "Stale revision or quiz not waiting for review"
+// @Router /admin/review/quizzes/{id} [put]
+func (h *ReviewHandler) EditQuiz(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+	var req dto.ReviewEditRequest
+	if !parseBody(c, &req) {
+		return nil
+	}
+	resp, err := h.reviewService.EditQuiz(c.Context(), c.Params("id"), &req, claims.UserID)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// ChangeQuizStatus moves a quiz to another status.
+// @Summary Change A Quiz Status
+// @Description Moves a quiz to a status its current status allows, e.g. retires a published quiz or drafts a retired one again. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Param body body dto.QuizStatusRequest true "Status and comment"
+// @Success 200 {object} dto.QuizDetailResponse
+// @Failure 400 {object} middleware.ErrorResponse "Unknown status"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz not found"
+// @Failure 409 {object} middleware.ErrorResponse "Transition not allowed"
+// @Router /admin/quizzes/{id}/status [post]
+func (h *ReviewHandler) ChangeQuizStatus(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+	var req dto.QuizStatusRequest
+	if !parseBody(c, &req) {
+		return nil
+	}
+	resp, err := h.reviewService.ChangeQuizStatus(c.Context(), c.Params("id"), &req, claims.UserID)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// GetQuizStatusHistory lists the status changes of a quiz.
+// @Summary Get A Quiz Status History
+// @Description Lists the audited status changes of a quiz, oldest first, with who made them and why. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Quiz ID"
+// @Success 200 {object} dto.QuizStatusHistoryResponse
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Quiz not found"
+// @Router /admin/quizzes/{id}/status-history [get]
+func (h *ReviewHandler) GetQuizStatusHistory(c *fiber.Ctx) error {
+	resp, err := h.reviewService.GetQuizStatusHistory(c.Context(), c.Params("id"))
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
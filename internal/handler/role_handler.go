@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/middleware"
+	"quiz-byte/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RoleHandler lets admins manage who holds a role. The first admin is granted with cmd/roles.
+type RoleHandler struct {
+	roleService service.RoleService
+}
+
+func NewRoleHandler(roleService service.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+// ListRoleMembers lists the users with a role.
+// @Summary List Role Members
+// @Description Lists the users a role was granted to, in the order it was granted. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param role path string true "Role name, e.g. admin"
+// @Success 200 {object} dto.RoleMemberListResponse
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Role not found"
+// @Router /admin/roles/{role}/members [get]
+func (h *RoleHandler) ListRoleMembers(c *fiber.Ctx) error {
+	resp, err := h.roleService.ListRoleMembers(c.Context(), c.Params("role"))
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	return c.JSON(resp)
+}
+
+// GrantRole grants a role to a user.
+// @Summary Grant A Role
+// @Description Grants the role to the user with the email or user ID; a user without a verified email has no email and is named by ID. The user has to have signed in once, and gets the role with their next token refresh. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param role path string true "Role name, e.g. admin"
+// @Param body body dto.RoleMemberRequest true "User to grant the role to"
+// @Success 200 {object} dto.MessageResponse
+// @Failure 400 {object} middleware.ErrorResponse "Invalid request body"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "Role or user not found"
+// @Router /admin/roles/{role}/members [post]
+func (h *RoleHandler) GrantRole(c *fiber.Ctx) error {
+	claims, ok := authClaims(c)
+	if !ok {
+		return nil
+	}
+	var req dto.RoleMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(middleware.ErrorResponse{
+			Code: "INVALID_REQUEST_BODY", Message: "Invalid request body", Status: fiber.StatusBadRequest,
+		})
+	}
+
+	role := c.Params("role")
+	granted, err := h.roleService.GrantRole(c.Context(), req, role, claims.UserID)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	if !granted {
+		return c.JSON(dto.MessageResponse{Message: "User already has the " + role + " role."})
+	}
+	return c.JSON(dto.MessageResponse{Message: "Role " + role + " granted."})
+}
+
+// RevokeRole takes a role from a user.
+// @Summary Revoke A Role
+// @Description Takes the role from the user with the email or user ID; their access tokens keep it until they expire. Requires the admin role.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Produce json
+// @Param role path string true "Role name, e.g. admin"
+// @Param email query string false "Email of the user"
+// @Param user_id query string false "ID of the user, for users without an email"
+// @Success 204 "Role revoked"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Failure 403 {object} middleware.ErrorResponse "Admin role required"
+// @Failure 404 {object} middleware.ErrorResponse "User not found or without the role"
+// @Router /admin/roles/{role}/members [delete]
+func (h *RoleHandler) RevokeRole(c *fiber.Ctx) error {
+	role := c.Params("role")
+	member := dto.RoleMemberRequest{Email: c.Query("email"), UserID: c.Query("user_id")}
+	revoked, err := h.roleService.RevokeRole(c.Context(), member, role)
+	if err != nil {
+		return err // Handled by the global error handler
+	}
+	if !revoked {
+		return c.Status(fiber.StatusNotFound).JSON(middleware.ErrorResponse{
+			Code: "ROLE_NOT_GRANTED", Message: "User does not have the " + role + " role", Status: fiber.StatusNotFound,
+		})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"quiz-byte/internal/domain"
 	"quiz-byte/internal/dto"
 	"quiz-byte/internal/logger"     // Added
 	"quiz-byte/internal/middleware" // For UserIDKey and ErrorResponse
@@ -16,11 +17,12 @@ import (
 )
 
 type UserHandler struct {
-	userService service.UserService
+	userService   service.UserService
+	attemptClaims service.AttemptClaimService
 }
 
-func NewUserHandler(userService service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService service.UserService, attemptClaims service.AttemptClaimService) *UserHandler {
+	return &UserHandler{userService: userService, attemptClaims: attemptClaims}
 }
 
 // GetMyProfile retrieves the profile of the currently authenticated user.
@@ -268,3 +270,43 @@ func (h *UserHandler) GetMyRecommendations(c *fiber.Ctx) error {
 
 	return c.JSON(recommendations)
 }
+
+// ClaimMyAttempts records results graded before login as attempts of the authenticated user.
+// @Summary Claim Anonymous Results
+// @Description Turns the result_token values returned to anonymous callers of POST /quiz/check into quiz attempts of the logged-in user.
+// @Description Each token can be claimed once; the outcome of every token is reported individually.
+// @Tags users
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.ClaimAttemptsRequest true "Result tokens to claim"
+// @Success 200 {object} dto.ClaimAttemptsResponse
+// @Failure 400 {object} middleware.ErrorResponse "Invalid request"
+// @Failure 401 {object} middleware.ErrorResponse "Unauthorized"
+// @Router /users/me/attempts/claim [post]
+func (h *UserHandler) ClaimMyAttempts(c *fiber.Ctx) error {
+	appLogger := logger.Get()
+	userID, ok := c.Locals(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		appLogger.Warn("User ID not found in context for ClaimMyAttempts", zap.String("path", c.Path()))
+		return c.Status(fiber.StatusUnauthorized).JSON(middleware.ErrorResponse{
+			Code: "INVALID_USER_CONTEXT", Message: "User ID not found in context", Status: fiber.StatusUnauthorized,
+		})
+	}
+
+	var req dto.ClaimAttemptsRequest
+	if err := c.BodyParser(&req); err != nil {
+		appLogger.Warn("Failed to parse request body for ClaimMyAttempts", zap.Error(err))
+		return domain.NewValidationError("Invalid request body format")
+	}
+
+	response, err := h.attemptClaims.ClaimAttempts(c.Context(), userID, req.ResultTokens)
+	if err != nil {
+		return err
+	}
+	appLogger.Info("Anonymous results claimed",
+		zap.String("userID", userID),
+		zap.Int("requested", len(req.ResultTokens)),
+		zap.Int("claimed", response.Claimed))
+	return c.JSON(response)
+}
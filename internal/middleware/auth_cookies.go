@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"net/url"
+	"time"
+
+	"quiz-byte/internal/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// Cookies of the cookie auth mode. The CSRF cookie is readable by the frontend, which sends its
+// value back in CSRFHeader (double-submit); the token cookies are HttpOnly.
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	CSRFCookie         = "csrf_token"
+	CSRFHeader         = "X-CSRF-Token"
+)
+
+// AuthCookies sets and clears the token cookies of the cookie auth mode.
+type AuthCookies struct {
+	cfg        config.AuthCookieConfig
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewAuthCookies creates AuthCookies whose cookies expire with the tokens they hold.
+func NewAuthCookies(cfg config.AuthConfig) *AuthCookies {
+	return &AuthCookies{cfg: cfg.Cookie, accessTTL: cfg.JWT.AccessTokenTTL, refreshTTL: cfg.JWT.RefreshTokenTTL}
+}
+
+// Set stores the tokens in cookies along with a new CSRF token.
+func (a *AuthCookies) Set(c *fiber.Ctx, accessToken string, refreshToken string) {
+	now := time.Now()
+	a.setCookie(c, AccessTokenCookie, accessToken, now.Add(a.accessTTL), true)
+	a.setCookie(c, RefreshTokenCookie, refreshToken, now.Add(a.refreshTTL), true)
+	a.setCookie(c, CSRFCookie, rand.Text(), now.Add(a.refreshTTL), false)
+}
+
+// Clear expires the token and CSRF cookies.
+func (a *AuthCookies) Clear(c *fiber.Ctx) {
+	expired := time.Now().Add(-time.Hour)
+	a.setCookie(c, AccessTokenCookie, "", expired, true)
+	a.setCookie(c, RefreshTokenCookie, "", expired, true)
+	a.setCookie(c, CSRFCookie, "", expired, false)
+}
+
+func (a *AuthCookies) setCookie(c *fiber.Ctx, name string, value string, expires time.Time, httpOnly bool) {
+	c.Cookie(&fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Domain:   a.cfg.Domain,
+		Path:     "/",
+		Expires:  expires,
+		HTTPOnly: httpOnly,
+		Secure:   !a.cfg.Insecure,
+		SameSite: a.cfg.SameSite,
+	})
+}
+
+// ValidCSRF reports whether the request carries the CSRF cookie and the same value in CSRFHeader.
+// Safe methods (GET, HEAD, OPTIONS) do not change state and always pass.
+func ValidCSRF(c *fiber.Ctx) bool {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return true
+	}
+	cookie, header := c.Cookies(CSRFCookie), c.Get(CSRFHeader)
+	return cookie != "" && subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+// csrfErrorResponse writes the 403 response for a cookie-authenticated request without a valid CSRF token.
+func csrfErrorResponse(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+		Code:    "INVALID_CSRF_TOKEN",
+		Message: "CSRF token is missing or does not match; send the csrf_token cookie value in the " + CSRFHeader + " header",
+		Status:  fiber.StatusForbidden,
+	})
+}
+
+// CORSConfig returns the CORS settings of the auth mode. Browsers only send cookies to another
+// origin with credentials allowed, which requires naming the frontend's origin instead of "*".
+func CORSConfig(cfg config.AuthConfig) cors.Config {
+	corsCfg := cors.Config{
+		AllowOrigins: "*",
+		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
+		AllowHeaders: "Origin,Content-Type,Accept,Authorization," + CSRFHeader,
+		MaxAge:       300,
+	}
+	if cfg.Mode != config.AuthModeCookie {
+		return corsCfg
+	}
+	if frontend, err := url.Parse(cfg.Cookie.FrontendURL); err == nil && frontend.Host != "" {
+		corsCfg.AllowOrigins = frontend.Scheme + "://" + frontend.Host
+		corsCfg.AllowCredentials = true
+	}
+	return corsCfg
+}
@@ -14,20 +14,29 @@ import (
 const (
 	AuthorizationHeader = "Authorization"
 	BearerSchema        = "Bearer "
-	UserIDKey           = "userID" // Key for storing UserID in fiber.Ctx locals
+	UserIDKey           = "userID"     // Key for storing UserID in fiber.Ctx locals
+	AuthClaimsKey       = "authClaims" // Key for storing the validated *dto.AuthClaims in fiber.Ctx locals
 )
 
 // Protected is a middleware function that protects routes by requiring a valid JWT.
-// It validates the token using the provided AuthService and sets the userID in the context.
+// It validates the token using the provided AuthService, rejects revoked tokens and sets the userID in the context.
+// Without an Authorization header the access token cookie of the cookie auth mode is used; such
+// requests must pass the CSRF check unless they are safe (GET, HEAD, OPTIONS).
 func Protected(authService service.AuthService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get(AuthorizationHeader)
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{ // Using existing middleware.ErrorResponse
-				Code:    "MISSING_AUTH_HEADER",
-				Message: "Authorization header is missing",
-				Status:  fiber.StatusUnauthorized,
-			})
+			if c.Cookies(AccessTokenCookie) == "" {
+				return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{ // Using existing middleware.ErrorResponse
+					Code:    "MISSING_AUTH_HEADER",
+					Message: "Authorization header is missing",
+					Status:  fiber.StatusUnauthorized,
+				})
+			}
+			if !ValidCSRF(c) {
+				return csrfErrorResponse(c)
+			}
+			authHeader = BearerSchema + c.Cookies(AccessTokenCookie)
 		}
 
 		if !strings.HasPrefix(authHeader, BearerSchema) {
@@ -67,7 +76,25 @@ func Protected(authService service.AuthService) fiber.Handler {
 			})
 		}
 
+		revoked, err := authService.IsTokenRevoked(c.Context(), claims)
+		if err != nil {
+			logger.Get().Error("Failed to check whether token is revoked", zap.Error(err), zap.String("userID", claims.UserID))
+			return c.Status(fiber.StatusServiceUnavailable).JSON(ErrorResponse{
+				Code:    "TOKEN_CHECK_FAILED",
+				Message: "Could not verify the token, please retry",
+				Status:  fiber.StatusServiceUnavailable,
+			})
+		}
+		if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+				Code:    "TOKEN_REVOKED",
+				Message: "Token has been revoked",
+				Status:  fiber.StatusUnauthorized,
+			})
+		}
+
 		c.Locals(UserIDKey, claims.UserID)
+		c.Locals(AuthClaimsKey, claims)
 
 		return c.Next()
 	}
@@ -76,10 +103,19 @@ func Protected(authService service.AuthService) fiber.Handler {
 // OptionalAuth is a middleware function that optionally authenticates a user.
 // If a valid access token is provided, it sets the userID in the context.
 // Otherwise, it proceeds without setting the userID, allowing for anonymous access.
+// A request authenticated by the access token cookie that fails the CSRF check is rejected
+// rather than served anonymously, so a broken frontend does not silently lose the user.
 func OptionalAuth(authService service.AuthService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get(AuthorizationHeader)
 
+		if authHeader == "" && c.Cookies(AccessTokenCookie) != "" {
+			if !ValidCSRF(c) {
+				return csrfErrorResponse(c)
+			}
+			authHeader = BearerSchema + c.Cookies(AccessTokenCookie)
+		}
+
 		// If no Authorization header, proceed as anonymous
 		if authHeader == "" {
 			return c.Next()
@@ -109,8 +145,14 @@ func OptionalAuth(authService service.AuthService) fiber.Handler {
 			return c.Next()
 		}
 
+		if revoked, err := authService.IsTokenRevoked(c.Context(), claims); err != nil || revoked {
+			logger.Get().Debug("OptionalAuth: Token revoked or revocation check failed, proceeding as anonymous.", zap.Bool("revoked", revoked), zap.Error(err))
+			return c.Next()
+		}
+
 		// If all checks pass, set UserID in locals
 		c.Locals(UserIDKey, claims.UserID)
+		c.Locals(AuthClaimsKey, claims)
 		logger.Get().Debug("OptionalAuth: User authenticated.", zap.String("userID", claims.UserID))
 
 		return c.Next()
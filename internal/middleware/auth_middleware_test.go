@@ -3,13 +3,17 @@ package middleware_test
 import (
 	"context"
 	"errors"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
+	"quiz-byte/internal/config"
 	"quiz-byte/internal/domain"
 	"quiz-byte/internal/dto"
+	"quiz-byte/internal/logger"
 	"quiz-byte/internal/middleware"
 
 	"github.com/gofiber/fiber/v2"
@@ -18,21 +22,44 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+func TestMain(m *testing.M) {
+	if err := logger.Initialize(config.LoggerConfig{}); err != nil {
+		log.Fatalf("Failed to initialize logger for middleware tests: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
 // MockAuthService implements the AuthService interface for testing
 type MockAuthService struct {
 	mock.Mock
 }
 
-func (m *MockAuthService) GetGoogleLoginURL(state string) string {
-	args := m.Called(state)
-	return args.String(0)
+func (m *MockAuthService) IdentityProviders() []string {
+	args := m.Called()
+	return args.Get(0).([]string)
+}
+
+func (m *MockAuthService) LoginURL(ctx context.Context, provider string) (string, *dto.OAuthFlow, error) {
+	args := m.Called(ctx, provider)
+	if args.Get(1) == nil {
+		return args.String(0), nil, args.Error(2)
+	}
+	return args.String(0), args.Get(1).(*dto.OAuthFlow), args.Error(2)
 }
 
-func (m *MockAuthService) HandleGoogleCallback(ctx context.Context, code string, receivedState string, expectedState string) (string, string, *dto.AuthenticatedUser, error) {
-	args := m.Called(ctx, code, receivedState, expectedState)
+func (m *MockAuthService) HandleOAuthCallback(ctx context.Context, provider string, code string, receivedState string, flow dto.OAuthFlow, client dto.SessionClientInfo) (string, string, *dto.AuthenticatedUser, error) {
+	args := m.Called(ctx, provider, code, receivedState, flow, client)
 	return args.String(0), args.String(1), args.Get(2).(*dto.AuthenticatedUser), args.Error(3)
 }
 
+func (m *MockAuthService) ListIdentities(ctx context.Context, userID string) (*dto.IdentityListResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.IdentityListResponse), args.Error(1)
+}
+
 func (m *MockAuthService) CreateJWT(ctx context.Context, user *domain.User, ttl time.Duration, tokenType string) (string, error) {
 	args := m.Called(ctx, user, ttl, tokenType)
 	return args.String(0), args.Error(1)
@@ -53,6 +80,49 @@ func (m *MockAuthService) RefreshToken(ctx context.Context, refreshTokenString s
 	return args.String(0), args.String(1), args.Error(2)
 }
 
+func (m *MockAuthService) Logout(ctx context.Context, claims *dto.AuthClaims) error {
+	args := m.Called(ctx, claims)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) IsTokenRevoked(ctx context.Context, claims *dto.AuthClaims) (bool, error) {
+	args := m.Called(ctx, claims)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAuthService) ListSessions(ctx context.Context, userID string, currentSessionID string) (*dto.SessionListResponse, error) {
+	args := m.Called(ctx, userID, currentSessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.SessionListResponse), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) RevokeOtherSessions(ctx context.Context, userID string, currentSessionID string) (int, error) {
+	args := m.Called(ctx, userID, currentSessionID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAuthService) JWKS() *dto.JWKSet {
+	args := m.Called()
+	return args.Get(0).(*dto.JWKSet)
+}
+
+func (m *MockAuthService) RefreshExpiringGoogleTokens(ctx context.Context, expiringBefore time.Time, limit int) (int, error) {
+	args := m.Called(ctx, expiringBefore, limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAuthService) DeleteAccount(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
 func (m *MockAuthService) ValidateJWT(ctx context.Context, token string) (*dto.AuthClaims, error) {
 	args := m.Called(ctx, token)
 	if args.Get(0) == nil {
@@ -72,6 +142,7 @@ func TestJWTAuthMiddleware_ValidToken(t *testing.T) {
 	}
 
 	mockAuthService.On("ValidateJWT", mock.Anything, "valid-token").Return(claims, nil)
+	mockAuthService.On("IsTokenRevoked", mock.Anything, claims).Return(false, nil)
 
 	app := fiber.New()
 	app.Use(middleware.Protected(mockAuthService))
@@ -143,3 +214,156 @@ func TestJWTAuthMiddleware_InvalidAuthorizationHeader(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 	mockAuthService.AssertNotCalled(t, "ValidateJWT")
 }
+
+func TestJWTAuthMiddleware_RevokedToken(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+	claims := &dto.AuthClaims{UserID: "test-user-id", TokenType: "access", SessionID: "session-1"}
+	mockAuthService.On("ValidateJWT", mock.Anything, "revoked-token").Return(claims, nil)
+	mockAuthService.On("IsTokenRevoked", mock.Anything, claims).Return(true, nil)
+
+	app := fiber.New()
+	app.Use(middleware.Protected(mockAuthService))
+	app.Get("/test", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "ok"})
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer revoked-token")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestJWTAuthMiddleware_AccessTokenCookie(t *testing.T) {
+	claims := &dto.AuthClaims{UserID: "test-user-id", TokenType: "access", SessionID: "session-1"}
+	newApp := func() (*fiber.App, *MockAuthService) {
+		mockAuthService := new(MockAuthService)
+		mockAuthService.On("ValidateJWT", mock.Anything, "cookie-token").Return(claims, nil)
+		mockAuthService.On("IsTokenRevoked", mock.Anything, claims).Return(false, nil)
+		app := fiber.New()
+		app.Use(middleware.Protected(mockAuthService))
+		app.All("/test", func(c *fiber.Ctx) error {
+			return c.JSON(fiber.Map{"userID": c.Locals(middleware.UserIDKey)})
+		})
+		return app, mockAuthService
+	}
+	cookieRequest := func(method string, csrfCookie string, csrfHeader string) *http.Request {
+		req := httptest.NewRequest(method, "/test", nil)
+		req.AddCookie(&http.Cookie{Name: middleware.AccessTokenCookie, Value: "cookie-token"})
+		if csrfCookie != "" {
+			req.AddCookie(&http.Cookie{Name: middleware.CSRFCookie, Value: csrfCookie})
+		}
+		if csrfHeader != "" {
+			req.Header.Set(middleware.CSRFHeader, csrfHeader)
+		}
+		return req
+	}
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+	}{
+		{"GET Without CSRF Token", cookieRequest("GET", "", ""), http.StatusOK},
+		{"POST With Matching CSRF Token", cookieRequest("POST", "csrf-1", "csrf-1"), http.StatusOK},
+		{"POST Without CSRF Header", cookieRequest("POST", "csrf-1", ""), http.StatusForbidden},
+		{"POST With Mismatching CSRF Token", cookieRequest("POST", "csrf-1", "csrf-2"), http.StatusForbidden},
+		{"POST Without CSRF Cookie", cookieRequest("POST", "", "csrf-1"), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, mockAuthService := newApp()
+			resp, err := app.Test(tt.req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantStatus == http.StatusForbidden {
+				mockAuthService.AssertNotCalled(t, "ValidateJWT")
+			}
+		})
+	}
+}
+
+func TestJWTAuthMiddleware_BearerNeedsNoCSRFToken(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+	claims := &dto.AuthClaims{UserID: "test-user-id", TokenType: "access", SessionID: "session-1"}
+	mockAuthService.On("ValidateJWT", mock.Anything, "valid-token").Return(claims, nil)
+	mockAuthService.On("IsTokenRevoked", mock.Anything, claims).Return(false, nil)
+
+	app := fiber.New()
+	app.Use(middleware.Protected(mockAuthService))
+	app.Post("/test", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "ok"})
+	})
+
+	// The header wins over a cookie, so a stale browser cookie does not demand a CSRF token.
+	req := httptest.NewRequest("POST", "/test", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	req.AddCookie(&http.Cookie{Name: middleware.AccessTokenCookie, Value: "cookie-token"})
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestOptionalAuth_CookieNeedsCSRFToken(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+
+	app := fiber.New()
+	app.Use(middleware.OptionalAuth(mockAuthService))
+	app.Post("/test", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "ok"})
+	})
+
+	req := httptest.NewRequest("POST", "/test", nil)
+	req.AddCookie(&http.Cookie{Name: middleware.AccessTokenCookie, Value: "cookie-token"})
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	mockAuthService.AssertNotCalled(t, "ValidateJWT")
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		roles      []string
+		wantStatus int
+	}{
+		{"Admin", []string{"admin"}, http.StatusOK},
+		{"No Roles", nil, http.StatusForbidden},
+		{"Other Role", []string{"editor"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuthService := new(MockAuthService)
+			claims := &dto.AuthClaims{UserID: "test-user-id", TokenType: "access", SessionID: "session-1", Roles: tt.roles}
+			mockAuthService.On("ValidateJWT", mock.Anything, "valid-token").Return(claims, nil)
+			mockAuthService.On("IsTokenRevoked", mock.Anything, claims).Return(false, nil)
+
+			app := fiber.New()
+			app.Get("/admin", middleware.Protected(mockAuthService), middleware.RequireRole("admin"), func(c *fiber.Ctx) error {
+				return c.JSON(fiber.Map{"status": "ok"})
+			})
+
+			req := httptest.NewRequest("GET", "/admin", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestRequireRole_WithoutProtected(t *testing.T) {
+	app := fiber.New()
+	app.Get("/admin", middleware.RequireRole("admin"), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "ok"})
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/admin", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -98,6 +98,23 @@ func ErrorHandler() fiber.ErrorHandler {
 	}
 }
 
+// ErrorResponseFor builds the response ErrorHandler would send for err. It is used where an
+// error has to be reported inside an already started response, such as an event stream.
+func ErrorResponseFor(err error) ErrorResponse {
+	if _, ok := err.(domain.ValidationErrors); ok {
+		return ErrorResponse{Code: string(domain.CodeValidation), Message: "Request validation failed", Status: http.StatusBadRequest}
+	}
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) {
+		response := ErrorResponse{Code: string(domainErr.Code), Message: domainErr.Message, Status: mapDomainErrorToHTTPStatus(domainErr)}
+		if len(domainErr.Context) > 0 {
+			response.Details = domainErr.Context
+		}
+		return response
+	}
+	return ErrorResponse{Code: string(domain.CodeInternal), Message: "Internal server error", Status: http.StatusInternalServerError}
+}
+
 // mapDomainErrorToHTTPStatus maps domain errors to HTTP status codes
 func mapDomainErrorToHTTPStatus(err *domain.DomainError) int {
 	switch err.Code {
@@ -108,8 +125,12 @@ func mapDomainErrorToHTTPStatus(err *domain.DomainError) int {
 		return http.StatusBadRequest
 	case domain.CodeUnauthorized:
 		return http.StatusUnauthorized
-	case domain.CodeLLMServiceError:
+	case domain.CodeConflict:
+		return http.StatusConflict
+	case domain.CodeLLMServiceError, domain.CodeGradingQueueFull:
 		return http.StatusServiceUnavailable
+	case domain.CodeEvaluationTimeout:
+		return http.StatusGatewayTimeout
 	default:
 		return http.StatusInternalServerError
 	}
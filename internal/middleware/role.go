@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// RequireRole guards routes that need a role, e.g. RequireRole(domain.RoleAdmin). It checks the
+// claims stored by Protected, so it must run after it.
+func RequireRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals(AuthClaimsKey).(*dto.AuthClaims)
+		if !ok || claims == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+				Code:    "UNAUTHORIZED",
+				Message: "Authentication required",
+				Status:  fiber.StatusUnauthorized,
+			})
+		}
+		if !claims.HasRole(role) {
+			logger.Get().Warn("Access denied for missing role",
+				zap.String("userID", claims.UserID), zap.String("role", role), zap.String("path", c.Path()))
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+				Code:    "INSUFFICIENT_ROLE",
+				Message: "This action requires the " + role + " role",
+				Status:  fiber.StatusForbidden,
+			})
+		}
+		return c.Next()
+	}
+}
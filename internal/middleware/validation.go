@@ -3,6 +3,7 @@ package middleware
 import (
 	"quiz-byte/internal/domain"
 	"quiz-byte/internal/validation"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -32,8 +33,19 @@ func (vm *ValidationMiddleware) ValidateSubCategory() fiber.Handler {
 			return errors // This will be handled by ErrorHandler middleware
 		}
 
-		// Store validated value in context for handlers to use
+		// Optional filters
+		difficulty := strings.TrimSpace(c.Query("difficulty"))
+		keywords := validation.SplitCommaList(c.Query("keywords"))
+		excludeIDs := validation.SplitCommaList(c.Query("exclude_ids"))
+		if errors := vm.validator.ValidateRandomQuizFilters(difficulty, keywords, excludeIDs); len(errors) > 0 {
+			return errors
+		}
+
+		// Store validated values in context for handlers to use
 		c.Locals("validated_sub_category", subCategory)
+		c.Locals("validated_difficulty", difficulty)
+		c.Locals("validated_keywords", keywords)
+		c.Locals("validated_exclude_ids", excludeIDs)
 		return c.Next()
 	}
 }
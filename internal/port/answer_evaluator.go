@@ -1,8 +1,30 @@
 package port
 
-import "quiz-byte/internal/domain"
+import (
+	"context"
+	"quiz-byte/internal/domain"
+)
+
+// EvaluationInput is everything an evaluator grades against.
+type EvaluationInput struct {
+	Question     string
+	ModelAnswers []string // All accepted model answers; the user only has to match one of them
+	UserAnswer   string
+	Keywords     []string
+	Rubric       *domain.QuizEvaluation // Optional grading rubric stored for the quiz
+}
 
 // AnswerEvaluator defines the interface for evaluating user answers
 type AnswerEvaluator interface {
-	EvaluateAnswer(questionText string, modelAnswer string, userAnswer string, keywords []string) (*domain.Answer, error)
+	// EvaluateAnswer grades input.UserAnswer and must stop once ctx is done.
+	// A deadline overrun is reported as a domain EVALUATION_TIMEOUT error.
+	EvaluateAnswer(ctx context.Context, input EvaluationInput) (*domain.Answer, error)
+}
+
+// StreamingAnswerEvaluator is an AnswerEvaluator that can report the explanation while it is generated.
+type StreamingAnswerEvaluator interface {
+	AnswerEvaluator
+	// EvaluateAnswerStream behaves like EvaluateAnswer and calls onExplanation with each new
+	// piece of the explanation text as it arrives.
+	EvaluateAnswerStream(ctx context.Context, input EvaluationInput, onExplanation func(delta string)) (*domain.Answer, error)
 }
@@ -0,0 +1,50 @@
+package port
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrCodeExchange is wrapped by Exchange errors caused by the provider rejecting the authorization
+// code, as opposed to failures to read the account afterwards.
+var ErrCodeExchange = errors.New("failed to exchange authorization code")
+
+// ErrInvalidIDToken is wrapped by Exchange errors caused by an id_token that fails verification,
+// e.g. a bad signature, another audience or a nonce that does not match the login.
+var ErrInvalidIDToken = errors.New("invalid id_token")
+
+// AuthRequest holds the per-login values that tie the callback to the login that started it.
+type AuthRequest struct {
+	State        string // Returned unchanged in the callback
+	Nonce        string // Must come back in the id_token of OpenID Connect providers
+	CodeVerifier string // PKCE (RFC 7636) secret; only its S256 challenge is sent with the consent request
+}
+
+// ExternalIdentity is the account a user signed in with at an identity provider.
+type ExternalIdentity struct {
+	Provider      string // Name of the IdentityProvider, e.g. "google"
+	Subject       string // The provider's stable, unique ID of the account
+	Email         string
+	EmailVerified bool // Only verified emails are used to link the identity to an existing user
+	Name          string
+	PictureURL    string
+}
+
+// IdentityProvider signs users in with an external OAuth2 or OpenID Connect provider.
+type IdentityProvider interface {
+	// Name identifies the provider in the login routes and in stored identities.
+	Name() string
+	// AuthCodeURL returns the provider's consent page URL the user is redirected to.
+	AuthCodeURL(ctx context.Context, req AuthRequest) (string, error)
+	// Exchange trades the authorization code from the callback for the provider's tokens
+	// and the signed-in account. req is the request the consent page URL was built for.
+	Exchange(ctx context.Context, code string, req AuthRequest) (*ExternalIdentity, *oauth2.Token, error)
+}
+
+// TokenRefresher is implemented by identity providers whose tokens are stored and renewed in the background.
+type TokenRefresher interface {
+	// RefreshToken returns a new token for the refresh token. The returned RefreshToken may equal the given one.
+	RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error)
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/repository/models"
+	"quiz-byte/internal/util"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// maxOutboxErrorLength matches the size of attempt_outbox.last_error.
+const maxOutboxErrorLength = 1000
+
+// sqlxAttemptOutboxRepository implements domain.AttemptOutboxRepository using sqlx.
+// Every statement joins the transaction in ctx, if any, so an entry can be marked done
+// in the same transaction that records its attempt.
+type sqlxAttemptOutboxRepository struct {
+	db DBTX
+}
+
+// NewSQLXAttemptOutboxRepository creates a new instance of sqlxAttemptOutboxRepository.
+func NewSQLXAttemptOutboxRepository(db *sqlx.DB) domain.AttemptOutboxRepository {
+	return &sqlxAttemptOutboxRepository{db: db}
+}
+
+// attemptPayload is the JSON stored in attempt_outbox.payload.
+type attemptPayload struct {
+	UserAnswer     string    `json:"user_answer"`
+	Score          float64   `json:"score"`
+	Explanation    string    `json:"explanation"`
+	KeywordMatches []string  `json:"keyword_matches"`
+	Completeness   float64   `json:"completeness"`
+	Relevance      float64   `json:"relevance"`
+	Accuracy       float64   `json:"accuracy"`
+	IsCorrect      bool      `json:"is_correct"`
+	QuizRevision   int       `json:"quiz_revision,omitempty"`
+	AttemptedAt    time.Time `json:"attempted_at"`
+}
+
+func fromDomainAttemptOutboxEntry(entry *domain.AttemptOutboxEntry) (*models.AttemptOutboxEntry, error) {
+	payload, err := json.Marshal(attemptPayload{
+		UserAnswer:     entry.Attempt.UserAnswer,
+		Score:          entry.Attempt.LLMScore,
+		Explanation:    entry.Attempt.LLMExplanation,
+		KeywordMatches: entry.Attempt.LLMKeywordMatches,
+		Completeness:   entry.Attempt.LLMCompleteness,
+		Relevance:      entry.Attempt.LLMRelevance,
+		Accuracy:       entry.Attempt.LLMAccuracy,
+		IsCorrect:      entry.Attempt.IsCorrect,
+		QuizRevision:   entry.Attempt.QuizRevision,
+		AttemptedAt:    entry.Attempt.AttemptedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode attempt outbox payload: %w", err)
+	}
+	return &models.AttemptOutboxEntry{
+		ID:             entry.ID,
+		IdempotencyKey: entry.IdempotencyKey,
+		UserID:         entry.Attempt.UserID,
+		QuizID:         entry.Attempt.QuizID,
+		Payload:        string(payload),
+		Status:         entry.Status,
+		Tries:          entry.Tries,
+		NextAttemptAt:  entry.NextAttemptAt,
+		LastError:      util.StringToNullString(entry.LastError),
+		CreatedAt:      entry.CreatedAt,
+	}, nil
+}
+
+func toDomainAttemptOutboxEntry(model *models.AttemptOutboxEntry) (*domain.AttemptOutboxEntry, error) {
+	var payload attemptPayload
+	if err := json.Unmarshal([]byte(model.Payload), &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode payload of attempt outbox entry %s: %w", model.ID, err)
+	}
+	var processedAt *time.Time
+	if model.ProcessedAt.Valid {
+		processedAt = &model.ProcessedAt.Time
+	}
+	return &domain.AttemptOutboxEntry{
+		ID:             model.ID,
+		IdempotencyKey: model.IdempotencyKey,
+		Attempt: domain.UserQuizAttempt{
+			ID:                model.ID,
+			UserID:            model.UserID,
+			QuizID:            model.QuizID,
+			UserAnswer:        payload.UserAnswer,
+			LLMScore:          payload.Score,
+			LLMExplanation:    payload.Explanation,
+			LLMKeywordMatches: payload.KeywordMatches,
+			LLMCompleteness:   payload.Completeness,
+			LLMRelevance:      payload.Relevance,
+			LLMAccuracy:       payload.Accuracy,
+			IsCorrect:         payload.IsCorrect,
+			QuizRevision:      payload.QuizRevision,
+			AttemptedAt:       payload.AttemptedAt,
+		},
+		Status:        model.Status,
+		Tries:         model.Tries,
+		NextAttemptAt: model.NextAttemptAt,
+		LastError:     model.LastError.String,
+		CreatedAt:     model.CreatedAt,
+		ProcessedAt:   processedAt,
+	}, nil
+}
+
+// Enqueue inserts the entry unless its idempotency key is already in the outbox.
+func (r *sqlxAttemptOutboxRepository) Enqueue(ctx context.Context, entry *domain.AttemptOutboxEntry) (bool, error) {
+	model, err := fromDomainAttemptOutboxEntry(entry)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if model.CreatedAt.IsZero() {
+		model.CreatedAt = now
+	}
+	if model.NextAttemptAt.IsZero() {
+		model.NextAttemptAt = now
+	}
+	if model.Status == "" {
+		model.Status = domain.OutboxStatusPending
+	}
+
+	query := `MERGE INTO attempt_outbox o
+	          USING (SELECT :1 AS idempotency_key FROM dual) k
+	          ON (o.idempotency_key = k.idempotency_key)
+	          WHEN NOT MATCHED THEN
+	            INSERT (id, idempotency_key, user_id, quiz_id, payload, status, tries, next_attempt_at, created_at, updated_at)
+	            VALUES (:2, :3, :4, :5, :6, :7, :8, :9, :10, :11)`
+
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query,
+		model.IdempotencyKey,
+		model.ID,
+		model.IdempotencyKey,
+		model.UserID,
+		model.QuizID,
+		model.Payload,
+		model.Status,
+		model.Tries,
+		model.NextAttemptAt,
+		model.CreatedAt,
+		now,
+	)
+	if err != nil {
+		// Two concurrent merges of the same key can both miss the existing row; the loser hits the unique constraint.
+		if isUniqueViolation(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to enqueue attempt outbox entry: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// FetchDue returns pending entries that are due, oldest first.
+func (r *sqlxAttemptOutboxRepository) FetchDue(ctx context.Context, now time.Time, limit int) ([]*domain.AttemptOutboxEntry, error) {
+	if limit <= 0 {
+		limit = 1
+	}
+	query := fmt.Sprintf(`SELECT id, idempotency_key, user_id, quiz_id, payload, status, tries, next_attempt_at, last_error, created_at, updated_at, processed_at
+	          FROM attempt_outbox
+	          WHERE status = :1 AND next_attempt_at <= :2
+	          ORDER BY next_attempt_at, id
+	          FETCH FIRST %d ROWS ONLY`, limit)
+
+	var modelEntries []models.AttemptOutboxEntry
+	if err := GetExecutor(ctx, r.db).SelectContext(ctx, &modelEntries, query, domain.OutboxStatusPending, now); err != nil {
+		return nil, fmt.Errorf("failed to fetch due attempt outbox entries: %w", err)
+	}
+
+	entries := make([]*domain.AttemptOutboxEntry, 0, len(modelEntries))
+	for i := range modelEntries {
+		entry, err := toDomainAttemptOutboxEntry(&modelEntries[i])
+		if err != nil {
+			// A payload that cannot be decoded never will be; dead-letter it so it
+			// does not block the rest of the batch on every poll.
+			model := &modelEntries[i]
+			if markErr := r.MarkFailed(ctx, model.ID, model.Tries+1, now, err.Error(), true); markErr != nil {
+				return nil, markErr
+			}
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// MarkDone marks a pending entry as processed.
+func (r *sqlxAttemptOutboxRepository) MarkDone(ctx context.Context, id string) (bool, error) {
+	query := `UPDATE attempt_outbox SET status = :1, processed_at = :2 WHERE id = :3 AND status = :4`
+
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, domain.OutboxStatusDone, time.Now(), id, domain.OutboxStatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark attempt outbox entry %s as done: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// MarkFailed records a failed try of a pending entry.
+func (r *sqlxAttemptOutboxRepository) MarkFailed(ctx context.Context, id string, tries int, nextAttemptAt time.Time, lastError string, giveUp bool) error {
+	status := domain.OutboxStatusPending
+	if giveUp {
+		status = domain.OutboxStatusFailed
+	}
+	if len(lastError) > maxOutboxErrorLength {
+		lastError = strings.ToValidUTF8(lastError[:maxOutboxErrorLength], "")
+	}
+	query := `UPDATE attempt_outbox SET status = :1, tries = :2, next_attempt_at = :3, last_error = :4 WHERE id = :5 AND status = :6`
+
+	if _, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, status, tries, nextAttemptAt, lastError, id, domain.OutboxStatusPending); err != nil {
+		return fmt.Errorf("failed to mark attempt outbox entry %s as failed: %w", id, err)
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err is Oracle's unique constraint violation (ORA-00001).
+func isUniqueViolation(err error) bool {
+	return strings.Contains(err.Error(), "ORA-00001")
+}
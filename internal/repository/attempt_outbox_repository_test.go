@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAttemptOutboxTestDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	return sqlx.NewDb(mockDB, "sqlmock"), mock
+}
+
+func newTestOutboxEntry() *domain.AttemptOutboxEntry {
+	return &domain.AttemptOutboxEntry{
+		ID:             "entry1",
+		IdempotencyKey: "key1",
+		Attempt: domain.UserQuizAttempt{
+			ID:                "entry1",
+			UserID:            "user1",
+			QuizID:            "quiz1",
+			UserAnswer:        "My answer",
+			LLMScore:          0.8,
+			LLMKeywordMatches: []string{"key1"},
+			IsCorrect:         true,
+			AttemptedAt:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}
+}
+
+func TestAttemptOutboxRepository_Enqueue(t *testing.T) {
+	ctx := context.Background()
+	mergeQuery := regexp.QuoteMeta(`MERGE INTO attempt_outbox o`)
+
+	t.Run("Inserted", func(t *testing.T) {
+		db, mock := setupAttemptOutboxTestDB(t)
+		defer db.Close()
+		repo := NewSQLXAttemptOutboxRepository(db)
+
+		mock.ExpectExec(mergeQuery).
+			WithArgs("key1", "entry1", "key1", "user1", "quiz1", sqlmock.AnyArg(), domain.OutboxStatusPending, 0, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		inserted, err := repo.Enqueue(ctx, newTestOutboxEntry())
+		assert.NoError(t, err)
+		assert.True(t, inserted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Duplicate Key", func(t *testing.T) {
+		db, mock := setupAttemptOutboxTestDB(t)
+		defer db.Close()
+		repo := NewSQLXAttemptOutboxRepository(db)
+
+		mock.ExpectExec(mergeQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+		inserted, err := repo.Enqueue(ctx, newTestOutboxEntry())
+		assert.NoError(t, err)
+		assert.False(t, inserted)
+
+		mock.ExpectExec(mergeQuery).WillReturnError(errors.New("ORA-00001: unique constraint (UQ_ATTEMPT_OUTBOX_IDEM_KEY) violated"))
+		inserted, err = repo.Enqueue(ctx, newTestOutboxEntry())
+		assert.NoError(t, err)
+		assert.False(t, inserted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAttemptOutboxRepository_FetchDue(t *testing.T) {
+	db, mock := setupAttemptOutboxTestDB(t)
+	defer db.Close()
+	repo := NewSQLXAttemptOutboxRepository(db)
+	now := time.Now()
+
+	entry := newTestOutboxEntry()
+	model, err := fromDomainAttemptOutboxEntry(entry)
+	assert.NoError(t, err)
+
+	rows := sqlmock.NewRows([]string{"ID", "IDEMPOTENCY_KEY", "USER_ID", "QUIZ_ID", "PAYLOAD", "STATUS", "TRIES", "NEXT_ATTEMPT_AT", "LAST_ERROR", "CREATED_AT", "UPDATED_AT", "PROCESSED_AT"}).
+		AddRow("entry1", "key1", "user1", "quiz1", model.Payload, domain.OutboxStatusPending, 2, now, "db down", now, now, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM attempt_outbox`)+`.*FETCH FIRST 10 ROWS ONLY`).
+		WithArgs(domain.OutboxStatusPending, now).
+		WillReturnRows(rows)
+
+	entries, err := repo.FetchDue(context.Background(), now, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, entry.Attempt, entries[0].Attempt)
+		assert.Equal(t, 2, entries[0].Tries)
+		assert.Equal(t, "db down", entries[0].LastError)
+		assert.Nil(t, entries[0].ProcessedAt)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttemptOutboxRepository_FetchDue_UndecodablePayload(t *testing.T) {
+	db, mock := setupAttemptOutboxTestDB(t)
+	defer db.Close()
+	repo := NewSQLXAttemptOutboxRepository(db)
+	now := time.Now()
+
+	entry := newTestOutboxEntry()
+	model, err := fromDomainAttemptOutboxEntry(entry)
+	assert.NoError(t, err)
+
+	rows := sqlmock.NewRows([]string{"ID", "IDEMPOTENCY_KEY", "USER_ID", "QUIZ_ID", "PAYLOAD", "STATUS", "TRIES", "NEXT_ATTEMPT_AT", "LAST_ERROR", "CREATED_AT", "UPDATED_AT", "PROCESSED_AT"}).
+		AddRow("bad", "key0", "user1", "quiz1", "{not json", domain.OutboxStatusPending, 0, now, nil, now, now, nil).
+		AddRow("entry1", "key1", "user1", "quiz1", model.Payload, domain.OutboxStatusPending, 0, now, nil, now, now, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM attempt_outbox`)+`.*FETCH FIRST 10 ROWS ONLY`).
+		WithArgs(domain.OutboxStatusPending, now).
+		WillReturnRows(rows)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE attempt_outbox SET status = :1, tries = :2, next_attempt_at = :3, last_error = :4 WHERE id = :5 AND status = :6`)).
+		WithArgs(domain.OutboxStatusFailed, 1, now, sqlmock.AnyArg(), "bad", domain.OutboxStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	entries, err := repo.FetchDue(context.Background(), now, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "entry1", entries[0].ID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttemptOutboxRepository_MarkDone(t *testing.T) {
+	db, mock := setupAttemptOutboxTestDB(t)
+	defer db.Close()
+	repo := NewSQLXAttemptOutboxRepository(db)
+	query := regexp.QuoteMeta(`UPDATE attempt_outbox SET status = :1, processed_at = :2 WHERE id = :3 AND status = :4`)
+
+	mock.ExpectExec(query).
+		WithArgs(domain.OutboxStatusDone, sqlmock.AnyArg(), "entry1", domain.OutboxStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	done, err := repo.MarkDone(context.Background(), "entry1")
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	// Already handled by another dispatcher
+	mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+	done, err = repo.MarkDone(context.Background(), "entry1")
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttemptOutboxRepository_MarkFailed(t *testing.T) {
+	db, mock := setupAttemptOutboxTestDB(t)
+	defer db.Close()
+	repo := NewSQLXAttemptOutboxRepository(db)
+	query := regexp.QuoteMeta(`UPDATE attempt_outbox SET status = :1, tries = :2, next_attempt_at = :3, last_error = :4 WHERE id = :5 AND status = :6`)
+	next := time.Now().Add(time.Minute)
+
+	mock.ExpectExec(query).
+		WithArgs(domain.OutboxStatusPending, 1, next, "db down", "entry1", domain.OutboxStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.MarkFailed(context.Background(), "entry1", 1, next, "db down", false))
+
+	mock.ExpectExec(query).
+		WithArgs(domain.OutboxStatusFailed, 10, next, "db down", "entry1", domain.OutboxStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.MarkFailed(context.Background(), "entry1", 10, next, "db down", true))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -42,7 +42,7 @@ func (r *CategoryDatabaseAdapter) GetAllCategories(ctx context.Context) ([]*doma
 func (r *CategoryDatabaseAdapter) GetSubCategories(ctx context.Context, categoryID string) ([]*domain.SubCategory, error) {
 	var subCategories []models.SubCategory
 	query := "SELECT id, category_id, name, description, created_at, updated_at, deleted_at FROM sub_categories WHERE category_id = :1 AND deleted_at IS NULL"
-	err := r.db.SelectContext(ctx, &subCategories, query, categoryID)
+	err := GetExecutor(ctx, r.db).SelectContext(ctx, &subCategories, query, categoryID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return []*domain.SubCategory{}, nil
@@ -66,7 +66,7 @@ func (r *CategoryDatabaseAdapter) SaveCategory(ctx context.Context, category *do
 
 	query := `INSERT INTO categories (id, name, description, created_at, updated_at)
               VALUES (:1, :2, :3, :4, :5)`
-	_, err := r.db.ExecContext(ctx, query, modelCategory.ID, modelCategory.Name, modelCategory.Description, modelCategory.CreatedAt, modelCategory.UpdatedAt)
+	_, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, modelCategory.ID, modelCategory.Name, modelCategory.Description, modelCategory.CreatedAt, modelCategory.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to save category: %w", err)
 	}
@@ -85,7 +85,7 @@ func (r *CategoryDatabaseAdapter) SaveSubCategory(ctx context.Context, subCatego
 
 	query := `INSERT INTO sub_categories (id, category_id, name, description, created_at, updated_at)
               VALUES (:1, :2, :3, :4, :5, :6)`
-	_, err := r.db.ExecContext(ctx, query, modelSubCategory.ID, modelSubCategory.CategoryID, modelSubCategory.Name, modelSubCategory.Description, modelSubCategory.CreatedAt, modelSubCategory.UpdatedAt)
+	_, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, modelSubCategory.ID, modelSubCategory.CategoryID, modelSubCategory.Name, modelSubCategory.Description, modelSubCategory.CreatedAt, modelSubCategory.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to save subcategory: %w", err)
 	}
@@ -101,7 +101,7 @@ func (r *CategoryDatabaseAdapter) GetByName(ctx context.Context, name string) (*
 	var category models.Category
 	// Using NamedArg for Oracle compatibility if needed, otherwise :name or $1 depending on driver
 	query := "SELECT id, name, description, created_at, updated_at FROM categories WHERE name = :1 AND deleted_at IS NULL"
-	err := r.db.GetContext(ctx, &category, query, name) // Use GetContext for context propagation
+	err := GetExecutor(ctx, r.db).GetContext(ctx, &category, query, name) // Use GetContext for context propagation
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Not found is not an application error here
@@ -116,7 +116,7 @@ func (r *CategoryDatabaseAdapter) GetByName(ctx context.Context, name string) (*
 func (r *CategoryDatabaseAdapter) GetByNameAndCategoryID(ctx context.Context, name string, categoryID string) (*domain.SubCategory, error) {
 	var subCategory models.SubCategory
 	query := "SELECT id, category_id, name, description, created_at, updated_at FROM sub_categories WHERE name = :1 AND category_id = :2 AND deleted_at IS NULL"
-	err := r.db.GetContext(ctx, &subCategory, query, name, categoryID) // Use GetContext
+	err := GetExecutor(ctx, r.db).GetContext(ctx, &subCategory, query, name, categoryID) // Use GetContext
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Not found
@@ -126,6 +126,102 @@ func (r *CategoryDatabaseAdapter) GetByNameAndCategoryID(ctx context.Context, na
 	return convertToDomainSubCategory(&subCategory), nil
 }
 
+// GetCategoryByID retrieves an active category by its ID.
+// It returns (nil, nil) if the category is not found.
+func (r *CategoryDatabaseAdapter) GetCategoryByID(ctx context.Context, id string) (*domain.Category, error) {
+	var category models.Category
+	query := "SELECT id, name, description, created_at, updated_at FROM categories WHERE id = :1 AND deleted_at IS NULL"
+	err := GetExecutor(ctx, r.db).GetContext(ctx, &category, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error fetching category by ID %s: %w", id, err)
+	}
+	return convertToDomainCategory(&category), nil
+}
+
+// GetSubCategoryByID retrieves an active subcategory by its ID.
+// It returns (nil, nil) if the subcategory is not found.
+func (r *CategoryDatabaseAdapter) GetSubCategoryByID(ctx context.Context, id string) (*domain.SubCategory, error) {
+	var subCategory models.SubCategory
+	query := "SELECT id, category_id, name, description, created_at, updated_at FROM sub_categories WHERE id = :1 AND deleted_at IS NULL"
+	err := GetExecutor(ctx, r.db).GetContext(ctx, &subCategory, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error fetching subcategory by ID %s: %w", id, err)
+	}
+	return convertToDomainSubCategory(&subCategory), nil
+}
+
+// UpdateCategory saves the name and description of an active category
+func (r *CategoryDatabaseAdapter) UpdateCategory(ctx context.Context, category *domain.Category) error {
+	modelCategory := convertToModelCategory(category)
+	modelCategory.UpdatedAt = time.Now()
+
+	query := "UPDATE categories SET name = :1, description = :2, updated_at = :3 WHERE id = :4 AND deleted_at IS NULL"
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, modelCategory.Name, modelCategory.Description, modelCategory.UpdatedAt, modelCategory.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update category %s: %w", category.ID, err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("category with ID %s not found or not updated", category.ID)
+	}
+	category.UpdatedAt = modelCategory.UpdatedAt
+	return nil
+}
+
+// UpdateSubCategory saves the name and description of an active subcategory
+func (r *CategoryDatabaseAdapter) UpdateSubCategory(ctx context.Context, subCategory *domain.SubCategory) error {
+	modelSubCategory := convertToModelSubCategory(subCategory)
+	modelSubCategory.UpdatedAt = time.Now()
+
+	query := "UPDATE sub_categories SET name = :1, description = :2, updated_at = :3 WHERE id = :4 AND deleted_at IS NULL"
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, modelSubCategory.Name, modelSubCategory.Description, modelSubCategory.UpdatedAt, modelSubCategory.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update subcategory %s: %w", subCategory.ID, err)
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	} else if rowsAffected == 0 {
+		return fmt.Errorf("subcategory with ID %s not found or not updated", subCategory.ID)
+	}
+	subCategory.UpdatedAt = modelSubCategory.UpdatedAt
+	return nil
+}
+
+// DeleteCategory soft deletes a category that has no active subcategories
+func (r *CategoryDatabaseAdapter) DeleteCategory(ctx context.Context, id string) (bool, error) {
+	return r.softDelete(ctx, "categories", id, "sub_categories", "category_id")
+}
+
+// DeleteSubCategory soft deletes a subcategory that has no active quizzes
+func (r *CategoryDatabaseAdapter) DeleteSubCategory(ctx context.Context, id string) (bool, error) {
+	return r.softDelete(ctx, "sub_categories", id, "quizzes", "sub_category_id")
+}
+
+// softDelete sets deleted_at of an active row of table, reporting whether there was one. The row is
+// kept when an active row of childTable still refers to it through parentColumn; checking this in
+// the same statement keeps a child added concurrently from being orphaned.
+func (r *CategoryDatabaseAdapter) softDelete(ctx context.Context, table string, id string, childTable string, parentColumn string) (bool, error) {
+	now := time.Now()
+	query := "UPDATE " + table + " SET deleted_at = :1, updated_at = :2 WHERE id = :3 AND deleted_at IS NULL" +
+		" AND NOT EXISTS (SELECT 1 FROM " + childTable + " WHERE " + parentColumn + " = :4 AND deleted_at IS NULL)"
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, now, now, id, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete %s row %s: %w", table, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
 // Helper functions for converting between domain and model types
 func convertToDomainCategory(category *models.Category) *domain.Category {
 	if category == nil {
@@ -189,6 +189,72 @@ func TestSaveSubCategory(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetCategoryByID_NotFound(t *testing.T) {
+	db, mock := setupCategoryTestDB(t)
+	repo := NewCategoryDatabaseAdapter(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, description, created_at, updated_at FROM categories WHERE id = :1 AND deleted_at IS NULL")).
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	result, err := repo.GetCategoryByID(context.Background(), "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCategory(t *testing.T) {
+	db, mock := setupCategoryTestDB(t)
+	repo := NewCategoryDatabaseAdapter(db)
+	category := &domain.Category{ID: util.NewULID(), Name: "Renamed"}
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE categories SET name = :1, description = :2, updated_at = :3 WHERE id = :4 AND deleted_at IS NULL")).
+		WithArgs("Renamed", sql.NullString{}, sqlmock.AnyArg(), category.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.UpdateCategory(context.Background(), category))
+	assert.NotZero(t, category.UpdatedAt)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE categories SET")).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.Error(t, repo.UpdateCategory(context.Background(), category), "a deleted category is not updated")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSubCategory_SoftDeletes(t *testing.T) {
+	db, mock := setupCategoryTestDB(t)
+	repo := NewCategoryDatabaseAdapter(db)
+	id := util.NewULID()
+
+	query := regexp.QuoteMeta("UPDATE sub_categories SET deleted_at = :1, updated_at = :2 WHERE id = :3 AND deleted_at IS NULL" +
+		" AND NOT EXISTS (SELECT 1 FROM quizzes WHERE sub_category_id = :4 AND deleted_at IS NULL)")
+	mock.ExpectExec(query).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), id, id).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), id, id).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	deleted, err := repo.DeleteSubCategory(context.Background(), id)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = repo.DeleteSubCategory(context.Background(), id)
+	assert.NoError(t, err)
+	assert.False(t, deleted, "an already deleted subcategory or one with quizzes is reported as not deleted")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteCategory_KeepsCategoryWithSubCategories(t *testing.T) {
+	db, mock := setupCategoryTestDB(t)
+	repo := NewCategoryDatabaseAdapter(db)
+	id := util.NewULID()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE categories SET deleted_at = :1, updated_at = :2 WHERE id = :3 AND deleted_at IS NULL"+
+		" AND NOT EXISTS (SELECT 1 FROM sub_categories WHERE category_id = :4 AND deleted_at IS NULL)")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), id, id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	deleted, err := repo.DeleteCategory(context.Background(), id)
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestConvertToDomainCategory(t *testing.T) {
 	now := time.Now()
 	model := &models.Category{
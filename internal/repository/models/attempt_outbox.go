@@ -0,0 +1,22 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AttemptOutboxEntry represents a row of the attempt_outbox table.
+type AttemptOutboxEntry struct {
+	ID             string         `db:"ID"`              // ULID, reused as the ID of the recorded attempt
+	IdempotencyKey string         `db:"IDEMPOTENCY_KEY"` // Unique; repeated keys are dropped on insert
+	UserID         string         `db:"USER_ID"`
+	QuizID         string         `db:"QUIZ_ID"`
+	Payload        string         `db:"PAYLOAD"` // JSON encoded attempt
+	Status         string         `db:"STATUS"`  // pending, done or failed
+	Tries          int            `db:"TRIES"`   // Failed dispatch tries
+	NextAttemptAt  time.Time      `db:"NEXT_ATTEMPT_AT"`
+	LastError      sql.NullString `db:"LAST_ERROR"`
+	CreatedAt      time.Time      `db:"CREATED_AT"`
+	UpdatedAt      time.Time      `db:"UPDATED_AT"`
+	ProcessedAt    sql.NullTime   `db:"PROCESSED_AT"`
+}
@@ -85,11 +85,45 @@ type Quiz struct {
 	Keywords      string       `db:"KEYWORDS"`
 	Difficulty    int          `db:"DIFFICULTY"`
 	SubCategoryID string       `db:"SUB_CATEGORY_ID"`
+	Revision      int          `db:"REVISION"`
+	Status        string       `db:"STATUS"`
 	CreatedAt     time.Time    `db:"CREATED_AT"`
 	UpdatedAt     time.Time    `db:"UPDATED_AT"`
 	DeletedAt     sql.NullTime `db:"DELETED_AT"`
 }
 
+// QuizRevision 모델
+type QuizRevision struct {
+	QuizID        string         `db:"QUIZ_ID"`
+	Revision      int            `db:"REVISION"`
+	Question      string         `db:"QUESTION"`
+	ModelAnswers  string         `db:"MODEL_ANSWERS"`
+	Keywords      string         `db:"KEYWORDS"`
+	Difficulty    int            `db:"DIFFICULTY"`
+	SubCategoryID string         `db:"SUB_CATEGORY_ID"`
+	Author        sql.NullString `db:"AUTHOR"` // NULL 허용 (000009 이전 리비전)
+	CreatedAt     time.Time      `db:"CREATED_AT"`
+}
+
+// QuizStatusChange 모델
+type QuizStatusChange struct {
+	ID            string         `db:"ID"`
+	QuizID        string         `db:"QUIZ_ID"`
+	FromStatus    string         `db:"FROM_STATUS"`
+	ToStatus      string         `db:"TO_STATUS"`
+	Actor         sql.NullString `db:"ACTOR"`          // NULL 허용
+	ReviewComment sql.NullString `db:"REVIEW_COMMENT"` // NULL 허용
+	CreatedAt     time.Time      `db:"CREATED_AT"`
+}
+
+// QuizSeed 모델
+type QuizSeed struct {
+	SeedKey     string    `db:"SEED_KEY"`
+	QuizID      string    `db:"QUIZ_ID"`
+	ContentHash string    `db:"CONTENT_HASH"`
+	SeededAt    time.Time `db:"SEEDED_AT"`
+}
+
 // Answer 모델
 type Answer struct {
 	ID             string       `db:"ID"`
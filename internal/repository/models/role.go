@@ -0,0 +1,22 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Role represents a row of the roles table.
+type Role struct {
+	Name        string         `db:"NAME"`
+	Description sql.NullString `db:"DESCRIPTION"`
+	CreatedAt   time.Time      `db:"CREATED_AT"`
+}
+
+// UserRole represents a row of the user_roles table, joined with the email of the user.
+type UserRole struct {
+	UserID    string         `db:"USER_ID"`
+	Email     string         `db:"EMAIL"`
+	RoleName  string         `db:"ROLE_NAME"`
+	GrantedBy sql.NullString `db:"GRANTED_BY"`
+	GrantedAt time.Time      `db:"GRANTED_AT"`
+}
@@ -8,13 +8,15 @@ import (
 // User represents a user in the system.
 type User struct {
 	ID                    string         `db:"ID"`                      // ULID
-	GoogleID              string         `db:"GOOGLE_ID"`               // Google's unique identifier for the user
-	Email                 string         `db:"EMAIL"`                   // User's email address
+	GoogleID              string         `db:"GOOGLE_ID"`               // Legacy Google ID, superseded by user_identities; no longer read
+	Email                 sql.NullString `db:"EMAIL"`                   // User's verified email address; NULL when no provider verified one
 	Name                  sql.NullString `db:"NAME"`                    // User's full name
 	ProfilePictureURL     sql.NullString `db:"PROFILE_PICTURE_URL"`     // URL of the user's profile picture
 	EncryptedAccessToken  sql.NullString `db:"ENCRYPTED_ACCESS_TOKEN"`  // Encrypted Google OAuth access token
 	EncryptedRefreshToken sql.NullString `db:"ENCRYPTED_REFRESH_TOKEN"` // Encrypted Google OAuth refresh token
 	TokenExpiresAt        sql.NullTime   `db:"TOKEN_EXPIRES_AT"`        // Expiry time for the access token
+	TokenRefreshFailures  int            `db:"TOKEN_REFRESH_FAILURES"`  // Failed background refreshes in a row
+	TokenRefreshRetryAt   sql.NullTime   `db:"TOKEN_REFRESH_RETRY_AT"`  // The next background refresh is not tried before this time
 	CreatedAt             time.Time      `db:"CREATED_AT"`              // Timestamp of user creation
 	UpdatedAt             time.Time      `db:"UPDATED_AT"`              // Timestamp of last update
 	DeletedAt             sql.NullTime   `db:"DELETED_AT"`              // Timestamp of soft deletion, if applicable
@@ -33,6 +35,7 @@ type UserQuizAttempt struct {
 	LlmRelevance      sql.NullFloat64 `db:"LLM_RELEVANCE"`       // Relevance score from LLM
 	LlmAccuracy       sql.NullFloat64 `db:"LLM_ACCURACY"`        // Accuracy score from LLM
 	IsCorrect         bool            `db:"IS_CORRECT"`          // Whether the answer was deemed correct (e.g., score >= threshold)
+	QuizRevision      sql.NullInt64   `db:"QUIZ_REVISION"`       // Revision of the quiz the answer was graded against; NULL when unknown
 	AttemptedAt       time.Time       `db:"ATTEMPTED_AT"`        // Timestamp when the attempt was made
 	CreatedAt         time.Time       `db:"CREATED_AT"`          // Timestamp of record creation
 	UpdatedAt         time.Time       `db:"UPDATED_AT"`          // Timestamp of last update
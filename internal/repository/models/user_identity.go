@@ -0,0 +1,18 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UserIdentity represents a row of the user_identities table.
+type UserIdentity struct {
+	ID          string         `db:"ID"` // ULID
+	UserID      string         `db:"USER_ID"`
+	Provider    string         `db:"PROVIDER"`
+	Subject     string         `db:"SUBJECT"` // The provider's ID of the account
+	Email       sql.NullString `db:"EMAIL"`
+	LastLoginAt sql.NullTime   `db:"LAST_LOGIN_AT"`
+	CreatedAt   time.Time      `db:"CREATED_AT"`
+	UpdatedAt   time.Time      `db:"UPDATED_AT"`
+}
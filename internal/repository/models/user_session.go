@@ -0,0 +1,22 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UserSession represents a row of the user_sessions table.
+type UserSession struct {
+	ID            string         `db:"ID"` // ULID, also the "sid" claim of the session's tokens
+	UserID        string         `db:"USER_ID"`
+	CurrentJTI    string         `db:"CURRENT_JTI"` // jti of the refresh token that can be used next
+	ExpiresAt     time.Time      `db:"EXPIRES_AT"`
+	LastUsedAt    time.Time      `db:"LAST_USED_AT"`
+	RevokedAt     sql.NullTime   `db:"REVOKED_AT"`
+	RevokedReason sql.NullString `db:"REVOKED_REASON"`
+	Device        sql.NullString `db:"DEVICE"`
+	UserAgent     sql.NullString `db:"USER_AGENT"`
+	IPAddress     sql.NullString `db:"IP_ADDRESS"`
+	CreatedAt     time.Time      `db:"CREATED_AT"`
+	UpdatedAt     time.Time      `db:"UPDATED_AT"`
+}
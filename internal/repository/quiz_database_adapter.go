@@ -39,11 +39,12 @@ func (a *QuizDatabaseAdapter) GetRandomQuiz(ctx context.Context) (*domain.Quiz,
 		updated_at "UPDATED_AT",
 		deleted_at "DELETED_AT"
 	FROM quizzes 
-	WHERE deleted_at IS NULL 
+	WHERE status = :1 
+	AND deleted_at IS NULL 
 	ORDER BY DBMS_RANDOM.VALUE 
 	FETCH FIRST 1 ROWS ONLY`
 
-	err := a.db.GetContext(ctx, &modelQuiz, query)
+	err := a.db.GetContext(ctx, &modelQuiz, query, domain.QuizStatusPublished)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -63,6 +64,8 @@ func (a *QuizDatabaseAdapter) GetQuizByID(ctx context.Context, id string) (*doma
 		keywords "KEYWORDS",
 		difficulty "DIFFICULTY",
 		sub_category_id "SUB_CATEGORY_ID",
+		revision "REVISION",
+		status "STATUS",
 		created_at "CREATED_AT",
 		updated_at "UPDATED_AT",
 		deleted_at "DELETED_AT"
@@ -70,7 +73,7 @@ func (a *QuizDatabaseAdapter) GetQuizByID(ctx context.Context, id string) (*doma
 	WHERE id = :1 
 	AND deleted_at IS NULL`
 
-	err := a.db.GetContext(ctx, &modelQuiz, query, id)
+	err := GetExecutor(ctx, a.db).GetContext(ctx, &modelQuiz, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Not found
@@ -87,14 +90,18 @@ func (a *QuizDatabaseAdapter) SaveQuiz(ctx context.Context, quiz *domain.Quiz) e
 		return fmt.Errorf("cannot save nil quiz")
 	}
 	modelQuiz.ID = util.NewULID()
+	modelQuiz.Revision = 1
+	if modelQuiz.Status == "" {
+		modelQuiz.Status = domain.QuizStatusPublished
+	}
 	modelQuiz.CreatedAt = time.Now()
 	modelQuiz.UpdatedAt = time.Now()
 
 	query := `INSERT INTO quizzes (
 		id, question, model_answers, keywords, 
-		difficulty, sub_category_id, created_at, updated_at
+		difficulty, sub_category_id, revision, status, created_at, updated_at
 	) VALUES (
-		:1, :2, :3, :4, :5, :6, :7, :8
+		:1, :2, :3, :4, :5, :6, :7, :8, :9, :10
 	)`
 
 	executor := GetExecutor(ctx, a.db)
@@ -105,6 +112,8 @@ func (a *QuizDatabaseAdapter) SaveQuiz(ctx context.Context, quiz *domain.Quiz) e
 		modelQuiz.Keywords,
 		modelQuiz.Difficulty,
 		modelQuiz.SubCategoryID,
+		modelQuiz.Revision,
+		modelQuiz.Status,
 		modelQuiz.CreatedAt,
 		modelQuiz.UpdatedAt,
 	)
@@ -113,13 +122,18 @@ func (a *QuizDatabaseAdapter) SaveQuiz(ctx context.Context, quiz *domain.Quiz) e
 	}
 
 	quiz.ID = modelQuiz.ID
+	quiz.Revision = modelQuiz.Revision
+	quiz.Status = modelQuiz.Status
 	quiz.CreatedAt = modelQuiz.CreatedAt
 	quiz.UpdatedAt = modelQuiz.UpdatedAt
-	return nil
+	// Quizzes created by batch jobs and the seeder have no author.
+	return a.saveQuizRevision(ctx, domain.NewQuizRevision(quiz, "", quiz.CreatedAt))
 }
 
-// UpdateQuiz implements domain.QuizRepository
-func (a *QuizDatabaseAdapter) UpdateQuiz(ctx context.Context, quiz *domain.Quiz) error {
+// UpdateQuiz implements domain.QuizRepository.
+// The update only applies while the quiz is still at quiz.Revision, so concurrent edits cannot overwrite
+// each other. Run it in a transaction so the quiz and its revision are saved together.
+func (a *QuizDatabaseAdapter) UpdateQuiz(ctx context.Context, quiz *domain.Quiz, author string) error {
 	modelQuiz := toModelQuiz(quiz)
 	if modelQuiz == nil {
 		return fmt.Errorf("cannot update nil quiz")
@@ -127,6 +141,7 @@ func (a *QuizDatabaseAdapter) UpdateQuiz(ctx context.Context, quiz *domain.Quiz)
 	if modelQuiz.ID == "" { // ULIDs are strings
 		return fmt.Errorf("cannot update quiz with empty ID")
 	}
+	modelQuiz.Revision = quiz.Revision + 1
 	modelQuiz.UpdatedAt = time.Now()
 
 	query := `UPDATE quizzes SET 
@@ -135,18 +150,22 @@ func (a *QuizDatabaseAdapter) UpdateQuiz(ctx context.Context, quiz *domain.Quiz)
 		keywords = :3, 
 		difficulty = :4, 
 		sub_category_id = :5, 
-		updated_at = :6
-	WHERE id = :7 
+		revision = :6, 
+		updated_at = :7
+	WHERE id = :8 
+	AND revision = :9 
 	AND deleted_at IS NULL`
 
-	result, err := a.db.ExecContext(ctx, query,
+	result, err := GetExecutor(ctx, a.db).ExecContext(ctx, query,
 		modelQuiz.Question,
 		modelQuiz.ModelAnswers,
 		modelQuiz.Keywords,
 		modelQuiz.Difficulty,
 		modelQuiz.SubCategoryID,
+		modelQuiz.Revision,
 		modelQuiz.UpdatedAt,
 		modelQuiz.ID,
+		quiz.Revision,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update quiz: %w", err)
@@ -157,12 +176,109 @@ func (a *QuizDatabaseAdapter) UpdateQuiz(ctx context.Context, quiz *domain.Quiz)
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("quiz with ID %s not found or not updated", quiz.ID)
+		return fmt.Errorf("quiz with ID %s not found or no longer at revision %d: %w", quiz.ID, quiz.Revision, domain.ErrConflict)
 	}
+	quiz.Revision = modelQuiz.Revision
 	quiz.UpdatedAt = modelQuiz.UpdatedAt
+	return a.saveQuizRevision(ctx, domain.NewQuizRevision(quiz, author, quiz.UpdatedAt))
+}
+
+// saveQuizRevision records a snapshot of a quiz, joining the transaction in ctx if any.
+func (a *QuizDatabaseAdapter) saveQuizRevision(ctx context.Context, revision *domain.QuizRevision) error {
+	modelRevision := toModelQuizRevision(revision)
+	query := `INSERT INTO quiz_revisions (
+		quiz_id, revision, question, model_answers, keywords,
+		difficulty, sub_category_id, author, created_at
+	) VALUES (
+		:1, :2, :3, :4, :5, :6, :7, :8, :9
+	)`
+
+	_, err := GetExecutor(ctx, a.db).ExecContext(ctx, query,
+		modelRevision.QuizID,
+		modelRevision.Revision,
+		modelRevision.Question,
+		modelRevision.ModelAnswers,
+		modelRevision.Keywords,
+		modelRevision.Difficulty,
+		modelRevision.SubCategoryID,
+		modelRevision.Author,
+		modelRevision.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save revision %d of quiz %s: %w", revision.Revision, revision.QuizID, err)
+	}
 	return nil
 }
 
+// ListQuizRevisions implements domain.QuizRepository
+func (a *QuizDatabaseAdapter) ListQuizRevisions(ctx context.Context, quizID string) ([]*domain.QuizRevision, error) {
+	var modelRevisions []models.QuizRevision
+	query := `SELECT
+		quiz_id "QUIZ_ID",
+		revision "REVISION",
+		question "QUESTION",
+		model_answers "MODEL_ANSWERS",
+		keywords "KEYWORDS",
+		difficulty "DIFFICULTY",
+		sub_category_id "SUB_CATEGORY_ID",
+		author "AUTHOR",
+		created_at "CREATED_AT"
+	FROM quiz_revisions
+	WHERE quiz_id = :1
+	ORDER BY revision DESC`
+
+	if err := GetExecutor(ctx, a.db).SelectContext(ctx, &modelRevisions, query, quizID); err != nil {
+		return nil, fmt.Errorf("failed to list revisions of quiz %s: %w", quizID, err)
+	}
+	revisions := make([]*domain.QuizRevision, 0, len(modelRevisions))
+	for i := range modelRevisions {
+		revisions = append(revisions, toDomainQuizRevision(&modelRevisions[i]))
+	}
+	return revisions, nil
+}
+
+// GetQuizRevision implements domain.QuizRepository
+func (a *QuizDatabaseAdapter) GetQuizRevision(ctx context.Context, quizID string, revision int) (*domain.QuizRevision, error) {
+	var modelRevision models.QuizRevision
+	query := `SELECT
+		quiz_id "QUIZ_ID",
+		revision "REVISION",
+		question "QUESTION",
+		model_answers "MODEL_ANSWERS",
+		keywords "KEYWORDS",
+		difficulty "DIFFICULTY",
+		sub_category_id "SUB_CATEGORY_ID",
+		author "AUTHOR",
+		created_at "CREATED_AT"
+	FROM quiz_revisions
+	WHERE quiz_id = :1
+	AND revision = :2`
+
+	err := GetExecutor(ctx, a.db).GetContext(ctx, &modelRevision, query, quizID, revision)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get revision %d of quiz %s: %w", revision, quizID, err)
+	}
+	return toDomainQuizRevision(&modelRevision), nil
+}
+
+// DeleteQuiz implements domain.QuizRepository
+func (a *QuizDatabaseAdapter) DeleteQuiz(ctx context.Context, id string) (bool, error) {
+	now := time.Now()
+	query := `UPDATE quizzes SET deleted_at = :1, updated_at = :2 WHERE id = :3 AND deleted_at IS NULL`
+	result, err := GetExecutor(ctx, a.db).ExecContext(ctx, query, now, now, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete quiz %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
 // SaveAnswer implements domain.QuizRepository
 func (a *QuizDatabaseAdapter) SaveAnswer(ctx context.Context, answer *domain.Answer) error {
 	modelAnswer := toModelAnswer(answer)
@@ -203,8 +319,10 @@ func (a *QuizDatabaseAdapter) SaveAnswer(ctx context.Context, answer *domain.Ans
 	return nil
 }
 
-// GetSimilarQuiz implements domain.QuizRepository
-func (a *QuizDatabaseAdapter) GetSimilarQuiz(ctx context.Context, quizID string) (*domain.Quiz, error) {
+// GetSimilarQuiz implements domain.QuizRepository.
+// It picks another quiz from the same subcategory as quizID. The difficulty defaults to that of
+// the current quiz unless the filter specifies one.
+func (a *QuizDatabaseAdapter) GetSimilarQuiz(ctx context.Context, quizID string, filter *domain.QuizFilter) (*domain.Quiz, error) {
 	current := struct {
 		Difficulty    int    `db:"DIFFICULTY"`
 		SubCategoryID string `db:"SUB_CATEGORY_ID"`
@@ -224,8 +342,16 @@ func (a *QuizDatabaseAdapter) GetSimilarQuiz(ctx context.Context, quizID string)
 		return nil, fmt.Errorf("failed to get current quiz for similarity check: %w", err)
 	}
 
+	similarFilter := domain.QuizFilter{Difficulty: current.Difficulty}
+	if filter != nil {
+		similarFilter = *filter
+		if similarFilter.Difficulty == 0 {
+			similarFilter.Difficulty = current.Difficulty
+		}
+	}
+
 	var similarQuizModel models.Quiz
-	querySimilar := `SELECT 
+	querySimilar := `SELECT
 		id "ID",
 		question "QUESTION",
 		model_answers "MODEL_ANSWERS",
@@ -236,14 +362,21 @@ func (a *QuizDatabaseAdapter) GetSimilarQuiz(ctx context.Context, quizID string)
 		updated_at "UPDATED_AT",
 		deleted_at "DELETED_AT"
 	FROM quizzes
-	WHERE id != :1 
-	AND sub_category_id = :2 
-	AND difficulty = :3 
-	AND deleted_at IS NULL
-	ORDER BY DBMS_RANDOM.VALUE 
+	WHERE id != :1
+	AND sub_category_id = :2
+	AND status = :3
+	AND deleted_at IS NULL`
+
+	args := []interface{}{quizID, current.SubCategoryID, domain.QuizStatusPublished}
+	conditions, filterArgs := buildQuizFilterConditions(&similarFilter, len(args)+1)
+	querySimilar += conditions
+	args = append(args, filterArgs...)
+
+	querySimilar += `
+	ORDER BY DBMS_RANDOM.VALUE
 	FETCH FIRST 1 ROWS ONLY`
 
-	err = a.db.GetContext(ctx, &similarQuizModel, querySimilar, quizID, current.SubCategoryID, current.Difficulty)
+	err = a.db.GetContext(ctx, &similarQuizModel, querySimilar, args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // No similar quiz found
@@ -309,6 +442,61 @@ func (a *QuizDatabaseAdapter) SaveQuizEvaluation(ctx context.Context, evaluation
 	return nil
 }
 
+// UpdateQuizEvaluation implements domain.QuizRepository
+func (a *QuizDatabaseAdapter) UpdateQuizEvaluation(ctx context.Context, evaluation *domain.QuizEvaluation) error {
+	modelEval, err := toModelQuizEvaluation(evaluation)
+	if err != nil {
+		return fmt.Errorf("failed to convert domain.QuizEvaluation to model: %w", err)
+	}
+	if modelEval == nil {
+		return fmt.Errorf("cannot update nil QuizEvaluation")
+	}
+	modelEval.UpdatedAt = time.Now()
+
+	query := `UPDATE quiz_evaluations SET
+		minimum_keywords = :1,
+		required_topics = :2,
+		score_ranges = :3,
+		sample_answers = :4,
+		rubric_details = :5,
+		score_evaluations = :6,
+		updated_at = :7
+	WHERE quiz_id = :8
+	AND deleted_at IS NULL`
+
+	result, err := GetExecutor(ctx, a.db).ExecContext(ctx, query,
+		modelEval.MinimumKeywords, modelEval.RequiredTopics, modelEval.ScoreRanges,
+		modelEval.SampleAnswers, modelEval.RubricDetails, modelEval.ScoreEvaluations,
+		modelEval.UpdatedAt, modelEval.QuizID)
+	if err != nil {
+		return fmt.Errorf("failed to update quiz evaluation for quiz_id %s: %w", modelEval.QuizID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("quiz evaluation for quiz_id %s not found or not updated", modelEval.QuizID)
+	}
+	evaluation.UpdatedAt = modelEval.UpdatedAt
+	return nil
+}
+
+// DeleteQuizEvaluation implements domain.QuizRepository
+func (a *QuizDatabaseAdapter) DeleteQuizEvaluation(ctx context.Context, quizID string) (bool, error) {
+	now := time.Now()
+	query := `UPDATE quiz_evaluations SET deleted_at = :1, updated_at = :2 WHERE quiz_id = :3 AND deleted_at IS NULL`
+	result, err := GetExecutor(ctx, a.db).ExecContext(ctx, query, now, now, quizID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete quiz evaluation for quiz_id %s: %w", quizID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
 // GetQuizEvaluation implements domain.QuizRepository
 func (a *QuizDatabaseAdapter) GetQuizEvaluation(ctx context.Context, quizID string) (*domain.QuizEvaluation, error) {
 	var modelEval models.QuizEvaluation
@@ -336,9 +524,9 @@ func (a *QuizDatabaseAdapter) GetQuizEvaluation(ctx context.Context, quizID stri
 }
 
 // GetRandomQuizBySubCategory implements domain.QuizRepository
-func (a *QuizDatabaseAdapter) GetRandomQuizBySubCategory(ctx context.Context, subCategoryID string) (*domain.Quiz, error) {
+func (a *QuizDatabaseAdapter) GetRandomQuizBySubCategory(ctx context.Context, subCategoryID string, filter *domain.QuizFilter) (*domain.Quiz, error) {
 	var modelQuiz models.Quiz
-	query := `SELECT 
+	query := `SELECT
 		id "ID",
 		question "QUESTION",
 		model_answers "MODEL_ANSWERS",
@@ -349,12 +537,20 @@ func (a *QuizDatabaseAdapter) GetRandomQuizBySubCategory(ctx context.Context, su
 		updated_at "UPDATED_AT",
 		deleted_at "DELETED_AT"
 	FROM quizzes
-	WHERE sub_category_id = :1 
-	AND deleted_at IS NULL
-	ORDER BY DBMS_RANDOM.VALUE 
+	WHERE sub_category_id = :1
+	AND status = :2
+	AND deleted_at IS NULL`
+
+	args := []interface{}{subCategoryID, domain.QuizStatusPublished}
+	conditions, filterArgs := buildQuizFilterConditions(filter, len(args)+1)
+	query += conditions
+	args = append(args, filterArgs...)
+
+	query += `
+	ORDER BY DBMS_RANDOM.VALUE
 	FETCH FIRST 1 ROWS ONLY`
 
-	err := a.db.GetContext(ctx, &modelQuiz, query, subCategoryID)
+	err := a.db.GetContext(ctx, &modelQuiz, query, args...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // No quiz found
@@ -379,11 +575,12 @@ func (a *QuizDatabaseAdapter) GetQuizzesByCriteria(ctx context.Context, subCateg
 		deleted_at "DELETED_AT"
 	FROM quizzes 
 	WHERE sub_category_id = :1 
+	AND status = :2 
 	AND deleted_at IS NULL 
 	ORDER BY DBMS_RANDOM.VALUE 
-	FETCH FIRST :2 ROWS ONLY`
+	FETCH FIRST :3 ROWS ONLY`
 
-	err := a.db.SelectContext(ctx, &modelQuizzes, query, subCategoryID, count)
+	err := a.db.SelectContext(ctx, &modelQuizzes, query, subCategoryID, domain.QuizStatusPublished, count)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return []*domain.Quiz{}, nil
@@ -428,6 +625,8 @@ func (a *QuizDatabaseAdapter) GetQuizzesBySubCategory(ctx context.Context, subCa
 		keywords "KEYWORDS",
 		difficulty "DIFFICULTY",
 		sub_category_id "SUB_CATEGORY_ID",
+		revision "REVISION",
+		status "STATUS",
 		created_at "CREATED_AT",
 		updated_at "UPDATED_AT",
 		deleted_at "DELETED_AT"
@@ -437,7 +636,7 @@ func (a *QuizDatabaseAdapter) GetQuizzesBySubCategory(ctx context.Context, subCa
 	ORDER BY created_at DESC`
 
 	// Using SelectContext for context propagation
-	err := a.db.SelectContext(ctx, &modelQuizzes, query, subCategoryID) // Already using SelectContext
+	err := GetExecutor(ctx, a.db).SelectContext(ctx, &modelQuizzes, query, subCategoryID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return []*domain.Quiz{}, nil
@@ -461,6 +660,56 @@ func (a *QuizDatabaseAdapter) GetQuizzesBySubCategory(ctx context.Context, subCa
 	return domainQuizzes, nil
 }
 
+// likeEscaper escapes the wildcards of a value matched with LIKE ... ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// buildQuizFilterConditions turns a QuizFilter into additional WHERE clauses.
+// Positional bind numbering starts at startPos so the clauses can be appended to an existing query.
+func buildQuizFilterConditions(filter *domain.QuizFilter, startPos int) (string, []interface{}) {
+	if filter.IsEmpty() {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	var args []interface{}
+	pos := startPos
+
+	if filter.Difficulty > 0 {
+		sb.WriteString(fmt.Sprintf("\n\tAND difficulty = :%d", pos))
+		args = append(args, filter.Difficulty)
+		pos++
+	}
+
+	if len(filter.Keywords) > 0 {
+		// Keywords are stored joined by stringDelimiter; wrapping the column in it as well lets a
+		// keyword match only a whole entry, so "go" does not match "google".
+		keywordConditions := make([]string, 0, len(filter.Keywords))
+		for _, keyword := range filter.Keywords {
+			keywordConditions = append(keywordConditions, fmt.Sprintf("'%s' || UPPER(keywords) || '%s' LIKE :%d ESCAPE '\\'", stringDelimiter, stringDelimiter, pos))
+			args = append(args, "%"+stringDelimiter+likeEscaper.Replace(strings.ToUpper(keyword))+stringDelimiter+"%")
+			pos++
+		}
+		sb.WriteString("\n\tAND (" + strings.Join(keywordConditions, " OR ") + ")")
+	}
+
+	if len(filter.ExcludeIDs) > 0 {
+		placeholders := make([]string, 0, len(filter.ExcludeIDs))
+		for _, id := range filter.ExcludeIDs {
+			placeholders = append(placeholders, fmt.Sprintf(":%d", pos))
+			args = append(args, id)
+			pos++
+		}
+		sb.WriteString("\n\tAND id NOT IN (" + strings.Join(placeholders, ", ") + ")")
+	}
+
+	if filter.ExcludeAttemptedByUserID != "" {
+		sb.WriteString(fmt.Sprintf("\n\tAND id NOT IN (SELECT quiz_id FROM user_quiz_attempts WHERE user_id = :%d)", pos))
+		args = append(args, filter.ExcludeAttemptedByUserID)
+	}
+
+	return sb.String(), args
+}
+
 // Helper functions for model conversion
 func toDomainQuiz(m *models.Quiz) (*domain.Quiz, error) {
 	if m == nil {
@@ -473,6 +722,8 @@ func toDomainQuiz(m *models.Quiz) (*domain.Quiz, error) {
 		Keywords:      strings.Split(m.Keywords, stringDelimiter),
 		Difficulty:    m.Difficulty,
 		SubCategoryID: m.SubCategoryID,
+		Revision:      m.Revision,
+		Status:        m.Status,
 		CreatedAt:     m.CreatedAt,
 		UpdatedAt:     m.UpdatedAt,
 	}, nil
@@ -489,11 +740,41 @@ func toModelQuiz(d *domain.Quiz) *models.Quiz {
 		Keywords:      strings.Join(d.Keywords, stringDelimiter),
 		Difficulty:    d.Difficulty,
 		SubCategoryID: d.SubCategoryID,
+		Revision:      d.Revision,
+		Status:        d.Status,
 		CreatedAt:     d.CreatedAt,
 		UpdatedAt:     d.UpdatedAt,
 	}
 }
 
+func toDomainQuizRevision(m *models.QuizRevision) *domain.QuizRevision {
+	return &domain.QuizRevision{
+		QuizID:        m.QuizID,
+		Revision:      m.Revision,
+		Question:      m.Question,
+		ModelAnswers:  strings.Split(m.ModelAnswers, stringDelimiter),
+		Keywords:      strings.Split(m.Keywords, stringDelimiter),
+		Difficulty:    m.Difficulty,
+		SubCategoryID: m.SubCategoryID,
+		Author:        m.Author.String,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+func toModelQuizRevision(d *domain.QuizRevision) *models.QuizRevision {
+	return &models.QuizRevision{
+		QuizID:        d.QuizID,
+		Revision:      d.Revision,
+		Question:      d.Question,
+		ModelAnswers:  strings.Join(d.ModelAnswers, stringDelimiter),
+		Keywords:      strings.Join(d.Keywords, stringDelimiter),
+		Difficulty:    d.Difficulty,
+		SubCategoryID: d.SubCategoryID,
+		Author:        util.StringToNullString(d.Author),
+		CreatedAt:     d.CreatedAt,
+	}
+}
+
 func toModelAnswer(d *domain.Answer) *models.Answer {
 	if d == nil {
 		return nil
@@ -615,6 +896,7 @@ func (a *QuizDatabaseAdapter) GetUnattemptedQuizzesWithDetails(ctx context.Conte
 
 	params := map[string]interface{}{
 		"user_id": userID,
+		"status":  domain.QuizStatusPublished,
 		"limit":   limit,
 	}
 
@@ -627,7 +909,7 @@ func (a *QuizDatabaseAdapter) GetUnattemptedQuizzesWithDetails(ctx context.Conte
 	FROM quizzes q
 	JOIN sub_categories sc ON q.sub_category_id = sc.id
 	LEFT JOIN user_quiz_attempts uqa ON q.id = uqa.quiz_id AND uqa.user_id = :user_id
-	WHERE q.deleted_at IS NULL AND uqa.id IS NULL`
+	WHERE q.status = :status AND q.deleted_at IS NULL AND uqa.id IS NULL`
 
 	if optionalSubCategoryID != "" {
 		query += " AND q.sub_category_id = :sub_category_id"
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/repository/models"
+	"time"
+)
+
+// sqlxQuizSeedRepository implements domain.QuizSeedRepository using sqlx.
+type sqlxQuizSeedRepository struct {
+	db DBTX
+}
+
+// NewSQLXQuizSeedRepository creates a new instance of sqlxQuizSeedRepository.
+func NewSQLXQuizSeedRepository(db DBTX) domain.QuizSeedRepository {
+	return &sqlxQuizSeedRepository{db: db}
+}
+
+// ListQuizSeeds retrieves the seeds whose quiz is not deleted.
+func (r *sqlxQuizSeedRepository) ListQuizSeeds(ctx context.Context) ([]*domain.QuizSeed, error) {
+	var modelSeeds []models.QuizSeed
+	query := `SELECT s.seed_key, s.quiz_id, s.content_hash, s.seeded_at
+	          FROM quiz_seeds s
+	          JOIN quizzes q ON q.id = s.quiz_id
+	          WHERE q.deleted_at IS NULL
+	          ORDER BY s.seed_key`
+
+	if err := GetExecutor(ctx, r.db).SelectContext(ctx, &modelSeeds, query); err != nil {
+		return nil, fmt.Errorf("failed to list quiz seeds: %w", err)
+	}
+	seeds := make([]*domain.QuizSeed, 0, len(modelSeeds))
+	for _, model := range modelSeeds {
+		seeds = append(seeds, &domain.QuizSeed{
+			Key:         model.SeedKey,
+			QuizID:      model.QuizID,
+			ContentHash: model.ContentHash,
+			SeededAt:    model.SeededAt,
+		})
+	}
+	return seeds, nil
+}
+
+// SaveQuizSeed inserts the seed, or points its key at the quiz and content hash when it exists.
+func (r *sqlxQuizSeedRepository) SaveQuizSeed(ctx context.Context, seed *domain.QuizSeed) error {
+	if seed.SeededAt.IsZero() {
+		seed.SeededAt = time.Now()
+	}
+	query := `MERGE INTO quiz_seeds s
+	          USING (SELECT :1 AS seed_key FROM dual) k
+	          ON (s.seed_key = k.seed_key)
+	          WHEN MATCHED THEN UPDATE SET quiz_id = :2, content_hash = :3, seeded_at = :4
+	          WHEN NOT MATCHED THEN INSERT (seed_key, quiz_id, content_hash, seeded_at)
+	          VALUES (k.seed_key, :5, :6, :7)`
+
+	_, err := GetExecutor(ctx, r.db).ExecContext(ctx, query,
+		seed.Key,
+		seed.QuizID, seed.ContentHash, seed.SeededAt,
+		seed.QuizID, seed.ContentHash, seed.SeededAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save seed %s of quiz %s: %w", seed.Key, seed.QuizID, err)
+	}
+	return nil
+}
+
+// RekeyQuizSeed moves the seed under oldKey to newKey. A seed left under newKey, whose quiz
+// was deleted, is dropped first so the key is free.
+func (r *sqlxQuizSeedRepository) RekeyQuizSeed(ctx context.Context, oldKey, newKey string) error {
+	executor := GetExecutor(ctx, r.db)
+	if _, err := executor.ExecContext(ctx, `DELETE FROM quiz_seeds WHERE seed_key = :1`, newKey); err != nil {
+		return fmt.Errorf("failed to free seed key %s: %w", newKey, err)
+	}
+	if _, err := executor.ExecContext(ctx, `UPDATE quiz_seeds SET seed_key = :1 WHERE seed_key = :2`, newKey, oldKey); err != nil {
+		return fmt.Errorf("failed to rekey seed %s to %s: %w", oldKey, newKey, err)
+	}
+	return nil
+}
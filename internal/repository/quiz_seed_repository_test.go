@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuizSeedRepository_ListQuizSeeds(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXQuizSeedRepository(db)
+	seededAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{"SEED_KEY", "QUIZ_ID", "CONTENT_HASH", "SEEDED_AT"}).
+		AddRow("hash-tables", "quiz1", "abc123", seededAt)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM quiz_seeds s JOIN quizzes q ON q.id = s.quiz_id WHERE q.deleted_at IS NULL`)).
+		WillReturnRows(rows)
+
+	seeds, err := repo.ListQuizSeeds(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.QuizSeed{{Key: "hash-tables", QuizID: "quiz1", ContentHash: "abc123", SeededAt: seededAt}}, seeds)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuizSeedRepository_SaveQuizSeed(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXQuizSeedRepository(db)
+	seed := &domain.QuizSeed{Key: "hash-tables", QuizID: "quiz1", ContentHash: "abc123"}
+
+	mock.ExpectExec(regexp.QuoteMeta(`MERGE INTO quiz_seeds s`)).
+		WithArgs("hash-tables", "quiz1", "abc123", sqlmock.AnyArg(), "quiz1", "abc123", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.SaveQuizSeed(context.Background(), seed)
+	assert.NoError(t, err)
+	assert.False(t, seed.SeededAt.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuizSeedRepository_SaveQuizSeed_Error(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXQuizSeedRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(`MERGE INTO quiz_seeds s`)).WillReturnError(errors.New("db down"))
+
+	err := repo.SaveQuizSeed(context.Background(), &domain.QuizSeed{Key: "hash-tables", QuizID: "quiz1", ContentHash: "abc123"})
+	assert.ErrorContains(t, err, "failed to save seed hash-tables of quiz quiz1")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuizSeedRepository_RekeyQuizSeed(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXQuizSeedRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM quiz_seeds WHERE seed_key = :1`)).
+		WithArgs("hash-tables").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE quiz_seeds SET seed_key = :1 WHERE seed_key = :2`)).
+		WithArgs("hash-tables", "sha256:abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.RekeyQuizSeed(context.Background(), "sha256:abc", "hash-tables")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
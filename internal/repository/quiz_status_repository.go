@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/repository/models"
+	"quiz-byte/internal/util"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// sqlxQuizStatusRepository implements domain.QuizStatusRepository using sqlx.
+type sqlxQuizStatusRepository struct {
+	db DBTX
+}
+
+// NewSQLXQuizStatusRepository creates a new instance of sqlxQuizStatusRepository.
+func NewSQLXQuizStatusRepository(db *sqlx.DB) domain.QuizStatusRepository {
+	return &sqlxQuizStatusRepository{db: db}
+}
+
+// ChangeQuizStatus updates the status of the quiz and inserts the audit record of the change.
+func (r *sqlxQuizStatusRepository) ChangeQuizStatus(ctx context.Context, change *domain.QuizStatusChange) error {
+	if change.ID == "" {
+		change.ID = util.NewULID()
+	}
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = time.Now()
+	}
+	executor := GetExecutor(ctx, r.db)
+
+	query := `UPDATE quizzes SET status = :1, updated_at = :2 WHERE id = :3 AND status = :4 AND deleted_at IS NULL`
+	result, err := executor.ExecContext(ctx, query, change.ToStatus, change.CreatedAt, change.QuizID, change.FromStatus)
+	if err != nil {
+		return fmt.Errorf("failed to change status of quiz %s: %w", change.QuizID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("quiz with ID %s not found or no longer %s: %w", change.QuizID, change.FromStatus, domain.ErrConflict)
+	}
+
+	query = `INSERT INTO quiz_status_changes (id, quiz_id, from_status, to_status, actor, review_comment, created_at)
+	         VALUES (:1, :2, :3, :4, :5, :6, :7)`
+	_, err = executor.ExecContext(ctx, query,
+		change.ID,
+		change.QuizID,
+		change.FromStatus,
+		change.ToStatus,
+		util.StringToNullString(change.Actor),
+		util.StringToNullString(change.Comment),
+		change.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record status change of quiz %s: %w", change.QuizID, err)
+	}
+	return nil
+}
+
+// ListQuizStatusChanges retrieves the audit trail of a quiz's status.
+func (r *sqlxQuizStatusRepository) ListQuizStatusChanges(ctx context.Context, quizID string) ([]*domain.QuizStatusChange, error) {
+	var modelChanges []models.QuizStatusChange
+	query := `SELECT id, quiz_id, from_status, to_status, actor, review_comment, created_at
+	          FROM quiz_status_changes
+	          WHERE quiz_id = :1
+	          ORDER BY created_at ASC, id ASC`
+
+	if err := GetExecutor(ctx, r.db).SelectContext(ctx, &modelChanges, query, quizID); err != nil {
+		return nil, fmt.Errorf("failed to list status changes of quiz %s: %w", quizID, err)
+	}
+	changes := make([]*domain.QuizStatusChange, 0, len(modelChanges))
+	for _, model := range modelChanges {
+		changes = append(changes, &domain.QuizStatusChange{
+			ID:         model.ID,
+			QuizID:     model.QuizID,
+			FromStatus: model.FromStatus,
+			ToStatus:   model.ToStatus,
+			Actor:      model.Actor.String,
+			Comment:    model.ReviewComment.String,
+			CreatedAt:  model.CreatedAt,
+		})
+	}
+	return changes, nil
+}
+
+// ListQuizzesByStatus retrieves the active quizzes in the statuses, those waiting longest first.
+func (r *sqlxQuizStatusRepository) ListQuizzesByStatus(ctx context.Context, statuses []string, limit int) ([]*domain.Quiz, error) {
+	if len(statuses) == 0 {
+		return []*domain.Quiz{}, nil
+	}
+	args := make([]interface{}, 0, len(statuses)+1)
+	placeholders := make([]string, 0, len(statuses))
+	for i, status := range statuses {
+		placeholders = append(placeholders, fmt.Sprintf(":%d", i+1))
+		args = append(args, status)
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`SELECT
+		id "ID",
+		question "QUESTION",
+		model_answers "MODEL_ANSWERS",
+		keywords "KEYWORDS",
+		difficulty "DIFFICULTY",
+		sub_category_id "SUB_CATEGORY_ID",
+		revision "REVISION",
+		status "STATUS",
+		created_at "CREATED_AT",
+		updated_at "UPDATED_AT",
+		deleted_at "DELETED_AT"
+	FROM quizzes
+	WHERE status IN (%s)
+	AND deleted_at IS NULL
+	ORDER BY updated_at ASC, id ASC
+	FETCH FIRST :%d ROWS ONLY`, strings.Join(placeholders, ", "), len(args))
+
+	var modelQuizzes []models.Quiz
+	if err := GetExecutor(ctx, r.db).SelectContext(ctx, &modelQuizzes, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list quizzes by status: %w", err)
+	}
+	quizzes := make([]*domain.Quiz, 0, len(modelQuizzes))
+	for i := range modelQuizzes {
+		quiz, err := toDomainQuiz(&modelQuizzes[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert model to domain quiz: %w", err)
+		}
+		quizzes = append(quizzes, quiz)
+	}
+	return quizzes, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuizStatusRepository_ChangeQuizStatus(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXQuizStatusRepository(db)
+	change := &domain.QuizStatusChange{QuizID: "quiz1", FromStatus: domain.QuizStatusDraft, ToStatus: domain.QuizStatusPublished, Actor: "admin1"}
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE quizzes SET status = :1, updated_at = :2 WHERE id = :3 AND status = :4 AND deleted_at IS NULL`)).
+		WithArgs(domain.QuizStatusPublished, sqlmock.AnyArg(), "quiz1", domain.QuizStatusDraft).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO quiz_status_changes`)).
+		WithArgs(sqlmock.AnyArg(), "quiz1", domain.QuizStatusDraft, domain.QuizStatusPublished, "admin1", nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.ChangeQuizStatus(context.Background(), change)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, change.ID)
+	assert.False(t, change.CreatedAt.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuizStatusRepository_ChangeQuizStatus_Conflict(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXQuizStatusRepository(db)
+	change := &domain.QuizStatusChange{QuizID: "quiz1", FromStatus: domain.QuizStatusDraft, ToStatus: domain.QuizStatusRetired}
+
+	// Someone else moved the quiz first, so nothing is audited.
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE quizzes SET status = :1`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.ChangeQuizStatus(context.Background(), change)
+	assert.True(t, errors.Is(err, domain.ErrConflict))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuizStatusRepository_ListQuizStatusChanges(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXQuizStatusRepository(db)
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"ID", "QUIZ_ID", "FROM_STATUS", "TO_STATUS", "ACTOR", "REVIEW_COMMENT", "CREATED_AT"}).
+		AddRow("change1", "quiz1", domain.QuizStatusDraft, domain.QuizStatusRetired, "admin1", "Duplicate question", now)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM quiz_status_changes WHERE quiz_id = :1 ORDER BY created_at ASC`)).
+		WithArgs("quiz1").
+		WillReturnRows(rows)
+
+	changes, err := repo.ListQuizStatusChanges(context.Background(), "quiz1")
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, domain.QuizStatusRetired, changes[0].ToStatus)
+		assert.Equal(t, "admin1", changes[0].Actor)
+		assert.Equal(t, "Duplicate question", changes[0].Comment)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuizStatusRepository_ListQuizzesByStatus(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXQuizStatusRepository(db)
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"ID", "QUESTION", "MODEL_ANSWERS", "KEYWORDS", "DIFFICULTY", "SUB_CATEGORY_ID", "REVISION", "STATUS", "CREATED_AT", "UPDATED_AT", "DELETED_AT"}).
+		AddRow("quiz1", "What is Go?", "A language", "go", 1, "sub1", 1, domain.QuizStatusDraft, now, now, nil)
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE status IN (:1, :2) AND deleted_at IS NULL ORDER BY updated_at ASC, id ASC FETCH FIRST :3 ROWS ONLY`)).
+		WithArgs(domain.QuizStatusDraft, domain.QuizStatusInReview, 50).
+		WillReturnRows(rows)
+
+	quizzes, err := repo.ListQuizzesByStatus(context.Background(), []string{domain.QuizStatusDraft, domain.QuizStatusInReview}, 50)
+	assert.NoError(t, err)
+	if assert.Len(t, quizzes, 1) {
+		assert.Equal(t, "quiz1", quizzes[0].ID)
+		assert.Equal(t, domain.QuizStatusDraft, quizzes[0].Status)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -53,7 +53,7 @@ func TestGetQuizByID(t *testing.T) {
 		AddRow(expectedModelQuiz.ID, expectedModelQuiz.Question, expectedModelQuiz.ModelAnswers, expectedModelQuiz.Keywords, expectedModelQuiz.Difficulty, expectedModelQuiz.SubCategoryID, expectedModelQuiz.CreatedAt, expectedModelQuiz.UpdatedAt, expectedModelQuiz.DeletedAt)
 
 	// Corrected SQL with uppercase aliases to match actual query
-	originalSQL := `SELECT id "ID", question "QUESTION", model_answers "MODEL_ANSWERS", keywords "KEYWORDS", difficulty "DIFFICULTY", sub_category_id "SUB_CATEGORY_ID", created_at "CREATED_AT", updated_at "UPDATED_AT", deleted_at "DELETED_AT" FROM quizzes WHERE id = :1 AND deleted_at IS NULL`
+	originalSQL := `SELECT id "ID", question "QUESTION", model_answers "MODEL_ANSWERS", keywords "KEYWORDS", difficulty "DIFFICULTY", sub_category_id "SUB_CATEGORY_ID", revision "REVISION", status "STATUS", created_at "CREATED_AT", updated_at "UPDATED_AT", deleted_at "DELETED_AT" FROM quizzes WHERE id = :1 AND deleted_at IS NULL`
 
 	mock.ExpectQuery(regexp.QuoteMeta(originalSQL)).
 		WithArgs(testULID).
@@ -141,13 +141,13 @@ func TestGetRandomQuizBySubCategory(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"ID", "QUESTION", "MODEL_ANSWERS", "KEYWORDS", "DIFFICULTY", "SUB_CATEGORY_ID", "CREATED_AT", "UPDATED_AT", "DELETED_AT"}).
 		AddRow(expectedModelQuiz.ID, expectedModelQuiz.Question, expectedModelQuiz.ModelAnswers, expectedModelQuiz.Keywords, expectedModelQuiz.Difficulty, expectedModelQuiz.SubCategoryID, expectedModelQuiz.CreatedAt, expectedModelQuiz.UpdatedAt, expectedModelQuiz.DeletedAt)
 
-	originalSQL := `SELECT id "ID", question "QUESTION", model_answers "MODEL_ANSWERS", keywords "KEYWORDS", difficulty "DIFFICULTY", sub_category_id "SUB_CATEGORY_ID", created_at "CREATED_AT", updated_at "UPDATED_AT", deleted_at "DELETED_AT" FROM quizzes WHERE sub_category_id = :1 AND deleted_at IS NULL ORDER BY DBMS_RANDOM.VALUE FETCH FIRST 1 ROWS ONLY`
+	originalSQL := `SELECT id "ID", question "QUESTION", model_answers "MODEL_ANSWERS", keywords "KEYWORDS", difficulty "DIFFICULTY", sub_category_id "SUB_CATEGORY_ID", created_at "CREATED_AT", updated_at "UPDATED_AT", deleted_at "DELETED_AT" FROM quizzes WHERE sub_category_id = :1 AND status = :2 AND deleted_at IS NULL ORDER BY DBMS_RANDOM.VALUE FETCH FIRST 1 ROWS ONLY`
 
 	mock.ExpectQuery(regexp.QuoteMeta(originalSQL)).
-		WithArgs(testSubCatID).
+		WithArgs(testSubCatID, domain.QuizStatusPublished).
 		WillReturnRows(rows)
 
-	result, err := repo.GetRandomQuizBySubCategory(context.Background(), testSubCatID)
+	result, err := repo.GetRandomQuizBySubCategory(context.Background(), testSubCatID, nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -155,6 +155,55 @@ func TestGetRandomQuizBySubCategory(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetRandomQuizBySubCategory_WithFilter(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewQuizDatabaseAdapter(db)
+
+	now := time.Now()
+	testSubCatID := util.NewULID()
+	excludedID := util.NewULID()
+	expectedModelQuiz := models.Quiz{
+		ID:            util.NewULID(),
+		Question:      "Explain goroutines.",
+		ModelAnswers:  "Lightweight threads managed by the Go runtime",
+		Keywords:      "goroutine" + stringDelimiter + "concurrency",
+		Difficulty:    2,
+		SubCategoryID: testSubCatID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		DeletedAt:     sql.NullTime{},
+	}
+
+	rows := sqlmock.NewRows([]string{"ID", "QUESTION", "MODEL_ANSWERS", "KEYWORDS", "DIFFICULTY", "SUB_CATEGORY_ID", "CREATED_AT", "UPDATED_AT", "DELETED_AT"}).
+		AddRow(expectedModelQuiz.ID, expectedModelQuiz.Question, expectedModelQuiz.ModelAnswers, expectedModelQuiz.Keywords, expectedModelQuiz.Difficulty, expectedModelQuiz.SubCategoryID, expectedModelQuiz.CreatedAt, expectedModelQuiz.UpdatedAt, expectedModelQuiz.DeletedAt)
+
+	filteredSQL := `SELECT id "ID", question "QUESTION", model_answers "MODEL_ANSWERS", keywords "KEYWORDS", difficulty "DIFFICULTY", sub_category_id "SUB_CATEGORY_ID", created_at "CREATED_AT", updated_at "UPDATED_AT", deleted_at "DELETED_AT" FROM quizzes WHERE sub_category_id = :1 AND status = :2 AND deleted_at IS NULL AND difficulty = :3 AND ('|||' || UPPER(keywords) || '|||' LIKE :4 ESCAPE '\' OR '|||' || UPPER(keywords) || '|||' LIKE :5 ESCAPE '\') AND id NOT IN (:6) ORDER BY DBMS_RANDOM.VALUE FETCH FIRST 1 ROWS ONLY`
+
+	mock.ExpectQuery(regexp.QuoteMeta(filteredSQL)).
+		WithArgs(testSubCatID, domain.QuizStatusPublished, 2, "%|||GOROUTINE|||%", "%|||CHANNEL|||%", excludedID).
+		WillReturnRows(rows)
+
+	filter := &domain.QuizFilter{
+		Difficulty: 2,
+		Keywords:   []string{"goroutine", "channel"},
+		ExcludeIDs: []string{excludedID},
+	}
+	result, err := repo.GetRandomQuizBySubCategory(context.Background(), testSubCatID, filter)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, expectedModelQuiz.ID, result.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildQuizFilterConditions_EscapesKeywords(t *testing.T) {
+	conditions, args := buildQuizFilterConditions(&domain.QuizFilter{Keywords: []string{"go", "100%_done\\"}}, 3)
+
+	assert.Equal(t, "\n\tAND ('|||' || UPPER(keywords) || '|||' LIKE :3 ESCAPE '\\' OR '|||' || UPPER(keywords) || '|||' LIKE :4 ESCAPE '\\')", conditions)
+	// Whole entries only, with LIKE wildcards in a keyword matched literally
+	assert.Equal(t, []interface{}{"%|||GO|||%", `%|||100\%\_DONE\\|||%`}, args)
+}
+
 func TestGetRandomQuiz(t *testing.T) {
 	db, mock := setupTestDB(t)
 	repo := NewQuizDatabaseAdapter(db)
@@ -186,11 +235,13 @@ func TestGetRandomQuiz(t *testing.T) {
 		updated_at "UPDATED_AT",
 		deleted_at "DELETED_AT"
 	FROM quizzes
-	WHERE deleted_at IS NULL
+	WHERE status = :1
+	AND deleted_at IS NULL
 	ORDER BY DBMS_RANDOM.VALUE
 	FETCH FIRST 1 ROWS ONLY`
 
 	mock.ExpectQuery(regexp.QuoteMeta(originalSQL)).
+		WithArgs(domain.QuizStatusPublished).
 		WillReturnRows(rows)
 
 	result, err := repo.GetRandomQuiz(context.Background())
@@ -209,7 +260,7 @@ func TestGetQuizByID_NotFound(t *testing.T) {
 	repo := NewQuizDatabaseAdapter(db)
 	testULID := util.NewULID()
 
-	originalSQL := `SELECT id "ID", question "QUESTION", model_answers "MODEL_ANSWERS", keywords "KEYWORDS", difficulty "DIFFICULTY", sub_category_id "SUB_CATEGORY_ID", created_at "CREATED_AT", updated_at "UPDATED_AT", deleted_at "DELETED_AT" FROM quizzes WHERE id = :1 AND deleted_at IS NULL`
+	originalSQL := `SELECT id "ID", question "QUESTION", model_answers "MODEL_ANSWERS", keywords "KEYWORDS", difficulty "DIFFICULTY", sub_category_id "SUB_CATEGORY_ID", revision "REVISION", status "STATUS", created_at "CREATED_AT", updated_at "UPDATED_AT", deleted_at "DELETED_AT" FROM quizzes WHERE id = :1 AND deleted_at IS NULL`
 
 	mock.ExpectQuery(regexp.QuoteMeta(originalSQL)).
 		WithArgs(testULID).
@@ -222,6 +273,90 @@ func TestGetQuizByID_NotFound(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestDeleteQuiz_SoftDeletes(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewQuizDatabaseAdapter(db)
+	testULID := util.NewULID()
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE quizzes SET deleted_at = :1, updated_at = :2 WHERE id = :3 AND deleted_at IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), testULID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	deleted, err := repo.DeleteQuiz(context.Background(), testULID)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateQuiz_RecordsRevision(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewQuizDatabaseAdapter(db)
+	quiz := &domain.Quiz{ID: util.NewULID(), Question: "Q?", ModelAnswers: []string{"A", "B"}, Keywords: []string{"k"}, Difficulty: 2, SubCategoryID: "sub-1", Revision: 3}
+
+	mock.ExpectExec(`UPDATE quizzes SET .* revision = :6, updated_at = :7 WHERE id = :8 AND revision = :9 AND deleted_at IS NULL`).
+		WithArgs("Q?", "A"+stringDelimiter+"B", "k", 2, "sub-1", 4, sqlmock.AnyArg(), quiz.ID, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO quiz_revisions`)).
+		WithArgs(quiz.ID, 4, "Q?", "A"+stringDelimiter+"B", "k", 2, "sub-1", sql.NullString{String: "admin-1", Valid: true}, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.UpdateQuiz(context.Background(), quiz, "admin-1"))
+	assert.Equal(t, 4, quiz.Revision)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateQuiz_StaleRevision(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewQuizDatabaseAdapter(db)
+	quiz := &domain.Quiz{ID: util.NewULID(), Question: "Q?", ModelAnswers: []string{"A"}, Difficulty: 1, SubCategoryID: "sub-1", Revision: 3}
+
+	mock.ExpectExec(`UPDATE quizzes SET`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UpdateQuiz(context.Background(), quiz, "admin-1")
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	assert.Equal(t, 3, quiz.Revision)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListQuizRevisions(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewQuizDatabaseAdapter(db)
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"QUIZ_ID", "REVISION", "QUESTION", "MODEL_ANSWERS", "KEYWORDS", "DIFFICULTY", "SUB_CATEGORY_ID", "AUTHOR", "CREATED_AT"}).
+		AddRow("quiz-1", 2, "Q2?", "A", "k", 1, "sub-1", "admin-1", now).
+		AddRow("quiz-1", 1, "Q1?", "A", "k", 1, "sub-1", nil, now)
+	mock.ExpectQuery(`FROM quiz_revisions WHERE quiz_id = :1 ORDER BY revision DESC`).WithArgs("quiz-1").WillReturnRows(rows)
+
+	revisions, err := repo.ListQuizRevisions(context.Background(), "quiz-1")
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+	assert.Equal(t, "admin-1", revisions[0].Author)
+	assert.Equal(t, "", revisions[1].Author)
+	assert.Equal(t, "Q1?", revisions[1].Question)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateQuizEvaluation(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewQuizDatabaseAdapter(db)
+	evaluation := &domain.QuizEvaluation{
+		QuizID:           util.NewULID(),
+		MinimumKeywords:  2,
+		ScoreRanges:      []string{"0.0-1.0"},
+		ScoreEvaluations: []domain.ScoreEvaluationDetail{{ScoreRange: "0.0-1.0", SampleAnswers: []string{"a"}, Explanation: "e"}},
+	}
+
+	mock.ExpectExec(`UPDATE quiz_evaluations SET\s+minimum_keywords = :1,.*score_evaluations = :6,\s+updated_at = :7\s+WHERE quiz_id = :8\s+AND deleted_at IS NULL`).
+		WithArgs(2, sql.NullString{}, sql.NullString{String: "0.0-1.0", Valid: true}, sql.NullString{}, sql.NullString{},
+			sqlmock.AnyArg(), sqlmock.AnyArg(), evaluation.QuizID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.UpdateQuizEvaluation(context.Background(), evaluation))
+	assert.NotZero(t, evaluation.UpdatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSaveAnswer(t *testing.T) {
 	db, mock := setupTestDB(t)
 	repo := NewQuizDatabaseAdapter(db)
@@ -316,15 +451,16 @@ func TestGetSimilarQuiz(t *testing.T) {
 	FROM quizzes
 	WHERE id != :1
 	AND sub_category_id = :2
-	AND difficulty = :3
+	AND status = :3
 	AND deleted_at IS NULL
+	AND difficulty = :4
 	ORDER BY DBMS_RANDOM.VALUE
 	FETCH FIRST 1 ROWS ONLY`
 	mock.ExpectQuery(regexp.QuoteMeta(originalQuerySimilar)).
-		WithArgs(currentQuizID, subCatID, difficulty).
+		WithArgs(currentQuizID, subCatID, domain.QuizStatusPublished, difficulty).
 		WillReturnRows(rowsSimilar)
 
-	result, err := repo.GetSimilarQuiz(context.Background(), currentQuizID)
+	result, err := repo.GetSimilarQuiz(context.Background(), currentQuizID, nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -332,6 +468,41 @@ func TestGetSimilarQuiz(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetSimilarQuiz_WithDifficultyShiftAndAttemptExclusion(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewQuizDatabaseAdapter(db)
+
+	currentQuizID := util.NewULID()
+	subCatID := util.NewULID()
+	userID := util.NewULID()
+
+	queryCurrent := `SELECT difficulty "DIFFICULTY", sub_category_id "SUB_CATEGORY_ID" FROM quizzes WHERE id = :1 AND deleted_at IS NULL`
+	mock.ExpectQuery(regexp.QuoteMeta(queryCurrent)).
+		WithArgs(currentQuizID).
+		WillReturnRows(sqlmock.NewRows([]string{"DIFFICULTY", "SUB_CATEGORY_ID"}).AddRow(2, subCatID))
+
+	now := time.Now()
+	nextQuizID := util.NewULID()
+	rowsSimilar := sqlmock.NewRows([]string{"ID", "QUESTION", "MODEL_ANSWERS", "KEYWORDS", "DIFFICULTY", "SUB_CATEGORY_ID", "CREATED_AT", "UPDATED_AT", "DELETED_AT"}).
+		AddRow(nextQuizID, "A harder question?", "Harder answer", "hard", 3, subCatID, now, now, sql.NullTime{})
+
+	querySimilar := `SELECT id "ID", question "QUESTION", model_answers "MODEL_ANSWERS", keywords "KEYWORDS", difficulty "DIFFICULTY", sub_category_id "SUB_CATEGORY_ID", created_at "CREATED_AT", updated_at "UPDATED_AT", deleted_at "DELETED_AT" FROM quizzes WHERE id != :1 AND sub_category_id = :2 AND status = :3 AND deleted_at IS NULL AND difficulty = :4 AND id NOT IN (SELECT quiz_id FROM user_quiz_attempts WHERE user_id = :5) ORDER BY DBMS_RANDOM.VALUE FETCH FIRST 1 ROWS ONLY`
+	mock.ExpectQuery(regexp.QuoteMeta(querySimilar)).
+		WithArgs(currentQuizID, subCatID, domain.QuizStatusPublished, 3, userID).
+		WillReturnRows(rowsSimilar)
+
+	result, err := repo.GetSimilarQuiz(context.Background(), currentQuizID, &domain.QuizFilter{
+		Difficulty:               3,
+		ExcludeAttemptedByUserID: userID,
+	})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, nextQuizID, result.ID)
+	assert.Equal(t, 3, result.Difficulty)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetSimilarQuiz_CurrentQuizNotFound(t *testing.T) {
 	db, mock := setupTestDB(t)
 	repo := NewQuizDatabaseAdapter(db)
@@ -347,7 +518,7 @@ func TestGetSimilarQuiz_CurrentQuizNotFound(t *testing.T) {
 		WithArgs(currentQuizID).
 		WillReturnError(sql.ErrNoRows)
 
-	result, err := repo.GetSimilarQuiz(context.Background(), currentQuizID)
+	result, err := repo.GetSimilarQuiz(context.Background(), currentQuizID, nil)
 	assert.NoError(t, err)
 	assert.Nil(t, result)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -384,15 +555,16 @@ func TestGetSimilarQuiz_SimilarQuizNotFound(t *testing.T) {
 	FROM quizzes
 	WHERE id != :1
 	AND sub_category_id = :2
-	AND difficulty = :3
+	AND status = :3
 	AND deleted_at IS NULL
+	AND difficulty = :4
 	ORDER BY DBMS_RANDOM.VALUE
 	FETCH FIRST 1 ROWS ONLY`
 	mock.ExpectQuery(regexp.QuoteMeta(originalQuerySimilar)).
-		WithArgs(currentQuizID, subCatID, difficulty).
+		WithArgs(currentQuizID, subCatID, domain.QuizStatusPublished, difficulty).
 		WillReturnError(sql.ErrNoRows)
 
-	result, err := repo.GetSimilarQuiz(context.Background(), currentQuizID)
+	result, err := repo.GetSimilarQuiz(context.Background(), currentQuizID, nil)
 
 	assert.NoError(t, err)
 	assert.Nil(t, result)
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/repository/models"
+	"quiz-byte/internal/util"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// sqlxRoleRepository implements domain.RoleRepository using sqlx.
+type sqlxRoleRepository struct {
+	db DBTX
+}
+
+// NewSQLXRoleRepository creates a new instance of sqlxRoleRepository.
+func NewSQLXRoleRepository(db *sqlx.DB) domain.RoleRepository {
+	return &sqlxRoleRepository{db: db}
+}
+
+// GetRole retrieves a role by name.
+func (r *sqlxRoleRepository) GetRole(ctx context.Context, name string) (*domain.Role, error) {
+	var model models.Role
+	query := `SELECT name, description, created_at FROM roles WHERE name = :1`
+
+	if err := GetExecutor(ctx, r.db).GetContext(ctx, &model, query, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Return nil, nil for not found
+		}
+		return nil, fmt.Errorf("failed to get role %s: %w", name, err)
+	}
+	return &domain.Role{Name: model.Name, Description: model.Description.String, CreatedAt: model.CreatedAt}, nil
+}
+
+// ListUserRoles retrieves the role names of an active user.
+func (r *sqlxRoleRepository) ListUserRoles(ctx context.Context, userID string) ([]string, error) {
+	roles := []string{}
+	query := `SELECT ur.role_name FROM user_roles ur
+	          JOIN users u ON u.id = ur.user_id
+	          WHERE ur.user_id = :1 AND u.deleted_at IS NULL
+	          ORDER BY ur.role_name ASC`
+
+	if err := GetExecutor(ctx, r.db).SelectContext(ctx, &roles, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list roles of user %s: %w", userID, err)
+	}
+	return roles, nil
+}
+
+// ListRoleMembers retrieves the active users a role was granted to.
+func (r *sqlxRoleRepository) ListRoleMembers(ctx context.Context, role string) ([]domain.UserRole, error) {
+	var modelRoles []models.UserRole
+	query := `SELECT ur.user_id, u.email, ur.role_name, ur.granted_by, ur.granted_at FROM user_roles ur
+	          JOIN users u ON u.id = ur.user_id
+	          WHERE ur.role_name = :1 AND u.deleted_at IS NULL
+	          ORDER BY ur.granted_at ASC, ur.user_id ASC`
+
+	if err := GetExecutor(ctx, r.db).SelectContext(ctx, &modelRoles, query, role); err != nil {
+		return nil, fmt.Errorf("failed to list members of role %s: %w", role, err)
+	}
+	members := make([]domain.UserRole, 0, len(modelRoles))
+	for _, model := range modelRoles {
+		members = append(members, domain.UserRole{
+			UserID:    model.UserID,
+			Email:     model.Email,
+			Role:      model.RoleName,
+			GrantedBy: model.GrantedBy.String,
+			GrantedAt: model.GrantedAt,
+		})
+	}
+	return members, nil
+}
+
+// GrantRole inserts the grant unless the user already has the role.
+func (r *sqlxRoleRepository) GrantRole(ctx context.Context, grant *domain.UserRole) (bool, error) {
+	grantedAt := grant.GrantedAt
+	if grantedAt.IsZero() {
+		grantedAt = time.Now()
+	}
+	query := `MERGE INTO user_roles ur
+	          USING (SELECT :1 AS user_id, :2 AS role_name FROM dual) g
+	          ON (ur.user_id = g.user_id AND ur.role_name = g.role_name)
+	          WHEN NOT MATCHED THEN INSERT (user_id, role_name, granted_by, granted_at)
+	          VALUES (g.user_id, g.role_name, :3, :4)`
+
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, grant.UserID, grant.Role, util.StringToNullString(grant.GrantedBy), grantedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to grant role %s to user %s: %w", grant.Role, grant.UserID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// RevokeRole deletes the grant of the role.
+func (r *sqlxRoleRepository) RevokeRole(ctx context.Context, userID string, role string) (bool, error) {
+	query := `DELETE FROM user_roles WHERE user_id = :1 AND role_name = :2`
+
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, userID, role)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke role %s of user %s: %w", role, userID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleRepository_GetRole(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXRoleRepository(db)
+	query := regexp.QuoteMeta(`FROM roles WHERE name = :1`)
+
+	rows := sqlmock.NewRows([]string{"NAME", "DESCRIPTION", "CREATED_AT"}).AddRow(domain.RoleAdmin, "Admins", time.Now())
+	mock.ExpectQuery(query).WithArgs(domain.RoleAdmin).WillReturnRows(rows)
+	role, err := repo.GetRole(context.Background(), domain.RoleAdmin)
+	assert.NoError(t, err)
+	if assert.NotNil(t, role) {
+		assert.Equal(t, "Admins", role.Description)
+	}
+
+	mock.ExpectQuery(query).WithArgs("editor").WillReturnError(sql.ErrNoRows)
+	role, err = repo.GetRole(context.Background(), "editor")
+	assert.NoError(t, err)
+	assert.Nil(t, role)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_ListUserRoles(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXRoleRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE ur.user_id = :1 AND u.deleted_at IS NULL`)).
+		WithArgs("user1").
+		WillReturnRows(sqlmock.NewRows([]string{"ROLE_NAME"}).AddRow(domain.RoleAdmin))
+	roles, err := repo.ListUserRoles(context.Background(), "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.RoleAdmin}, roles)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM user_roles`)).
+		WithArgs("user2").
+		WillReturnRows(sqlmock.NewRows([]string{"ROLE_NAME"}))
+	roles, err = repo.ListUserRoles(context.Background(), "user2")
+	assert.NoError(t, err)
+	assert.Empty(t, roles)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_GrantRole(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXRoleRepository(db)
+	now := time.Now()
+	query := regexp.QuoteMeta(`MERGE INTO user_roles`)
+	grant := &domain.UserRole{UserID: "user1", Role: domain.RoleAdmin, GrantedBy: "cli:root", GrantedAt: now}
+
+	mock.ExpectExec(query).WithArgs("user1", domain.RoleAdmin, "cli:root", now).WillReturnResult(sqlmock.NewResult(0, 1))
+	granted, err := repo.GrantRole(context.Background(), grant)
+	assert.NoError(t, err)
+	assert.True(t, granted)
+
+	mock.ExpectExec(query).WithArgs("user1", domain.RoleAdmin, "cli:root", now).WillReturnResult(sqlmock.NewResult(0, 0))
+	granted, err = repo.GrantRole(context.Background(), grant)
+	assert.NoError(t, err)
+	assert.False(t, granted, "granting a role twice is a no-op")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_RevokeRole(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXRoleRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM user_roles WHERE user_id = :1 AND role_name = :2`)).
+		WithArgs("user1", domain.RoleAdmin).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	revoked, err := repo.RevokeRole(context.Background(), "user1", domain.RoleAdmin)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
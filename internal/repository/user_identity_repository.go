@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/repository/models"
+	"quiz-byte/internal/util"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const userIdentityColumns = `id, user_id, provider, subject, email, last_login_at, created_at, updated_at`
+
+// sqlxUserIdentityRepository implements domain.UserIdentityRepository using sqlx.
+type sqlxUserIdentityRepository struct {
+	db DBTX
+}
+
+// NewSQLXUserIdentityRepository creates a new instance of sqlxUserIdentityRepository.
+func NewSQLXUserIdentityRepository(db *sqlx.DB) domain.UserIdentityRepository {
+	return &sqlxUserIdentityRepository{db: db}
+}
+
+func toDomainUserIdentity(model *models.UserIdentity) *domain.UserIdentity {
+	return &domain.UserIdentity{
+		ID:          model.ID,
+		UserID:      model.UserID,
+		Provider:    model.Provider,
+		Subject:     model.Subject,
+		Email:       model.Email.String,
+		LastLoginAt: model.LastLoginAt.Time,
+		CreatedAt:   model.CreatedAt,
+	}
+}
+
+// GetIdentity retrieves the identity of a provider account.
+func (r *sqlxUserIdentityRepository) GetIdentity(ctx context.Context, provider string, subject string) (*domain.UserIdentity, error) {
+	var model models.UserIdentity
+	query := `SELECT ` + userIdentityColumns + ` FROM user_identities WHERE provider = :1 AND subject = :2`
+
+	if err := GetExecutor(ctx, r.db).GetContext(ctx, &model, query, provider, subject); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Return nil, nil for not found
+		}
+		return nil, fmt.Errorf("failed to get %s identity: %w", provider, err)
+	}
+	return toDomainUserIdentity(&model), nil
+}
+
+// CreateIdentity links a provider account to a user.
+func (r *sqlxUserIdentityRepository) CreateIdentity(ctx context.Context, identity *domain.UserIdentity) error {
+	now := time.Now()
+	createdAt := identity.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+	var lastLoginAt sql.NullTime
+	if !identity.LastLoginAt.IsZero() {
+		lastLoginAt = util.TimeToNullTime(identity.LastLoginAt)
+	}
+
+	query := `INSERT INTO user_identities (id, user_id, provider, subject, email, last_login_at, created_at, updated_at)
+	          VALUES (:1, :2, :3, :4, :5, :6, :7, :8)`
+	_, err := GetExecutor(ctx, r.db).ExecContext(ctx, query,
+		identity.ID,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		util.StringToNullString(identity.Email),
+		lastLoginAt,
+		createdAt,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create user identity: %w", err)
+	}
+	return nil
+}
+
+// UpdateIdentityLogin records a sign-in with the identity.
+func (r *sqlxUserIdentityRepository) UpdateIdentityLogin(ctx context.Context, identityID string, email string, at time.Time) error {
+	query := `UPDATE user_identities SET email = :1, last_login_at = :2 WHERE id = :3`
+
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, util.StringToNullString(email), at, identityID)
+	if err != nil {
+		return fmt.Errorf("failed to update user identity %s: %w", identityID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user identity %s not found: %w", identityID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// ListIdentitiesByUserID retrieves the identities linked to a user.
+func (r *sqlxUserIdentityRepository) ListIdentitiesByUserID(ctx context.Context, userID string) ([]domain.UserIdentity, error) {
+	var modelIdentities []models.UserIdentity
+	query := `SELECT ` + userIdentityColumns + ` FROM user_identities WHERE user_id = :1 ORDER BY created_at ASC, id ASC`
+
+	if err := GetExecutor(ctx, r.db).SelectContext(ctx, &modelIdentities, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list identities of user %s: %w", userID, err)
+	}
+	identities := make([]domain.UserIdentity, 0, len(modelIdentities))
+	for i := range modelIdentities {
+		identities = append(identities, *toDomainUserIdentity(&modelIdentities[i]))
+	}
+	return identities, nil
+}
+
+// DeleteIdentitiesByUserID removes all identities of a user.
+func (r *sqlxUserIdentityRepository) DeleteIdentitiesByUserID(ctx context.Context, userID string) error {
+	query := `DELETE FROM user_identities WHERE user_id = :1`
+
+	if _, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to delete identities of user %s: %w", userID, err)
+	}
+	return nil
+}
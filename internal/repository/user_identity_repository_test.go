@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var userIdentityTestColumns = []string{"ID", "USER_ID", "PROVIDER", "SUBJECT", "EMAIL", "LAST_LOGIN_AT", "CREATED_AT", "UPDATED_AT"}
+
+func TestUserIdentityRepository_CreateIdentity(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXUserIdentityRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_identities`)).
+		WithArgs("identity1", "user1", domain.IdentityProviderGitHub, "12345", "user@example.com", now, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.CreateIdentity(context.Background(), &domain.UserIdentity{
+		ID: "identity1", UserID: "user1", Provider: domain.IdentityProviderGitHub, Subject: "12345",
+		Email: "user@example.com", LastLoginAt: now,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserIdentityRepository_GetIdentity(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXUserIdentityRepository(db)
+	now := time.Now()
+	query := regexp.QuoteMeta(`FROM user_identities WHERE provider = :1 AND subject = :2`)
+
+	rows := sqlmock.NewRows(userIdentityTestColumns).
+		AddRow("identity1", "user1", domain.IdentityProviderGoogle, "google-123", nil, nil, now, now)
+	mock.ExpectQuery(query).WithArgs(domain.IdentityProviderGoogle, "google-123").WillReturnRows(rows)
+
+	identity, err := repo.GetIdentity(context.Background(), domain.IdentityProviderGoogle, "google-123")
+	assert.NoError(t, err)
+	if assert.NotNil(t, identity) {
+		assert.Equal(t, "user1", identity.UserID)
+		assert.Empty(t, identity.Email)
+		assert.True(t, identity.LastLoginAt.IsZero())
+	}
+
+	mock.ExpectQuery(query).WithArgs(domain.IdentityProviderGoogle, "missing").WillReturnError(sql.ErrNoRows)
+	identity, err = repo.GetIdentity(context.Background(), domain.IdentityProviderGoogle, "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, identity)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserIdentityRepository_UpdateIdentityLogin(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXUserIdentityRepository(db)
+	now := time.Now()
+	query := regexp.QuoteMeta(`UPDATE user_identities SET email = :1, last_login_at = :2 WHERE id = :3`)
+
+	mock.ExpectExec(query).WithArgs("new@example.com", now, "identity1").WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.UpdateIdentityLogin(context.Background(), "identity1", "new@example.com", now))
+
+	mock.ExpectExec(query).WithArgs("new@example.com", now, "missing").WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.UpdateIdentityLogin(context.Background(), "missing", "new@example.com", now), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserIdentityRepository_ListAndDeleteIdentities(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXUserIdentityRepository(db)
+	now := time.Now()
+
+	rows := sqlmock.NewRows(userIdentityTestColumns).
+		AddRow("identity1", "user1", domain.IdentityProviderGoogle, "google-123", "user@example.com", now, now, now).
+		AddRow("identity2", "user1", "corp", "corp-sub", "user@corp.example", now, now, now)
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM user_identities WHERE user_id = :1 ORDER BY created_at ASC`)).
+		WithArgs("user1").
+		WillReturnRows(rows)
+	identities, err := repo.ListIdentitiesByUserID(context.Background(), "user1")
+	assert.NoError(t, err)
+	if assert.Len(t, identities, 2) {
+		assert.Equal(t, "corp", identities[1].Provider)
+		assert.Equal(t, "user@corp.example", identities[1].Email)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM user_identities WHERE user_id = :1`)).
+		WithArgs("user1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	assert.NoError(t, repo.DeleteIdentitiesByUserID(context.Background(), "user1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
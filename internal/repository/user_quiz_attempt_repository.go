@@ -54,6 +54,7 @@ func toDomainUserQuizAttempt(modelAttempt *models.UserQuizAttempt) *domain.UserQ
 		LLMRelevance:      modelAttempt.LlmRelevance.Float64,
 		LLMAccuracy:       modelAttempt.LlmAccuracy.Float64,
 		IsCorrect:         modelAttempt.IsCorrect,
+		QuizRevision:      int(modelAttempt.QuizRevision.Int64),
 		AttemptedAt:       modelAttempt.AttemptedAt,
 		CreatedAt:         modelAttempt.CreatedAt,
 		UpdatedAt:         modelAttempt.UpdatedAt,
@@ -91,6 +92,7 @@ func fromDomainUserQuizAttempt(domainAttempt *domain.UserQuizAttempt) *models.Us
 		LlmRelevance:      sql.NullFloat64{Float64: domainAttempt.LLMRelevance, Valid: true},
 		LlmAccuracy:       sql.NullFloat64{Float64: domainAttempt.LLMAccuracy, Valid: true},
 		IsCorrect:         domainAttempt.IsCorrect,
+		QuizRevision:      sql.NullInt64{Int64: int64(domainAttempt.QuizRevision), Valid: domainAttempt.QuizRevision > 0},
 		AttemptedAt:       domainAttempt.AttemptedAt,
 		CreatedAt:         domainAttempt.CreatedAt,
 		UpdatedAt:         domainAttempt.UpdatedAt,
@@ -110,8 +112,8 @@ func (r *sqlxUserQuizAttemptRepository) CreateAttempt(ctx context.Context, domai
 	}
 	modelAttempt.UpdatedAt = time.Now()
 
-	query := `INSERT INTO user_quiz_attempts (ID, USER_ID, QUIZ_ID, USER_ANSWER, LLM_SCORE, LLM_EXPLANATION, LLM_KEYWORD_MATCHES, LLM_COMPLETENESS, LLM_RELEVANCE, LLM_ACCURACY, IS_CORRECT, ATTEMPTED_AT, CREATED_AT, UPDATED_AT, DELETED_AT)
-	          VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14, :15)`
+	query := `INSERT INTO user_quiz_attempts (ID, USER_ID, QUIZ_ID, USER_ANSWER, LLM_SCORE, LLM_EXPLANATION, LLM_KEYWORD_MATCHES, LLM_COMPLETENESS, LLM_RELEVANCE, LLM_ACCURACY, IS_CORRECT, QUIZ_REVISION, ATTEMPTED_AT, CREATED_AT, UPDATED_AT, DELETED_AT)
+	          VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14, :15, :16)`
 
 	// Convert StringSlice to string manually for Oracle compatibility
 	var keywordMatchesStr string
@@ -125,7 +127,8 @@ func (r *sqlxUserQuizAttemptRepository) CreateAttempt(ctx context.Context, domai
 		}
 	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	// Joins the transaction in ctx, if any, e.g. when the attempt outbox dispatcher records an entry.
+	_, err := GetExecutor(ctx, r.db).ExecContext(ctx, query,
 		modelAttempt.ID,
 		modelAttempt.UserID,
 		modelAttempt.QuizID,
@@ -137,6 +140,7 @@ func (r *sqlxUserQuizAttemptRepository) CreateAttempt(ctx context.Context, domai
 		modelAttempt.LlmRelevance,
 		modelAttempt.LlmAccuracy,
 		modelAttempt.IsCorrect,
+		modelAttempt.QuizRevision,
 		modelAttempt.AttemptedAt,
 		modelAttempt.CreatedAt,
 		modelAttempt.UpdatedAt,
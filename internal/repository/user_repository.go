@@ -33,8 +33,7 @@ func toDomainUser(modelUser *models.User) *domain.User {
 	}
 	return &domain.User{
 		ID:                modelUser.ID,
-		GoogleID:          modelUser.GoogleID,
-		Email:             modelUser.Email,
+		Email:             modelUser.Email.String,
 		Name:              modelUser.Name.String,
 		ProfilePictureURL: modelUser.ProfilePictureURL.String,
 		CreatedAt:         modelUser.CreatedAt,
@@ -53,8 +52,7 @@ func fromDomainUser(domainUser *domain.User) *models.User {
 	}
 	return &models.User{
 		ID:                domainUser.ID,
-		GoogleID:          domainUser.GoogleID,
-		Email:             domainUser.Email,
+		Email:             util.StringToNullString(domainUser.Email),
 		Name:              util.StringToNullString(domainUser.Name),
 		ProfilePictureURL: util.StringToNullString(domainUser.ProfilePictureURL),
 		CreatedAt:         domainUser.CreatedAt,
@@ -78,13 +76,12 @@ func (r *sqlxUserRepository) CreateUser(ctx context.Context, domainUser *domain.
 		modelUser.UpdatedAt = time.Now()
 	}
 
-	query := `INSERT INTO users (id, google_id, email, name, profile_picture_url, created_at, updated_at, deleted_at)
-	          VALUES (:1, :2, :3, :4, :5, :6, :7, :8)`
+	query := `INSERT INTO users (id, email, name, profile_picture_url, created_at, updated_at, deleted_at)
+	          VALUES (:1, :2, :3, :4, :5, :6, :7)`
 	// Note: Removed token fields from insert as they are not in domain.User
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := GetExecutor(ctx, r.db).ExecContext(ctx, query,
 		modelUser.ID,
-		modelUser.GoogleID,
 		modelUser.Email,
 		modelUser.Name,
 		modelUser.ProfilePictureURL,
@@ -97,18 +94,18 @@ func (r *sqlxUserRepository) CreateUser(ctx context.Context, domainUser *domain.
 	return nil
 }
 
-// GetUserByGoogleID retrieves a user by their Google ID.
-func (r *sqlxUserRepository) GetUserByGoogleID(ctx context.Context, googleID string) (*domain.User, error) {
+// GetUserByEmail retrieves a user by their email address.
+func (r *sqlxUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var modelUser models.User
-	query := `SELECT id, google_id, email, name, profile_picture_url, encrypted_access_token, encrypted_refresh_token, token_expires_at, created_at, updated_at, deleted_at FROM users WHERE google_id = :1 AND deleted_at IS NULL`
+	query := `SELECT id, email, name, profile_picture_url, encrypted_access_token, encrypted_refresh_token, token_expires_at, created_at, updated_at, deleted_at FROM users WHERE email = :1 AND deleted_at IS NULL`
 
-	err := r.db.GetContext(ctx, &modelUser, query, googleID)
+	err := GetExecutor(ctx, r.db).GetContext(ctx, &modelUser, query, email)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Return nil, nil for not found
 		}
-		return nil, fmt.Errorf("failed to get user by google_id: %w", err)
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 	return toDomainUser(&modelUser), nil
 }
@@ -116,9 +113,9 @@ func (r *sqlxUserRepository) GetUserByGoogleID(ctx context.Context, googleID str
 // GetUserByID retrieves a user by their internal ID.
 func (r *sqlxUserRepository) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
 	var modelUser models.User
-	query := `SELECT id, google_id, email, name, profile_picture_url, encrypted_access_token, encrypted_refresh_token, token_expires_at, created_at, updated_at, deleted_at FROM users WHERE id = :1 AND deleted_at IS NULL`
+	query := `SELECT id, email, name, profile_picture_url, encrypted_access_token, encrypted_refresh_token, token_expires_at, created_at, updated_at, deleted_at FROM users WHERE id = :1 AND deleted_at IS NULL`
 
-	err := r.db.GetContext(ctx, &modelUser, query, userID)
+	err := GetExecutor(ctx, r.db).GetContext(ctx, &modelUser, query, userID)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -148,7 +145,7 @@ func (r *sqlxUserRepository) UpdateUser(ctx context.Context, domainUser *domain.
 	// modelUser.DeletedAt will be sql.NullTime{Valid:false}, preserving existing DB value if not changing.
 	// If domainUser.DeletedAt is set, it will update the DB field.
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query,
 		modelUser.Email,
 		modelUser.Name,
 		modelUser.ProfilePictureURL,
@@ -173,3 +170,134 @@ func (r *sqlxUserRepository) UpdateUser(ctx context.Context, domainUser *domain.
 
 	return nil
 }
+
+func toDomainGoogleTokens(modelUser *models.User) *domain.GoogleTokens {
+	return &domain.GoogleTokens{
+		UserID:                modelUser.ID,
+		EncryptedAccessToken:  modelUser.EncryptedAccessToken.String,
+		EncryptedRefreshToken: modelUser.EncryptedRefreshToken.String,
+		ExpiresAt:             modelUser.TokenExpiresAt.Time,
+		RefreshFailures:       modelUser.TokenRefreshFailures,
+	}
+}
+
+// UpdateGoogleTokens stores the encrypted Google tokens of a user.
+func (r *sqlxUserRepository) UpdateGoogleTokens(ctx context.Context, tokens *domain.GoogleTokens) error {
+	query := `UPDATE users SET
+	            encrypted_access_token = :1,
+	            encrypted_refresh_token = COALESCE(:2, encrypted_refresh_token),
+	            token_expires_at = :3,
+	            token_refresh_failures = 0,
+	            token_refresh_retry_at = NULL,
+	            updated_at = :4
+	          WHERE id = :5 AND deleted_at IS NULL`
+
+	var expiresAt sql.NullTime
+	if !tokens.ExpiresAt.IsZero() {
+		expiresAt = util.TimeToNullTime(tokens.ExpiresAt)
+	}
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query,
+		util.StringToNullString(tokens.EncryptedAccessToken),
+		util.StringToNullString(tokens.EncryptedRefreshToken),
+		expiresAt,
+		time.Now(),
+		tokens.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update google tokens: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// GetGoogleTokens retrieves the encrypted Google tokens of a user.
+func (r *sqlxUserRepository) GetGoogleTokens(ctx context.Context, userID string) (*domain.GoogleTokens, error) {
+	var modelUser models.User
+	query := `SELECT id, encrypted_access_token, encrypted_refresh_token, token_expires_at FROM users
+	          WHERE id = :1 AND deleted_at IS NULL`
+
+	if err := GetExecutor(ctx, r.db).GetContext(ctx, &modelUser, query, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Return nil, nil for not found
+		}
+		return nil, fmt.Errorf("failed to get google tokens: %w", err)
+	}
+	if !modelUser.EncryptedAccessToken.Valid && !modelUser.EncryptedRefreshToken.Valid {
+		return nil, nil
+	}
+	return toDomainGoogleTokens(&modelUser), nil
+}
+
+// ListExpiringGoogleTokens retrieves refreshable Google tokens that expire before the given time.
+func (r *sqlxUserRepository) ListExpiringGoogleTokens(ctx context.Context, before time.Time, limit int) ([]domain.GoogleTokens, error) {
+	var modelUsers []models.User
+	query := fmt.Sprintf(`SELECT id, encrypted_access_token, encrypted_refresh_token, token_expires_at, token_refresh_failures FROM users
+	          WHERE deleted_at IS NULL AND encrypted_refresh_token IS NOT NULL AND token_expires_at < :1
+	            AND (token_refresh_retry_at IS NULL OR token_refresh_retry_at <= :2)
+	          ORDER BY token_expires_at ASC
+	          FETCH FIRST %d ROWS ONLY`, limit)
+
+	if err := GetExecutor(ctx, r.db).SelectContext(ctx, &modelUsers, query, before, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to list expiring google tokens: %w", err)
+	}
+	tokens := make([]domain.GoogleTokens, 0, len(modelUsers))
+	for i := range modelUsers {
+		tokens = append(tokens, *toDomainGoogleTokens(&modelUsers[i]))
+	}
+	return tokens, nil
+}
+
+// RecordGoogleTokenRefreshFailure counts a failed refresh of a user's Google tokens and sets when to try again.
+func (r *sqlxUserRepository) RecordGoogleTokenRefreshFailure(ctx context.Context, userID string, retryAt time.Time) error {
+	query := `UPDATE users SET token_refresh_failures = token_refresh_failures + 1, token_refresh_retry_at = :1
+	          WHERE id = :2`
+
+	if _, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, retryAt, userID); err != nil {
+		return fmt.Errorf("failed to record google token refresh failure: %w", err)
+	}
+	return nil
+}
+
+// ClearGoogleTokens removes the stored Google tokens of a user.
+func (r *sqlxUserRepository) ClearGoogleTokens(ctx context.Context, userID string) error {
+	query := `UPDATE users SET encrypted_access_token = NULL, encrypted_refresh_token = NULL, token_expires_at = NULL,
+	            token_refresh_failures = 0, token_refresh_retry_at = NULL, updated_at = :1
+	          WHERE id = :2`
+
+	if _, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, time.Now(), userID); err != nil {
+		return fmt.Errorf("failed to clear google tokens: %w", err)
+	}
+	return nil
+}
+
+// DeleteUser soft-deletes a user. The unique email is replaced with a placeholder derived from
+// the user ID, so the same email can sign up again.
+func (r *sqlxUserRepository) DeleteUser(ctx context.Context, userID string, deletedAt time.Time) (bool, error) {
+	query := `UPDATE users SET
+	            google_id = NULL,
+	            email = :1,
+	            name = NULL,
+	            profile_picture_url = NULL,
+	            encrypted_access_token = NULL,
+	            encrypted_refresh_token = NULL,
+	            token_expires_at = NULL,
+	            updated_at = :2,
+	            deleted_at = :3
+	          WHERE id = :4 AND deleted_at IS NULL`
+
+	placeholder := "deleted:" + userID
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, placeholder, deletedAt, deletedAt, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete user: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
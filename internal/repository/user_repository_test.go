@@ -31,8 +31,7 @@ func TestToDomainUser(t *testing.T) {
 	now := time.Now().Truncate(time.Second)
 	modelUser := &models.User{
 		ID:                "user1",
-		GoogleID:          "google123",
-		Email:             "test@example.com",
+		Email:             sql.NullString{String: "test@example.com", Valid: true},
 		Name:              sql.NullString{String: "Test User", Valid: true},
 		ProfilePictureURL: sql.NullString{String: "http://example.com/pic.jpg", Valid: true},
 		CreatedAt:         now,
@@ -43,8 +42,7 @@ func TestToDomainUser(t *testing.T) {
 	domainUser := toDomainUser(modelUser)
 	assert.NotNil(t, domainUser)
 	assert.Equal(t, modelUser.ID, domainUser.ID)
-	assert.Equal(t, modelUser.GoogleID, domainUser.GoogleID)
-	assert.Equal(t, modelUser.Email, domainUser.Email)
+	assert.Equal(t, modelUser.Email.String, domainUser.Email)
 	assert.Equal(t, modelUser.Name.String, domainUser.Name)
 	assert.Equal(t, modelUser.ProfilePictureURL.String, domainUser.ProfilePictureURL)
 	assert.True(t, modelUser.CreatedAt.Equal(domainUser.CreatedAt))
@@ -75,7 +73,6 @@ func TestFromDomainUser(t *testing.T) {
 	now := time.Now().Truncate(time.Second)
 	domainUser := &domain.User{
 		ID:                "user1",
-		GoogleID:          "google123",
 		Email:             "test@example.com",
 		Name:              "Test User",
 		ProfilePictureURL: "http://example.com/pic.jpg",
@@ -87,8 +84,8 @@ func TestFromDomainUser(t *testing.T) {
 	modelUser := fromDomainUser(domainUser)
 	assert.NotNil(t, modelUser)
 	assert.Equal(t, domainUser.ID, modelUser.ID)
-	assert.Equal(t, domainUser.GoogleID, modelUser.GoogleID)
-	assert.Equal(t, domainUser.Email, modelUser.Email)
+	assert.Equal(t, domainUser.Email, modelUser.Email.String)
+	assert.True(t, modelUser.Email.Valid)
 	assert.Equal(t, domainUser.Name, modelUser.Name.String)
 	assert.True(t, modelUser.Name.Valid)
 	assert.Equal(t, domainUser.ProfilePictureURL, modelUser.ProfilePictureURL.String)
@@ -130,16 +127,15 @@ func TestSQLXUserRepository_GetUserByID_Success(t *testing.T) {
 	now := time.Now()
 	expectedModel := models.User{
 		ID:        userID,
-		GoogleID:  "google-id",
-		Email:     "test@example.com",
+		Email:     sql.NullString{String: "test@example.com", Valid: true},
 		Name:      sql.NullString{String: "Test User", Valid: true},
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
 	// For sqlx, column names in Rows must match struct fields or `db` tags.
-	rows := sqlmock.NewRows([]string{"id", "google_id", "email", "name", "profile_picture_url", "encrypted_access_token", "encrypted_refresh_token", "token_expires_at", "created_at", "updated_at", "deleted_at"}).
-		AddRow(expectedModel.ID, expectedModel.GoogleID, expectedModel.Email, expectedModel.Name, expectedModel.ProfilePictureURL, nil, nil, nil, expectedModel.CreatedAt, expectedModel.UpdatedAt, nil)
+	rows := sqlmock.NewRows([]string{"id", "email", "name", "profile_picture_url", "encrypted_access_token", "encrypted_refresh_token", "token_expires_at", "created_at", "updated_at", "deleted_at"}).
+		AddRow(expectedModel.ID, expectedModel.Email, expectedModel.Name, expectedModel.ProfilePictureURL, nil, nil, nil, expectedModel.CreatedAt, expectedModel.UpdatedAt, nil)
 
 	// The query in GetUserByID uses named arg :id. sqlx converts this to positional parameter.
 	// When using PrepareNamedContext, the actual SQL that gets prepared uses ? instead of :id
@@ -153,7 +149,7 @@ func TestSQLXUserRepository_GetUserByID_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, domainUser)
 	assert.Equal(t, expectedModel.ID, domainUser.ID)
-	assert.Equal(t, expectedModel.Email, domainUser.Email)
+	assert.Equal(t, expectedModel.Email.String, domainUser.Email)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -177,22 +173,45 @@ func TestSQLXUserRepository_GetUserByID_NotFound(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestSQLXUserRepository_GetUserByEmail(t *testing.T) {
+	db, mock := setupUserTestDB(t)
+	repo := NewSQLXUserRepository(db)
+	defer db.Close()
+	now := time.Now()
+	query := regexp.QuoteMeta(`FROM users WHERE email = :1 AND deleted_at IS NULL`)
+
+	rows := sqlmock.NewRows([]string{"ID", "EMAIL", "NAME", "PROFILE_PICTURE_URL", "ENCRYPTED_ACCESS_TOKEN", "ENCRYPTED_REFRESH_TOKEN", "TOKEN_EXPIRES_AT", "CREATED_AT", "UPDATED_AT", "DELETED_AT"}).
+		AddRow("user1", "user@example.com", "Test User", nil, nil, nil, nil, now, now, nil)
+	mock.ExpectQuery(query).WithArgs("user@example.com").WillReturnRows(rows)
+	user, err := repo.GetUserByEmail(context.Background(), "user@example.com")
+	assert.NoError(t, err)
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "user1", user.ID)
+		assert.Equal(t, "Test User", user.Name)
+	}
+
+	mock.ExpectQuery(query).WithArgs("missing@example.com").WillReturnError(sql.ErrNoRows)
+	user, err = repo.GetUserByEmail(context.Background(), "missing@example.com")
+	assert.NoError(t, err)
+	assert.Nil(t, user)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSQLXUserRepository_CreateUser_Success(t *testing.T) {
 	db, mock := setupUserTestDB(t)
 	repo := NewSQLXUserRepository(db)
 	defer db.Close()
 
 	domainUser := &domain.User{
-		ID:       "new-user-id", // Assuming ID is set before calling CreateUser for predictability
-		GoogleID: "new-google-id",
-		Email:    "new@example.com",
-		Name:     "New User",
+		ID:    "new-user-id", // Assuming ID is set before calling CreateUser for predictability
+		Email: "new@example.com",
+		Name:  "New User",
 		// CreatedAt and UpdatedAt will be set by the method if zero, or use provided.
 	}
 
 	// Query uses named exec. sqlx rebinds this.
 	// Regex matches the query structure.
-	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO users (id, google_id, email, name, profile_picture_url, created_at, updated_at, deleted_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)).
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO users (id, email, name, profile_picture_url, created_at, updated_at, deleted_at) VALUES (:1, :2, :3, :4, :5, :6, :7)`)).
 		WillReturnResult(sqlmock.NewResult(1, 1)) // Args are checked by sqlmock with NamedExec
 
 	err := repo.CreateUser(context.Background(), domainUser)
@@ -200,3 +219,113 @@ func TestSQLXUserRepository_CreateUser_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestSQLXUserRepository_UpdateGoogleTokens(t *testing.T) {
+	db, mock := setupUserTestDB(t)
+	repo := NewSQLXUserRepository(db)
+	defer db.Close()
+	expiresAt := time.Now().Add(time.Hour)
+	query := regexp.QuoteMeta(`encrypted_refresh_token = COALESCE(:2, encrypted_refresh_token)`)
+
+	mock.ExpectExec(query).
+		WithArgs("enc-access", "enc-refresh", expiresAt, sqlmock.AnyArg(), "user1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	err := repo.UpdateGoogleTokens(context.Background(), &domain.GoogleTokens{
+		UserID: "user1", EncryptedAccessToken: "enc-access", EncryptedRefreshToken: "enc-refresh", ExpiresAt: expiresAt,
+	})
+	assert.NoError(t, err)
+
+	// Without a refresh token, NULL keeps the stored one
+	mock.ExpectExec(query).
+		WithArgs("enc-access", nil, expiresAt, sqlmock.AnyArg(), "user1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	err = repo.UpdateGoogleTokens(context.Background(), &domain.GoogleTokens{UserID: "user1", EncryptedAccessToken: "enc-access", ExpiresAt: expiresAt})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLXUserRepository_GetGoogleTokens(t *testing.T) {
+	db, mock := setupUserTestDB(t)
+	repo := NewSQLXUserRepository(db)
+	defer db.Close()
+	now := time.Now()
+	query := regexp.QuoteMeta(`SELECT id, encrypted_access_token, encrypted_refresh_token, token_expires_at FROM users`)
+	columns := []string{"ID", "ENCRYPTED_ACCESS_TOKEN", "ENCRYPTED_REFRESH_TOKEN", "TOKEN_EXPIRES_AT"}
+
+	mock.ExpectQuery(query).WithArgs("user1").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("user1", "enc-access", "enc-refresh", now))
+	tokens, err := repo.GetGoogleTokens(context.Background(), "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.GoogleTokens{UserID: "user1", EncryptedAccessToken: "enc-access", EncryptedRefreshToken: "enc-refresh", ExpiresAt: now}, tokens)
+
+	mock.ExpectQuery(query).WithArgs("user2").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("user2", nil, nil, nil))
+	tokens, err = repo.GetGoogleTokens(context.Background(), "user2")
+	assert.NoError(t, err)
+	assert.Nil(t, tokens)
+
+	mock.ExpectQuery(query).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+	tokens, err = repo.GetGoogleTokens(context.Background(), "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, tokens)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLXUserRepository_ListExpiringGoogleTokens(t *testing.T) {
+	db, mock := setupUserTestDB(t)
+	repo := NewSQLXUserRepository(db)
+	defer db.Close()
+	before := time.Now().Add(10 * time.Minute)
+
+	rows := sqlmock.NewRows([]string{"ID", "ENCRYPTED_ACCESS_TOKEN", "ENCRYPTED_REFRESH_TOKEN", "TOKEN_EXPIRES_AT", "TOKEN_REFRESH_FAILURES"}).
+		AddRow("user1", "enc-access", "enc-refresh", before.Add(-time.Minute), 2)
+	mock.ExpectQuery(regexp.QuoteMeta(`encrypted_refresh_token IS NOT NULL AND token_expires_at < :1`)+`\s+`+
+		regexp.QuoteMeta(`AND (token_refresh_retry_at IS NULL OR token_refresh_retry_at <= :2)`)+`.*FETCH FIRST 20 ROWS ONLY`).
+		WithArgs(before, sqlmock.AnyArg()).
+		WillReturnRows(rows)
+
+	tokens, err := repo.ListExpiringGoogleTokens(context.Background(), before, 20)
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 1) {
+		assert.Equal(t, "user1", tokens[0].UserID)
+		assert.Equal(t, "enc-refresh", tokens[0].EncryptedRefreshToken)
+		assert.Equal(t, 2, tokens[0].RefreshFailures)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLXUserRepository_RecordGoogleTokenRefreshFailure(t *testing.T) {
+	db, mock := setupUserTestDB(t)
+	repo := NewSQLXUserRepository(db)
+	defer db.Close()
+	retryAt := time.Now().Add(5 * time.Minute)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET token_refresh_failures = token_refresh_failures + 1, token_refresh_retry_at = :1`)).
+		WithArgs(retryAt, "user1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.RecordGoogleTokenRefreshFailure(context.Background(), "user1", retryAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLXUserRepository_DeleteUser(t *testing.T) {
+	db, mock := setupUserTestDB(t)
+	repo := NewSQLXUserRepository(db)
+	defer db.Close()
+	now := time.Now()
+	query := regexp.QuoteMeta(`UPDATE users SET`) + `.*` + regexp.QuoteMeta(`WHERE id = :4 AND deleted_at IS NULL`)
+
+	mock.ExpectExec(query).
+		WithArgs("deleted:user1", now, now, "user1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	deleted, err := repo.DeleteUser(context.Background(), "user1", now)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	// Already deleted
+	mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+	deleted, err = repo.DeleteUser(context.Background(), "user1", now)
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
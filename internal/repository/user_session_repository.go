@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/repository/models"
+	"quiz-byte/internal/util"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const userSessionColumns = `id, user_id, current_jti, expires_at, last_used_at, revoked_at, revoked_reason, device, user_agent, ip_address, created_at, updated_at`
+
+// sqlxUserSessionRepository implements domain.UserSessionRepository using sqlx.
+type sqlxUserSessionRepository struct {
+	db DBTX
+}
+
+// NewSQLXUserSessionRepository creates a new instance of sqlxUserSessionRepository.
+func NewSQLXUserSessionRepository(db *sqlx.DB) domain.UserSessionRepository {
+	return &sqlxUserSessionRepository{db: db}
+}
+
+func toDomainUserSession(model *models.UserSession) *domain.UserSession {
+	var revokedAt *time.Time
+	if model.RevokedAt.Valid {
+		revokedAt = &model.RevokedAt.Time
+	}
+	return &domain.UserSession{
+		ID:            model.ID,
+		UserID:        model.UserID,
+		CurrentJTI:    model.CurrentJTI,
+		ExpiresAt:     model.ExpiresAt,
+		LastUsedAt:    model.LastUsedAt,
+		RevokedAt:     revokedAt,
+		RevokedReason: model.RevokedReason.String,
+		Device:        model.Device.String,
+		UserAgent:     model.UserAgent.String,
+		IPAddress:     model.IPAddress.String,
+		CreatedAt:     model.CreatedAt,
+	}
+}
+
+// CreateSession inserts a new session.
+func (r *sqlxUserSessionRepository) CreateSession(ctx context.Context, session *domain.UserSession) error {
+	now := time.Now()
+	createdAt := session.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+	lastUsedAt := session.LastUsedAt
+	if lastUsedAt.IsZero() {
+		lastUsedAt = createdAt
+	}
+
+	query := `INSERT INTO user_sessions (id, user_id, current_jti, expires_at, last_used_at, device, user_agent, ip_address, created_at, updated_at)
+	          VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10)`
+	_, err := GetExecutor(ctx, r.db).ExecContext(ctx, query,
+		session.ID,
+		session.UserID,
+		session.CurrentJTI,
+		session.ExpiresAt,
+		lastUsedAt,
+		util.StringToNullString(session.Device),
+		util.StringToNullString(session.UserAgent),
+		util.StringToNullString(session.IPAddress),
+		createdAt,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create user session: %w", err)
+	}
+	return nil
+}
+
+// GetSessionByID retrieves a session by its ID.
+func (r *sqlxUserSessionRepository) GetSessionByID(ctx context.Context, id string) (*domain.UserSession, error) {
+	var model models.UserSession
+	query := `SELECT ` + userSessionColumns + ` FROM user_sessions WHERE id = :1`
+
+	if err := GetExecutor(ctx, r.db).GetContext(ctx, &model, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Return nil, nil for not found
+		}
+		return nil, fmt.Errorf("failed to get user session by id: %w", err)
+	}
+	return toDomainUserSession(&model), nil
+}
+
+// ListActiveSessions retrieves the sessions of a user that can still be refreshed.
+func (r *sqlxUserSessionRepository) ListActiveSessions(ctx context.Context, userID string, now time.Time) ([]domain.UserSession, error) {
+	var modelSessions []models.UserSession
+	query := `SELECT ` + userSessionColumns + ` FROM user_sessions
+	          WHERE user_id = :1 AND revoked_at IS NULL AND expires_at > :2
+	          ORDER BY last_used_at DESC, id DESC`
+
+	if err := GetExecutor(ctx, r.db).SelectContext(ctx, &modelSessions, query, userID, now); err != nil {
+		return nil, fmt.Errorf("failed to list sessions of user %s: %w", userID, err)
+	}
+	sessions := make([]domain.UserSession, 0, len(modelSessions))
+	for i := range modelSessions {
+		sessions = append(sessions, *toDomainUserSession(&modelSessions[i]))
+	}
+	return sessions, nil
+}
+
+// RotateSession swaps the current jti of an active session. The jti check in the WHERE clause
+// lets only one of several concurrent refreshes with the same token succeed.
+func (r *sqlxUserSessionRepository) RotateSession(ctx context.Context, id string, currentJTI string, newJTI string, expiresAt time.Time, usedAt time.Time) (bool, error) {
+	query := `UPDATE user_sessions SET current_jti = :1, expires_at = :2, last_used_at = :3
+	          WHERE id = :4 AND current_jti = :5 AND revoked_at IS NULL`
+
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, newJTI, expiresAt, usedAt, id, currentJTI)
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate user session %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// RevokeSession marks an active session as revoked.
+func (r *sqlxUserSessionRepository) RevokeSession(ctx context.Context, id string, reason string, revokedAt time.Time) (bool, error) {
+	query := `UPDATE user_sessions SET revoked_at = :1, revoked_reason = :2 WHERE id = :3 AND revoked_at IS NULL`
+
+	result, err := GetExecutor(ctx, r.db).ExecContext(ctx, query, revokedAt, util.StringToNullString(reason), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke user session %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupUserSessionTestDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	if err != nil {
+		t.Fatalf("Failed to create sqlmock: %v", err)
+	}
+	return sqlx.NewDb(mockDB, "sqlmock"), mock
+}
+
+var userSessionTestColumns = []string{"ID", "USER_ID", "CURRENT_JTI", "EXPIRES_AT", "LAST_USED_AT", "REVOKED_AT", "REVOKED_REASON", "DEVICE", "USER_AGENT", "IP_ADDRESS", "CREATED_AT", "UPDATED_AT"}
+
+func TestUserSessionRepository_CreateSession(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXUserSessionRepository(db)
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_sessions`)).
+		WithArgs("session1", "user1", "jti1", expiresAt, sqlmock.AnyArg(), "Chrome on macOS", "Mozilla/5.0", "203.0.113.7", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.CreateSession(context.Background(), &domain.UserSession{
+		ID: "session1", UserID: "user1", CurrentJTI: "jti1", ExpiresAt: expiresAt,
+		Device: "Chrome on macOS", UserAgent: "Mozilla/5.0", IPAddress: "203.0.113.7",
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserSessionRepository_GetSessionByID(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXUserSessionRepository(db)
+	now := time.Now()
+	query := regexp.QuoteMeta(`FROM user_sessions WHERE id = :1`)
+
+	rows := sqlmock.NewRows(userSessionTestColumns).
+		AddRow("session1", "user1", "jti1", now, now, now, domain.SessionRevokedLogout, nil, nil, nil, now, now)
+	mock.ExpectQuery(query).WithArgs("session1").WillReturnRows(rows)
+
+	session, err := repo.GetSessionByID(context.Background(), "session1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, session) {
+		assert.Equal(t, "jti1", session.CurrentJTI)
+		assert.NotNil(t, session.RevokedAt)
+		assert.Equal(t, domain.SessionRevokedLogout, session.RevokedReason)
+	}
+
+	mock.ExpectQuery(query).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+	session, err = repo.GetSessionByID(context.Background(), "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, session)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserSessionRepository_ListActiveSessions(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXUserSessionRepository(db)
+	now := time.Now()
+
+	rows := sqlmock.NewRows(userSessionTestColumns).
+		AddRow("session2", "user1", "jti2", now.Add(time.Hour), now, nil, nil, "Firefox on Linux", "Mozilla/5.0 (X11; Linux x86_64) Firefox/126.0", "198.51.100.1", now, now).
+		AddRow("session1", "user1", "jti1", now.Add(time.Hour), now.Add(-time.Hour), nil, nil, nil, nil, nil, now, now)
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE user_id = :1 AND revoked_at IS NULL AND expires_at > :2`)).
+		WithArgs("user1", now).
+		WillReturnRows(rows)
+
+	sessions, err := repo.ListActiveSessions(context.Background(), "user1", now)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 2) {
+		assert.Equal(t, "session2", sessions[0].ID)
+		assert.Equal(t, "Firefox on Linux", sessions[0].Device)
+		assert.Equal(t, "198.51.100.1", sessions[0].IPAddress)
+		assert.Nil(t, sessions[0].RevokedAt)
+		assert.Empty(t, sessions[1].UserAgent)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserSessionRepository_RotateSession(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXUserSessionRepository(db)
+	now := time.Now()
+	query := regexp.QuoteMeta(`UPDATE user_sessions SET current_jti = :1`)
+
+	mock.ExpectExec(query).
+		WithArgs("jti2", now.Add(time.Hour), now, "session1", "jti1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	rotated, err := repo.RotateSession(context.Background(), "session1", "jti1", "jti2", now.Add(time.Hour), now)
+	assert.NoError(t, err)
+	assert.True(t, rotated)
+
+	// Already rotated, or revoked
+	mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+	rotated, err = repo.RotateSession(context.Background(), "session1", "jti1", "jti3", now.Add(time.Hour), now)
+	assert.NoError(t, err)
+	assert.False(t, rotated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserSessionRepository_RevokeSession(t *testing.T) {
+	db, mock := setupUserSessionTestDB(t)
+	defer db.Close()
+	repo := NewSQLXUserSessionRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE user_sessions SET revoked_at = :1, revoked_reason = :2 WHERE id = :3 AND revoked_at IS NULL`)).
+		WithArgs(now, domain.SessionRevokedTokenReuse, "session1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	revoked, err := repo.RevokeSession(context.Background(), "session1", domain.SessionRevokedTokenReuse, now)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,10 +21,30 @@ import (
 // ErrAnonymousResultNotFound is returned when a cached result is not found.
 var ErrAnonymousResultNotFound = errors.New("anonymous result not found in cache")
 
+// AnonymousResult is a graded answer of an anonymous user, kept until it is claimed into an account or expires.
+type AnonymousResult struct {
+	QuizID     string                   `json:"quiz_id"`
+	UserAnswer string                   `json:"user_answer"`
+	Result     *dto.CheckAnswerResponse `json:"result"`
+	AnsweredAt time.Time                `json:"answered_at"`
+}
+
 // AnonymousResultCacheService defines the interface for caching anonymous user quiz results.
+// Results are stored under a result token handed to the anonymous user (see NewResultToken).
 type AnonymousResultCacheService interface {
-	Put(ctx context.Context, requestID string, result *dto.CheckAnswerResponse) error
-	Get(ctx context.Context, requestID string) (*dto.CheckAnswerResponse, error)
+	Put(ctx context.Context, token string, entry *AnonymousResult) error
+	Get(ctx context.Context, token string) (*AnonymousResult, error)
+	// Claim returns the result and removes it in one step, so each token can be claimed only once.
+	Claim(ctx context.Context, token string) (*AnonymousResult, error)
+}
+
+// NewResultToken generates an unguessable token for an anonymous result.
+func NewResultToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", domain.NewInternalError("failed to generate result token", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
 // anonymousResultCacheServiceImpl implements AnonymousResultCacheService using a generic cache.
@@ -51,15 +73,15 @@ func (s *anonymousResultCacheServiceImpl) generateKey(requestID string) string {
 }
 
 // Put stores the quiz result for an anonymous user in the cache.
-func (s *anonymousResultCacheServiceImpl) Put(ctx context.Context, requestID string, result *dto.CheckAnswerResponse) error {
-	if result == nil {
+func (s *anonymousResultCacheServiceImpl) Put(ctx context.Context, token string, entry *AnonymousResult) error {
+	if entry == nil || entry.Result == nil {
 		return domain.NewInvalidInputError("cannot cache nil result")
 	}
 
-	key := s.generateKey(requestID)
-	dataBytes, err := json.Marshal(result)
+	key := s.generateKey(token)
+	dataBytes, err := json.Marshal(entry)
 	if err != nil {
-		logger.Get().Error("Failed to marshal anonymous result for caching", zap.Error(err), zap.String("requestID", requestID))
+		logger.Get().Error("Failed to marshal anonymous result for caching", zap.Error(err), zap.String("quizID", entry.QuizID))
 		return domain.NewInternalError("failed to marshal result for caching", err)
 	}
 
@@ -75,10 +97,21 @@ func (s *anonymousResultCacheServiceImpl) Put(ctx context.Context, requestID str
 }
 
 // Get retrieves the quiz result for an anonymous user from the cache.
-func (s *anonymousResultCacheServiceImpl) Get(ctx context.Context, requestID string) (*dto.CheckAnswerResponse, error) {
-	key := s.generateKey(requestID)
+func (s *anonymousResultCacheServiceImpl) Get(ctx context.Context, token string) (*AnonymousResult, error) {
+	key := s.generateKey(token)
 	// domain.Cache interface returns string
 	dataString, err := s.cache.Get(ctx, key)
+	return s.decode(key, dataString, err)
+}
+
+// Claim retrieves and removes the quiz result for an anonymous user.
+func (s *anonymousResultCacheServiceImpl) Claim(ctx context.Context, token string) (*AnonymousResult, error) {
+	key := s.generateKey(token)
+	dataString, err := s.cache.GetDel(ctx, key)
+	return s.decode(key, dataString, err)
+}
+
+func (s *anonymousResultCacheServiceImpl) decode(key string, dataString string, err error) (*AnonymousResult, error) {
 	if err != nil {
 		if errors.Is(err, domain.ErrCacheMiss) {
 			logger.Get().Debug("Anonymous result cache miss", zap.String("key", key))
@@ -94,7 +127,7 @@ func (s *anonymousResultCacheServiceImpl) Get(ctx context.Context, requestID str
 		return nil, ErrAnonymousResultNotFound
 	}
 
-	var result dto.CheckAnswerResponse
+	var result AnonymousResult
 	// Unmarshal from []byte(dataString)
 	if err := json.Unmarshal([]byte(dataString), &result); err != nil {
 		logger.Get().Error("Failed to unmarshal anonymous result from cache", zap.Error(err), zap.String("key", key))
@@ -108,12 +141,17 @@ func (s *anonymousResultCacheServiceImpl) Get(ctx context.Context, requestID str
 // noopAnonymousResultCacheService is a no-op implementation for when caching is disabled or fails to initialize.
 type noopAnonymousResultCacheService struct{}
 
-func (s *noopAnonymousResultCacheService) Put(ctx context.Context, requestID string, result *dto.CheckAnswerResponse) error {
-	logger.Get().Debug("No-op AnonymousResultCacheService: Put called", zap.String("requestID", requestID))
+func (s *noopAnonymousResultCacheService) Put(ctx context.Context, token string, entry *AnonymousResult) error {
+	logger.Get().Debug("No-op AnonymousResultCacheService: Put called")
 	return nil
 }
 
-func (s *noopAnonymousResultCacheService) Get(ctx context.Context, requestID string) (*dto.CheckAnswerResponse, error) {
-	logger.Get().Debug("No-op AnonymousResultCacheService: Get called", zap.String("requestID", requestID))
+func (s *noopAnonymousResultCacheService) Get(ctx context.Context, token string) (*AnonymousResult, error) {
+	logger.Get().Debug("No-op AnonymousResultCacheService: Get called")
+	return nil, ErrAnonymousResultNotFound
+}
+
+func (s *noopAnonymousResultCacheService) Claim(ctx context.Context, token string) (*AnonymousResult, error) {
+	logger.Get().Debug("No-op AnonymousResultCacheService: Claim called")
 	return nil, ErrAnonymousResultNotFound
 }
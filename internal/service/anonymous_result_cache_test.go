@@ -18,6 +18,7 @@ type ManualMockCache struct {
 	GetFunc    func(ctx context.Context, key string) (string, error)                        // Changed []byte to string
 	SetFunc    func(ctx context.Context, key string, value string, ttl time.Duration) error // Changed value interface{} to string
 	DeleteFunc func(ctx context.Context, key string) error
+	GetDelFunc func(ctx context.Context, key string) (string, error)
 	// Add other methods if AnonymousResultCacheService uses them
 	HGetFunc    func(ctx context.Context, key, field string) (string, error)
 	HSetFunc    func(ctx context.Context, key string, field string, value string) error
@@ -47,6 +48,13 @@ func (m *ManualMockCache) Delete(ctx context.Context, key string) error {
 	return errors.New("DeleteFunc not set")
 }
 
+func (m *ManualMockCache) GetDel(ctx context.Context, key string) (string, error) {
+	if m.GetDelFunc != nil {
+		return m.GetDelFunc(ctx, key)
+	}
+	return "", errors.New("GetDelFunc not set")
+}
+
 func (m *ManualMockCache) GetKeysByPrefix(ctx context.Context, prefix string) ([]string, error) {
 	panic("not implemented in mock")
 }
@@ -91,7 +99,12 @@ func TestAnonymousResultCacheServiceImpl_Put(t *testing.T) {
 	ctx := context.Background()
 
 	requestID := "req123"
-	result := &dto.CheckAnswerResponse{Score: 0.8, Explanation: "Good job!"}
+	result := &AnonymousResult{
+		QuizID:     "quiz-1",
+		UserAnswer: "An answer",
+		Result:     &dto.CheckAnswerResponse{Score: 0.8, Explanation: "Good job!"},
+		AnsweredAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
 
 	expectedKey := "quizbyte:anonymous:result:" + requestID
 	expectedJSONData, _ := json.Marshal(result)    // This is []byte
@@ -116,7 +129,12 @@ func TestAnonymousResultCacheServiceImpl_Get(t *testing.T) {
 	ctx := context.Background()
 
 	requestID := "req123"
-	expectedResult := &dto.CheckAnswerResponse{Score: 0.9, Explanation: "Excellent!"}
+	expectedResult := &AnonymousResult{
+		QuizID:     "quiz-1",
+		UserAnswer: "An answer",
+		Result:     &dto.CheckAnswerResponse{Score: 0.9, Explanation: "Excellent!"},
+		AnsweredAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
 	expectedKey := "quizbyte:anonymous:result:" + requestID
 
 	t.Run("Cache Hit", func(t *testing.T) {
@@ -196,7 +214,7 @@ func TestNewAnonymousResultCacheService_NilCache(t *testing.T) {
 	ctx := context.Background()
 
 	requestID := "reqNoCache"
-	resultToPut := &dto.CheckAnswerResponse{Score: 0.7, Explanation: "Testing no-op"}
+	resultToPut := &AnonymousResult{QuizID: "quiz-1", Result: &dto.CheckAnswerResponse{Score: 0.7, Explanation: "Testing no-op"}}
 
 	// Test Put on no-op service
 	errPut := cacheService.Put(ctx, requestID, resultToPut)
@@ -206,4 +224,42 @@ func TestNewAnonymousResultCacheService_NilCache(t *testing.T) {
 	retrievedResult, errGet := cacheService.Get(ctx, requestID)
 	assert.Nil(t, retrievedResult, "Get on no-op service should return nil result")
 	assert.Equal(t, ErrAnonymousResultNotFound, errGet, "Get on no-op service should return ErrAnonymousResultNotFound")
+
+	claimedResult, errClaim := cacheService.Claim(ctx, requestID)
+	assert.Nil(t, claimedResult, "Claim on no-op service should return nil result")
+	assert.Equal(t, ErrAnonymousResultNotFound, errClaim, "Claim on no-op service should return ErrAnonymousResultNotFound")
+}
+
+func TestAnonymousResultCacheServiceImpl_Claim(t *testing.T) {
+	cache := newMapCache()
+	cacheService := NewAnonymousResultCacheService(cache, 5*time.Minute, &MockTransactionManager{})
+	ctx := context.Background()
+
+	token, err := NewResultToken()
+	assert.NoError(t, err)
+	entry := &AnonymousResult{
+		QuizID:     "quiz-1",
+		UserAnswer: "An answer",
+		Result:     &dto.CheckAnswerResponse{Score: 0.9, Explanation: "Excellent!"},
+		AnsweredAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	assert.NoError(t, cacheService.Put(ctx, token, entry))
+
+	claimed, err := cacheService.Claim(ctx, token)
+	assert.NoError(t, err)
+	assert.Equal(t, entry, claimed)
+
+	// A token can only be claimed once
+	claimed, err = cacheService.Claim(ctx, token)
+	assert.Nil(t, claimed)
+	assert.Equal(t, ErrAnonymousResultNotFound, err)
+}
+
+func TestNewResultToken(t *testing.T) {
+	first, err := NewResultToken()
+	assert.NoError(t, err)
+	second, err := NewResultToken()
+	assert.NoError(t, err)
+	assert.Len(t, first, 32)
+	assert.NotEqual(t, first, second)
 }
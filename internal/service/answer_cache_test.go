@@ -60,6 +60,11 @@ func (m *MockAnswerCacheDomainCache) Delete(ctx context.Context, key string) err
 	return args.Error(0)
 }
 
+func (m *MockAnswerCacheDomainCache) GetDel(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockAnswerCacheDomainCache) Ping(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"unicode"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/logger"
+	"quiz-byte/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// preScore is the deterministic assessment of an answer, computed before the LLM is called.
+type preScore struct {
+	blank           bool     // Nothing but whitespace and punctuation
+	exactMatch      bool     // Same text as a model answer, ignoring case and punctuation
+	keywordMatches  []string // Quiz keywords found in the answer
+	keywordCoverage float64  // Share of quiz keywords found; only meaningful when hasKeywords
+	hasKeywords     bool
+	similarity      float64 // Best cosine similarity to a model answer; only meaningful when hasSimilarity
+	hasSimilarity   bool
+}
+
+// answerPreScorer scores answers locally from keyword coverage and embedding similarity.
+type answerPreScorer struct {
+	embeddingService domain.EmbeddingService
+	settings         config.PreScoreConfig
+}
+
+func newAnswerPreScorer(embeddingService domain.EmbeddingService, settings config.PreScoreConfig) *answerPreScorer {
+	return &answerPreScorer{embeddingService: embeddingService, settings: settings}
+}
+
+// score assesses userAnswer. userEmbedding may be nil, in which case similarity is skipped.
+func (p *answerPreScorer) score(ctx context.Context, quiz *domain.Quiz, userAnswer string, userEmbedding []float32) preScore {
+	normalizedAnswer := normalizeAnswerText(userAnswer)
+	ps := preScore{blank: normalizedAnswer == ""}
+
+	for _, modelAnswer := range quiz.ModelAnswers {
+		if normalizedAnswer != "" && normalizedAnswer == normalizeAnswerText(modelAnswer) {
+			ps.exactMatch = true
+		}
+	}
+
+	lowerAnswer := strings.ToLower(userAnswer)
+	keywordCount := 0
+	for _, kw := range quiz.Keywords {
+		if kw = strings.TrimSpace(kw); kw == "" {
+			continue
+		}
+		keywordCount++
+		if strings.Contains(lowerAnswer, strings.ToLower(kw)) {
+			ps.keywordMatches = append(ps.keywordMatches, kw)
+		}
+	}
+	if keywordCount > 0 {
+		ps.hasKeywords = true
+		ps.keywordCoverage = float64(len(ps.keywordMatches)) / float64(keywordCount)
+	}
+
+	if p.embeddingService == nil || len(userEmbedding) == 0 {
+		return ps
+	}
+	for _, modelAnswer := range quiz.ModelAnswers {
+		// Model answer embeddings are cached by the embedding service, so this is cheap after the first check.
+		modelEmbedding, err := p.embeddingService.Generate(ctx, modelAnswer)
+		if err != nil {
+			logger.Get().Warn("Pre-score: failed to embed model answer", zap.String("quiz_id", quiz.ID), zap.Error(err))
+			continue
+		}
+		similarity, err := util.CosineSimilarity(userEmbedding, modelEmbedding)
+		if err != nil {
+			logger.Get().Warn("Pre-score: cannot compare embeddings", zap.String("quiz_id", quiz.ID), zap.Error(err))
+			continue
+		}
+		if !ps.hasSimilarity || similarity > ps.similarity {
+			ps.similarity = similarity
+			ps.hasSimilarity = true
+		}
+	}
+	return ps
+}
+
+// decide returns a final answer for obvious cases so the LLM can be skipped:
+// blank or off-topic answers score 0 and (near) copies of a model answer score 1.
+func (p *answerPreScorer) decide(ps preScore, userAnswer string, rubric *domain.QuizEvaluation) (*domain.Answer, bool) {
+	switch {
+	case ps.blank:
+		return p.answer(ps, userAnswer, 0, "The answer is empty.", rubric), true
+	case ps.exactMatch || (ps.hasSimilarity && ps.similarity >= p.settings.ExactMatchSimilarity):
+		return p.answer(ps, userAnswer, 1, "The answer matches a model answer.", rubric), true
+	case ps.hasSimilarity && ps.similarity < p.settings.OffTopicSimilarity && len(ps.keywordMatches) == 0:
+		return p.answer(ps, userAnswer, 0, "The answer does not address the question.", rubric), true
+	default:
+		return nil, false
+	}
+}
+
+func (p *answerPreScorer) answer(ps preScore, userAnswer string, score float64, explanation string, rubric *domain.QuizEvaluation) *domain.Answer {
+	answer := &domain.Answer{
+		UserAnswer:     userAnswer,
+		Score:          score,
+		Explanation:    explanation,
+		KeywordMatches: ps.keywordMatches,
+		Completeness:   score,
+		Relevance:      score,
+		Accuracy:       score,
+		ScoredBy:       domain.ScoredByPreScore,
+	}
+	if items := rubric.RubricItems(); len(items) > 0 {
+		if score == 1 {
+			answer.RubricSatisfied = items
+		} else {
+			answer.RubricMissed = items
+		}
+	}
+	return answer
+}
+
+// fallback estimates a score when the LLM is unavailable. It returns false when there is
+// nothing to base an estimate on (no keywords and no embeddings).
+func (p *answerPreScorer) fallback(ps preScore, userAnswer string) (*domain.Answer, bool) {
+	var score float64
+	switch {
+	case ps.hasKeywords && ps.hasSimilarity:
+		score = 0.5*ps.keywordCoverage + 0.5*clamp01(ps.similarity)
+	case ps.hasKeywords:
+		score = ps.keywordCoverage
+	case ps.hasSimilarity:
+		score = clamp01(ps.similarity)
+	default:
+		return nil, false
+	}
+	score = math.Round(score*100) / 100
+
+	return &domain.Answer{
+		UserAnswer:     userAnswer,
+		Score:          score,
+		Explanation:    "The answer could not be graded in detail right now; this score is an estimate based on keywords and similarity to the model answers.",
+		KeywordMatches: ps.keywordMatches,
+		Completeness:   math.Round(ps.keywordCoverage*100) / 100,
+		Relevance:      score,
+		Accuracy:       score,
+		Confidence:     domain.ConfidenceLow,
+		ScoredBy:       domain.ScoredByFallback,
+	}, true
+}
+
+// normalizeAnswerText lowercases text and collapses everything but letters and digits into single spaces.
+func normalizeAnswerText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCheckAnswer_PreScore(t *testing.T) {
+	modelAnswer := "A goroutine is a lightweight thread managed by the Go runtime."
+	quiz := &domain.Quiz{ID: "quiz-ps", Question: "What is a goroutine?", ModelAnswers: []string{modelAnswer}, Keywords: []string{"goroutine", "runtime"}, Status: domain.QuizStatusPublished}
+	rubric := &domain.QuizEvaluation{QuizID: quiz.ID, RequiredTopics: []string{"scheduling"}}
+	budgets := config.CheckAnswerConfig{PreScore: config.PreScoreConfig{
+		Enabled:              true,
+		OffTopicSimilarity:   0.3,
+		ExactMatchSimilarity: 0.97,
+		FallbackOnLLMError:   true,
+	}}
+
+	newService := func(userAnswer string, userEmbedding []float32, evaluator *MockAnswerEvaluator) QuizService {
+		repo := new(MockQuizRepository)
+		repo.On("GetQuizByID", mock.Anything, quiz.ID).Return(quiz, nil)
+		repo.On("GetQuizEvaluation", mock.Anything, quiz.ID).Return(rubric, nil)
+		embeddings := new(MockEmbeddingService)
+		embeddings.On("Generate", mock.Anything, modelAnswer).Return([]float32{1, 0}, nil)
+		embeddings.On("Generate", mock.Anything, userAnswer).Return(userEmbedding, nil)
+		return NewQuizService(repo, evaluator, new(MockCache), embeddings, nil, &MockTransactionManager{}, time.Hour, time.Hour, budgets)
+	}
+
+	t.Run("Exact model answer scores 1 without LLM", func(t *testing.T) {
+		evaluator := new(MockAnswerEvaluator)
+		userAnswer := "a goroutine is a lightweight thread, managed by the Go runtime"
+		svc := newService(userAnswer, []float32{0.9, 0.1}, evaluator)
+
+		resp, err := svc.CheckAnswer(context.Background(), &dto.CheckAnswerRequest{QuizID: quiz.ID, UserAnswer: userAnswer})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1.0, resp.Score)
+		assert.Equal(t, domain.ScoredByPreScore, resp.ScoredBy)
+		assert.Equal(t, []string{"scheduling"}, resp.RubricSatisfied)
+		assert.Equal(t, 0, resp.JudgeCount)
+		evaluator.AssertNotCalled(t, "EvaluateAnswer")
+	})
+
+	t.Run("Near-identical embedding scores 1 without LLM", func(t *testing.T) {
+		evaluator := new(MockAnswerEvaluator)
+		userAnswer := "Goroutines are light threads the runtime schedules."
+		svc := newService(userAnswer, []float32{1, 0.01}, evaluator)
+
+		resp, err := svc.CheckAnswer(context.Background(), &dto.CheckAnswerRequest{QuizID: quiz.ID, UserAnswer: userAnswer})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1.0, resp.Score)
+		evaluator.AssertNotCalled(t, "EvaluateAnswer")
+	})
+
+	t.Run("Off-topic answer scores 0 without LLM", func(t *testing.T) {
+		evaluator := new(MockAnswerEvaluator)
+		userAnswer := "I like pizza."
+		svc := newService(userAnswer, []float32{0, 1}, evaluator)
+
+		resp, err := svc.CheckAnswer(context.Background(), &dto.CheckAnswerRequest{QuizID: quiz.ID, UserAnswer: userAnswer})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, resp.Score)
+		assert.Equal(t, domain.ScoredByPreScore, resp.ScoredBy)
+		assert.Equal(t, []string{"scheduling"}, resp.RubricMissed)
+		evaluator.AssertNotCalled(t, "EvaluateAnswer")
+	})
+
+	t.Run("Low similarity with a keyword still goes to the LLM", func(t *testing.T) {
+		evaluator := new(MockAnswerEvaluator)
+		userAnswer := "A goroutine is like a coroutine."
+		evaluator.On("EvaluateAnswer", mock.Anything, mock.Anything).Return(&domain.Answer{Score: 0.4}, nil).Once()
+		svc := newService(userAnswer, []float32{0, 1}, evaluator)
+
+		resp, err := svc.CheckAnswer(context.Background(), &dto.CheckAnswerRequest{QuizID: quiz.ID, UserAnswer: userAnswer})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0.4, resp.Score)
+		assert.Equal(t, domain.ScoredByLLM, resp.ScoredBy)
+		assert.Equal(t, 1, resp.JudgeCount)
+		evaluator.AssertExpectations(t)
+	})
+
+	t.Run("LLM failure returns fallback estimate", func(t *testing.T) {
+		evaluator := new(MockAnswerEvaluator)
+		userAnswer := "A goroutine is a thread."
+		evaluator.On("EvaluateAnswer", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused")).Once()
+		svc := newService(userAnswer, []float32{1, 1}, evaluator) // similarity ~0.71
+
+		resp, err := svc.CheckAnswer(context.Background(), &dto.CheckAnswerRequest{QuizID: quiz.ID, UserAnswer: userAnswer})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ScoredByFallback, resp.ScoredBy)
+		assert.Equal(t, domain.ConfidenceLow, resp.Confidence)
+		assert.InDelta(t, 0.5*0.5+0.5*0.71, resp.Score, 0.01)
+		assert.Equal(t, []string{"goroutine"}, resp.KeywordMatches)
+	})
+
+	t.Run("LLM failure without fallback is LLM_SERVICE_ERROR", func(t *testing.T) {
+		evaluator := new(MockAnswerEvaluator)
+		evaluator.On("EvaluateAnswer", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused")).Once()
+		repo := new(MockQuizRepository)
+		repo.On("GetQuizByID", mock.Anything, quiz.ID).Return(quiz, nil)
+		repo.On("GetQuizEvaluation", mock.Anything, quiz.ID).Return(nil, nil)
+		noFallback := budgets
+		noFallback.PreScore.FallbackOnLLMError = false
+		svc := NewQuizService(repo, evaluator, new(MockCache), nil, nil, &MockTransactionManager{}, time.Hour, time.Hour, noFallback)
+
+		_, err := svc.CheckAnswer(context.Background(), &dto.CheckAnswerRequest{QuizID: quiz.ID, UserAnswer: "A goroutine is a thread."})
+
+		assert.ErrorIs(t, err, domain.ErrLLMServiceError)
+	})
+}
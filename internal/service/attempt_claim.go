@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// MaxClaimTokens is the maximum number of result tokens accepted in one claim request.
+const MaxClaimTokens = 50
+
+// AttemptClaimService moves results graded before login into a user's attempt history.
+type AttemptClaimService interface {
+	// ClaimAttempts records the results behind tokens as attempts of userID. Each token can be
+	// claimed once; the outcome of every token is reported individually.
+	ClaimAttempts(ctx context.Context, userID string, tokens []string) (*dto.ClaimAttemptsResponse, error)
+}
+
+type attemptClaimService struct {
+	anonymousResults AnonymousResultCacheService
+	attempts         AttemptRecorder
+}
+
+// NewAttemptClaimService creates a new AttemptClaimService.
+func NewAttemptClaimService(anonymousResults AnonymousResultCacheService, attempts AttemptRecorder) AttemptClaimService {
+	return &attemptClaimService{
+		anonymousResults: anonymousResults,
+		attempts:         attempts,
+	}
+}
+
+// ClaimAttempts implements AttemptClaimService.
+func (s *attemptClaimService) ClaimAttempts(ctx context.Context, userID string, tokens []string) (*dto.ClaimAttemptsResponse, error) {
+	if len(tokens) == 0 {
+		return nil, domain.NewValidationError("At least one result token is required")
+	}
+	if len(tokens) > MaxClaimTokens {
+		return nil, domain.NewValidationError(fmt.Sprintf("At most %d result tokens can be claimed at once", MaxClaimTokens))
+	}
+
+	response := &dto.ClaimAttemptsResponse{Results: make([]dto.ClaimedAttemptItem, 0, len(tokens))}
+	seen := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		if token == "" || seen[token] {
+			continue
+		}
+		seen[token] = true
+
+		item := s.claim(ctx, userID, token)
+		if item.Status == dto.ClaimStatusClaimed {
+			response.Claimed++
+		}
+		response.Results = append(response.Results, item)
+	}
+	return response, nil
+}
+
+func (s *attemptClaimService) claim(ctx context.Context, userID string, token string) dto.ClaimedAttemptItem {
+	item := dto.ClaimedAttemptItem{ResultToken: token}
+
+	entry, err := s.anonymousResults.Claim(ctx, token)
+	if err != nil {
+		if errors.Is(err, ErrAnonymousResultNotFound) {
+			item.Status = dto.ClaimStatusNotFound
+		} else {
+			logger.Get().Error("Failed to claim anonymous result", zap.String("userID", userID), zap.Error(err))
+			item.Status = dto.ClaimStatusFailed
+		}
+		return item
+	}
+	item.QuizID = entry.QuizID
+
+	answer := AttemptAnswer(entry.Result)
+	if !entry.AnsweredAt.IsZero() {
+		answer.AnsweredAt = entry.AnsweredAt
+	}
+	// Keyed by token so a token restored after a partial failure cannot add a second attempt.
+	if err := s.attempts.RecordAttempt(ctx, "claim:"+token, userID, entry.QuizID, entry.UserAnswer, answer); err != nil {
+		logger.Get().Error("Failed to record claimed attempt",
+			zap.String("userID", userID),
+			zap.String("quizID", entry.QuizID),
+			zap.Error(err))
+		// Put the result back so the claim can be retried.
+		if errPut := s.anonymousResults.Put(ctx, token, entry); errPut != nil {
+			logger.Get().Error("Failed to restore anonymous result after failed claim", zap.String("quizID", entry.QuizID), zap.Error(errPut))
+		}
+		item.Status = dto.ClaimStatusFailed
+		return item
+	}
+
+	item.Status = dto.ClaimStatusClaimed
+	return item
+}
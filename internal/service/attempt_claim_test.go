@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordAttemptStub is an AttemptRecorder that calls fn.
+type recordAttemptStub struct {
+	fn func(ctx context.Context, idempotencyKey, userID, quizID, userAnswer string, answer *domain.Answer) error
+}
+
+func (s *recordAttemptStub) RecordAttempt(ctx context.Context, idempotencyKey, userID, quizID, userAnswer string, answer *domain.Answer) error {
+	return s.fn(ctx, idempotencyKey, userID, quizID, userAnswer, answer)
+}
+
+func TestAttemptClaimService_ClaimAttempts(t *testing.T) {
+	ctx := context.Background()
+	answeredAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	entry := &AnonymousResult{
+		QuizID:     "quiz-1",
+		UserAnswer: "An answer",
+		Result:     &dto.CheckAnswerResponse{Score: 0.8, Explanation: "Good", Accuracy: 0.7},
+		AnsweredAt: answeredAt,
+	}
+
+	t.Run("Claims Each Token Once", func(t *testing.T) {
+		anonymousResults := NewAnonymousResultCacheService(newMapCache(), time.Hour, nil)
+		require.NoError(t, anonymousResults.Put(ctx, "token-1", entry))
+
+		var recorded []*domain.Answer
+		attempts := &recordAttemptStub{fn: func(_ context.Context, idempotencyKey, userID, quizID, userAnswer string, answer *domain.Answer) error {
+			assert.Equal(t, "claim:token-1", idempotencyKey)
+			assert.Equal(t, "user-1", userID)
+			assert.Equal(t, "quiz-1", quizID)
+			assert.Equal(t, "An answer", userAnswer)
+			recorded = append(recorded, answer)
+			return nil
+		}}
+		svc := NewAttemptClaimService(anonymousResults, attempts)
+
+		resp, err := svc.ClaimAttempts(ctx, "user-1", []string{"token-1", "token-1", "unknown"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.Claimed)
+		assert.Equal(t, []dto.ClaimedAttemptItem{
+			{ResultToken: "token-1", Status: dto.ClaimStatusClaimed, QuizID: "quiz-1"},
+			{ResultToken: "unknown", Status: dto.ClaimStatusNotFound},
+		}, resp.Results)
+		require.Len(t, recorded, 1)
+		assert.Equal(t, 0.8, recorded[0].Score)
+		assert.Equal(t, 0.7, recorded[0].Accuracy)
+		assert.Equal(t, answeredAt, recorded[0].AnsweredAt)
+
+		resp, err = svc.ClaimAttempts(ctx, "user-1", []string{"token-1"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, resp.Claimed)
+		assert.Equal(t, dto.ClaimStatusNotFound, resp.Results[0].Status)
+		assert.Len(t, recorded, 1)
+	})
+
+	t.Run("Failed Recording Keeps The Token Claimable", func(t *testing.T) {
+		anonymousResults := NewAnonymousResultCacheService(newMapCache(), time.Hour, nil)
+		require.NoError(t, anonymousResults.Put(ctx, "token-1", entry))
+
+		attempts := &recordAttemptStub{fn: func(context.Context, string, string, string, string, *domain.Answer) error {
+			return errors.New("database is down")
+		}}
+		svc := NewAttemptClaimService(anonymousResults, attempts)
+
+		resp, err := svc.ClaimAttempts(ctx, "user-1", []string{"token-1"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, resp.Claimed)
+		assert.Equal(t, dto.ClaimStatusFailed, resp.Results[0].Status)
+
+		restored, err := anonymousResults.Get(ctx, "token-1")
+		require.NoError(t, err)
+		assert.Equal(t, entry, restored)
+	})
+
+	t.Run("Rejects Missing Or Too Many Tokens", func(t *testing.T) {
+		svc := NewAttemptClaimService(NewAnonymousResultCacheService(newMapCache(), time.Hour, nil), &recordAttemptStub{})
+
+		_, err := svc.ClaimAttempts(ctx, "user-1", nil)
+		assert.ErrorIs(t, err, domain.ErrValidation)
+
+		tooMany := strings.Split(strings.Repeat("t,", MaxClaimTokens), ",") // MaxClaimTokens+1 entries
+		_, err = svc.ClaimAttempts(ctx, "user-1", tooMany)
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+}
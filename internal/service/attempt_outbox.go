@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/logger"
+	"quiz-byte/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// AttemptRecorder records quiz attempts of signed-in users.
+type AttemptRecorder interface {
+	// RecordAttempt records the attempt of userID. A call repeating the idempotencyKey of an earlier
+	// one is ignored, so callers can retry safely; with an empty key every call records a new attempt.
+	RecordAttempt(ctx context.Context, idempotencyKey string, userID string, quizID string, userAnswer string, evalResult *domain.Answer) error
+}
+
+// AttemptAnswer converts a graded response into the answer an AttemptRecorder stores.
+func AttemptAnswer(result *dto.CheckAnswerResponse) *domain.Answer {
+	return &domain.Answer{
+		Score:          result.Score,
+		Explanation:    result.Explanation,
+		KeywordMatches: result.KeywordMatches,
+		Completeness:   result.Completeness,
+		Relevance:      result.Relevance,
+		Accuracy:       result.Accuracy,
+		QuizRevision:   result.QuizRevision,
+		AnsweredAt:     time.Now(),
+	}
+}
+
+// AttemptOutboxService records attempts through the attempt outbox. RecordAttempt only stores the
+// attempt in the outbox; a background dispatcher writes it to the attempt history and retries
+// failures with exponential backoff.
+type AttemptOutboxService interface {
+	AttemptRecorder
+	// Shutdown stops the dispatcher after a last pass over the due entries. Entries still waiting
+	// for a retry stay in the outbox for the next start. When ctx is done first, the running
+	// dispatch is canceled and ctx.Err() is returned.
+	Shutdown(ctx context.Context) error
+}
+
+// errOutboxEntryTaken rolls back a dispatch whose entry was recorded by another dispatcher meanwhile.
+var errOutboxEntryTaken = errors.New("attempt outbox entry is no longer pending")
+
+type attemptOutboxService struct {
+	outboxRepo  domain.AttemptOutboxRepository
+	attemptRepo domain.UserQuizAttemptRepository
+	txManager   domain.TransactionManager
+	settings    config.AttemptOutboxConfig
+
+	wake       chan struct{} // Signals the dispatcher that a new entry is waiting
+	stop       chan struct{}
+	stopOnce   sync.Once
+	stopped    chan struct{} // Closed when the dispatcher loop has returned
+	workCtx    context.Context
+	cancelWork context.CancelFunc
+}
+
+// NewAttemptOutboxService creates the service and starts its dispatcher.
+func NewAttemptOutboxService(
+	outboxRepo domain.AttemptOutboxRepository,
+	attemptRepo domain.UserQuizAttemptRepository,
+	txManager domain.TransactionManager,
+	settings config.AttemptOutboxConfig,
+) AttemptOutboxService {
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	s := &attemptOutboxService{
+		outboxRepo:  outboxRepo,
+		attemptRepo: attemptRepo,
+		txManager:   txManager,
+		settings:    settings,
+		wake:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+		workCtx:     workCtx,
+		cancelWork:  cancelWork,
+	}
+	go s.run()
+	return s
+}
+
+// RecordAttempt implements AttemptRecorder.
+func (s *attemptOutboxService) RecordAttempt(ctx context.Context, idempotencyKey string, userID string, quizID string, userAnswer string, evalResult *domain.Answer) error {
+	if evalResult == nil {
+		return errors.New("evaluation result cannot be nil")
+	}
+
+	id := util.NewULID()
+	if idempotencyKey == "" {
+		idempotencyKey = id
+	}
+	now := time.Now()
+	entry := &domain.AttemptOutboxEntry{
+		ID:             id,
+		IdempotencyKey: idempotencyKey,
+		Attempt:        *newUserQuizAttempt(id, userID, quizID, userAnswer, evalResult),
+		Status:         domain.OutboxStatusPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}
+
+	inserted, err := s.outboxRepo.Enqueue(ctx, entry)
+	if err != nil {
+		return domain.NewInternalError("failed to queue quiz attempt", err)
+	}
+	if !inserted {
+		logger.Get().Info("Quiz attempt already queued, ignoring duplicate",
+			zap.String("userID", userID),
+			zap.String("quizID", quizID),
+			zap.String("idempotencyKey", idempotencyKey))
+		return nil
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default: // A dispatch pass is already pending and will pick the entry up
+	}
+	return nil
+}
+
+// Shutdown implements AttemptOutboxService.
+func (s *attemptOutboxService) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.stopped:
+	case <-ctx.Done():
+		s.cancelWork()
+		logger.Get().Warn("Attempt outbox dispatcher did not stop before shutdown deadline")
+		return ctx.Err()
+	}
+
+	// Last pass, so attempts queued by the final requests are not left for the next start.
+	s.dispatchDue(ctx)
+	s.cancelWork()
+	return ctx.Err()
+}
+
+func (s *attemptOutboxService) run() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(max(s.settings.PollInterval, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		s.dispatchDue(s.workCtx)
+	}
+}
+
+// dispatchDue records due entries batch by batch until none are left.
+func (s *attemptOutboxService) dispatchDue(ctx context.Context) {
+	batchSize := max(s.settings.BatchSize, 1)
+	for ctx.Err() == nil {
+		entries, err := s.outboxRepo.FetchDue(ctx, time.Now(), batchSize)
+		if err != nil {
+			logger.Get().Error("Failed to fetch due attempt outbox entries", zap.Error(err))
+			return
+		}
+		for _, entry := range entries {
+			// An entry that could not be updated would be fetched again right away.
+			if !s.dispatch(ctx, entry) {
+				return
+			}
+		}
+		if len(entries) < batchSize {
+			return
+		}
+	}
+}
+
+// dispatch records one entry and marks it done in the same transaction, so an attempt is
+// recorded once even when several dispatchers pick up the same entry. It returns false
+// when the entry is left unchanged.
+func (s *attemptOutboxService) dispatch(ctx context.Context, entry *domain.AttemptOutboxEntry) bool {
+	err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		claimed, err := s.outboxRepo.MarkDone(txCtx, entry.ID)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return errOutboxEntryTaken
+		}
+		attempt := entry.Attempt
+		attempt.ID = entry.ID // Reusing the entry ID keeps the attempt unique per entry
+		return s.attemptRepo.CreateAttempt(txCtx, &attempt)
+	})
+	if err == nil {
+		logger.Get().Debug("Quiz attempt recorded from outbox", zap.String("entryID", entry.ID), zap.String("userID", entry.Attempt.UserID))
+		return true
+	}
+	if errors.Is(err, errOutboxEntryTaken) {
+		return true
+	}
+	if ctx.Err() != nil {
+		return false // Interrupted by shutdown; not counted as a failed try
+	}
+
+	tries := entry.Tries + 1
+	giveUp := tries >= max(s.settings.MaxTries, 1)
+	nextAttemptAt := time.Now().Add(s.backoff(tries))
+	if errMark := s.outboxRepo.MarkFailed(ctx, entry.ID, tries, nextAttemptAt, err.Error(), giveUp); errMark != nil {
+		logger.Get().Error("Failed to update attempt outbox entry after failed dispatch",
+			zap.String("entryID", entry.ID),
+			zap.NamedError("dispatchError", err),
+			zap.Error(errMark))
+		return false
+	}
+
+	if giveUp {
+		logger.Get().Error("Giving up on recording quiz attempt from outbox",
+			zap.String("entryID", entry.ID),
+			zap.String("userID", entry.Attempt.UserID),
+			zap.String("quizID", entry.Attempt.QuizID),
+			zap.Int("tries", tries),
+			zap.Error(err))
+	} else {
+		logger.Get().Warn("Failed to record quiz attempt from outbox, will retry",
+			zap.String("entryID", entry.ID),
+			zap.Int("tries", tries),
+			zap.Time("nextAttemptAt", nextAttemptAt),
+			zap.Error(err))
+	}
+	return true
+}
+
+// backoff returns the delay before the next try: InitialBackoff doubled for every failure after the first, capped at MaxBackoff.
+func (s *attemptOutboxService) backoff(tries int) time.Duration {
+	delay := s.settings.InitialBackoff
+	for i := 1; i < tries && delay < s.settings.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.settings.MaxBackoff {
+		return s.settings.MaxBackoff
+	}
+	return delay
+}
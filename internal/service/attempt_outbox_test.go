@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryOutboxRepository is an in-memory domain.AttemptOutboxRepository.
+type memoryOutboxRepository struct {
+	mu      sync.Mutex
+	entries []*domain.AttemptOutboxEntry
+}
+
+func (r *memoryOutboxRepository) Enqueue(_ context.Context, entry *domain.AttemptOutboxEntry) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entries {
+		if e.IdempotencyKey == entry.IdempotencyKey {
+			return false, nil
+		}
+	}
+	stored := *entry
+	r.entries = append(r.entries, &stored)
+	return true, nil
+}
+
+func (r *memoryOutboxRepository) FetchDue(_ context.Context, now time.Time, limit int) ([]*domain.AttemptOutboxEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []*domain.AttemptOutboxEntry
+	for _, e := range r.entries {
+		if e.Status == domain.OutboxStatusPending && !e.NextAttemptAt.After(now) && len(due) < limit {
+			copied := *e
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryOutboxRepository) MarkDone(_ context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entries {
+		if e.ID == id && e.Status == domain.OutboxStatusPending {
+			e.Status = domain.OutboxStatusDone
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryOutboxRepository) MarkFailed(_ context.Context, id string, tries int, nextAttemptAt time.Time, lastError string, giveUp bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entries {
+		if e.ID == id && e.Status == domain.OutboxStatusPending {
+			e.Tries = tries
+			e.NextAttemptAt = nextAttemptAt
+			e.LastError = lastError
+			if giveUp {
+				e.Status = domain.OutboxStatusFailed
+			}
+		}
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepository) get(t *testing.T, key string) domain.AttemptOutboxEntry {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entries {
+		if e.IdempotencyKey == key {
+			return *e
+		}
+	}
+	t.Fatalf("no outbox entry with key %q", key)
+	return domain.AttemptOutboxEntry{}
+}
+
+// createAttemptStub is a domain.UserQuizAttemptRepository that only supports CreateAttempt.
+type createAttemptStub struct {
+	domain.UserQuizAttemptRepository
+	mu       sync.Mutex
+	err      error
+	recorded []domain.UserQuizAttempt
+}
+
+func (s *createAttemptStub) CreateAttempt(_ context.Context, attempt *domain.UserQuizAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.recorded = append(s.recorded, *attempt)
+	return nil
+}
+
+func (s *createAttemptStub) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.recorded)
+}
+
+// outboxTxManager runs fn directly and restores the outbox entries when fn fails.
+type outboxTxManager struct {
+	outbox *memoryOutboxRepository
+}
+
+func (m outboxTxManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.outbox.mu.Lock()
+	snapshot := make([]domain.AttemptOutboxEntry, len(m.outbox.entries))
+	for i, e := range m.outbox.entries {
+		snapshot[i] = *e
+	}
+	m.outbox.mu.Unlock()
+
+	err := fn(ctx)
+	if err != nil {
+		m.outbox.mu.Lock()
+		for i := range snapshot {
+			*m.outbox.entries[i] = snapshot[i]
+		}
+		m.outbox.mu.Unlock()
+	}
+	return err
+}
+
+func TestAttemptOutboxService_RecordAttempt(t *testing.T) {
+	ctx := context.Background()
+	answer := &domain.Answer{Score: 0.8, Explanation: "Good", KeywordMatches: []string{"go"}, AnsweredAt: time.Now()}
+	settings := config.AttemptOutboxConfig{PollInterval: time.Hour, BatchSize: 10, MaxTries: 3, InitialBackoff: time.Second, MaxBackoff: time.Minute}
+
+	t.Run("Dispatches Queued Attempt Once", func(t *testing.T) {
+		outbox := &memoryOutboxRepository{}
+		attempts := &createAttemptStub{}
+		svc := NewAttemptOutboxService(outbox, attempts, outboxTxManager{outbox}, settings)
+		defer svc.Shutdown(ctx)
+
+		require.NoError(t, svc.RecordAttempt(ctx, "key-1", "user-1", "quiz-1", "An answer", answer))
+		require.NoError(t, svc.RecordAttempt(ctx, "key-1", "user-1", "quiz-1", "An answer", answer))
+
+		require.Eventually(t, func() bool { return attempts.count() == 1 }, time.Second, 5*time.Millisecond)
+		entry := outbox.get(t, "key-1")
+		assert.Equal(t, domain.OutboxStatusDone, entry.Status)
+
+		attempts.mu.Lock()
+		recorded := attempts.recorded[0]
+		attempts.mu.Unlock()
+		assert.Equal(t, entry.ID, recorded.ID)
+		assert.Equal(t, "user-1", recorded.UserID)
+		assert.Equal(t, "quiz-1", recorded.QuizID)
+		assert.Equal(t, 0.8, recorded.LLMScore)
+		assert.True(t, recorded.IsCorrect)
+	})
+
+	t.Run("Rejects Missing Evaluation Result", func(t *testing.T) {
+		outbox := &memoryOutboxRepository{}
+		svc := NewAttemptOutboxService(outbox, &createAttemptStub{}, outboxTxManager{outbox}, settings)
+		defer svc.Shutdown(ctx)
+
+		assert.Error(t, svc.RecordAttempt(ctx, "key-1", "user-1", "quiz-1", "An answer", nil))
+	})
+}
+
+func TestAttemptOutboxService_Dispatch_RetriesThenGivesUp(t *testing.T) {
+	ctx := context.Background()
+	outbox := &memoryOutboxRepository{}
+	attempts := &createAttemptStub{err: errors.New("database is down")}
+	svc := &attemptOutboxService{
+		outboxRepo:  outbox,
+		attemptRepo: attempts,
+		txManager:   outboxTxManager{outbox},
+		settings:    config.AttemptOutboxConfig{BatchSize: 10, MaxTries: 2, InitialBackoff: time.Minute, MaxBackoff: time.Hour},
+	}
+	_, err := outbox.Enqueue(ctx, &domain.AttemptOutboxEntry{ID: "entry-1", IdempotencyKey: "key-1", Status: domain.OutboxStatusPending})
+	require.NoError(t, err)
+
+	svc.dispatchDue(ctx)
+	entry := outbox.get(t, "key-1")
+	assert.Equal(t, domain.OutboxStatusPending, entry.Status)
+	assert.Equal(t, 1, entry.Tries)
+	assert.Equal(t, "database is down", entry.LastError)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), entry.NextAttemptAt, 5*time.Second)
+
+	svc.dispatchDue(ctx) // Not due yet
+	assert.Equal(t, 1, outbox.get(t, "key-1").Tries)
+
+	outbox.mu.Lock()
+	outbox.entries[0].NextAttemptAt = time.Now()
+	outbox.mu.Unlock()
+	svc.dispatchDue(ctx)
+	entry = outbox.get(t, "key-1")
+	assert.Equal(t, domain.OutboxStatusFailed, entry.Status)
+	assert.Equal(t, 2, entry.Tries)
+	assert.Equal(t, 0, attempts.count())
+}
+
+func TestAttemptOutboxService_Shutdown_DrainsDueEntries(t *testing.T) {
+	ctx := context.Background()
+	outbox := &memoryOutboxRepository{}
+	attempts := &createAttemptStub{}
+	svc := NewAttemptOutboxService(outbox, attempts, outboxTxManager{outbox}, config.AttemptOutboxConfig{PollInterval: time.Hour, BatchSize: 1, MaxTries: 3})
+
+	// Queued behind the service's back, so only the final pass in Shutdown can pick them up.
+	for _, key := range []string{"key-1", "key-2", "key-3"} {
+		_, err := outbox.Enqueue(ctx, &domain.AttemptOutboxEntry{ID: key, IdempotencyKey: key, Status: domain.OutboxStatusPending})
+		require.NoError(t, err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	require.NoError(t, svc.Shutdown(shutdownCtx))
+	assert.Equal(t, 3, attempts.count())
+}
+
+func TestAttemptOutboxService_Backoff(t *testing.T) {
+	svc := &attemptOutboxService{settings: config.AttemptOutboxConfig{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}}
+
+	assert.Equal(t, time.Second, svc.backoff(1))
+	assert.Equal(t, 2*time.Second, svc.backoff(2))
+	assert.Equal(t, 4*time.Second, svc.backoff(3))
+	assert.Equal(t, 5*time.Second, svc.backoff(4))
+	assert.Equal(t, 5*time.Second, svc.backoff(20))
+}
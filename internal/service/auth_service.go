@@ -6,134 +6,231 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 
+	"quiz-byte/internal/cache"
 	"quiz-byte/internal/config"
 	"quiz-byte/internal/domain"
 	"quiz-byte/internal/dto"    // For AuthClaims and AuthenticatedUser
 	"quiz-byte/internal/logger" // Added
-	"quiz-byte/internal/util"   // For ULID generation
+	"quiz-byte/internal/port"
+	"quiz-byte/internal/util" // For ULID generation
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap" // Added
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 )
 
 const (
-	googleUserInfoURL = "https://www.googleapis.com/oauth2/v2/userinfo"
-	tokenTypeAccess   = "access"
-	tokenTypeRefresh  = "refresh"
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
 )
 
 var (
 	ErrInvalidAuthState      = errors.New("invalid oauth state")
 	ErrFailedToExchangeToken = errors.New("failed to exchange oauth token")
-	ErrFailedToGetUserInfo   = errors.New("failed to get user info from google")
+	ErrFailedToGetUserInfo   = errors.New("failed to get user info from identity provider")
+	ErrInvalidIDToken        = errors.New("invalid id token from identity provider")
 	ErrInvalidJWTToken       = errors.New("invalid jwt token")
 	ErrEncryptionFailed      = errors.New("failed to encrypt token")
 	ErrDecryptionFailed      = errors.New("failed to decrypt token")
+	ErrRefreshTokenReused    = errors.New("refresh token was already used; the session has been revoked")
+	ErrSessionRevoked        = errors.New("session has been revoked")
+)
+
+var (
+	ErrUnknownIdentityProvider = errors.New("unknown identity provider")
+	// ErrIdentityEmailConflict is returned when an account's email belongs to an existing user but the
+	// provider did not verify it, so the account cannot be linked to that user.
+	ErrIdentityEmailConflict = errors.New("an account with this email already exists; sign in with the provider used before")
 )
 
 // AuthService defines the interface for authentication operations.
 type AuthService interface {
-	GetGoogleLoginURL(state string) string
-	HandleGoogleCallback(ctx context.Context, code string, receivedState string, expectedState string) (accessToken string, refreshToken string, user *dto.AuthenticatedUser, err error) // Changed return type
+	// IdentityProviders lists the names of the configured identity providers.
+	IdentityProviders() []string
+	// LoginURL starts a login: it returns the consent page URL of the identity provider and the
+	// flow the callback must be handled with. It returns ErrUnknownIdentityProvider when no provider has that name.
+	LoginURL(ctx context.Context, provider string) (string, *dto.OAuthFlow, error)
+	// HandleOAuthCallback signs the user in with the identity provider and starts a login session for the client.
+	// flow is the one LoginURL returned for this login.
+	HandleOAuthCallback(ctx context.Context, provider string, code string, receivedState string, flow dto.OAuthFlow, clientInfo dto.SessionClientInfo) (accessToken string, refreshToken string, user *dto.AuthenticatedUser, err error)
+	// ListIdentities lists the identity provider accounts linked to the user.
+	ListIdentities(ctx context.Context, userID string) (*dto.IdentityListResponse, error)
 	ValidateJWT(ctx context.Context, tokenString string) (*dto.AuthClaims, error)
 	CreateJWT(ctx context.Context, user *domain.User, ttl time.Duration, tokenType string) (string, error)
 	RefreshToken(ctx context.Context, refreshTokenString string) (newAccessToken string, newRefreshToken string, err error)
 	EncryptToken(token string) (string, error)
 	DecryptToken(encryptedToken string) (string, error)
+	// Logout revokes the session of the given access token claims, so none of its tokens are accepted anymore.
+	Logout(ctx context.Context, claims *dto.AuthClaims) error
+	// IsTokenRevoked reports whether a validated access token was revoked, directly or through its session.
+	IsTokenRevoked(ctx context.Context, claims *dto.AuthClaims) (bool, error)
+	// ListSessions lists the user's active sessions, marking currentSessionID as the current one.
+	ListSessions(ctx context.Context, userID string, currentSessionID string) (*dto.SessionListResponse, error)
+	// RevokeSession signs one of the user's sessions out. It returns a not found error when the
+	// session does not belong to the user or is no longer active.
+	RevokeSession(ctx context.Context, userID string, sessionID string) error
+	// RevokeOtherSessions signs out every active session of the user except currentSessionID.
+	RevokeOtherSessions(ctx context.Context, userID string, currentSessionID string) (int, error)
+	// JWKS returns the public keys tokens can be verified with, including keys kept after a rotation.
+	JWKS() *dto.JWKSet
+	// RefreshExpiringGoogleTokens renews up to limit stored Google access tokens that expire before
+	// expiringBefore, and returns how many were renewed.
+	RefreshExpiringGoogleTokens(ctx context.Context, expiringBefore time.Time, limit int) (int, error)
+	// DeleteAccount signs out all of the user's sessions, then soft-deletes the user, unlinks their
+	// identities and revokes the Google grant. It returns a not found error when the user does not exist.
+	DeleteAccount(ctx context.Context, userID string) error
 }
 
 type authServiceImpl struct {
 	userRepo      domain.UserRepository // Changed to domain.UserRepository
-	oauth2Config  *oauth2.Config
+	identityRepo  domain.UserIdentityRepository
+	sessionRepo   domain.UserSessionRepository
+	roleRepo      domain.RoleRepository // Roles put into access tokens; may be nil
+	tokenDenylist domain.Cache          // Revoked sessions and tokens, checked on every authenticated request; may be nil
+	providers     map[string]port.IdentityProvider
+	providerNames []string          // Configuration order
 	authCfg       config.AuthConfig // Changed from appConfig
+	jwtKeys       *jwtKeySet
 	encryptionKey []byte
 	txManager     domain.TransactionManager // Added for transaction support
 }
 
 // NewAuthService creates a new instance of AuthService.
-func NewAuthService(userRepo domain.UserRepository, authCfg config.AuthConfig, txManager domain.TransactionManager) (AuthService, error) { // Changed param type
-	if len(authCfg.JWT.SecretKey) == 0 {
-		return nil, errors.New("encryption key for auth service is not configured (use JWT.SecretKey or a dedicated one)")
+// Revoked sessions are also put on tokenDenylist, so their access tokens are rejected without a database lookup.
+// Users sign in with identityProviders; provider names must be unique.
+// Access tokens carry the user's roles from roleRepo as of their issuing, so a granted or revoked
+// role takes effect with the next token refresh.
+func NewAuthService(userRepo domain.UserRepository, identityRepo domain.UserIdentityRepository, sessionRepo domain.UserSessionRepository, roleRepo domain.RoleRepository, tokenDenylist domain.Cache, identityProviders []port.IdentityProvider, authCfg config.AuthConfig, txManager domain.TransactionManager) (AuthService, error) { // Changed param type
+	if authCfg.TokenEncryptionKey == "" {
+		return nil, errors.New("token encryption key for auth service is not configured (auth.token_encryption_key)")
 	}
-	var encKey []byte
-	if len(authCfg.JWT.SecretKey) >= 32 {
-		encKey = []byte(authCfg.JWT.SecretKey[:32])
-	} else {
-		return nil, errors.New("encryption key must be at least 32 bytes long")
+	encKey, err := base64.StdEncoding.DecodeString(authCfg.TokenEncryptionKey)
+	if err != nil || len(encKey) != 32 {
+		return nil, errors.New("token encryption key must be 32 bytes, base64 encoded")
+	}
+
+	jwtKeys, ephemeral, err := newJWTKeySet(authCfg.JWT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+	if ephemeral {
+		logger.Get().Warn("No JWT signing keys configured; using a temporary key. Tokens will not survive a restart.")
+	}
+
+	providers := make(map[string]port.IdentityProvider, len(identityProviders))
+	providerNames := make([]string, 0, len(identityProviders))
+	for _, provider := range identityProviders {
+		if _, dup := providers[provider.Name()]; dup {
+			return nil, fmt.Errorf("identity provider %q is configured more than once", provider.Name())
+		}
+		providers[provider.Name()] = provider
+		providerNames = append(providerNames, provider.Name())
 	}
 
 	return &authServiceImpl{
-		userRepo: userRepo,
-		oauth2Config: &oauth2.Config{
-			ClientID:     authCfg.GoogleOAuth.ClientID,
-			ClientSecret: authCfg.GoogleOAuth.ClientSecret,
-			RedirectURL:  authCfg.GoogleOAuth.RedirectURL,
-			Scopes:       []string{"https_://www.googleapis.com/auth/userinfo.email", "https_://www.googleapis.com/auth/userinfo.profile"},
-			Endpoint:     google.Endpoint,
-		},
+		userRepo:      userRepo,
+		identityRepo:  identityRepo,
+		sessionRepo:   sessionRepo,
+		roleRepo:      roleRepo,
+		tokenDenylist: tokenDenylist,
+		providers:     providers,
+		providerNames: providerNames,
 		authCfg:       authCfg,
+		jwtKeys:       jwtKeys,
 		encryptionKey: encKey,
 		txManager:     txManager, // Added transaction manager
 	}, nil
 }
 
-func (s *authServiceImpl) GetGoogleLoginURL(state string) string {
-	return s.oauth2Config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce)
+// IdentityProviders implements AuthService.
+func (s *authServiceImpl) IdentityProviders() []string {
+	return s.providerNames
 }
 
-func (s *authServiceImpl) HandleGoogleCallback(ctx context.Context, code string, receivedState string, expectedState string) (string, string, *dto.AuthenticatedUser, error) { // Changed return type
-	appLogger := logger.Get()
-	if receivedState != expectedState {
-		return "", "", nil, ErrInvalidAuthState
+// LoginURL implements AuthService. Every login gets its own state, nonce and PKCE verifier.
+func (s *authServiceImpl) LoginURL(ctx context.Context, providerName string) (string, *dto.OAuthFlow, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", nil, ErrUnknownIdentityProvider
 	}
-
-	googleToken, err := s.oauth2Config.Exchange(ctx, code)
-	if err != nil {
-		return "", "", nil, fmt.Errorf("%w: %v", ErrFailedToExchangeToken, err)
+	flow := &dto.OAuthFlow{
+		State:        rand.Text(),
+		Nonce:        rand.Text(),
+		CodeVerifier: oauth2.GenerateVerifier(),
 	}
-
-	client := s.oauth2Config.Client(ctx, googleToken)
-	resp, err := client.Get(googleUserInfoURL)
+	loginURL, err := provider.AuthCodeURL(ctx, port.AuthRequest(*flow))
 	if err != nil {
-		return "", "", nil, fmt.Errorf("%w: %v", ErrFailedToGetUserInfo, err)
+		return "", nil, fmt.Errorf("failed to build %s login url: %w", providerName, err)
 	}
-	defer resp.Body.Close()
+	return loginURL, flow, nil
+}
 
-	var userInfo dto.GoogleUserInfo
-	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
-		return "", "", nil, fmt.Errorf("failed to decode user info: %w", err)
+// HandleOAuthCallback implements AuthService. The account is looked up by its linked identity. An
+// unknown account is linked to the user with the same email when the provider verified the email,
+// and otherwise signs up a new user. Users only get an email a provider verified, so an unverified
+// sign-up can never claim the address of the person who owns it.
+func (s *authServiceImpl) HandleOAuthCallback(ctx context.Context, providerName string, code string, receivedState string, flow dto.OAuthFlow, clientInfo dto.SessionClientInfo) (string, string, *dto.AuthenticatedUser, error) {
+	appLogger := logger.Get()
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", nil, ErrUnknownIdentityProvider
 	}
-
-	if userInfo.ID == "" || userInfo.Email == "" {
-		return "", "", nil, errors.New("google user info is incomplete")
+	if flow.State == "" || receivedState != flow.State || flow.Nonce == "" || flow.CodeVerifier == "" {
+		return "", "", nil, ErrInvalidAuthState
 	}
 
-	domainUser, err := s.userRepo.GetUserByGoogleID(ctx, userInfo.ID)
-	// Check for actual error vs not found (nil, nil from repo)
-	if err != nil { // Error from repository (other than sql.ErrNoRows which results in domainUser == nil, err == nil)
-		return "", "", nil, domain.NewInternalError(fmt.Sprintf("error fetching user by google_id %s", userInfo.ID), err)
+	account, providerToken, err := provider.Exchange(ctx, code, port.AuthRequest(flow))
+	if err != nil {
+		if errors.Is(err, port.ErrCodeExchange) {
+			return "", "", nil, fmt.Errorf("%w: %v", ErrFailedToExchangeToken, err)
+		}
+		if errors.Is(err, port.ErrInvalidIDToken) {
+			return "", "", nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+		}
+		return "", "", nil, fmt.Errorf("%w: %v", ErrFailedToGetUserInfo, err)
+	}
+	if account.Subject == "" || account.Email == "" {
+		return "", "", nil, fmt.Errorf("%s user info is incomplete", providerName)
 	}
-	// If domainUser is nil (and err is nil), it means user not found, which is handled by creating the user.
 
-	// Token encryption logic remains, but these encrypted tokens are not directly saved
-	// by the domain repository's CreateUser/UpdateUser methods.
-	// Their persistence is deferred / handled by a future specialized repository method.
-	_, err = s.EncryptToken(googleToken.AccessToken) // Keep encryption to see if it works, but don't use the result for domainUser
+	identity, err := s.identityRepo.GetIdentity(ctx, providerName, account.Subject)
 	if err != nil {
-		return "", "", nil, fmt.Errorf("failed to encrypt access token: %w", err)
+		return "", "", nil, domain.NewInternalError(fmt.Sprintf("error fetching %s identity %s", providerName, account.Subject), err)
 	}
-	if googleToken.RefreshToken != "" {
-		_, err = s.EncryptToken(googleToken.RefreshToken)
+	var domainUser *domain.User
+	if identity != nil {
+		domainUser, err = s.userRepo.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			return "", "", nil, domain.NewInternalError(fmt.Sprintf("error fetching user %s", identity.UserID), err)
+		}
+		if domainUser == nil { // Identities are removed when the account is deleted
+			return "", "", nil, domain.NewInternalError(fmt.Sprintf("user %s of %s identity not found", identity.UserID, providerName), nil)
+		}
+	} else {
+		// Only verified emails are stored on users, so a match is the owner of the address.
+		domainUser, err = s.userRepo.GetUserByEmail(ctx, account.Email)
 		if err != nil {
-			return "", "", nil, fmt.Errorf("failed to encrypt refresh token: %w", err)
+			return "", "", nil, domain.NewInternalError("error fetching user by email", err)
+		}
+		if domainUser != nil && !account.EmailVerified {
+			return "", "", nil, ErrIdentityEmailConflict
+		}
+	}
+	userEmail := account.Email
+	if !account.EmailVerified {
+		userEmail = "" // Stored as NULL; the identity keeps the unverified email
+	}
+
+	// Google's tokens are stored encrypted, for renewing them in the background and revoking the grant on account deletion.
+	var googleTokens *domain.GoogleTokens
+	if providerName == domain.IdentityProviderGoogle {
+		if googleTokens, err = s.encryptGoogleToken(providerToken); err != nil {
+			return "", "", nil, err
 		}
 	}
 
@@ -144,28 +241,56 @@ func (s *authServiceImpl) HandleGoogleCallback(ctx context.Context, code string,
 		if domainUser == nil { // User not found, create new domain user
 			domainUser = &domain.User{
 				ID:                util.NewULID(),
-				GoogleID:          userInfo.ID,
-				Email:             userInfo.Email,
-				Name:              userInfo.Name,
-				ProfilePictureURL: userInfo.Picture,
+				Email:             userEmail,
+				Name:              account.Name,
+				ProfilePictureURL: account.PictureURL,
 				CreatedAt:         now,
 				UpdatedAt:         now,
 			}
 			// err from CreateUser is already wrapped by the repository
 			if err := s.userRepo.CreateUser(txCtx, domainUser); err != nil {
-				return domain.NewInternalError("failed to create user during google callback", err)
+				return domain.NewInternalError(fmt.Sprintf("failed to create user during %s callback", providerName), err)
 			}
-			appLogger.Info("New user created via Google OAuth", zap.String("userID", domainUser.ID), zap.String("email", domainUser.Email))
+			appLogger.Info("New user created via OAuth", zap.String("provider", providerName), zap.String("userID", domainUser.ID), zap.String("email", domainUser.Email))
 		} else { // User found, update profile info if changed
-			domainUser.Email = userInfo.Email
-			domainUser.Name = userInfo.Name
-			domainUser.ProfilePictureURL = userInfo.Picture
+			// The email is kept: with several linked identities no single provider owns it.
+			if account.Name != "" {
+				domainUser.Name = account.Name
+			}
+			if account.PictureURL != "" {
+				domainUser.ProfilePictureURL = account.PictureURL
+			}
 			domainUser.UpdatedAt = now
 			// err from UpdateUser is already wrapped by the repository
 			if err := s.userRepo.UpdateUser(txCtx, domainUser); err != nil {
-				return domain.NewInternalError("failed to update user during google callback", err)
+				return domain.NewInternalError(fmt.Sprintf("failed to update user during %s callback", providerName), err)
+			}
+			appLogger.Info("User logged in via OAuth", zap.String("provider", providerName), zap.String("userID", domainUser.ID), zap.String("email", domainUser.Email))
+		}
+
+		if identity == nil {
+			identity = &domain.UserIdentity{
+				ID:          util.NewULID(),
+				UserID:      domainUser.ID,
+				Provider:    providerName,
+				Subject:     account.Subject,
+				Email:       account.Email,
+				LastLoginAt: now,
+				CreatedAt:   now,
+			}
+			if err := s.identityRepo.CreateIdentity(txCtx, identity); err != nil {
+				return domain.NewInternalError(fmt.Sprintf("failed to link %s identity during callback", providerName), err)
+			}
+			appLogger.Info("Identity linked to user", zap.String("provider", providerName), zap.String("userID", domainUser.ID))
+		} else if err := s.identityRepo.UpdateIdentityLogin(txCtx, identity.ID, account.Email, now); err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to update %s identity during callback", providerName), err)
+		}
+
+		if googleTokens != nil {
+			googleTokens.UserID = domainUser.ID
+			if err := s.userRepo.UpdateGoogleTokens(txCtx, googleTokens); err != nil {
+				return domain.NewInternalError("failed to store google tokens during google callback", err)
 			}
-			appLogger.Info("User logged in via Google OAuth", zap.String("userID", domainUser.ID), zap.String("email", domainUser.Email))
 		}
 		return nil
 	})
@@ -173,15 +298,9 @@ func (s *authServiceImpl) HandleGoogleCallback(ctx context.Context, code string,
 		return "", "", nil, err
 	}
 
-	accessToken, err := s.CreateJWT(ctx, domainUser, s.authCfg.JWT.AccessTokenTTL, tokenTypeAccess)
-	if err != nil {
-		// CreateJWT now returns a wrapped error
-		return "", "", nil, domain.NewInternalError("failed to create access token", err)
-	}
-	refreshToken, err := s.CreateJWT(ctx, domainUser, s.authCfg.JWT.RefreshTokenTTL, tokenTypeRefresh)
+	accessToken, refreshToken, err := s.startSession(ctx, domainUser, clientInfo)
 	if err != nil {
-		// CreateJWT now returns a wrapped error
-		return "", "", nil, domain.NewInternalError("failed to create refresh token", err)
+		return "", "", nil, err
 	}
 
 	// Map domainUser to dto.AuthenticatedUser for the return type.
@@ -195,19 +314,112 @@ func (s *authServiceImpl) HandleGoogleCallback(ctx context.Context, code string,
 	return accessToken, refreshToken, authenticatedUserData, nil
 }
 
+// ListIdentities implements AuthService.
+func (s *authServiceImpl) ListIdentities(ctx context.Context, userID string) (*dto.IdentityListResponse, error) {
+	identities, err := s.identityRepo.ListIdentitiesByUserID(ctx, userID)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to list identities of user %s", userID), err)
+	}
+
+	items := make([]dto.IdentityItem, 0, len(identities))
+	for _, identity := range identities {
+		items = append(items, dto.IdentityItem{
+			Provider:    identity.Provider,
+			Email:       identity.Email,
+			LinkedAt:    identity.CreatedAt,
+			LastLoginAt: identity.LastLoginAt,
+		})
+	}
+	return &dto.IdentityListResponse{Identities: items}, nil
+}
+
+// startSession creates a login session for the user and issues its first token pair.
+func (s *authServiceImpl) startSession(ctx context.Context, user *domain.User, client dto.SessionClientInfo) (string, string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now()
+	session := &domain.UserSession{
+		ID:         util.NewULID(),
+		UserID:     user.ID,
+		CurrentJTI: jti,
+		ExpiresAt:  now.Add(s.authCfg.JWT.RefreshTokenTTL),
+		LastUsedAt: now,
+		Device:     describeDevice(client.UserAgent),
+		UserAgent:  truncate(client.UserAgent, maxSessionUserAgentLength),
+		IPAddress:  truncate(client.IPAddress, maxSessionIPAddressLength),
+		CreatedAt:  now,
+	}
+	if err := s.sessionRepo.CreateSession(ctx, session); err != nil {
+		return "", "", domain.NewInternalError("failed to create user session", err)
+	}
+
+	roles, err := s.userRoles(ctx, user.ID)
+	if err != nil {
+		return "", "", err
+	}
+	accessToken, err := s.signJWT(user.ID, s.authCfg.JWT.AccessTokenTTL, tokenTypeAccess, session.ID, "", roles)
+	if err != nil {
+		return "", "", domain.NewInternalError("failed to create access token", err)
+	}
+	refreshToken, err := s.signJWT(user.ID, s.authCfg.JWT.RefreshTokenTTL, tokenTypeRefresh, session.ID, jti, nil)
+	if err != nil {
+		return "", "", domain.NewInternalError("failed to create refresh token", err)
+	}
+	return accessToken, refreshToken, nil
+}
+
+// userRoles returns the roles to put into the user's access tokens.
+func (s *authServiceImpl) userRoles(ctx context.Context, userID string) ([]string, error) {
+	if s.roleRepo == nil {
+		return nil, nil
+	}
+	roles, err := s.roleRepo.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to list roles of user %s", userID), err)
+	}
+	return roles, nil
+}
+
+// newTokenID generates an unguessable jti.
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", domain.NewInternalError("failed to generate token id", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CreateJWT signs a token that is not bound to a session.
 func (s *authServiceImpl) CreateJWT(ctx context.Context, user *domain.User, ttl time.Duration, tokenType string) (string, error) {
+	return s.signJWT(user.ID, ttl, tokenType, "", "", nil)
+}
+
+// signJWT signs a token for the session; a new jti is generated when jti is empty.
+// Only access tokens need roles; refresh tokens look them up again when they are used.
+func (s *authServiceImpl) signJWT(userID string, ttl time.Duration, tokenType string, sessionID string, jti string, roles []string) (string, error) {
+	if jti == "" {
+		var err error
+		if jti, err = newTokenID(); err != nil {
+			return "", err
+		}
+	}
+	now := time.Now()
 	claims := dto.AuthClaims{
-		UserID:    user.ID,
+		UserID:    userID,
 		TokenType: tokenType,
+		SessionID: sessionID,
+		Roles:     roles,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Subject:   user.ID,
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   userID,
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(s.authCfg.JWT.SecretKey))
+	signedToken, err := s.jwtKeys.sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign JWT: %w", err)
 	}
@@ -223,12 +435,7 @@ func min(a, b int) int {
 
 func (s *authServiceImpl) ValidateJWT(ctx context.Context, tokenString string) (*dto.AuthClaims, error) {
 	appLogger := logger.Get()
-	token, err := jwt.ParseWithClaims(tokenString, &dto.AuthClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.authCfg.JWT.SecretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &dto.AuthClaims{}, s.jwtKeys.verificationKey, jwt.WithValidMethods(s.jwtKeys.validMethods()))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -278,21 +485,211 @@ func (s *authServiceImpl) RefreshToken(ctx context.Context, refreshTokenString s
 		return "", "", domain.NewNotFoundError(fmt.Sprintf("user %s not found for refresh token", claims.UserID))
 	}
 
-	newAccessToken, err := s.CreateJWT(ctx, domainUser, s.authCfg.JWT.AccessTokenTTL, tokenTypeAccess)
+	if claims.SessionID == "" || claims.ID == "" {
+		return "", "", fmt.Errorf("%w: refresh token is not bound to a session", ErrInvalidJWTToken)
+	}
+
+	// Rotate: the presented token is retired and a new one becomes the only usable token of the session.
+	newJTI, err := newTokenID()
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now()
+	rotated, err := s.sessionRepo.RotateSession(ctx, claims.SessionID, claims.ID, newJTI, now.Add(s.authCfg.JWT.RefreshTokenTTL), now)
+	if err != nil {
+		return "", "", domain.NewInternalError(fmt.Sprintf("failed to rotate session %s", claims.SessionID), err)
+	}
+	if !rotated {
+		return "", "", s.rejectStaleRefreshToken(ctx, claims)
+	}
+
+	roles, err := s.userRoles(ctx, domainUser.ID)
+	if err != nil {
+		return "", "", err
+	}
+	newAccessToken, err := s.signJWT(domainUser.ID, s.authCfg.JWT.AccessTokenTTL, tokenTypeAccess, claims.SessionID, "", roles)
 	if err != nil {
-		// CreateJWT now returns a wrapped error
 		return "", "", domain.NewInternalError("failed to create new access token during refresh", err)
 	}
-	newRefreshToken, err := s.CreateJWT(ctx, domainUser, s.authCfg.JWT.RefreshTokenTTL, tokenTypeRefresh)
+	newRefreshToken, err := s.signJWT(domainUser.ID, s.authCfg.JWT.RefreshTokenTTL, tokenTypeRefresh, claims.SessionID, newJTI, nil)
 	if err != nil {
-		// CreateJWT now returns a wrapped error
 		return "", "", domain.NewInternalError("failed to create new refresh token during refresh", err)
 	}
 
-	appLogger.Info("JWT token refreshed", zap.String("userID", domainUser.ID))
+	appLogger.Info("JWT token refreshed", zap.String("userID", domainUser.ID), zap.String("sessionID", claims.SessionID))
 	return newAccessToken, newRefreshToken, nil
 }
 
+// rejectStaleRefreshToken explains why a refresh token could not be rotated. A token of an active
+// session that is not its current one was rotated before, so it is being replayed: the whole
+// session is revoked, since either the user or an attacker holds a stolen copy.
+func (s *authServiceImpl) rejectStaleRefreshToken(ctx context.Context, claims *dto.AuthClaims) error {
+	session, err := s.sessionRepo.GetSessionByID(ctx, claims.SessionID)
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("error fetching session %s for refresh token", claims.SessionID), err)
+	}
+	if session == nil {
+		return fmt.Errorf("%w: unknown session", ErrInvalidJWTToken)
+	}
+	if session.RevokedAt != nil {
+		return ErrSessionRevoked
+	}
+
+	logger.Get().Warn("Refresh token reuse detected, revoking session",
+		zap.String("userID", claims.UserID),
+		zap.String("sessionID", claims.SessionID),
+		zap.String("jti", claims.ID))
+	if err := s.revokeSession(ctx, claims.SessionID, domain.SessionRevokedTokenReuse); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Logout implements AuthService.
+func (s *authServiceImpl) Logout(ctx context.Context, claims *dto.AuthClaims) error {
+	if claims.SessionID != "" {
+		return s.revokeSession(ctx, claims.SessionID, domain.SessionRevokedLogout)
+	}
+	// Tokens issued before sessions existed: only the presented access token can be revoked.
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.denyToken(ctx, revokedTokenKey(claims.ID), domain.SessionRevokedLogout, time.Until(claims.ExpiresAt.Time)); err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to revoke token %s", claims.ID), err)
+		}
+	}
+	return nil
+}
+
+// revokeSession puts the session on the denylist for as long as its access tokens live and revokes it.
+// Access tokens are only checked against the database when the denylist cannot be read, so a session
+// that could not be put on the denylist is left active and the error returned for the caller to retry.
+func (s *authServiceImpl) revokeSession(ctx context.Context, sessionID string, reason string) error {
+	if err := s.denyToken(ctx, revokedSessionKey(sessionID), reason, s.authCfg.JWT.AccessTokenTTL); err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to revoke session %s", sessionID), err)
+	}
+	if _, err := s.sessionRepo.RevokeSession(ctx, sessionID, reason, time.Now()); err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to revoke session %s", sessionID), err)
+	}
+	logger.Get().Info("Session revoked", zap.String("sessionID", sessionID), zap.String("reason", reason))
+	return nil
+}
+
+func (s *authServiceImpl) denyToken(ctx context.Context, key string, reason string, ttl time.Duration) error {
+	if s.tokenDenylist == nil || ttl <= 0 {
+		return nil
+	}
+	if err := s.tokenDenylist.Set(ctx, key, reason, ttl); err != nil {
+		return fmt.Errorf("failed to add revoked token to denylist: %w", err)
+	}
+	return nil
+}
+
+// IsTokenRevoked implements AuthService. The denylist is checked first; when it is unavailable
+// the session is looked up in the database instead.
+func (s *authServiceImpl) IsTokenRevoked(ctx context.Context, claims *dto.AuthClaims) (bool, error) {
+	var key string
+	switch {
+	case claims.SessionID != "":
+		key = revokedSessionKey(claims.SessionID)
+	case claims.ID != "":
+		key = revokedTokenKey(claims.ID)
+	default:
+		return false, nil // Issued before tokens could be revoked
+	}
+
+	if s.tokenDenylist != nil {
+		_, err := s.tokenDenylist.Get(ctx, key)
+		if err == nil {
+			return true, nil
+		}
+		if errors.Is(err, domain.ErrCacheMiss) {
+			return false, nil
+		}
+		logger.Get().Warn("Token denylist lookup failed, checking the session instead", zap.String("key", key), zap.Error(err))
+	}
+
+	if claims.SessionID == "" {
+		return false, nil
+	}
+	session, err := s.sessionRepo.GetSessionByID(ctx, claims.SessionID)
+	if err != nil {
+		return false, domain.NewInternalError(fmt.Sprintf("failed to check session %s", claims.SessionID), err)
+	}
+	return session == nil || session.RevokedAt != nil, nil
+}
+
+// ListSessions implements AuthService.
+func (s *authServiceImpl) ListSessions(ctx context.Context, userID string, currentSessionID string) (*dto.SessionListResponse, error) {
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID, time.Now())
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to list sessions of user %s", userID), err)
+	}
+
+	items := make([]dto.SessionItem, 0, len(sessions))
+	for _, session := range sessions {
+		items = append(items, dto.SessionItem{
+			ID:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return &dto.SessionListResponse{Sessions: items}, nil
+}
+
+// RevokeSession implements AuthService.
+func (s *authServiceImpl) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	session, err := s.sessionRepo.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to get session %s", sessionID), err)
+	}
+	// Sessions of other users are reported as missing, so their IDs cannot be probed.
+	if session == nil || session.UserID != userID || session.RevokedAt != nil || !session.ExpiresAt.After(time.Now()) {
+		return domain.NewNotFoundError(fmt.Sprintf("session %s not found", sessionID))
+	}
+	return s.revokeSession(ctx, sessionID, domain.SessionRevokedByUser)
+}
+
+// RevokeOtherSessions implements AuthService.
+func (s *authServiceImpl) RevokeOtherSessions(ctx context.Context, userID string, currentSessionID string) (int, error) {
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID, time.Now())
+	if err != nil {
+		return 0, domain.NewInternalError(fmt.Sprintf("failed to list sessions of user %s", userID), err)
+	}
+
+	return s.revokeSessions(ctx, sessions, currentSessionID, domain.SessionRevokedByUser)
+}
+
+// revokeSessions revokes the given sessions except exceptSessionID and returns how many were revoked.
+func (s *authServiceImpl) revokeSessions(ctx context.Context, sessions []domain.UserSession, exceptSessionID string, reason string) (int, error) {
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == exceptSessionID {
+			continue
+		}
+		if err := s.revokeSession(ctx, session.ID, reason); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+func revokedSessionKey(sessionID string) string {
+	return cache.GenerateCacheKey("auth", "revoked_session", sessionID)
+}
+
+func revokedTokenKey(jti string) string {
+	return cache.GenerateCacheKey("auth", "revoked_token", jti)
+}
+
+// JWKS returns the public keys of the configured JWT keys.
+func (s *authServiceImpl) JWKS() *dto.JWKSet {
+	return s.jwtKeys.jwks()
+}
+
 // EncryptToken encrypts a token using AES-GCM.
 func (s *authServiceImpl) EncryptToken(token string) (string, error) {
 	if token == "" {
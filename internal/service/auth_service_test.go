@@ -6,13 +6,20 @@ import (
 	"fmt"
 	"quiz-byte/internal/config"
 	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+// testTokenEncryptionKey is a base64 encoded 32 byte AES key.
+const testTokenEncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
 // MockUserRepository is a mock type for the domain.UserRepository
 type MockUserRepository struct {
 	mock.Mock
@@ -23,8 +30,8 @@ func (m *MockUserRepository) CreateUser(ctx context.Context, user *domain.User)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) GetUserByGoogleID(ctx context.Context, googleID string) (*domain.User, error) {
-	args := m.Called(ctx, googleID)
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	args := m.Called(ctx, email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -44,17 +51,366 @@ func (m *MockUserRepository) UpdateUser(ctx context.Context, user *domain.User)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateGoogleTokens(ctx context.Context, tokens *domain.GoogleTokens) error {
+	args := m.Called(ctx, tokens)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetGoogleTokens(ctx context.Context, userID string) (*domain.GoogleTokens, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.GoogleTokens), args.Error(1)
+}
+
+func (m *MockUserRepository) ListExpiringGoogleTokens(ctx context.Context, before time.Time, limit int) ([]domain.GoogleTokens, error) {
+	args := m.Called(ctx, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.GoogleTokens), args.Error(1)
+}
+
+func (m *MockUserRepository) RecordGoogleTokenRefreshFailure(ctx context.Context, userID string, retryAt time.Time) error {
+	args := m.Called(ctx, userID, retryAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ClearGoogleTokens(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DeleteUser(ctx context.Context, userID string, deletedAt time.Time) (bool, error) {
+	args := m.Called(ctx, userID, deletedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+// memorySessionRepository is an in-memory domain.UserSessionRepository.
+type memorySessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]domain.UserSession
+}
+
+func newMemorySessionRepository() *memorySessionRepository {
+	return &memorySessionRepository{sessions: make(map[string]domain.UserSession)}
+}
+
+func (r *memorySessionRepository) CreateSession(_ context.Context, session *domain.UserSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *memorySessionRepository) GetSessionByID(_ context.Context, id string) (*domain.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (r *memorySessionRepository) ListActiveSessions(_ context.Context, userID string, now time.Time) ([]domain.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessions []domain.UserSession
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+func (r *memorySessionRepository) RotateSession(_ context.Context, id string, currentJTI string, newJTI string, expiresAt time.Time, usedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok || session.RevokedAt != nil || session.CurrentJTI != currentJTI {
+		return false, nil
+	}
+	session.CurrentJTI, session.ExpiresAt, session.LastUsedAt = newJTI, expiresAt, usedAt
+	r.sessions[id] = session
+	return true, nil
+}
+
+func (r *memorySessionRepository) RevokeSession(_ context.Context, id string, reason string, revokedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return false, nil
+	}
+	session.RevokedAt, session.RevokedReason = &revokedAt, reason
+	r.sessions[id] = session
+	return true, nil
+}
+
+func newSessionTestAuthService(t *testing.T, sessions domain.UserSessionRepository, denylist domain.Cache) (*authServiceImpl, *MockUserRepository) {
+	t.Helper()
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetUserByID", mock.Anything, "user123").Return(&domain.User{ID: "user123"}, nil)
+	authCfg := config.AuthConfig{
+		TokenEncryptionKey: testTokenEncryptionKey,
+		JWT: config.JWTConfig{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+	}
+	authService, err := NewAuthService(mockUserRepo, newMemoryIdentityRepository(), sessions, nil, denylist, nil, authCfg, &MockTransactionManager{})
+	if err != nil {
+		t.Fatalf("NewAuthService: %v", err)
+	}
+	return authService.(*authServiceImpl), mockUserRepo
+}
+
+func TestAuthService_RefreshToken_RotationAndReuse(t *testing.T) {
+	ctx := context.Background()
+	sessions := newMemorySessionRepository()
+	authService, _ := newSessionTestAuthService(t, sessions, newMapCache())
+
+	_, refresh1, err := authService.startSession(ctx, &domain.User{ID: "user123"}, dto.SessionClientInfo{})
+	assert.NoError(t, err)
+
+	access2, refresh2, err := authService.RefreshToken(ctx, refresh1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, refresh1, refresh2)
+	claims, err := authService.ValidateJWT(ctx, access2)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, claims.SessionID)
+	assert.NotEmpty(t, claims.ID)
+
+	_, refresh3, err := authService.RefreshToken(ctx, refresh2)
+	assert.NoError(t, err)
+
+	// Replaying a rotated token revokes the whole session
+	_, _, err = authService.RefreshToken(ctx, refresh1)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	session, _ := sessions.GetSessionByID(ctx, claims.SessionID)
+	if assert.NotNil(t, session.RevokedAt) {
+		assert.Equal(t, domain.SessionRevokedTokenReuse, session.RevokedReason)
+	}
+
+	_, _, err = authService.RefreshToken(ctx, refresh3)
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+
+	revoked, err := authService.IsTokenRevoked(ctx, claims)
+	assert.NoError(t, err)
+	assert.True(t, revoked, "access tokens of a revoked session must be rejected")
+}
+
+func TestAuthService_RefreshToken_WithoutSession(t *testing.T) {
+	authService, _ := newSessionTestAuthService(t, newMemorySessionRepository(), newMapCache())
+
+	refreshToken, err := authService.CreateJWT(context.Background(), &domain.User{ID: "user123"}, time.Hour, tokenTypeRefresh)
+	assert.NoError(t, err)
+
+	_, _, err = authService.RefreshToken(context.Background(), refreshToken)
+	assert.ErrorIs(t, err, ErrInvalidJWTToken)
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Revokes The Session", func(t *testing.T) {
+		sessions := newMemorySessionRepository()
+		authService, _ := newSessionTestAuthService(t, sessions, newMapCache())
+		accessToken, refreshToken, err := authService.startSession(ctx, &domain.User{ID: "user123"}, dto.SessionClientInfo{})
+		assert.NoError(t, err)
+		claims, err := authService.ValidateJWT(ctx, accessToken)
+		assert.NoError(t, err)
+
+		revoked, err := authService.IsTokenRevoked(ctx, claims)
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+
+		assert.NoError(t, authService.Logout(ctx, claims))
+
+		revoked, err = authService.IsTokenRevoked(ctx, claims)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+		_, _, err = authService.RefreshToken(ctx, refreshToken)
+		assert.ErrorIs(t, err, ErrSessionRevoked)
+	})
+
+	t.Run("Revokes A Token Without Session", func(t *testing.T) {
+		authService, _ := newSessionTestAuthService(t, newMemorySessionRepository(), newMapCache())
+		accessToken, err := authService.CreateJWT(ctx, &domain.User{ID: "user123"}, time.Hour, tokenTypeAccess)
+		assert.NoError(t, err)
+		claims, err := authService.ValidateJWT(ctx, accessToken)
+		assert.NoError(t, err)
+
+		assert.NoError(t, authService.Logout(ctx, claims))
+		revoked, err := authService.IsTokenRevoked(ctx, claims)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+}
+
+func TestAuthService_Sessions(t *testing.T) {
+	ctx := context.Background()
+	sessions := newMemorySessionRepository()
+	authService, _ := newSessionTestAuthService(t, sessions, newMapCache())
+	user := &domain.User{ID: "user123"}
+
+	laptopToken, _, err := authService.startSession(ctx, user, dto.SessionClientInfo{
+		UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36",
+		IPAddress: "203.0.113.7",
+	})
+	assert.NoError(t, err)
+	phoneToken, _, err := authService.startSession(ctx, user, dto.SessionClientInfo{UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) Safari/604.1"})
+	assert.NoError(t, err)
+	_, _, err = authService.startSession(ctx, &domain.User{ID: "other-user"}, dto.SessionClientInfo{})
+	assert.NoError(t, err)
+
+	laptop, err := authService.ValidateJWT(ctx, laptopToken)
+	assert.NoError(t, err)
+	phone, err := authService.ValidateJWT(ctx, phoneToken)
+	assert.NoError(t, err)
+
+	t.Run("Lists The User's Sessions", func(t *testing.T) {
+		resp, err := authService.ListSessions(ctx, "user123", laptop.SessionID)
+		assert.NoError(t, err)
+		if assert.Len(t, resp.Sessions, 2) {
+			byID := map[string]dto.SessionItem{}
+			for _, item := range resp.Sessions {
+				byID[item.ID] = item
+			}
+			assert.Equal(t, "Chrome on macOS", byID[laptop.SessionID].Device)
+			assert.Equal(t, "203.0.113.7", byID[laptop.SessionID].IPAddress)
+			assert.True(t, byID[laptop.SessionID].Current)
+			assert.Equal(t, "Safari on iOS", byID[phone.SessionID].Device)
+			assert.False(t, byID[phone.SessionID].Current)
+		}
+	})
+
+	t.Run("Cannot Revoke Another User's Session", func(t *testing.T) {
+		err := authService.RevokeSession(ctx, "other-user", laptop.SessionID)
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Revokes Every Other Session", func(t *testing.T) {
+		revoked, err := authService.RevokeOtherSessions(ctx, "user123", laptop.SessionID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, revoked)
+
+		isRevoked, err := authService.IsTokenRevoked(ctx, phone)
+		assert.NoError(t, err)
+		assert.True(t, isRevoked)
+		isRevoked, err = authService.IsTokenRevoked(ctx, laptop)
+		assert.NoError(t, err)
+		assert.False(t, isRevoked)
+
+		resp, err := authService.ListSessions(ctx, "user123", laptop.SessionID)
+		assert.NoError(t, err)
+		assert.Len(t, resp.Sessions, 1)
+	})
+
+	t.Run("Revokes One Session", func(t *testing.T) {
+		assert.NoError(t, authService.RevokeSession(ctx, "user123", laptop.SessionID))
+		assert.ErrorIs(t, authService.RevokeSession(ctx, "user123", laptop.SessionID), domain.ErrNotFound)
+	})
+}
+
+func TestDescribeDevice(t *testing.T) {
+	tests := map[string]string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36 Edg/125.0.0.0": "Edge on Windows",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Mobile Safari/537.36":         "Chrome on Android",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:126.0) Gecko/20100101 Firefox/126.0":                                                        "Firefox on Linux",
+		"curl/8.5.0": "curl",
+		"":           unknownDevice,
+	}
+	for userAgent, expected := range tests {
+		assert.Equal(t, expected, describeDevice(userAgent), userAgent)
+	}
+}
+
+func TestAuthService_IsTokenRevoked_DenylistUnavailable(t *testing.T) {
+	ctx := context.Background()
+	sessions := newMemorySessionRepository()
+	denylist := &ManualMockCache{
+		GetFunc: func(context.Context, string) (string, error) { return "", errors.New("redis is down") },
+		SetFunc: func(context.Context, string, string, time.Duration) error { return errors.New("redis is down") },
+	}
+	authService, _ := newSessionTestAuthService(t, sessions, denylist)
+	accessToken, _, err := authService.startSession(ctx, &domain.User{ID: "user123"}, dto.SessionClientInfo{})
+	assert.NoError(t, err)
+	claims, err := authService.ValidateJWT(ctx, accessToken)
+	assert.NoError(t, err)
+
+	revoked, err := authService.IsTokenRevoked(ctx, claims)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	// A revoked session is caught by the session lookup
+	_, err = sessions.RevokeSession(ctx, claims.SessionID, domain.SessionRevokedLogout, time.Now())
+	assert.NoError(t, err)
+	revoked, err = authService.IsTokenRevoked(ctx, claims)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestAuthService_Logout_DenylistWriteFails(t *testing.T) {
+	ctx := context.Background()
+	sessions := newMemorySessionRepository()
+	denylist := newMapCache()
+	setDown := func(context.Context, string, string, time.Duration) error { return errors.New("redis is down") }
+	failing := &ManualMockCache{GetFunc: denylist.Get, SetFunc: setDown}
+	authService, _ := newSessionTestAuthService(t, sessions, failing)
+
+	t.Run("Session", func(t *testing.T) {
+		accessToken, _, err := authService.startSession(ctx, &domain.User{ID: "user123"}, dto.SessionClientInfo{})
+		require.NoError(t, err)
+		claims, err := authService.ValidateJWT(ctx, accessToken)
+		require.NoError(t, err)
+
+		// Reads still miss the denylist, so a logout that could not deny the session must fail
+		// instead of leaving its access tokens valid.
+		err = authService.Logout(ctx, claims)
+		var domainErr *domain.DomainError
+		if assert.ErrorAs(t, err, &domainErr) {
+			assert.Equal(t, domain.CodeInternal, domainErr.Code)
+		}
+		session, _ := sessions.GetSessionByID(ctx, claims.SessionID)
+		assert.Nil(t, session.RevokedAt, "the session stays active so the logout can be retried")
+
+		failing.SetFunc = denylist.Set
+		require.NoError(t, authService.Logout(ctx, claims))
+		revoked, err := authService.IsTokenRevoked(ctx, claims)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("Token Without Session", func(t *testing.T) {
+		failing.SetFunc = setDown
+		accessToken, err := authService.CreateJWT(ctx, &domain.User{ID: "user123"}, time.Hour, tokenTypeAccess)
+		require.NoError(t, err)
+		claims, err := authService.ValidateJWT(ctx, accessToken)
+		require.NoError(t, err)
+
+		assert.Error(t, authService.Logout(ctx, claims))
+		revoked, err := authService.IsTokenRevoked(ctx, claims)
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+}
+
 func TestAuthService_RefreshToken_UserNotFound(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	authCfg := config.AuthConfig{
+		TokenEncryptionKey: testTokenEncryptionKey,
 		JWT: config.JWTConfig{
-			SecretKey:       "testsecretkeydontuseinproduction32bytes!",
 			AccessTokenTTL:  15 * time.Minute,
 			RefreshTokenTTL: 7 * 24 * time.Hour,
 		},
 	}
 
-	authService, err := NewAuthService(mockUserRepo, authCfg, &MockTransactionManager{})
+	authService, err := NewAuthService(mockUserRepo, nil, nil, nil, nil, nil, authCfg, &MockTransactionManager{})
 	assert.NoError(t, err)
 
 	// Create a valid refresh token string (for testing purposes)
@@ -81,13 +437,13 @@ func TestAuthService_RefreshToken_UserNotFound(t *testing.T) {
 func TestAuthService_RefreshToken_RepoError(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	authCfg := config.AuthConfig{
+		TokenEncryptionKey: testTokenEncryptionKey,
 		JWT: config.JWTConfig{
-			SecretKey:       "testsecretkeydontuseinproduction32bytes!",
 			AccessTokenTTL:  15 * time.Minute,
 			RefreshTokenTTL: 7 * 24 * time.Hour,
 		},
 	}
-	authService, err := NewAuthService(mockUserRepo, authCfg, &MockTransactionManager{})
+	authService, err := NewAuthService(mockUserRepo, nil, nil, nil, nil, nil, authCfg, &MockTransactionManager{})
 	assert.NoError(t, err)
 
 	dummyUser := &domain.User{ID: "user123"}
@@ -108,52 +464,5 @@ func TestAuthService_RefreshToken_RepoError(t *testing.T) {
 	}
 }
 
-func TestAuthService_HandleGoogleCallback_CreateUser_RepoError(t *testing.T) {
-	mockUserRepo := new(MockUserRepository)
-	authCfg := config.AuthConfig{
-		JWT: config.JWTConfig{
-			SecretKey: "testsecretkeydontuseinproduction32bytes!", AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: 7 * 24 * time.Hour,
-		},
-		GoogleOAuth: config.GoogleOAuthConfig{ /* fields can be dummy for this test if not directly used before repo call */ },
-	}
-	// Note: NewAuthService also creates oauth2Config. For this test, we're focused on repo interaction.
-	// A more complete test might mock http calls for oauth2.Exchange and client.Get.
-
-	_, err := NewAuthService(mockUserRepo, authCfg, &MockTransactionManager{})
-	assert.NoError(t, err)
-
-	// Mock GetUserByGoogleID to return (nil, nil) -> user not found
-	mockUserRepo.On("GetUserByGoogleID", mock.Anything, "googleTestID").Return(nil, nil)
-
-	// Mock CreateUser to return an error
-	expectedRepoError := errors.New("failed to create user in DB")
-	mockUserRepo.On("CreateUser", mock.Anything, mock.AnythingOfType("*domain.User")).Return(expectedRepoError)
-
-	// Simulate a successful Google OAuth exchange part (token & user info)
-	// This part is complex to fully mock without also mocking HTTP.
-	// We are focusing on the error from CreateUser.
-	// To simplify, we'd need to bypass the actual Google API calls in a real unit test for this specific case,
-	// or make HandleGoogleCallback more modular to test parts.
-	// For this example, we assume the flow reaches CreateUser.
-	// A true unit test for HandleGoogleCallback would require more extensive mocking (e.g., http client for Google API calls).
-
-	// This test will be more conceptual for now as fully testing HandleGoogleCallback as a unit is complex
-	// due to its external HTTP calls. The main goal here is to illustrate testing the error conversion from repo.
-
-	// If we could directly call the part after getting Google UserInfo:
-	// Assume userInfo is populated
-	// ...
-	// domainUser := constructDomainUserFromGoogleInfo()
-	// err := authService.userRepo.CreateUser(ctx, domainUser)
-	// if err != nil { /* THIS IS WHAT WE WANT TO TEST */
-	//    // service should return domain.NewInternalError("...", err)
-	// }
-
-	// Due to the complexity of mocking the full HandleGoogleCallback, this test case will be omitted for now.
-	// The error wrapping for CreateUser and UpdateUser calls within HandleGoogleCallback was already
-	// updated to domain.NewInternalError in the previous step.
-	t.Log("Skipping full HandleGoogleCallback test due to complexity of mocking external HTTP calls in this context.")
-}
-
 // TODO: Add more tests for other scenarios in AuthService,
-// e.g., successful token refresh, successful Google callback, JWT creation/validation errors.
+// e.g., successful token refresh, JWT creation/validation errors.
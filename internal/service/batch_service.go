@@ -218,6 +218,7 @@ func (s *batchService) generateAndSaveQuizzesInTransaction(ctx context.Context)
 					Keywords:      generatedQuiz.Keywords,
 					Difficulty:    domain.DifficultyToInt(generatedQuiz.Difficulty),
 					SubCategoryID: subCategoryID,
+					Status:        domain.QuizStatusDraft, // Generated quizzes are served only once a reviewer approves them
 					// CreatedAt and UpdatedAt will be set by the repository
 				}
 
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"quiz-byte/internal/cache"
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/logger"
+	"quiz-byte/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// CheckJobService grades answers in the background with a fixed pool of workers.
+// Job state is kept in domain.Cache so any API instance can answer a poll.
+type CheckJobService interface {
+	// Submit queues req for grading on behalf of userID ("" for anonymous users) and returns the pending job.
+	// It fails with GRADING_QUEUE_FULL when every worker is busy and the queue is full.
+	Submit(ctx context.Context, req *dto.CheckAnswerRequest, userID string) (*dto.CheckJobResponse, error)
+	// Get returns the job. Jobs submitted by a signed-in user are only visible to that user.
+	Get(ctx context.Context, jobID string, userID string) (*dto.CheckJobResponse, error)
+	// Shutdown stops accepting jobs and waits for queued ones to finish. When ctx is done first,
+	// running evaluations are canceled and ctx.Err() is returned.
+	Shutdown(ctx context.Context) error
+}
+
+// checkJobRecord is the cached job state; it carries what the response leaves out.
+type checkJobRecord struct {
+	dto.CheckJobResponse
+	UserID     string `json:"user_id,omitempty"`
+	UserAnswer string `json:"user_answer"`
+
+	// resultToken is handed out in the submit response only and never cached, so polling
+	// the job ID does not reveal it.
+	resultToken string
+}
+
+type checkJobService struct {
+	quizService      QuizService
+	attempts         AttemptRecorder
+	anonymousResults AnonymousResultCacheService
+	cache            domain.Cache
+	resultTTL        time.Duration
+
+	queue      chan *checkJobRecord
+	mu         sync.RWMutex // Guards closed against concurrent sends on queue
+	closed     bool
+	workers    sync.WaitGroup
+	workCtx    context.Context
+	cancelWork context.CancelFunc
+}
+
+// NewCheckJobService creates the service and starts settings.Workers workers.
+// attempts and anonymousResults may be nil, in which case attempts are not recorded or cached.
+func NewCheckJobService(
+	quizService QuizService,
+	attempts AttemptRecorder,
+	anonymousResults AnonymousResultCacheService,
+	cache domain.Cache,
+	settings config.CheckJobsConfig,
+) CheckJobService {
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	s := &checkJobService{
+		quizService:      quizService,
+		attempts:         attempts,
+		anonymousResults: anonymousResults,
+		cache:            cache,
+		resultTTL:        settings.ResultTTL,
+		queue:            make(chan *checkJobRecord, max(settings.QueueSize, 0)),
+		workCtx:          workCtx,
+		cancelWork:       cancelWork,
+	}
+	for i := 0; i < max(settings.Workers, 1); i++ {
+		s.workers.Add(1)
+		go s.work()
+	}
+	return s
+}
+
+func (s *checkJobService) jobKey(jobID string) string {
+	return cache.GenerateCacheKey("check", "job", jobID)
+}
+
+// Submit implements CheckJobService.
+func (s *checkJobService) Submit(ctx context.Context, req *dto.CheckAnswerRequest, userID string) (*dto.CheckJobResponse, error) {
+	record := &checkJobRecord{
+		CheckJobResponse: dto.CheckJobResponse{
+			JobID:       util.NewULID(),
+			Status:      dto.CheckJobStatusPending,
+			QuizID:      req.QuizID,
+			SubmittedAt: time.Now(),
+		},
+		UserID:     userID,
+		UserAnswer: req.UserAnswer,
+	}
+	if userID == "" && s.anonymousResults != nil {
+		token, err := NewResultToken()
+		if err != nil {
+			logger.Get().Error("Failed to create result token for grading job", zap.String("quiz_id", req.QuizID), zap.Error(err))
+		}
+		record.resultToken = token
+	}
+	// Saved before queueing so a worker never finishes a job that cannot be polled yet.
+	if err := s.save(ctx, record); err != nil {
+		return nil, err
+	}
+
+	response := record.CheckJobResponse // Copied before a worker starts updating the record
+	response.ResultToken = record.resultToken
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.closed {
+		select {
+		case s.queue <- record:
+			logger.Get().Info("Grading job queued", zap.String("job_id", response.JobID), zap.String("quiz_id", req.QuizID))
+			return &response, nil
+		default:
+		}
+	}
+
+	logger.Get().Warn("Grading queue is full, rejecting job", zap.String("quiz_id", req.QuizID), zap.Int("queue_size", cap(s.queue)))
+	if err := s.cache.Delete(ctx, s.jobKey(response.JobID)); err != nil {
+		logger.Get().Warn("Failed to delete rejected grading job", zap.String("job_id", response.JobID), zap.Error(err))
+	}
+	return nil, domain.NewGradingQueueFullError()
+}
+
+// Get implements CheckJobService.
+func (s *checkJobService) Get(ctx context.Context, jobID string, userID string) (*dto.CheckJobResponse, error) {
+	data, err := s.cache.Get(ctx, s.jobKey(jobID))
+	if err != nil {
+		if errors.Is(err, domain.ErrCacheMiss) {
+			return nil, errCheckJobNotFound(jobID)
+		}
+		return nil, domain.NewInternalError("Failed to load grading job", err)
+	}
+
+	var record checkJobRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, domain.NewInternalError("Failed to decode grading job", err)
+	}
+	// Reported as missing rather than forbidden so job IDs of other users cannot be probed.
+	if record.UserID != "" && record.UserID != userID {
+		return nil, errCheckJobNotFound(jobID)
+	}
+	return &record.CheckJobResponse, nil
+}
+
+// Shutdown implements CheckJobService.
+func (s *checkJobService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.cancelWork()
+		return nil
+	case <-ctx.Done():
+		s.cancelWork()
+		logger.Get().Warn("Grading workers did not finish before shutdown deadline", zap.Int("queued_jobs", len(s.queue)))
+		return ctx.Err()
+	}
+}
+
+func (s *checkJobService) work() {
+	defer s.workers.Done()
+	for record := range s.queue {
+		s.run(record)
+	}
+}
+
+// run grades one job. It records the attempt (or caches the anonymous result) like the
+// synchronous endpoint does, then stores the final job state.
+func (s *checkJobService) run(record *checkJobRecord) {
+	ctx := s.workCtx
+	if ctx.Err() != nil {
+		record.Status = dto.CheckJobStatusFailed
+		record.Error = &dto.CheckJobError{Code: string(domain.CodeInternal), Message: "Grading was interrupted by a server shutdown"}
+		s.finish(record)
+		return
+	}
+
+	result, err := s.quizService.CheckAnswer(ctx, &dto.CheckAnswerRequest{QuizID: record.QuizID, UserAnswer: record.UserAnswer})
+	if err != nil {
+		logger.Get().Error("Grading job failed", zap.String("job_id", record.JobID), zap.String("quiz_id", record.QuizID), zap.Error(err))
+		record.Status = dto.CheckJobStatusFailed
+		record.Error = checkJobErrorFrom(err)
+		s.finish(record)
+		return
+	}
+
+	if record.UserID != "" && s.attempts != nil {
+		// Keyed by job so a job never adds two attempts.
+		if errRecord := s.attempts.RecordAttempt(ctx, "check-job:"+record.JobID, record.UserID, record.QuizID, record.UserAnswer, AttemptAnswer(result)); errRecord != nil {
+			logger.Get().Error("Failed to record quiz attempt for grading job",
+				zap.String("job_id", record.JobID),
+				zap.String("user_id", record.UserID),
+				zap.Error(errRecord))
+		}
+	} else if record.resultToken != "" {
+		s.cacheAnonymousResult(ctx, record, result)
+	}
+
+	record.Status = dto.CheckJobStatusDone
+	record.Result = result
+	s.finish(record)
+}
+
+// cacheAnonymousResult keeps the result for a later claim with the job's result token.
+func (s *checkJobService) cacheAnonymousResult(ctx context.Context, record *checkJobRecord, result *dto.CheckAnswerResponse) {
+	err := s.anonymousResults.Put(ctx, record.resultToken, &AnonymousResult{
+		QuizID:     record.QuizID,
+		UserAnswer: record.UserAnswer,
+		Result:     result,
+		AnsweredAt: time.Now(),
+	})
+	if err != nil {
+		logger.Get().Error("Failed to cache anonymous result for grading job", zap.String("job_id", record.JobID), zap.Error(err))
+	}
+}
+
+func (s *checkJobService) finish(record *checkJobRecord) {
+	completedAt := time.Now()
+	record.CompletedAt = &completedAt
+	// Stored even when shutdown canceled the work, so pollers see the outcome.
+	if err := s.save(context.WithoutCancel(s.workCtx), record); err != nil {
+		logger.Get().Error("Failed to store grading job result", zap.String("job_id", record.JobID), zap.Error(err))
+		return
+	}
+	logger.Get().Info("Grading job finished", zap.String("job_id", record.JobID), zap.String("status", record.Status))
+}
+
+func (s *checkJobService) save(ctx context.Context, record *checkJobRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return domain.NewInternalError("Failed to encode grading job", err)
+	}
+	if err := s.cache.Set(ctx, s.jobKey(record.JobID), string(data), s.resultTTL); err != nil {
+		return domain.NewInternalError(fmt.Sprintf("Failed to store grading job %s", record.JobID), err)
+	}
+	return nil
+}
+
+func errCheckJobNotFound(jobID string) error {
+	return domain.NewError(domain.CodeNotFound, "Grading job not found", domain.ErrNotFound).WithContext("job_id", jobID)
+}
+
+// checkJobErrorFrom keeps the code of domain errors so clients can handle a failed job like a failed request.
+func checkJobErrorFrom(err error) *dto.CheckJobError {
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) && domainErr.Code != domain.CodeInternal {
+		return &dto.CheckJobError{Code: string(domainErr.Code), Message: domainErr.Message}
+	}
+	return &dto.CheckJobError{Code: string(domain.CodeInternal), Message: "Internal server error"}
+}
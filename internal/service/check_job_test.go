@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkAnswerStub is a QuizService whose CheckAnswer is replaced by fn; other methods are not used by the job service.
+type checkAnswerStub struct {
+	QuizService
+	fn func(ctx context.Context, req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error)
+}
+
+func (s *checkAnswerStub) CheckAnswer(ctx context.Context, req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
+	return s.fn(ctx, req)
+}
+
+// newMapCache returns a ManualMockCache backed by a map.
+func newMapCache() *ManualMockCache {
+	var mu sync.Mutex
+	data := make(map[string]string)
+	return &ManualMockCache{
+		GetFunc: func(_ context.Context, key string) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			value, ok := data[key]
+			if !ok {
+				return "", domain.ErrCacheMiss
+			}
+			return value, nil
+		},
+		SetFunc: func(_ context.Context, key string, value string, _ time.Duration) error {
+			mu.Lock()
+			defer mu.Unlock()
+			data[key] = value
+			return nil
+		},
+		DeleteFunc: func(_ context.Context, key string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			delete(data, key)
+			return nil
+		},
+		GetDelFunc: func(_ context.Context, key string) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			value, ok := data[key]
+			if !ok {
+				return "", domain.ErrCacheMiss
+			}
+			delete(data, key)
+			return value, nil
+		},
+	}
+}
+
+func waitForJob(t *testing.T, svc CheckJobService, jobID, userID string) *dto.CheckJobResponse {
+	t.Helper()
+	var job *dto.CheckJobResponse
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = svc.Get(context.Background(), jobID, userID)
+		require.NoError(t, err)
+		return job.Status != dto.CheckJobStatusPending
+	}, time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestCheckJobService(t *testing.T) {
+	req := &dto.CheckAnswerRequest{QuizID: "quiz-1", UserAnswer: "An answer"}
+	settings := config.CheckJobsConfig{Workers: 1, QueueSize: 1, ResultTTL: time.Hour}
+
+	t.Run("Grades In The Background And Caches Anonymous Result", func(t *testing.T) {
+		cache := newMapCache()
+		anonymousResults := NewAnonymousResultCacheService(cache, time.Hour, nil)
+		result := &dto.CheckAnswerResponse{Score: 0.9, Explanation: "Good", ScoredBy: domain.ScoredByLLM}
+		stub := &checkAnswerStub{fn: func(_ context.Context, got *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
+			assert.Equal(t, req, got)
+			return result, nil
+		}}
+		svc := NewCheckJobService(stub, nil, anonymousResults, cache, settings)
+		defer svc.Shutdown(context.Background())
+
+		submitted, err := svc.Submit(context.Background(), req, "")
+		require.NoError(t, err)
+		assert.Equal(t, dto.CheckJobStatusPending, submitted.Status)
+		assert.NotEmpty(t, submitted.JobID)
+		assert.NotEmpty(t, submitted.ResultToken)
+
+		job := waitForJob(t, svc, submitted.JobID, "")
+		assert.Equal(t, dto.CheckJobStatusDone, job.Status)
+		require.NotNil(t, job.Result)
+		assert.Empty(t, job.ResultToken, "Polling the job must not reveal the result token")
+		assert.Empty(t, job.Result.ResultToken, "Polling the job must not reveal the result token")
+		assert.Equal(t, result.Score, job.Result.Score)
+		assert.Nil(t, job.Error)
+		assert.NotNil(t, job.CompletedAt)
+
+		cached, err := anonymousResults.Get(context.Background(), submitted.ResultToken)
+		require.NoError(t, err)
+		assert.Equal(t, req.QuizID, cached.QuizID)
+		assert.Equal(t, req.UserAnswer, cached.UserAnswer)
+		assert.Equal(t, result, cached.Result)
+	})
+
+	t.Run("Failed Evaluation Keeps The Error Code", func(t *testing.T) {
+		stub := &checkAnswerStub{fn: func(context.Context, *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
+			return nil, domain.NewLLMServiceError(errors.New("model offline"))
+		}}
+		svc := NewCheckJobService(stub, nil, nil, newMapCache(), settings)
+		defer svc.Shutdown(context.Background())
+
+		job, err := svc.Submit(context.Background(), req, "user-1")
+		require.NoError(t, err)
+
+		job = waitForJob(t, svc, job.JobID, "user-1")
+		assert.Equal(t, dto.CheckJobStatusFailed, job.Status)
+		assert.Nil(t, job.Result)
+		assert.Equal(t, &dto.CheckJobError{Code: string(domain.CodeLLMServiceError), Message: "Failed to process with LLM service"}, job.Error)
+	})
+
+	t.Run("Jobs Of A User Are Hidden From Others", func(t *testing.T) {
+		stub := &checkAnswerStub{fn: func(context.Context, *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
+			return &dto.CheckAnswerResponse{}, nil
+		}}
+		svc := NewCheckJobService(stub, nil, nil, newMapCache(), settings)
+		defer svc.Shutdown(context.Background())
+
+		job, err := svc.Submit(context.Background(), req, "user-1")
+		require.NoError(t, err)
+
+		_, err = svc.Get(context.Background(), job.JobID, "user-2")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = svc.Get(context.Background(), job.JobID, "")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+		_, err = svc.Get(context.Background(), "unknown", "user-1")
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("Full Queue Rejects And Shutdown Drains", func(t *testing.T) {
+		started := make(chan struct{}, 2)
+		proceed := make(chan struct{})
+		var graded []string
+		var mu sync.Mutex
+		stub := &checkAnswerStub{fn: func(_ context.Context, got *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
+			started <- struct{}{}
+			<-proceed
+			mu.Lock()
+			graded = append(graded, got.UserAnswer)
+			mu.Unlock()
+			return &dto.CheckAnswerResponse{}, nil
+		}}
+		cache := newMapCache()
+		svc := NewCheckJobService(stub, nil, nil, cache, settings)
+
+		running, err := svc.Submit(context.Background(), &dto.CheckAnswerRequest{QuizID: "quiz-1", UserAnswer: "first"}, "")
+		require.NoError(t, err)
+		<-started // The only worker is busy
+		queued, err := svc.Submit(context.Background(), &dto.CheckAnswerRequest{QuizID: "quiz-1", UserAnswer: "second"}, "")
+		require.NoError(t, err)
+
+		_, err = svc.Submit(context.Background(), &dto.CheckAnswerRequest{QuizID: "quiz-1", UserAnswer: "third"}, "")
+		assert.ErrorIs(t, err, domain.ErrGradingQueueFull)
+
+		close(proceed)
+		require.NoError(t, svc.Shutdown(context.Background()))
+		assert.Equal(t, []string{"first", "second"}, graded)
+		for _, jobID := range []string{running.JobID, queued.JobID} {
+			job, err := svc.Get(context.Background(), jobID, "")
+			require.NoError(t, err)
+			assert.Equal(t, dto.CheckJobStatusDone, job.Status)
+		}
+
+		_, err = svc.Submit(context.Background(), req, "")
+		assert.ErrorIs(t, err, domain.ErrGradingQueueFull)
+	})
+}
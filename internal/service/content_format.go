@@ -0,0 +1,296 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats content is imported and exported in.
+const (
+	ContentFormatJSON = "json"
+	ContentFormatCSV  = "csv"
+	ContentFormatYAML = "yaml"
+)
+
+// ContentFormatFromFileName returns the content format of a file by its extension, or "" for other files.
+func ContentFormatFromFileName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return ContentFormatJSON
+	case ".csv":
+		return ContentFormatCSV
+	case ".yaml", ".yml":
+		return ContentFormatYAML
+	}
+	return ""
+}
+
+// contentCSVHeader lists the CSV columns. A CSV has one row per quiz, plus a row for every category
+// without subcategories and every subcategory without quizzes. List columns hold JSON arrays, e.g.
+// ["first answer","second answer"], and score_evaluations a JSON array of score evaluation objects.
+// The evaluation columns are empty for a quiz without a rubric.
+var contentCSVHeader = []string{
+	"category", "category_description", "sub_category", "sub_category_description",
+	"question", "model_answers", "keywords", "difficulty", "status",
+	"minimum_keywords", "required_topics", "score_ranges", "sample_answers", "rubric_details", "score_evaluations",
+}
+
+func checkContentFormat(format string) error {
+	switch format {
+	case ContentFormatJSON, ContentFormatCSV, ContentFormatYAML:
+		return nil
+	}
+	return domain.NewValidationError(fmt.Sprintf("unknown content format %q; use json, csv or yaml", format))
+}
+
+func encodeContentBundle(bundle *dto.ContentBundle, format string) ([]byte, error) {
+	switch format {
+	case ContentFormatJSON:
+		return json.MarshalIndent(bundle, "", "  ")
+	case ContentFormatYAML:
+		return yaml.Marshal(bundle)
+	case ContentFormatCSV:
+		return encodeContentCSV(bundle)
+	}
+	return nil, checkContentFormat(format)
+}
+
+// decodeContentBundle parses the content. Problems with single CSV rows are returned as row errors
+// so they are reported together; a file that cannot be parsed at all is a validation error.
+func decodeContentBundle(data []byte, format string) (*dto.ContentBundle, []dto.ImportRowError, error) {
+	var bundle dto.ContentBundle
+	switch format {
+	case ContentFormatJSON:
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			return nil, nil, domain.NewValidationError(fmt.Sprintf("invalid JSON: %v", err))
+		}
+	case ContentFormatYAML:
+		if err := yaml.Unmarshal(data, &bundle); err != nil {
+			return nil, nil, domain.NewValidationError(fmt.Sprintf("invalid YAML: %v", err))
+		}
+	case ContentFormatCSV:
+		return decodeContentCSV(data)
+	default:
+		return nil, nil, checkContentFormat(format)
+	}
+	return &bundle, nil, nil
+}
+
+func encodeContentCSV(bundle *dto.ContentBundle) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(contentCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, category := range bundle.Categories {
+		if len(category.SubCategories) == 0 {
+			if err := w.Write([]string{category.Name, category.Description, "", "", "", "", "", "", "", "", "", "", "", "", ""}); err != nil {
+				return nil, err
+			}
+		}
+		for _, subCategory := range category.SubCategories {
+			prefix := []string{category.Name, category.Description, subCategory.Name, subCategory.Description}
+			if len(subCategory.Quizzes) == 0 {
+				if err := w.Write(append(prefix, "", "", "", "", "", "", "", "", "", "", "")); err != nil {
+					return nil, err
+				}
+			}
+			for _, quiz := range subCategory.Quizzes {
+				record := append(append([]string{}, prefix...),
+					quiz.Question, csvList(quiz.ModelAnswers), csvList(quiz.Keywords), quiz.Difficulty, quiz.Status)
+				if evaluation := quiz.Evaluation; evaluation != nil {
+					scoreEvaluations, err := json.Marshal(evaluation.ScoreEvaluations)
+					if err != nil {
+						return nil, err
+					}
+					record = append(record, strconv.Itoa(evaluation.MinimumKeywords), csvList(evaluation.RequiredTopics),
+						csvList(evaluation.ScoreRanges), csvList(evaluation.SampleAnswers), evaluation.RubricDetails, string(scoreEvaluations))
+				} else {
+					record = append(record, "", "", "", "", "", "")
+				}
+				if err := w.Write(record); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func decodeContentCSV(data []byte) (*dto.ContentBundle, []dto.ImportRowError, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff")))) // Spreadsheets may add a BOM
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, domain.NewValidationError(fmt.Sprintf("invalid CSV header: %v", err))
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(contentCSVHeader, name) {
+			return nil, nil, domain.NewValidationError(fmt.Sprintf("unknown CSV column %q", name))
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["category"]; !ok {
+		return nil, nil, domain.NewValidationError("the CSV has no category column")
+	}
+
+	bundle := &dto.ContentBundle{}
+	var rowErrs []dto.ImportRowError
+	categories := map[string]int{}
+	subCategories := map[[2]string]int{}
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, domain.NewValidationError(fmt.Sprintf("invalid CSV: %v", err))
+		}
+		line, _ := r.FieldPos(0)
+		row := fmt.Sprintf("line %d", line)
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		fail := func(format string, args ...interface{}) {
+			rowErrs = append(rowErrs, dto.ImportRowError{Row: row, Message: fmt.Sprintf(format, args...)})
+		}
+
+		categoryName, subCategoryName, question := cell("category"), cell("sub_category"), cell("question")
+		if categoryName == "" {
+			fail("category is required")
+			continue
+		}
+		if subCategoryName == "" && question != "" {
+			fail("sub_category is required for a quiz")
+			continue
+		}
+
+		ci, ok := categories[categoryName]
+		if !ok {
+			ci = len(bundle.Categories)
+			categories[categoryName] = ci
+			bundle.Categories = append(bundle.Categories, dto.BundleCategory{Name: categoryName, Line: line})
+		}
+		category := &bundle.Categories[ci]
+		if !mergeDescription(&category.Description, cell("category_description")) {
+			fail("category %q has a different description than on line %d", categoryName, category.Line)
+		}
+		if subCategoryName == "" {
+			continue
+		}
+
+		si, ok := subCategories[[2]string{categoryName, subCategoryName}]
+		if !ok {
+			si = len(category.SubCategories)
+			subCategories[[2]string{categoryName, subCategoryName}] = si
+			category.SubCategories = append(category.SubCategories, dto.BundleSubCategory{Name: subCategoryName, Line: line})
+		}
+		subCategory := &category.SubCategories[si]
+		if !mergeDescription(&subCategory.Description, cell("sub_category_description")) {
+			fail("subcategory %q has a different description than on line %d", subCategoryName, subCategory.Line)
+		}
+		if question == "" {
+			continue
+		}
+
+		quiz := dto.BundleQuiz{Question: question, Difficulty: cell("difficulty"), Status: cell("status"), Line: line}
+		var listErr error
+		list := func(name string) []string {
+			items, err := parseCSVList(cell(name))
+			if err != nil && listErr == nil {
+				listErr = fmt.Errorf("%s must be a JSON array of strings", name)
+			}
+			return items
+		}
+		quiz.ModelAnswers, quiz.Keywords = list("model_answers"), list("keywords")
+		if hasEvaluation(cell) {
+			evaluation := &dto.QuizRubricRequest{
+				RequiredTopics: list("required_topics"),
+				ScoreRanges:    list("score_ranges"),
+				SampleAnswers:  list("sample_answers"),
+				RubricDetails:  cell("rubric_details"),
+			}
+			if value := cell("minimum_keywords"); value != "" {
+				if evaluation.MinimumKeywords, err = strconv.Atoi(value); err != nil {
+					fail("minimum_keywords must be a number")
+					continue
+				}
+			}
+			if value := cell("score_evaluations"); value != "" {
+				if err := json.Unmarshal([]byte(value), &evaluation.ScoreEvaluations); err != nil {
+					fail("score_evaluations must be a JSON array of objects with score_range, sample_answers and explanation")
+					continue
+				}
+			}
+			quiz.Evaluation = evaluation
+		}
+		if listErr != nil {
+			fail("%v", listErr)
+			continue
+		}
+		subCategory.Quizzes = append(subCategory.Quizzes, quiz)
+	}
+	return bundle, rowErrs, nil
+}
+
+// csvList encodes a list column; an empty list is an empty cell.
+func csvList(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(items) // Marshaling strings cannot fail
+	return string(data)
+}
+
+func parseCSVList(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var items []string
+	if err := json.Unmarshal([]byte(value), &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// hasEvaluation reports whether any evaluation column of the row is filled in.
+func hasEvaluation(cell func(string) string) bool {
+	for _, name := range contentCSVHeader[9:] {
+		if cell(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeDescription takes the description of a category or subcategory repeated on a CSV row. It reports
+// false when the row gives a different one than an earlier row.
+func mergeDescription(description *string, value string) bool {
+	if value == "" || value == *description {
+		return true
+	}
+	if *description == "" {
+		*description = value
+		return true
+	}
+	return false
+}
@@ -0,0 +1,717 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// ContentService lets admins manage categories, subcategories, quizzes and their grading rubrics.
+// Deletes are soft; a category or subcategory can only be deleted once it is empty.
+// Every edit of a quiz is kept as a revision, which can be compared with others and rolled back to.
+// Every change invalidates the cached data it affects.
+type ContentService interface {
+	ListCategories(ctx context.Context) (*dto.CategoryListResponse, error)
+	CreateCategory(ctx context.Context, req *dto.CategoryRequest) (*dto.CategoryResponse, error)
+	UpdateCategory(ctx context.Context, id string, req *dto.CategoryRequest) (*dto.CategoryResponse, error)
+	DeleteCategory(ctx context.Context, id string) error
+
+	ListSubCategories(ctx context.Context, categoryID string) (*dto.SubCategoryListResponse, error)
+	CreateSubCategory(ctx context.Context, categoryID string, req *dto.SubCategoryRequest) (*dto.SubCategoryResponse, error)
+	UpdateSubCategory(ctx context.Context, id string, req *dto.SubCategoryRequest) (*dto.SubCategoryResponse, error)
+	DeleteSubCategory(ctx context.Context, id string) error
+
+	ListQuizzes(ctx context.Context, subCategoryID string) (*dto.QuizDetailListResponse, error)
+	GetQuiz(ctx context.Context, id string) (*dto.QuizDetailResponse, error)
+	CreateQuiz(ctx context.Context, req *dto.QuizRequest) (*dto.QuizDetailResponse, error)
+	// UpdateQuiz saves the edit as a new revision by author.
+	UpdateQuiz(ctx context.Context, id string, req *dto.QuizRequest, author string) (*dto.QuizDetailResponse, error)
+	// UpdateQuizWith is UpdateQuiz that also runs within the edit's transaction, before the edit is saved,
+	// e.g. to change the quiz's status along with it. Nothing is saved when also fails.
+	UpdateQuizWith(ctx context.Context, id string, req *dto.QuizRequest, author string, also func(ctx context.Context, quiz *domain.Quiz) error) (*dto.QuizDetailResponse, error)
+	DeleteQuiz(ctx context.Context, id string) error
+
+	ListQuizRevisions(ctx context.Context, quizID string) (*dto.QuizRevisionListResponse, error)
+	// DiffQuizRevision lists the fields changed from revision against to revision. An against of 0
+	// means the revision before it.
+	DiffQuizRevision(ctx context.Context, quizID string, revision int, against int) (*dto.QuizRevisionDiffResponse, error)
+	// RollbackQuiz restores the content of a revision, saved as a new revision by author.
+	RollbackQuiz(ctx context.Context, quizID string, revision int, author string) (*dto.QuizDetailResponse, error)
+
+	GetQuizRubric(ctx context.Context, quizID string) (*dto.QuizRubricResponse, error)
+	// PutQuizRubric creates the rubric of a quiz or replaces the existing one.
+	PutQuizRubric(ctx context.Context, quizID string, req *dto.QuizRubricRequest) (*dto.QuizRubricResponse, error)
+	DeleteQuizRubric(ctx context.Context, quizID string) error
+
+	// ExportContent returns every category, subcategory, quiz and rubric as a ContentBundle in format.
+	ExportContent(ctx context.Context, format string) ([]byte, error)
+	// ImportContent upserts the ContentBundle in data, saving quiz edits as revisions by author. Nothing is
+	// saved when a row is invalid, which the report lists, or in a dry run, which reports what would be saved.
+	ImportContent(ctx context.Context, data []byte, format string, dryRun bool, author string) (*dto.ContentImportReport, error)
+}
+
+type contentServiceImpl struct {
+	quizRepo     domain.QuizRepository
+	categoryRepo domain.CategoryRepository
+	statusRepo   domain.QuizStatusRepository
+	quizService  QuizService
+	txManager    domain.TransactionManager
+}
+
+// NewContentService creates a new instance of ContentService.
+// quizService owns the caches of quizzes and categories and is used to invalidate them.
+// statusRepo records the status changes imports make to existing quizzes.
+func NewContentService(quizRepo domain.QuizRepository, categoryRepo domain.CategoryRepository, statusRepo domain.QuizStatusRepository, quizService QuizService, txManager domain.TransactionManager) ContentService {
+	return &contentServiceImpl{quizRepo: quizRepo, categoryRepo: categoryRepo, statusRepo: statusRepo, quizService: quizService, txManager: txManager}
+}
+
+// ListCategories implements ContentService.
+func (s *contentServiceImpl) ListCategories(ctx context.Context) (*dto.CategoryListResponse, error) {
+	categories, err := s.categoryRepo.GetAllCategories(ctx)
+	if err != nil {
+		return nil, domain.NewInternalError("failed to list categories", err)
+	}
+	resp := &dto.CategoryListResponse{Categories: make([]dto.CategoryResponse, 0, len(categories))}
+	for _, category := range categories {
+		resp.Categories = append(resp.Categories, toCategoryResponse(category))
+	}
+	return resp, nil
+}
+
+// CreateCategory implements ContentService.
+func (s *contentServiceImpl) CreateCategory(ctx context.Context, req *dto.CategoryRequest) (*dto.CategoryResponse, error) {
+	category := &domain.Category{Name: req.Name, Description: req.Description}
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategoryName(ctx, category); err != nil {
+		return nil, err
+	}
+	if err := s.categoryRepo.SaveCategory(ctx, category); err != nil {
+		return nil, domain.NewInternalError("failed to save category", err)
+	}
+
+	s.invalidateCategoryList(ctx)
+	resp := toCategoryResponse(category)
+	return &resp, nil
+}
+
+// UpdateCategory implements ContentService.
+func (s *contentServiceImpl) UpdateCategory(ctx context.Context, id string, req *dto.CategoryRequest) (*dto.CategoryResponse, error) {
+	category, err := s.activeCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	category.Name, category.Description = req.Name, req.Description
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategoryName(ctx, category); err != nil {
+		return nil, err
+	}
+	if err := s.categoryRepo.UpdateCategory(ctx, category); err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to update category %s", id), err)
+	}
+
+	s.invalidateCategoryList(ctx)
+	resp := toCategoryResponse(category)
+	return &resp, nil
+}
+
+// DeleteCategory implements ContentService.
+func (s *contentServiceImpl) DeleteCategory(ctx context.Context, id string) error {
+	err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.activeCategory(ctx, id); err != nil {
+			return err
+		}
+		subCategories, err := s.categoryRepo.GetSubCategories(ctx, id)
+		if err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to get subcategories of category %s", id), err)
+		}
+		if len(subCategories) > 0 {
+			return domain.NewConflictError(fmt.Sprintf("category %s still has %d subcategories; delete them first", id, len(subCategories)))
+		}
+		deleted, err := s.categoryRepo.DeleteCategory(ctx, id)
+		if err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to delete category %s", id), err)
+		}
+		if !deleted {
+			// A subcategory was added, or the category deleted, since the checks above
+			if _, err := s.activeCategory(ctx, id); err != nil {
+				return err
+			}
+			return domain.NewConflictError(fmt.Sprintf("category %s still has subcategories; delete them first", id))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateCategoryList(ctx)
+	return nil
+}
+
+// ListSubCategories implements ContentService.
+func (s *contentServiceImpl) ListSubCategories(ctx context.Context, categoryID string) (*dto.SubCategoryListResponse, error) {
+	if _, err := s.activeCategory(ctx, categoryID); err != nil {
+		return nil, err
+	}
+	subCategories, err := s.categoryRepo.GetSubCategories(ctx, categoryID)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to list subcategories of category %s", categoryID), err)
+	}
+	resp := &dto.SubCategoryListResponse{SubCategories: make([]dto.SubCategoryResponse, 0, len(subCategories))}
+	for _, subCategory := range subCategories {
+		resp.SubCategories = append(resp.SubCategories, toSubCategoryResponse(subCategory))
+	}
+	return resp, nil
+}
+
+// CreateSubCategory implements ContentService.
+func (s *contentServiceImpl) CreateSubCategory(ctx context.Context, categoryID string, req *dto.SubCategoryRequest) (*dto.SubCategoryResponse, error) {
+	if _, err := s.activeCategory(ctx, categoryID); err != nil {
+		return nil, err
+	}
+	subCategory := &domain.SubCategory{CategoryID: categoryID, Name: req.Name, Description: req.Description}
+	if err := subCategory.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkSubCategoryName(ctx, subCategory); err != nil {
+		return nil, err
+	}
+	if err := s.categoryRepo.SaveSubCategory(ctx, subCategory); err != nil {
+		return nil, domain.NewInternalError("failed to save subcategory", err)
+	}
+
+	s.invalidateCategoryList(ctx)
+	resp := toSubCategoryResponse(subCategory)
+	return &resp, nil
+}
+
+// UpdateSubCategory implements ContentService.
+func (s *contentServiceImpl) UpdateSubCategory(ctx context.Context, id string, req *dto.SubCategoryRequest) (*dto.SubCategoryResponse, error) {
+	subCategory, err := s.activeSubCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	subCategory.Name, subCategory.Description = req.Name, req.Description
+	if err := subCategory.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkSubCategoryName(ctx, subCategory); err != nil {
+		return nil, err
+	}
+	if err := s.categoryRepo.UpdateSubCategory(ctx, subCategory); err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to update subcategory %s", id), err)
+	}
+
+	s.invalidateCategoryList(ctx)
+	resp := toSubCategoryResponse(subCategory)
+	return &resp, nil
+}
+
+// DeleteSubCategory implements ContentService.
+func (s *contentServiceImpl) DeleteSubCategory(ctx context.Context, id string) error {
+	err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.activeSubCategory(ctx, id); err != nil {
+			return err
+		}
+		quizzes, err := s.quizRepo.GetQuizzesBySubCategory(ctx, id)
+		if err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to get quizzes of subcategory %s", id), err)
+		}
+		if len(quizzes) > 0 {
+			return domain.NewConflictError(fmt.Sprintf("subcategory %s still has %d quizzes; delete them first", id, len(quizzes)))
+		}
+		deleted, err := s.categoryRepo.DeleteSubCategory(ctx, id)
+		if err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to delete subcategory %s", id), err)
+		}
+		if !deleted {
+			// A quiz was added, or the subcategory deleted, since the checks above
+			if _, err := s.activeSubCategory(ctx, id); err != nil {
+				return err
+			}
+			return domain.NewConflictError(fmt.Sprintf("subcategory %s still has quizzes; delete them first", id))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateCategoryList(ctx)
+	s.invalidateQuizList(ctx, id)
+	return nil
+}
+
+// ListQuizzes implements ContentService.
+func (s *contentServiceImpl) ListQuizzes(ctx context.Context, subCategoryID string) (*dto.QuizDetailListResponse, error) {
+	if _, err := s.activeSubCategory(ctx, subCategoryID); err != nil {
+		return nil, err
+	}
+	quizzes, err := s.quizRepo.GetQuizzesBySubCategory(ctx, subCategoryID)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to list quizzes of subcategory %s", subCategoryID), err)
+	}
+	resp := &dto.QuizDetailListResponse{Quizzes: make([]dto.QuizDetailResponse, 0, len(quizzes))}
+	for _, quiz := range quizzes {
+		resp.Quizzes = append(resp.Quizzes, toQuizDetailResponse(quiz))
+	}
+	return resp, nil
+}
+
+// GetQuiz implements ContentService.
+func (s *contentServiceImpl) GetQuiz(ctx context.Context, id string) (*dto.QuizDetailResponse, error) {
+	quiz, err := s.activeQuiz(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toQuizDetailResponse(quiz)
+	return &resp, nil
+}
+
+// CreateQuiz implements ContentService.
+func (s *contentServiceImpl) CreateQuiz(ctx context.Context, req *dto.QuizRequest) (*dto.QuizDetailResponse, error) {
+	quiz := domain.NewQuiz(req.Question, req.ModelAnswers, req.Keywords, domain.ParseDifficulty(req.Difficulty), req.SubCategoryID)
+	if err := s.validateQuiz(ctx, quiz); err != nil {
+		return nil, err
+	}
+	if err := s.quizRepo.SaveQuiz(ctx, quiz); err != nil {
+		return nil, domain.NewInternalError("failed to save quiz", err)
+	}
+
+	s.invalidateQuizList(ctx, quiz.SubCategoryID)
+	resp := toQuizDetailResponse(quiz)
+	return &resp, nil
+}
+
+// UpdateQuiz implements ContentService.
+func (s *contentServiceImpl) UpdateQuiz(ctx context.Context, id string, req *dto.QuizRequest, author string) (*dto.QuizDetailResponse, error) {
+	return s.UpdateQuizWith(ctx, id, req, author, nil)
+}
+
+// UpdateQuizWith implements ContentService.
+func (s *contentServiceImpl) UpdateQuizWith(ctx context.Context, id string, req *dto.QuizRequest, author string, also func(ctx context.Context, quiz *domain.Quiz) error) (*dto.QuizDetailResponse, error) {
+	quiz, err := s.activeQuiz(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Revision != 0 && req.Revision != quiz.Revision {
+		return nil, domain.NewConflictError(fmt.Sprintf("quiz %s is at revision %d, not %d; reload it and try again", id, quiz.Revision, req.Revision))
+	}
+	previousSubCategoryID := quiz.SubCategoryID
+	quiz.Question, quiz.ModelAnswers, quiz.Keywords = req.Question, req.ModelAnswers, req.Keywords
+	quiz.Difficulty, quiz.SubCategoryID = domain.ParseDifficulty(req.Difficulty), req.SubCategoryID
+	if err := s.saveQuizRevision(ctx, quiz, previousSubCategoryID, author, also); err != nil {
+		return nil, err
+	}
+	resp := toQuizDetailResponse(quiz)
+	return &resp, nil
+}
+
+// ListQuizRevisions implements ContentService.
+func (s *contentServiceImpl) ListQuizRevisions(ctx context.Context, quizID string) (*dto.QuizRevisionListResponse, error) {
+	if _, err := s.activeQuiz(ctx, quizID); err != nil {
+		return nil, err
+	}
+	revisions, err := s.quizRepo.ListQuizRevisions(ctx, quizID)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to list the revisions of quiz %s", quizID), err)
+	}
+	resp := &dto.QuizRevisionListResponse{Revisions: make([]dto.QuizRevisionResponse, 0, len(revisions))}
+	for _, revision := range revisions {
+		resp.Revisions = append(resp.Revisions, toQuizRevisionResponse(revision))
+	}
+	return resp, nil
+}
+
+// DiffQuizRevision implements ContentService.
+func (s *contentServiceImpl) DiffQuizRevision(ctx context.Context, quizID string, revision int, against int) (*dto.QuizRevisionDiffResponse, error) {
+	if against == 0 {
+		if revision <= 1 {
+			return nil, domain.NewValidationError("the first revision has no previous revision; choose one to compare against")
+		}
+		against = revision - 1
+	}
+	if _, err := s.activeQuiz(ctx, quizID); err != nil {
+		return nil, err
+	}
+	from, err := s.quizRevision(ctx, quizID, against)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.quizRevision(ctx, quizID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.QuizRevisionDiffResponse{QuizID: quizID, FromRevision: from.Revision, ToRevision: to.Revision, Changes: []dto.QuizFieldChangeResponse{}}
+	for _, change := range domain.DiffQuizRevisions(from, to) {
+		resp.Changes = append(resp.Changes, dto.QuizFieldChangeResponse{Field: change.Field, Old: change.Old, New: change.New})
+	}
+	return resp, nil
+}
+
+// RollbackQuiz implements ContentService. Answers graded against the rolled back content are evicted
+// from the answer cache along with the other cached data of the quiz.
+func (s *contentServiceImpl) RollbackQuiz(ctx context.Context, quizID string, revision int, author string) (*dto.QuizDetailResponse, error) {
+	quiz, err := s.activeQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := s.quizRevision(ctx, quizID, revision)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Revision == quiz.Revision {
+		return nil, domain.NewValidationError(fmt.Sprintf("quiz %s is already at revision %d", quizID, revision))
+	}
+
+	previousSubCategoryID := quiz.SubCategoryID
+	quiz.Question, quiz.ModelAnswers, quiz.Keywords = snapshot.Question, snapshot.ModelAnswers, snapshot.Keywords
+	quiz.Difficulty, quiz.SubCategoryID = snapshot.Difficulty, snapshot.SubCategoryID
+	if err := s.saveQuizRevision(ctx, quiz, previousSubCategoryID, author, nil); err != nil {
+		return nil, err
+	}
+	resp := toQuizDetailResponse(quiz)
+	return &resp, nil
+}
+
+// DeleteQuiz implements ContentService. The quiz's rubric is deleted along with it.
+func (s *contentServiceImpl) DeleteQuiz(ctx context.Context, id string) error {
+	var quiz *domain.Quiz
+	err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if quiz, err = s.activeQuiz(ctx, id); err != nil {
+			return err
+		}
+		deleted, err := s.quizRepo.DeleteQuiz(ctx, id)
+		if err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to delete quiz %s", id), err)
+		}
+		if !deleted {
+			return domain.NewQuizNotFoundError(id)
+		}
+		if _, err := s.quizRepo.DeleteQuizEvaluation(ctx, id); err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to delete the rubric of quiz %s", id), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateQuizList(ctx, quiz.SubCategoryID)
+	s.invalidateQuizAnswers(ctx, id)
+	return nil
+}
+
+// GetQuizRubric implements ContentService.
+func (s *contentServiceImpl) GetQuizRubric(ctx context.Context, quizID string) (*dto.QuizRubricResponse, error) {
+	if _, err := s.activeQuiz(ctx, quizID); err != nil {
+		return nil, err
+	}
+	evaluation, err := s.quizRepo.GetQuizEvaluation(ctx, quizID)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to get the rubric of quiz %s", quizID), err)
+	}
+	if evaluation == nil {
+		return nil, domain.NewNotFoundError(fmt.Sprintf("quiz %s has no rubric", quizID))
+	}
+	return toQuizRubricResponse(evaluation), nil
+}
+
+// PutQuizRubric implements ContentService.
+func (s *contentServiceImpl) PutQuizRubric(ctx context.Context, quizID string, req *dto.QuizRubricRequest) (*dto.QuizRubricResponse, error) {
+	evaluation := toQuizEvaluation(quizID, req)
+	if err := validateQuizEvaluation(evaluation); err != nil {
+		return nil, err
+	}
+
+	err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.activeQuiz(ctx, quizID); err != nil {
+			return err
+		}
+		existing, err := s.quizRepo.GetQuizEvaluation(ctx, quizID)
+		if err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to get the rubric of quiz %s", quizID), err)
+		}
+		if existing == nil {
+			err = s.quizRepo.SaveQuizEvaluation(ctx, evaluation)
+		} else {
+			evaluation.ID, evaluation.CreatedAt = existing.ID, existing.CreatedAt
+			err = s.quizRepo.UpdateQuizEvaluation(ctx, evaluation)
+		}
+		if err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to save the rubric of quiz %s", quizID), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateQuizAnswers(ctx, quizID)
+	return toQuizRubricResponse(evaluation), nil
+}
+
+// DeleteQuizRubric implements ContentService.
+func (s *contentServiceImpl) DeleteQuizRubric(ctx context.Context, quizID string) error {
+	if _, err := s.activeQuiz(ctx, quizID); err != nil {
+		return err
+	}
+	deleted, err := s.quizRepo.DeleteQuizEvaluation(ctx, quizID)
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to delete the rubric of quiz %s", quizID), err)
+	}
+	if !deleted {
+		return domain.NewNotFoundError(fmt.Sprintf("quiz %s has no rubric", quizID))
+	}
+
+	s.invalidateQuizAnswers(ctx, quizID)
+	return nil
+}
+
+func (s *contentServiceImpl) activeCategory(ctx context.Context, id string) (*domain.Category, error) {
+	category, err := s.categoryRepo.GetCategoryByID(ctx, id)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to get category %s", id), err)
+	}
+	if category == nil {
+		return nil, domain.NewNotFoundError(fmt.Sprintf("category %s not found", id))
+	}
+	return category, nil
+}
+
+func (s *contentServiceImpl) activeSubCategory(ctx context.Context, id string) (*domain.SubCategory, error) {
+	subCategory, err := s.categoryRepo.GetSubCategoryByID(ctx, id)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to get subcategory %s", id), err)
+	}
+	if subCategory == nil {
+		return nil, domain.NewNotFoundError(fmt.Sprintf("subcategory %s not found", id))
+	}
+	return subCategory, nil
+}
+
+func (s *contentServiceImpl) activeQuiz(ctx context.Context, id string) (*domain.Quiz, error) {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, id)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to get quiz %s", id), err)
+	}
+	if quiz == nil {
+		return nil, domain.NewQuizNotFoundError(id)
+	}
+	return quiz, nil
+}
+
+func (s *contentServiceImpl) quizRevision(ctx context.Context, quizID string, revision int) (*domain.QuizRevision, error) {
+	quizRevision, err := s.quizRepo.GetQuizRevision(ctx, quizID, revision)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to get revision %d of quiz %s", revision, quizID), err)
+	}
+	if quizRevision == nil {
+		return nil, domain.NewNotFoundError(fmt.Sprintf("quiz %s has no revision %d", quizID, revision))
+	}
+	return quizRevision, nil
+}
+
+// saveQuizRevision validates the edited quiz and saves it as its next revision, after also when it
+// is set, then invalidates the quiz lists of its old and new subcategory and the answers graded
+// against the old content.
+func (s *contentServiceImpl) saveQuizRevision(ctx context.Context, quiz *domain.Quiz, previousSubCategoryID string, author string, also func(ctx context.Context, quiz *domain.Quiz) error) error {
+	if err := s.validateQuiz(ctx, quiz); err != nil {
+		return err
+	}
+	err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if also != nil {
+			if err := also(ctx, quiz); err != nil {
+				return err
+			}
+		}
+		return s.quizRepo.UpdateQuiz(ctx, quiz, author)
+	})
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) { // From also
+		return err
+	}
+	if errors.Is(err, domain.ErrConflict) {
+		return domain.NewConflictError(fmt.Sprintf("quiz %s was changed by someone else; reload it and try again", quiz.ID))
+	}
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to update quiz %s", quiz.ID), err)
+	}
+
+	s.invalidateQuizList(ctx, previousSubCategoryID)
+	if quiz.SubCategoryID != previousSubCategoryID {
+		s.invalidateQuizList(ctx, quiz.SubCategoryID)
+	}
+	s.invalidateQuizAnswers(ctx, quiz.ID)
+	return nil
+}
+
+// checkCategoryName rejects a name another active category already has.
+func (s *contentServiceImpl) checkCategoryName(ctx context.Context, category *domain.Category) error {
+	existing, err := s.categoryRepo.GetByName(ctx, category.Name)
+	if err != nil {
+		return domain.NewInternalError("failed to check the category name", err)
+	}
+	if existing != nil && existing.ID != category.ID {
+		return domain.NewConflictError(fmt.Sprintf("a category named %q already exists", category.Name))
+	}
+	return nil
+}
+
+// checkSubCategoryName rejects a name another active subcategory of the same category already has.
+func (s *contentServiceImpl) checkSubCategoryName(ctx context.Context, subCategory *domain.SubCategory) error {
+	existing, err := s.categoryRepo.GetByNameAndCategoryID(ctx, subCategory.Name, subCategory.CategoryID)
+	if err != nil {
+		return domain.NewInternalError("failed to check the subcategory name", err)
+	}
+	if existing != nil && existing.ID != subCategory.ID {
+		return domain.NewConflictError(fmt.Sprintf("the category already has a subcategory named %q", subCategory.Name))
+	}
+	return nil
+}
+
+// validateQuiz validates the quiz and checks that its subcategory exists.
+func (s *contentServiceImpl) validateQuiz(ctx context.Context, quiz *domain.Quiz) error {
+	if err := quiz.Validate(); err != nil {
+		return err
+	}
+	subCategory, err := s.categoryRepo.GetSubCategoryByID(ctx, quiz.SubCategoryID)
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to get subcategory %s", quiz.SubCategoryID), err)
+	}
+	if subCategory == nil {
+		return domain.NewValidationError(fmt.Sprintf("subcategory %s does not exist", quiz.SubCategoryID))
+	}
+	return nil
+}
+
+// validateQuizEvaluation validates a rubric, reporting problems as domain.ValidationErrors.
+func validateQuizEvaluation(evaluation *domain.QuizEvaluation) error {
+	if err := evaluation.Validate(); err != nil {
+		return asValidationErrors(err)
+	}
+	if evaluation.MinimumKeywords < 0 {
+		return domain.ValidationErrors{{Field: "MinimumKeywords", Value: evaluation.MinimumKeywords, Message: "minimum keywords cannot be negative", Code: domain.CodeOutOfRange}}
+	}
+	return nil
+}
+
+// asValidationErrors turns the *domain.ValidationError returned by QuizEvaluation.Validate into
+// domain.ValidationErrors, which the error handler reports as a 400.
+func asValidationErrors(err error) error {
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		return domain.ValidationErrors{*validationErr}
+	}
+	return err
+}
+
+// The invalidate helpers only log failures: the change is already saved, and the stale entries expire with their TTL.
+
+func (s *contentServiceImpl) invalidateCategoryList(ctx context.Context) {
+	if err := s.quizService.InvalidateCategoryListCache(ctx); err != nil {
+		logger.Get().Error("Failed to invalidate the cached category list", zap.Error(err))
+	}
+}
+
+func (s *contentServiceImpl) invalidateQuizList(ctx context.Context, subCategoryID string) {
+	if err := s.quizService.InvalidateQuizListCache(ctx, subCategoryID); err != nil {
+		logger.Get().Error("Failed to invalidate the cached quiz lists", zap.String("subCategoryID", subCategoryID), zap.Error(err))
+	}
+}
+
+func (s *contentServiceImpl) invalidateQuizAnswers(ctx context.Context, quizID string) {
+	if err := s.quizService.InvalidateQuizCache(ctx, quizID); err != nil {
+		logger.Get().Error("Failed to invalidate the cached answer evaluations", zap.String("quizID", quizID), zap.Error(err))
+	}
+}
+
+func toCategoryResponse(category *domain.Category) dto.CategoryResponse {
+	return dto.CategoryResponse{ID: category.ID, Name: category.Name, Description: category.Description}
+}
+
+func toSubCategoryResponse(subCategory *domain.SubCategory) dto.SubCategoryResponse {
+	return dto.SubCategoryResponse{ID: subCategory.ID, CategoryID: subCategory.CategoryID, Name: subCategory.Name, Description: subCategory.Description}
+}
+
+func toQuizDetailResponse(quiz *domain.Quiz) dto.QuizDetailResponse {
+	return dto.QuizDetailResponse{
+		ID:            quiz.ID,
+		Question:      quiz.Question,
+		ModelAnswers:  quiz.ModelAnswers,
+		Keywords:      quiz.Keywords,
+		Difficulty:    quiz.DifficultyToString(),
+		SubCategoryID: quiz.SubCategoryID,
+		Revision:      quiz.Revision,
+		Status:        quiz.Status,
+		CreatedAt:     quiz.CreatedAt,
+		UpdatedAt:     quiz.UpdatedAt,
+	}
+}
+
+func toQuizRevisionResponse(revision *domain.QuizRevision) dto.QuizRevisionResponse {
+	quiz := domain.Quiz{Difficulty: revision.Difficulty}
+	return dto.QuizRevisionResponse{
+		QuizID:        revision.QuizID,
+		Revision:      revision.Revision,
+		Question:      revision.Question,
+		ModelAnswers:  revision.ModelAnswers,
+		Keywords:      revision.Keywords,
+		Difficulty:    quiz.DifficultyToString(),
+		SubCategoryID: revision.SubCategoryID,
+		Author:        revision.Author,
+		CreatedAt:     revision.CreatedAt,
+	}
+}
+
+func toQuizEvaluation(quizID string, req *dto.QuizRubricRequest) *domain.QuizEvaluation {
+	evaluation := &domain.QuizEvaluation{
+		QuizID:          quizID,
+		MinimumKeywords: req.MinimumKeywords,
+		RequiredTopics:  req.RequiredTopics,
+		ScoreRanges:     req.ScoreRanges,
+		SampleAnswers:   req.SampleAnswers,
+		RubricDetails:   req.RubricDetails,
+	}
+	for _, item := range req.ScoreEvaluations {
+		evaluation.ScoreEvaluations = append(evaluation.ScoreEvaluations, domain.ScoreEvaluationDetail{
+			ScoreRange:    item.ScoreRange,
+			SampleAnswers: item.SampleAnswers,
+			Explanation:   item.Explanation,
+		})
+	}
+	return evaluation
+}
+
+func toQuizRubricResponse(evaluation *domain.QuizEvaluation) *dto.QuizRubricResponse {
+	resp := &dto.QuizRubricResponse{
+		QuizID:           evaluation.QuizID,
+		MinimumKeywords:  evaluation.MinimumKeywords,
+		RequiredTopics:   evaluation.RequiredTopics,
+		ScoreRanges:      evaluation.ScoreRanges,
+		SampleAnswers:    evaluation.SampleAnswers,
+		RubricDetails:    evaluation.RubricDetails,
+		ScoreEvaluations: make([]dto.ScoreEvaluationItem, 0, len(evaluation.ScoreEvaluations)),
+		UpdatedAt:        evaluation.UpdatedAt,
+	}
+	for _, detail := range evaluation.ScoreEvaluations {
+		resp.ScoreEvaluations = append(resp.ScoreEvaluations, dto.ScoreEvaluationItem{
+			ScoreRange:    detail.ScoreRange,
+			SampleAnswers: detail.SampleAnswers,
+			Explanation:   detail.Explanation,
+		})
+	}
+	return resp
+}
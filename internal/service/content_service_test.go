@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestContentService wires a ContentService whose caches are invalidated through a real quizService.
+func newTestContentService() (ContentService, *MockQuizRepository, *MockCategoryRepository, *MockCache) {
+	svc, quizRepo, _, categoryRepo, cache := newTestContentServiceWithStatus()
+	return svc, quizRepo, categoryRepo, cache
+}
+
+// newTestContentServiceWithStatus is newTestContentService for tests that change the status of quizzes.
+func newTestContentServiceWithStatus() (ContentService, *MockQuizRepository, *MockQuizStatusRepository, *MockCategoryRepository, *MockCache) {
+	quizRepo, statusRepo, categoryRepo, cache := new(MockQuizRepository), new(MockQuizStatusRepository), new(MockCategoryRepository), new(MockCache)
+	quizService := NewQuizService(quizRepo, nil, cache, nil, nil, nil, 0, 0, config.CheckAnswerConfig{})
+	return NewContentService(quizRepo, categoryRepo, statusRepo, quizService, directTxManager{}), quizRepo, statusRepo, categoryRepo, cache
+}
+
+func assertDomainErrorCode(t *testing.T, err error, code domain.ErrorCode) {
+	t.Helper()
+	var domainErr *domain.DomainError
+	require.True(t, errors.As(err, &domainErr), "expected a domain error, got %v", err)
+	assert.Equal(t, code, domainErr.Code)
+}
+
+func TestContentService_CreateCategory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Invalidates The Category List", func(t *testing.T) {
+		svc, _, categoryRepo, cache := newTestContentService()
+		categoryRepo.On("GetByName", ctx, "Backend").Return(nil, nil)
+		categoryRepo.On("SaveCategory", ctx, mock.AnythingOfType("*domain.Category")).
+			Run(func(args mock.Arguments) { args.Get(1).(*domain.Category).ID = "cat-1" }).Return(nil)
+		cache.On("Delete", ctx, "quizbyte:quiz_service:category_list:all").Return(nil)
+
+		resp, err := svc.CreateCategory(ctx, &dto.CategoryRequest{Name: "Backend"})
+		require.NoError(t, err)
+		assert.Equal(t, "cat-1", resp.ID)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("Rejects A Taken Name", func(t *testing.T) {
+		svc, _, categoryRepo, _ := newTestContentService()
+		categoryRepo.On("GetByName", ctx, "Backend").Return(&domain.Category{ID: "cat-1", Name: "Backend"}, nil)
+
+		_, err := svc.CreateCategory(ctx, &dto.CategoryRequest{Name: "Backend"})
+		assertDomainErrorCode(t, err, domain.CodeConflict)
+		categoryRepo.AssertNotCalled(t, "SaveCategory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Validates", func(t *testing.T) {
+		svc, _, _, _ := newTestContentService()
+		_, err := svc.CreateCategory(ctx, &dto.CategoryRequest{})
+		assertDomainErrorCode(t, err, domain.CodeValidation)
+	})
+}
+
+func TestContentService_DeleteCategory_RequiresEmptyCategory(t *testing.T) {
+	ctx := context.Background()
+	svc, _, categoryRepo, cache := newTestContentService()
+	categoryRepo.On("GetCategoryByID", ctx, "cat-1").Return(&domain.Category{ID: "cat-1"}, nil)
+	categoryRepo.On("GetSubCategories", ctx, "cat-1").Return([]*domain.SubCategory{{ID: "sub-1"}}, nil).Once()
+
+	err := svc.DeleteCategory(ctx, "cat-1")
+	assertDomainErrorCode(t, err, domain.CodeConflict)
+	categoryRepo.AssertNotCalled(t, "DeleteCategory", mock.Anything, mock.Anything)
+
+	categoryRepo.On("GetSubCategories", ctx, "cat-1").Return([]*domain.SubCategory{}, nil)
+	categoryRepo.On("DeleteCategory", ctx, "cat-1").Return(true, nil)
+	cache.On("Delete", ctx, "quizbyte:quiz_service:category_list:all").Return(nil)
+	require.NoError(t, svc.DeleteCategory(ctx, "cat-1"))
+	cache.AssertExpectations(t)
+
+	categoryRepo.On("GetCategoryByID", ctx, "missing").Return(nil, nil)
+	assertDomainErrorCode(t, svc.DeleteCategory(ctx, "missing"), domain.CodeNotFound)
+
+	// A subcategory added after the emptiness check keeps the category
+	categoryRepo.On("GetCategoryByID", ctx, "cat-2").Return(&domain.Category{ID: "cat-2"}, nil)
+	categoryRepo.On("GetSubCategories", ctx, "cat-2").Return([]*domain.SubCategory{}, nil)
+	categoryRepo.On("DeleteCategory", ctx, "cat-2").Return(false, nil)
+	assertDomainErrorCode(t, svc.DeleteCategory(ctx, "cat-2"), domain.CodeConflict)
+}
+
+func TestContentService_UpdateQuiz_InvalidatesCaches(t *testing.T) {
+	ctx := context.Background()
+	svc, quizRepo, categoryRepo, cache := newTestContentService()
+	quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{
+		ID: "quiz-1", Question: "Old?", ModelAnswers: []string{"Old"}, Difficulty: domain.DifficultyEasy, SubCategoryID: "sub-1",
+	}, nil)
+	categoryRepo.On("GetSubCategoryByID", ctx, "sub-2").Return(&domain.SubCategory{ID: "sub-2", CategoryID: "cat-1"}, nil)
+	quizRepo.On("UpdateQuiz", ctx, mock.AnythingOfType("*domain.Quiz"), "admin-1").Return(nil)
+	var deleted []string
+	cache.On("Delete", ctx, mock.AnythingOfType("string")).Run(func(args mock.Arguments) { deleted = append(deleted, args.String(1)) }).Return(nil)
+
+	resp, err := svc.UpdateQuiz(ctx, "quiz-1", &dto.QuizRequest{
+		Question: "New?", ModelAnswers: []string{"New"}, Keywords: []string{"new"}, Difficulty: "hard", SubCategoryID: "sub-2",
+	}, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, "hard", resp.Difficulty)
+	assert.Equal(t, "sub-2", resp.SubCategoryID)
+
+	// The lists of both subcategories are cached for every count, and the quiz's graded answers are stale too.
+	assert.Len(t, deleted, 2*maxBulkQuizCount+1)
+	assert.Contains(t, deleted, "quizbyte:quiz_service:quiz_list:sub-1:10")
+	assert.Contains(t, deleted, "quizbyte:quiz_service:quiz_list:sub-2:50")
+	assert.Contains(t, deleted, "quizbyte:answer:evaluation_map:quiz-1")
+}
+
+func TestContentService_UpdateQuiz_Validates(t *testing.T) {
+	ctx := context.Background()
+	svc, quizRepo, categoryRepo, _ := newTestContentService()
+	quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1", SubCategoryID: "sub-1"}, nil)
+	categoryRepo.On("GetSubCategoryByID", ctx, "missing").Return(nil, nil)
+
+	_, err := svc.UpdateQuiz(ctx, "quiz-1", &dto.QuizRequest{Question: "Q?", ModelAnswers: []string{"A"}, Difficulty: "impossible", SubCategoryID: "sub-1"}, "admin-1")
+	assertDomainErrorCode(t, err, domain.CodeValidation)
+
+	_, err = svc.UpdateQuiz(ctx, "quiz-1", &dto.QuizRequest{Question: "Q?", ModelAnswers: []string{"A"}, Difficulty: "easy", SubCategoryID: "missing"}, "admin-1")
+	assertDomainErrorCode(t, err, domain.CodeValidation)
+	quizRepo.AssertNotCalled(t, "UpdateQuiz", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestContentService_UpdateQuiz_RejectsStaleRevisions(t *testing.T) {
+	ctx := context.Background()
+	req := &dto.QuizRequest{Question: "Q?", ModelAnswers: []string{"A"}, Difficulty: "easy", SubCategoryID: "sub-1"}
+
+	t.Run("Edit Based On An Older Revision", func(t *testing.T) {
+		svc, quizRepo, _, _ := newTestContentService()
+		quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1", SubCategoryID: "sub-1", Revision: 3}, nil)
+
+		stale := *req
+		stale.Revision = 2
+		_, err := svc.UpdateQuiz(ctx, "quiz-1", &stale, "admin-1")
+		assertDomainErrorCode(t, err, domain.CodeConflict)
+		quizRepo.AssertNotCalled(t, "UpdateQuiz", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Concurrent Update", func(t *testing.T) {
+		svc, quizRepo, categoryRepo, _ := newTestContentService()
+		quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1", SubCategoryID: "sub-1", Revision: 3}, nil)
+		categoryRepo.On("GetSubCategoryByID", ctx, "sub-1").Return(&domain.SubCategory{ID: "sub-1"}, nil)
+		quizRepo.On("UpdateQuiz", ctx, mock.AnythingOfType("*domain.Quiz"), "admin-1").Return(fmt.Errorf("quiz with ID quiz-1 not found or no longer at revision 3: %w", domain.ErrConflict))
+
+		_, err := svc.UpdateQuiz(ctx, "quiz-1", req, "admin-1")
+		assertDomainErrorCode(t, err, domain.CodeConflict)
+	})
+}
+
+func TestContentService_DiffQuizRevision(t *testing.T) {
+	ctx := context.Background()
+	svc, quizRepo, _, _ := newTestContentService()
+	quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1", Revision: 3}, nil)
+	quizRepo.On("GetQuizRevision", ctx, "quiz-1", 1).Return(&domain.QuizRevision{
+		QuizID: "quiz-1", Revision: 1, Question: "Q?", ModelAnswers: []string{"A"}, Difficulty: domain.DifficultyEasy, SubCategoryID: "sub-1",
+	}, nil)
+	quizRepo.On("GetQuizRevision", ctx, "quiz-1", 2).Return(&domain.QuizRevision{
+		QuizID: "quiz-1", Revision: 2, Question: "Q?", ModelAnswers: []string{"A", "B"}, Difficulty: domain.DifficultyHard, SubCategoryID: "sub-1",
+	}, nil)
+	quizRepo.On("GetQuizRevision", ctx, "quiz-1", 9).Return(nil, nil)
+
+	resp, err := svc.DiffQuizRevision(ctx, "quiz-1", 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.FromRevision)
+	assert.Equal(t, 2, resp.ToRevision)
+	assert.Equal(t, []dto.QuizFieldChangeResponse{
+		{Field: "model_answers", Old: []string{"A"}, New: []string{"A", "B"}},
+		{Field: "difficulty", Old: "easy", New: "hard"},
+	}, resp.Changes)
+
+	_, err = svc.DiffQuizRevision(ctx, "quiz-1", 1, 0)
+	assertDomainErrorCode(t, err, domain.CodeValidation)
+
+	_, err = svc.DiffQuizRevision(ctx, "quiz-1", 9, 1)
+	assertDomainErrorCode(t, err, domain.CodeNotFound)
+}
+
+func TestContentService_RollbackQuiz(t *testing.T) {
+	ctx := context.Background()
+	svc, quizRepo, categoryRepo, cache := newTestContentService()
+	quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{
+		ID: "quiz-1", Question: "Wrong?", ModelAnswers: []string{"Wrong"}, Difficulty: domain.DifficultyHard, SubCategoryID: "sub-1", Revision: 3,
+	}, nil)
+	quizRepo.On("GetQuizRevision", ctx, "quiz-1", 1).Return(&domain.QuizRevision{
+		QuizID: "quiz-1", Revision: 1, Question: "Right?", ModelAnswers: []string{"Right"}, Keywords: []string{"right"}, Difficulty: domain.DifficultyEasy, SubCategoryID: "sub-1",
+	}, nil)
+	quizRepo.On("GetQuizRevision", ctx, "quiz-1", 3).Return(&domain.QuizRevision{QuizID: "quiz-1", Revision: 3}, nil)
+	categoryRepo.On("GetSubCategoryByID", ctx, "sub-1").Return(&domain.SubCategory{ID: "sub-1"}, nil)
+	quizRepo.On("UpdateQuiz", ctx, mock.MatchedBy(func(q *domain.Quiz) bool {
+		return q.Question == "Right?" && q.Difficulty == domain.DifficultyEasy && q.Revision == 3
+	}), "admin-1").Run(func(args mock.Arguments) { args.Get(1).(*domain.Quiz).Revision = 4 }).Return(nil)
+	cache.On("Delete", ctx, mock.AnythingOfType("string")).Return(nil)
+
+	resp, err := svc.RollbackQuiz(ctx, "quiz-1", 1, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, resp.Revision)
+	assert.Equal(t, []string{"Right"}, resp.ModelAnswers)
+	// Answers graded against the rolled back text must not be served from the cache.
+	cache.AssertCalled(t, "Delete", ctx, "quizbyte:answer:evaluation_map:quiz-1")
+
+	_, err = svc.RollbackQuiz(ctx, "quiz-1", 3, "admin-1")
+	assertDomainErrorCode(t, err, domain.CodeValidation)
+}
+
+func TestContentService_DeleteQuiz_DeletesRubric(t *testing.T) {
+	ctx := context.Background()
+	svc, quizRepo, _, cache := newTestContentService()
+	quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1", SubCategoryID: "sub-1"}, nil)
+	quizRepo.On("DeleteQuiz", ctx, "quiz-1").Return(true, nil)
+	quizRepo.On("DeleteQuizEvaluation", ctx, "quiz-1").Return(true, nil)
+	cache.On("Delete", ctx, mock.AnythingOfType("string")).Return(nil)
+
+	require.NoError(t, svc.DeleteQuiz(ctx, "quiz-1"))
+	quizRepo.AssertExpectations(t)
+	cache.AssertCalled(t, "Delete", ctx, "quizbyte:answer:evaluation_map:quiz-1")
+	cache.AssertCalled(t, "Delete", ctx, "quizbyte:quiz_service:quiz_list:sub-1:1")
+
+	quizRepo.On("GetQuizByID", ctx, "missing").Return(nil, nil)
+	assertDomainErrorCode(t, svc.DeleteQuiz(ctx, "missing"), domain.CodeQuizNotFound)
+
+	// A quiz deleted by a concurrent request is reported as not found
+	quizRepo.On("GetQuizByID", ctx, "quiz-2").Return(&domain.Quiz{ID: "quiz-2", SubCategoryID: "sub-1"}, nil)
+	quizRepo.On("DeleteQuiz", ctx, "quiz-2").Return(false, nil)
+	assertDomainErrorCode(t, svc.DeleteQuiz(ctx, "quiz-2"), domain.CodeQuizNotFound)
+	quizRepo.AssertNotCalled(t, "DeleteQuizEvaluation", ctx, "quiz-2")
+}
+
+func TestContentService_PutQuizRubric(t *testing.T) {
+	ctx := context.Background()
+	rubric := &dto.QuizRubricRequest{
+		MinimumKeywords: 2,
+		ScoreRanges:     []string{"0.0-0.5", "0.5-1.0"},
+		ScoreEvaluations: []dto.ScoreEvaluationItem{
+			{ScoreRange: "0.0-0.5", SampleAnswers: []string{"weak"}, Explanation: "Misses the point"},
+			{ScoreRange: "0.5-1.0", SampleAnswers: []string{"strong"}, Explanation: "Covers the point"},
+		},
+	}
+
+	t.Run("Replaces The Existing Rubric", func(t *testing.T) {
+		svc, quizRepo, _, cache := newTestContentService()
+		quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1"}, nil)
+		quizRepo.On("GetQuizEvaluation", ctx, "quiz-1").Return(&domain.QuizEvaluation{ID: "eval-1", QuizID: "quiz-1"}, nil)
+		quizRepo.On("UpdateQuizEvaluation", ctx, mock.MatchedBy(func(e *domain.QuizEvaluation) bool {
+			return e.ID == "eval-1" && e.MinimumKeywords == 2 && len(e.ScoreEvaluations) == 2
+		})).Return(nil)
+		cache.On("Delete", ctx, "quizbyte:answer:evaluation_map:quiz-1").Return(nil)
+
+		resp, err := svc.PutQuizRubric(ctx, "quiz-1", rubric)
+		require.NoError(t, err)
+		assert.Equal(t, "Covers the point", resp.ScoreEvaluations[1].Explanation)
+		quizRepo.AssertNotCalled(t, "SaveQuizEvaluation", mock.Anything, mock.Anything)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("Creates A Missing Rubric", func(t *testing.T) {
+		svc, quizRepo, _, cache := newTestContentService()
+		quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1"}, nil)
+		quizRepo.On("GetQuizEvaluation", ctx, "quiz-1").Return(nil, nil)
+		quizRepo.On("SaveQuizEvaluation", ctx, mock.AnythingOfType("*domain.QuizEvaluation")).Return(nil)
+		cache.On("Delete", ctx, "quizbyte:answer:evaluation_map:quiz-1").Return(nil)
+
+		_, err := svc.PutQuizRubric(ctx, "quiz-1", rubric)
+		require.NoError(t, err)
+		quizRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reports Validation Errors", func(t *testing.T) {
+		svc, quizRepo, _, _ := newTestContentService()
+		invalid := *rubric
+		invalid.ScoreEvaluations = invalid.ScoreEvaluations[:1]
+
+		_, err := svc.PutQuizRubric(ctx, "quiz-1", &invalid)
+		var validationErrs domain.ValidationErrors
+		require.ErrorAs(t, err, &validationErrs)
+		assert.Equal(t, "ScoreEvaluations", validationErrs[0].Field)
+		quizRepo.AssertNotCalled(t, "GetQuizByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestContentService_DeleteQuizRubric_NotFound(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("No Rubric", func(t *testing.T) {
+		svc, quizRepo, _, cache := newTestContentService()
+		quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1"}, nil)
+		quizRepo.On("DeleteQuizEvaluation", ctx, "quiz-1").Return(false, nil)
+
+		assertDomainErrorCode(t, svc.DeleteQuizRubric(ctx, "quiz-1"), domain.CodeNotFound)
+		cache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Deleted Quiz", func(t *testing.T) {
+		svc, quizRepo, _, cache := newTestContentService()
+		quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(nil, nil)
+
+		assertDomainErrorCode(t, svc.DeleteQuizRubric(ctx, "quiz-1"), domain.CodeQuizNotFound)
+		quizRepo.AssertNotCalled(t, "DeleteQuizEvaluation", mock.Anything, mock.Anything)
+		cache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
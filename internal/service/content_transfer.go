@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+)
+
+// ExportContent implements ContentService.
+func (s *contentServiceImpl) ExportContent(ctx context.Context, format string) ([]byte, error) {
+	if err := checkContentFormat(format); err != nil {
+		return nil, err
+	}
+	bundle, err := s.contentBundle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := encodeContentBundle(bundle, format)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to encode the content as %s", format), err)
+	}
+	return data, nil
+}
+
+// ImportContent implements ContentService.
+func (s *contentServiceImpl) ImportContent(ctx context.Context, data []byte, format string, dryRun bool, author string) (*dto.ContentImportReport, error) {
+	bundle, rowErrs, err := decodeContentBundle(data, format)
+	if err != nil {
+		return nil, err
+	}
+	report := &dto.ContentImportReport{DryRun: dryRun, Errors: append(rowErrs, validateContentBundle(bundle)...)}
+	if len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	imp := &contentImport{
+		contentServiceImpl: s,
+		report:             report,
+		dryRun:             dryRun,
+		author:             author,
+		staleQuizLists:     map[string]bool{},
+		staleQuizAnswers:   map[string]bool{},
+	}
+	if dryRun {
+		err = imp.run(ctx, bundle)
+	} else {
+		err = s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+			if err := imp.run(ctx, bundle); err != nil {
+				return err
+			}
+			if len(report.Errors) > 0 {
+				return errImportRejected // Rolls back what the valid rows saved
+			}
+			return nil
+		})
+	}
+	if errors.Is(err, errImportRejected) {
+		return &dto.ContentImportReport{Errors: report.Errors}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	if imp.staleCategoryList {
+		s.invalidateCategoryList(ctx)
+	}
+	for subCategoryID := range imp.staleQuizLists {
+		s.invalidateQuizList(ctx, subCategoryID)
+	}
+	for quizID := range imp.staleQuizAnswers {
+		s.invalidateQuizAnswers(ctx, quizID)
+	}
+	return report, nil
+}
+
+// contentBundle loads every active category, subcategory, quiz and rubric.
+func (s *contentServiceImpl) contentBundle(ctx context.Context) (*dto.ContentBundle, error) {
+	categories, err := s.categoryRepo.GetAllCategories(ctx)
+	if err != nil {
+		return nil, domain.NewInternalError("failed to list categories", err)
+	}
+	bundle := &dto.ContentBundle{Categories: make([]dto.BundleCategory, 0, len(categories))}
+	for _, category := range categories {
+		subCategories, err := s.categoryRepo.GetSubCategories(ctx, category.ID)
+		if err != nil {
+			return nil, domain.NewInternalError(fmt.Sprintf("failed to list subcategories of category %s", category.ID), err)
+		}
+		bundleCategory := dto.BundleCategory{Name: category.Name, Description: category.Description, SubCategories: make([]dto.BundleSubCategory, 0, len(subCategories))}
+		for _, subCategory := range subCategories {
+			quizzes, err := s.quizRepo.GetQuizzesBySubCategory(ctx, subCategory.ID)
+			if err != nil {
+				return nil, domain.NewInternalError(fmt.Sprintf("failed to list quizzes of subcategory %s", subCategory.ID), err)
+			}
+			bundleSubCategory := dto.BundleSubCategory{Name: subCategory.Name, Description: subCategory.Description, Quizzes: make([]dto.BundleQuiz, 0, len(quizzes))}
+			for _, quiz := range quizzes {
+				evaluation, err := s.quizRepo.GetQuizEvaluation(ctx, quiz.ID)
+				if err != nil {
+					return nil, domain.NewInternalError(fmt.Sprintf("failed to get the rubric of quiz %s", quiz.ID), err)
+				}
+				bundleQuiz := dto.BundleQuiz{
+					Question:     quiz.Question,
+					ModelAnswers: compactList(quiz.ModelAnswers),
+					Keywords:     compactList(quiz.Keywords),
+					Difficulty:   quiz.DifficultyToString(),
+					Status:       quiz.Status,
+				}
+				if evaluation != nil {
+					bundleQuiz.Evaluation = toQuizRubricRequest(evaluation)
+				}
+				bundleSubCategory.Quizzes = append(bundleSubCategory.Quizzes, bundleQuiz)
+			}
+			bundleCategory.SubCategories = append(bundleCategory.SubCategories, bundleSubCategory)
+		}
+		bundle.Categories = append(bundle.Categories, bundleCategory)
+	}
+	return bundle, nil
+}
+
+// errImportRejected rolls back an import whose rows failed checks against the database.
+var errImportRejected = errors.New("import has invalid rows")
+
+// importRowError reports a problem with a row, by its CSV line when it has one, else by its path.
+func importRowError(line int, path string, format string, args ...interface{}) dto.ImportRowError {
+	row := path
+	if line > 0 {
+		row = fmt.Sprintf("line %d", line)
+	}
+	return dto.ImportRowError{Row: row, Message: fmt.Sprintf(format, args...)}
+}
+
+// validateContentBundle checks every row of the bundle and trims its names and questions, which are
+// the natural keys rows are matched by.
+func validateContentBundle(bundle *dto.ContentBundle) []dto.ImportRowError {
+	var errs []dto.ImportRowError
+	fail := func(line int, path string, format string, args ...interface{}) {
+		errs = append(errs, importRowError(line, path, format, args...))
+	}
+
+	categoryNames := map[string]bool{}
+	for ci := range bundle.Categories {
+		category := &bundle.Categories[ci]
+		categoryPath := fmt.Sprintf("categories[%d]", ci)
+		category.Name = strings.TrimSpace(category.Name)
+		if category.Name == "" {
+			fail(category.Line, categoryPath, "category name is required")
+		} else if categoryNames[category.Name] {
+			fail(category.Line, categoryPath, "category %q appears more than once", category.Name)
+		}
+		categoryNames[category.Name] = true
+
+		subCategoryNames := map[string]bool{}
+		for si := range category.SubCategories {
+			subCategory := &category.SubCategories[si]
+			subCategoryPath := fmt.Sprintf("%s.sub_categories[%d]", categoryPath, si)
+			subCategory.Name = strings.TrimSpace(subCategory.Name)
+			if subCategory.Name == "" {
+				fail(subCategory.Line, subCategoryPath, "subcategory name is required")
+			} else if subCategoryNames[subCategory.Name] {
+				fail(subCategory.Line, subCategoryPath, "subcategory %q appears more than once in category %q", subCategory.Name, category.Name)
+			}
+			subCategoryNames[subCategory.Name] = true
+
+			questions := map[string]bool{}
+			for qi := range subCategory.Quizzes {
+				quiz := &subCategory.Quizzes[qi]
+				quizPath := fmt.Sprintf("%s.quizzes[%d]", subCategoryPath, qi)
+				quiz.Question = strings.TrimSpace(quiz.Question)
+				if quiz.Question == "" {
+					fail(quiz.Line, quizPath, "question is required")
+				} else if questions[quiz.Question] {
+					fail(quiz.Line, quizPath, "question %q appears more than once in subcategory %q", quiz.Question, subCategory.Name)
+				}
+				questions[quiz.Question] = true
+				if len(compactList(quiz.ModelAnswers)) == 0 {
+					fail(quiz.Line, quizPath, "at least one model answer is required")
+				}
+				if domain.ParseDifficulty(quiz.Difficulty) == 0 {
+					fail(quiz.Line, quizPath, "difficulty %q must be easy, medium or hard", quiz.Difficulty)
+				}
+				if quiz.Status != "" && !domain.IsValidQuizStatus(quiz.Status) {
+					fail(quiz.Line, quizPath, "unknown status %q", quiz.Status)
+				}
+				if quiz.Evaluation != nil {
+					var validationErrs domain.ValidationErrors
+					if err := validateQuizEvaluation(toQuizEvaluation("import", quiz.Evaluation)); errors.As(err, &validationErrs) {
+						for _, validationErr := range validationErrs {
+							fail(quiz.Line, quizPath, "invalid evaluation: %s", validationErr.Message)
+						}
+					}
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// contentImport upserts a validated ContentBundle and counts what it does. A dry run only looks rows up,
+// so everything under a category or subcategory that does not exist yet counts as created. Rows that
+// conflict with the database are added to the report's errors and the import goes on checking the rest.
+type contentImport struct {
+	*contentServiceImpl
+	report *dto.ContentImportReport
+	dryRun bool
+	author string
+
+	// Caches to invalidate once the import is saved
+	staleCategoryList bool
+	staleQuizLists    map[string]bool // By subcategory ID
+	staleQuizAnswers  map[string]bool // By quiz ID
+}
+
+func (imp *contentImport) run(ctx context.Context, bundle *dto.ContentBundle) error {
+	for i := range bundle.Categories {
+		if err := imp.importCategory(ctx, &bundle.Categories[i], fmt.Sprintf("categories[%d]", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (imp *contentImport) importCategory(ctx context.Context, row *dto.BundleCategory, path string) error {
+	category, err := imp.categoryRepo.GetByName(ctx, row.Name)
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to get category %q", row.Name), err)
+	}
+	isNew := category == nil
+	switch {
+	case isNew:
+		imp.report.Categories.Created++
+		category = domain.NewCategory(row.Name, row.Description)
+		if !imp.dryRun {
+			err = imp.categoryRepo.SaveCategory(ctx, category)
+		}
+		imp.staleCategoryList = true
+	case category.Description != row.Description:
+		imp.report.Categories.Updated++
+		category.Description = row.Description
+		if !imp.dryRun {
+			err = imp.categoryRepo.UpdateCategory(ctx, category)
+		}
+		imp.staleCategoryList = true
+	default:
+		imp.report.Categories.Skipped++
+	}
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to save category %q", row.Name), err)
+	}
+	for i := range row.SubCategories {
+		if err := imp.importSubCategory(ctx, category, isNew, &row.SubCategories[i], fmt.Sprintf("%s.sub_categories[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (imp *contentImport) importSubCategory(ctx context.Context, category *domain.Category, categoryIsNew bool, row *dto.BundleSubCategory, path string) error {
+	var subCategory *domain.SubCategory
+	if !categoryIsNew {
+		var err error
+		if subCategory, err = imp.categoryRepo.GetByNameAndCategoryID(ctx, row.Name, category.ID); err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to get subcategory %q of category %q", row.Name, category.Name), err)
+		}
+	}
+	isNew := subCategory == nil
+	var err error
+	switch {
+	case isNew:
+		imp.report.SubCategories.Created++
+		subCategory = domain.NewSubCategory(category.ID, row.Name, row.Description)
+		if !imp.dryRun {
+			err = imp.categoryRepo.SaveSubCategory(ctx, subCategory)
+		}
+		imp.staleCategoryList = true
+	case subCategory.Description != row.Description:
+		imp.report.SubCategories.Updated++
+		subCategory.Description = row.Description
+		if !imp.dryRun {
+			err = imp.categoryRepo.UpdateSubCategory(ctx, subCategory)
+		}
+		imp.staleCategoryList = true
+	default:
+		imp.report.SubCategories.Skipped++
+	}
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to save subcategory %q of category %q", row.Name, category.Name), err)
+	}
+
+	existing := map[string]*domain.Quiz{}
+	if !isNew {
+		quizzes, err := imp.quizRepo.GetQuizzesBySubCategory(ctx, subCategory.ID)
+		if err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to list quizzes of subcategory %s", subCategory.ID), err)
+		}
+		for _, quiz := range quizzes {
+			existing[strings.TrimSpace(quiz.Question)] = quiz
+		}
+	}
+	for i := range row.Quizzes {
+		if err := imp.importQuiz(ctx, subCategory, existing[row.Quizzes[i].Question], &row.Quizzes[i], fmt.Sprintf("%s.quizzes[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importQuiz creates the quiz of the row, or updates quiz, the existing quiz with the same question.
+// The status of an existing quiz is changed like a reviewer would, with an audit record; a status the
+// quiz cannot move to is reported as an error of the row.
+func (imp *contentImport) importQuiz(ctx context.Context, subCategory *domain.SubCategory, quiz *domain.Quiz, row *dto.BundleQuiz, path string) error {
+	modelAnswers, keywords, difficulty := compactList(row.ModelAnswers), compactList(row.Keywords), domain.ParseDifficulty(row.Difficulty)
+	isNew := quiz == nil
+	contentChanged := !isNew && !(slices.Equal(compactList(quiz.ModelAnswers), modelAnswers) && slices.Equal(compactList(quiz.Keywords), keywords) && quiz.Difficulty == difficulty)
+	statusChanged := !isNew && row.Status != "" && row.Status != quiz.Status
+	if statusChanged && !domain.CanTransitionQuizStatus(quiz.Status, row.Status) {
+		imp.report.Errors = append(imp.report.Errors, importRowError(row.Line, path, "quiz %s cannot move from %s to %s", quiz.ID, quiz.Status, row.Status))
+		statusChanged = false
+	}
+	switch {
+	case isNew:
+		imp.report.Quizzes.Created++
+		quiz = domain.NewQuiz(row.Question, modelAnswers, keywords, difficulty, subCategory.ID)
+		if row.Status != "" {
+			quiz.Status = row.Status
+		}
+		if !imp.dryRun {
+			if err := imp.quizRepo.SaveQuiz(ctx, quiz); err != nil {
+				return domain.NewInternalError(fmt.Sprintf("failed to save quiz %q", row.Question), err)
+			}
+			imp.staleQuizLists[subCategory.ID] = true
+		}
+	case !contentChanged && !statusChanged:
+		imp.report.Quizzes.Skipped++
+	default:
+		imp.report.Quizzes.Updated++
+		if contentChanged {
+			quiz.ModelAnswers, quiz.Keywords, quiz.Difficulty = modelAnswers, keywords, difficulty
+		}
+		if imp.dryRun {
+			break
+		}
+		if contentChanged {
+			err := imp.quizRepo.UpdateQuiz(ctx, quiz, imp.author)
+			if errors.Is(err, domain.ErrConflict) {
+				return domain.NewConflictError(fmt.Sprintf("quiz %s was changed during the import; run it again", quiz.ID))
+			}
+			if err != nil {
+				return domain.NewInternalError(fmt.Sprintf("failed to update quiz %s", quiz.ID), err)
+			}
+			imp.staleQuizAnswers[quiz.ID] = true
+		}
+		if statusChanged {
+			if err := imp.importQuizStatus(ctx, quiz, row.Status); err != nil {
+				return err
+			}
+		}
+		imp.staleQuizLists[subCategory.ID] = true
+	}
+
+	if row.Evaluation == nil {
+		return nil
+	}
+	return imp.importEvaluation(ctx, quiz, isNew, row.Evaluation)
+}
+
+// importQuizStatus moves an existing quiz to status and records the change.
+func (imp *contentImport) importQuizStatus(ctx context.Context, quiz *domain.Quiz, status string) error {
+	change := &domain.QuizStatusChange{QuizID: quiz.ID, FromStatus: quiz.Status, ToStatus: status, Actor: imp.author, Comment: "Imported"}
+	err := imp.statusRepo.ChangeQuizStatus(ctx, change)
+	if errors.Is(err, domain.ErrConflict) {
+		return domain.NewConflictError(fmt.Sprintf("quiz %s was changed during the import; run it again", quiz.ID))
+	}
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to change the status of quiz %s", quiz.ID), err)
+	}
+	quiz.Status, quiz.UpdatedAt = status, change.CreatedAt
+	return nil
+}
+
+func (imp *contentImport) importEvaluation(ctx context.Context, quiz *domain.Quiz, quizIsNew bool, row *dto.QuizRubricRequest) error {
+	evaluation := toQuizEvaluation(quiz.ID, row)
+	var existing *domain.QuizEvaluation
+	if !quizIsNew {
+		var err error
+		if existing, err = imp.quizRepo.GetQuizEvaluation(ctx, quiz.ID); err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to get the rubric of quiz %s", quiz.ID), err)
+		}
+	}
+	var err error
+	switch {
+	case existing == nil:
+		imp.report.Evaluations.Created++
+		if !imp.dryRun {
+			err = imp.quizRepo.SaveQuizEvaluation(ctx, evaluation)
+		}
+	case sameQuizEvaluation(existing, evaluation):
+		imp.report.Evaluations.Skipped++
+		return nil
+	default:
+		imp.report.Evaluations.Updated++
+		evaluation.ID, evaluation.CreatedAt = existing.ID, existing.CreatedAt
+		if !imp.dryRun {
+			err = imp.quizRepo.UpdateQuizEvaluation(ctx, evaluation)
+		}
+	}
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to save the rubric of quiz %s", quiz.ID), err)
+	}
+	if !quizIsNew && !imp.dryRun {
+		imp.staleQuizAnswers[quiz.ID] = true
+	}
+	return nil
+}
+
+func sameQuizEvaluation(a, b *domain.QuizEvaluation) bool {
+	return a.MinimumKeywords == b.MinimumKeywords &&
+		slices.Equal(compactList(a.RequiredTopics), compactList(b.RequiredTopics)) &&
+		slices.Equal(compactList(a.ScoreRanges), compactList(b.ScoreRanges)) &&
+		slices.Equal(compactList(a.SampleAnswers), compactList(b.SampleAnswers)) &&
+		a.RubricDetails == b.RubricDetails &&
+		slices.EqualFunc(a.ScoreEvaluations, b.ScoreEvaluations, func(x, y domain.ScoreEvaluationDetail) bool {
+			return x.ScoreRange == y.ScoreRange && x.Explanation == y.Explanation && slices.Equal(x.SampleAnswers, y.SampleAnswers)
+		})
+}
+
+// compactList drops blank items, e.g. the one an empty list reads back as from the database.
+func compactList(items []string) []string {
+	compacted := make([]string, 0, len(items))
+	for _, item := range items {
+		if strings.TrimSpace(item) != "" {
+			compacted = append(compacted, item)
+		}
+	}
+	return compacted
+}
+
+func toQuizRubricRequest(evaluation *domain.QuizEvaluation) *dto.QuizRubricRequest {
+	req := &dto.QuizRubricRequest{
+		MinimumKeywords:  evaluation.MinimumKeywords,
+		RequiredTopics:   compactList(evaluation.RequiredTopics),
+		ScoreRanges:      compactList(evaluation.ScoreRanges),
+		SampleAnswers:    compactList(evaluation.SampleAnswers),
+		RubricDetails:    evaluation.RubricDetails,
+		ScoreEvaluations: make([]dto.ScoreEvaluationItem, 0, len(evaluation.ScoreEvaluations)),
+	}
+	for _, detail := range evaluation.ScoreEvaluations {
+		req.ScoreEvaluations = append(req.ScoreEvaluations, dto.ScoreEvaluationItem{
+			ScoreRange:    detail.ScoreRange,
+			SampleAnswers: detail.SampleAnswers,
+			Explanation:   detail.Explanation,
+		})
+	}
+	return req
+}
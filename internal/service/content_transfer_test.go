@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testContentBundle() *dto.ContentBundle {
+	return &dto.ContentBundle{Categories: []dto.BundleCategory{
+		{Name: "Backend", Description: "Server side", SubCategories: []dto.BundleSubCategory{
+			{Name: "Databases", Description: "Storage", Quizzes: []dto.BundleQuiz{
+				{
+					Question:     "What is an index?",
+					ModelAnswers: []string{"A structure that speeds up lookups"},
+					Keywords:     []string{"b-tree", "lookup"},
+					Difficulty:   "medium",
+					Status:       domain.QuizStatusPublished,
+					Evaluation: &dto.QuizRubricRequest{
+						MinimumKeywords: 1,
+						RequiredTopics:  []string{"lookups"},
+						ScoreRanges:     []string{"0.0-0.5", "0.5-1.0"},
+						SampleAnswers:   []string{"It makes reads faster"},
+						RubricDetails:   "Mention the trade-off, \"writes get slower\"",
+						ScoreEvaluations: []dto.ScoreEvaluationItem{
+							{ScoreRange: "0.0-0.5", SampleAnswers: []string{"A table"}, Explanation: "Wrong"},
+							{ScoreRange: "0.5-1.0", SampleAnswers: []string{"A b-tree"}, Explanation: "Right"},
+						},
+					},
+				},
+				{Question: "What is a transaction?", ModelAnswers: []string{"A unit of work", "All or nothing"}, Keywords: []string{"atomicity"}, Difficulty: "hard", Status: domain.QuizStatusDraft},
+			}},
+			{Name: "Caching", Quizzes: []dto.BundleQuiz{}},
+		}},
+		{Name: "Frontend", SubCategories: []dto.BundleSubCategory{}},
+	}}
+}
+
+func TestContentBundle_RoundTrip(t *testing.T) {
+	for _, format := range []string{ContentFormatJSON, ContentFormatCSV, ContentFormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			data, err := encodeContentBundle(testContentBundle(), format)
+			require.NoError(t, err)
+
+			bundle, rowErrs, err := decodeContentBundle(data, format)
+			require.NoError(t, err)
+			assert.Empty(t, rowErrs)
+			for ci := range bundle.Categories { // CSV rows remember their line
+				category := &bundle.Categories[ci]
+				category.Line = 0
+				for si := range category.SubCategories {
+					subCategory := &category.SubCategories[si]
+					subCategory.Line = 0
+					if subCategory.Quizzes == nil {
+						subCategory.Quizzes = []dto.BundleQuiz{}
+					}
+					for qi := range subCategory.Quizzes {
+						subCategory.Quizzes[qi].Line = 0
+					}
+				}
+				if category.SubCategories == nil {
+					category.SubCategories = []dto.BundleSubCategory{}
+				}
+			}
+			assert.Equal(t, testContentBundle(), bundle)
+		})
+	}
+}
+
+func TestDecodeContentCSV_RowErrors(t *testing.T) {
+	data := strings.Join([]string{
+		"category,sub_category,question,model_answers,difficulty,minimum_keywords",
+		`Backend,Databases,What is an index?,"[""A lookup structure""]",easy,`,
+		`,Databases,Orphan?,"[""Answer""]",easy,`,
+		`Backend,,No subcategory?,"[""Answer""]",easy,`,
+		`Backend,Databases,Bad list?,not a list,easy,`,
+		`Backend,Databases,Bad rubric?,"[""Answer""]",easy,many`,
+	}, "\n")
+
+	bundle, rowErrs, err := decodeContentBundle([]byte(data), ContentFormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, []dto.ImportRowError{
+		{Row: "line 3", Message: "category is required"},
+		{Row: "line 4", Message: "sub_category is required for a quiz"},
+		{Row: "line 5", Message: "model_answers must be a JSON array of strings"},
+		{Row: "line 6", Message: "minimum_keywords must be a number"},
+	}, rowErrs)
+	require.Len(t, bundle.Categories, 1)
+	require.Len(t, bundle.Categories[0].SubCategories[0].Quizzes, 1)
+	assert.Equal(t, 2, bundle.Categories[0].SubCategories[0].Quizzes[0].Line)
+
+	_, _, err = decodeContentBundle([]byte("category,answer\nBackend,42"), ContentFormatCSV)
+	assertDomainErrorCode(t, err, domain.CodeValidation)
+}
+
+func TestContentService_ExportContent(t *testing.T) {
+	ctx := context.Background()
+	svc, quizRepo, categoryRepo, _ := newTestContentService()
+	categoryRepo.On("GetAllCategories", ctx).Return([]*domain.Category{{ID: "cat-1", Name: "Backend"}}, nil)
+	categoryRepo.On("GetSubCategories", ctx, "cat-1").Return([]*domain.SubCategory{{ID: "sub-1", CategoryID: "cat-1", Name: "Databases"}}, nil)
+	quizRepo.On("GetQuizzesBySubCategory", ctx, "sub-1").Return([]*domain.Quiz{
+		{ID: "quiz-1", Question: "What is an index?", ModelAnswers: []string{"A lookup structure"}, Keywords: []string{""}, Difficulty: 3, Status: domain.QuizStatusRetired},
+	}, nil)
+	quizRepo.On("GetQuizEvaluation", ctx, "quiz-1").Return(nil, nil)
+
+	data, err := svc.ExportContent(ctx, ContentFormatYAML)
+	require.NoError(t, err)
+	bundle, _, err := decodeContentBundle(data, ContentFormatYAML)
+	require.NoError(t, err)
+	assert.Equal(t, dto.BundleQuiz{Question: "What is an index?", ModelAnswers: []string{"A lookup structure"}, Keywords: []string{}, Difficulty: "hard", Status: domain.QuizStatusRetired},
+		bundle.Categories[0].SubCategories[0].Quizzes[0])
+
+	_, err = svc.ExportContent(ctx, "xml")
+	assertDomainErrorCode(t, err, domain.CodeValidation)
+}
+
+func TestContentService_ImportContent(t *testing.T) {
+	ctx := context.Background()
+	data, err := encodeContentBundle(testContentBundle(), ContentFormatJSON)
+	require.NoError(t, err)
+
+	t.Run("Creates A New Tree", func(t *testing.T) {
+		svc, quizRepo, categoryRepo, cache := newTestContentService()
+		categoryRepo.On("GetByName", ctx, mock.Anything).Return(nil, nil)
+		categoryRepo.On("SaveCategory", ctx, mock.AnythingOfType("*domain.Category")).Return(nil)
+		categoryRepo.On("SaveSubCategory", ctx, mock.AnythingOfType("*domain.SubCategory")).Return(nil)
+		var saved []*domain.Quiz
+		quizRepo.On("SaveQuiz", ctx, mock.AnythingOfType("*domain.Quiz")).Run(func(args mock.Arguments) { saved = append(saved, args.Get(1).(*domain.Quiz)) }).Return(nil)
+		quizRepo.On("SaveQuizEvaluation", ctx, mock.AnythingOfType("*domain.QuizEvaluation")).Return(nil)
+		cache.On("Delete", ctx, mock.AnythingOfType("string")).Return(nil)
+
+		report, err := svc.ImportContent(ctx, data, ContentFormatJSON, false, "admin-1")
+		require.NoError(t, err)
+		assert.Empty(t, report.Errors)
+		assert.Equal(t, dto.ImportCounts{Created: 2}, report.Categories)
+		assert.Equal(t, dto.ImportCounts{Created: 2}, report.SubCategories)
+		assert.Equal(t, dto.ImportCounts{Created: 2}, report.Quizzes)
+		assert.Equal(t, dto.ImportCounts{Created: 1}, report.Evaluations)
+		require.Len(t, saved, 2)
+		assert.Equal(t, domain.QuizStatusDraft, saved[1].Status)
+		categoryRepo.AssertNotCalled(t, "GetByNameAndCategoryID", mock.Anything, mock.Anything, mock.Anything)
+		cache.AssertCalled(t, "Delete", ctx, "quizbyte:quiz_service:category_list:all")
+	})
+
+	t.Run("Updates Changed Rows And Skips The Rest", func(t *testing.T) {
+		svc, quizRepo, categoryRepo, cache := newTestContentService()
+		categoryRepo.On("GetByName", ctx, "Backend").Return(&domain.Category{ID: "cat-1", Name: "Backend", Description: "Server side"}, nil)
+		categoryRepo.On("GetByName", ctx, "Frontend").Return(&domain.Category{ID: "cat-2", Name: "Frontend", Description: "Old"}, nil)
+		categoryRepo.On("UpdateCategory", ctx, mock.MatchedBy(func(c *domain.Category) bool { return c.ID == "cat-2" && c.Description == "" })).Return(nil)
+		categoryRepo.On("GetByNameAndCategoryID", ctx, "Databases", "cat-1").Return(&domain.SubCategory{ID: "sub-1", CategoryID: "cat-1", Name: "Databases", Description: "Storage"}, nil)
+		categoryRepo.On("GetByNameAndCategoryID", ctx, "Caching", "cat-1").Return(&domain.SubCategory{ID: "sub-2", CategoryID: "cat-1", Name: "Caching"}, nil)
+		quizRepo.On("GetQuizzesBySubCategory", ctx, "sub-1").Return([]*domain.Quiz{
+			{ID: "quiz-1", Question: "What is an index?", ModelAnswers: []string{"A structure that speeds up lookups"}, Keywords: []string{"b-tree", "lookup"}, Difficulty: 2, SubCategoryID: "sub-1", Status: domain.QuizStatusPublished},
+			{ID: "quiz-2", Question: "What is a transaction?", ModelAnswers: []string{"A unit of work"}, Keywords: []string{""}, Difficulty: 3, SubCategoryID: "sub-1", Status: domain.QuizStatusDraft},
+		}, nil)
+		quizRepo.On("GetQuizzesBySubCategory", ctx, "sub-2").Return([]*domain.Quiz{}, nil)
+		quizRepo.On("GetQuizEvaluation", ctx, "quiz-1").Return(toQuizEvaluation("quiz-1", testContentBundle().Categories[0].SubCategories[0].Quizzes[0].Evaluation), nil)
+		var updated []*domain.Quiz
+		quizRepo.On("UpdateQuiz", ctx, mock.AnythingOfType("*domain.Quiz"), "admin-1").Run(func(args mock.Arguments) { updated = append(updated, args.Get(1).(*domain.Quiz)) }).Return(nil)
+		cache.On("Delete", ctx, mock.AnythingOfType("string")).Return(nil)
+
+		report, err := svc.ImportContent(ctx, data, ContentFormatJSON, false, "admin-1")
+		require.NoError(t, err)
+		assert.Equal(t, dto.ImportCounts{Updated: 1, Skipped: 1}, report.Categories)
+		assert.Equal(t, dto.ImportCounts{Skipped: 2}, report.SubCategories)
+		assert.Equal(t, dto.ImportCounts{Updated: 1, Skipped: 1}, report.Quizzes)
+		assert.Equal(t, dto.ImportCounts{Skipped: 1}, report.Evaluations)
+
+		require.Len(t, updated, 1)
+		assert.Equal(t, "quiz-2", updated[0].ID)
+		assert.Equal(t, []string{"A unit of work", "All or nothing"}, updated[0].ModelAnswers)
+		quizRepo.AssertNotCalled(t, "SaveQuiz", mock.Anything, mock.Anything)
+		cache.AssertCalled(t, "Delete", ctx, "quizbyte:answer:evaluation_map:quiz-2")
+	})
+
+	t.Run("Changes The Status Of An Existing Quiz", func(t *testing.T) {
+		svc, quizRepo, statusRepo, categoryRepo, cache := newTestContentServiceWithStatus()
+		categoryRepo.On("GetByName", ctx, "Backend").Return(&domain.Category{ID: "cat-1", Name: "Backend", Description: "Server side"}, nil)
+		categoryRepo.On("GetByName", ctx, "Frontend").Return(&domain.Category{ID: "cat-2", Name: "Frontend"}, nil)
+		categoryRepo.On("GetByNameAndCategoryID", ctx, "Databases", "cat-1").Return(&domain.SubCategory{ID: "sub-1", CategoryID: "cat-1", Name: "Databases", Description: "Storage"}, nil)
+		categoryRepo.On("GetByNameAndCategoryID", ctx, "Caching", "cat-1").Return(&domain.SubCategory{ID: "sub-2", CategoryID: "cat-1", Name: "Caching"}, nil)
+		quizRepo.On("GetQuizzesBySubCategory", ctx, "sub-1").Return([]*domain.Quiz{
+			{ID: "quiz-1", Question: "What is an index?", ModelAnswers: []string{"A structure that speeds up lookups"}, Keywords: []string{"b-tree", "lookup"}, Difficulty: 2, SubCategoryID: "sub-1", Status: domain.QuizStatusInReview},
+			{ID: "quiz-2", Question: "What is a transaction?", ModelAnswers: []string{"A unit of work", "All or nothing"}, Keywords: []string{"atomicity"}, Difficulty: 3, SubCategoryID: "sub-1", Status: domain.QuizStatusDraft},
+		}, nil)
+		quizRepo.On("GetQuizzesBySubCategory", ctx, "sub-2").Return([]*domain.Quiz{}, nil)
+		quizRepo.On("GetQuizEvaluation", ctx, "quiz-1").Return(toQuizEvaluation("quiz-1", testContentBundle().Categories[0].SubCategories[0].Quizzes[0].Evaluation), nil)
+		statusRepo.On("ChangeQuizStatus", ctx, mock.MatchedBy(func(change *domain.QuizStatusChange) bool {
+			return change.QuizID == "quiz-1" && change.FromStatus == domain.QuizStatusInReview && change.ToStatus == domain.QuizStatusPublished && change.Actor == "admin-1"
+		})).Return(nil)
+		cache.On("Delete", ctx, mock.AnythingOfType("string")).Return(nil)
+
+		report, err := svc.ImportContent(ctx, data, ContentFormatJSON, false, "admin-1")
+		require.NoError(t, err)
+		assert.Equal(t, dto.ImportCounts{Updated: 1, Skipped: 1}, report.Quizzes)
+		statusRepo.AssertExpectations(t)
+		quizRepo.AssertNotCalled(t, "UpdateQuiz", mock.Anything, mock.Anything, mock.Anything)
+		cache.AssertCalled(t, "Delete", ctx, "quizbyte:quiz_service:quiz_list:sub-1:1")
+	})
+
+	// Both quizzes moved backwards in this environment: the file has quiz-1 published and quiz-2 draft
+	expectBackwardStatuses := func(quizRepo *MockQuizRepository, categoryRepo *MockCategoryRepository) {
+		categoryRepo.On("GetByName", ctx, "Backend").Return(&domain.Category{ID: "cat-1", Name: "Backend", Description: "Server side"}, nil)
+		categoryRepo.On("GetByName", ctx, "Frontend").Return(&domain.Category{ID: "cat-2", Name: "Frontend"}, nil)
+		categoryRepo.On("GetByNameAndCategoryID", ctx, "Databases", "cat-1").Return(&domain.SubCategory{ID: "sub-1", CategoryID: "cat-1", Name: "Databases", Description: "Storage"}, nil)
+		categoryRepo.On("GetByNameAndCategoryID", ctx, "Caching", "cat-1").Return(&domain.SubCategory{ID: "sub-2", CategoryID: "cat-1", Name: "Caching"}, nil)
+		quizRepo.On("GetQuizzesBySubCategory", ctx, "sub-1").Return([]*domain.Quiz{
+			{ID: "quiz-1", Question: "What is an index?", ModelAnswers: []string{"A structure that speeds up lookups"}, Keywords: []string{"b-tree", "lookup"}, Difficulty: 2, SubCategoryID: "sub-1", Status: domain.QuizStatusRetired},
+			{ID: "quiz-2", Question: "What is a transaction?", ModelAnswers: []string{"A unit of work"}, Keywords: []string{"atomicity"}, Difficulty: 3, SubCategoryID: "sub-1", Status: domain.QuizStatusPublished},
+		}, nil)
+		quizRepo.On("GetQuizzesBySubCategory", ctx, "sub-2").Return([]*domain.Quiz{}, nil)
+		quizRepo.On("GetQuizEvaluation", ctx, "quiz-1").Return(toQuizEvaluation("quiz-1", testContentBundle().Categories[0].SubCategories[0].Quizzes[0].Evaluation), nil)
+	}
+	backwardStatusErrors := []dto.ImportRowError{
+		{Row: "categories[0].sub_categories[0].quizzes[0]", Message: "quiz quiz-1 cannot move from retired to published"},
+		{Row: "categories[0].sub_categories[0].quizzes[1]", Message: "quiz quiz-2 cannot move from published to draft"},
+	}
+
+	t.Run("Dry Run Reports Every Status A Quiz Cannot Move To", func(t *testing.T) {
+		svc, quizRepo, statusRepo, categoryRepo, _ := newTestContentServiceWithStatus()
+		expectBackwardStatuses(quizRepo, categoryRepo)
+
+		report, err := svc.ImportContent(ctx, data, ContentFormatJSON, true, "admin-1")
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, backwardStatusErrors, report.Errors)
+		assert.Equal(t, dto.ImportCounts{Updated: 1, Skipped: 1}, report.Quizzes)
+		statusRepo.AssertNotCalled(t, "ChangeQuizStatus", mock.Anything, mock.Anything)
+		quizRepo.AssertNotCalled(t, "UpdateQuiz", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Import With A Status A Quiz Cannot Move To Saves Nothing", func(t *testing.T) {
+		svc, quizRepo, statusRepo, categoryRepo, cache := newTestContentServiceWithStatus()
+		expectBackwardStatuses(quizRepo, categoryRepo)
+		quizRepo.On("UpdateQuiz", ctx, mock.AnythingOfType("*domain.Quiz"), "admin-1").Return(nil)
+
+		report, err := svc.ImportContent(ctx, data, ContentFormatJSON, false, "admin-1")
+		require.NoError(t, err)
+		assert.Equal(t, backwardStatusErrors, report.Errors)
+		assert.Equal(t, dto.ImportCounts{}, report.Quizzes, "the transaction was rolled back")
+		statusRepo.AssertNotCalled(t, "ChangeQuizStatus", mock.Anything, mock.Anything)
+		cache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Dry Run Writes Nothing", func(t *testing.T) {
+		svc, quizRepo, categoryRepo, cache := newTestContentService()
+		categoryRepo.On("GetByName", ctx, "Backend").Return(&domain.Category{ID: "cat-1", Name: "Backend", Description: "Server side"}, nil)
+		categoryRepo.On("GetByName", ctx, "Frontend").Return(nil, nil)
+		categoryRepo.On("GetByNameAndCategoryID", ctx, "Databases", "cat-1").Return(&domain.SubCategory{ID: "sub-1", CategoryID: "cat-1", Name: "Databases"}, nil)
+		categoryRepo.On("GetByNameAndCategoryID", ctx, "Caching", "cat-1").Return(nil, nil)
+		quizRepo.On("GetQuizzesBySubCategory", ctx, "sub-1").Return([]*domain.Quiz{}, nil)
+
+		report, err := svc.ImportContent(ctx, data, ContentFormatJSON, true, "admin-1")
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, dto.ImportCounts{Created: 1, Skipped: 1}, report.Categories)
+		assert.Equal(t, dto.ImportCounts{Created: 1, Updated: 1}, report.SubCategories)
+		assert.Equal(t, dto.ImportCounts{Created: 2}, report.Quizzes)
+		assert.Equal(t, dto.ImportCounts{Created: 1}, report.Evaluations)
+		categoryRepo.AssertNotCalled(t, "SaveCategory", mock.Anything, mock.Anything)
+		categoryRepo.AssertNotCalled(t, "UpdateSubCategory", mock.Anything, mock.Anything)
+		quizRepo.AssertNotCalled(t, "SaveQuiz", mock.Anything, mock.Anything)
+		quizRepo.AssertNotCalled(t, "SaveQuizEvaluation", mock.Anything, mock.Anything)
+		cache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Reports Every Invalid Row And Writes Nothing", func(t *testing.T) {
+		svc, quizRepo, categoryRepo, _ := newTestContentService()
+		bundle := testContentBundle()
+		quizzes := bundle.Categories[0].SubCategories[0].Quizzes
+		quizzes[0].Evaluation.ScoreEvaluations = nil
+		quizzes[1].Question, quizzes[1].Difficulty = " What is an index? ", "trivial"
+		bundle.Categories[1].Name = "Backend"
+		invalid, err := encodeContentBundle(bundle, ContentFormatJSON)
+		require.NoError(t, err)
+
+		report, err := svc.ImportContent(ctx, invalid, ContentFormatJSON, true, "admin-1")
+		require.NoError(t, err)
+		assert.Equal(t, []dto.ImportRowError{
+			{Row: "categories[0].sub_categories[0].quizzes[0]", Message: "invalid evaluation: score evaluations must correspond to defined score ranges in count"},
+			{Row: "categories[0].sub_categories[0].quizzes[1]", Message: `question "What is an index?" appears more than once in subcategory "Databases"`},
+			{Row: "categories[0].sub_categories[0].quizzes[1]", Message: `difficulty "trivial" must be easy, medium or hard`},
+			{Row: "categories[1]", Message: `category "Backend" appears more than once`},
+		}, report.Errors)
+		categoryRepo.AssertNotCalled(t, "GetByName", mock.Anything, mock.Anything)
+		quizRepo.AssertNotCalled(t, "SaveQuiz", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Rejects An Unknown Format", func(t *testing.T) {
+		svc, _, _, _ := newTestContentService()
+		_, err := svc.ImportContent(ctx, data, "xml", false, "admin-1")
+		assertDomainErrorCode(t, err, domain.CodeValidation)
+	})
+}
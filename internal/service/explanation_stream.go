@@ -0,0 +1,83 @@
+package service
+
+import "sync"
+
+// explanationStream buffers the explanation chunks of one in-flight evaluation so that every
+// caller sharing its singleflight key can replay what was produced so far and follow the rest.
+type explanationStream struct {
+	mu      sync.Mutex
+	chunks  []string
+	changed chan struct{} // Closed and replaced on every publish
+	refs    int           // Guarded by explanationStreams.mu
+}
+
+// reset drops chunks left over from an earlier evaluation under the same key. The singleflight
+// leader calls it before evaluating, so only one evaluation writes to a stream at a time.
+func (s *explanationStream) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunks = nil
+}
+
+func (s *explanationStream) publish(chunk string) {
+	if chunk == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunks = append(s.chunks, chunk)
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// since returns the chunks after the first n and a channel that is closed on the next publish.
+func (s *explanationStream) since(n int) ([]string, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n > len(s.chunks) { // The stream was reset for a newer evaluation
+		return nil, s.changed
+	}
+	return append([]string(nil), s.chunks[n:]...), s.changed
+}
+
+// explanationStreams hands out one explanationStream per singleflight key.
+type explanationStreams struct {
+	mu      sync.Mutex
+	streams map[string]*explanationStream
+}
+
+func newExplanationStreams() *explanationStreams {
+	return &explanationStreams{streams: make(map[string]*explanationStream)}
+}
+
+// acquire returns the stream for key, creating it if needed. Every acquire must be paired with a release.
+func (h *explanationStreams) acquire(key string) *explanationStream {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.streams[key]
+	if !ok {
+		s = &explanationStream{changed: make(chan struct{})}
+		h.streams[key] = s
+	}
+	s.refs++
+	return s
+}
+
+func (h *explanationStreams) release(key string, s *explanationStream) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s.refs--
+	if s.refs == 0 && h.streams[key] == s {
+		delete(h.streams, key)
+	}
+}
+
+// refCount reports how many callers hold the stream for key.
+func (h *explanationStreams) refCount(key string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.streams[key]; ok {
+		return s.refs
+	}
+	return 0
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/logger"
+	"quiz-byte/internal/port"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// googleRevokeTimeout bounds the call to Google's revocation endpoint during account deletion.
+const googleRevokeTimeout = 10 * time.Second
+
+// A failed refresh is tried again after googleRefreshRetryDelay, doubled for every further failure up
+// to googleRefreshMaxRetryDelay, so tokens that keep failing do not hold up the other expiring ones.
+const (
+	googleRefreshRetryDelay    = 5 * time.Minute
+	googleRefreshMaxRetryDelay = 12 * time.Hour
+)
+
+// encryptGoogleToken encrypts a token from Google for storage. UserID is left for the caller.
+func (s *authServiceImpl) encryptGoogleToken(token *oauth2.Token) (*domain.GoogleTokens, error) {
+	encryptedAccessToken, err := s.EncryptToken(token.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt access token: %w", err)
+	}
+	encryptedRefreshToken, err := s.EncryptToken(token.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt refresh token: %w", err)
+	}
+	return &domain.GoogleTokens{
+		EncryptedAccessToken:  encryptedAccessToken,
+		EncryptedRefreshToken: encryptedRefreshToken,
+		ExpiresAt:             token.Expiry,
+	}, nil
+}
+
+// RefreshExpiringGoogleTokens implements AuthService. A grant that Google no longer accepts
+// (the user removed the app's access) is dropped, as are tokens that cannot be decrypted anymore;
+// other failures are retried with a growing delay.
+func (s *authServiceImpl) RefreshExpiringGoogleTokens(ctx context.Context, expiringBefore time.Time, limit int) (int, error) {
+	google, ok := s.providers[domain.IdentityProviderGoogle].(port.TokenRefresher)
+	if !ok {
+		return 0, nil // Google sign-in is not configured, so no tokens are stored
+	}
+	expiring, err := s.userRepo.ListExpiringGoogleTokens(ctx, expiringBefore, limit)
+	if err != nil {
+		return 0, domain.NewInternalError("failed to list expiring google tokens", err)
+	}
+
+	refreshed := 0
+	for i := range expiring {
+		if ctx.Err() != nil {
+			return refreshed, ctx.Err()
+		}
+		if s.refreshGoogleToken(ctx, google, &expiring[i]) {
+			refreshed++
+		}
+	}
+	return refreshed, nil
+}
+
+func (s *authServiceImpl) refreshGoogleToken(ctx context.Context, google port.TokenRefresher, stored *domain.GoogleTokens) bool {
+	appLogger := logger.Get()
+	refreshToken, err := s.DecryptToken(stored.EncryptedRefreshToken)
+	if err != nil {
+		// E.g. encrypted with a key that was replaced; it can never be refreshed, so the user signs in again.
+		appLogger.Error("Failed to decrypt stored google refresh token, dropping stored tokens", zap.String("userID", stored.UserID), zap.Error(err))
+		if err := s.userRepo.ClearGoogleTokens(ctx, stored.UserID); err != nil {
+			appLogger.Error("Failed to clear google tokens", zap.String("userID", stored.UserID), zap.Error(err))
+		}
+		return false
+	}
+
+	token, err := google.RefreshToken(ctx, refreshToken)
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+			appLogger.Info("Google grant is no longer valid, dropping stored tokens", zap.String("userID", stored.UserID))
+			if err := s.userRepo.ClearGoogleTokens(ctx, stored.UserID); err != nil {
+				appLogger.Error("Failed to clear google tokens", zap.String("userID", stored.UserID), zap.Error(err))
+			}
+			return false
+		}
+		appLogger.Warn("Failed to refresh google token, will retry", zap.String("userID", stored.UserID), zap.Int("failures", stored.RefreshFailures+1), zap.Error(err))
+		s.postponeGoogleRefresh(ctx, stored)
+		return false
+	}
+
+	if token.RefreshToken == refreshToken {
+		token.RefreshToken = "" // Unchanged; keeps the stored one without encrypting it again
+	}
+	renewed, err := s.encryptGoogleToken(token)
+	if err != nil {
+		appLogger.Error("Failed to encrypt refreshed google token", zap.String("userID", stored.UserID), zap.Error(err))
+		s.postponeGoogleRefresh(ctx, stored)
+		return false
+	}
+	renewed.UserID = stored.UserID
+	if err := s.userRepo.UpdateGoogleTokens(ctx, renewed); err != nil {
+		appLogger.Error("Failed to store refreshed google token", zap.String("userID", stored.UserID), zap.Error(err))
+		s.postponeGoogleRefresh(ctx, stored)
+		return false
+	}
+	appLogger.Debug("Google token refreshed", zap.String("userID", stored.UserID), zap.Time("expiresAt", token.Expiry))
+	return true
+}
+
+// postponeGoogleRefresh records the failed refresh, so the tokens are only tried again after a delay.
+func (s *authServiceImpl) postponeGoogleRefresh(ctx context.Context, stored *domain.GoogleTokens) {
+	delay := googleRefreshRetryDelay
+	for i := 0; i < stored.RefreshFailures && delay < googleRefreshMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > googleRefreshMaxRetryDelay {
+		delay = googleRefreshMaxRetryDelay
+	}
+	if err := s.userRepo.RecordGoogleTokenRefreshFailure(ctx, stored.UserID, time.Now().Add(delay)); err != nil {
+		logger.Get().Error("Failed to record google token refresh failure", zap.String("userID", stored.UserID), zap.Error(err))
+	}
+}
+
+// DeleteAccount implements AuthService. Sessions are revoked before the account is deleted, so a
+// failed revocation leaves the account in place and the request can be retried. The account is
+// deleted even when Google cannot be reached; the grant then stays listed in the user's Google
+// account until they remove it there.
+func (s *authServiceImpl) DeleteAccount(ctx context.Context, userID string) error {
+	appLogger := logger.Get()
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to get user %s", userID), err)
+	}
+	if user == nil {
+		return domain.NewNotFoundError("user not found")
+	}
+	googleTokens, err := s.userRepo.GetGoogleTokens(ctx, userID)
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to get google tokens of user %s", userID), err)
+	}
+
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID, time.Now())
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to list sessions of user %s", userID), err)
+	}
+	if _, err := s.revokeSessions(ctx, sessions, "", domain.SessionRevokedAccountDeleted); err != nil {
+		return err
+	}
+
+	err = s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		deleted, err := s.userRepo.DeleteUser(txCtx, userID, time.Now())
+		if err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to delete user %s", userID), err)
+		}
+		if !deleted {
+			return domain.NewNotFoundError("user not found")
+		}
+		// Unlinked provider accounts can sign up again
+		if err := s.identityRepo.DeleteIdentitiesByUserID(txCtx, userID); err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to unlink identities of user %s", userID), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	appLogger.Info("User account deleted", zap.String("userID", userID))
+
+	if googleTokens != nil {
+		if err := s.revokeGoogleGrant(ctx, googleTokens); err != nil {
+			appLogger.Warn("Failed to revoke google grant of deleted account", zap.String("userID", userID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// revokeGoogleGrant revokes the app's access at Google. Revoking the refresh token revokes the
+// whole grant, so the access token is only used when there is no refresh token.
+func (s *authServiceImpl) revokeGoogleGrant(ctx context.Context, stored *domain.GoogleTokens) error {
+	encryptedToken := stored.EncryptedRefreshToken
+	if encryptedToken == "" {
+		encryptedToken = stored.EncryptedAccessToken
+	}
+	token, err := s.DecryptToken(encryptedToken)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, googleRevokeTimeout)
+	defer cancel()
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.authCfg.GoogleOAuth.RevokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create revoke request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call google revoke endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("google revoke endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// GoogleTokenRefresher renews stored Google access tokens in the background before they expire.
+type GoogleTokenRefresher interface {
+	// Shutdown stops the refresher. When ctx is done before the running refresh has finished,
+	// the refresh is canceled and ctx.Err() is returned.
+	Shutdown(ctx context.Context) error
+}
+
+type googleTokenRefresher struct {
+	authService AuthService
+	settings    config.GoogleTokenRefreshConfig
+
+	stop       chan struct{}
+	stopOnce   sync.Once
+	stopped    chan struct{} // Closed when the refresh loop has returned
+	workCtx    context.Context
+	cancelWork context.CancelFunc
+}
+
+// NewGoogleTokenRefresher creates the refresher and starts its loop.
+func NewGoogleTokenRefresher(authService AuthService, settings config.GoogleTokenRefreshConfig) GoogleTokenRefresher {
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	r := &googleTokenRefresher{
+		authService: authService,
+		settings:    settings,
+		stop:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+		workCtx:     workCtx,
+		cancelWork:  cancelWork,
+	}
+	go r.run()
+	return r
+}
+
+// Shutdown implements GoogleTokenRefresher.
+func (r *googleTokenRefresher) Shutdown(ctx context.Context) error {
+	r.stopOnce.Do(func() { close(r.stop) })
+	defer r.cancelWork()
+
+	select {
+	case <-r.stopped:
+		return nil
+	case <-ctx.Done():
+		logger.Get().Warn("Google token refresher did not stop before shutdown deadline")
+		return ctx.Err()
+	}
+}
+
+func (r *googleTokenRefresher) run() {
+	defer close(r.stopped)
+	ticker := time.NewTicker(max(r.settings.Interval, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		r.refresh()
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *googleTokenRefresher) refresh() {
+	refreshed, err := r.authService.RefreshExpiringGoogleTokens(r.workCtx, time.Now().Add(r.settings.RefreshBefore), max(r.settings.BatchSize, 1))
+	if err != nil {
+		logger.Get().Error("Failed to refresh expiring google tokens", zap.Error(err))
+		return
+	}
+	if refreshed > 0 {
+		logger.Get().Info("Refreshed expiring google tokens", zap.Int("count", refreshed))
+	}
+}
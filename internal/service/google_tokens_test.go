@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/adapter/identity"
+	"quiz-byte/internal/adapter/identity/identitytest"
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func newGoogleTestAuthService(t *testing.T, google *identitytest.Server, userRepo domain.UserRepository, identities domain.UserIdentityRepository, sessions domain.UserSessionRepository) *authServiceImpl {
+	t.Helper()
+	authCfg := config.AuthConfig{
+		TokenEncryptionKey: testTokenEncryptionKey,
+		GoogleOAuth:        google.GoogleConfig(),
+		JWT:                config.JWTConfig{AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: 7 * 24 * time.Hour},
+	}
+	providers := []port.IdentityProvider{identity.NewGoogleProvider(authCfg.GoogleOAuth)}
+	authService, err := NewAuthService(userRepo, identities, sessions, nil, newMapCache(), providers, authCfg, directTxManager{})
+	require.NoError(t, err)
+	return authService.(*authServiceImpl)
+}
+
+func TestAuthService_HandleGoogleCallback_StoresGoogleTokens(t *testing.T) {
+	ctx := context.Background()
+	google := identitytest.NewServer(t)
+	mockUserRepo := new(MockUserRepository)
+	identities := newMemoryIdentityRepository()
+	authService := newGoogleTestAuthService(t, google, mockUserRepo, identities, newMemorySessionRepository())
+
+	mockUserRepo.On("GetUserByEmail", mock.Anything, "user@example.com").Return(nil, nil)
+	mockUserRepo.On("CreateUser", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+	var stored *domain.GoogleTokens
+	mockUserRepo.On("UpdateGoogleTokens", mock.Anything, mock.AnythingOfType("*domain.GoogleTokens")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*domain.GoogleTokens) }).
+		Return(nil)
+
+	loginURL, flow, err := authService.LoginURL(ctx, domain.IdentityProviderGoogle)
+	require.NoError(t, err)
+	code := google.Authorize(t, loginURL)
+	accessToken, _, user, err := authService.HandleOAuthCallback(ctx, domain.IdentityProviderGoogle, code, flow.State, *flow, dto.SessionClientInfo{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, accessToken)
+	assert.Equal(t, "user@example.com", user.Email)
+	linked, err := identities.GetIdentity(ctx, domain.IdentityProviderGoogle, "user-1")
+	require.NoError(t, err)
+	require.NotNil(t, linked)
+	assert.Equal(t, user.ID, linked.UserID)
+
+	require.NotNil(t, stored)
+	assert.Equal(t, user.ID, stored.UserID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+	decrypted, err := authService.DecryptToken(stored.EncryptedAccessToken)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(decrypted, "access-"))
+	assert.NotContains(t, stored.EncryptedAccessToken, decrypted)
+	decrypted, err = authService.DecryptToken(stored.EncryptedRefreshToken)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(decrypted, "refresh-"))
+}
+
+func TestAuthService_RefreshExpiringGoogleTokens(t *testing.T) {
+	ctx := context.Background()
+	google := identitytest.NewServer(t)
+	mockUserRepo := new(MockUserRepository)
+	authService := newGoogleTestAuthService(t, google, mockUserRepo, nil, nil)
+
+	encrypt := func(token string) string {
+		encrypted, err := authService.EncryptToken(token)
+		require.NoError(t, err)
+		return encrypted
+	}
+	before := time.Now().Add(10 * time.Minute)
+	mockUserRepo.On("ListExpiringGoogleTokens", ctx, before, 50).Return([]domain.GoogleTokens{
+		{UserID: "user-valid", EncryptedAccessToken: encrypt("old-access"), EncryptedRefreshToken: encrypt(google.IssueRefreshToken())},
+		{UserID: "user-revoked", EncryptedAccessToken: encrypt("old-access"), EncryptedRefreshToken: encrypt("revoked-refresh")},
+	}, nil)
+	var renewed *domain.GoogleTokens
+	mockUserRepo.On("UpdateGoogleTokens", ctx, mock.AnythingOfType("*domain.GoogleTokens")).
+		Run(func(args mock.Arguments) { renewed = args.Get(1).(*domain.GoogleTokens) }).
+		Return(nil).Once()
+	mockUserRepo.On("ClearGoogleTokens", ctx, "user-revoked").Return(nil).Once()
+
+	refreshed, err := authService.RefreshExpiringGoogleTokens(ctx, before, 50)
+	require.NoError(t, err)
+	assert.Equal(t, 1, refreshed)
+	mockUserRepo.AssertExpectations(t)
+
+	require.NotNil(t, renewed)
+	assert.Equal(t, "user-valid", renewed.UserID)
+	assert.Empty(t, renewed.EncryptedRefreshToken, "an unchanged refresh token is kept as stored")
+	decrypted, err := authService.DecryptToken(renewed.EncryptedAccessToken)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(decrypted, "access-"))
+}
+
+// failingTokenRefresher is a port.TokenRefresher whose token endpoint is down.
+type failingTokenRefresher struct{}
+
+func (failingTokenRefresher) RefreshToken(context.Context, string) (*oauth2.Token, error) {
+	return nil, errors.New("token endpoint returned status 503")
+}
+
+func TestAuthService_RefreshGoogleToken_Failures(t *testing.T) {
+	ctx := context.Background()
+	google := identitytest.NewServer(t)
+
+	t.Run("Undecryptable Tokens Are Dropped", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		authService := newGoogleTestAuthService(t, google, mockUserRepo, nil, nil)
+		mockUserRepo.On("ClearGoogleTokens", ctx, "user-old-key").Return(nil).Once()
+
+		stored := &domain.GoogleTokens{UserID: "user-old-key", EncryptedRefreshToken: "c2VhbGVkIHdpdGggYW4gb2xkIGtleQ=="}
+		assert.False(t, authService.refreshGoogleToken(ctx, failingTokenRefresher{}, stored))
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed Refreshes Are Postponed With A Growing Delay", func(t *testing.T) {
+		tests := []struct {
+			failures int
+			delay    time.Duration
+		}{
+			{failures: 0, delay: googleRefreshRetryDelay},
+			{failures: 3, delay: 8 * googleRefreshRetryDelay},
+			{failures: 20, delay: googleRefreshMaxRetryDelay},
+		}
+		for _, tt := range tests {
+			mockUserRepo := new(MockUserRepository)
+			authService := newGoogleTestAuthService(t, google, mockUserRepo, nil, nil)
+			encryptedRefresh, err := authService.EncryptToken("google-refresh-1")
+			require.NoError(t, err)
+			var retryAt time.Time
+			mockUserRepo.On("RecordGoogleTokenRefreshFailure", ctx, "user-1", mock.AnythingOfType("time.Time")).
+				Run(func(args mock.Arguments) { retryAt = args.Get(2).(time.Time) }).
+				Return(nil).Once()
+
+			stored := &domain.GoogleTokens{UserID: "user-1", EncryptedRefreshToken: encryptedRefresh, RefreshFailures: tt.failures}
+			assert.False(t, authService.refreshGoogleToken(ctx, failingTokenRefresher{}, stored))
+			mockUserRepo.AssertExpectations(t)
+			assert.WithinDuration(t, time.Now().Add(tt.delay), retryAt, time.Minute, "after %d failures", tt.failures)
+			mockUserRepo.AssertNotCalled(t, "ClearGoogleTokens", mock.Anything, mock.Anything)
+		}
+	})
+}
+
+func TestAuthService_DeleteAccount(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Revokes Google Grant And Sessions", func(t *testing.T) {
+		google := identitytest.NewServer(t)
+		mockUserRepo := new(MockUserRepository)
+		sessions := newMemorySessionRepository()
+		identities := newMemoryIdentityRepository()
+		authService := newGoogleTestAuthService(t, google, mockUserRepo, identities, sessions)
+		user := &domain.User{ID: "user123"}
+		require.NoError(t, identities.CreateIdentity(ctx, &domain.UserIdentity{ID: "identity1", UserID: "user123", Provider: domain.IdentityProviderGoogle, Subject: "user-1"}))
+		_, refreshToken, err := authService.startSession(ctx, user, dto.SessionClientInfo{})
+		require.NoError(t, err)
+
+		encryptedRefresh, err := authService.EncryptToken("google-refresh-1")
+		require.NoError(t, err)
+		mockUserRepo.On("GetUserByID", ctx, "user123").Return(user, nil)
+		mockUserRepo.On("GetGoogleTokens", ctx, "user123").Return(&domain.GoogleTokens{UserID: "user123", EncryptedRefreshToken: encryptedRefresh}, nil)
+		mockUserRepo.On("DeleteUser", ctx, "user123", mock.AnythingOfType("time.Time")).Return(true, nil)
+
+		require.NoError(t, authService.DeleteAccount(ctx, "user123"))
+		assert.Equal(t, []string{"google-refresh-1"}, google.Revoked())
+		linked, err := identities.GetIdentity(ctx, domain.IdentityProviderGoogle, "user-1")
+		require.NoError(t, err)
+		assert.Nil(t, linked, "the Google account can sign up again")
+
+		claims, err := authService.ValidateJWT(ctx, refreshToken)
+		require.NoError(t, err)
+		session, err := sessions.GetSessionByID(ctx, claims.SessionID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.SessionRevokedAccountDeleted, session.RevokedReason)
+	})
+
+	t.Run("Deletes Even When Google Is Unreachable", func(t *testing.T) {
+		google := identitytest.NewServer(t)
+		mockUserRepo := new(MockUserRepository)
+		authService := newGoogleTestAuthService(t, google, mockUserRepo, newMemoryIdentityRepository(), newMemorySessionRepository())
+		google.Close()
+
+		encryptedAccess, err := authService.EncryptToken("google-access-1")
+		require.NoError(t, err)
+		mockUserRepo.On("GetUserByID", ctx, "user123").Return(&domain.User{ID: "user123"}, nil)
+		mockUserRepo.On("GetGoogleTokens", ctx, "user123").Return(&domain.GoogleTokens{UserID: "user123", EncryptedAccessToken: encryptedAccess}, nil)
+		mockUserRepo.On("DeleteUser", ctx, "user123", mock.AnythingOfType("time.Time")).Return(true, nil)
+
+		assert.NoError(t, authService.DeleteAccount(ctx, "user123"))
+	})
+
+	t.Run("Unknown User", func(t *testing.T) {
+		google := identitytest.NewServer(t)
+		mockUserRepo := new(MockUserRepository)
+		authService := newGoogleTestAuthService(t, google, mockUserRepo, newMemoryIdentityRepository(), newMemorySessionRepository())
+
+		mockUserRepo.On("GetUserByID", ctx, "missing").Return(nil, nil)
+
+		assert.ErrorIs(t, authService.DeleteAccount(ctx, "missing"), domain.ErrNotFound)
+		assert.Empty(t, google.Revoked())
+		mockUserRepo.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Keeps The Account When Sessions Cannot Be Revoked", func(t *testing.T) {
+		google := identitytest.NewServer(t)
+		mockUserRepo := new(MockUserRepository)
+		sessions := newMemorySessionRepository()
+		authService := newGoogleTestAuthService(t, google, mockUserRepo, newMemoryIdentityRepository(), sessions)
+		user := &domain.User{ID: "user123"}
+		_, refreshToken, err := authService.startSession(ctx, user, dto.SessionClientInfo{})
+		require.NoError(t, err)
+		denylist := authService.tokenDenylist
+		authService.tokenDenylist = &ManualMockCache{GetFunc: denylist.Get, SetFunc: func(context.Context, string, string, time.Duration) error {
+			return errors.New("redis is down")
+		}}
+		mockUserRepo.On("GetUserByID", ctx, "user123").Return(user, nil)
+		mockUserRepo.On("GetGoogleTokens", ctx, "user123").Return(nil, nil)
+
+		assertDomainErrorCode(t, authService.DeleteAccount(ctx, "user123"), domain.CodeInternal)
+		mockUserRepo.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything, mock.Anything)
+
+		// The retry revokes the sessions and deletes the account
+		authService.tokenDenylist = denylist
+		mockUserRepo.On("DeleteUser", ctx, "user123", mock.AnythingOfType("time.Time")).Return(true, nil)
+		require.NoError(t, authService.DeleteAccount(ctx, "user123"))
+		claims, err := authService.ValidateJWT(ctx, refreshToken)
+		require.NoError(t, err)
+		session, err := sessions.GetSessionByID(ctx, claims.SessionID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.SessionRevokedAccountDeleted, session.RevokedReason)
+	})
+}
+
+func TestGoogleTokenRefresher_RunsUntilShutdown(t *testing.T) {
+	mockAuth := &refreshCountingAuthService{}
+	refresher := NewGoogleTokenRefresher(mockAuth, config.GoogleTokenRefreshConfig{Interval: 5 * time.Millisecond, RefreshBefore: time.Minute, BatchSize: 10})
+
+	require.Eventually(t, func() bool { return mockAuth.count() >= 2 }, time.Second, time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, refresher.Shutdown(ctx))
+
+	calls := mockAuth.count()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, calls, mockAuth.count(), "no refresh after shutdown")
+}
+
+// refreshCountingAuthService counts RefreshExpiringGoogleTokens calls; other methods are not used.
+type refreshCountingAuthService struct {
+	AuthService
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *refreshCountingAuthService) RefreshExpiringGoogleTokens(_ context.Context, _ time.Time, _ int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return 0, nil
+}
+
+func (s *refreshCountingAuthService) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
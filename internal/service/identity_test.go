@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// memoryIdentityRepository is an in-memory domain.UserIdentityRepository.
+type memoryIdentityRepository struct {
+	mu         sync.Mutex
+	identities map[string]domain.UserIdentity // By provider and subject
+}
+
+func newMemoryIdentityRepository() *memoryIdentityRepository {
+	return &memoryIdentityRepository{identities: make(map[string]domain.UserIdentity)}
+}
+
+func (r *memoryIdentityRepository) GetIdentity(_ context.Context, provider string, subject string) (*domain.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	identity, ok := r.identities[provider+"|"+subject]
+	if !ok {
+		return nil, nil
+	}
+	return &identity, nil
+}
+
+func (r *memoryIdentityRepository) CreateIdentity(_ context.Context, identity *domain.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := identity.Provider + "|" + identity.Subject
+	if _, dup := r.identities[key]; dup {
+		return fmt.Errorf("identity %s already exists", key)
+	}
+	r.identities[key] = *identity
+	return nil
+}
+
+func (r *memoryIdentityRepository) UpdateIdentityLogin(_ context.Context, identityID string, email string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, identity := range r.identities {
+		if identity.ID == identityID {
+			identity.Email, identity.LastLoginAt = email, at
+			r.identities[key] = identity
+			return nil
+		}
+	}
+	return fmt.Errorf("identity %s not found", identityID)
+}
+
+func (r *memoryIdentityRepository) ListIdentitiesByUserID(_ context.Context, userID string) ([]domain.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var identities []domain.UserIdentity
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	sort.Slice(identities, func(i, j int) bool { return identities[i].CreatedAt.Before(identities[j].CreatedAt) })
+	return identities, nil
+}
+
+func (r *memoryIdentityRepository) DeleteIdentitiesByUserID(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, identity := range r.identities {
+		if identity.UserID == userID {
+			delete(r.identities, key)
+		}
+	}
+	return nil
+}
+
+// directTxManager runs fn without a transaction.
+type directTxManager struct{}
+
+func (directTxManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// testLogin is the login the callbacks in the tests belong to.
+var testLogin = dto.OAuthFlow{State: "state", Nonce: "nonce", CodeVerifier: "verifier"}
+
+// stubIdentityProvider is a port.IdentityProvider that signs in the configured account for "good-code".
+type stubIdentityProvider struct {
+	name    string
+	account port.ExternalIdentity
+}
+
+func (p *stubIdentityProvider) Name() string { return p.name }
+
+func (p *stubIdentityProvider) AuthCodeURL(_ context.Context, req port.AuthRequest) (string, error) {
+	return "https://" + p.name + ".example.com/authorize?state=" + req.State, nil
+}
+
+func (p *stubIdentityProvider) Exchange(_ context.Context, code string, req port.AuthRequest) (*port.ExternalIdentity, *oauth2.Token, error) {
+	if req != port.AuthRequest(testLogin) {
+		return nil, nil, fmt.Errorf("%w: login secrets do not match", port.ErrInvalidIDToken)
+	}
+	switch code {
+	case "good-code":
+		account := p.account
+		return &account, &oauth2.Token{AccessToken: p.name + "-access"}, nil
+	case "provider-down":
+		return nil, nil, errors.New("userinfo endpoint returned status 503")
+	default:
+		return nil, nil, fmt.Errorf("%w: invalid_grant", port.ErrCodeExchange)
+	}
+}
+
+func newIdentityTestAuthService(t *testing.T, userRepo domain.UserRepository, identities domain.UserIdentityRepository, providers ...port.IdentityProvider) *authServiceImpl {
+	t.Helper()
+	authCfg := config.AuthConfig{
+		TokenEncryptionKey: testTokenEncryptionKey,
+		JWT:                config.JWTConfig{AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: 7 * 24 * time.Hour},
+	}
+	authService, err := NewAuthService(userRepo, identities, newMemorySessionRepository(), nil, nil, providers, authCfg, directTxManager{})
+	require.NoError(t, err)
+	return authService.(*authServiceImpl)
+}
+
+func TestAuthService_HandleOAuthCallback_SignUpThenSignIn(t *testing.T) {
+	ctx := context.Background()
+	github := &stubIdentityProvider{name: domain.IdentityProviderGitHub, account: port.ExternalIdentity{
+		Provider: domain.IdentityProviderGitHub, Subject: "583231", Email: "octocat@example.com", EmailVerified: true, Name: "octocat",
+	}}
+	mockUserRepo := new(MockUserRepository)
+	identities := newMemoryIdentityRepository()
+	authService := newIdentityTestAuthService(t, mockUserRepo, identities, github)
+
+	var created *domain.User
+	mockUserRepo.On("GetUserByEmail", mock.Anything, "octocat@example.com").Return(nil, nil).Once()
+	mockUserRepo.On("CreateUser", mock.Anything, mock.AnythingOfType("*domain.User")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*domain.User) }).
+		Return(nil).Once()
+
+	_, _, user, err := authService.HandleOAuthCallback(ctx, "github", "good-code", "state", testLogin, dto.SessionClientInfo{})
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	assert.Equal(t, created.ID, user.ID)
+	assert.Equal(t, "octocat", user.Name)
+
+	// The second sign-in finds the user through the linked identity.
+	mockUserRepo.On("GetUserByID", mock.Anything, created.ID).Return(created, nil)
+	mockUserRepo.On("UpdateUser", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil).Once()
+	github.account.Email = "octocat@new.example.com"
+
+	_, _, user, err = authService.HandleOAuthCallback(ctx, "github", "good-code", "state", testLogin, dto.SessionClientInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, user.ID)
+	assert.Equal(t, "octocat@example.com", user.Email, "the user's email is not taken from the provider")
+	mockUserRepo.AssertExpectations(t)
+
+	linked, err := identities.GetIdentity(ctx, "github", "583231")
+	require.NoError(t, err)
+	assert.Equal(t, "octocat@new.example.com", linked.Email)
+}
+
+func TestAuthService_HandleOAuthCallback_LinksExistingUser(t *testing.T) {
+	ctx := context.Background()
+	existing := &domain.User{ID: "user123", Email: "user@corp.example", Name: "Jane"}
+
+	t.Run("Verified Email", func(t *testing.T) {
+		corp := &stubIdentityProvider{name: "corp", account: port.ExternalIdentity{
+			Provider: "corp", Subject: "corp-user-1", Email: "user@corp.example", EmailVerified: true,
+		}}
+		mockUserRepo := new(MockUserRepository)
+		identities := newMemoryIdentityRepository()
+		authService := newIdentityTestAuthService(t, mockUserRepo, identities, corp)
+		mockUserRepo.On("GetUserByEmail", mock.Anything, "user@corp.example").Return(existing, nil)
+		mockUserRepo.On("UpdateUser", mock.Anything, existing).Return(nil)
+
+		_, _, user, err := authService.HandleOAuthCallback(ctx, "corp", "good-code", "state", testLogin, dto.SessionClientInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, "user123", user.ID)
+		assert.Equal(t, "Jane", user.Name, "an empty name from the provider keeps the profile")
+
+		resp, err := authService.ListIdentities(ctx, "user123")
+		require.NoError(t, err)
+		if assert.Len(t, resp.Identities, 1) {
+			assert.Equal(t, "corp", resp.Identities[0].Provider)
+			assert.Equal(t, "user@corp.example", resp.Identities[0].Email)
+		}
+	})
+
+	t.Run("Unverified Email", func(t *testing.T) {
+		github := &stubIdentityProvider{name: "github", account: port.ExternalIdentity{
+			Provider: "github", Subject: "583231", Email: "user@corp.example",
+		}}
+		mockUserRepo := new(MockUserRepository)
+		identities := newMemoryIdentityRepository()
+		authService := newIdentityTestAuthService(t, mockUserRepo, identities, github)
+		mockUserRepo.On("GetUserByEmail", mock.Anything, "user@corp.example").Return(existing, nil)
+
+		_, _, _, err := authService.HandleOAuthCallback(ctx, "github", "good-code", "state", testLogin, dto.SessionClientInfo{})
+		assert.ErrorIs(t, err, ErrIdentityEmailConflict)
+		linked, _ := identities.GetIdentity(ctx, "github", "583231")
+		assert.Nil(t, linked)
+	})
+}
+
+// memoryUserRepository keeps users in memory and finds them by their stored email, like the users table.
+type memoryUserRepository struct {
+	*MockUserRepository
+	users map[string]domain.User // By ID
+}
+
+func (r *memoryUserRepository) CreateUser(_ context.Context, user *domain.User) error {
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) GetUserByEmail(_ context.Context, email string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.Email != "" && user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryUserRepository) GetUserByID(_ context.Context, userID string) (*domain.User, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+func (r *memoryUserRepository) UpdateUser(_ context.Context, user *domain.User) error {
+	r.users[user.ID] = *user
+	return nil
+}
+
+func TestAuthService_HandleOAuthCallback_UnverifiedSignUpThenVerifiedLogin(t *testing.T) {
+	ctx := context.Background()
+	// An attacker signs up first with the victim's address, which their provider did not verify.
+	attacker := &stubIdentityProvider{name: "github", account: port.ExternalIdentity{
+		Provider: "github", Subject: "attacker", Email: "victim@corp.example",
+	}}
+	owner := &stubIdentityProvider{name: "corp", account: port.ExternalIdentity{
+		Provider: "corp", Subject: "victim", Email: "victim@corp.example", EmailVerified: true,
+	}}
+	userRepo := &memoryUserRepository{MockUserRepository: new(MockUserRepository), users: make(map[string]domain.User)}
+	identities := newMemoryIdentityRepository()
+	authService := newIdentityTestAuthService(t, userRepo, identities, attacker, owner)
+
+	_, _, attackerUser, err := authService.HandleOAuthCallback(ctx, "github", "good-code", "state", testLogin, dto.SessionClientInfo{})
+	require.NoError(t, err)
+	assert.Empty(t, attackerUser.Email, "an unverified email is not stored on the user")
+	linked, err := identities.GetIdentity(ctx, "github", "attacker")
+	require.NoError(t, err)
+	assert.Equal(t, "victim@corp.example", linked.Email)
+
+	_, _, ownerUser, err := authService.HandleOAuthCallback(ctx, "corp", "good-code", "state", testLogin, dto.SessionClientInfo{})
+	require.NoError(t, err)
+	assert.NotEqual(t, attackerUser.ID, ownerUser.ID, "the verified login is not linked to the attacker's user")
+	assert.Equal(t, "victim@corp.example", ownerUser.Email)
+	linked, err = identities.GetIdentity(ctx, "corp", "victim")
+	require.NoError(t, err)
+	assert.Equal(t, ownerUser.ID, linked.UserID)
+
+	// The address now belongs to the verified user, so further unverified sign-ups are refused.
+	attacker.account.Subject = "attacker-2"
+	_, _, _, err = authService.HandleOAuthCallback(ctx, "github", "good-code", "state", testLogin, dto.SessionClientInfo{})
+	assert.ErrorIs(t, err, ErrIdentityEmailConflict)
+}
+
+func TestAuthService_HandleOAuthCallback_Errors(t *testing.T) {
+	ctx := context.Background()
+	github := &stubIdentityProvider{name: "github", account: port.ExternalIdentity{
+		Provider: "github", Subject: "583231", Email: "octocat@example.com", EmailVerified: true,
+	}}
+
+	t.Run("Unknown Provider", func(t *testing.T) {
+		authService := newIdentityTestAuthService(t, new(MockUserRepository), newMemoryIdentityRepository(), github)
+		_, _, err := authService.LoginURL(ctx, "gitlab")
+		assert.ErrorIs(t, err, ErrUnknownIdentityProvider)
+		_, _, _, err = authService.HandleOAuthCallback(ctx, "gitlab", "good-code", "state", testLogin, dto.SessionClientInfo{})
+		assert.ErrorIs(t, err, ErrUnknownIdentityProvider)
+	})
+
+	t.Run("State Mismatch", func(t *testing.T) {
+		authService := newIdentityTestAuthService(t, new(MockUserRepository), newMemoryIdentityRepository(), github)
+		_, _, _, err := authService.HandleOAuthCallback(ctx, "github", "good-code", "other", testLogin, dto.SessionClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidAuthState)
+	})
+
+	t.Run("Login Secrets Missing", func(t *testing.T) {
+		authService := newIdentityTestAuthService(t, new(MockUserRepository), newMemoryIdentityRepository(), github)
+		_, _, _, err := authService.HandleOAuthCallback(ctx, "github", "good-code", "state", dto.OAuthFlow{State: "state"}, dto.SessionClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidAuthState)
+	})
+
+	t.Run("Invalid ID Token", func(t *testing.T) {
+		authService := newIdentityTestAuthService(t, new(MockUserRepository), newMemoryIdentityRepository(), github)
+		replayed := testLogin
+		replayed.Nonce = "another-nonce"
+		_, _, _, err := authService.HandleOAuthCallback(ctx, "github", "good-code", "state", replayed, dto.SessionClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("Rejected Code", func(t *testing.T) {
+		authService := newIdentityTestAuthService(t, new(MockUserRepository), newMemoryIdentityRepository(), github)
+		_, _, _, err := authService.HandleOAuthCallback(ctx, "github", "bad-code", "state", testLogin, dto.SessionClientInfo{})
+		assert.ErrorIs(t, err, ErrFailedToExchangeToken)
+	})
+
+	t.Run("User Info Unavailable", func(t *testing.T) {
+		authService := newIdentityTestAuthService(t, new(MockUserRepository), newMemoryIdentityRepository(), github)
+		_, _, _, err := authService.HandleOAuthCallback(ctx, "github", "provider-down", "state", testLogin, dto.SessionClientInfo{})
+		assert.ErrorIs(t, err, ErrFailedToGetUserInfo)
+	})
+
+	t.Run("Create User Fails", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		identities := newMemoryIdentityRepository()
+		authService := newIdentityTestAuthService(t, mockUserRepo, identities, github)
+		expectedRepoError := errors.New("failed to create user in DB")
+		mockUserRepo.On("GetUserByEmail", mock.Anything, "octocat@example.com").Return(nil, nil)
+		mockUserRepo.On("CreateUser", mock.Anything, mock.AnythingOfType("*domain.User")).Return(expectedRepoError)
+
+		_, _, _, err := authService.HandleOAuthCallback(ctx, "github", "good-code", "state", testLogin, dto.SessionClientInfo{})
+		var domainErr *domain.DomainError
+		if assert.ErrorAs(t, err, &domainErr) {
+			assert.Equal(t, domain.CodeInternal, domainErr.Code)
+		}
+		assert.ErrorIs(t, err, expectedRepoError)
+		linked, _ := identities.GetIdentity(ctx, "github", "583231")
+		assert.Nil(t, linked)
+	})
+}
+
+func TestNewAuthService_IdentityProviders(t *testing.T) {
+	github := &stubIdentityProvider{name: "github"}
+	corp := &stubIdentityProvider{name: "corp"}
+	authService := newIdentityTestAuthService(t, nil, nil, github, corp)
+	assert.Equal(t, []string{"github", "corp"}, authService.IdentityProviders())
+
+	loginURL, flow, err := authService.LoginURL(context.Background(), "corp")
+	require.NoError(t, err)
+	assert.Equal(t, "https://corp.example.com/authorize?state="+flow.State, loginURL)
+	assert.NotEmpty(t, flow.Nonce)
+	assert.GreaterOrEqual(t, len(flow.CodeVerifier), 43, "RFC 7636 requires at least 43 characters")
+
+	_, another, err := authService.LoginURL(context.Background(), "corp")
+	require.NoError(t, err)
+	assert.NotEqual(t, flow.State, another.State)
+	assert.NotEqual(t, flow.Nonce, another.Nonce)
+	assert.NotEqual(t, flow.CodeVerifier, another.CodeVerifier)
+
+	_, err = NewAuthService(nil, nil, nil, nil, nil, []port.IdentityProvider{github, github}, config.AuthConfig{TokenEncryptionKey: testTokenEncryptionKey}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/dto"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	jwtAlgorithmEdDSA = "EdDSA"
+	jwtAlgorithmRS256 = "RS256"
+	minRSAKeyBits     = 2048
+	ephemeralJWTKeyID = "ephemeral"
+)
+
+// jwtKey is one key of a jwtKeySet. signer is nil for verify-only keys.
+type jwtKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signer    crypto.Signer
+	publicKey crypto.PublicKey
+}
+
+// jwtKeySet signs tokens with the active key and verifies them with any configured key,
+// chosen by the kid header, so tokens signed before a key rotation stay valid.
+type jwtKeySet struct {
+	active *jwtKey
+	keys   []*jwtKey // Configuration order, which is also the JWKS order
+	byID   map[string]*jwtKey
+}
+
+// newJWTKeySet loads the configured keys. Without any key it generates an ephemeral Ed25519 key;
+// tokens signed with it are not valid after a restart or on other instances, so the configuration
+// only allows it in development.
+func newJWTKeySet(cfg config.JWTConfig) (ks *jwtKeySet, ephemeral bool, err error) {
+	if len(cfg.SigningKeys) == 0 {
+		key, err := newEphemeralJWTKey()
+		if err != nil {
+			return nil, false, err
+		}
+		return &jwtKeySet{active: key, keys: []*jwtKey{key}, byID: map[string]*jwtKey{key.id: key}}, true, nil
+	}
+
+	ks = &jwtKeySet{byID: make(map[string]*jwtKey, len(cfg.SigningKeys))}
+	for _, keyCfg := range cfg.SigningKeys {
+		key, err := loadJWTKey(keyCfg)
+		if err != nil {
+			return nil, false, err
+		}
+		if _, dup := ks.byID[key.id]; dup {
+			return nil, false, fmt.Errorf("duplicate JWT key id %q", key.id)
+		}
+		ks.keys = append(ks.keys, key)
+		ks.byID[key.id] = key
+	}
+
+	activeKeyID := cfg.ActiveKeyID
+	if activeKeyID == "" && len(ks.keys) == 1 {
+		activeKeyID = ks.keys[0].id
+	}
+	if activeKeyID == "" {
+		return nil, false, errors.New("auth.jwt.active_key_id is required when several JWT keys are configured")
+	}
+	ks.active = ks.byID[activeKeyID]
+	if ks.active == nil {
+		return nil, false, fmt.Errorf("active JWT key %q is not configured", activeKeyID)
+	}
+	if ks.active.signer == nil {
+		return nil, false, fmt.Errorf("active JWT key %q has no private key", activeKeyID)
+	}
+	return ks, false, nil
+}
+
+func newEphemeralJWTKey() (*jwtKey, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate JWT signing key: %w", err)
+	}
+	return &jwtKey{id: ephemeralJWTKeyID, method: jwt.SigningMethodEdDSA, signer: privateKey, publicKey: publicKey}, nil
+}
+
+func loadJWTKey(keyCfg config.JWTKeyConfig) (*jwtKey, error) {
+	if keyCfg.ID == "" {
+		return nil, errors.New("JWT key id is required")
+	}
+	privatePEM, err := pemValue(keyCfg.PrivateKey, keyCfg.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("JWT key %q: %w", keyCfg.ID, err)
+	}
+	publicPEM, err := pemValue(keyCfg.PublicKey, keyCfg.PublicKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("JWT key %q: %w", keyCfg.ID, err)
+	}
+	if len(privatePEM) == 0 && len(publicPEM) == 0 {
+		return nil, fmt.Errorf("JWT key %q has neither a private nor a public key", keyCfg.ID)
+	}
+
+	key := &jwtKey{id: keyCfg.ID}
+	switch keyCfg.Algorithm {
+	case jwtAlgorithmEdDSA:
+		key.method = jwt.SigningMethodEdDSA
+		if len(privatePEM) > 0 {
+			privateKey, err := jwt.ParseEdPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, fmt.Errorf("JWT key %q: invalid Ed25519 private key: %w", keyCfg.ID, err)
+			}
+			key.signer = privateKey.(ed25519.PrivateKey)
+			key.publicKey = key.signer.Public()
+		} else {
+			publicKey, err := jwt.ParseEdPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, fmt.Errorf("JWT key %q: invalid Ed25519 public key: %w", keyCfg.ID, err)
+			}
+			key.publicKey = publicKey
+		}
+	case jwtAlgorithmRS256:
+		key.method = jwt.SigningMethodRS256
+		var publicKey *rsa.PublicKey
+		if len(privatePEM) > 0 {
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, fmt.Errorf("JWT key %q: invalid RSA private key: %w", keyCfg.ID, err)
+			}
+			key.signer = privateKey
+			publicKey = &privateKey.PublicKey
+		} else {
+			if publicKey, err = jwt.ParseRSAPublicKeyFromPEM(publicPEM); err != nil {
+				return nil, fmt.Errorf("JWT key %q: invalid RSA public key: %w", keyCfg.ID, err)
+			}
+		}
+		if publicKey.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("JWT key %q: RSA keys must have at least %d bits", keyCfg.ID, minRSAKeyBits)
+		}
+		key.publicKey = publicKey
+	default:
+		return nil, fmt.Errorf("JWT key %q: unsupported algorithm %q (use %s or %s)", keyCfg.ID, keyCfg.Algorithm, jwtAlgorithmEdDSA, jwtAlgorithmRS256)
+	}
+	return key, nil
+}
+
+// pemValue returns the inline PEM, or the contents of file when no inline PEM is set.
+func pemValue(inline string, file string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	return data, nil
+}
+
+// sign signs the claims with the active key and sets its kid header.
+func (ks *jwtKeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	token.Header["kid"] = ks.active.id
+	return token.SignedString(ks.active.signer)
+}
+
+// verificationKey is a jwt.Keyfunc. It only accepts tokens whose kid names a configured key
+// and whose alg matches that key, so an RSA public key can never be used as an HMAC secret.
+func (ks *jwtKeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key := ks.byID[kid]
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v for key %q", token.Header["alg"], kid)
+	}
+	return key.publicKey, nil
+}
+
+// validMethods lists the algorithms of the configured keys, for jwt.WithValidMethods.
+func (ks *jwtKeySet) validMethods() []string {
+	var methods []string
+	seen := make(map[string]bool)
+	for _, key := range ks.keys {
+		if alg := key.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// jwks returns the public keys of the set as a JSON Web Key Set.
+func (ks *jwtKeySet) jwks() *dto.JWKSet {
+	set := &dto.JWKSet{Keys: make([]dto.JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		jwk := dto.JWK{Use: "sig", Algorithm: key.method.Alg(), KeyID: key.id}
+		switch publicKey := key.publicKey.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
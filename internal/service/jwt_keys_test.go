@@ -0,0 +1,206 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/dto"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateEd25519PEM(t *testing.T) (privatePEM string, publicPEM string) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return marshalKeyPairPEM(t, privateKey, publicKey)
+}
+
+func generateRSAPEM(t *testing.T, bits int) (privatePEM string, publicPEM string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	return marshalKeyPairPEM(t, privateKey, &privateKey.PublicKey)
+}
+
+func marshalKeyPairPEM(t *testing.T, privateKey any, publicKey any) (string, string) {
+	t.Helper()
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+}
+
+func newTestClaims() dto.AuthClaims {
+	now := time.Now()
+	return dto.AuthClaims{
+		UserID:    "user123",
+		TokenType: tokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   "user123",
+		},
+	}
+}
+
+func parseWithKeySet(ks *jwtKeySet, tokenString string) (*dto.AuthClaims, error) {
+	claims := &dto.AuthClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, ks.verificationKey, jwt.WithValidMethods(ks.validMethods()))
+	return claims, err
+}
+
+func TestJWTKeySet_Rotation(t *testing.T) {
+	oldPrivate, oldPublic := generateEd25519PEM(t)
+	newPrivate, _ := generateRSAPEM(t, 2048)
+
+	before, ephemeral, err := newJWTKeySet(config.JWTConfig{
+		SigningKeys: []config.JWTKeyConfig{{ID: "2024-01", Algorithm: "EdDSA", PrivateKey: oldPrivate}},
+	})
+	require.NoError(t, err)
+	assert.False(t, ephemeral)
+	oldToken, err := before.sign(newTestClaims())
+	require.NoError(t, err)
+
+	// The new key signs; the old one is only kept for verification.
+	after, _, err := newJWTKeySet(config.JWTConfig{
+		ActiveKeyID: "2024-06",
+		SigningKeys: []config.JWTKeyConfig{
+			{ID: "2024-06", Algorithm: "RS256", PrivateKey: newPrivate},
+			{ID: "2024-01", Algorithm: "EdDSA", PublicKey: oldPublic},
+		},
+	})
+	require.NoError(t, err)
+
+	claims, err := parseWithKeySet(after, oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user123", claims.UserID)
+
+	newToken, err := after.sign(newTestClaims())
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &dto.AuthClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06", parsed.Header["kid"])
+	assert.Equal(t, "RS256", parsed.Header["alg"])
+
+	_, err = parseWithKeySet(after, newToken)
+	assert.NoError(t, err)
+	_, err = parseWithKeySet(before, newToken)
+	assert.Error(t, err, "a key set without the new key must not accept its tokens")
+}
+
+func TestJWTKeySet_RejectsForgedTokens(t *testing.T) {
+	privatePEM, publicPEM := generateRSAPEM(t, 2048)
+	ks, _, err := newJWTKeySet(config.JWTConfig{
+		SigningKeys: []config.JWTKeyConfig{{ID: "k1", Algorithm: "RS256", PrivateKey: privatePEM}},
+	})
+	require.NoError(t, err)
+
+	// HS256 with the public key as the secret must not pass as RS256.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, newTestClaims())
+	forged.Header["kid"] = "k1"
+	forgedString, err := forged.SignedString([]byte(publicPEM))
+	require.NoError(t, err)
+	_, err = parseWithKeySet(ks, forgedString)
+	assert.Error(t, err)
+
+	// A valid signature without a kid header
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, newTestClaims())
+	withoutKid, err := token.SignedString(ks.active.signer)
+	require.NoError(t, err)
+	_, err = parseWithKeySet(ks, withoutKid)
+	assert.Error(t, err)
+}
+
+func TestJWTKeySet_JWKS(t *testing.T) {
+	edPrivate, _ := generateEd25519PEM(t)
+	rsaPrivate, _ := generateRSAPEM(t, 2048)
+	ks, _, err := newJWTKeySet(config.JWTConfig{
+		ActiveKeyID: "ed",
+		SigningKeys: []config.JWTKeyConfig{
+			{ID: "ed", Algorithm: "EdDSA", PrivateKey: edPrivate},
+			{ID: "rsa", Algorithm: "RS256", PrivateKey: rsaPrivate},
+		},
+	})
+	require.NoError(t, err)
+
+	set := ks.jwks()
+	require.Len(t, set.Keys, 2)
+	assert.Equal(t, dto.JWK{KeyType: "OKP", Use: "sig", Algorithm: "EdDSA", KeyID: "ed", Curve: "Ed25519", X: set.Keys[0].X}, set.Keys[0])
+	assert.Len(t, set.Keys[0].X, 43) // 32 bytes, base64url without padding
+	assert.Equal(t, "RSA", set.Keys[1].KeyType)
+	assert.Equal(t, "rsa", set.Keys[1].KeyID)
+	assert.Equal(t, "AQAB", set.Keys[1].E)
+	assert.NotEmpty(t, set.Keys[1].N)
+}
+
+func TestNewJWTKeySet_Ephemeral(t *testing.T) {
+	ks, ephemeral, err := newJWTKeySet(config.JWTConfig{})
+	require.NoError(t, err)
+	assert.True(t, ephemeral)
+
+	token, err := ks.sign(newTestClaims())
+	require.NoError(t, err)
+	_, err = parseWithKeySet(ks, token)
+	assert.NoError(t, err)
+}
+
+func TestNewJWTKeySet_InvalidConfig(t *testing.T) {
+	edPrivate, edPublic := generateEd25519PEM(t)
+	smallRSAPrivate, _ := generateRSAPEM(t, 1024)
+
+	testCases := []struct {
+		name string
+		cfg  config.JWTConfig
+	}{
+		{"Missing Key ID", config.JWTConfig{SigningKeys: []config.JWTKeyConfig{{Algorithm: "EdDSA", PrivateKey: edPrivate}}}},
+		{"Unsupported Algorithm", config.JWTConfig{SigningKeys: []config.JWTKeyConfig{{ID: "k1", Algorithm: "HS256", PrivateKey: edPrivate}}}},
+		{"No Key Material", config.JWTConfig{SigningKeys: []config.JWTKeyConfig{{ID: "k1", Algorithm: "EdDSA"}}}},
+		{"Wrong Key Type", config.JWTConfig{SigningKeys: []config.JWTKeyConfig{{ID: "k1", Algorithm: "RS256", PrivateKey: edPrivate}}}},
+		{"RSA Key Too Small", config.JWTConfig{SigningKeys: []config.JWTKeyConfig{{ID: "k1", Algorithm: "RS256", PrivateKey: smallRSAPrivate}}}},
+		{"Missing Key File", config.JWTConfig{SigningKeys: []config.JWTKeyConfig{{ID: "k1", Algorithm: "EdDSA", PrivateKeyFile: "/nonexistent/key.pem"}}}},
+		{"Duplicate Key ID", config.JWTConfig{ActiveKeyID: "k1", SigningKeys: []config.JWTKeyConfig{
+			{ID: "k1", Algorithm: "EdDSA", PrivateKey: edPrivate},
+			{ID: "k1", Algorithm: "EdDSA", PublicKey: edPublic},
+		}}},
+		{"Active Key Not Chosen", config.JWTConfig{SigningKeys: []config.JWTKeyConfig{
+			{ID: "k1", Algorithm: "EdDSA", PrivateKey: edPrivate},
+			{ID: "k2", Algorithm: "EdDSA", PublicKey: edPublic},
+		}}},
+		{"Active Key Unknown", config.JWTConfig{ActiveKeyID: "k2", SigningKeys: []config.JWTKeyConfig{{ID: "k1", Algorithm: "EdDSA", PrivateKey: edPrivate}}}},
+		{"Active Key Verify Only", config.JWTConfig{SigningKeys: []config.JWTKeyConfig{{ID: "k1", Algorithm: "EdDSA", PublicKey: edPublic}}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := newJWTKeySet(tc.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewAuthService_TokenEncryptionKey(t *testing.T) {
+	for _, key := range []string{"", "not base64!", "c2hvcnQ="} {
+		_, err := NewAuthService(nil, nil, nil, nil, nil, nil, config.AuthConfig{TokenEncryptionKey: key}, nil)
+		assert.Error(t, err, "key %q", key)
+	}
+
+	authService, err := NewAuthService(nil, nil, nil, nil, nil, nil, config.AuthConfig{TokenEncryptionKey: testTokenEncryptionKey}, nil)
+	require.NoError(t, err)
+	encrypted, err := authService.EncryptToken("google-access-token")
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "google-access-token")
+	decrypted, err := authService.DecryptToken(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "google-access-token", decrypted)
+}
@@ -56,8 +56,8 @@ func (m *MockQuizRepository) GetRandomQuiz(ctx context.Context) (*domain.Quiz, e
 	return args.Get(0).(*domain.Quiz), args.Error(1)
 }
 
-func (m *MockQuizRepository) GetRandomQuizBySubCategory(ctx context.Context, subCategory string) (*domain.Quiz, error) {
-	args := m.Called(ctx, subCategory)
+func (m *MockQuizRepository) GetRandomQuizBySubCategory(ctx context.Context, subCategoryID string, filter *domain.QuizFilter) (*domain.Quiz, error) {
+	args := m.Called(ctx, subCategoryID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -85,8 +85,8 @@ func (m *MockQuizRepository) GetUnattemptedQuizzesWithDetails(ctx context.Contex
 	return args.Get(0).([]dto.QuizRecommendationItem), args.Error(1)
 }
 
-func (m *MockQuizRepository) GetSimilarQuiz(ctx context.Context, quizID string) (*domain.Quiz, error) {
-	args := m.Called(ctx, quizID)
+func (m *MockQuizRepository) GetSimilarQuiz(ctx context.Context, quizID string, filter *domain.QuizFilter) (*domain.Quiz, error) {
+	args := m.Called(ctx, quizID, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -111,11 +111,68 @@ func (m *MockQuizRepository) GetSubCategoryIDByName(ctx context.Context, name st
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockQuizRepository) UpdateQuiz(ctx context.Context, quiz *domain.Quiz) error {
-	args := m.Called(ctx, quiz)
+func (m *MockQuizRepository) UpdateQuiz(ctx context.Context, quiz *domain.Quiz, author string) error {
+	args := m.Called(ctx, quiz, author)
 	return args.Error(0)
 }
 
+func (m *MockQuizRepository) ListQuizRevisions(ctx context.Context, quizID string) ([]*domain.QuizRevision, error) {
+	args := m.Called(ctx, quizID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.QuizRevision), args.Error(1)
+}
+
+func (m *MockQuizRepository) GetQuizRevision(ctx context.Context, quizID string, revision int) (*domain.QuizRevision, error) {
+	args := m.Called(ctx, quizID, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.QuizRevision), args.Error(1)
+}
+
+func (m *MockQuizRepository) UpdateQuizEvaluation(ctx context.Context, evaluation *domain.QuizEvaluation) error {
+	args := m.Called(ctx, evaluation)
+	return args.Error(0)
+}
+
+func (m *MockQuizRepository) DeleteQuiz(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockQuizRepository) DeleteQuizEvaluation(ctx context.Context, quizID string) (bool, error) {
+	args := m.Called(ctx, quizID)
+	return args.Bool(0), args.Error(1)
+}
+
+// --- MockQuizStatusRepository ---
+type MockQuizStatusRepository struct {
+	mock.Mock
+}
+
+func (m *MockQuizStatusRepository) ChangeQuizStatus(ctx context.Context, change *domain.QuizStatusChange) error {
+	args := m.Called(ctx, change)
+	return args.Error(0)
+}
+
+func (m *MockQuizStatusRepository) ListQuizStatusChanges(ctx context.Context, quizID string) ([]*domain.QuizStatusChange, error) {
+	args := m.Called(ctx, quizID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.QuizStatusChange), args.Error(1)
+}
+
+func (m *MockQuizStatusRepository) ListQuizzesByStatus(ctx context.Context, statuses []string, limit int) ([]*domain.Quiz, error) {
+	args := m.Called(ctx, statuses, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Quiz), args.Error(1)
+}
+
 // --- MockCategoryRepository ---
 type MockCategoryRepository struct {
 	mock.Mock
@@ -163,6 +220,42 @@ func (m *MockCategoryRepository) GetByNameAndCategoryID(ctx context.Context, nam
 	return args.Get(0).(*domain.SubCategory), args.Error(1)
 }
 
+func (m *MockCategoryRepository) GetCategoryByID(ctx context.Context, id string) (*domain.Category, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetSubCategoryByID(ctx context.Context, id string) (*domain.SubCategory, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SubCategory), args.Error(1)
+}
+
+func (m *MockCategoryRepository) UpdateCategory(ctx context.Context, category *domain.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) UpdateSubCategory(ctx context.Context, subCategory *domain.SubCategory) error {
+	args := m.Called(ctx, subCategory)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) DeleteCategory(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCategoryRepository) DeleteSubCategory(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
 // --- MockEmbeddingService ---
 type MockEmbeddingService struct {
 	mock.Mock
@@ -208,8 +301,21 @@ type MockAnswerEvaluator struct {
 	mock.Mock
 }
 
-func (m *MockAnswerEvaluator) EvaluateAnswer(question, modelAnswer, userAnswer string, keywords []string) (*domain.Answer, error) {
-	args := m.Called(question, modelAnswer, userAnswer, keywords)
+func (m *MockAnswerEvaluator) EvaluateAnswer(ctx context.Context, input port.EvaluationInput) (*domain.Answer, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Answer), args.Error(1)
+}
+
+// MockStreamingAnswerEvaluator additionally implements port.StreamingAnswerEvaluator.
+type MockStreamingAnswerEvaluator struct {
+	MockAnswerEvaluator
+}
+
+func (m *MockStreamingAnswerEvaluator) EvaluateAnswerStream(ctx context.Context, input port.EvaluationInput, onExplanation func(delta string)) (*domain.Answer, error) {
+	args := m.Called(ctx, input, onExplanation)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -239,6 +345,11 @@ func (m *MockCache) Delete(ctx context.Context, key string) error {
 	return args.Error(0)
 }
 
+func (m *MockCache) GetDel(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockCache) Ping(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
@@ -276,6 +387,7 @@ var _ domain.CategoryRepository = (*MockCategoryRepository)(nil)
 var _ domain.EmbeddingService = (*MockEmbeddingService)(nil)
 var _ domain.QuizGenerationService = (*MockQuizGenerationService)(nil)
 var _ port.AnswerEvaluator = (*MockAnswerEvaluator)(nil)
+var _ port.StreamingAnswerEvaluator = (*MockStreamingAnswerEvaluator)(nil)
 var _ domain.Cache = (*MockCache)(nil) // For the general MockCache
 
 // MockAnswerCacheService (moved from quiz_test.go)
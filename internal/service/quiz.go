@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"quiz-byte/internal/cache" // Added import for cache key generation
+	"quiz-byte/internal/config"
 	"quiz-byte/internal/domain"
 	"quiz-byte/internal/dto"
 	"quiz-byte/internal/logger"
@@ -111,6 +113,13 @@ func findMatchingScoreExplanation(score float64, details []domain.ScoreEvaluatio
 	return "", false
 }
 
+const (
+	// NextQuizHighScoreThreshold is the score at or above which the next quiz gets harder
+	NextQuizHighScoreThreshold = 0.8
+	// NextQuizLowScoreThreshold is the score below which the next quiz gets easier
+	NextQuizLowScoreThreshold = 0.5
+)
+
 // LLMResponse represents the response from the LLM service
 type LLMResponse struct {
 	Score          float64  `json:"score"`           // 종합 점수
@@ -123,10 +132,31 @@ type LLMResponse struct {
 
 // QuizService defines the interface for quiz-related operations
 type QuizService interface {
-	GetRandomQuiz(subCategory string) (*dto.QuizResponse, error)
-	CheckAnswer(req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error)
+	GetRandomQuiz(req *dto.RandomQuizRequest) (*dto.QuizResponse, error)
+	GetNextQuiz(req *dto.NextQuizRequest) (*dto.QuizResponse, error)
+	CheckAnswer(ctx context.Context, req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error)
+	// CheckAnswerStream is CheckAnswer that reports progress to onEvent: the cache lookup result and
+	// explanation deltas. onEvent is called from the calling goroutine only.
+	CheckAnswerStream(ctx context.Context, req *dto.CheckAnswerRequest, onEvent func(dto.CheckAnswerStreamEvent)) (*dto.CheckAnswerResponse, error)
 	GetAllSubCategories() ([]string, error)
 	GetBulkQuizzes(req *dto.BulkQuizzesRequest) (*dto.BulkQuizzesResponse, error)
+	// InvalidateQuizCache removes a quiz's cached answer evaluations, e.g. after its model answers or rubric changed.
+	InvalidateQuizCache(ctx context.Context, quizID string) error
+	// InvalidateQuizListCache removes the cached quiz lists of a subcategory.
+	InvalidateQuizListCache(ctx context.Context, subCategoryID string) error
+	// InvalidateCategoryListCache removes the cached list of subcategories.
+	InvalidateCategoryListCache(ctx context.Context) error
+}
+
+// maxBulkQuizCount caps the number of quizzes GetBulkQuizzes returns; the quiz lists are cached per count.
+const maxBulkQuizCount = 50
+
+func categoryListCacheKey() string {
+	return cache.GenerateCacheKey("quiz_service", "category_list", "all")
+}
+
+func quizListCacheKey(subCategoryID string, count int) string {
+	return cache.GenerateCacheKey("quiz_service", "quiz_list", subCategoryID, strconv.Itoa(count))
 }
 
 // quizService implements QuizService
@@ -140,6 +170,9 @@ type quizService struct {
 	sfGroup          singleflight.Group
 	categoryListTTL  time.Duration // Added
 	quizListTTL      time.Duration // Added
+	checkBudgets     config.CheckAnswerConfig
+	preScorer        *answerPreScorer // nil when check_answer.pre_score is disabled
+	explanations     *explanationStreams
 }
 
 // NewQuizService creates a new instance of quizService
@@ -152,7 +185,12 @@ func NewQuizService(
 	txManager domain.TransactionManager, // Added for transaction support
 	categoryListTTL time.Duration, // Added
 	quizListTTL time.Duration, // Added
+	checkBudgets config.CheckAnswerConfig, // Per-stage time budgets for CheckAnswer
 ) QuizService {
+	var preScorer *answerPreScorer
+	if checkBudgets.PreScore.Enabled {
+		preScorer = newAnswerPreScorer(embeddingService, checkBudgets.PreScore)
+	}
 	return &quizService{
 		repo:             repo,
 		evaluator:        evaluator,
@@ -162,18 +200,35 @@ func NewQuizService(
 		txManager:        txManager,
 		categoryListTTL:  categoryListTTL,
 		quizListTTL:      quizListTTL,
+		checkBudgets:     checkBudgets,
+		preScorer:        preScorer,
+		explanations:     newExplanationStreams(),
 	}
 }
 
 // GetRandomQuiz implements QuizService
-func (s *quizService) GetRandomQuiz(subCategory string) (*dto.QuizResponse, error) {
+func (s *quizService) GetRandomQuiz(req *dto.RandomQuizRequest) (*dto.QuizResponse, error) {
 	ctx := context.Background() // Added context
-	quiz, err := s.repo.GetRandomQuiz(ctx)
+
+	subCategoryID, err := s.resolveSubCategoryID(ctx, req.SubCategory)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &domain.QuizFilter{
+		Keywords:   req.Keywords,
+		ExcludeIDs: req.ExcludeIDs,
+	}
+	if req.Difficulty != "" {
+		filter.Difficulty = domain.DifficultyToInt(req.Difficulty)
+	}
+
+	quiz, err := s.repo.GetRandomQuizBySubCategory(ctx, subCategoryID, filter)
 	if err != nil {
 		return nil, domain.NewInternalError("Failed to get random quiz", err)
 	}
 	if quiz == nil {
-		return nil, domain.NewInvalidCategoryError(subCategory)
+		return nil, domain.NewNotFoundError(fmt.Sprintf("No quiz found for sub category %s matching the given filters", req.SubCategory))
 	}
 
 	return &dto.QuizResponse{
@@ -184,16 +239,140 @@ func (s *quizService) GetRandomQuiz(subCategory string) (*dto.QuizResponse, erro
 	}, nil
 }
 
-// CheckAnswer implements QuizService
-func (s *quizService) CheckAnswer(req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
+// resolveSubCategoryID accepts either a subcategory name or ID and returns the ID.
+// Names are looked up first (case-insensitive); otherwise the value must be a known subcategory ID.
+func (s *quizService) resolveSubCategoryID(ctx context.Context, subCategory string) (string, error) {
+	subCategoryID, err := s.repo.GetSubCategoryIDByName(ctx, subCategory)
+	if err != nil {
+		return "", domain.NewInternalError("Failed to get subcategory ID", err)
+	}
+	if subCategoryID != "" {
+		return subCategoryID, nil
+	}
+
+	subCategoryIDs, err := s.GetAllSubCategories()
+	if err != nil {
+		return "", err
+	}
+	for _, id := range subCategoryIDs {
+		if id == subCategory {
+			return id, nil
+		}
+	}
+	return "", domain.NewInvalidCategoryError(subCategory)
+}
+
+// GetNextQuiz implements QuizService.
+// It picks a follow-up quiz from the same subcategory, one level harder after a high score and
+// one level easier after a low score. If nothing is left at the shifted level, it falls back to
+// the current difficulty.
+func (s *quizService) GetNextQuiz(req *dto.NextQuizRequest) (*dto.QuizResponse, error) {
 	ctx := context.Background()
-	// cacheKey variable is removed as it's now handled by AnswerCacheService
+
+	current, err := s.getPublishedQuiz(ctx, req.CurrentQuizID)
+	if err != nil {
+		return nil, err
+	}
+
+	targetDifficulty := nextDifficulty(current.Difficulty, req.LastScore)
+	filter := &domain.QuizFilter{
+		Difficulty:               targetDifficulty,
+		ExcludeAttemptedByUserID: req.UserID,
+	}
+
+	next, err := s.repo.GetSimilarQuiz(ctx, current.ID, filter)
+	if err != nil {
+		return nil, domain.NewInternalError("Failed to get next quiz", err)
+	}
+	if next == nil && targetDifficulty != current.Difficulty {
+		logger.Get().Debug("No quiz at shifted difficulty, falling back to current difficulty",
+			zap.String("quiz_id", current.ID),
+			zap.Int("target_difficulty", targetDifficulty))
+		filter.Difficulty = current.Difficulty
+		next, err = s.repo.GetSimilarQuiz(ctx, current.ID, filter)
+		if err != nil {
+			return nil, domain.NewInternalError("Failed to get next quiz", err)
+		}
+	}
+	if next == nil {
+		return nil, domain.NewNotFoundError(fmt.Sprintf("No next quiz available after quiz %s", current.ID))
+	}
+
+	return &dto.QuizResponse{
+		ID:        next.ID,
+		Question:  next.Question,
+		Keywords:  next.Keywords,
+		DiffLevel: next.DifficultyToString(),
+	}, nil
+}
+
+// getPublishedQuiz loads a quiz served to learners. Quizzes that are not published are reported as
+// not found, so their model answers are neither graded against nor revealed.
+func (s *quizService) getPublishedQuiz(ctx context.Context, quizID string) (*domain.Quiz, error) {
+	quiz, err := s.repo.GetQuizByID(ctx, quizID)
+	if err != nil {
+		return nil, domain.NewInternalError("Failed to get quiz", err)
+	}
+	if quiz == nil || quiz.Status != domain.QuizStatusPublished {
+		return nil, domain.NewQuizNotFoundError(quizID)
+	}
+	return quiz, nil
+}
+
+// nextDifficulty shifts the difficulty by one level based on the last score, staying within bounds.
+func nextDifficulty(current int, lastScore *float64) int {
+	if lastScore == nil {
+		return current
+	}
+	switch {
+	case *lastScore >= NextQuizHighScoreThreshold && current < domain.DifficultyHard:
+		return current + 1
+	case *lastScore < NextQuizLowScoreThreshold && current > domain.DifficultyEasy:
+		return current - 1
+	default:
+		return current
+	}
+}
+
+// CheckAnswer implements QuizService.
+// ctx is the request context, which the handler cancels when the client disconnects; embedding,
+// cache lookup and LLM evaluation then stop. Each stage additionally runs under its budget from checkBudgets.
+func (s *quizService) CheckAnswer(ctx context.Context, req *dto.CheckAnswerRequest) (*dto.CheckAnswerResponse, error) {
+	return s.checkAnswer(ctx, req, nil)
+}
+
+// CheckAnswerStream implements QuizService.
+// Callers with the same quiz and answer share one evaluation and therefore one explanation stream.
+func (s *quizService) CheckAnswerStream(ctx context.Context, req *dto.CheckAnswerRequest, onEvent func(dto.CheckAnswerStreamEvent)) (*dto.CheckAnswerResponse, error) {
+	return s.checkAnswer(ctx, req, onEvent)
+}
+
+func (s *quizService) checkAnswer(ctx context.Context, req *dto.CheckAnswerRequest, onEvent func(dto.CheckAnswerStreamEvent)) (*dto.CheckAnswerResponse, error) {
+	emit := func(event string, data interface{}) {
+		if onEvent != nil {
+			onEvent(dto.CheckAnswerStreamEvent{Event: event, Data: data})
+		}
+	}
+
+	ctx, cancel := withBudget(ctx, s.checkBudgets.Total)
+	defer cancel()
+
+	// Checked before the answer cache too: cached evaluations carry the model answer.
+	quiz, err := s.getPublishedQuiz(ctx, req.QuizID)
+	if err != nil {
+		return nil, err
+	}
 
 	var userAnswerEmbedding []float32
 	var errEmbed error
 
 	if s.embeddingService != nil {
-		userAnswerEmbedding, errEmbed = s.embeddingService.Generate(ctx, req.UserAnswer)
+		embedCtx, embedCancel := withBudget(ctx, s.checkBudgets.Embedding)
+		userAnswerEmbedding, errEmbed = s.embeddingService.Generate(embedCtx, req.UserAnswer)
+		embedCancel()
+		if err := ctx.Err(); err != nil {
+			return nil, checkAnswerContextError("embedding", err)
+		}
 		if errEmbed != nil {
 			logger.Get().Warn("QuizService: Failed to generate embedding for current answer, cache will be skipped.",
 				zap.Error(errEmbed),
@@ -209,7 +388,12 @@ func (s *quizService) CheckAnswer(req *dto.CheckAnswerRequest) (*dto.CheckAnswer
 
 	// 1. Cache Read Logic (delegated to AnswerCacheService)
 	if s.answerCache != nil && errEmbed == nil && len(userAnswerEmbedding) > 0 {
-		cachedResp, errCacheGet := s.answerCache.GetAnswerFromCache(ctx, req.QuizID, userAnswerEmbedding, req.UserAnswer)
+		lookupCtx, lookupCancel := withBudget(ctx, s.checkBudgets.CacheLookup)
+		cachedResp, errCacheGet := s.answerCache.GetAnswerFromCache(lookupCtx, req.QuizID, userAnswerEmbedding, req.UserAnswer)
+		lookupCancel()
+		if err := ctx.Err(); err != nil {
+			return nil, checkAnswerContextError("cache_lookup", err)
+		}
 		if errCacheGet != nil {
 			// Log actual errors, not misses (misses are logged by AnswerCacheService)
 			logger.Get().Error("QuizService: Error getting answer from AnswerCacheService",
@@ -218,10 +402,12 @@ func (s *quizService) CheckAnswer(req *dto.CheckAnswerRequest) (*dto.CheckAnswer
 			// Proceed to LLM evaluation as if it was a cache miss
 		} else if cachedResp != nil {
 			logger.Get().Info("QuizService: Cache hit from AnswerCacheService.", zap.String("quizID", req.QuizID))
+			emit(dto.CheckStreamEventCache, dto.CacheLookupEvent{Hit: true})
 			return cachedResp, nil // Cache Hit
 		}
 		// If cachedResp is nil and errCacheGet is nil, it's a cache miss, proceed to LLM.
 	}
+	emit(dto.CheckStreamEventCache, dto.CacheLookupEvent{Hit: false})
 
 	// 2. LLM Evaluation Logic (Protected by SingleFlight)
 	// Create a unique key for singleflight based on quizID and userAnswer to prevent multiple LLM calls for the same input.
@@ -230,16 +416,15 @@ func (s *quizService) CheckAnswer(req *dto.CheckAnswerRequest) (*dto.CheckAnswer
 	userAnswerHash := hex.EncodeToString(hasher.Sum(nil))
 	sfKey := fmt.Sprintf("check_answer:%s:%s", req.QuizID, userAnswerHash)
 
-	res, sfErr, _ := s.sfGroup.Do(sfKey, func() (interface{}, error) {
+	// The leader's context bounds the shared evaluation; every caller still stops
+	// waiting as soon as its own context is done.
+	resultCh := s.sfGroup.DoChan(sfKey, func() (interface{}, error) {
 		logger.Get().Debug("Calling singleflight Do func for CheckAnswer", zap.String("sfKey", sfKey))
 
-		quiz, err := s.repo.GetQuizByID(ctx, req.QuizID) // Added ctx
-		if err != nil {
-			return nil, domain.NewInternalError("Failed to get quiz", err)
-		}
-		if quiz == nil {
-			return nil, domain.NewQuizNotFoundError(req.QuizID)
-		}
+		// Explanation text is always published so streaming callers can join any evaluation.
+		explanation := s.explanations.acquire(sfKey)
+		defer s.explanations.release(sfKey, explanation)
+		explanation.reset()
 
 		answer := domain.NewAnswer(req.QuizID, req.UserAnswer)
 		if err := answer.Validate(); err != nil {
@@ -250,24 +435,49 @@ func (s *quizService) CheckAnswer(req *dto.CheckAnswerRequest) (*dto.CheckAnswer
 			return nil, domain.NewInternalError("No model answer found", nil)
 		}
 
-		evaluatedAnswer, err := s.evaluator.EvaluateAnswer(
-			quiz.Question,
-			quiz.ModelAnswers[0],
-			req.UserAnswer,
-			quiz.Keywords,
-		)
-		if err != nil {
-			return nil, domain.NewLLMServiceError(err)
-		}
-
-		// ---> START NEW LOGIC TO GET PRE-GENERATED EXPLANATION <---
-		quizEvaluation, errEvalGet := s.repo.GetQuizEvaluation(ctx, quiz.ID) // ctx is available from CheckAnswer
+		// The rubric is optional: without it the answer is graded against the model answers only.
+		quizEvaluation, errEvalGet := s.repo.GetQuizEvaluation(ctx, quiz.ID)
 		if errEvalGet != nil {
 			logger.Get().Error("Failed to get QuizEvaluation during answer check",
 				zap.String("quiz_id", quiz.ID),
 				zap.Error(errEvalGet),
 			)
-			// Non-fatal, will use LLM's original explanation
+			quizEvaluation = nil
+		}
+
+		// 2. Local pre-score: obvious cases are decided without the LLM.
+		var ps preScore
+		var evaluatedAnswer *domain.Answer
+		if s.preScorer != nil {
+			psCtx, psCancel := withBudget(ctx, s.checkBudgets.Embedding)
+			ps = s.preScorer.score(psCtx, quiz, req.UserAnswer, userAnswerEmbedding)
+			psCancel()
+			if decided, ok := s.preScorer.decide(ps, req.UserAnswer, quizEvaluation); ok {
+				logger.Get().Info("Answer decided by pre-score, skipping LLM",
+					zap.String("quiz_id", quiz.ID),
+					zap.Float64("score", decided.Score),
+					zap.Float64("similarity", ps.similarity))
+				evaluatedAnswer = decided
+			}
+		}
+
+		if evaluatedAnswer == nil {
+			onExplanation := explanation.publish
+			if quizEvaluation != nil && len(quizEvaluation.ScoreEvaluations) > 0 {
+				// The rubric's explanation for the score replaces the LLM's below, so streaming the
+				// LLM's would show the client text the result then contradicts.
+				onExplanation = nil
+			}
+			evaluatedAnswer, err = s.evaluateWithLLM(ctx, quiz, req.UserAnswer, quizEvaluation, ps, onExplanation)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// ---> START NEW LOGIC TO GET PRE-GENERATED EXPLANATION <---
+		// A fallback keeps its own explanation, which says the score is only an estimate.
+		if evaluatedAnswer.ScoredBy == domain.ScoredByFallback {
+			logger.Get().Info("Keeping fallback explanation.", zap.String("quiz_id", quiz.ID))
 		} else if quizEvaluation != nil {
 			if len(quizEvaluation.ScoreEvaluations) > 0 {
 				preGeneratedExplanation, found := findMatchingScoreExplanation(evaluatedAnswer.Score, quizEvaluation.ScoreEvaluations, quizEvaluation.ScoreRanges)
@@ -287,23 +497,30 @@ func (s *quizService) CheckAnswer(req *dto.CheckAnswerRequest) (*dto.CheckAnswer
 			} else {
 				logger.Get().Info("QuizEvaluation found but ScoreEvaluations is empty. Using LLM's original explanation.", zap.String("quiz_id", quiz.ID))
 			}
-		} else {
+		} else if errEvalGet == nil {
 			logger.Get().Info("No QuizEvaluation found for quiz. Using LLM's original explanation.", zap.String("quiz_id", quiz.ID))
 		}
 		// ---> END NEW LOGIC <---
 
 		response := &dto.CheckAnswerResponse{
-			Score:          evaluatedAnswer.Score,
-			Explanation:    evaluatedAnswer.Explanation,
-			KeywordMatches: evaluatedAnswer.KeywordMatches,
-			Completeness:   evaluatedAnswer.Completeness,
-			Relevance:      evaluatedAnswer.Relevance,
-			Accuracy:       evaluatedAnswer.Accuracy,
-			ModelAnswer:    strings.Join(quiz.ModelAnswers, "\n"),
+			Score:           evaluatedAnswer.Score,
+			Explanation:     evaluatedAnswer.Explanation,
+			KeywordMatches:  evaluatedAnswer.KeywordMatches,
+			Completeness:    evaluatedAnswer.Completeness,
+			Relevance:       evaluatedAnswer.Relevance,
+			Accuracy:        evaluatedAnswer.Accuracy,
+			ModelAnswer:     strings.Join(quiz.ModelAnswers, "\n"),
+			RubricSatisfied: evaluatedAnswer.RubricSatisfied,
+			RubricMissed:    evaluatedAnswer.RubricMissed,
+			Confidence:      evaluatedAnswer.Confidence,
+			JudgeCount:      evaluatedAnswer.JudgeCount,
+			ScoredBy:        evaluatedAnswer.ScoredBy,
+			QuizRevision:    quiz.Revision,
 		}
 
 		// 3. Cache Write Logic (delegated to AnswerCacheService, happens within singleflight)
-		if s.answerCache != nil && errEmbed == nil && len(userAnswerEmbedding) > 0 {
+		// Fallback estimates are not cached so the next attempt is graded by the LLM again.
+		if s.answerCache != nil && errEmbed == nil && len(userAnswerEmbedding) > 0 && evaluatedAnswer.ScoredBy != domain.ScoredByFallback {
 			errCachePut := s.answerCache.PutAnswerToCache(ctx, req.QuizID, req.UserAnswer, userAnswerEmbedding, response)
 			if errCachePut != nil {
 				logger.Get().Error("QuizService: Error putting answer to AnswerCacheService (singleflight)",
@@ -315,15 +532,119 @@ func (s *quizService) CheckAnswer(req *dto.CheckAnswerRequest) (*dto.CheckAnswer
 		return response, nil
 	})
 
-	if sfErr != nil {
-		return nil, sfErr
+	var sfResult singleflight.Result
+	if onEvent == nil {
+		select {
+		case sfResult = <-resultCh:
+		case <-ctx.Done():
+			return nil, checkAnswerContextError("evaluation", ctx.Err())
+		}
+	} else {
+		var err error
+		if sfResult, err = s.followExplanation(ctx, sfKey, resultCh, emit); err != nil {
+			return nil, err
+		}
+	}
+
+	if sfResult.Err != nil {
+		return nil, sfResult.Err
 	}
 
-	if response, ok := res.(*dto.CheckAnswerResponse); ok {
+	if response, ok := sfResult.Val.(*dto.CheckAnswerResponse); ok {
 		return response, nil
 	}
 
-	return nil, fmt.Errorf("unexpected type from singleflight.Do for CheckAnswer: %T", res)
+	return nil, fmt.Errorf("unexpected type from singleflight.DoChan for CheckAnswer: %T", sfResult.Val)
+}
+
+// followExplanation relays the explanation chunks of the evaluation running under sfKey
+// until its result arrives.
+func (s *quizService) followExplanation(ctx context.Context, sfKey string, resultCh <-chan singleflight.Result, emit func(string, interface{})) (singleflight.Result, error) {
+	explanation := s.explanations.acquire(sfKey)
+	defer s.explanations.release(sfKey, explanation)
+
+	sent := 0
+	relay := func() <-chan struct{} {
+		chunks, changed := explanation.since(sent)
+		for _, chunk := range chunks {
+			emit(dto.CheckStreamEventExplanation, dto.ExplanationDeltaEvent{Delta: chunk})
+		}
+		sent += len(chunks)
+		return changed
+	}
+
+	for {
+		changed := relay()
+		select {
+		case result := <-resultCh:
+			relay()
+			return result, nil
+		case <-changed:
+		case <-ctx.Done():
+			return singleflight.Result{}, checkAnswerContextError("evaluation", ctx.Err())
+		}
+	}
+}
+
+// evaluateWithLLM grades the answer with the configured evaluator, streaming the explanation to
+// onExplanation when it is set and the evaluator supports it. When the evaluator fails
+// (but did not time out) and fallback is enabled, the pre-score estimate is returned instead.
+func (s *quizService) evaluateWithLLM(ctx context.Context, quiz *domain.Quiz, userAnswer string, rubric *domain.QuizEvaluation, ps preScore, onExplanation func(string)) (*domain.Answer, error) {
+	evalCtx, evalCancel := withBudget(ctx, s.checkBudgets.Evaluation)
+	defer evalCancel()
+	input := port.EvaluationInput{
+		Question:     quiz.Question,
+		ModelAnswers: quiz.ModelAnswers,
+		UserAnswer:   userAnswer,
+		Keywords:     quiz.Keywords,
+		Rubric:       rubric,
+	}
+	var evaluatedAnswer *domain.Answer
+	var err error
+	if streaming, ok := s.evaluator.(port.StreamingAnswerEvaluator); ok && onExplanation != nil {
+		evaluatedAnswer, err = streaming.EvaluateAnswerStream(evalCtx, input, onExplanation)
+	} else {
+		evaluatedAnswer, err = s.evaluator.EvaluateAnswer(evalCtx, input)
+	}
+	if err == nil {
+		evaluatedAnswer.ScoredBy = domain.ScoredByLLM
+		evaluatedAnswer.JudgeCount = max(evaluatedAnswer.JudgeCount, 1) // Single-judge evaluators leave it unset
+		return evaluatedAnswer, nil
+	}
+
+	if errors.Is(err, domain.ErrEvaluationTimeout) {
+		return nil, err
+	}
+	if ctxErr := evalCtx.Err(); ctxErr != nil {
+		return nil, checkAnswerContextError("evaluation", ctxErr)
+	}
+	if s.preScorer != nil && s.preScorer.settings.FallbackOnLLMError {
+		if fallback, ok := s.preScorer.fallback(ps, userAnswer); ok {
+			logger.Get().Warn("LLM evaluation failed, returning fallback score",
+				zap.String("quiz_id", quiz.ID),
+				zap.Float64("score", fallback.Score),
+				zap.Error(err))
+			return fallback, nil
+		}
+	}
+	return nil, domain.NewLLMServiceError(err)
+}
+
+// withBudget bounds ctx by budget; a zero budget leaves ctx as is.
+func withBudget(ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	if budget <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, budget)
+}
+
+// checkAnswerContextError maps an expired request context to EVALUATION_TIMEOUT.
+// Cancellation (client went away) is returned unchanged.
+func checkAnswerContextError(stage string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return domain.NewEvaluationTimeoutError(stage, err)
+	}
+	return err
 }
 
 // tryGetEvaluationFromCachedItem is removed as its logic is now in AnswerCacheService.
@@ -331,7 +652,7 @@ func (s *quizService) CheckAnswer(req *dto.CheckAnswerRequest) (*dto.CheckAnswer
 // GetAllSubCategories implements QuizService
 func (s *quizService) GetAllSubCategories() ([]string, error) {
 	ctx := context.Background() // Define context
-	cacheKey := cache.GenerateCacheKey("quiz_service", "category_list", "all")
+	cacheKey := categoryListCacheKey()
 
 	// Cache Check
 	if s.cache != nil {
@@ -401,8 +722,8 @@ func (s *quizService) GetBulkQuizzes(req *dto.BulkQuizzesRequest) (*dto.BulkQuiz
 	if req.Count <= 0 {
 		req.Count = 10 // Default to 10 if invalid count is somehow passed
 	}
-	if req.Count > 50 {
-		req.Count = 50 // Cap at 50
+	if req.Count > maxBulkQuizCount {
+		req.Count = maxBulkQuizCount
 	}
 
 	// Get subcategory ID using case-insensitive comparison
@@ -414,7 +735,7 @@ func (s *quizService) GetBulkQuizzes(req *dto.BulkQuizzesRequest) (*dto.BulkQuiz
 		return nil, domain.NewInvalidCategoryError(req.SubCategory)
 	}
 
-	cacheKey := cache.GenerateCacheKey("quiz_service", "quiz_list", subCategoryID, strconv.Itoa(req.Count))
+	cacheKey := quizListCacheKey(subCategoryID, req.Count)
 
 	// Cache Check
 	if s.cache != nil {
@@ -547,3 +868,28 @@ func (s *quizService) InvalidateQuizCache(ctx context.Context, quizID string) er
 		zap.String("cacheKey", cacheKey))
 	return nil
 }
+
+// InvalidateQuizListCache implements QuizService. Lists are cached per requested count, so every count is removed.
+func (s *quizService) InvalidateQuizListCache(ctx context.Context, subCategoryID string) error {
+	if s.cache == nil {
+		return nil
+	}
+	for count := 1; count <= maxBulkQuizCount; count++ {
+		if err := s.cache.Delete(ctx, quizListCacheKey(subCategoryID, count)); err != nil {
+			return domain.NewInternalError("failed to invalidate cached quiz lists", err)
+		}
+	}
+	logger.Get().Debug("QuizService: Invalidated cached quiz lists", zap.String("subCategoryID", subCategoryID))
+	return nil
+}
+
+// InvalidateCategoryListCache implements QuizService
+func (s *quizService) InvalidateCategoryListCache(ctx context.Context) error {
+	if s.cache == nil {
+		return nil
+	}
+	if err := s.cache.Delete(ctx, categoryListCacheKey()); err != nil {
+		return domain.NewInternalError("failed to invalidate cached category list", err)
+	}
+	return nil
+}
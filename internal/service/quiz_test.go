@@ -3,10 +3,12 @@ package service
 import (
 	"bytes" // Added for gob
 	"context"
+	"crypto/sha256"
 	"encoding/gob" // Added for gob
 	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time" // Needed for TTLs
 
@@ -14,9 +16,10 @@ import (
 	"quiz-byte/internal/domain"
 	"quiz-byte/internal/dto"
 	"quiz-byte/internal/logger"
+	"quiz-byte/internal/port"
 
 	"github.com/stretchr/testify/assert"
-	// "github.com/stretchr/testify/mock" // mock is in mocks_test.go
+	"github.com/stretchr/testify/mock"
 )
 
 // TestMain will be used to initialize the logger for all tests in this package
@@ -66,9 +69,11 @@ func TestCheckAnswer_With_AnswerCacheService(t *testing.T) { // Renamed test fun
 
 		expectedCachedResponse := &dto.CheckAnswerResponse{Score: 0.8, Explanation: "From AnswerCacheService"}
 		mockAnswerCacheSvc.On("GetAnswerFromCache", ctx, req.QuizID, userAnswerEmbedding, req.UserAnswer).Return(expectedCachedResponse, nil).Once()
+		// Only published quizzes are checked, also from the cache
+		mockRepo.On("GetQuizByID", ctx, req.QuizID).Return(&domain.Quiz{ID: req.QuizID, Status: domain.QuizStatusPublished}, nil).Once()
 
-		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL)
-		response, err := service.CheckAnswer(&req)
+		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+		response, err := service.CheckAnswer(context.Background(), &req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, response)
@@ -78,7 +83,7 @@ func TestCheckAnswer_With_AnswerCacheService(t *testing.T) { // Renamed test fun
 		mockEmbSvc.AssertExpectations(t)
 		mockAnswerCacheSvc.AssertExpectations(t)
 		mockEvaluator.AssertNotCalled(t, "EvaluateAnswer")
-		mockRepo.AssertNotCalled(t, "GetQuizByID") // Not called by QuizService directly in cache hit
+		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Cache Miss from AnswerCacheService (Embedding Success), LLM Fallback, Cache Write", func(t *testing.T) {
@@ -100,14 +105,14 @@ func TestCheckAnswer_With_AnswerCacheService(t *testing.T) { // Renamed test fun
 		// Simulate cache miss from AnswerCacheService
 		mockAnswerCacheSvc.On("GetAnswerFromCache", ctx, req.QuizID, userAnswerEmbedding, req.UserAnswer).Return(nil, nil).Once()
 
-		quizForEval := &domain.Quiz{ID: req.QuizID, Question: "Q1", ModelAnswers: []string{"Model Ans"}, Keywords: []string{"k1"}}
+		quizForEval := &domain.Quiz{ID: req.QuizID, Question: "Q1", ModelAnswers: []string{"Model Ans"}, Keywords: []string{"k1"}, Status: domain.QuizStatusPublished}
 		mockRepo.On("GetQuizByID", ctx, req.QuizID).Return(quizForEval, nil).Once() // Added ctx
 
 		// Mock GetQuizEvaluation call that happens during LLM evaluation
 		mockRepo.On("GetQuizEvaluation", ctx, req.QuizID).Return(nil, nil).Once() // No QuizEvaluation found
 
 		llmEvalResult := &domain.Answer{Score: 0.77, Explanation: "Fresh LLM explanation"}
-		mockEvaluator.On("EvaluateAnswer", quizForEval.Question, quizForEval.ModelAnswers[0], req.UserAnswer, quizForEval.Keywords).Return(llmEvalResult, nil).Once()
+		mockEvaluator.On("EvaluateAnswer", mock.Anything, port.EvaluationInput{Question: quizForEval.Question, ModelAnswers: quizForEval.ModelAnswers, UserAnswer: req.UserAnswer, Keywords: quizForEval.Keywords}).Return(llmEvalResult, nil).Once()
 
 		// Construct the expected response for PutAnswerToCache
 		expectedResponseToCache := &dto.CheckAnswerResponse{
@@ -118,11 +123,13 @@ func TestCheckAnswer_With_AnswerCacheService(t *testing.T) { // Renamed test fun
 			Relevance:      llmEvalResult.Relevance,
 			Accuracy:       llmEvalResult.Accuracy,
 			ModelAnswer:    quizForEval.ModelAnswers[0],
+			JudgeCount:     1,
+			ScoredBy:       domain.ScoredByLLM,
 		}
 		mockAnswerCacheSvc.On("PutAnswerToCache", ctx, req.QuizID, req.UserAnswer, userAnswerEmbedding, expectedResponseToCache).Return(nil).Once()
 
-		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL)
-		response, err := service.CheckAnswer(&req)
+		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+		response, err := service.CheckAnswer(context.Background(), &req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, response)
@@ -149,17 +156,17 @@ func TestCheckAnswer_With_AnswerCacheService(t *testing.T) { // Renamed test fun
 
 		mockEmbSvc.On("Generate", ctx, req.UserAnswer).Return(nil, fmt.Errorf("embedding generation failed")).Once()
 
-		quizForEval := &domain.Quiz{ID: req.QuizID, Question: "Q_embed_fail", ModelAnswers: []string{"Model_embed_fail"}, Keywords: []string{"k_ef"}}
+		quizForEval := &domain.Quiz{ID: req.QuizID, Question: "Q_embed_fail", ModelAnswers: []string{"Model_embed_fail"}, Keywords: []string{"k_ef"}, Status: domain.QuizStatusPublished}
 		mockRepo.On("GetQuizByID", ctx, req.QuizID).Return(quizForEval, nil).Once() // Added ctx
 
 		// Mock GetQuizEvaluation call that happens during LLM evaluation
 		mockRepo.On("GetQuizEvaluation", ctx, req.QuizID).Return(nil, nil).Once() // No QuizEvaluation found
 
 		llmEvalResult := &domain.Answer{Score: 0.65, Explanation: "LLM fallback due to embedding fail"}
-		mockEvaluator.On("EvaluateAnswer", quizForEval.Question, quizForEval.ModelAnswers[0], req.UserAnswer, quizForEval.Keywords).Return(llmEvalResult, nil).Once()
+		mockEvaluator.On("EvaluateAnswer", mock.Anything, port.EvaluationInput{Question: quizForEval.Question, ModelAnswers: quizForEval.ModelAnswers, UserAnswer: req.UserAnswer, Keywords: quizForEval.Keywords}).Return(llmEvalResult, nil).Once()
 
-		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL)
-		response, err := service.CheckAnswer(&req)
+		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+		response, err := service.CheckAnswer(context.Background(), &req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, response)
@@ -188,17 +195,17 @@ func TestCheckAnswer_With_AnswerCacheService(t *testing.T) { // Renamed test fun
 		userAnswerEmbedding := []float32{0.1, 0.2, 0.3}
 		mockEmbSvc.On("Generate", ctx, req.UserAnswer).Return(userAnswerEmbedding, nil).Once()
 
-		quizForEval := &domain.Quiz{ID: req.QuizID, Question: "Q_nil_ans_cache", ModelAnswers: []string{"Model_nil_ans_cache"}, Keywords: []string{"k_nac"}}
+		quizForEval := &domain.Quiz{ID: req.QuizID, Question: "Q_nil_ans_cache", ModelAnswers: []string{"Model_nil_ans_cache"}, Keywords: []string{"k_nac"}, Status: domain.QuizStatusPublished}
 		mockRepo.On("GetQuizByID", ctx, req.QuizID).Return(quizForEval, nil).Once() // Added ctx
 
 		// Mock GetQuizEvaluation call that happens during LLM evaluation
 		mockRepo.On("GetQuizEvaluation", ctx, req.QuizID).Return(nil, nil).Once() // No QuizEvaluation found
 
 		llmEvalResult := &domain.Answer{Score: 0.60, Explanation: "LLM fallback, nil AnswerCacheService"}
-		mockEvaluator.On("EvaluateAnswer", quizForEval.Question, quizForEval.ModelAnswers[0], req.UserAnswer, quizForEval.Keywords).Return(llmEvalResult, nil).Once()
+		mockEvaluator.On("EvaluateAnswer", mock.Anything, port.EvaluationInput{Question: quizForEval.Question, ModelAnswers: quizForEval.ModelAnswers, UserAnswer: req.UserAnswer, Keywords: quizForEval.Keywords}).Return(llmEvalResult, nil).Once()
 
-		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, mockEmbSvc, nil, &MockTransactionManager{}, categoryListTTL, quizListTTL) // Pass nil for AnswerCacheService, added TTLs
-		response, err := service.CheckAnswer(&req)
+		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, mockEmbSvc, nil, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{}) // Pass nil for AnswerCacheService, added TTLs
+		response, err := service.CheckAnswer(context.Background(), &req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, response)
@@ -222,18 +229,18 @@ func TestCheckAnswer_With_AnswerCacheService(t *testing.T) { // Renamed test fun
 		quizListTTL, _ := time.ParseDuration("1h")
 
 		// EmbeddingService is nil
-		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, nil, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL) // Pass nil for EmbeddingService
+		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, nil, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{}) // Pass nil for EmbeddingService
 
-		quizForEval := &domain.Quiz{ID: req.QuizID, Question: "Q_nil_embed_svc", ModelAnswers: []string{"Model_nil_embed_svc"}, Keywords: []string{"k_nes"}}
+		quizForEval := &domain.Quiz{ID: req.QuizID, Question: "Q_nil_embed_svc", ModelAnswers: []string{"Model_nil_embed_svc"}, Keywords: []string{"k_nes"}, Status: domain.QuizStatusPublished}
 		mockRepo.On("GetQuizByID", ctx, req.QuizID).Return(quizForEval, nil).Once() // Added ctx
 
 		// Mock GetQuizEvaluation call that happens during LLM evaluation
 		mockRepo.On("GetQuizEvaluation", ctx, req.QuizID).Return(nil, nil).Once() // No QuizEvaluation found
 
 		llmEvalResult := &domain.Answer{Score: 0.55, Explanation: "LLM fallback, nil EmbeddingService"}
-		mockEvaluator.On("EvaluateAnswer", quizForEval.Question, quizForEval.ModelAnswers[0], req.UserAnswer, quizForEval.Keywords).Return(llmEvalResult, nil).Once()
+		mockEvaluator.On("EvaluateAnswer", mock.Anything, port.EvaluationInput{Question: quizForEval.Question, ModelAnswers: quizForEval.ModelAnswers, UserAnswer: req.UserAnswer, Keywords: quizForEval.Keywords}).Return(llmEvalResult, nil).Once()
 
-		response, err := service.CheckAnswer(&req)
+		response, err := service.CheckAnswer(context.Background(), &req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, response)
@@ -245,7 +252,7 @@ func TestCheckAnswer_With_AnswerCacheService(t *testing.T) { // Renamed test fun
 		mockEvaluator.AssertExpectations(t)
 	})
 
-	t.Run("GetQuizByID Fails during LLM Fallback", func(t *testing.T) {
+	t.Run("GetQuizByID Fails Before The Cache Lookup", func(t *testing.T) {
 		req := *baseReq
 
 		mockRepo := new(MockQuizRepository)
@@ -257,15 +264,11 @@ func TestCheckAnswer_With_AnswerCacheService(t *testing.T) { // Renamed test fun
 		categoryListTTL, _ := time.ParseDuration("1h")
 		quizListTTL, _ := time.ParseDuration("1h")
 
-		userAnswerEmbedding := []float32{0.1, 0.2, 0.3}
-		mockEmbSvc.On("Generate", ctx, req.UserAnswer).Return(userAnswerEmbedding, nil).Once()
-		mockAnswerCacheSvc.On("GetAnswerFromCache", ctx, req.QuizID, userAnswerEmbedding, req.UserAnswer).Return(nil, nil).Once() // Cache miss
-
 		expectedRepoError := fmt.Errorf("database is down")
 		mockRepo.On("GetQuizByID", ctx, req.QuizID).Return(nil, expectedRepoError).Once()
 
-		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL)
-		_, err := service.CheckAnswer(&req)
+		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+		_, err := service.CheckAnswer(context.Background(), &req)
 
 		assert.Error(t, err)
 		var domainErr *domain.DomainError
@@ -284,13 +287,13 @@ func TestCheckAnswer_With_AnswerCacheService(t *testing.T) { // Renamed test fun
 			assert.True(t, errors.Is(err, expectedRepoError), "Original repo error should be discoverable")
 		}
 
-		mockEmbSvc.AssertExpectations(t)
-		mockAnswerCacheSvc.AssertExpectations(t)
+		mockEmbSvc.AssertNotCalled(t, "Generate")
+		mockAnswerCacheSvc.AssertNotCalled(t, "GetAnswerFromCache")
 		mockRepo.AssertExpectations(t)
 		mockEvaluator.AssertNotCalled(t, "EvaluateAnswer")
 	})
 
-	t.Run("GetQuizByID Returns NotFound during LLM Fallback", func(t *testing.T) {
+	t.Run("GetQuizByID Returns NotFound Before The Cache Lookup", func(t *testing.T) {
 		req := *baseReq
 
 		mockRepo := new(MockQuizRepository)
@@ -302,15 +305,11 @@ func TestCheckAnswer_With_AnswerCacheService(t *testing.T) { // Renamed test fun
 		categoryListTTL, _ := time.ParseDuration("1h")
 		quizListTTL, _ := time.ParseDuration("1h")
 
-		userAnswerEmbedding := []float32{0.1, 0.2, 0.3}
-		mockEmbSvc.On("Generate", ctx, req.UserAnswer).Return(userAnswerEmbedding, nil).Once()
-		mockAnswerCacheSvc.On("GetAnswerFromCache", ctx, req.QuizID, userAnswerEmbedding, req.UserAnswer).Return(nil, nil).Once() // Cache miss
-
 		// Simulate GetQuizByID finding no quiz (repo returns nil, nil for ErrNoRows)
 		mockRepo.On("GetQuizByID", ctx, req.QuizID).Return(nil, nil).Once()
 
-		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL)
-		_, err := service.CheckAnswer(&req)
+		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+		_, err := service.CheckAnswer(context.Background(), &req)
 
 		assert.Error(t, err)
 		var domainErr *domain.DomainError
@@ -319,16 +318,456 @@ func TestCheckAnswer_With_AnswerCacheService(t *testing.T) { // Renamed test fun
 			assert.Equal(t, domain.CodeQuizNotFound, domainErr.Code)
 		}
 
-		mockEmbSvc.AssertExpectations(t)
-		mockAnswerCacheSvc.AssertExpectations(t)
+		mockEmbSvc.AssertNotCalled(t, "Generate")
+		mockAnswerCacheSvc.AssertNotCalled(t, "GetAnswerFromCache")
 		mockRepo.AssertExpectations(t)
 		mockEvaluator.AssertNotCalled(t, "EvaluateAnswer")
 	})
 
+	t.Run("Unpublished Quiz Is Not Found", func(t *testing.T) {
+		req := *baseReq
+		for _, status := range []string{domain.QuizStatusDraft, domain.QuizStatusInReview, domain.QuizStatusRetired} {
+			mockRepo := new(MockQuizRepository)
+			mockEvaluator := new(MockAnswerEvaluator)
+			mockEmbSvc := new(MockEmbeddingService)
+			mockAnswerCacheSvc := new(MockAnswerCacheService)
+			mockRepo.On("GetQuizByID", ctx, req.QuizID).Return(&domain.Quiz{ID: req.QuizID, ModelAnswers: []string{"Model Ans"}, Status: status}, nil).Once()
+
+			service := NewQuizService(mockRepo, mockEvaluator, new(MockCache), mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, time.Hour, time.Hour, config.CheckAnswerConfig{})
+			resp, err := service.CheckAnswer(ctx, &req)
+
+			assert.Nil(t, resp, status)
+			assert.ErrorIs(t, err, domain.ErrQuizNotFound, status)
+			// Neither a cached evaluation nor a new one reveals the model answer
+			mockAnswerCacheSvc.AssertNotCalled(t, "GetAnswerFromCache")
+			mockEvaluator.AssertNotCalled(t, "EvaluateAnswer")
+		}
+	})
+
+	t.Run("Rubric Is Passed To Evaluator And Reported In Response", func(t *testing.T) {
+		req := *baseReq
+
+		mockRepo := new(MockQuizRepository)
+		mockEvaluator := new(MockAnswerEvaluator)
+		mockDirectCache := new(MockCache)
+
+		quizForEval := &domain.Quiz{ID: req.QuizID, Question: "Q1", ModelAnswers: []string{"Model Ans 1", "Model Ans 2"}, Keywords: []string{"k1"}, Status: domain.QuizStatusPublished}
+		rubric := &domain.QuizEvaluation{QuizID: req.QuizID, RequiredTopics: []string{"topic A", "topic B"}, RubricDetails: "details"}
+		mockRepo.On("GetQuizByID", ctx, req.QuizID).Return(quizForEval, nil).Once()
+		mockRepo.On("GetQuizEvaluation", ctx, req.QuizID).Return(rubric, nil).Once()
+
+		expectedInput := port.EvaluationInput{
+			Question:     quizForEval.Question,
+			ModelAnswers: quizForEval.ModelAnswers,
+			UserAnswer:   req.UserAnswer,
+			Keywords:     quizForEval.Keywords,
+			Rubric:       rubric,
+		}
+		llmEvalResult := &domain.Answer{Score: 0.5, Explanation: "Half", RubricSatisfied: []string{"topic A"}, RubricMissed: []string{"topic B"}}
+		mockEvaluator.On("EvaluateAnswer", mock.Anything, expectedInput).Return(llmEvalResult, nil).Once()
+
+		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, nil, nil, &MockTransactionManager{}, time.Hour, time.Hour, config.CheckAnswerConfig{})
+		response, err := service.CheckAnswer(context.Background(), &req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"topic A"}, response.RubricSatisfied)
+		assert.Equal(t, []string{"topic B"}, response.RubricMissed)
+		assert.Equal(t, "Model Ans 1\nModel Ans 2", response.ModelAnswer)
+		assert.Equal(t, 1, response.JudgeCount)
+		assert.Empty(t, response.Confidence)
+		mockRepo.AssertExpectations(t)
+		mockEvaluator.AssertExpectations(t)
+	})
+
+	t.Run("Evaluation Budget Exceeded Returns EVALUATION_TIMEOUT", func(t *testing.T) {
+		req := *baseReq
+
+		mockRepo := new(MockQuizRepository)
+		mockEvaluator := new(MockAnswerEvaluator)
+		mockAnswerCacheSvc := new(MockAnswerCacheService)
+		mockDirectCache := new(MockCache)
+
+		quizForEval := &domain.Quiz{ID: req.QuizID, Question: "Q1", ModelAnswers: []string{"Model Ans"}, Keywords: []string{"k1"}, Status: domain.QuizStatusPublished}
+		mockRepo.On("GetQuizByID", ctx, req.QuizID).Return(quizForEval, nil).Once()
+		mockRepo.On("GetQuizEvaluation", ctx, req.QuizID).Return(nil, nil).Once()
+
+		// The evaluator blocks until its context expires, like a slow LLM honouring ctx.
+		mockEvaluator.On("EvaluateAnswer", mock.Anything, port.EvaluationInput{Question: quizForEval.Question, ModelAnswers: quizForEval.ModelAnswers, UserAnswer: req.UserAnswer, Keywords: quizForEval.Keywords}).
+			Run(func(args mock.Arguments) {
+				<-args.Get(0).(context.Context).Done()
+			}).
+			Return(nil, context.DeadlineExceeded).Once()
+
+		budgets := config.CheckAnswerConfig{Evaluation: 20 * time.Millisecond}
+		service := NewQuizService(mockRepo, mockEvaluator, mockDirectCache, nil, mockAnswerCacheSvc, &MockTransactionManager{}, time.Hour, time.Hour, budgets)
+
+		start := time.Now()
+		_, err := service.CheckAnswer(context.Background(), &req)
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.ErrorIs(t, err, domain.ErrEvaluationTimeout)
+		var domainErr *domain.DomainError
+		if assert.True(t, errors.As(err, &domainErr)) {
+			assert.Equal(t, domain.CodeEvaluationTimeout, domainErr.Code)
+			assert.Equal(t, "evaluation", domainErr.Context["stage"])
+		}
+		mockAnswerCacheSvc.AssertNotCalled(t, "PutAnswerToCache")
+	})
+
+	t.Run("Canceled Request Context Stops The Evaluation In Progress", func(t *testing.T) {
+		req := *baseReq
+
+		mockRepo := new(MockQuizRepository)
+		mockEvaluator := new(MockAnswerEvaluator)
+		mockAnswerCacheSvc := new(MockAnswerCacheService)
+
+		quizForEval := &domain.Quiz{ID: req.QuizID, Question: "Q1", ModelAnswers: []string{"Model Ans"}, Keywords: []string{"k1"}, Status: domain.QuizStatusPublished}
+		mockRepo.On("GetQuizByID", mock.Anything, req.QuizID).Return(quizForEval, nil).Once()
+		mockRepo.On("GetQuizEvaluation", mock.Anything, req.QuizID).Return(nil, nil).Once()
+
+		requestCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		evaluating := make(chan struct{})
+		mockEvaluator.On("EvaluateAnswer", mock.Anything, mock.AnythingOfType("port.EvaluationInput")).
+			Run(func(args mock.Arguments) {
+				close(evaluating)
+				<-args.Get(0).(context.Context).Done()
+			}).
+			Return(nil, context.Canceled).Once()
+		go func() {
+			<-evaluating
+			cancel() // The client goes away while the LLM is grading
+		}()
+
+		service := NewQuizService(mockRepo, mockEvaluator, new(MockCache), nil, mockAnswerCacheSvc, &MockTransactionManager{}, time.Hour, time.Hour, config.CheckAnswerConfig{})
+		start := time.Now()
+		_, err := service.CheckAnswer(requestCtx, &req)
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.ErrorIs(t, err, context.Canceled)
+		mockEvaluator.AssertExpectations(t)
+		mockAnswerCacheSvc.AssertNotCalled(t, "PutAnswerToCache")
+	})
+
+	t.Run("Canceled Request Context Stops Before Evaluation", func(t *testing.T) {
+		req := *baseReq
+
+		mockRepo := new(MockQuizRepository)
+		mockEvaluator := new(MockAnswerEvaluator)
+		mockEmbSvc := new(MockEmbeddingService)
+		mockAnswerCacheSvc := new(MockAnswerCacheService)
+
+		canceledCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+		mockRepo.On("GetQuizByID", mock.Anything, req.QuizID).Return(&domain.Quiz{ID: req.QuizID, ModelAnswers: []string{"Model Ans"}, Status: domain.QuizStatusPublished}, nil).Once()
+		mockEmbSvc.On("Generate", canceledCtx, req.UserAnswer).Return(nil, context.Canceled).Once()
+
+		service := NewQuizService(mockRepo, mockEvaluator, new(MockCache), mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, time.Hour, time.Hour, config.CheckAnswerConfig{})
+		_, err := service.CheckAnswer(canceledCtx, &req)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		mockEvaluator.AssertNotCalled(t, "EvaluateAnswer")
+	})
+}
+
+func TestCheckAnswerStream(t *testing.T) {
+	req := &dto.CheckAnswerRequest{QuizID: "quiz-stream", UserAnswer: "Goroutines are multiplexed onto threads"}
+	quiz := &domain.Quiz{ID: req.QuizID, Question: "What is a goroutine?", ModelAnswers: []string{"A lightweight thread"}, Keywords: []string{"thread"}, Status: domain.QuizStatusPublished}
+	input := port.EvaluationInput{Question: quiz.Question, ModelAnswers: quiz.ModelAnswers, UserAnswer: req.UserAnswer, Keywords: quiz.Keywords}
+	answer := &domain.Answer{Score: 0.8, Explanation: "Mostly right.", KeywordMatches: []string{"thread"}}
+
+	collect := func(events *[]dto.CheckAnswerStreamEvent) func(dto.CheckAnswerStreamEvent) {
+		return func(event dto.CheckAnswerStreamEvent) { *events = append(*events, event) }
+	}
+
+	t.Run("Relays cache miss and explanation deltas in order", func(t *testing.T) {
+		mockRepo := new(MockQuizRepository)
+		mockEvaluator := new(MockStreamingAnswerEvaluator)
+		mockRepo.On("GetQuizByID", mock.Anything, req.QuizID).Return(quiz, nil).Once()
+		mockRepo.On("GetQuizEvaluation", mock.Anything, req.QuizID).Return(nil, nil).Once()
+		mockEvaluator.On("EvaluateAnswerStream", mock.Anything, input, mock.Anything).
+			Run(func(args mock.Arguments) {
+				onExplanation := args.Get(2).(func(string))
+				onExplanation("Mostly ")
+				onExplanation("right.")
+			}).
+			Return(answer, nil).Once()
+
+		service := NewQuizService(mockRepo, mockEvaluator, new(MockCache), nil, nil, &MockTransactionManager{}, time.Hour, time.Hour, config.CheckAnswerConfig{})
+		var events []dto.CheckAnswerStreamEvent
+		response, err := service.CheckAnswerStream(context.Background(), req, collect(&events))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Mostly right.", response.Explanation)
+		assert.Equal(t, []dto.CheckAnswerStreamEvent{
+			{Event: dto.CheckStreamEventCache, Data: dto.CacheLookupEvent{Hit: false}},
+			{Event: dto.CheckStreamEventExplanation, Data: dto.ExplanationDeltaEvent{Delta: "Mostly "}},
+			{Event: dto.CheckStreamEventExplanation, Data: dto.ExplanationDeltaEvent{Delta: "right."}},
+		}, events)
+		mockEvaluator.AssertNotCalled(t, "EvaluateAnswer")
+	})
+
+	t.Run("Does Not Stream An Explanation The Rubric Replaces", func(t *testing.T) {
+		mockRepo := new(MockQuizRepository)
+		mockEvaluator := new(MockStreamingAnswerEvaluator)
+		rubric := &domain.QuizEvaluation{
+			QuizID:      req.QuizID,
+			ScoreRanges: []string{"0.0-0.5", "0.5-1.0"},
+			ScoreEvaluations: []domain.ScoreEvaluationDetail{
+				{ScoreRange: "0.0-0.5", Explanation: "Revisit how goroutines are scheduled."},
+				{ScoreRange: "0.5-1.0", Explanation: "Good grasp of goroutines."},
+			},
+		}
+		mockRepo.On("GetQuizByID", mock.Anything, req.QuizID).Return(quiz, nil).Once()
+		mockRepo.On("GetQuizEvaluation", mock.Anything, req.QuizID).Return(rubric, nil).Once()
+		rubricInput := input
+		rubricInput.Rubric = rubric
+		mockEvaluator.On("EvaluateAnswer", mock.Anything, rubricInput).Return(&domain.Answer{Score: 0.8, Explanation: "Mostly right."}, nil).Once()
+
+		service := NewQuizService(mockRepo, mockEvaluator, new(MockCache), nil, nil, &MockTransactionManager{}, time.Hour, time.Hour, config.CheckAnswerConfig{})
+		var events []dto.CheckAnswerStreamEvent
+		response, err := service.CheckAnswerStream(context.Background(), req, collect(&events))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Good grasp of goroutines.", response.Explanation)
+		assert.Equal(t, []dto.CheckAnswerStreamEvent{
+			{Event: dto.CheckStreamEventCache, Data: dto.CacheLookupEvent{Hit: false}},
+		}, events)
+		mockEvaluator.AssertNotCalled(t, "EvaluateAnswerStream", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Identical Concurrent Answers Share One Stream", func(t *testing.T) {
+		mockRepo := new(MockQuizRepository)
+		mockEvaluator := new(MockStreamingAnswerEvaluator)
+		mockRepo.On("GetQuizByID", mock.Anything, req.QuizID).Return(quiz, nil).Twice() // Each caller checks the quiz is published
+		mockRepo.On("GetQuizEvaluation", mock.Anything, req.QuizID).Return(nil, nil).Once()
+
+		proceed := make(chan struct{})
+		mockEvaluator.On("EvaluateAnswerStream", mock.Anything, input, mock.Anything).
+			Run(func(args mock.Arguments) {
+				onExplanation := args.Get(2).(func(string))
+				onExplanation("Mostly ")
+				<-proceed
+				onExplanation("right.")
+			}).
+			Return(answer, nil).Once()
+
+		service := NewQuizService(mockRepo, mockEvaluator, new(MockCache), nil, nil, &MockTransactionManager{}, time.Hour, time.Hour, config.CheckAnswerConfig{})
+		streams := service.(*quizService).explanations
+		sfKey := fmt.Sprintf("check_answer:%s:%x", req.QuizID, sha256.Sum256([]byte(req.UserAnswer)))
+
+		var first, second []dto.CheckAnswerStreamEvent
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := service.CheckAnswerStream(context.Background(), req, collect(&first))
+			assert.NoError(t, err)
+		}()
+		// The leader and its caller hold the stream; the second caller joins once it is running.
+		assert.Eventually(t, func() bool { return streams.refCount(sfKey) == 2 }, time.Second, time.Millisecond)
+		go func() {
+			defer wg.Done()
+			_, err := service.CheckAnswerStream(context.Background(), req, collect(&second))
+			assert.NoError(t, err)
+		}()
+		assert.Eventually(t, func() bool { return streams.refCount(sfKey) == 3 }, time.Second, time.Millisecond)
+		close(proceed)
+		wg.Wait()
+
+		assert.Equal(t, first, second)
+		assert.Len(t, first, 3)
+		assert.Equal(t, 0, streams.refCount(sfKey))
+		mockEvaluator.AssertExpectations(t)
+	})
 }
 
 // TestInvalidateQuizCache has been removed as the method is no longer part of the QuizService interface.
 
+// --- Tests for GetRandomQuiz ---
+func TestGetRandomQuiz_SubCategoryAndFilters(t *testing.T) {
+	ctx := context.Background()
+	categoryListTTL, _ := time.ParseDuration("1h")
+	quizListTTL, _ := time.ParseDuration("1h")
+	subCategoryID := "01HSUBCATEGORY000000000001"
+	quiz := &domain.Quiz{
+		ID:            "01HQUIZ0000000000000000001",
+		Question:      "What is a goroutine?",
+		Keywords:      []string{"goroutine", "concurrency"},
+		Difficulty:    domain.DifficultyHard,
+		SubCategoryID: subCategoryID,
+	}
+
+	t.Run("Resolves Sub Category By Name And Applies Filters", func(t *testing.T) {
+		mockRepo := new(MockQuizRepository)
+		service := NewQuizService(mockRepo, nil, nil, nil, nil, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+
+		expectedFilter := &domain.QuizFilter{
+			Difficulty: domain.DifficultyHard,
+			Keywords:   []string{"goroutine"},
+			ExcludeIDs: []string{"01HQUIZ0000000000000000002"},
+		}
+		mockRepo.On("GetSubCategoryIDByName", ctx, "golang").Return(subCategoryID, nil).Once()
+		mockRepo.On("GetRandomQuizBySubCategory", ctx, subCategoryID, expectedFilter).Return(quiz, nil).Once()
+
+		resp, err := service.GetRandomQuiz(&dto.RandomQuizRequest{
+			SubCategory: "golang",
+			Difficulty:  "hard",
+			Keywords:    []string{"goroutine"},
+			ExcludeIDs:  []string{"01HQUIZ0000000000000000002"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, quiz.ID, resp.ID)
+		assert.Equal(t, "hard", resp.DiffLevel)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Resolves Sub Category By ID", func(t *testing.T) {
+		mockRepo := new(MockQuizRepository)
+		service := NewQuizService(mockRepo, nil, nil, nil, nil, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+
+		mockRepo.On("GetSubCategoryIDByName", ctx, subCategoryID).Return("", nil).Once()
+		mockRepo.On("GetAllSubCategories", ctx).Return([]string{"01HSUBCATEGORY000000000009", subCategoryID}, nil).Once()
+		mockRepo.On("GetRandomQuizBySubCategory", ctx, subCategoryID, &domain.QuizFilter{}).Return(quiz, nil).Once()
+
+		resp, err := service.GetRandomQuiz(&dto.RandomQuizRequest{SubCategory: subCategoryID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, quiz.ID, resp.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Sub Category", func(t *testing.T) {
+		mockRepo := new(MockQuizRepository)
+		service := NewQuizService(mockRepo, nil, nil, nil, nil, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+
+		mockRepo.On("GetSubCategoryIDByName", ctx, "unknown").Return("", nil).Once()
+		mockRepo.On("GetAllSubCategories", ctx).Return([]string{subCategoryID}, nil).Once()
+
+		resp, err := service.GetRandomQuiz(&dto.RandomQuizRequest{SubCategory: "unknown"})
+
+		assert.Nil(t, resp)
+		var domainErr *domain.DomainError
+		assert.True(t, errors.As(err, &domainErr))
+		assert.Equal(t, domain.CodeInvalidCategory, domainErr.Code)
+		mockRepo.AssertNotCalled(t, "GetRandomQuizBySubCategory")
+	})
+
+	t.Run("No Quiz Matches Filters", func(t *testing.T) {
+		mockRepo := new(MockQuizRepository)
+		service := NewQuizService(mockRepo, nil, nil, nil, nil, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+
+		mockRepo.On("GetSubCategoryIDByName", ctx, "golang").Return(subCategoryID, nil).Once()
+		mockRepo.On("GetRandomQuizBySubCategory", ctx, subCategoryID, &domain.QuizFilter{Difficulty: domain.DifficultyEasy}).Return(nil, nil).Once()
+
+		resp, err := service.GetRandomQuiz(&dto.RandomQuizRequest{SubCategory: "golang", Difficulty: "easy"})
+
+		assert.Nil(t, resp)
+		var domainErr *domain.DomainError
+		assert.True(t, errors.As(err, &domainErr))
+		assert.Equal(t, domain.CodeNotFound, domainErr.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// --- Tests for GetNextQuiz ---
+func TestGetNextQuiz(t *testing.T) {
+	ctx := context.Background()
+	categoryListTTL, _ := time.ParseDuration("1h")
+	quizListTTL, _ := time.ParseDuration("1h")
+	current := &domain.Quiz{ID: "01HQUIZ0000000000000000001", Question: "Current?", Difficulty: domain.DifficultyMedium, SubCategoryID: "01HSUBCATEGORY000000000001", Status: domain.QuizStatusPublished}
+	next := &domain.Quiz{ID: "01HQUIZ0000000000000000002", Question: "Next?", Keywords: []string{"k"}, Difficulty: domain.DifficultyHard, SubCategoryID: current.SubCategoryID}
+	highScore, lowScore := 0.9, 0.2
+
+	t.Run("High Score Moves To Harder Quiz And Excludes Attempts", func(t *testing.T) {
+		mockRepo := new(MockQuizRepository)
+		service := NewQuizService(mockRepo, nil, nil, nil, nil, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+
+		mockRepo.On("GetQuizByID", ctx, current.ID).Return(current, nil).Once()
+		mockRepo.On("GetSimilarQuiz", ctx, current.ID, &domain.QuizFilter{Difficulty: domain.DifficultyHard, ExcludeAttemptedByUserID: "user-1"}).Return(next, nil).Once()
+
+		resp, err := service.GetNextQuiz(&dto.NextQuizRequest{CurrentQuizID: current.ID, LastScore: &highScore, UserID: "user-1"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, next.ID, resp.ID)
+		assert.Equal(t, "hard", resp.DiffLevel)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Low Score Falls Back To Current Difficulty", func(t *testing.T) {
+		mockRepo := new(MockQuizRepository)
+		service := NewQuizService(mockRepo, nil, nil, nil, nil, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+
+		sameLevel := &domain.Quiz{ID: "01HQUIZ0000000000000000003", Difficulty: domain.DifficultyMedium}
+		mockRepo.On("GetQuizByID", ctx, current.ID).Return(current, nil).Once()
+		mockRepo.On("GetSimilarQuiz", ctx, current.ID, &domain.QuizFilter{Difficulty: domain.DifficultyEasy}).Return(nil, nil).Once()
+		mockRepo.On("GetSimilarQuiz", ctx, current.ID, &domain.QuizFilter{Difficulty: domain.DifficultyMedium}).Return(sameLevel, nil).Once()
+
+		resp, err := service.GetNextQuiz(&dto.NextQuizRequest{CurrentQuizID: current.ID, LastScore: &lowScore})
+
+		assert.NoError(t, err)
+		assert.Equal(t, sameLevel.ID, resp.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Current Quiz Not Found", func(t *testing.T) {
+		mockRepo := new(MockQuizRepository)
+		service := NewQuizService(mockRepo, nil, nil, nil, nil, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+
+		mockRepo.On("GetQuizByID", ctx, current.ID).Return(nil, nil).Once()
+
+		resp, err := service.GetNextQuiz(&dto.NextQuizRequest{CurrentQuizID: current.ID})
+
+		assert.Nil(t, resp)
+		var domainErr *domain.DomainError
+		assert.True(t, errors.As(err, &domainErr))
+		assert.Equal(t, domain.CodeQuizNotFound, domainErr.Code)
+	})
+
+	t.Run("Current Quiz Not Published", func(t *testing.T) {
+		for _, status := range []string{domain.QuizStatusDraft, domain.QuizStatusInReview, domain.QuizStatusRetired} {
+			mockRepo := new(MockQuizRepository)
+			service := NewQuizService(mockRepo, nil, nil, nil, nil, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+			unpublished := *current
+			unpublished.Status = status
+			mockRepo.On("GetQuizByID", ctx, current.ID).Return(&unpublished, nil).Once()
+
+			resp, err := service.GetNextQuiz(&dto.NextQuizRequest{CurrentQuizID: current.ID})
+
+			assert.Nil(t, resp, status)
+			assert.ErrorIs(t, err, domain.ErrQuizNotFound, status)
+			mockRepo.AssertNotCalled(t, "GetSimilarQuiz")
+		}
+	})
+
+	t.Run("No Next Quiz Available", func(t *testing.T) {
+		mockRepo := new(MockQuizRepository)
+		service := NewQuizService(mockRepo, nil, nil, nil, nil, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
+
+		mockRepo.On("GetQuizByID", ctx, current.ID).Return(current, nil).Once()
+		mockRepo.On("GetSimilarQuiz", ctx, current.ID, &domain.QuizFilter{Difficulty: domain.DifficultyMedium}).Return(nil, nil).Once()
+
+		resp, err := service.GetNextQuiz(&dto.NextQuizRequest{CurrentQuizID: current.ID})
+
+		assert.Nil(t, resp)
+		var domainErr *domain.DomainError
+		assert.True(t, errors.As(err, &domainErr))
+		assert.Equal(t, domain.CodeNotFound, domainErr.Code)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestNextDifficulty(t *testing.T) {
+	score := func(v float64) *float64 { return &v }
+	assert.Equal(t, domain.DifficultyMedium, nextDifficulty(domain.DifficultyMedium, nil))
+	assert.Equal(t, domain.DifficultyHard, nextDifficulty(domain.DifficultyMedium, score(NextQuizHighScoreThreshold)))
+	assert.Equal(t, domain.DifficultyHard, nextDifficulty(domain.DifficultyHard, score(1.0)))
+	assert.Equal(t, domain.DifficultyEasy, nextDifficulty(domain.DifficultyMedium, score(0.1)))
+	assert.Equal(t, domain.DifficultyEasy, nextDifficulty(domain.DifficultyEasy, score(0.0)))
+	assert.Equal(t, domain.DifficultyMedium, nextDifficulty(domain.DifficultyMedium, score(0.6)))
+}
+
 // --- Tests for GetAllSubCategories Caching ---
 func TestGetAllSubCategories_Caching(t *testing.T) {
 	ctx := context.Background()
@@ -352,7 +791,7 @@ func TestGetAllSubCategories_Caching(t *testing.T) {
 		categoryListTTL, _ := time.ParseDuration(testCategoryListTTLString)
 		quizListTTL, _ := time.ParseDuration("1h") // Dummy for this test
 
-		service := NewQuizService(mockRepo, mockEvaluator, mockCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL)
+		service := NewQuizService(mockRepo, mockEvaluator, mockCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
 
 		// Cache Miss
 		mockCache.On("Get", ctx, cacheKey).Return("", domain.ErrCacheMiss).Once()
@@ -384,7 +823,7 @@ func TestGetAllSubCategories_Caching(t *testing.T) {
 		categoryListTTL, _ := time.ParseDuration(testCategoryListTTLString)
 		quizListTTL, _ := time.ParseDuration("1h")
 
-		service := NewQuizService(mockRepo, mockEvaluator, mockCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL)
+		service := NewQuizService(mockRepo, mockEvaluator, mockCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
 
 		// Gob encode expected categories for cache hit
 		var expectedBuffer bytes.Buffer
@@ -494,7 +933,7 @@ func TestGetBulkQuizzes_Caching(t *testing.T) {
 		categoryListTTL, _ := time.ParseDuration("1h") // Dummy
 		quizListTTL, _ := time.ParseDuration(testQuizListTTLString)
 
-		service := NewQuizService(mockRepo, mockEvaluator, mockCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL)
+		service := NewQuizService(mockRepo, mockEvaluator, mockCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
 
 		mockRepo.On("GetSubCategoryIDByName", ctx, subCategoryName).Return(subCategoryID, nil).Once() // Added ctx
 		mockCache.On("Get", ctx, cacheKey).Return("", domain.ErrCacheMiss).Once()
@@ -526,7 +965,7 @@ func TestGetBulkQuizzes_Caching(t *testing.T) {
 		categoryListTTL, _ := time.ParseDuration("1h")
 		quizListTTL, _ := time.ParseDuration(testQuizListTTLString)
 
-		service := NewQuizService(mockRepo, mockEvaluator, mockCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL)
+		service := NewQuizService(mockRepo, mockEvaluator, mockCache, mockEmbSvc, mockAnswerCacheSvc, &MockTransactionManager{}, categoryListTTL, quizListTTL, config.CheckAnswerConfig{})
 
 		var expectedBuffer bytes.Buffer
 		enc := gob.NewEncoder(&expectedBuffer)
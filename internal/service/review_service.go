@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultReviewQueueLimit = 50
+	maxReviewQueueLimit     = 200
+)
+
+// ReviewService runs the editorial workflow of quizzes: drafts, e.g. from batch generation, wait in a
+// queue until a reviewer approves, edits or rejects them. Every status change is audited.
+type ReviewService interface {
+	// ListReviewQueue lists the quizzes in status, or in draft and in review when status is empty.
+	ListReviewQueue(ctx context.Context, status string, limit int) (*dto.ReviewQueueResponse, error)
+	// ApproveQuiz publishes a draft or a quiz in review.
+	ApproveQuiz(ctx context.Context, quizID string, comment string, reviewer string) (*dto.QuizDetailResponse, error)
+	// RejectQuiz retires a draft or a quiz in review; the comment is required.
+	RejectQuiz(ctx context.Context, quizID string, comment string, reviewer string) (*dto.QuizDetailResponse, error)
+	// EditQuiz saves a reviewer's edit as a new revision and leaves the quiz in review.
+	EditQuiz(ctx context.Context, quizID string, req *dto.ReviewEditRequest, reviewer string) (*dto.QuizDetailResponse, error)
+	// ChangeQuizStatus moves a quiz to any status its current status allows, e.g. to retire a published quiz.
+	ChangeQuizStatus(ctx context.Context, quizID string, req *dto.QuizStatusRequest, actor string) (*dto.QuizDetailResponse, error)
+	GetQuizStatusHistory(ctx context.Context, quizID string) (*dto.QuizStatusHistoryResponse, error)
+}
+
+type reviewServiceImpl struct {
+	quizRepo       domain.QuizRepository
+	statusRepo     domain.QuizStatusRepository
+	contentService ContentService
+	quizService    QuizService
+	txManager      domain.TransactionManager
+}
+
+// NewReviewService creates a new instance of ReviewService.
+// Edits go through contentService so they are saved as revisions; quizService invalidates the quiz lists
+// when a quiz starts or stops being served.
+func NewReviewService(quizRepo domain.QuizRepository, statusRepo domain.QuizStatusRepository, contentService ContentService, quizService QuizService, txManager domain.TransactionManager) ReviewService {
+	return &reviewServiceImpl{quizRepo: quizRepo, statusRepo: statusRepo, contentService: contentService, quizService: quizService, txManager: txManager}
+}
+
+// ListReviewQueue implements ReviewService.
+func (s *reviewServiceImpl) ListReviewQueue(ctx context.Context, status string, limit int) (*dto.ReviewQueueResponse, error) {
+	statuses := []string{domain.QuizStatusDraft, domain.QuizStatusInReview}
+	if status != "" {
+		if !domain.IsValidQuizStatus(status) {
+			return nil, domain.NewValidationError(fmt.Sprintf("unknown quiz status %q", status))
+		}
+		statuses = []string{status}
+	}
+	if limit <= 0 {
+		limit = defaultReviewQueueLimit
+	}
+	if limit > maxReviewQueueLimit {
+		limit = maxReviewQueueLimit
+	}
+
+	quizzes, err := s.statusRepo.ListQuizzesByStatus(ctx, statuses, limit)
+	if err != nil {
+		return nil, domain.NewInternalError("failed to list the review queue", err)
+	}
+	resp := &dto.ReviewQueueResponse{Quizzes: make([]dto.QuizDetailResponse, 0, len(quizzes))}
+	for _, quiz := range quizzes {
+		resp.Quizzes = append(resp.Quizzes, toQuizDetailResponse(quiz))
+	}
+	return resp, nil
+}
+
+// ApproveQuiz implements ReviewService.
+func (s *reviewServiceImpl) ApproveQuiz(ctx context.Context, quizID string, comment string, reviewer string) (*dto.QuizDetailResponse, error) {
+	return s.decide(ctx, quizID, domain.QuizStatusPublished, comment, reviewer)
+}
+
+// RejectQuiz implements ReviewService.
+func (s *reviewServiceImpl) RejectQuiz(ctx context.Context, quizID string, comment string, reviewer string) (*dto.QuizDetailResponse, error) {
+	if strings.TrimSpace(comment) == "" {
+		return nil, domain.NewValidationError("a comment explaining the rejection is required")
+	}
+	return s.decide(ctx, quizID, domain.QuizStatusRetired, comment, reviewer)
+}
+
+// EditQuiz implements ReviewService. The edit is audited even when the quiz was already in review.
+// The status change and the edit are saved in one transaction, so a quiz published or retired in the
+// meantime is not edited: the status change only applies to the status the edit was checked against.
+// Quizzes waiting for review are not served, so no quiz list changes.
+func (s *reviewServiceImpl) EditQuiz(ctx context.Context, quizID string, req *dto.ReviewEditRequest, reviewer string) (*dto.QuizDetailResponse, error) {
+	return s.contentService.UpdateQuizWith(ctx, quizID, &req.QuizRequest, reviewer, func(ctx context.Context, quiz *domain.Quiz) error {
+		if err := checkReviewable(quiz); err != nil {
+			return err
+		}
+		return s.saveStatusChange(ctx, quiz, domain.QuizStatusInReview, req.Comment, reviewer)
+	})
+}
+
+// ChangeQuizStatus implements ReviewService.
+func (s *reviewServiceImpl) ChangeQuizStatus(ctx context.Context, quizID string, req *dto.QuizStatusRequest, actor string) (*dto.QuizDetailResponse, error) {
+	if !domain.IsValidQuizStatus(req.Status) {
+		return nil, domain.NewValidationError(fmt.Sprintf("unknown quiz status %q", req.Status))
+	}
+	quiz, err := s.activeQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.CanTransitionQuizStatus(quiz.Status, req.Status) {
+		return nil, domain.NewConflictError(fmt.Sprintf("quiz %s cannot move from %s to %s", quizID, quiz.Status, req.Status))
+	}
+	if err := s.changeStatus(ctx, quiz, req.Status, req.Comment, actor); err != nil {
+		return nil, err
+	}
+	resp := toQuizDetailResponse(quiz)
+	return &resp, nil
+}
+
+// GetQuizStatusHistory implements ReviewService.
+func (s *reviewServiceImpl) GetQuizStatusHistory(ctx context.Context, quizID string) (*dto.QuizStatusHistoryResponse, error) {
+	quiz, err := s.activeQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.statusRepo.ListQuizStatusChanges(ctx, quizID)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to list the status changes of quiz %s", quizID), err)
+	}
+	resp := &dto.QuizStatusHistoryResponse{QuizID: quizID, Status: quiz.Status, Changes: make([]dto.QuizStatusChangeResponse, 0, len(changes))}
+	for _, change := range changes {
+		resp.Changes = append(resp.Changes, dto.QuizStatusChangeResponse{
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			Actor:      change.Actor,
+			Comment:    change.Comment,
+			CreatedAt:  change.CreatedAt,
+		})
+	}
+	return resp, nil
+}
+
+// decide moves a quiz waiting for review to the reviewer's decision.
+func (s *reviewServiceImpl) decide(ctx context.Context, quizID string, status string, comment string, reviewer string) (*dto.QuizDetailResponse, error) {
+	quiz, err := s.reviewableQuiz(ctx, quizID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.changeStatus(ctx, quiz, status, comment, reviewer); err != nil {
+		return nil, err
+	}
+	resp := toQuizDetailResponse(quiz)
+	return &resp, nil
+}
+
+// changeStatus saves and audits the status change, then invalidates the quiz lists when the quiz
+// starts or stops being served.
+func (s *reviewServiceImpl) changeStatus(ctx context.Context, quiz *domain.Quiz, status string, comment string, actor string) error {
+	wasServed := quiz.Status == domain.QuizStatusPublished
+	err := s.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		return s.saveStatusChange(ctx, quiz, status, comment, actor)
+	})
+	if err != nil {
+		return err
+	}
+
+	if wasServed != (status == domain.QuizStatusPublished) {
+		if err := s.quizService.InvalidateQuizListCache(ctx, quiz.SubCategoryID); err != nil {
+			// The change is saved; stale lists expire with their TTL.
+			logger.Get().Error("Failed to invalidate the cached quiz lists", zap.String("subCategoryID", quiz.SubCategoryID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// saveStatusChange saves and audits the status change within the caller's transaction. It fails with
+// a conflict when the quiz is no longer in the status it was read with.
+func (s *reviewServiceImpl) saveStatusChange(ctx context.Context, quiz *domain.Quiz, status string, comment string, actor string) error {
+	change := &domain.QuizStatusChange{QuizID: quiz.ID, FromStatus: quiz.Status, ToStatus: status, Actor: actor, Comment: comment}
+	err := s.statusRepo.ChangeQuizStatus(ctx, change)
+	if errors.Is(err, domain.ErrConflict) {
+		return domain.NewConflictError(fmt.Sprintf("the status of quiz %s was changed by someone else; reload it and try again", quiz.ID))
+	}
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to change the status of quiz %s", quiz.ID), err)
+	}
+	quiz.Status, quiz.UpdatedAt = status, change.CreatedAt
+	return nil
+}
+
+// reviewableQuiz returns the quiz if it is waiting for review.
+func (s *reviewServiceImpl) reviewableQuiz(ctx context.Context, id string) (*domain.Quiz, error) {
+	quiz, err := s.activeQuiz(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReviewable(quiz); err != nil {
+		return nil, err
+	}
+	return quiz, nil
+}
+
+func checkReviewable(quiz *domain.Quiz) error {
+	if quiz.Status != domain.QuizStatusDraft && quiz.Status != domain.QuizStatusInReview {
+		return domain.NewConflictError(fmt.Sprintf("quiz %s is %s, not waiting for review", quiz.ID, quiz.Status))
+	}
+	return nil
+}
+
+func (s *reviewServiceImpl) activeQuiz(ctx context.Context, id string) (*domain.Quiz, error) {
+	quiz, err := s.quizRepo.GetQuizByID(ctx, id)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to get quiz %s", id), err)
+	}
+	if quiz == nil {
+		return nil, domain.NewQuizNotFoundError(id)
+	}
+	return quiz, nil
+}
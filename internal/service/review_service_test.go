@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestReviewService wires a ReviewService whose edits and cache invalidations go through the real services.
+func newTestReviewService() (ReviewService, *MockQuizRepository, *MockQuizStatusRepository, *MockCategoryRepository, *MockCache) {
+	quizRepo, statusRepo, categoryRepo, cache := new(MockQuizRepository), new(MockQuizStatusRepository), new(MockCategoryRepository), new(MockCache)
+	quizService := NewQuizService(quizRepo, nil, cache, nil, nil, nil, 0, 0, config.CheckAnswerConfig{})
+	contentService := NewContentService(quizRepo, categoryRepo, statusRepo, quizService, directTxManager{})
+	return NewReviewService(quizRepo, statusRepo, contentService, quizService, directTxManager{}), quizRepo, statusRepo, categoryRepo, cache
+}
+
+func statusChange(from, to string) interface{} {
+	return mock.MatchedBy(func(change *domain.QuizStatusChange) bool {
+		return change.FromStatus == from && change.ToStatus == to
+	})
+}
+
+func TestReviewService_ListReviewQueue(t *testing.T) {
+	ctx := context.Background()
+	svc, _, statusRepo, _, _ := newTestReviewService()
+	statusRepo.On("ListQuizzesByStatus", ctx, []string{domain.QuizStatusDraft, domain.QuizStatusInReview}, defaultReviewQueueLimit).
+		Return([]*domain.Quiz{{ID: "quiz-1", Status: domain.QuizStatusDraft, Difficulty: domain.DifficultyEasy}}, nil)
+	statusRepo.On("ListQuizzesByStatus", ctx, []string{domain.QuizStatusRetired}, maxReviewQueueLimit).Return([]*domain.Quiz{}, nil)
+
+	resp, err := svc.ListReviewQueue(ctx, "", 0)
+	require.NoError(t, err)
+	require.Len(t, resp.Quizzes, 1)
+	assert.Equal(t, domain.QuizStatusDraft, resp.Quizzes[0].Status)
+
+	resp, err = svc.ListReviewQueue(ctx, domain.QuizStatusRetired, 1000)
+	require.NoError(t, err)
+	assert.Empty(t, resp.Quizzes)
+
+	_, err = svc.ListReviewQueue(ctx, "archived", 10)
+	assertDomainErrorCode(t, err, domain.CodeValidation)
+}
+
+func TestReviewService_ApproveQuiz(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Publishes And Invalidates The Quiz Lists", func(t *testing.T) {
+		svc, quizRepo, statusRepo, _, cache := newTestReviewService()
+		quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1", SubCategoryID: "sub-1", Status: domain.QuizStatusDraft}, nil)
+		statusRepo.On("ChangeQuizStatus", ctx, mock.MatchedBy(func(change *domain.QuizStatusChange) bool {
+			return change.FromStatus == domain.QuizStatusDraft && change.ToStatus == domain.QuizStatusPublished &&
+				change.Actor == "admin-1" && change.Comment == "Looks good"
+		})).Return(nil)
+		var deleted []string
+		cache.On("Delete", ctx, mock.AnythingOfType("string")).Run(func(args mock.Arguments) { deleted = append(deleted, args.String(1)) }).Return(nil)
+
+		resp, err := svc.ApproveQuiz(ctx, "quiz-1", "Looks good", "admin-1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.QuizStatusPublished, resp.Status)
+		assert.Contains(t, deleted, "quizbyte:quiz_service:quiz_list:sub-1:10")
+	})
+
+	t.Run("Rejects A Published Quiz", func(t *testing.T) {
+		svc, quizRepo, statusRepo, _, _ := newTestReviewService()
+		quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1", Status: domain.QuizStatusPublished}, nil)
+
+		_, err := svc.ApproveQuiz(ctx, "quiz-1", "", "admin-1")
+		assertDomainErrorCode(t, err, domain.CodeConflict)
+		statusRepo.AssertNotCalled(t, "ChangeQuizStatus", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Concurrent Decision", func(t *testing.T) {
+		svc, quizRepo, statusRepo, _, _ := newTestReviewService()
+		quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1", Status: domain.QuizStatusInReview}, nil)
+		statusRepo.On("ChangeQuizStatus", ctx, mock.Anything).Return(fmt.Errorf("quiz with ID quiz-1 not found or no longer in_review: %w", domain.ErrConflict))
+
+		_, err := svc.ApproveQuiz(ctx, "quiz-1", "", "admin-1")
+		assertDomainErrorCode(t, err, domain.CodeConflict)
+	})
+
+	t.Run("Missing Quiz", func(t *testing.T) {
+		svc, quizRepo, _, _, _ := newTestReviewService()
+		quizRepo.On("GetQuizByID", ctx, "missing").Return(nil, nil)
+
+		_, err := svc.ApproveQuiz(ctx, "missing", "", "admin-1")
+		assertDomainErrorCode(t, err, domain.CodeQuizNotFound)
+	})
+}
+
+func TestReviewService_RejectQuiz_RequiresComment(t *testing.T) {
+	ctx := context.Background()
+	svc, quizRepo, statusRepo, _, cache := newTestReviewService()
+
+	_, err := svc.RejectQuiz(ctx, "quiz-1", "  ", "admin-1")
+	assertDomainErrorCode(t, err, domain.CodeValidation)
+	quizRepo.AssertNotCalled(t, "GetQuizByID", mock.Anything, mock.Anything)
+
+	quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1", Status: domain.QuizStatusDraft}, nil)
+	statusRepo.On("ChangeQuizStatus", ctx, statusChange(domain.QuizStatusDraft, domain.QuizStatusRetired)).Return(nil)
+
+	resp, err := svc.RejectQuiz(ctx, "quiz-1", "Duplicate of quiz-2", "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuizStatusRetired, resp.Status)
+	// A draft was never served, so the cached lists are still valid.
+	cache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestReviewService_EditQuiz(t *testing.T) {
+	ctx := context.Background()
+	svc, quizRepo, statusRepo, categoryRepo, cache := newTestReviewService()
+	quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{
+		ID: "quiz-1", Question: "Old?", ModelAnswers: []string{"Old"}, Difficulty: domain.DifficultyEasy, SubCategoryID: "sub-1", Revision: 1, Status: domain.QuizStatusDraft,
+	}, nil)
+	categoryRepo.On("GetSubCategoryByID", ctx, "sub-1").Return(&domain.SubCategory{ID: "sub-1"}, nil)
+	quizRepo.On("UpdateQuiz", ctx, mock.AnythingOfType("*domain.Quiz"), "admin-1").Return(nil)
+	cache.On("Delete", ctx, mock.AnythingOfType("string")).Return(nil)
+	statusRepo.On("ChangeQuizStatus", ctx, statusChange(domain.QuizStatusDraft, domain.QuizStatusInReview)).Return(nil)
+
+	resp, err := svc.EditQuiz(ctx, "quiz-1", &dto.ReviewEditRequest{
+		QuizRequest: dto.QuizRequest{Question: "New?", ModelAnswers: []string{"New"}, Difficulty: "easy", SubCategoryID: "sub-1", Revision: 1},
+		Comment:     "Clarified the question",
+	}, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, "New?", resp.Question)
+	assert.Equal(t, domain.QuizStatusInReview, resp.Status)
+	statusRepo.AssertExpectations(t)
+}
+
+// countingTxManager runs fn without a transaction and counts the transactions.
+type countingTxManager struct{ transactions int }
+
+func (m *countingTxManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.transactions++
+	return fn(ctx)
+}
+
+func TestReviewService_EditQuiz_OneTransaction(t *testing.T) {
+	ctx := context.Background()
+	edit := &dto.ReviewEditRequest{QuizRequest: dto.QuizRequest{Question: "New?", ModelAnswers: []string{"New"}, Difficulty: "easy", SubCategoryID: "sub-1"}}
+	newService := func(status string) (ReviewService, *MockQuizRepository, *MockQuizStatusRepository, *countingTxManager) {
+		quizRepo, statusRepo, categoryRepo, cache := new(MockQuizRepository), new(MockQuizStatusRepository), new(MockCategoryRepository), new(MockCache)
+		quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{
+			ID: "quiz-1", Question: "Old?", ModelAnswers: []string{"Old"}, Difficulty: domain.DifficultyEasy, SubCategoryID: "sub-1", Revision: 1, Status: status,
+		}, nil)
+		categoryRepo.On("GetSubCategoryByID", ctx, "sub-1").Return(&domain.SubCategory{ID: "sub-1"}, nil)
+		cache.On("Delete", ctx, mock.AnythingOfType("string")).Return(nil)
+		txManager := &countingTxManager{}
+		quizService := NewQuizService(quizRepo, nil, cache, nil, nil, nil, 0, 0, config.CheckAnswerConfig{})
+		contentService := NewContentService(quizRepo, categoryRepo, statusRepo, quizService, txManager)
+		return NewReviewService(quizRepo, statusRepo, contentService, quizService, txManager), quizRepo, statusRepo, txManager
+	}
+
+	t.Run("Status Change And Edit Are Saved Together", func(t *testing.T) {
+		svc, quizRepo, statusRepo, txManager := newService(domain.QuizStatusInReview)
+		var order []string
+		statusRepo.On("ChangeQuizStatus", ctx, statusChange(domain.QuizStatusInReview, domain.QuizStatusInReview)).
+			Run(func(mock.Arguments) { order = append(order, "status") }).Return(nil)
+		quizRepo.On("UpdateQuiz", ctx, mock.AnythingOfType("*domain.Quiz"), "admin-1").
+			Run(func(mock.Arguments) { order = append(order, "edit") }).Return(nil)
+
+		_, err := svc.EditQuiz(ctx, "quiz-1", edit, "admin-1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, txManager.transactions)
+		assert.Equal(t, []string{"status", "edit"}, order, "the status guards the edit")
+	})
+
+	t.Run("Quiz Decided In The Meantime Is Not Edited", func(t *testing.T) {
+		svc, quizRepo, statusRepo, _ := newService(domain.QuizStatusDraft)
+		statusRepo.On("ChangeQuizStatus", ctx, statusChange(domain.QuizStatusDraft, domain.QuizStatusInReview)).
+			Return(fmt.Errorf("quiz with ID quiz-1 not found or no longer draft: %w", domain.ErrConflict))
+
+		_, err := svc.EditQuiz(ctx, "quiz-1", edit, "admin-1")
+		assertDomainErrorCode(t, err, domain.CodeConflict)
+		quizRepo.AssertNotCalled(t, "UpdateQuiz", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Published Quiz Is Not Edited", func(t *testing.T) {
+		svc, quizRepo, statusRepo, _ := newService(domain.QuizStatusPublished)
+
+		_, err := svc.EditQuiz(ctx, "quiz-1", edit, "admin-1")
+		assertDomainErrorCode(t, err, domain.CodeConflict)
+		statusRepo.AssertNotCalled(t, "ChangeQuizStatus", mock.Anything, mock.Anything)
+		quizRepo.AssertNotCalled(t, "UpdateQuiz", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestReviewService_ChangeQuizStatus(t *testing.T) {
+	ctx := context.Background()
+	svc, quizRepo, statusRepo, _, cache := newTestReviewService()
+	quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1", SubCategoryID: "sub-1", Status: domain.QuizStatusPublished}, nil)
+
+	_, err := svc.ChangeQuizStatus(ctx, "quiz-1", &dto.QuizStatusRequest{Status: domain.QuizStatusDraft}, "admin-1")
+	assertDomainErrorCode(t, err, domain.CodeConflict)
+
+	_, err = svc.ChangeQuizStatus(ctx, "quiz-1", &dto.QuizStatusRequest{Status: "archived"}, "admin-1")
+	assertDomainErrorCode(t, err, domain.CodeValidation)
+	statusRepo.AssertNotCalled(t, "ChangeQuizStatus", mock.Anything, mock.Anything)
+
+	statusRepo.On("ChangeQuizStatus", ctx, statusChange(domain.QuizStatusPublished, domain.QuizStatusRetired)).Return(nil)
+	cache.On("Delete", ctx, mock.AnythingOfType("string")).Return(nil)
+	resp, err := svc.ChangeQuizStatus(ctx, "quiz-1", &dto.QuizStatusRequest{Status: domain.QuizStatusRetired, Comment: "Outdated"}, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuizStatusRetired, resp.Status)
+	cache.AssertCalled(t, "Delete", ctx, "quizbyte:quiz_service:quiz_list:sub-1:10")
+}
+
+func TestReviewService_GetQuizStatusHistory(t *testing.T) {
+	ctx := context.Background()
+	svc, quizRepo, statusRepo, _, _ := newTestReviewService()
+	quizRepo.On("GetQuizByID", ctx, "quiz-1").Return(&domain.Quiz{ID: "quiz-1", Status: domain.QuizStatusRetired}, nil)
+	statusRepo.On("ListQuizStatusChanges", ctx, "quiz-1").Return([]*domain.QuizStatusChange{
+		{QuizID: "quiz-1", FromStatus: domain.QuizStatusDraft, ToStatus: domain.QuizStatusRetired, Actor: "admin-1", Comment: "Duplicate"},
+	}, nil)
+
+	resp, err := svc.GetQuizStatusHistory(ctx, "quiz-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.QuizStatusRetired, resp.Status)
+	require.Len(t, resp.Changes, 1)
+	assert.Equal(t, "Duplicate", resp.Changes[0].Comment)
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// RoleService manages the roles granted to users.
+type RoleService interface {
+	// GrantRole grants the role to the active user the member request names by email or user ID. grantedBy records
+	// who granted it. It returns false when the user already had the role, and a not found error when the user or
+	// the role does not exist.
+	GrantRole(ctx context.Context, member dto.RoleMemberRequest, role string, grantedBy string) (bool, error)
+	// RevokeRole takes the role from the active user the member request names. It returns false when the user did not have it.
+	RevokeRole(ctx context.Context, member dto.RoleMemberRequest, role string) (bool, error)
+	// ListRoleMembers lists the active users with the role, in the order it was granted.
+	ListRoleMembers(ctx context.Context, role string) (*dto.RoleMemberListResponse, error)
+}
+
+type roleServiceImpl struct {
+	userRepo domain.UserRepository
+	roleRepo domain.RoleRepository
+}
+
+// NewRoleService creates a new instance of RoleService.
+// Roles are put into access tokens when they are issued, so a change takes effect with the user's next token refresh.
+func NewRoleService(userRepo domain.UserRepository, roleRepo domain.RoleRepository) RoleService {
+	return &roleServiceImpl{userRepo: userRepo, roleRepo: roleRepo}
+}
+
+// GrantRole implements RoleService.
+func (s *roleServiceImpl) GrantRole(ctx context.Context, member dto.RoleMemberRequest, role string, grantedBy string) (bool, error) {
+	if err := s.requireRole(ctx, role); err != nil {
+		return false, err
+	}
+	user, err := s.activeUser(ctx, member)
+	if err != nil {
+		return false, err
+	}
+
+	granted, err := s.roleRepo.GrantRole(ctx, &domain.UserRole{UserID: user.ID, Role: role, GrantedBy: grantedBy, GrantedAt: time.Now()})
+	if err != nil {
+		return false, domain.NewInternalError(fmt.Sprintf("failed to grant role %s to user %s", role, user.ID), err)
+	}
+	if granted {
+		logger.Get().Info("Role granted", zap.String("userID", user.ID), zap.String("role", role), zap.String("grantedBy", grantedBy))
+	}
+	return granted, nil
+}
+
+// RevokeRole implements RoleService.
+func (s *roleServiceImpl) RevokeRole(ctx context.Context, member dto.RoleMemberRequest, role string) (bool, error) {
+	user, err := s.activeUser(ctx, member)
+	if err != nil {
+		return false, err
+	}
+
+	revoked, err := s.roleRepo.RevokeRole(ctx, user.ID, role)
+	if err != nil {
+		return false, domain.NewInternalError(fmt.Sprintf("failed to revoke role %s of user %s", role, user.ID), err)
+	}
+	if revoked {
+		logger.Get().Info("Role revoked", zap.String("userID", user.ID), zap.String("role", role))
+	}
+	return revoked, nil
+}
+
+// ListRoleMembers implements RoleService.
+func (s *roleServiceImpl) ListRoleMembers(ctx context.Context, role string) (*dto.RoleMemberListResponse, error) {
+	if err := s.requireRole(ctx, role); err != nil {
+		return nil, err
+	}
+	members, err := s.roleRepo.ListRoleMembers(ctx, role)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to list members of role %s", role), err)
+	}
+
+	items := make([]dto.RoleMemberItem, 0, len(members))
+	for _, member := range members {
+		items = append(items, dto.RoleMemberItem{
+			UserID:    member.UserID,
+			Email:     member.Email,
+			GrantedBy: member.GrantedBy,
+			GrantedAt: member.GrantedAt,
+		})
+	}
+	return &dto.RoleMemberListResponse{Role: role, Members: items}, nil
+}
+
+// requireRole returns a not found error when the role does not exist.
+func (s *roleServiceImpl) requireRole(ctx context.Context, role string) error {
+	found, err := s.roleRepo.GetRole(ctx, role)
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to get role %s", role), err)
+	}
+	if found == nil {
+		return domain.NewNotFoundError(fmt.Sprintf("role %q does not exist", role))
+	}
+	return nil
+}
+
+// activeUser returns the active user the member request names, or a not found error. Users whose email
+// was not verified are stored without one, so they can only be named by ID.
+func (s *roleServiceImpl) activeUser(ctx context.Context, member dto.RoleMemberRequest) (*domain.User, error) {
+	email, userID := strings.TrimSpace(member.Email), strings.TrimSpace(member.UserID)
+	switch {
+	case email == "" && userID == "":
+		return nil, domain.NewValidationError("email or user_id is required")
+	case email != "" && userID != "":
+		return nil, domain.NewValidationError("give either email or user_id, not both")
+	}
+
+	if userID != "" {
+		user, err := s.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, domain.NewInternalError(fmt.Sprintf("failed to get user %s", userID), err)
+		}
+		if user == nil {
+			return nil, domain.NewNotFoundError(fmt.Sprintf("no user with ID %s", userID))
+		}
+		return user, nil
+	}
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to get user %s", email), err)
+	}
+	if user == nil {
+		return nil, domain.NewNotFoundError(fmt.Sprintf("no user with email %s; the user has to sign in once first, "+
+			"and a user without a verified email has to be named by user ID", email))
+	}
+	return user, nil
+}
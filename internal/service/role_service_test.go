@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"quiz-byte/internal/config"
+	"quiz-byte/internal/domain"
+	"quiz-byte/internal/dto"
+	"quiz-byte/internal/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRoleRepository is an in-memory domain.RoleRepository knowing only the admin role.
+type memoryRoleRepository struct {
+	mu     sync.Mutex
+	grants map[string]map[string]domain.UserRole // user ID -> role -> grant
+}
+
+func newMemoryRoleRepository() *memoryRoleRepository {
+	return &memoryRoleRepository{grants: make(map[string]map[string]domain.UserRole)}
+}
+
+func (r *memoryRoleRepository) GetRole(_ context.Context, name string) (*domain.Role, error) {
+	if name != domain.RoleAdmin {
+		return nil, nil
+	}
+	return &domain.Role{Name: name}, nil
+}
+
+func (r *memoryRoleRepository) ListUserRoles(_ context.Context, userID string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	roles := []string{}
+	for role := range r.grants[userID] {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles, nil
+}
+
+func (r *memoryRoleRepository) ListRoleMembers(_ context.Context, role string) ([]domain.UserRole, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var members []domain.UserRole
+	for _, roles := range r.grants {
+		if grant, ok := roles[role]; ok {
+			members = append(members, grant)
+		}
+	}
+	return members, nil
+}
+
+func (r *memoryRoleRepository) GrantRole(_ context.Context, grant *domain.UserRole) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.grants[grant.UserID][grant.Role]; ok {
+		return false, nil
+	}
+	if r.grants[grant.UserID] == nil {
+		r.grants[grant.UserID] = make(map[string]domain.UserRole)
+	}
+	r.grants[grant.UserID][grant.Role] = *grant
+	return true, nil
+}
+
+func (r *memoryRoleRepository) RevokeRole(_ context.Context, userID string, role string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.grants[userID][role]; !ok {
+		return false, nil
+	}
+	delete(r.grants[userID], role)
+	return true, nil
+}
+
+func TestRoleService_GrantAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	user := &domain.User{ID: "user1", Email: "admin@example.com"}
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetUserByEmail", mock.Anything, "admin@example.com").Return(user, nil)
+	mockUserRepo.On("GetUserByEmail", mock.Anything, "nobody@example.com").Return(nil, nil)
+	roles := newMemoryRoleRepository()
+	roleService := NewRoleService(mockUserRepo, roles)
+
+	granted, err := roleService.GrantRole(ctx, dto.RoleMemberRequest{Email: " admin@example.com "}, domain.RoleAdmin, "cli:root")
+	require.NoError(t, err)
+	assert.True(t, granted)
+	granted, err = roleService.GrantRole(ctx, dto.RoleMemberRequest{Email: "admin@example.com"}, domain.RoleAdmin, "cli:root")
+	require.NoError(t, err)
+	assert.False(t, granted, "granting a role twice is a no-op")
+
+	members, err := roleService.ListRoleMembers(ctx, domain.RoleAdmin)
+	require.NoError(t, err)
+	if assert.Len(t, members.Members, 1) {
+		assert.Equal(t, "user1", members.Members[0].UserID)
+		assert.Equal(t, "cli:root", members.Members[0].GrantedBy)
+	}
+
+	revoked, err := roleService.RevokeRole(ctx, dto.RoleMemberRequest{Email: "admin@example.com"}, domain.RoleAdmin)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = roleService.RevokeRole(ctx, dto.RoleMemberRequest{Email: "admin@example.com"}, domain.RoleAdmin)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	var domainErr *domain.DomainError
+	_, err = roleService.GrantRole(ctx, dto.RoleMemberRequest{Email: "nobody@example.com"}, domain.RoleAdmin, "cli:root")
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, domain.CodeNotFound, domainErr.Code)
+	_, err = roleService.GrantRole(ctx, dto.RoleMemberRequest{Email: "admin@example.com"}, "superuser", "cli:root")
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, domain.CodeNotFound, domainErr.Code)
+	_, err = roleService.GrantRole(ctx, dto.RoleMemberRequest{}, domain.RoleAdmin, "cli:root")
+	assertDomainErrorCode(t, err, domain.CodeValidation)
+	_, err = roleService.GrantRole(ctx, dto.RoleMemberRequest{Email: "admin@example.com", UserID: "user1"}, domain.RoleAdmin, "cli:root")
+	assertDomainErrorCode(t, err, domain.CodeValidation)
+}
+
+func TestRoleService_GrantAndRevokeByUserID(t *testing.T) {
+	ctx := context.Background()
+	// Users who signed in without a verified email are stored without one
+	user := &domain.User{ID: "user2"}
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetUserByID", mock.Anything, "user2").Return(user, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, "missing").Return(nil, nil)
+	roles := newMemoryRoleRepository()
+	roleService := NewRoleService(mockUserRepo, roles)
+
+	granted, err := roleService.GrantRole(ctx, dto.RoleMemberRequest{UserID: " user2 "}, domain.RoleAdmin, "cli:root")
+	require.NoError(t, err)
+	assert.True(t, granted)
+	userRoles, err := roles.ListUserRoles(ctx, "user2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.RoleAdmin}, userRoles)
+
+	revoked, err := roleService.RevokeRole(ctx, dto.RoleMemberRequest{UserID: "user2"}, domain.RoleAdmin)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = roleService.GrantRole(ctx, dto.RoleMemberRequest{UserID: "missing"}, domain.RoleAdmin, "cli:root")
+	assertDomainErrorCode(t, err, domain.CodeNotFound)
+	mockUserRepo.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_AccessTokensCarryRoles(t *testing.T) {
+	ctx := context.Background()
+	user := &domain.User{ID: "user1", Email: "octocat@example.com"}
+	github := &stubIdentityProvider{name: domain.IdentityProviderGitHub, account: port.ExternalIdentity{
+		Provider: domain.IdentityProviderGitHub, Subject: "583231", Email: user.Email, EmailVerified: true,
+	}}
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, user.ID).Return(user, nil)
+	mockUserRepo.On("UpdateUser", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+	roles := newMemoryRoleRepository()
+	authCfg := config.AuthConfig{
+		TokenEncryptionKey: testTokenEncryptionKey,
+		JWT:                config.JWTConfig{AccessTokenTTL: 15 * time.Minute, RefreshTokenTTL: 7 * 24 * time.Hour},
+	}
+	authService, err := NewAuthService(mockUserRepo, newMemoryIdentityRepository(), newMemorySessionRepository(), roles, nil, []port.IdentityProvider{github}, authCfg, directTxManager{})
+	require.NoError(t, err)
+
+	accessToken, refreshToken, _, err := authService.HandleOAuthCallback(ctx, "github", "good-code", "state", testLogin, dto.SessionClientInfo{})
+	require.NoError(t, err)
+	claims, err := authService.ValidateJWT(ctx, accessToken)
+	require.NoError(t, err)
+	assert.False(t, claims.HasRole(domain.RoleAdmin))
+
+	// A granted role shows up in the tokens of the next refresh.
+	_, err = NewRoleService(mockUserRepo, roles).GrantRole(ctx, dto.RoleMemberRequest{Email: user.Email}, domain.RoleAdmin, "cli:root")
+	require.NoError(t, err)
+	accessToken, refreshToken, err = authService.RefreshToken(ctx, refreshToken)
+	require.NoError(t, err)
+	claims, err = authService.ValidateJWT(ctx, accessToken)
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.RoleAdmin}, claims.Roles)
+	assert.True(t, claims.HasRole(domain.RoleAdmin))
+
+	refreshClaims, err := authService.ValidateJWT(ctx, refreshToken)
+	require.NoError(t, err)
+	assert.Empty(t, refreshClaims.Roles, "refresh tokens look the roles up again")
+}
@@ -0,0 +1,68 @@
+package service
+
+import "strings"
+
+const (
+	maxSessionUserAgentLength = 512 // Matches user_sessions.user_agent
+	maxSessionIPAddressLength = 64  // Matches user_sessions.ip_address
+	unknownDevice             = "Unknown device"
+)
+
+// userAgentBrowsers lists browser markers in match order: Edge and Opera also send "Chrome",
+// and Chrome also sends "Safari".
+var userAgentBrowsers = []struct{ marker, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"CriOS/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+}
+
+// userAgentSystems lists OS markers in match order: Android also sends "Linux", iOS also sends "Mac OS X".
+var userAgentSystems = []struct{ marker, name string }{
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// describeDevice returns a short, human readable device name such as "Chrome on macOS".
+func describeDevice(userAgent string) string {
+	var browser, system string
+	for _, b := range userAgentBrowsers {
+		if strings.Contains(userAgent, b.marker) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range userAgentSystems {
+		if strings.Contains(userAgent, s.marker) {
+			system = s.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return unknownDevice
+	}
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
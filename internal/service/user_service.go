@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"quiz-byte/internal/domain"
 	"quiz-byte/internal/dto"
-	"quiz-byte/internal/util" // For ULID or other utils if needed
 	"time"
 	// No longer directly using repository or models, only domain interfaces
 )
@@ -21,7 +20,6 @@ const DefaultCorrectnessThreshold = 0.7 // Example threshold
 // UserService defines the interface for user-related operations.
 type UserService interface {
 	GetUserProfile(ctx context.Context, userID string) (*dto.UserProfileResponse, error)
-	RecordQuizAttempt(ctx context.Context, userID string, quizID string, userAnswer string, evalResult *domain.Answer) error
 	GetUserQuizAttempts(ctx context.Context, userID string, filters dto.AttemptFilters, pagination dto.Pagination) (*dto.UserQuizAttemptsResponse, error)
 	GetUserIncorrectAnswers(ctx context.Context, userID string, filters dto.AttemptFilters, pagination dto.Pagination) (*dto.UserIncorrectAnswersResponse, error)
 	GetUserRecommendations(ctx context.Context, userID string, limit int, optionalSubCategoryID string) (*dto.QuizRecommendationsResponse, error)
@@ -71,12 +69,8 @@ func (s *userServiceImpl) GetUserProfile(ctx context.Context, userID string) (*d
 	}, nil
 }
 
-// RecordQuizAttempt records a user's quiz attempt.
-func (s *userServiceImpl) RecordQuizAttempt(ctx context.Context, userID string, quizID string, userAnswer string, evalResult *domain.Answer) error {
-	if evalResult == nil {
-		return errors.New("evaluation result cannot be nil")
-	}
-
+// newUserQuizAttempt builds the attempt stored for a graded answer.
+func newUserQuizAttempt(id string, userID string, quizID string, userAnswer string, evalResult *domain.Answer) *domain.UserQuizAttempt {
 	isCorrect := evalResult.Score >= DefaultCorrectnessThreshold
 
 	// domain.UserQuizAttempt uses []string for LLMKeywordMatches
@@ -89,7 +83,7 @@ func (s *userServiceImpl) RecordQuizAttempt(ctx context.Context, userID string,
 	}
 
 	domainAttempt := &domain.UserQuizAttempt{ // Changed to domain.UserQuizAttempt
-		ID:                util.NewULID(),
+		ID:                id,
 		UserID:            userID,
 		QuizID:            quizID,
 		UserAnswer:        userAnswer, // domain.UserQuizAttempt.UserAnswer is string
@@ -100,18 +94,14 @@ func (s *userServiceImpl) RecordQuizAttempt(ctx context.Context, userID string,
 		LLMRelevance:      evalResult.Relevance,
 		LLMAccuracy:       evalResult.Accuracy,
 		IsCorrect:         isCorrect,
+		QuizRevision:      evalResult.QuizRevision,
 		AttemptedAt:       evalResult.AnsweredAt,
 		// CreatedAt and UpdatedAt will be set by repository or domain constructor if applicable
 	}
 	if domainAttempt.AttemptedAt.IsZero() {
 		domainAttempt.AttemptedAt = time.Now()
 	}
-
-	// The error from CreateAttempt is already wrapped by the repository
-	if err := s.attemptRepo.CreateAttempt(ctx, domainAttempt); err != nil {
-		return domain.NewInternalError("failed to create user quiz attempt in repository", err)
-	}
-	return nil
+	return domainAttempt
 }
 
 // GetUserQuizAttempts retrieves a user's quiz attempt history.
@@ -200,4 +200,4 @@ func TestUserService_GetUserQuizAttempts_QuizDetailRepoError(t *testing.T) {
 	mockQuizRepo.AssertExpectations(t)
 }
 
-// TODO: Add tests for RecordQuizAttempt, GetUserIncorrectAnswers, GetUserRecommendations focusing on error paths.
+// TODO: Add tests for GetUserIncorrectAnswers, GetUserRecommendations focusing on error paths.
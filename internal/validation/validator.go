@@ -1,11 +1,20 @@
 package validation
 
 import (
+	"fmt"
+	"math"
 	"quiz-byte/internal/domain"
 	"regexp"
 	"strings"
 )
 
+const (
+	// MaxFilterKeywords is the maximum number of keywords accepted in a quiz filter
+	MaxFilterKeywords = 10
+	// MaxExcludeIDs is the maximum number of quiz IDs that can be excluded in a single request
+	MaxExcludeIDs = 100
+)
+
 // Validator provides request validation functionality
 type Validator struct{}
 
@@ -67,8 +76,84 @@ func (v *Validator) ValidateBulkQuizzesRequest(subCategory string, count int) do
 	return errors
 }
 
+// ValidateRandomQuizFilters validates the optional filters of the random quiz request
+func (v *Validator) ValidateRandomQuizFilters(difficulty string, keywords []string, excludeIDs []string) domain.ValidationErrors {
+	var errors domain.ValidationErrors
+
+	if difficulty != "" && !isValidDifficulty(difficulty) {
+		errors = append(errors, domain.NewInvalidFormatError("difficulty", difficulty))
+	}
+
+	if len(keywords) > MaxFilterKeywords {
+		errors = append(errors, domain.NewOutOfRangeError("keywords", len(keywords), 1, MaxFilterKeywords))
+	}
+	for _, keyword := range keywords {
+		if !isValidSubCategory(keyword) { // Keywords share the sub-category character rules
+			errors = append(errors, domain.NewInvalidFormatError("keywords", keyword))
+		}
+	}
+
+	if len(excludeIDs) > MaxExcludeIDs {
+		errors = append(errors, domain.NewOutOfRangeError("exclude_ids", len(excludeIDs), 1, MaxExcludeIDs))
+	}
+	for _, id := range excludeIDs {
+		if !isValidULID(id) {
+			errors = append(errors, domain.NewInvalidFormatError("exclude_ids", id))
+		}
+	}
+
+	return errors
+}
+
+// ValidateNextQuizRequest validates the next quiz request
+func (v *Validator) ValidateNextQuizRequest(quizID string, lastScore *float64) domain.ValidationErrors {
+	var errors domain.ValidationErrors
+
+	if strings.TrimSpace(quizID) == "" {
+		errors = append(errors, domain.NewMissingFieldError("id"))
+	} else if !isValidULID(quizID) {
+		errors = append(errors, domain.NewInvalidFormatError("id", quizID))
+	}
+
+	if lastScore != nil {
+		switch {
+		case math.IsNaN(*lastScore) || math.IsInf(*lastScore, 0):
+			// Reported as text, since NaN and infinities cannot be encoded as JSON numbers
+			errors = append(errors, domain.NewInvalidFormatError("score", fmt.Sprint(*lastScore)))
+		case *lastScore < 0 || *lastScore > 1:
+			errors = append(errors, domain.NewOutOfRangeError("score", *lastScore, 0, 1))
+		}
+	}
+
+	return errors
+}
+
+// SplitCommaList splits a comma-separated query value, dropping empty entries
+func SplitCommaList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
+
 // Helper functions for validation
 
+// isValidDifficulty checks if the difficulty is one of the supported levels
+func isValidDifficulty(s string) bool {
+	switch strings.ToLower(s) {
+	case "easy", "medium", "hard":
+		return true
+	default:
+		return false
+	}
+}
+
 // isValidULID checks if the string is a valid ULID format
 func isValidULID(s string) bool {
 	// ULID is 26 characters long, base32 encoded
@@ -6,11 +6,10 @@ import (
 	"fmt"
 	"time"
 
-	"quiz-byte/internal/dto"
+	"quiz-byte/internal/domain"
 	"quiz-byte/internal/repository/models"
 	"quiz-byte/internal/util"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -55,31 +54,16 @@ func createTestUserDB(db *sqlx.DB, user models.User) (*models.User, error) {
 }
 
 // generateTestJWTToken generates a JWT token for a given user.
-// It uses the global cfg variable (from main_test.go) for JWT secret and TTLs.
+// It signs with the global authSvc (from main_test.go), so the token carries the kid of the active key.
 func generateTestJWTToken(user *models.User, tokenType string, ttl time.Duration) (string, error) {
-	if cfg == nil || cfg.Auth.JWT.SecretKey == "" {
-		return "", fmt.Errorf("JWT secret key is not configured in global cfg")
+	if authSvc == nil {
+		return "", fmt.Errorf("auth service is not initialized")
 	}
 	if user == nil {
 		return "", fmt.Errorf("user cannot be nil")
 	}
 
-	issuedAt := time.Now()
-	expiresAt := issuedAt.Add(ttl)
-
-	claims := dto.AuthClaims{
-		UserID:    user.ID,
-		TokenType: tokenType,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			IssuedAt:  jwt.NewNumericDate(issuedAt),
-			NotBefore: jwt.NewNumericDate(issuedAt),
-			Subject:   user.ID,
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(cfg.Auth.JWT.SecretKey))
+	signedToken, err := authSvc.CreateJWT(context.Background(), &domain.User{ID: user.ID}, ttl, tokenType)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -92,7 +76,7 @@ func newTestUser() models.User {
 	userID := util.NewULID()
 	return models.User{
 		ID:                userID,
-		Email:             "testuser-" + userID + "@example.com",
+		Email:             sql.NullString{String: "testuser-" + userID + "@example.com", Valid: true},
 		GoogleID:          "googleid-" + userID,
 		Name:              sql.NullString{String: "Test User " + userID, Valid: true},
 		ProfilePictureURL: sql.NullString{String: "http://example.com/picture-" + userID + ".jpg", Valid: true},
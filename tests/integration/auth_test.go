@@ -151,7 +151,7 @@ func TestGetMyProfile_Success(t *testing.T) {
 	userID := util.NewULID()
 	testUser := models.User{
 		ID:                userID,
-		Email:             "testuser-" + userID + "@success.com",
+		Email:             sql.NullString{String: "testuser-" + userID + "@success.com", Valid: true},
 		GoogleID:          "googleid-" + userID,
 		Name:              sql.NullString{String: "Test User " + userID, Valid: true},
 		ProfilePictureURL: sql.NullString{String: "http://example.com/pic-" + userID + ".jpg", Valid: true},
@@ -182,7 +182,7 @@ func TestGetMyProfile_Success(t *testing.T) {
 	require.NoError(t, err, "Failed to decode successful /me response. Body: %s", bodyBytes.String())
 
 	assert.Equal(t, createdUser.ID, userProfileResponse.ID)
-	assert.Equal(t, createdUser.Email, userProfileResponse.Email)
+	assert.Equal(t, createdUser.Email.String, userProfileResponse.Email)
 	assert.Equal(t, createdUser.Name.String, userProfileResponse.Name)
 	assert.Equal(t, createdUser.ProfilePictureURL.String, userProfileResponse.ProfilePictureURL)
 }
@@ -192,7 +192,7 @@ func TestGetMyAttempts_NoAttempts(t *testing.T) {
 	userID := util.NewULID()
 	testUser, err := createTestUserDB(db, models.User{
 		ID:                userID,
-		Email:             "noattemptsuser-" + userID + "@example.com",
+		Email:             sql.NullString{String: "noattemptsuser-" + userID + "@example.com", Valid: true},
 		GoogleID:          "google-noattempts-" + userID,
 		Name:              sql.NullString{String: "No Attempts User " + userID, Valid: true},
 		ProfilePictureURL: sql.NullString{String: "http://example.com/noattempts-" + userID + ".jpg", Valid: true},
@@ -234,7 +234,7 @@ func TestGetMyAttempts_WithAttempts(t *testing.T) {
 	userID := util.NewULID()
 	userWithAttempts, err := createTestUserDB(db, models.User{
 		ID:                userID,
-		Email:             "attemptsuser-" + userID + "@example.com",
+		Email:             sql.NullString{String: "attemptsuser-" + userID + "@example.com", Valid: true},
 		GoogleID:          "google-attempts-" + userID, // Changed from sql.NullString
 		Name:              sql.NullString{String: "Attempts User " + userID, Valid: true},
 		ProfilePictureURL: sql.NullString{String: "http://example.com/attempts-" + userID + ".jpg", Valid: true},
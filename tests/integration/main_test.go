@@ -12,6 +12,7 @@ import (
 	"quiz-byte/internal/adapter"
 	"quiz-byte/internal/adapter/embedding"
 	"quiz-byte/internal/adapter/evaluator"
+	"quiz-byte/internal/adapter/identity"
 	"quiz-byte/internal/domain"
 	"quiz-byte/internal/repository"
 	"quiz-byte/internal/repository/models"
@@ -29,7 +30,6 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/jmoiron/sqlx"
-	"github.com/tmc/langchaingo/llms/ollama"
 	"go.uber.org/zap"
 
 	"quiz-byte/internal/cache"
@@ -42,7 +42,8 @@ var (
 	logInstance *zap.Logger
 	db          *sqlx.DB
 	redisClient *redis.Client
-	cfg         *config.Config // Will be initialized in TestMain
+	cfg         *config.Config      // Will be initialized in TestMain
+	authSvc     service.AuthService // Signs test tokens with the configured JWT keys
 
 	subCategoryNameToIDMap map[string]string
 	cacheKey               string // Used in TestCheckAnswer_Caching, might need review if it should be global
@@ -127,19 +128,11 @@ func TestMain(m *testing.M) {
 		logInstance.Fatal("Unsupported embedding source", zap.String("source", cfg.Embedding.Source))
 	}
 
-	// Initialize LLM client and Evaluator Service
-	ollamaHTTPClient := &http.Client{
-		Timeout: 20 * time.Second, // Consider making this configurable
-	}
-	llm, err := ollama.New(
-		ollama.WithServerURL(cfg.LLMProviders.OllamaServerURL),
-		ollama.WithModel("qwen3:0.6b"), // Using qwen3 0.6b for evaluation
-		ollama.WithHTTPClient(ollamaHTTPClient),
-	)
+	// Initialize Evaluator Service. Set APP_EVALUATOR_PROVIDER=fake to run without a model server.
+	evaluatorService, err := evaluator.NewFromConfig(cfg.Evaluator)
 	if err != nil {
-		logInstance.Fatal("Failed to create LLM client", zap.Error(err))
+		logInstance.Fatal("Failed to create answer evaluator", zap.Error(err))
 	}
-	evaluatorService := evaluator.NewLLMEvaluator(llm) // Using evaluator.NewLLMEvaluator from the correct package
 
 	// Initialize Transaction Manager
 	txManager := repository.NewTransactionManagerAdapter(db)
@@ -151,6 +144,12 @@ func TestMain(m *testing.M) {
 	quizRepository := repository.NewQuizDatabaseAdapter(db)
 	userRepository := repository.NewSQLXUserRepository(db)
 	userQuizAttemptRepository := repository.NewSQLXUserQuizAttemptRepository(db)
+	attemptOutboxRepository := repository.NewSQLXAttemptOutboxRepository(db)
+	userSessionRepository := repository.NewSQLXUserSessionRepository(db)
+	userIdentityRepository := repository.NewSQLXUserIdentityRepository(db)
+	roleRepository := repository.NewSQLXRoleRepository(db)
+	categoryRepository := repository.NewCategoryDatabaseAdapter(db)
+	quizStatusRepository := repository.NewSQLXQuizStatusRepository(db)
 
 	// Initialize AnswerCacheService
 	answerEvaluationTTL := cfg.ParseTTLStringOrDefault(cfg.CacheTTLs.AnswerEvaluation, 10*time.Minute) // Example TTL
@@ -159,25 +158,39 @@ func TestMain(m *testing.M) {
 	// Initialize QuizService
 	categoryListTTL := cfg.ParseTTLStringOrDefault(cfg.CacheTTLs.CategoryList, 5*time.Minute)
 	quizListTTL := cfg.ParseTTLStringOrDefault(cfg.CacheTTLs.QuizList, 5*time.Minute)
-	quizService := service.NewQuizService(quizRepository, evaluatorService, cacheAdapter, embeddingService, answerCacheSvc, txManager, categoryListTTL, quizListTTL)
+	quizService := service.NewQuizService(quizRepository, evaluatorService, cacheAdapter, embeddingService, answerCacheSvc, txManager, categoryListTTL, quizListTTL, cfg.CheckAnswer)
 
 	// Initialize AuthService
-	authService, err := service.NewAuthService(userRepository, cfg.Auth, txManager)
+	identityProviders, err := identity.NewFromConfig(cfg.Auth)
+	if err != nil {
+		logInstance.Fatal("Failed to configure identity providers", zap.Error(err))
+	}
+	authService, err := service.NewAuthService(userRepository, userIdentityRepository, userSessionRepository, roleRepository, cacheAdapter, identityProviders, cfg.Auth, txManager)
 	if err != nil {
 		logInstance.Fatal("Failed to initialize AuthService", zap.Error(err))
 	}
+	authSvc = authService
 
 	// Initialize UserService - matches cmd/api/main.go (no cfg)
 	userService := service.NewUserService(userRepository, userQuizAttemptRepository, quizRepository, txManager)
+	roleService := service.NewRoleService(userRepository, roleRepository)
+	contentService := service.NewContentService(quizRepository, categoryRepository, quizStatusRepository, quizService, txManager)
+	reviewService := service.NewReviewService(quizRepository, quizStatusRepository, contentService, quizService, txManager)
+	attemptOutboxSvc := service.NewAttemptOutboxService(attemptOutboxRepository, userQuizAttemptRepository, txManager, cfg.AttemptOutbox)
 
 	// Initialize AnonymousResultCacheService
 	anonymousResultCacheTTL := cfg.ParseTTLStringOrDefault(cfg.CacheTTLs.LLMResponse, 5*time.Minute)
 	anonymousResultCacheSvc := service.NewAnonymousResultCacheService(cacheAdapter, anonymousResultCacheTTL, txManager)
+	checkJobSvc := service.NewCheckJobService(quizService, attemptOutboxSvc, anonymousResultCacheSvc, cacheAdapter, cfg.CheckAnswer.Jobs)
+	attemptClaimSvc := service.NewAttemptClaimService(anonymousResultCacheSvc, attemptOutboxSvc)
 
 	// Initialize Handlers
-	quizHandler := handler.NewQuizHandler(quizService, userService, anonymousResultCacheSvc)
-	authHandler := handler.NewAuthHandler(authService)
-	userHandler := handler.NewUserHandler(userService)
+	quizHandler := handler.NewQuizHandler(quizService, attemptOutboxSvc, anonymousResultCacheSvc, checkJobSvc)
+	authHandler := handler.NewAuthHandler(authService, cfg.Auth)
+	userHandler := handler.NewUserHandler(userService, attemptClaimSvc)
+	roleHandler := handler.NewRoleHandler(roleService)
+	contentHandler := handler.NewContentHandler(contentService)
+	reviewHandler := handler.NewReviewHandler(reviewService)
 
 	// Initialize Validation Middleware
 	validationMiddleware := middleware.NewValidationMiddleware()
@@ -191,16 +204,57 @@ func TestMain(m *testing.M) {
 
 	// Register Routes
 	// Auth routes
+	app.Get("/.well-known/jwks.json", authHandler.JWKS)
 	authRouterGroup := app.Group("/auth")
-	authRouterGroup.Get("/google/login", authHandler.GoogleLogin)
-	authRouterGroup.Get("/google/callback", authHandler.GoogleCallback)
+	authRouterGroup.Get("/providers", authHandler.ListIdentityProviders)
+	authRouterGroup.Get("/:provider/login", authHandler.Login)
+	authRouterGroup.Get("/:provider/callback", authHandler.Callback)
 	authRouterGroup.Post("/refresh", authHandler.RefreshToken)
 	authRouterGroup.Post("/logout", middleware.Protected(authService), authHandler.Logout) // Protected
 
 	// User routes
 	userRouterGroup := app.Group("/users", middleware.Protected(authService)) // Protected group
 	userRouterGroup.Get("/me", userHandler.GetMyProfile)
+	userRouterGroup.Delete("/me", authHandler.DeleteMyAccount)
 	userRouterGroup.Get("/me/attempts", userHandler.GetMyAttempts)
+	userRouterGroup.Post("/me/attempts/claim", userHandler.ClaimMyAttempts)
+	userRouterGroup.Get("/me/sessions", authHandler.ListMySessions)
+	userRouterGroup.Delete("/me/sessions", authHandler.RevokeMyOtherSessions)
+	userRouterGroup.Delete("/me/sessions/:id", authHandler.RevokeMySession)
+	userRouterGroup.Get("/me/identities", authHandler.ListMyIdentities)
+
+	// Admin routes
+	adminRouterGroup := app.Group("/api/admin", middleware.Protected(authService), middleware.RequireRole(domain.RoleAdmin)) // Admin role required
+	adminRouterGroup.Get("/roles/:role/members", roleHandler.ListRoleMembers)
+	adminRouterGroup.Post("/roles/:role/members", roleHandler.GrantRole)
+	adminRouterGroup.Delete("/roles/:role/members", roleHandler.RevokeRole)
+	adminRouterGroup.Get("/categories", contentHandler.ListCategories)
+	adminRouterGroup.Post("/categories", contentHandler.CreateCategory)
+	adminRouterGroup.Put("/categories/:id", contentHandler.UpdateCategory)
+	adminRouterGroup.Delete("/categories/:id", contentHandler.DeleteCategory)
+	adminRouterGroup.Get("/categories/:id/subcategories", contentHandler.ListSubCategories)
+	adminRouterGroup.Post("/categories/:id/subcategories", contentHandler.CreateSubCategory)
+	adminRouterGroup.Put("/subcategories/:id", contentHandler.UpdateSubCategory)
+	adminRouterGroup.Delete("/subcategories/:id", contentHandler.DeleteSubCategory)
+	adminRouterGroup.Get("/subcategories/:id/quizzes", contentHandler.ListQuizzes)
+	adminRouterGroup.Post("/quizzes", contentHandler.CreateQuiz)
+	adminRouterGroup.Get("/quizzes/:id", contentHandler.GetQuiz)
+	adminRouterGroup.Put("/quizzes/:id", contentHandler.UpdateQuiz)
+	adminRouterGroup.Delete("/quizzes/:id", contentHandler.DeleteQuiz)
+	adminRouterGroup.Get("/quizzes/:id/revisions", contentHandler.ListQuizRevisions)
+	adminRouterGroup.Get("/quizzes/:id/revisions/:revision/diff", contentHandler.DiffQuizRevision)
+	adminRouterGroup.Post("/quizzes/:id/revisions/:revision/rollback", contentHandler.RollbackQuiz)
+	adminRouterGroup.Get("/quizzes/:id/rubric", contentHandler.GetQuizRubric)
+	adminRouterGroup.Put("/quizzes/:id/rubric", contentHandler.PutQuizRubric)
+	adminRouterGroup.Delete("/quizzes/:id/rubric", contentHandler.DeleteQuizRubric)
+	adminRouterGroup.Get("/content/export", contentHandler.ExportContent)
+	adminRouterGroup.Post("/content/import", contentHandler.ImportContent)
+	adminRouterGroup.Post("/quizzes/:id/status", reviewHandler.ChangeQuizStatus)
+	adminRouterGroup.Get("/quizzes/:id/status-history", reviewHandler.GetQuizStatusHistory)
+	adminRouterGroup.Get("/review/queue", reviewHandler.ListReviewQueue)
+	adminRouterGroup.Post("/review/quizzes/:id/approve", reviewHandler.ApproveQuiz)
+	adminRouterGroup.Post("/review/quizzes/:id/reject", reviewHandler.RejectQuiz)
+	adminRouterGroup.Put("/review/quizzes/:id", reviewHandler.EditQuiz)
 
 	// Quiz routes
 	apiGroup := app.Group("/api")
@@ -208,6 +262,9 @@ func TestMain(m *testing.M) {
 	apiGroup.Get("/quiz", middleware.OptionalAuth(authService), validationMiddleware.ValidateSubCategory(), quizHandler.GetRandomQuiz)           // Optional Auth & Validation
 	apiGroup.Get("/quizzes", middleware.OptionalAuth(authService), validationMiddleware.ValidateBulkQuizzesParams(), quizHandler.GetBulkQuizzes) // Optional Auth & Validation
 	apiGroup.Post("/quiz/check", middleware.OptionalAuth(authService), quizHandler.CheckAnswer)                                                  // Optional Auth
+	apiGroup.Post("/quiz/check/stream", middleware.OptionalAuth(authService), quizHandler.CheckAnswerStream)                                     // Optional Auth
+	apiGroup.Get("/quiz/check/:jobId", middleware.OptionalAuth(authService), quizHandler.GetCheckJob)                                            // Optional Auth
+	apiGroup.Get("/quiz/:id/next", middleware.OptionalAuth(authService), quizHandler.GetNextQuiz)                                                // Optional Auth
 
 	// Run migrations, seed data, and execute tests
 	if err := initDatabase(cfg); err != nil { // Pass cfg to initDatabase
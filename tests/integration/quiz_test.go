@@ -410,7 +410,7 @@ func TestCheckAnswer_LoggedInUser(t *testing.T) {
 	userID := util.NewULID()
 	testUser, err := createTestUserDB(db, models.User{ // db is the global from main_test.go
 		ID:                userID,
-		Email:             "loggeduser-" + userID + "@example.com",
+		Email:             sql.NullString{String: "loggeduser-" + userID + "@example.com", Valid: true},
 		GoogleID:          "googlelogged-" + userID,
 		Name:              sql.NullString{String: "Logged Test User " + userID, Valid: true},
 		ProfilePictureURL: sql.NullString{String: "http://example.com/picture-logged-" + userID + ".jpg", Valid: true},